@@ -19,19 +19,54 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
 	"runtime/debug"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
-	container "cloud.google.com/go/container/apiv1"
 	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/apierrors"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/availability"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/budget"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cancellation"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/clientrequest"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/completion"
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/doctor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/elicitation"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/install"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/logger"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/mock"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/operations"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/prompts"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/protocol"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/ratelimit"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/sampling"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/state"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/telemetry"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/timeout"
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/instructions"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/update"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
-	"google.golang.org/api/option"
+	"github.com/spf13/pflag"
 )
 
 const (
@@ -42,19 +77,68 @@ var (
 	version = "(unknown)"
 
 	// command flags
-	serverMode string
-	serverPort int
+	serverMode                string
+	serverPort                int
+	requireSessionCredentials bool
+	impersonateServiceAccount string
+	impersonationAllowlist    []string
+	readOnly                  bool
+	dryRun                    bool
+	otlpEndpoint              string
+	logLevel                  string
+	logFormat                 string
+	shutdownTimeout           time.Duration
+	maxResponseTokens         int
+	samplingSummaries         bool
+	explainCommands           bool
+	hideUnavailableTools      bool
+	inventoryRefresh          time.Duration
+	mockMode                  bool
+	mockFixtures              string
+	profile                   string
+	projectID                 string
+	location                  string
+	cacheTTLs                 map[string]string
+	toolTimeout               time.Duration
+	toolTimeouts              map[string]string
+	maxResultBytes            int
+	memoryLimitMiB            int64
+	toolMemoryLimits          map[string]string
+	endpoints                 map[string]string
+	quotaProject              string
+	stateStore                string
+	stateFile                 string
+	stateConfigMap            string
+	embeddings                string
+	embeddingModel            string
+	bm25K1                    float64
+	bm25B                     float64
+	titleWeight               float64
+	headingsWeight            float64
+	instructionsDir           string
+	instructionsDocs          []string
 
 	// rootCmd represents the base command when called without any subcommands
 	rootCmd = &cobra.Command{
 		Use:   "gke-mcp",
 		Short: "An MCP Server for Google Kubernetes Engine",
-		Run:   runRootCmd,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.ApplyEnv(cmd.Flags()); err != nil {
+				return err
+			}
+			return logger.Setup(logLevel, logFormat)
+		},
+		Run: runRootCmd,
 	}
 
 	installCmd = &cobra.Command{
-		Use:   "install",
+		Use:   "install [-- SERVER_FLAGS...]",
 		Short: "Install the GKE MCP Server into your AI tool settings.",
+		Long:  "Install the GKE MCP Server into your AI tool settings. Flags after -- are passed to the server when the client starts it.",
+		Example: `  gke-mcp install --client claude-desktop
+  gke-mcp install --client claude-code --scope project --agent-files claude-md
+  gke-mcp install --client cursor --scope project -- --read-only`,
+		RunE: runInstallCmd,
 	}
 
 	installGeminiCLICmd = &cobra.Command{
@@ -63,7 +147,54 @@ var (
 		Run:   runInstallGeminiCLICmd,
 	}
 
-	installDeveloper bool
+	uninstallCmd = &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the GKE MCP Server from your AI tool settings.",
+		Long:  "Remove the GKE MCP Server from the settings of every AI client, for your user and for the project in the current directory, and delete the extension and instruction files installed with it.",
+		Args:  cobra.NoArgs,
+		RunE:  runUninstallCmd,
+	}
+
+	updateCmd = &cobra.Command{
+		Use:   "update",
+		Short: "Update the GKE MCP Server to the latest release.",
+		Long:  "Update the GKE MCP Server to the latest GitHub release, replacing the binary in place after verifying it against the release checksums and their signature. Set $GITHUB_TOKEN to avoid the GitHub API rate limit.",
+		Args:  cobra.NoArgs,
+		RunE:  runUpdateCmd,
+	}
+
+	doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that the environment is set up for the GKE MCP Server.",
+		Long:  "Check that gcloud, gke-gcloud-auth-plugin and the Application Default Credentials are set up, that the APIs the tools call are enabled on the default project, and that their endpoints are reachable. Every failed check comes with a fix.",
+		Args:  cobra.NoArgs,
+		RunE:  runDoctorCmd,
+	}
+
+	installDeveloper  bool
+	updateCheck       bool
+	updateForce       bool
+	updateSkipSig     bool
+	uninstallDryRun   bool
+	installClient     string
+	installAgentFiles []string
+	installScope      string
+
+	evaluateInstructionsCmd = &cobra.Command{
+		Use:   "evaluate-instructions JUDGMENTS_FILE",
+		Short: "Measure how well get_instructions ranks the sections relevant to a set of queries.",
+		Long: `Measure how well get_instructions ranks the sections relevant to a set of queries,
+with the same instructions, embeddings and ranking flags as the server, e.g. to tune
+the ranking weights for your own instructions. JUDGMENTS_FILE is a YAML or JSON list of
+queries and the titles of their relevant sections:
+
+  - query: how do I query the logs of my cluster
+    relevant: [GKE Logs]`,
+		Args: cobra.ExactArgs(1),
+		RunE: runEvaluateInstructionsCmd,
+	}
+
+	evaluateK int
 )
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -81,47 +212,280 @@ func init() {
 	} else {
 		log.Printf("Failed to read build info to get version.")
 	}
+	rootCmd.Version = version
+	cobra.AddTemplateFunc("protocolVersions", func() string { return strings.Join(protocol.Supported(), ", ") })
+	cobra.AddTemplateFunc("toolSet", toolSet)
+	rootCmd.SetVersionTemplate(`gke-mcp {{.Version}}
+MCP protocol versions: {{protocolVersions}}
+Tools: {{toolSet}}
+`)
 
 	rootCmd.Flags().StringVar(&serverMode, "server-mode", "stdio", "transport to use for the server: stdio (default) or http")
 	rootCmd.Flags().IntVar(&serverPort, "server-port", 8080, "server port to use when server-mode is http; defaults to 8080")
+	rootCmd.Flags().BoolVar(&requireSessionCredentials, "require-session-credentials", false, "when server-mode is http, require every request to carry the caller's OAuth2 access token in the Authorization header instead of falling back to the server's Application Default Credentials")
+	rootCmd.Flags().StringVar(&impersonateServiceAccount, "impersonate-service-account", "", "service account email to impersonate for all GCP API calls; in http mode a request that carries its own credentials can override it with the X-Goog-Impersonate-Service-Account header")
+	rootCmd.Flags().StringSliceVar(&impersonationAllowlist, "impersonation-allowlist", nil, "service accounts that http requests without their own credentials may impersonate with the server's credentials through the X-Goog-Impersonate-Service-Account header; other such requests are rejected")
+	rootCmd.Flags().BoolVar(&readOnly, "read-only", false, "disable every tool that can modify resources")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "make mutating tools default to a dry run that shows the API request and equivalent gcloud command without executing it")
+	rootCmd.Flags().StringVar(&otlpEndpoint, "otlp-endpoint", "", "host:port of an OTLP/HTTP collector to export traces to; tracing is also enabled by the standard OTEL_EXPORTER_OTLP_ENDPOINT environment variable")
+	rootCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "how long to wait for in-flight tool calls to finish after a shutdown signal")
+	rootCmd.Flags().IntVar(&maxResponseTokens, "max-response-tokens", config.DefaultMaxResponseTokens, "approximate size in tokens above which tool results are summarized to protect the client's context window; 0 disables summarization")
+	rootCmd.Flags().BoolVar(&samplingSummaries, "sampling-summaries", false, "ask the client's model, through MCP sampling, to summarize tool results above --max-response-tokens when the client supports it, instead of the server's heuristic summary")
+	rootCmd.Flags().BoolVar(&explainCommands, "explain-commands", false, "append the equivalent gcloud or kubectl commands to every tool result, not only to dry runs")
+	rootCmd.Flags().BoolVar(&hideUnavailableTools, "hide-unavailable-tools", false, "hide the tools whose API is disabled on the project, or none of whose IAM permissions the caller holds, probing both on first use; call probe_tools to probe again")
+	rootCmd.Flags().DurationVar(&inventoryRefresh, "inventory-refresh", 0, "refresh the clusters, node pools and namespaces of the default project, and of the projects queried since, in the background at this interval, so queries and completions answer from warm data; 0 disables it")
+	rootCmd.Flags().BoolVar(&mockMode, "mock", false, "serve every GCP and Kubernetes API call from recorded fixtures, of a demo project and cluster unless --mock-fixtures adds others, so the server can be tried and tested without a project or credentials")
+	rootCmd.Flags().StringVar(&mockFixtures, "mock-fixtures", "", "directory of JSON fixtures served in --mock mode before the bundled ones")
+	rootCmd.Flags().StringVar(&projectID, "project", "", "default GCP project ID; defaults to the profile's project, then to the project configured in gcloud")
+	rootCmd.Flags().StringVar(&location, "location", "", "default GKE location; defaults to the profile's location, then to the region or zone configured in gcloud")
+	rootCmd.Flags().StringToStringVar(&cacheTTLs, "cache-ttl", nil, "how long to cache responses per resource kind, e.g. clusters=1m,server_config=2h; 0 disables caching")
+	rootCmd.Flags().DurationVar(&toolTimeout, "tool-timeout", config.DefaultToolTimeout, "how long a tool call may run before it is canceled; 0 disables the timeout")
+	rootCmd.Flags().StringToStringVar(&toolTimeouts, "tool-timeouts", nil, "per-tool timeouts overriding --tool-timeout, e.g. query_logs=5m,list_clusters=30s")
+	rootCmd.Flags().IntVar(&maxResultBytes, "max-result-bytes", config.DefaultMaxResultBytes, "size above which tool results are truncated, even with full_output; 0 disables the limit")
+	rootCmd.Flags().Int64Var(&memoryLimitMiB, "memory-limit-mib", 0, "heap size in MiB above which running tool calls are stopped, instead of the process running out of memory; also the Go runtime's soft memory limit; 0 disables the limit")
+	rootCmd.Flags().StringToStringVar(&toolMemoryLimits, "tool-memory-limits-mib", nil, "per-tool limits in MiB on how much the heap may grow during a call before it is stopped, e.g. query_logs=256,tail_logs=512")
+	rootCmd.Flags().StringToStringVar(&endpoints, "endpoint", nil, "API endpoint overrides as host:port, e.g. container=container-myendpoint.p.googleapis.com:443, for regional or Private Service Connect endpoints; APIs: "+strings.Join(config.APIs, ", "))
+	rootCmd.Flags().StringVar(&quotaProject, "quota-project", "", "project to bill API calls to and count against its quota, instead of the project of the credentials; like gcloud's --billing-project")
+	rootCmd.Flags().StringVar(&stateStore, "state-store", "file", "where to persist the session context, pending operations and cached inventory across restarts: file, configmap (in the cluster the server runs in) or none")
+	rootCmd.Flags().StringVar(&stateFile, "state-file", "", "state file used with --state-store=file; defaults to ~/.config/gke-mcp/state.json")
+	rootCmd.Flags().StringVar(&stateConfigMap, "state-configmap", "", "[namespace/]name of the ConfigMap used with --state-store=configmap; defaults to "+state.DefaultConfigMapName+" in the server's namespace")
+	addInstructionsFlags(rootCmd.Flags())
+	rootCmd.Flags().StringVar(&profile, "profile", "", profileUsage)
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "log level: debug, info (default), warn or error; defaults to $"+logger.LevelEnv)
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "log format: text (default) or json; defaults to $"+logger.FormatEnv+". Logs are always written to stderr")
 	rootCmd.AddCommand(installCmd)
 
 	installCmd.AddCommand(installGeminiCLICmd)
 	installCmd.PersistentFlags().BoolVarP(&installDeveloper, "developer", "d", false, "Install the MCP Server in developer mode")
+	installCmd.Flags().StringVar(&installClient, "client", "", "AI client to install the MCP Server into: "+strings.Join(installClients, ", "))
+	installCmd.Flags().StringVar(&installScope, "scope", install.ScopeUser, "where to configure clients that support it: user, for every project, or project, in the current directory")
+	installCmd.Flags().StringSliceVar(&installAgentFiles, "agent-files", nil, "instruction files to write the GKE instructions to in the current directory, for agents that don't support extensions: "+strings.Join(slices.Sorted(maps.Keys(install.AgentFiles)), ", ")+". They tell the agent to use the default project, location and cluster")
+	installCmd.Flags().StringVar(&projectID, "project", "", "default project for --agent-files; defaults to the profile's project, then to the project configured in gcloud")
+	installCmd.Flags().StringVar(&location, "location", "", "default location for --agent-files; defaults to the profile's location, then to the region or zone configured in gcloud")
+	installCmd.Flags().StringVar(&profile, "profile", "", profileUsage)
+
+	rootCmd.AddCommand(uninstallCmd)
+	uninstallCmd.Flags().BoolVar(&uninstallDryRun, "dry-run", false, "only show the changes that would be made")
+
+	rootCmd.AddCommand(updateCmd)
+	updateCmd.Flags().BoolVar(&updateCheck, "check", false, "only check whether an update is available")
+	updateCmd.Flags().BoolVar(&updateForce, "force", false, "install the latest release even if it isn't newer, e.g. over a development build")
+	updateCmd.Flags().BoolVar(&updateSkipSig, "insecure-skip-signature", false, "install the release without verifying its signature if the build has no release signing key, only its checksums")
+
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringVar(&projectID, "project", "", "GCP project to check; defaults to the profile's project, then to the project configured in gcloud")
+	doctorCmd.Flags().StringToStringVar(&endpoints, "endpoint", nil, "API endpoint overrides to check, as for the server")
+	doctorCmd.Flags().StringVar(&impersonateServiceAccount, "impersonate-service-account", "", "service account to check impersonating, as for the server")
+	doctorCmd.Flags().StringVar(&embeddings, "instructions-embeddings", "", "embeddings provider to check, as for the server")
+	doctorCmd.Flags().StringVar(&profile, "profile", "", profileUsage)
+
+	rootCmd.AddCommand(evaluateInstructionsCmd)
+	addInstructionsFlags(evaluateInstructionsCmd.Flags())
+	evaluateInstructionsCmd.Flags().StringVar(&profile, "profile", "", profileUsage)
+	evaluateInstructionsCmd.Flags().IntVar(&evaluateK, "k", 3, "number of results of each query to consider")
+}
+
+const profileUsage = "name of the profile to use from the configuration file (~/.config/gke-mcp/config.yaml); defaults to $" + config.ProfileEnv + ", then to the file's default_profile"
+
+// addInstructionsFlags adds the flags configuring get_instructions, which
+// the server and evaluate-instructions share.
+func addInstructionsFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&embeddings, "instructions-embeddings", "", "rank get_instructions results by semantic similarity using embeddings from vertex (Vertex AI) or ollama (a local Ollama server); keyword search is used when unset or unavailable")
+	fs.StringVar(&embeddingModel, "instructions-embedding-model", "", "embedding model used with --instructions-embeddings; defaults to text-embedding-005 for vertex and nomic-embed-text for ollama")
+	fs.Float64Var(&bm25K1, "instructions-bm25-k1", config.DefaultBM25K1, "BM25 k1 parameter of the get_instructions keyword search: how quickly repeated terms stop raising a section's score")
+	fs.Float64Var(&bm25B, "instructions-bm25-b", config.DefaultBM25B, "BM25 b parameter of the get_instructions keyword search, between 0 and 1: how much long sections are penalized")
+	fs.Float64Var(&titleWeight, "instructions-title-weight", config.DefaultTitleWeight, "how much a term in a section's title counts in the get_instructions keyword search, as extra occurrences in its content")
+	fs.Float64Var(&headingsWeight, "instructions-headings-weight", config.DefaultHeadingsWeight, "how much a term in the titles of a section's enclosing sections counts in the get_instructions keyword search, as extra occurrences in its content")
+	fs.StringVar(&instructionsDir, "instructions-dir", "", "directory of markdown files, e.g. your organization's runbooks, searched by get_instructions along with the bundled instructions")
+	fs.StringSliceVar(&instructionsDocs, "instructions-docs", nil, "GKE documentation pages to fetch and search with get_instructions, as URLs or paths relative to https://cloud.google.com/kubernetes-engine/docs/, e.g. troubleshooting/known-issues,how-to/cost-allocations. Pages are cached for a day")
 }
 
 type startOptions struct {
-	serverMode string
-	serverPort int
+	serverMode                string
+	serverPort                int
+	requireSessionCredentials bool
+	impersonateServiceAccount string
+	impersonationAllowlist    []string
+	readOnly                  bool
+	dryRun                    bool
+	otlpEndpoint              string
+	shutdownTimeout           time.Duration
+	maxResponseTokens         int
+	samplingSummaries         bool
+	explainCommands           bool
+	hideUnavailableTools      bool
+	inventoryRefresh          time.Duration
+	mockMode                  bool
+	mockFixtures              string
+	profile                   string
+	projectID                 string
+	location                  string
+	cacheTTLs                 map[string]string
+	toolTimeout               time.Duration
+	toolTimeouts              map[string]string
+	maxResultBytes            int
+	memoryLimitMiB            int64
+	toolMemoryLimits          map[string]string
+	endpoints                 map[string]string
+	quotaProject              string
+	stateStore                string
+	stateFile                 string
+	stateConfigMap            string
+	embeddings                string
+	embeddingModel            string
+	bm25K1                    float64
+	bm25B                     float64
+	titleWeight               float64
+	headingsWeight            float64
+	instructionsDir           string
+	instructionsDocs          []string
 }
 
 func runRootCmd(cmd *cobra.Command, args []string) {
-	opts := startOptions{
-		serverMode: serverMode,
-		serverPort: serverPort,
+	startMCPServer(cmd.Context(), flagOptions())
+}
+
+// flagOptions returns the options set by the command flags.
+func flagOptions() startOptions {
+	return startOptions{
+		serverMode:                serverMode,
+		serverPort:                serverPort,
+		requireSessionCredentials: requireSessionCredentials,
+		impersonateServiceAccount: impersonateServiceAccount,
+		impersonationAllowlist:    impersonationAllowlist,
+		readOnly:                  readOnly,
+		dryRun:                    dryRun,
+		otlpEndpoint:              otlpEndpoint,
+		shutdownTimeout:           shutdownTimeout,
+		maxResponseTokens:         maxResponseTokens,
+		samplingSummaries:         samplingSummaries,
+		explainCommands:           explainCommands,
+		hideUnavailableTools:      hideUnavailableTools,
+		inventoryRefresh:          inventoryRefresh,
+		mockMode:                  mockMode,
+		mockFixtures:              mockFixtures,
+		profile:                   profile,
+		projectID:                 projectID,
+		location:                  location,
+		cacheTTLs:                 cacheTTLs,
+		toolTimeout:               toolTimeout,
+		toolTimeouts:              toolTimeouts,
+		maxResultBytes:            maxResultBytes,
+		memoryLimitMiB:            memoryLimitMiB,
+		toolMemoryLimits:          toolMemoryLimits,
+		endpoints:                 endpoints,
+		quotaProject:              quotaProject,
+		stateStore:                stateStore,
+		stateFile:                 stateFile,
+		stateConfigMap:            stateConfigMap,
+		embeddings:                embeddings,
+		embeddingModel:            embeddingModel,
+		bm25K1:                    bm25K1,
+		bm25B:                     bm25B,
+		titleWeight:               titleWeight,
+		headingsWeight:            headingsWeight,
+		instructionsDir:           instructionsDir,
+		instructionsDocs:          instructionsDocs,
 	}
-	startMCPServer(cmd.Context(), opts)
 }
 
 func startMCPServer(ctx context.Context, opts startOptions) {
-	c := config.New(version)
+	configOpts, err := configOptions(opts)
+	if err != nil {
+		slog.Error("Invalid configuration", "err", err)
+		os.Exit(1)
+	}
+	c := config.New(version, configOpts...)
+
+	if limit := c.MemoryLimit(); limit > 0 {
+		// Make the garbage collector work harder before calls are stopped.
+		debug.SetMemoryLimit(limit)
+	}
+
+	if telemetry.TracingEnabled(opts.otlpEndpoint) {
+		shutdown, err := telemetry.SetupTracing(ctx, version, opts.otlpEndpoint)
+		if err != nil {
+			slog.Error("Failed to set up tracing", "err", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := shutdown(context.Background()); err != nil {
+				slog.Warn("Failed to flush traces", "err", err)
+			}
+		}()
+	}
 
 	instructions := ""
-	if err := adcAuthCheck(ctx, c); err != nil {
-		if strings.Contains(err.Error(), "Unauthenticated") {
-			log.Printf("GKE API calls requires Application Default Credentials (https://cloud.google.com/docs/authentication/application-default-credentials). Get credentials with `gcloud auth application-default login` before calling MCP tools.")
+	if m := c.Mock(); m != nil {
+		defer m.Close()
+		slog.Warn("Serving the GCP and Kubernetes API calls from mock fixtures", "project", c.DefaultProjectID(), "cluster", c.DefaultCluster())
+		instructions += fmt.Sprintf("The server runs in mock mode: its tools answer from recorded fixtures of the demo project %s, not from a real GCP project.", c.DefaultProjectID())
+	} else if err := adcAuthCheck(ctx, c); err != nil {
+		if auth.IsAuthError(err.Error()) {
+			slog.Warn("GKE API calls requires Application Default Credentials (https://cloud.google.com/docs/authentication/application-default-credentials). Get credentials with `gcloud auth application-default login` before calling MCP tools.")
 			instructions += "GKE API calls requires Application Default Credentials (https://cloud.google.com/docs/authentication/application-default-credentials). Get credentials with `gcloud auth application-default login` before calling MCP tools."
 		}
 	}
+	if err := auth.CheckAuthPlugin(); err != nil {
+		slog.Warn(err.Error())
+		instructions += "\n" + err.Error()
+	}
+
+	if c.DefaultCluster() != "" {
+		instructions += fmt.Sprintf("\nUnless the user names another cluster, they are working with the GKE cluster %s in location %s of project %s.", c.DefaultCluster(), c.DefaultLocation(), c.DefaultProjectID())
+	}
+
+	if err := loadState(ctx, opts); err != nil {
+		slog.Warn("Failed to restore server state", "err", err)
+	}
+
+	if ops := operations.Default.Pending(); len(ops) > 0 {
+		var names []string
+		for _, op := range ops {
+			names = append(names, fmt.Sprintf("%s (started by %s at %s)", op.Name, op.Tool, op.StartTime.Format(time.RFC3339)))
+		}
+		instructions += "\nThese GKE operations were still running when the server last stopped. Use the get_operation tool to check on them if the user asks: " + strings.Join(names, ", ")
+	}
+
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(session.Default.UnregisterHook)
+	hooks.AddAfterInitialize(protocol.Default.AfterInitializeHook)
+	hooks.AddBeforeCallTool(cancellation.Default.BeforeCallToolHook)
+	hooks.AddOnUnregisterSession(protocol.Default.UnregisterHook)
+
+	var summarizer governor.Summarizer
+	if c.SamplingSummaries() {
+		summarizer = sampling.Default.Summarize
+	}
 
 	s := server.NewMCPServer(
 		"GKE MCP Server",
 		version,
 		server.WithToolCapabilities(true),
-		server.WithResourceCapabilities(false, false),
+		server.WithToolFilter(availability.Default.Filter(c)),
+		server.WithToolFilter(elicitation.Default.Filter()),
+		server.WithResourceCapabilities(false, true),
+		server.WithPromptCapabilities(false),
 		server.WithInstructions(instructions),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(cancellation.Default.Middleware),
+		server.WithToolHandlerMiddleware(elicitation.Default.Middleware(c)),
+		server.WithToolHandlerMiddleware(operations.Default.Middleware),
+		server.WithToolHandlerMiddleware(budget.Middleware(c)),
+		server.WithToolHandlerMiddleware(timeout.Middleware(c)),
+		server.WithToolHandlerMiddleware(telemetry.TracingMiddleware),
+		server.WithToolHandlerMiddleware(telemetry.MetricsMiddleware),
+		server.WithToolHandlerMiddleware(auth.ErrorMiddleware),
+		server.WithToolHandlerMiddleware(apierrors.Middleware),
+		server.WithToolHandlerMiddleware(availability.Default.Middleware(c)),
+		server.WithToolHandlerMiddleware(ratelimit.Middleware),
+		server.WithToolHandlerMiddleware(explain.Middleware(c)),
+		server.WithToolHandlerMiddleware(governor.Middleware(c.MaxResponseTokens(), summarizer)),
 	)
 
+	s.AddNotificationHandler(cancellation.Method, cancellation.Default.NotificationHandler)
+	clientrequest.Default.Handle(completion.Method, completion.Capability, completion.Default.Handle)
+
 	resource := mcp.NewResource(
 		geminiInstructionsURI,
 		"GEMINI.md",
@@ -139,36 +503,168 @@ func startMCPServer(ctx context.Context, opts startOptions) {
 		}, nil
 	})
 
+	governor.Install(s)
+
 	if err := tools.Install(ctx, s, c); err != nil {
-		log.Fatalf("Failed to install tools: %v\n", err)
+		slog.Error("Failed to install tools", "err", err)
+		os.Exit(1)
+	}
+	if err := prompts.Install(ctx, s, c); err != nil {
+		slog.Error("Failed to install prompts", "err", err)
+		os.Exit(1)
+	}
+	installed, err := tools.ListTools(ctx, s)
+	if err != nil {
+		slog.Error("Failed to list tools", "err", err)
+		os.Exit(1)
 	}
+	elicitation.Default.Protect(installed)
+	governor.Declare(installed)
 
 	// start server in the right mode
-	log.Printf("Starting GKE MCP Server (%s) in mode '%s'", version, opts.serverMode)
-	var err error
-	endpoint := fmt.Sprintf(":%d", opts.serverPort)
+	slog.Info("Starting GKE MCP Server", "version", version, "mode", opts.serverMode)
+	if opts.serverMode != "stdio" && opts.serverMode != "http" {
+		slog.Warn("Unknown server mode, defaulting to stdio", "mode", opts.serverMode)
+		opts.serverMode = "stdio"
+	}
+
+	// The server itself runs on its own context, so in-flight tool calls can
+	// finish after a shutdown signal.
+	serveCtx, stopServing := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopServing()
+	signalCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
 
+	var httpServer *server.StreamableHTTPServer
+	errCh := make(chan error, 1)
 	switch opts.serverMode {
 	case "stdio":
-		err = server.ServeStdio(s)
+		go func() {
+			in, out := clientrequest.Default.InterceptStdio(os.Stdin, os.Stdout)
+			errCh <- server.NewStdioServer(s).Listen(serveCtx, cancellation.Default.InterceptStdio(in), out)
+		}()
 	case "http":
-		httpServer := server.NewStreamableHTTPServer(s)
-		log.Printf("Listening for HTTP connections on port: %d", opts.serverPort)
-		err = httpServer.Start(endpoint)
-	default:
-		log.Printf("Unknown mode '%s', defaulting to 'stdio'", opts.serverMode)
-		err = server.ServeStdio(s)
+		mux := http.NewServeMux()
+		httpServer = server.NewStreamableHTTPServer(s,
+			server.WithHTTPContextFunc(auth.HTTPContextFunc),
+			server.WithStreamableHTTPServer(&http.Server{
+				Handler:     mux,
+				BaseContext: func(net.Listener) context.Context { return serveCtx },
+			}),
+		)
+		mountHTTP(mux, httpServer)
+		slog.Info("Listening for HTTP connections", "port", opts.serverPort, "metrics_path", telemetry.MetricsPath)
+		go func() {
+			errCh <- httpServer.Start(fmt.Sprintf(":%d", opts.serverPort))
+		}()
+	}
+
+	select {
+	case err = <-errCh:
+	case <-signalCtx.Done():
+		slog.Info("Received shutdown signal, waiting for in-flight tool calls", "timeout", opts.shutdownTimeout)
+		drainCtx, cancel := context.WithTimeout(context.Background(), opts.shutdownTimeout)
+		if err := operations.Default.Drain(drainCtx); err != nil {
+			slog.Warn("Stopping with tool calls still running", "err", err)
+		}
+		if httpServer != nil {
+			if err := httpServer.Shutdown(drainCtx); err != nil {
+				slog.Warn("Failed to shut down HTTP server", "err", err)
+			}
+		}
+		cancel()
+		stopServing()
+		err = context.Canceled
 	}
+	saveState(context.Background())
+	plugin.Close()
+	gcp.Close()
+
 	if err != nil {
-		if errors.Is(err, context.Canceled) {
-			log.Printf("Server shutting down.")
+		if errors.Is(err, context.Canceled) || errors.Is(err, http.ErrServerClosed) {
+			slog.Info("Server shutting down")
 		} else {
-			log.Printf("Server error: %v\n", err)
+			slog.Error("Server error", "err", err)
+		}
+	}
+}
+
+// mountHTTP serves the MCP endpoint and, next to it, the metrics of the
+// server. Over stdio there is no listener, so the metrics aren't served.
+func mountHTTP(mux *http.ServeMux, mcpHandler http.Handler) {
+	mux.Handle("/mcp", mcpHandler)
+	mux.Handle(telemetry.MetricsPath, telemetry.MetricsHandler())
+}
+
+// loadState sets up the state store and restores the state persisted when
+// the server last stopped.
+func loadState(ctx context.Context, opts startOptions) error {
+	backend, err := stateBackend(opts)
+	if err != nil {
+		return err
+	}
+	state.Default.SetBackend(backend)
+	if err := state.Default.Register("operations", operations.Default); err != nil {
+		return err
+	}
+	if err := state.Default.Register("session_context", session.Default); err != nil {
+		return err
+	}
+	return state.Default.Load(ctx)
+}
+
+// stateBackend returns the backend selected by the --state-* flags. In mock
+// mode nothing is persisted, so the demo doesn't mix its state with that of
+// the real server.
+func stateBackend(opts startOptions) (state.Backend, error) {
+	if opts.mockMode {
+		return nil, nil
+	}
+	switch opts.stateStore {
+	case "none":
+		return nil, nil
+	case "file":
+		path := opts.stateFile
+		if path == "" {
+			var err error
+			if path, err = state.DefaultFilePath(); err != nil {
+				return nil, err
+			}
+		}
+		return state.File(path), nil
+	case "configmap":
+		namespace, name, ok := strings.Cut(opts.stateConfigMap, "/")
+		if !ok {
+			namespace, name = "", opts.stateConfigMap
 		}
+		if name == "" {
+			name = state.DefaultConfigMapName
+		}
+		return state.InClusterConfigMap(namespace, name)
+	default:
+		return nil, fmt.Errorf("unknown state store %q: must be file, configmap or none", opts.stateStore)
 	}
 }
 
+// saveState persists the server state, such as the operations that are still
+// running, so it can be picked up after a restart.
+func saveState(ctx context.Context) {
+	backend := state.Default.Backend()
+	if backend == nil {
+		return
+	}
+	if err := state.Default.Save(ctx); err != nil {
+		slog.Warn("Failed to save server state", "store", backend, "err", err)
+		return
+	}
+	slog.Info("Saved server state", "store", backend, "pending_operations", len(operations.Default.Pending()))
+}
+
 func adcAuthCheck(ctx context.Context, c *config.Config) error {
+	// The server's own credentials are never used in this mode.
+	if c.RequireSessionCredentials() {
+		return nil
+	}
 	projectID := c.DefaultProjectID()
 	// Can't do a pre-flight check without a default project.
 	if projectID == "" {
@@ -181,11 +677,10 @@ func adcAuthCheck(ctx context.Context, c *config.Config) error {
 		location = "us-central1"
 	}
 
-	cmClient, err := container.NewClusterManagerClient(ctx, option.WithUserAgent(c.UserAgent()))
+	cmClient, err := gcp.ClusterManager(ctx, c)
 	if err != nil {
-		return fmt.Errorf("failed to create cluster manager client: %w", err)
+		return err
 	}
-	defer cmClient.Close()
 
 	_, err = cmClient.GetServerConfig(ctx, &containerpb.GetServerConfigRequest{
 		Name: fmt.Sprintf("projects/%s/locations/%s", projectID, location),
@@ -193,6 +688,195 @@ func adcAuthCheck(ctx context.Context, c *config.Config) error {
 	return err
 }
 
+// installClients are the values of install --client.
+var installClients = []string{"gemini-cli", "claude-desktop", "claude-code", "cursor", "vscode", "windsurf", "zed"}
+
+// toolSet describes the tools the flags and profile enable, for --version.
+func toolSet() string {
+	configOpts, err := configOptions(flagOptions())
+	if err != nil {
+		return err.Error()
+	}
+	c := config.New(version, configOpts...)
+	set := "all"
+	if enabled := c.EnabledTools(); len(enabled) > 0 {
+		set = strings.Join(enabled, ", ")
+	}
+	switch {
+	case c.ReadOnly():
+		set += ", read-only"
+	case c.DryRun():
+		set += ", dry run by default"
+	}
+	return set
+}
+
+func runInstallCmd(cmd *cobra.Command, args []string) error {
+	if installClient == "" && len(installAgentFiles) == 0 {
+		return fmt.Errorf("--client or --agent-files is required; clients: %s", strings.Join(installClients, ", "))
+	}
+	for _, format := range installAgentFiles {
+		if _, ok := install.AgentFiles[format]; !ok {
+			return fmt.Errorf("unsupported agent file %q, must be one of: %s", format, strings.Join(slices.Sorted(maps.Keys(install.AgentFiles)), ", "))
+		}
+	}
+	if installClient != "" {
+		if err := installForClient(cmd, args); err != nil {
+			return err
+		}
+	}
+	if len(installAgentFiles) == 0 {
+		return nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	configOpts, err := configOptions(flagOptions())
+	if err != nil {
+		return err
+	}
+	c := config.New(version, configOpts...)
+	defaults := install.Defaults{
+		Project:  c.DefaultProjectID(),
+		Location: c.DefaultLocation(),
+		Cluster:  c.DefaultCluster(),
+	}
+	for _, format := range installAgentFiles {
+		path := filepath.Join(wd, install.AgentFiles[format])
+		if err := install.WriteAgentFile(path, defaults); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote the GKE instructions to %s.\n", path)
+	}
+	return nil
+}
+
+// installForClient adds the server to the configuration of installClient,
+// with args as the server flags.
+func installForClient(cmd *cobra.Command, args []string) error {
+	if installClient == "gemini-cli" {
+		if len(args) > 0 {
+			return fmt.Errorf("server flags aren't supported for gemini-cli")
+		}
+		runInstallGeminiCLICmd(cmd, args)
+		return nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	var configPath string
+	switch installClient {
+	case "claude-desktop":
+		if configPath, err = install.ClaudeDesktopConfigPath(); err == nil {
+			err = install.ClaudeDesktop(configPath, exePath, args)
+		}
+	case "claude-code":
+		if configPath, err = install.ClaudeCodeConfigPath(installScope, wd); err == nil {
+			err = install.ClaudeCode(configPath, exePath, args)
+		}
+	case "cursor":
+		if configPath, err = install.CursorConfigPath(installScope, wd); err == nil {
+			// Cursor only reads rules from projects.
+			rulesDir := ""
+			if installScope == install.ScopeProject {
+				rulesDir = filepath.Join(wd, ".cursor", "rules")
+			}
+			err = install.Cursor(configPath, rulesDir, exePath, args)
+		}
+	case "vscode":
+		if configPath, err = install.VSCodeConfigPath(installScope, wd); err == nil {
+			err = install.VSCode(configPath, wd, exePath, args)
+		}
+	case "windsurf":
+		if installScope != install.ScopeUser {
+			return fmt.Errorf("windsurf only supports --scope %s", install.ScopeUser)
+		}
+		if configPath, err = install.WindsurfConfigPath(); err == nil {
+			err = install.Windsurf(configPath, exePath, args)
+		}
+	case "zed":
+		if configPath, err = install.ZedConfigPath(installScope, wd); err == nil {
+			err = install.Zed(configPath, exePath, args)
+		}
+	default:
+		return fmt.Errorf("unsupported client %q, must be one of: %s", installClient, strings.Join(installClients, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to install for %s: %w", installClient, err)
+	}
+	fmt.Printf("Successfully added the GKE MCP server to %s. Restart %s to use it.\n", configPath, installClient)
+	return nil
+}
+
+func runUninstallCmd(cmd *cobra.Command, args []string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	changes, err := install.Uninstall(wd, uninstallDryRun)
+	switch {
+	case len(changes) == 0 && err == nil:
+		fmt.Println("The GKE MCP server isn't installed in any client.")
+	case uninstallDryRun:
+		fmt.Println("Would make the following changes:")
+	default:
+		fmt.Println("Made the following changes:")
+	}
+	for _, c := range changes {
+		fmt.Println("  " + c.String())
+	}
+	return err
+}
+
+func runUpdateCmd(cmd *cobra.Command, args []string) error {
+	u, err := update.New()
+	if err != nil {
+		return err
+	}
+	u.InsecureSkipSignature = updateSkipSig
+	release, err := u.Latest(cmd.Context())
+	if err != nil {
+		return err
+	}
+	newer, err := update.Newer(release.Tag, version)
+	switch {
+	case err != nil && !updateForce:
+		return fmt.Errorf("can't compare version %s to the latest release %s, use --force to install it: %w", version, release.Tag, err)
+	case !newer && !updateForce:
+		fmt.Printf("gke-mcp %s is up to date.\n", version)
+		return nil
+	case updateCheck:
+		fmt.Printf("gke-mcp %s is available, you have %s. Run gke-mcp update to install it.\n", release.Tag, version)
+		return nil
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	if exePath, err = filepath.EvalSymlinks(exePath); err != nil {
+		return fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+	binary, err := u.Download(cmd.Context(), release, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return fmt.Errorf("failed to download gke-mcp %s: %w", release.Tag, err)
+	}
+	if err := update.Replace(exePath, binary); err != nil {
+		return err
+	}
+	fmt.Printf("Updated gke-mcp at %s from %s to %s. Restart your AI clients to use it.\n", exePath, version, release.Tag)
+	return nil
+}
+
 func runInstallGeminiCLICmd(cmd *cobra.Command, args []string) {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -209,3 +893,157 @@ func runInstallGeminiCLICmd(cmd *cobra.Command, args []string) {
 	}
 	fmt.Println("Successfully installed GKE MCP server as a gemini-cli extension.")
 }
+
+func runDoctorCmd(cmd *cobra.Command, args []string) error {
+	configOpts, err := configOptions(flagOptions())
+	if err != nil {
+		return err
+	}
+	c := config.New(version, configOpts...)
+	failed := false
+	for _, r := range doctor.Run(cmd.Context(), c) {
+		fmt.Printf("[%s] %s: %s\n", r.Status, r.Check, r.Detail)
+		if r.Fix != "" {
+			fmt.Printf("    Fix: %s\n", strings.ReplaceAll(r.Fix, "\n", "\n         "))
+		}
+		failed = failed || r.Status == doctor.Failure
+	}
+	if failed {
+		// The results already describe the failures.
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return errors.New("some checks failed")
+	}
+	return nil
+}
+
+func runEvaluateInstructionsCmd(cmd *cobra.Command, args []string) error {
+	judgments, err := instructions.LoadJudgments(args[0])
+	if err != nil {
+		return err
+	}
+	configOpts, err := configOptions(flagOptions())
+	if err != nil {
+		return err
+	}
+	c := config.New(version, configOpts...)
+	e, err := instructions.EvaluateRanking(cmd.Context(), c, judgments, evaluateK)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Queries: %d\nMRR@%d: %.3f\nRecall@%d: %.3f\n", len(judgments), e.K, e.MRR, e.K, e.Recall)
+	for _, m := range e.Misses {
+		fmt.Printf("Miss: %q got %q\n", m.Query, m.Got)
+	}
+	return nil
+}
+
+// configOptions returns the config options set by the selected profile and
+// the flags, which override the profile.
+func configOptions(opts startOptions) ([]config.Option, error) {
+	profile, err := loadProfile(opts.profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration profile: %w", err)
+	}
+	configOpts, err := profile.Options()
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile: %w", err)
+	}
+	if opts.mockMode {
+		m, err := mock.New(opts.mockFixtures)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load mock fixtures: %w", err)
+		}
+		configOpts = append(configOpts, config.WithMock(m))
+	} else if opts.mockFixtures != "" {
+		return nil, errors.New("--mock-fixtures requires --mock")
+	}
+	configOpts = append(configOpts,
+		config.WithServerMode(opts.serverMode),
+		// Session credentials can only be supplied over HTTP.
+		config.WithRequireSessionCredentials(opts.requireSessionCredentials && opts.serverMode == "http"),
+		config.WithDryRun(opts.dryRun),
+		config.WithMaxResponseTokens(opts.maxResponseTokens),
+		config.WithSamplingSummaries(opts.samplingSummaries),
+		config.WithExplainCommands(opts.explainCommands),
+		config.WithHideUnavailableTools(opts.hideUnavailableTools),
+		config.WithInventoryRefresh(opts.inventoryRefresh),
+		config.WithToolTimeout(opts.toolTimeout),
+		config.WithMaxResultBytes(opts.maxResultBytes),
+		config.WithMemoryLimit(opts.memoryLimitMiB<<20),
+	)
+	if opts.impersonateServiceAccount != "" {
+		configOpts = append(configOpts, config.WithImpersonateServiceAccount(opts.impersonateServiceAccount))
+	}
+	if len(opts.impersonationAllowlist) > 0 {
+		configOpts = append(configOpts, config.WithImpersonationAllowlist(opts.impersonationAllowlist))
+	}
+	if opts.readOnly {
+		configOpts = append(configOpts, config.WithReadOnly(true))
+	}
+	if opts.quotaProject != "" {
+		configOpts = append(configOpts, config.WithQuotaProject(opts.quotaProject))
+	}
+	if opts.bm25K1 < 0 || opts.bm25B < 0 || opts.bm25B > 1 {
+		return nil, fmt.Errorf("invalid BM25 parameters k1=%v, b=%v: k1 must not be negative and b must be between 0 and 1", opts.bm25K1, opts.bm25B)
+	}
+	configOpts = append(configOpts, config.WithBM25(opts.bm25K1, opts.bm25B))
+	if opts.titleWeight < 0 || opts.headingsWeight < 0 {
+		return nil, fmt.Errorf("invalid ranking weights title=%v, headings=%v: weights must not be negative", opts.titleWeight, opts.headingsWeight)
+	}
+	configOpts = append(configOpts, config.WithRankingWeights(opts.titleWeight, opts.headingsWeight))
+	if opts.instructionsDir != "" {
+		configOpts = append(configOpts, config.WithInstructionsDir(opts.instructionsDir))
+	}
+	if len(opts.instructionsDocs) > 0 {
+		configOpts = append(configOpts, config.WithInstructionsDocs(opts.instructionsDocs))
+	}
+	if opts.embeddings != "" {
+		configOpts = append(configOpts, config.WithEmbeddings(opts.embeddings, opts.embeddingModel))
+	}
+	if opts.projectID != "" {
+		configOpts = append(configOpts, config.WithDefaultProjectID(opts.projectID))
+	}
+	if opts.location != "" {
+		configOpts = append(configOpts, config.WithDefaultLocation(opts.location))
+	}
+	cacheOpts, err := config.CacheTTLOptions(opts.cacheTTLs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --cache-ttl: %w", err)
+	}
+	configOpts = append(configOpts, cacheOpts...)
+	timeoutOpts, err := config.ToolTimeoutOptions(opts.toolTimeouts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --tool-timeouts: %w", err)
+	}
+	configOpts = append(configOpts, timeoutOpts...)
+	memoryOpts, err := config.ToolMemoryLimitOptions(opts.toolMemoryLimits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --tool-memory-limits-mib: %w", err)
+	}
+	configOpts = append(configOpts, memoryOpts...)
+	endpointOpts, err := config.EndpointOptions(opts.endpoints)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --endpoint: %w", err)
+	}
+	return append(configOpts, endpointOpts...), nil
+}
+
+// loadProfile returns the selected profile of the configuration file.
+func loadProfile(name string) (config.Profile, error) {
+	if name == "" {
+		name = os.Getenv(config.ProfileEnv)
+	}
+	path, err := config.DefaultFilePath()
+	if err != nil {
+		if name != "" {
+			return config.Profile{}, err
+		}
+		return config.Profile{}, nil
+	}
+	f, err := config.LoadFile(path)
+	if err != nil {
+		return config.Profile{}, err
+	}
+	return f.Profile(name)
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/telemetry"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestStateBackend(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	for _, tc := range []struct {
+		name        string
+		opts        startOptions
+		wantBackend bool
+	}{
+		{
+			name:        "file",
+			opts:        startOptions{stateStore: "file", stateFile: stateFile},
+			wantBackend: true,
+		},
+		{
+			name: "none",
+			opts: startOptions{stateStore: "none"},
+		},
+		{
+			name: "mock",
+			opts: startOptions{stateStore: "file", stateFile: stateFile, mockMode: true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			backend, err := stateBackend(tc.opts)
+			if err != nil {
+				t.Fatalf("stateBackend() failed: %v", err)
+			}
+			if got := backend != nil; got != tc.wantBackend {
+				t.Errorf("stateBackend() = %v, want a backend: %t", backend, tc.wantBackend)
+			}
+		})
+	}
+}
+
+func TestMountHTTP(t *testing.T) {
+	mux := http.NewServeMux()
+	mountHTTP(mux, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("mcp"))
+	}))
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "list_clusters"
+	telemetry.MetricsMiddleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	})(context.Background(), request)
+
+	for path, want := range map[string]string{
+		"/mcp":                "mcp",
+		telemetry.MetricsPath: `gke_mcp_tool_calls_total{result="ok",tool="list_clusters"}`,
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("GET %s = %d %q, want %q", path, w.Code, w.Body.String(), want)
+		}
+	}
+}
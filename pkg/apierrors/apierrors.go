@@ -0,0 +1,311 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apierrors classifies the errors of GCP and Kubernetes API calls,
+// retries the transient ones, and tells the user what to do about the
+// others: the IAM permission to grant, the API to enable or the quota to
+// wait for.
+package apierrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/googleapis/gax-go/v2/apierror"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Class is the kind of failure of an API call, which decides whether it is
+// retried and what the user is told to do about it.
+type Class int
+
+const (
+	// Other errors are reported as they are.
+	Other Class = iota
+	// PermissionDenied errors are fixed by granting an IAM role or a
+	// Kubernetes RBAC binding.
+	PermissionDenied
+	// APINotEnabled errors are fixed by enabling the API in the project.
+	APINotEnabled
+	// Quota errors go away once the quota refills, or is raised. Reads
+	// failing with them are retried, as they are mostly per-minute rate
+	// limits that refill while backing off.
+	Quota
+	// Transient errors go away by themselves, so reads failing with them
+	// are retried.
+	Transient
+)
+
+func (c Class) String() string {
+	switch c {
+	case PermissionDenied:
+		return "permission denied"
+	case APINotEnabled:
+		return "API not enabled"
+	case Quota:
+		return "quota exceeded"
+	case Transient:
+		return "transient"
+	}
+	return "other"
+}
+
+const maxAttempts = 4
+
+var (
+	// baseBackoff is the maximum delay before the first retry.
+	baseBackoff = time.Second
+	maxBackoff  = 30 * time.Second
+)
+
+// SetBackoff sets the maximum delay before the first retry.
+func SetBackoff(base time.Duration) {
+	baseBackoff = base
+}
+
+// httpStatus is implemented by the errors of the Kubernetes API, which
+// carry the status code of the response.
+type httpStatus interface {
+	HTTPStatus() int
+}
+
+// Classify returns the class of err, from its gRPC or HTTP status, or from
+// its message if it has none.
+func Classify(err error) Class {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return Other
+	}
+	c := classifyStatus(err)
+	// A disabled API is reported as PERMISSION_DENIED, with details telling
+	// it apart from a missing permission.
+	if m := ClassifyMessage(err.Error()); c == Other || c == PermissionDenied && m == APINotEnabled {
+		return m
+	}
+	return c
+}
+
+func classifyStatus(err error) Class {
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Reason() {
+		case "SERVICE_DISABLED":
+			return APINotEnabled
+		case "IAM_PERMISSION_DENIED":
+			return PermissionDenied
+		case "RATE_LIMIT_EXCEEDED":
+			return Quota
+		}
+	}
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return classifyHTTP(googleErr.Code)
+	}
+	var kubeErr httpStatus
+	if errors.As(err, &kubeErr) {
+		// The Kubernetes API server answers 429 when its priority and
+		// fairness queues are full, which clears within seconds.
+		if kubeErr.HTTPStatus() == http.StatusTooManyRequests {
+			return Transient
+		}
+		return classifyHTTP(kubeErr.HTTPStatus())
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.PermissionDenied:
+			return PermissionDenied
+		case codes.ResourceExhausted:
+			return Quota
+		case codes.Unavailable:
+			return Transient
+		}
+	}
+	return Other
+}
+
+func classifyHTTP(code int) Class {
+	switch code {
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusTooManyRequests:
+		return Quota
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return Transient
+	}
+	return Other
+}
+
+// messageClasses recognizes the classes of errors flattened into text, as
+// tools report them, in the order they are tried.
+var messageClasses = []struct {
+	class    Class
+	contains []string
+}{
+	{APINotEnabled, []string{"SERVICE_DISABLED", "has not been used in project", "API has not been enabled"}},
+	{PermissionDenied, []string{"code = PermissionDenied", "PERMISSION_DENIED", "IAM_PERMISSION_DENIED", "Error 403", "API returned 403"}},
+	{Quota, []string{"code = ResourceExhausted", "RESOURCE_EXHAUSTED", "Quota exceeded", "quotaExceeded", "rateLimitExceeded", "Error 429"}},
+	{Transient, []string{"code = Unavailable", "Error 502", "Error 503", "Error 504", "API returned 429", "API returned 502", "API returned 503", "API returned 504", "connection reset by peer"}},
+}
+
+// ClassifyMessage returns the class of an error from its message.
+func ClassifyMessage(msg string) Class {
+	for _, m := range messageClasses {
+		for _, s := range m.contains {
+			if strings.Contains(msg, s) {
+				return m.class
+			}
+		}
+	}
+	return Other
+}
+
+var (
+	permissionPatterns = []*regexp.Regexp{
+		// Permission 'container.clusters.get' denied, permission:container.clusters.get
+		regexp.MustCompile(`[Pp]ermission(?:\(s\))?[\s:=\\'"]+([a-z][a-zA-Z]*\.[a-z][a-zA-Z]*\.[a-z][a-zA-Z]*)`),
+		// Required 'compute.firewalls.list' permission
+		regexp.MustCompile(`\\?['"]([a-z][a-zA-Z]*\.[a-z][a-zA-Z]*\.[a-z][a-zA-Z]*)\\?['"] permission`),
+	}
+	// rbacPattern matches the Kubernetes API denying a request for RBAC.
+	rbacPattern    = regexp.MustCompile(`cannot (\w+) resource \\?"([^"\\]*)\\?" in API group \\?"([^"\\]*)\\?"(?: in the namespace \\?"([^"\\]*)\\?")?`)
+	servicePattern = regexp.MustCompile(`\b([a-z0-9-]+\.googleapis\.com)\b`)
+	projectPattern = regexp.MustCompile(`(?:in project |projects/)([a-z0-9-]+)`)
+)
+
+// Explain returns what to do about an error with message msg, or "" if its
+// class is Other or there is nothing to add.
+func Explain(msg string) string {
+	return explain(ClassifyMessage(msg), msg)
+}
+
+func explain(class Class, msg string) string {
+	project := "PROJECT_ID"
+	if m := projectPattern.FindStringSubmatch(msg); m != nil {
+		project = m[1]
+	}
+	switch class {
+	case PermissionDenied:
+		if m := rbacPattern.FindStringSubmatch(msg); m != nil {
+			resource := m[2]
+			if m[3] != "" {
+				resource += "." + m[3]
+			}
+			scope := "cluster-wide"
+			if m[4] != "" {
+				scope = "in namespace " + m[4]
+			}
+			return fmt.Sprintf("Kubernetes RBAC denied %s on %s %s. Ask a cluster admin to bind a Role or ClusterRole allowing it to the caller's Google identity, or to grant the caller an IAM role like roles/container.developer, then retry.", m[1], resource, scope)
+		}
+		for _, p := range permissionPatterns {
+			if m := p.FindStringSubmatch(msg); m != nil {
+				return fmt.Sprintf("The caller is missing the IAM permission %s. Ask the user to grant a role including it (see https://cloud.google.com/iam/docs/permissions-reference) to the identity the server acts as, e.g. `gcloud projects add-iam-policy-binding %s --member=MEMBER --role=ROLE`, then retry.", m[1], project)
+			}
+		}
+		return "The caller is missing an IAM permission the call needs. Ask the user to grant the identity the server acts as a role including it, e.g. roles/container.viewer to read GKE resources, then retry."
+	case APINotEnabled:
+		service := "SERVICE"
+		if m := servicePattern.FindStringSubmatch(msg); m != nil {
+			service = m[1]
+		}
+		return fmt.Sprintf("The %s API is not enabled in project %s. Ask the user to enable it with `gcloud services enable %s --project %s`, wait a few minutes for it to propagate, then retry.", service, project, service, project)
+	case Quota:
+		return fmt.Sprintf("A GCP quota or rate limit was exhausted. Retry in a minute or narrow the scope of the call; if it persists, ask the user to request a higher quota at https://console.cloud.google.com/iam-admin/quotas?project=%s.", project)
+	case Transient:
+		return "The API was temporarily unavailable. Retry the call in a moment."
+	}
+	return ""
+}
+
+// Retryable reports whether a call failing with err may succeed if
+// repeated, that is if err is Transient or Quota.
+func Retryable(err error) bool {
+	c := Classify(err)
+	return c == Transient || c == Quota
+}
+
+// Retry calls fn until it succeeds, fails with an error that isn't
+// Retryable, or was called maxAttempts times, backing off between calls.
+// Only calls that are safe to repeat, like reads, may be retried. It is the
+// only retry policy of the server: the GCP clients apply it through
+// ratelimit, and the Kubernetes client directly.
+func Retry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, backoff(attempt-1)); err != nil {
+				return err
+			}
+		}
+		if err = fn(); !Retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// backoff returns a fully jittered exponential delay for the given attempt,
+// starting at 0.
+func backoff(attempt int) time.Duration {
+	d := min(baseBackoff<<attempt, maxBackoff)
+	return time.Duration(rand.Int64N(int64(d)) + 1)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Middleware appends what to do about permission, API enablement, quota
+// and transient errors to the tool errors reporting them.
+func Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil {
+			if hint := explain(Classify(err), err.Error()); hint != "" {
+				return mcp.NewToolResultError(fmt.Sprintf("%v\n\n%s", err, hint)), nil
+			}
+			return result, err
+		}
+		if result == nil || !result.IsError {
+			return result, err
+		}
+		for i, content := range result.Content {
+			text, ok := content.(mcp.TextContent)
+			if !ok {
+				continue
+			}
+			if hint := Explain(text.Text); hint != "" && !strings.Contains(text.Text, hint) {
+				text.Text += "\n\n" + hint
+				result.Content[i] = text
+			}
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apierrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type kubeError int
+
+func (e kubeError) Error() string   { return fmt.Sprintf("Kubernetes API returned %d", int(e)) }
+func (e kubeError) HTTPStatus() int { return int(e) }
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{"nil", nil, Other},
+		{"canceled", fmt.Errorf("list: %w", context.Canceled), Other},
+		{"grpc permission", status.Error(codes.PermissionDenied, "Permission 'container.clusters.get' denied"), PermissionDenied},
+		{"grpc disabled", status.Error(codes.PermissionDenied, "Kubernetes Engine API has not been used in project 123 before or it is disabled."), APINotEnabled},
+		{"grpc quota", status.Error(codes.ResourceExhausted, "Quota exceeded"), Quota},
+		{"grpc unavailable", fmt.Errorf("get cluster: %w", status.Error(codes.Unavailable, "connection refused")), Transient},
+		{"grpc not found", status.Error(codes.NotFound, "cluster not found"), Other},
+		{"http forbidden", &googleapi.Error{Code: 403, Message: "Required 'compute.firewalls.list' permission"}, PermissionDenied},
+		{"http rate limit", &googleapi.Error{Code: 429}, Quota},
+		{"http unavailable", &googleapi.Error{Code: 503}, Transient},
+		{"kube forbidden", kubeError(403), PermissionDenied},
+		{"kube throttled", kubeError(429), Transient},
+		{"kube not found", kubeError(404), Other},
+		{"message", errors.New("rpc error: code = Unavailable desc = try again"), Transient},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Classify(tc.err); got != tc.want {
+				t.Errorf("Classify(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{
+			name: "grpc permission",
+			msg:  "rpc error: code = PermissionDenied desc = Permission 'container.clusters.get' denied on resource 'projects/my-project/locations/us-central1/clusters/c'",
+			want: "missing the IAM permission container.clusters.get. Ask the user to grant a role including it (see https://cloud.google.com/iam/docs/permissions-reference) to the identity the server acts as, e.g. `gcloud projects add-iam-policy-binding my-project",
+		},
+		{
+			name: "rest permission",
+			msg:  "googleapi: Error 403: Required 'compute.firewalls.list' permission for 'projects/my-project', forbidden",
+			want: "missing the IAM permission compute.firewalls.list.",
+		},
+		{
+			name: "error info permission",
+			msg:  "rpc error: code = PermissionDenied desc = denied\nerror details: name = ErrorInfo reason = IAM_PERMISSION_DENIED domain = iam.googleapis.com metadata = map[permission:monitoring.timeSeries.list]",
+			want: "missing the IAM permission monitoring.timeSeries.list.",
+		},
+		{
+			name: "unnamed permission",
+			msg:  "rpc error: code = PermissionDenied desc = The caller does not have permission",
+			want: "missing an IAM permission the call needs",
+		},
+		{
+			name: "rbac",
+			msg:  `Kubernetes API returned 403 Forbidden: pods is forbidden: User "me@example.com" cannot list resource "pods" in API group "" in the namespace "prod"`,
+			want: "Kubernetes RBAC denied list on pods in namespace prod.",
+		},
+		{
+			name: "cluster-wide rbac",
+			msg:  `Kubernetes API returned 403 Forbidden: clusterroles.rbac.authorization.k8s.io is forbidden: User "me@example.com" cannot list resource "clusterroles" in API group "rbac.authorization.k8s.io" at the cluster scope`,
+			want: "Kubernetes RBAC denied list on clusterroles.rbac.authorization.k8s.io cluster-wide.",
+		},
+		{
+			name: "api not enabled",
+			msg:  "rpc error: code = PermissionDenied desc = Cloud Logging API has not been used in project my-project before or it is disabled.\nerror details: name = ErrorInfo reason = SERVICE_DISABLED domain = googleapis.com metadata = map[consumer:projects/my-project service:logging.googleapis.com]",
+			want: "The logging.googleapis.com API is not enabled in project my-project. Ask the user to enable it with `gcloud services enable logging.googleapis.com --project my-project`",
+		},
+		{
+			name: "quota",
+			msg:  "rpc error: code = ResourceExhausted desc = Quota exceeded for quota metric 'Read requests' of service 'logging.googleapis.com' for consumer 'project_number:123'",
+			want: "A GCP quota or rate limit was exhausted.",
+		},
+		{
+			name: "transient",
+			msg:  "googleapi: Error 503: The service is currently unavailable., backendError",
+			want: "temporarily unavailable",
+		},
+		{
+			name: "other",
+			msg:  "rpc error: code = NotFound desc = cluster c not found",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Explain(tc.msg)
+			if tc.want == "" && got != "" || !strings.Contains(got, tc.want) {
+				t.Errorf("Explain() = %q, want it to contain %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	SetBackoff(time.Millisecond)
+	t.Cleanup(func() { SetBackoff(time.Second) })
+
+	for _, tc := range []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success", []error{nil}, 1, false},
+		{"transient then success", []error{kubeError(503), nil}, 2, false},
+		{"always transient", []error{kubeError(503), kubeError(503), kubeError(503), kubeError(503), kubeError(503)}, maxAttempts, true},
+		{"permission denied", []error{kubeError(403), nil}, 1, true},
+		{"quota then success", []error{status.Error(codes.ResourceExhausted, "Quota exceeded"), nil}, 2, false},
+		{"not found", []error{kubeError(404), nil}, 1, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			err := Retry(context.Background(), func() error {
+				calls++
+				return tc.errs[calls-1]
+			})
+			if calls != tc.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tc.wantCalls)
+			}
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Retry() = %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	msg := "googleapi: Error 403: Required 'compute.firewalls.list' permission for 'projects/my-project', forbidden"
+	for _, tc := range []struct {
+		name   string
+		result *mcp.CallToolResult
+		err    error
+	}{
+		{"error result", mcp.NewToolResultError(msg), nil},
+		{"error", nil, errors.New(msg)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := Middleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tc.result, tc.err
+			})
+			result, err := handler(context.Background(), mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatalf("handler returned %v, want an error result", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if !result.IsError || !strings.HasPrefix(text, msg) || !strings.Contains(text, "compute.firewalls.list. Ask the user") {
+				t.Errorf("result = %q, want the error followed by what to do", text)
+			}
+		})
+	}
+
+	handler := Middleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(msg), nil
+	})
+	if result, _ := handler(context.Background(), mcp.CallToolRequest{}); result.Content[0].(mcp.TextContent).Text != msg {
+		t.Error("a successful result was changed")
+	}
+}
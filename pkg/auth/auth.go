@@ -0,0 +1,299 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth resolves the Google credentials used for GCP API calls.
+//
+// By default the server calls GCP with its own Application Default
+// Credentials. When it is shared by several users over HTTP, each request can
+// instead carry the caller's OAuth2 access token in the Authorization header,
+// so every session acts with its own identity.
+//
+// Either identity can be used to impersonate a service account, configured
+// server-wide or per request with the X-Goog-Impersonate-Service-Account
+// header. A request only impersonates another service account with the
+// server's own credentials if it is allowlisted, so HTTP clients can't borrow
+// the server's identity to reach any service account it can impersonate.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/mock"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/ratelimit"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/telemetry"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+	"google.golang.org/grpc"
+)
+
+// ErrNoSessionCredentials is returned when the server requires session
+// credentials but the request did not supply any.
+var ErrNoSessionCredentials = errors.New("no credentials supplied for this session: send an OAuth2 access token in the Authorization header (e.g. the output of `gcloud auth print-access-token`)")
+
+// ErrImpersonationNotAllowed is returned when a request without its own
+// credentials asks to impersonate a service account that isn't allowlisted.
+var ErrImpersonationNotAllowed = errors.New("impersonating this service account with the server's credentials is not allowed: send your own OAuth2 access token in the Authorization header, or ask the server administrator to add it to --impersonation-allowlist")
+
+// ImpersonateServiceAccountHeader is the HTTP header used to override the
+// impersonated service account for a single request.
+const ImpersonateServiceAccountHeader = "X-Goog-Impersonate-Service-Account"
+
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+type tokenSourceKey struct{}
+
+type impersonateKey struct{}
+
+// WithTokenSource returns a copy of ctx carrying session-scoped credentials.
+func WithTokenSource(ctx context.Context, ts oauth2.TokenSource) context.Context {
+	return context.WithValue(ctx, tokenSourceKey{}, ts)
+}
+
+// TokenSourceFromContext returns the session-scoped credentials carried by ctx, if any.
+func TokenSourceFromContext(ctx context.Context) (oauth2.TokenSource, bool) {
+	ts, ok := ctx.Value(tokenSourceKey{}).(oauth2.TokenSource)
+	return ts, ok && ts != nil
+}
+
+// WithImpersonateServiceAccount returns a copy of ctx whose GCP API calls
+// impersonate serviceAccount, overriding the server-wide setting.
+func WithImpersonateServiceAccount(ctx context.Context, serviceAccount string) context.Context {
+	return context.WithValue(ctx, impersonateKey{}, serviceAccount)
+}
+
+// impersonateServiceAccount returns the service account to impersonate for
+// the caller identified by ctx, or "" for none. A caller without its own
+// credentials may only override the server-wide setting with an allowlisted
+// service account.
+func impersonateServiceAccount(ctx context.Context, c *config.Config) (string, error) {
+	sa, ok := ctx.Value(impersonateKey{}).(string)
+	if !ok || sa == "" {
+		return c.ImpersonateServiceAccount(), nil
+	}
+	if _, ok := TokenSourceFromContext(ctx); !ok && !c.ImpersonationAllowed(sa) {
+		return "", fmt.Errorf("%w (%s)", ErrImpersonationNotAllowed, sa)
+	}
+	return sa, nil
+}
+
+// HTTPContextFunc attaches the bearer token and impersonation override of an
+// incoming HTTP request, if present, to the request context. It is meant to
+// be used with server.WithHTTPContextFunc.
+func HTTPContextFunc(ctx context.Context, r *http.Request) context.Context {
+	if sa := strings.TrimSpace(r.Header.Get(ImpersonateServiceAccountHeader)); sa != "" {
+		ctx = WithImpersonateServiceAccount(ctx, sa)
+	}
+	token := bearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		return ctx
+	}
+	return WithTokenSource(ctx, oauth2.StaticTokenSource(&oauth2.Token{
+		AccessToken: token,
+		TokenType:   "Bearer",
+	}))
+}
+
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// restPaths are the base paths of the APIs whose clients only speak REST.
+var restPaths = map[string]string{
+	config.APICompute:              "",
+	config.APIBigQuery:             "/bigquery/v2/",
+	config.APIStorage:              "/storage/v1/",
+	config.APIBigQueryDataTransfer: "/",
+	config.APICloudBilling:         "/",
+	config.APISecurityCenter:       "/",
+	config.APIIAM:                  "/",
+	config.APIPolicyAnalyzer:       "/",
+	config.APIBinaryAuthorization:  "/",
+}
+
+// ClientOptions returns the options used to construct clients of a GCP API,
+// one of the config.API constants, on behalf of the caller identified by ctx.
+func ClientOptions(ctx context.Context, c *config.Config, api string) ([]option.ClientOption, error) {
+	if m := c.Mock(); m != nil {
+		return mockClientOptions(c, m, api)
+	}
+	opts := []option.ClientOption{
+		option.WithUserAgent(c.UserAgent()),
+		option.WithGRPCDialOption(ratelimit.DialOption()),
+		option.WithGRPCDialOption(telemetry.MetricsDialOption()),
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(unauthenticatedInterceptor)),
+	}
+	if endpoint := c.Endpoint(api); endpoint != "" {
+		// REST clients need a URL, with the base path of the API.
+		if path, ok := restPaths[api]; ok {
+			endpoint = "https://" + endpoint + path
+		}
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+	if quotaProject := c.QuotaProject(); quotaProject != "" {
+		opts = append(opts, option.WithQuotaProject(quotaProject))
+	}
+
+	var credentials []option.ClientOption
+	if ts, ok := TokenSourceFromContext(ctx); ok {
+		credentials = append(credentials, option.WithTokenSource(ts))
+	} else if c.RequireSessionCredentials() {
+		return nil, ErrNoSessionCredentials
+	} else {
+		creds, err := adcCredentials.credentials()
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, option.WithCredentials(creds))
+	}
+
+	sa, err := impersonateServiceAccount(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if sa != "" {
+		ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+			TargetPrincipal: sa,
+			Scopes:          []string{cloudPlatformScope},
+		}, credentials...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to impersonate service account %s: %w", sa, err)
+		}
+		credentials = []option.ClientOption{option.WithTokenSource(ts)}
+	}
+
+	opts = append(opts, credentials...)
+	if _, ok := restPaths[api]; ok {
+		// REST clients don't take dial options, so they get an HTTP client
+		// whose transport does the same for their requests.
+		transport, err := htransport.NewTransport(ctx, restTransport(api), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP transport: %w", err)
+		}
+		opts = append(opts, option.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+	return opts, nil
+}
+
+// restTransport returns the transport under the credentials of the REST
+// clients of api.
+func restTransport(api string) http.RoundTripper {
+	return unauthenticatedTransport{base: ratelimit.Transport(api, http.DefaultTransport)}
+}
+
+// mockClientOptions returns the options of clients of api calling the
+// fixtures of m, without credentials.
+func mockClientOptions(c *config.Config, m *mock.Server, api string) ([]option.ClientOption, error) {
+	opts := []option.ClientOption{option.WithUserAgent(c.UserAgent()), option.WithoutAuthentication()}
+	// Service Usage is called over HTTP too, by doctor.EnabledServices.
+	if _, rest := restPaths[api]; rest || api == config.APIServiceUsage {
+		return append(opts, option.WithHTTPClient(&http.Client{Transport: m})), nil
+	}
+	// Clients close their connection, so each gets its own.
+	conn, err := m.Dial(ratelimit.DialOption(), telemetry.MetricsDialOption(), telemetry.TracingDialOption())
+	if err != nil {
+		return nil, err
+	}
+	return append(opts, option.WithGRPCConn(conn)), nil
+}
+
+// TokenSource returns the access tokens of the caller identified by ctx,
+// for APIs called without a GCP client library, such as the Kubernetes API of
+// GKE clusters.
+func TokenSource(ctx context.Context, c *config.Config) (oauth2.TokenSource, error) {
+	if c.Mock() != nil {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "mock"}), nil
+	}
+	sa, err := impersonateServiceAccount(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	var ts oauth2.TokenSource
+	if sessionTS, ok := TokenSourceFromContext(ctx); ok {
+		ts = sessionTS
+	} else if c.RequireSessionCredentials() {
+		return nil, ErrNoSessionCredentials
+	} else {
+		creds, err := adcCredentials.credentials()
+		if err != nil {
+			return nil, err
+		}
+		ts = creds.TokenSource
+	}
+
+	if sa != "" {
+		// The token source is used after the request returns, e.g. by
+		// cached Kubernetes clients, so it must not be bound to ctx.
+		impersonated, err := impersonate.CredentialsTokenSource(context.Background(), impersonate.CredentialsConfig{
+			TargetPrincipal: sa,
+			Scopes:          []string{cloudPlatformScope},
+		}, option.WithTokenSource(ts))
+		if err != nil {
+			return nil, fmt.Errorf("failed to impersonate service account %s: %w", sa, err)
+		}
+		ts = impersonated
+	}
+	return ts, nil
+}
+
+// CheckCredentials gets an access token with the server's own credentials,
+// impersonating the configured service account if any, to check that they
+// are valid.
+func CheckCredentials(ctx context.Context, c *config.Config) error {
+	creds, err := adcCredentials.credentials()
+	if err != nil {
+		return err
+	}
+	ts := creds.TokenSource
+	if sa := c.ImpersonateServiceAccount(); sa != "" {
+		ts, err = impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+			TargetPrincipal: sa,
+			Scopes:          []string{cloudPlatformScope},
+		}, option.WithCredentials(creds))
+		if err != nil {
+			return fmt.Errorf("failed to impersonate service account %s: %w", sa, err)
+		}
+	}
+	_, err = ts.Token()
+	return err
+}
+
+// CacheKey identifies the credentials used for the caller identified by ctx,
+// so cached API responses are never shared between identities. It is empty
+// for the server's own credentials.
+func CacheKey(ctx context.Context, c *config.Config) string {
+	var key string
+	if ts, ok := TokenSourceFromContext(ctx); ok {
+		if tok, err := ts.Token(); err == nil {
+			sum := sha256.Sum256([]byte(tok.AccessToken))
+			key = hex.EncodeToString(sum[:8])
+		}
+	}
+	if sa, _ := impersonateServiceAccount(ctx, c); sa != "" {
+		key += "|" + sa
+	}
+	return key
+}
@@ -0,0 +1,234 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHTTPContextFunc(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantToken string
+	}{
+		{name: "no header"},
+		{name: "bearer token", header: "Bearer ya29.token", wantToken: "ya29.token"},
+		{name: "case insensitive scheme", header: "bearer ya29.token", wantToken: "ya29.token"},
+		{name: "basic auth ignored", header: "Basic dXNlcjpwYXNz"},
+		{name: "empty token", header: "Bearer "},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/mcp", nil)
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+			ctx := HTTPContextFunc(context.Background(), r)
+			ts, ok := TokenSourceFromContext(ctx)
+			if tc.wantToken == "" {
+				if ok {
+					t.Fatalf("TokenSourceFromContext() returned credentials, want none")
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("TokenSourceFromContext() returned no credentials")
+			}
+			tok, err := ts.Token()
+			if err != nil {
+				t.Fatalf("Token() failed: %v", err)
+			}
+			if tok.AccessToken != tc.wantToken {
+				t.Errorf("AccessToken = %q, want %q", tok.AccessToken, tc.wantToken)
+			}
+		})
+	}
+}
+
+func TestClientOptionsRequireSessionCredentials(t *testing.T) {
+	c := config.New("test", config.WithRequireSessionCredentials(true))
+
+	if _, err := ClientOptions(context.Background(), c, config.APIContainer); !errors.Is(err, ErrNoSessionCredentials) {
+		t.Errorf("ClientOptions() without session credentials returned %v, want %v", err, ErrNoSessionCredentials)
+	}
+
+	r := httptest.NewRequest("POST", "/mcp", nil)
+	r.Header.Set("Authorization", "Bearer ya29.token")
+	ctx := HTTPContextFunc(context.Background(), r)
+	if _, err := ClientOptions(ctx, c, config.APIContainer); err != nil {
+		t.Errorf("ClientOptions() with session credentials failed: %v", err)
+	}
+}
+
+func TestImpersonateServiceAccountOverride(t *testing.T) {
+	c := config.New("test",
+		config.WithImpersonateServiceAccount("default@p.iam.gserviceaccount.com"),
+		config.WithImpersonationAllowlist([]string{"allowed@p.iam.gserviceaccount.com"}),
+	)
+	tests := []struct {
+		name    string
+		header  string
+		token   string
+		want    string
+		wantErr bool
+	}{
+		{name: "no header", want: "default@p.iam.gserviceaccount.com"},
+		{name: "own credentials", header: "override@p.iam.gserviceaccount.com", token: "ya29.token", want: "override@p.iam.gserviceaccount.com"},
+		{name: "allowlisted", header: "allowed@p.iam.gserviceaccount.com", want: "allowed@p.iam.gserviceaccount.com"},
+		{name: "default", header: "default@p.iam.gserviceaccount.com", want: "default@p.iam.gserviceaccount.com"},
+		{name: "not allowlisted", header: "override@p.iam.gserviceaccount.com", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/mcp", nil)
+			if tc.header != "" {
+				r.Header.Set(ImpersonateServiceAccountHeader, tc.header)
+			}
+			if tc.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			ctx := HTTPContextFunc(context.Background(), r)
+			got, err := impersonateServiceAccount(ctx, c)
+			if tc.wantErr {
+				if !errors.Is(err, ErrImpersonationNotAllowed) {
+					t.Errorf("impersonateServiceAccount() returned %q, %v, want %v", got, err, ErrImpersonationNotAllowed)
+				}
+				if _, err := TokenSource(ctx, c); !errors.Is(err, ErrImpersonationNotAllowed) {
+					t.Errorf("TokenSource() returned %v, want %v", err, ErrImpersonationNotAllowed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("impersonateServiceAccount() failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("impersonateServiceAccount() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	session := WithTokenSource(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "ya29.token"}))
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		msg      string
+		rejected bool
+		wantHint string
+	}{
+		{name: "unauthenticated error", ctx: context.Background(), err: status.Error(codes.Unauthenticated, "..."), wantHint: reauthInstructions},
+		{name: "rejected call", ctx: context.Background(), msg: "failed to list clusters: ...", rejected: true, wantHint: reauthInstructions},
+		{name: "rejected session token", ctx: session, msg: "failed to list clusters: ...", rejected: true, wantHint: sessionReauthInstructions},
+		{name: "kubernetes 401", ctx: context.Background(), msg: "Error 401: Unauthorized"},
+		{name: "not found", ctx: context.Background(), msg: "rpc error: code = NotFound desc = cluster not found"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := ErrorMiddleware(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				if tc.rejected {
+					markRejected(ctx)
+				}
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return mcp.NewToolResultError(tc.msg), nil
+			})
+			result, err := handler(tc.ctx, mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			for _, hint := range []string{reauthInstructions, sessionReauthInstructions} {
+				if got, want := strings.Contains(text, hint), hint == tc.wantHint; got != want {
+					t.Errorf("result %q contains %q = %v, want %v", text, hint, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUnauthenticatedTransport(t *testing.T) {
+	for _, code := range []int{http.StatusOK, http.StatusUnauthorized, http.StatusForbidden} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(code)
+		}))
+		r := &rejected{}
+		req := httptest.NewRequest("GET", srv.URL, nil).WithContext(context.WithValue(context.Background(), rejectedKey{}, r))
+		req.RequestURI = ""
+		resp, err := (unauthenticatedTransport{base: http.DefaultTransport}).RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip() failed: %v", err)
+		}
+		resp.Body.Close()
+		srv.Close()
+		if got, want := r.Load(), code == http.StatusUnauthorized; got != want {
+			t.Errorf("status %d recorded as rejected = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestCredentialManagerReset(t *testing.T) {
+	defer func(old func(context.Context, ...string) (*google.Credentials, error)) { findCredentials = old }(findCredentials)
+	token := "old"
+	findCredentials = func(context.Context, ...string) (*google.Credentials, error) {
+		return &google.Credentials{TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})}, nil
+	}
+
+	resets := 0
+	OnReset(func() { resets++ })
+
+	m := &credentialManager{}
+	creds, err := m.credentials()
+	if err != nil {
+		t.Fatalf("credentials() failed: %v", err)
+	}
+	ts := creds.TokenSource
+	if tok, err := ts.Token(); err != nil || tok.AccessToken != "old" {
+		t.Fatalf("Token() = %v, %v; want the old token", tok, err)
+	}
+
+	// The user re-authenticates after the old credentials were rejected.
+	m.reset()
+	token = "new"
+	if tok, err := ts.Token(); err != nil || tok.AccessToken != "new" {
+		t.Errorf("Token() of an existing source after reset = %v, %v; want the new token", tok, err)
+	}
+	if resets != 1 {
+		t.Errorf("reset hooks called %d times, want 1", resets)
+	}
+}
+
+func TestServerIdentity(t *testing.T) {
+	for key, want := range map[string]bool{"": true, "|sa@p.iam.gserviceaccount.com": true, "0123abcd": false, "0123abcd|sa@p.iam.gserviceaccount.com": false} {
+		if got := ServerIdentity(key); got != want {
+			t.Errorf("ServerIdentity(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
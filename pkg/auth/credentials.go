@@ -0,0 +1,282 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/googleapis/gax-go/v2/apierror"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// refreshWindow is how long before expiry an access token is refreshed, so a
+// token never expires in the middle of a slow API call.
+const refreshWindow = 5 * time.Minute
+
+const reauthInstructions = "Application Default Credentials are missing, expired or revoked. Ask the user to run `gcloud auth application-default login` outside the AI, then retry; the server picks up the new credentials without a restart."
+
+// adcCredentials is the process-wide Application Default Credentials
+// manager. It refreshes tokens ahead of expiry and reloads the credentials
+// from disk after a failure, so the user can re-authenticate while the server
+// keeps running.
+var adcCredentials = &credentialManager{}
+
+// findCredentials loads the Application Default Credentials.
+var findCredentials = google.FindDefaultCredentials
+
+type credentialManager struct {
+	mu    sync.Mutex
+	creds *google.Credentials
+	// src is the token source of the loaded credentials, which the token
+	// source of creds draws from.
+	src oauth2.TokenSource
+}
+
+// credentials returns the current Application Default Credentials, loading
+// them if needed. Their token source always draws from the credentials
+// loaded last, so clients keep working after a reset.
+func (m *credentialManager) credentials() (*google.Credentials, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m.creds, nil
+}
+
+// source returns the token source of the current credentials, loading them
+// if needed.
+func (m *credentialManager) source() (oauth2.TokenSource, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m.src, nil
+}
+
+// load loads the credentials if they aren't yet. m.mu must be held.
+func (m *credentialManager) load() error {
+	if m.creds != nil {
+		return nil
+	}
+	// The token source outlives any single request, so it must not be bound
+	// to a request context.
+	creds, err := findCredentials(context.Background(), cloudPlatformScope)
+	if err != nil {
+		return fmt.Errorf("failed to load Application Default Credentials: %w. %s", err, reauthInstructions)
+	}
+	m.src = oauth2.ReuseTokenSourceWithExpiry(nil, creds.TokenSource, refreshWindow)
+	m.creds = &google.Credentials{
+		ProjectID:   creds.ProjectID,
+		JSON:        creds.JSON,
+		TokenSource: &refreshingTokenSource{m: m},
+	}
+	return nil
+}
+
+// reset drops the cached credentials so the next call reloads them, and
+// tells the OnReset hooks.
+func (m *credentialManager) reset() {
+	m.mu.Lock()
+	m.creds = nil
+	m.src = nil
+	m.mu.Unlock()
+
+	resetHooksMu.Lock()
+	hooks := slices.Clone(resetHooks)
+	resetHooksMu.Unlock()
+	for _, f := range hooks {
+		f()
+	}
+}
+
+var (
+	resetHooksMu sync.Mutex
+	resetHooks   []func()
+)
+
+// OnReset registers f to be called when the server's own credentials are
+// reset after being rejected, e.g. to drop what was cached for them.
+func OnReset(f func()) {
+	resetHooksMu.Lock()
+	defer resetHooksMu.Unlock()
+	resetHooks = append(resetHooks, f)
+}
+
+// ServerIdentity reports whether key, returned by CacheKey, identifies the
+// server's own credentials, impersonating a service account or not, rather
+// than those of a session.
+func ServerIdentity(key string) bool {
+	return key == "" || strings.HasPrefix(key, "|")
+}
+
+// refreshingTokenSource gets tokens from the current credentials of m, not
+// those it was handed out with, so it picks up new credentials once the
+// user re-authenticates.
+type refreshingTokenSource struct {
+	m *credentialManager
+}
+
+func (ts *refreshingTokenSource) Token() (*oauth2.Token, error) {
+	src, err := ts.m.source()
+	if err != nil {
+		return nil, err
+	}
+	tok, err := src.Token()
+	if err != nil {
+		ts.m.reset()
+		return nil, fmt.Errorf("%w. %s", err, reauthInstructions)
+	}
+	return tok, nil
+}
+
+const sessionReauthInstructions = "The access token sent in the Authorization header was rejected, probably because it expired. Ask the user to send a fresh one, e.g. the output of `gcloud auth print-access-token`, then retry."
+
+// IsAuthError reports whether an error message describes a failure to load
+// or refresh Application Default Credentials, like the server's startup
+// check.
+func IsAuthError(msg string) bool {
+	for _, s := range []string{
+		"Unauthenticated",
+		"invalid_grant",
+		"invalid_rapt",
+		"reauth related error",
+		"could not find default credentials",
+		"Request had invalid authentication credentials",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+type rejectedKey struct{}
+
+// rejected records whether a GCP API rejected the credentials of any call
+// made during a tool call.
+type rejected struct {
+	atomic.Bool
+}
+
+func markRejected(ctx context.Context) {
+	if r, ok := ctx.Value(rejectedKey{}).(*rejected); ok {
+		r.Store(true)
+	}
+}
+
+// isUnauthenticated reports whether err is a GCP API rejecting the
+// credentials of a call, from its gRPC or HTTP status.
+func isUnauthenticated(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPCode() == http.StatusUnauthorized || apiErr.GRPCStatus().Code() == codes.Unauthenticated
+	}
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return googleErr.Code == http.StatusUnauthorized
+	}
+	return status.Code(err) == codes.Unauthenticated
+}
+
+// unauthenticatedInterceptor records the gRPC calls rejected as
+// UNAUTHENTICATED.
+func unauthenticatedInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if status.Code(err) == codes.Unauthenticated {
+		markRejected(ctx)
+	}
+	return err
+}
+
+// unauthenticatedTransport records the HTTP requests answered with 401
+// Unauthorized.
+type unauthenticatedTransport struct {
+	base http.RoundTripper
+}
+
+func (t unauthenticatedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		markRejected(req.Context())
+	}
+	return resp, err
+}
+
+// ErrorMiddleware tells the user how to re-authenticate when a GCP API
+// rejected the credentials of a tool call. If they were the server's
+// Application Default Credentials, they are also reloaded, so the user can
+// log in again without restarting the server; a session's own access token is
+// left to its caller to renew.
+func ErrorMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		r := &rejected{}
+		result, err := next(context.WithValue(ctx, rejectedKey{}, r), request)
+		if !r.Load() && !isUnauthenticated(err) {
+			return result, err
+		}
+		hint := sessionReauthInstructions
+		if _, ok := TokenSourceFromContext(ctx); !ok {
+			adcCredentials.reset()
+			hint = reauthInstructions
+		}
+		if err != nil {
+			if strings.Contains(err.Error(), hint) {
+				return result, err
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("%v\n\n%s", err, hint)), nil
+		}
+		if result == nil || !result.IsError {
+			return result, err
+		}
+		for i, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok && !strings.Contains(text.Text, hint) {
+				text.Text += "\n\n" + hint
+				result.Content[i] = text
+				break
+			}
+		}
+		return result, err
+	}
+}
+
+// CheckAuthPlugin reports whether gke-gcloud-auth-plugin, which kubectl needs
+// to authenticate to GKE clusters, is installed.
+func CheckAuthPlugin() error {
+	if _, err := exec.LookPath("gke-gcloud-auth-plugin"); err != nil {
+		return fmt.Errorf("gke-gcloud-auth-plugin was not found in PATH, so kubectl cannot authenticate to GKE clusters. Install it with `gcloud components install gke-gcloud-auth-plugin` (see https://cloud.google.com/kubernetes-engine/docs/how-to/cluster-access-for-kubectl#install_plugin)")
+	}
+	return nil
+}
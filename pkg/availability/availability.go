@@ -0,0 +1,255 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package availability hides the tools the caller can't use on a project:
+// the tools whose API is disabled, and the tools none of whose IAM
+// permissions the caller holds. It probes the APIs and permissions on first
+// use, once per project and identity, and again when asked to.
+//
+// A tool's API is the service of its first permission in the catalog, which
+// names what the tool mainly does; the other APIs it uses only add to its
+// results.
+package availability
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/iam/apiv1/iampb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/doctor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// ttl is how long a probe is used before the project is probed again,
+	// so APIs enabled and roles granted meanwhile are eventually noticed.
+	ttl = time.Hour
+	// Service Usage and testIamPermissions accept at most 30 services and
+	// 100 permissions per call.
+	maxServices    = 30
+	maxPermissions = 100
+	// maxInvalid bounds how many permissions not testable on projects are
+	// dropped before giving up.
+	maxInvalid = 10
+)
+
+// services are the services of the permission prefixes not named after
+// their service.
+var services = map[string]string{
+	"resourcemanager": "cloudresourcemanager.googleapis.com",
+}
+
+// invalidPermission matches the error of testIamPermissions for permissions
+// that don't apply to projects.
+var invalidPermission = regexp.MustCompile(`[Pp]ermission '?([a-z][a-zA-Z]*\.[a-z][a-zA-Z]*\.[a-z][a-zA-Z]*)'? is not valid`)
+
+// Service returns the service of the API an IAM permission belongs to, e.g.
+// container.googleapis.com for container.clusters.get.
+func Service(permission string) string {
+	prefix, _, _ := strings.Cut(permission, ".")
+	if service, ok := services[prefix]; ok {
+		return service
+	}
+	return prefix + ".googleapis.com"
+}
+
+// Probe is what the caller can use on a project.
+type Probe struct {
+	Project string `json:"project"`
+	// Disabled are the services disabled on the project.
+	Disabled []string `json:"disabled_services,omitempty"`
+	// Denied are the permissions the caller doesn't hold on the project.
+	Denied []string `json:"denied_permissions,omitempty"`
+	// Errors are the probes that failed, whose tools are assumed available.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Unavailable returns why a tool described by e can't be used on the
+// project, or "" if it can.
+func (p *Probe) Unavailable(e catalog.Entry) string {
+	if len(e.Permissions) == 0 {
+		return ""
+	}
+	if service := Service(e.Permissions[0]); slices.Contains(p.Disabled, service) {
+		return fmt.Sprintf("the %s API it needs is disabled on project %s; enable it with `gcloud services enable %s --project %s`", service, p.Project, service, p.Project)
+	}
+	for _, permission := range e.Permissions {
+		if !slices.Contains(p.Denied, permission) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("the caller holds none of its IAM permissions on project %s: %s", p.Project, strings.Join(e.Permissions, ", "))
+}
+
+// Store keeps the probes of each project and identity.
+type Store struct {
+	probes *cache.Cache[*Probe]
+	// Probes of the environment, replaceable in tests.
+	enabledServices func(ctx context.Context, c *config.Config, project string, services []string) (map[string]bool, error)
+	testPermissions func(ctx context.Context, c *config.Config, project string, permissions []string) ([]string, error)
+}
+
+// NewStore returns a store without probes.
+func NewStore() *Store {
+	return &Store{
+		probes:          cache.New[*Probe]("tool_availability"),
+		enabledServices: doctor.EnabledServices,
+		testPermissions: testPermissions,
+	}
+}
+
+// Default is the store of the server.
+var Default = NewStore()
+
+// Get returns the probe of project for the caller identified by ctx,
+// probing it if it wasn't, or too long ago, or if refresh is set.
+func (s *Store) Get(ctx context.Context, c *config.Config, project string, refresh bool) *Probe {
+	p, _, _ := s.probes.Get(ctx, project+"|"+auth.CacheKey(ctx, c), ttl, refresh, func(ctx context.Context) (*Probe, error) {
+		return s.probe(ctx, c, project), nil
+	})
+	return p
+}
+
+// probe checks the services and permissions of every tool in the catalog.
+func (s *Store) probe(ctx context.Context, c *config.Config, project string) *Probe {
+	p := &Probe{Project: project}
+	permissionSet := map[string]bool{}
+	serviceSet := map[string]bool{}
+	for _, e := range catalog.Entries() {
+		for i, permission := range e.Permissions {
+			permissionSet[permission] = true
+			if i == 0 {
+				serviceSet[Service(permission)] = true
+			}
+		}
+	}
+	if len(permissionSet) == 0 {
+		return p
+	}
+
+	allServices := slices.Sorted(maps.Keys(serviceSet))
+	for chunk := range slices.Chunk(allServices, maxServices) {
+		enabled, err := s.enabledServices(ctx, c, project, chunk)
+		if err != nil {
+			p.Errors = append(p.Errors, fmt.Sprintf("couldn't check the APIs enabled on project %s: %v", project, err))
+			break
+		}
+		for _, service := range chunk {
+			if state, ok := enabled[service]; ok && !state {
+				p.Disabled = append(p.Disabled, service)
+			}
+		}
+	}
+
+	permissions := slices.Sorted(maps.Keys(permissionSet))
+	for chunk := range slices.Chunk(permissions, maxPermissions) {
+		held, err := s.testPermissions(ctx, c, project, chunk)
+		if err != nil {
+			p.Errors = append(p.Errors, fmt.Sprintf("couldn't check the IAM permissions of the caller on project %s: %v", project, err))
+			break
+		}
+		for _, permission := range chunk {
+			if !slices.Contains(held, permission) {
+				p.Denied = append(p.Denied, permission)
+			}
+		}
+	}
+	return p
+}
+
+// testPermissions returns which of permissions the caller holds on project.
+// Permissions that don't apply to projects, like organization permissions,
+// are assumed held.
+func testPermissions(ctx context.Context, c *config.Config, project string, permissions []string) ([]string, error) {
+	client, err := gcp.Projects(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	var invalid []string
+	for range maxInvalid {
+		resp, err := client.TestIamPermissions(ctx, &iampb.TestIamPermissionsRequest{
+			Resource:    "projects/" + project,
+			Permissions: permissions,
+		})
+		if err == nil {
+			return append(resp.GetPermissions(), invalid...), nil
+		}
+		m := invalidPermission.FindStringSubmatch(err.Error())
+		if m == nil || !slices.Contains(permissions, m[1]) {
+			return nil, err
+		}
+		invalid = append(invalid, m[1])
+		permissions = slices.DeleteFunc(permissions, func(p string) bool { return p == m[1] })
+	}
+	return nil, fmt.Errorf("more than %d permissions don't apply to projects: %s", maxInvalid, strings.Join(invalid, ", "))
+}
+
+// project returns the project the tools of the session are used on, or ""
+// if there is none.
+func project(ctx context.Context, c *config.Config, request mcp.CallToolRequest) string {
+	return session.ProjectID(ctx, request, c)
+}
+
+// Filter hides the tools the caller can't use on the project of its session
+// from the tools listed, if c enables it. Listings by the server itself,
+// outside of a client session, are left alone.
+func (s *Store) Filter(c *config.Config) server.ToolFilterFunc {
+	return func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		if !c.HideUnavailableTools() || server.ClientSessionFromContext(ctx) == nil {
+			return tools
+		}
+		projectID := project(ctx, c, mcp.CallToolRequest{})
+		if projectID == "" {
+			return tools
+		}
+		p := s.Get(ctx, c, projectID, false)
+		return slices.DeleteFunc(tools, func(tool mcp.Tool) bool {
+			e, ok := catalog.Lookup(tool.Name)
+			return ok && p.Unavailable(e) != ""
+		})
+	}
+}
+
+// Middleware rejects the calls of tools the caller can't use on the project
+// of the call, if c enables it, explaining why.
+func (s *Store) Middleware(c *config.Config) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !c.HideUnavailableTools() {
+				return next(ctx, request)
+			}
+			e, ok := catalog.Lookup(request.Params.Name)
+			projectID := project(ctx, c, request)
+			if !ok || projectID == "" {
+				return next(ctx, request)
+			}
+			if reason := s.Get(ctx, c, projectID, false).Unavailable(e); reason != "" {
+				return mcp.NewToolResultError(fmt.Sprintf("%s is unavailable: %s. Once fixed, call probe_tools to make it available again.", request.Params.Name, reason)), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
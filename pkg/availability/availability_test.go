@@ -0,0 +1,153 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package availability
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func init() {
+	for name, permissions := range map[string][]string{
+		"get_cluster":    {"container.clusters.get"},
+		"list_backups":   {"gkebackup.backups.list"},
+		"health":         {"container.clusters.get", "monitoring.timeSeries.list"},
+		"list_projects":  {"resourcemanager.projects.get", "container.clusters.list"},
+		"search_secrets": {"container.secrets.list"},
+		"get_context":    nil,
+	} {
+		mcp.NewTool(name, catalog.Describe(catalog.Clusters, catalog.Read, permissions...))
+	}
+}
+
+func newTestStore(probes *int) *Store {
+	s := NewStore()
+	s.enabledServices = func(_ context.Context, _ *config.Config, _ string, services []string) (map[string]bool, error) {
+		*probes++
+		enabled := map[string]bool{}
+		for _, service := range services {
+			enabled[service] = service != "gkebackup.googleapis.com"
+		}
+		return enabled, nil
+	}
+	s.testPermissions = func(_ context.Context, _ *config.Config, _ string, permissions []string) ([]string, error) {
+		var held []string
+		for _, p := range permissions {
+			if p != "container.secrets.list" && p != "monitoring.timeSeries.list" {
+				held = append(held, p)
+			}
+		}
+		return held, nil
+	}
+	return s
+}
+
+func TestService(t *testing.T) {
+	for permission, want := range map[string]string{
+		"container.clusters.get":       "container.googleapis.com",
+		"resourcemanager.projects.get": "cloudresourcemanager.googleapis.com",
+	} {
+		if got := Service(permission); got != want {
+			t.Errorf("Service(%q) = %q, want %q", permission, got, want)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	probes := 0
+	s := newTestStore(&probes)
+	c := config.New("test")
+
+	p := s.Get(context.Background(), c, "my-project", false)
+	want := &Probe{
+		Project:  "my-project",
+		Disabled: []string{"gkebackup.googleapis.com"},
+		Denied:   []string{"container.secrets.list", "monitoring.timeSeries.list"},
+	}
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+	}
+
+	unavailable := map[string]bool{}
+	for name, e := range catalog.Entries() {
+		if p.Unavailable(e) != "" {
+			unavailable[name] = true
+		}
+	}
+	if diff := cmp.Diff(map[string]bool{"list_backups": true, "search_secrets": true}, unavailable); diff != "" {
+		t.Errorf("unavailable tools mismatch (-want +got):\n%s", diff)
+	}
+
+	s.Get(context.Background(), c, "my-project", false)
+	if probes != 1 {
+		t.Errorf("the project was probed %d times, want once", probes)
+	}
+	s.Get(context.Background(), c, "my-project", true)
+	if probes != 2 {
+		t.Errorf("the project was probed %d times after a refresh, want twice", probes)
+	}
+}
+
+func TestProbeErrorsKeepToolsAvailable(t *testing.T) {
+	s := NewStore()
+	s.enabledServices = func(context.Context, *config.Config, string, []string) (map[string]bool, error) {
+		return nil, errors.New("serviceusage.services.get denied")
+	}
+	s.testPermissions = func(context.Context, *config.Config, string, []string) ([]string, error) {
+		return nil, errors.New("unavailable")
+	}
+
+	p := s.Get(context.Background(), config.New("test"), "my-project", false)
+	if len(p.Errors) != 2 {
+		t.Errorf("Errors = %q, want both probes to fail", p.Errors)
+	}
+	for name, e := range catalog.Entries() {
+		if reason := p.Unavailable(e); reason != "" {
+			t.Errorf("%s is unavailable (%s), want every tool available when probes fail", name, reason)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	probes := 0
+	s := newTestStore(&probes)
+	next := func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("called"), nil
+	}
+	call := func(c *config.Config, tool string) string {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = tool
+		result, _ := s.Middleware(c)(next)(context.Background(), request)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	hiding := config.New("test", config.WithDefaultProjectID("my-project"), config.WithHideUnavailableTools(true))
+	if got := call(hiding, "get_cluster"); got != "called" {
+		t.Errorf("get_cluster = %q, want it called", got)
+	}
+	if got := call(hiding, "list_backups"); !strings.Contains(got, "the gkebackup.googleapis.com API it needs is disabled on project my-project") {
+		t.Errorf("list_backups = %q, want it rejected for the disabled API", got)
+	}
+	if got := call(config.New("test", config.WithDefaultProjectID("my-project")), "list_backups"); got != "called" {
+		t.Errorf("list_backups = %q, want it called when unavailable tools aren't hidden", got)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package budget bounds the memory tool calls may use and the size of their
+// results. A call that would run the server out of memory is stopped with an
+// error, and a huge result is cut down with a marker of what was omitted,
+// instead of the process being killed, which would end the client's session
+// with the stdio transport.
+//
+// Go doesn't account memory per goroutine, so the memory of a call is
+// approximated by how much the heap grows while it runs, which counts the
+// memory of the calls running at the same time too. That is only a fair
+// estimate when calls mostly run one at a time, as with the stdio transport,
+// so per-tool limits only apply to a server serving a single user. The
+// server-wide limit applies to every call running when it is reached,
+// whichever of them grew the heap.
+package budget
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/metrics"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/stream"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// pollInterval is how often the heap is measured while a call runs.
+var pollInterval = 100 * time.Millisecond
+
+// heapBytes returns the size of the objects in the heap.
+var heapBytes = func() int64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	return int64(sample[0].Value.Uint64())
+}
+
+// markerBytes is room left for the marker of omitted text.
+const markerBytes = 200
+
+type result struct {
+	result *mcp.CallToolResult
+	err    error
+}
+
+// Middleware returns a tool middleware that stops calls once the heap grows
+// above c.MemoryLimit, or by more than c.ToolMemoryLimit during the call if
+// the server serves a single user, and truncates results larger than
+// c.MaxResultBytes.
+func Middleware(c *config.Config) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			r, err := watch(ctx, request, c, next)
+			if r != nil {
+				r = Truncate(r, c.MaxResultBytes())
+			}
+			return r, err
+		}
+	}
+}
+
+// watch calls next, and stops the call if it uses too much memory. A stopped
+// call only returns once next does, so its memory is no longer held.
+func watch(ctx context.Context, request mcp.CallToolRequest, c *config.Config, next server.ToolHandlerFunc) (*mcp.CallToolResult, error) {
+	tool := request.Params.Name
+	limit, toolLimit := c.MemoryLimit(), c.ToolMemoryLimit(tool)
+	if !c.SingleUser() {
+		toolLimit = 0
+	}
+	if limit <= 0 && toolLimit <= 0 {
+		return next(ctx, request)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	start := heapBytes()
+	done := make(chan result, 1)
+	go func() {
+		r, err := next(ctx, request)
+		done <- result{r, err}
+	}()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case r := <-done:
+			return r.result, r.err
+		case <-ticker.C:
+		}
+		heap := heapBytes()
+		var exceeded string
+		switch {
+		case limit > 0 && heap > limit:
+			exceeded = fmt.Sprintf("the server's memory reached %s, above its limit of %s", mib(heap), mib(limit))
+		case toolLimit > 0 && heap-start > toolLimit:
+			exceeded = fmt.Sprintf("it used about %s of memory, above the limit of %s for %s", mib(heap-start), mib(toolLimit), tool)
+		default:
+			continue
+		}
+		slog.Warn("Stopped a tool call using too much memory", "tool", tool, "heap_bytes", heap, "start_heap_bytes", start)
+		cancel(fmt.Errorf("%s was stopped because %s", tool, exceeded))
+		<-done
+		return mcp.NewToolResultError(fmt.Sprintf("%s was stopped because %s. Narrow the request, e.g. with a more specific filter, a shorter time range or fewer projects, or set %s=true if the tool supports streaming.", tool, exceeded, stream.Argument)), nil
+	}
+}
+
+func mib(bytes int64) string {
+	return fmt.Sprintf("%d MiB", bytes>>20)
+}
+
+// Truncate cuts the content of result down to about maxBytes, or returns it
+// unchanged if it is smaller or maxBytes is 0. The structured result is
+// dropped first, as it can't be cut and repeats the text. Texts are then cut
+// in proportion to their size, keeping their beginning and end around a
+// marker of the omitted bytes, and other content too large to keep is
+// replaced by a marker.
+func Truncate(result *mcp.CallToolResult, maxBytes int) *mcp.CallToolResult {
+	if maxBytes <= 0 || size(result.Content) <= maxBytes {
+		return result
+	}
+	var contents []mcp.Content
+	for _, content := range result.Content {
+		if structured.IsStructured(content) {
+			delete(result.Meta, structured.SchemaMetaField)
+			continue
+		}
+		contents = append(contents, content)
+	}
+	if total := size(contents); total > maxBytes {
+		for i, content := range contents {
+			share := int(int64(maxBytes) * int64(contentSize(content)) / int64(total))
+			if text, ok := content.(mcp.TextContent); ok {
+				text.Text = cut(text.Text, share)
+				contents[i] = text
+			} else if contentSize(content) > share {
+				contents[i] = mcp.NewTextContent(fmt.Sprintf("[%d bytes of %s content omitted: the result exceeds the server's limit of %d bytes.]", contentSize(content), contentType(content), maxBytes))
+			}
+		}
+	}
+	result.Content = contents
+	return result
+}
+
+func size(contents []mcp.Content) int {
+	total := 0
+	for _, content := range contents {
+		total += contentSize(content)
+	}
+	return total
+}
+
+func contentSize(content mcp.Content) int {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return len(c.Text)
+	case mcp.ImageContent:
+		return len(c.Data)
+	case mcp.AudioContent:
+		return len(c.Data)
+	case mcp.EmbeddedResource:
+		switch r := c.Resource.(type) {
+		case mcp.TextResourceContents:
+			return len(r.Text)
+		case mcp.BlobResourceContents:
+			return len(r.Blob)
+		}
+	}
+	return 0
+}
+
+func contentType(content mcp.Content) string {
+	switch content.(type) {
+	case mcp.ImageContent:
+		return "image"
+	case mcp.AudioContent:
+		return "audio"
+	}
+	return "resource"
+}
+
+// cut cuts text down to about maxBytes, keeping its first and last lines
+// around a marker of the omitted bytes.
+func cut(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	budget := max(maxBytes-markerBytes, 0)
+	head := lineBoundary(text, budget*3/4, false)
+	tail := lineBoundary(text, len(text)-(budget-head), true)
+	return fmt.Sprintf("%s\n[... %d of %d bytes omitted: the result exceeds the server's limit. Narrow the request to see them ...]\n%s",
+		strings.TrimSuffix(text[:head], "\n"), tail-head, len(text), text[tail:])
+}
+
+// lineBoundary returns the start of the line i is in, or of the next line if
+// next is set, falling back to the character boundary closest to i when
+// lines are too long.
+func lineBoundary(text string, i int, next bool) int {
+	i = min(max(i, 0), len(text))
+	if next {
+		if j := strings.IndexByte(text[i:], '\n'); j >= 0 && j < len(text[i:])/2 {
+			return i + j + 1
+		}
+		for i < len(text) && !utf8.RuneStart(text[i]) {
+			i++
+		}
+		return i
+	}
+	if j := strings.LastIndexByte(text[:i], '\n'); j >= i/2 {
+		return j + 1
+	}
+	for i > 0 && i < len(text) && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	return i
+}
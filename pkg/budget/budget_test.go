@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestTruncate(t *testing.T) {
+	var lines []string
+	for i := range 1000 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	text := strings.Join(lines, "\n")
+
+	small := mcp.NewToolResultText("ok")
+	if got := Truncate(small, 100); got.Content[0].(mcp.TextContent).Text != "ok" {
+		t.Errorf("Truncate() changed a small result: %+v", got)
+	}
+
+	result, err := structured.Result(text, structured.Clusters, map[string]any{"text": text})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Truncate(result, 2000)
+	if len(got.Content) != 1 || got.Meta[structured.SchemaMetaField] != nil {
+		t.Fatalf("Truncate() kept the structured result: %+v", got)
+	}
+	cut := got.Content[0].(mcp.TextContent).Text
+	if len(cut) > 2000 {
+		t.Errorf("Truncate() returned %d bytes, want at most 2000", len(cut))
+	}
+	if !strings.HasPrefix(cut, "line 0\nline 1\n") || !strings.HasSuffix(cut, "\nline 999") {
+		t.Errorf("Truncate() didn't keep the first and last lines:\n%s", cut)
+	}
+	if !strings.Contains(cut, "bytes omitted") {
+		t.Errorf("Truncate() didn't mark the omitted text:\n%s", cut)
+	}
+	for _, line := range strings.Split(cut, "\n") {
+		if !strings.HasPrefix(line, "line ") && !strings.HasPrefix(line, "[... ") {
+			t.Errorf("Truncate() cut a line: %q", line)
+		}
+	}
+}
+
+func TestCutKeepsCharacters(t *testing.T) {
+	text := strings.Repeat("é", 1000)
+	got := cut(text, 500)
+	if !strings.HasPrefix(got, "é") || !strings.HasSuffix(got, "é") || strings.ContainsRune(got, '�') {
+		t.Errorf("cut() split a character: %q", got)
+	}
+	if len(got) > 500 {
+		t.Errorf("cut() returned %d bytes, want at most 500", len(got))
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	defer func(old func() int64, interval time.Duration) { heapBytes, pollInterval = old, interval }(heapBytes, pollInterval)
+	pollInterval = time.Millisecond
+	var heap atomic.Int64
+	heapBytes = heap.Load
+
+	c := config.New("test", config.WithToolMemoryLimit("hungry", 100<<20), config.WithMaxResultBytes(1000))
+	stopped := make(chan error, 1)
+	hungry := Middleware(c)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		heap.Add(200 << 20)
+		<-ctx.Done()
+		stopped <- context.Cause(ctx)
+		return mcp.NewToolResultText("done"), nil
+	})
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "hungry"
+	result, err := hungry(context.Background(), request)
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "200 MiB of memory, above the limit of 100 MiB") {
+		t.Errorf("hungry call returned %+v, %v, want an error about its memory", result, err)
+	}
+	select {
+	case cause := <-stopped:
+		if cause == nil || !strings.Contains(cause.Error(), "hungry was stopped") {
+			t.Errorf("hungry call canceled with cause %v, want its memory", cause)
+		}
+	default:
+		t.Error("hungry call returned before its handler did")
+	}
+
+	// Over HTTP, concurrent calls of other users make the growth of the heap
+	// meaningless for a single call.
+	shared := config.New("test", config.WithServerMode("http"), config.WithToolMemoryLimit("hungry", 100<<20))
+	result, err = Middleware(shared)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		heap.Add(200 << 20)
+		time.Sleep(10 * pollInterval)
+		return mcp.NewToolResultText("done"), nil
+	})(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Errorf("hungry call over HTTP returned %+v, %v, want it to complete", result, err)
+	}
+
+	verbose := Middleware(c)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(strings.Repeat("x\n", 1000)), nil
+	})
+	request.Params.Name = "verbose"
+	result, err = verbose(context.Background(), request)
+	if err != nil || len(result.Content[0].(mcp.TextContent).Text) > 1000 {
+		t.Errorf("verbose call returned %+v, %v, want a truncated result", result, err)
+	}
+}
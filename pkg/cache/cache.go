@@ -0,0 +1,200 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache keeps slow, frequently repeated GCP API reads for a short time.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/telemetry"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RefreshArgument is the name of the tool argument that bypasses the cache.
+const RefreshArgument = "refresh"
+
+// RefreshOption declares the refresh argument on a tool that serves cached
+// responses.
+func RefreshOption() mcp.ToolOption {
+	return mcp.WithBoolean(RefreshArgument, mcp.Description("Bypass the cache and fetch fresh data. Only set it if the user expects very recent changes to be visible."))
+}
+
+// Note describes the age of a cached response, or returns "" if it was just
+// fetched.
+func Note(fetchedAt time.Time) string {
+	age := time.Since(fetchedAt)
+	if age < time.Second {
+		return ""
+	}
+	return fmt.Sprintf("(Cached response from %s ago. Call again with %s=true for fresh data.)", age.Round(time.Second), RefreshArgument)
+}
+
+// Cache is a TTL cache of values of type V, safe for concurrent use.
+type Cache[V any] struct {
+	name string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]entry[V]
+}
+
+type entry[V any] struct {
+	value     V
+	fetchedAt time.Time
+	expires   time.Time
+}
+
+// New returns an empty cache. name identifies it in metrics.
+func New[V any](name string) *Cache[V] {
+	return &Cache[V]{
+		name:    name,
+		now:     time.Now,
+		entries: map[string]entry[V]{},
+	}
+}
+
+// Get returns the value cached under key if it is younger than ttl, and
+// otherwise calls fetch and caches its result. refresh forces a fetch. It
+// also returns when the value was fetched. Errors are never cached.
+func (c *Cache[V]) Get(ctx context.Context, key string, ttl time.Duration, refresh bool, fetch func(context.Context) (V, error)) (V, time.Time, error) {
+	now := c.now()
+	if !refresh && ttl > 0 {
+		c.mu.Lock()
+		e, ok := c.entries[key]
+		c.mu.Unlock()
+		if ok && now.Before(e.expires) {
+			telemetry.RecordCacheLookup(c.name, true)
+			return e.value, e.fetchedAt, nil
+		}
+	}
+	telemetry.RecordCacheLookup(c.name, false)
+
+	v, err := fetch(ctx)
+	if err != nil {
+		return v, time.Time{}, err
+	}
+	if ttl > 0 {
+		c.set(key, entry[V]{value: v, fetchedAt: now, expires: now.Add(ttl)})
+	}
+	return v, now, nil
+}
+
+func (c *Cache[V]) set(key string, e entry[V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Drop expired entries so the cache doesn't grow without bound.
+	for k, old := range c.entries {
+		if !e.fetchedAt.Before(old.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = e
+}
+
+// Invalidate drops every entry whose key starts with prefix, e.g. after a
+// tool changes the resources they describe.
+func (c *Cache[V]) Invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// persistedEntry is the persisted form of a cache entry.
+type persistedEntry struct {
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Expires   time.Time       `json:"expires"`
+}
+
+// Persister persists the entries of a cache across restarts. It implements
+// state.Component.
+type Persister[V any] struct {
+	c      *Cache[V]
+	encode func(V) ([]byte, error)
+	decode func([]byte) (V, error)
+}
+
+// Persister returns a state component persisting the unexpired entries of c,
+// with values encoded as JSON by encode and decode.
+func (c *Cache[V]) Persister(encode func(V) ([]byte, error), decode func([]byte) (V, error)) *Persister[V] {
+	return &Persister[V]{c: c, encode: encode, decode: decode}
+}
+
+// Snapshot returns the unexpired entries, or nil if there are none.
+func (p *Persister[V]) Snapshot() (json.RawMessage, error) {
+	return p.SnapshotMatching(func(string) bool { return true })
+}
+
+// SnapshotMatching returns the unexpired entries whose key matches, or nil if
+// there are none.
+func (p *Persister[V]) SnapshotMatching(match func(key string) bool) (json.RawMessage, error) {
+	now := p.c.now()
+	p.c.mu.Lock()
+	defer p.c.mu.Unlock()
+	var entries []persistedEntry
+	for _, key := range slices.Sorted(maps.Keys(p.c.entries)) {
+		e := p.c.entries[key]
+		if !now.Before(e.expires) || !match(key) {
+			continue
+		}
+		value, err := p.encode(e.value)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, persistedEntry{Key: key, Value: value, FetchedAt: e.fetchedAt, Expires: e.expires})
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(entries)
+}
+
+// Restore replaces the entries with a snapshot, skipping expired ones.
+func (p *Persister[V]) Restore(data json.RawMessage) error {
+	var entries []persistedEntry
+	if data != nil {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+	}
+	now := p.c.now()
+	restored := map[string]entry[V]{}
+	for _, e := range entries {
+		if !now.Before(e.Expires) {
+			continue
+		}
+		value, err := p.decode(e.Value)
+		if err != nil {
+			return err
+		}
+		restored[e.Key] = entry[V]{value: value, fetchedAt: e.FetchedAt, expires: e.Expires}
+	}
+	p.c.mu.Lock()
+	defer p.c.mu.Unlock()
+	p.c.entries = restored
+	return nil
+}
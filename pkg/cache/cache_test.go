@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	c := New[int]("test")
+	c.now = func() time.Time { return now }
+
+	fetches := 0
+	fetch := func(context.Context) (int, error) {
+		fetches++
+		return fetches, nil
+	}
+	get := func(refresh bool) int {
+		t.Helper()
+		v, _, err := c.Get(ctx, "key", time.Minute, refresh, fetch)
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		return v
+	}
+
+	if got := get(false); got != 1 {
+		t.Errorf("first Get() = %d, want 1", got)
+	}
+	now = now.Add(30 * time.Second)
+	if got := get(false); got != 1 {
+		t.Errorf("Get() within TTL = %d, want cached 1", got)
+	}
+	if got := get(true); got != 2 {
+		t.Errorf("Get() with refresh = %d, want 2", got)
+	}
+	now = now.Add(2 * time.Minute)
+	if got := get(false); got != 3 {
+		t.Errorf("Get() after TTL = %d, want 3", got)
+	}
+
+	c.Invalidate("ke")
+	if got := get(false); got != 4 {
+		t.Errorf("Get() after Invalidate() = %d, want 4", got)
+	}
+}
+
+func TestGetDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	c := New[int]("test")
+
+	if _, _, err := c.Get(ctx, "key", time.Minute, false, func(context.Context) (int, error) {
+		return 0, errors.New("boom")
+	}); err == nil {
+		t.Fatalf("Get() succeeded, want error")
+	}
+	v, _, err := c.Get(ctx, "key", time.Minute, false, func(context.Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil || v != 42 {
+		t.Errorf("Get() after an error = %d, %v; want 42, nil", v, err)
+	}
+}
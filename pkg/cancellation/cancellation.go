@@ -0,0 +1,191 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cancellation honors MCP request cancellation: when a client sends
+// notifications/cancelled for a tool call, the context of the call is
+// cancelled, so the tool stops waiting and can cancel the GCP operations it
+// started.
+package cancellation
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Method is the method of cancellation notifications.
+const Method = "notifications/cancelled"
+
+// ErrCancelled is the cause of the context of cancelled tool calls.
+var ErrCancelled = errors.New("the client cancelled the request")
+
+// requestIDField is the field of the request metadata the request ID is
+// passed in from BeforeCallToolHook to Middleware.
+const requestIDField = "gke-mcp/requestId"
+
+// Registry holds the cancel functions of in-flight tool calls.
+type Registry struct {
+	mu      sync.Mutex
+	cancels map[call]context.CancelCauseFunc
+}
+
+// call identifies a tool call by its session and request ID.
+type call struct {
+	sessionID string
+	requestID string
+}
+
+// Default is the registry used by the server.
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{cancels: map[call]context.CancelCauseFunc{}}
+}
+
+// requestID normalizes a JSON-RPC request ID. IDs may be numbers or strings,
+// and numbers parse differently in requests and notifications.
+func requestID(id any) string {
+	if rid, ok := id.(mcp.RequestId); ok {
+		id = rid.Value()
+	}
+	return mcp.NewRequestId(id).String()
+}
+
+func sessionID(ctx context.Context) string {
+	if s := server.ClientSessionFromContext(ctx); s != nil {
+		return s.SessionID()
+	}
+	return ""
+}
+
+// BeforeCallToolHook passes the ID of a tool call to Middleware, which
+// doesn't get it otherwise.
+func (r *Registry) BeforeCallToolHook(_ context.Context, id any, request *mcp.CallToolRequest) {
+	if request.Params.Meta == nil {
+		request.Params.Meta = &mcp.Meta{}
+	}
+	if request.Params.Meta.AdditionalFields == nil {
+		request.Params.Meta.AdditionalFields = map[string]any{}
+	}
+	request.Params.Meta.AdditionalFields[requestIDField] = id
+}
+
+// Middleware makes tool calls cancellable by the client.
+func (r *Registry) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.Params.Meta == nil {
+			return next(ctx, request)
+		}
+		id, ok := request.Params.Meta.AdditionalFields[requestIDField]
+		if !ok {
+			return next(ctx, request)
+		}
+		delete(request.Params.Meta.AdditionalFields, requestIDField)
+
+		key := call{sessionID: sessionID(ctx), requestID: requestID(id)}
+		ctx, cancel := context.WithCancelCause(ctx)
+		r.mu.Lock()
+		r.cancels[key] = cancel
+		r.mu.Unlock()
+		defer func() {
+			r.mu.Lock()
+			delete(r.cancels, key)
+			r.mu.Unlock()
+			cancel(nil)
+		}()
+		return next(ctx, request)
+	}
+}
+
+// Cancel cancels the tool call with the given request ID in a session, or
+// in any session if sessionID is "". It reports whether it found the call.
+func (r *Registry) Cancel(sessionID string, id any, reason string) bool {
+	cause := ErrCancelled
+	if reason != "" {
+		cause = fmt.Errorf("%w: %s", ErrCancelled, reason)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	found := false
+	for c, cancel := range r.cancels {
+		if c.requestID == requestID(id) && (sessionID == "" || c.sessionID == sessionID) {
+			cancel(cause)
+			found = true
+		}
+	}
+	return found
+}
+
+// cancelledParams returns the ID and reason of a cancellation notification.
+func cancelledParams(params map[string]any) (id any, reason string, ok bool) {
+	id, ok = params["requestId"]
+	if !ok {
+		return nil, "", false
+	}
+	reason, _ = params["reason"].(string)
+	return id, reason, true
+}
+
+// NotificationHandler cancels the tool calls named by the cancellation
+// notifications of clients. Register it for Method.
+func (r *Registry) NotificationHandler(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, reason, ok := cancelledParams(notification.Params.AdditionalFields)
+	if !ok {
+		return
+	}
+	if r.Cancel(sessionID(ctx), id, reason) {
+		slog.Info("Cancelled a tool call at the request of the client", "request_id", id, "reason", reason)
+	}
+}
+
+// InterceptStdio returns a reader of in that cancels the tool calls named by
+// the cancellation notifications it reads as soon as they arrive. The stdio
+// transport handles one message at a time, so it would only read them once
+// the call they cancel is over.
+func (r *Registry) InterceptStdio(in io.Reader) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				var message struct {
+					Method string         `json:"method"`
+					Params map[string]any `json:"params"`
+				}
+				if json.Unmarshal(line, &message) == nil && message.Method == Method {
+					if id, reason, ok := cancelledParams(message.Params); ok && r.Cancel("", id, reason) {
+						slog.Info("Cancelled a tool call at the request of the client", "request_id", id, "reason", reason)
+					}
+				}
+				if _, werr := pw.Write(line); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cancellation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newServer returns a server with a tool that blocks until its call is
+// cancelled, and sends the cause of the cancellation to causes.
+func newServer(r *Registry, causes chan<- error) *server.MCPServer {
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(r.BeforeCallToolHook)
+	s := server.NewMCPServer("test", "0.0.0",
+		server.WithToolCapabilities(true),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(r.Middleware),
+	)
+	s.AddTool(mcp.NewTool("block"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return mcp.NewToolResultText("cancelled"), nil
+	})
+	return s
+}
+
+// waitForCall waits until the registry tracks a tool call.
+func waitForCall(t *testing.T, r *Registry) {
+	t.Helper()
+	for range 100 {
+		r.mu.Lock()
+		n := len(r.cancels)
+		r.mu.Unlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the tool call never started")
+}
+
+func TestNotificationHandler(t *testing.T) {
+	r := NewRegistry()
+	causes := make(chan error, 1)
+	s := newServer(r, causes)
+	go s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"block"}}`))
+	waitForCall(t, r)
+
+	// Cancelling another request does nothing.
+	if r.Cancel("", 8, "") {
+		t.Errorf("Cancel() of an unknown request found a call")
+	}
+	notification := mcp.JSONRPCNotification{}
+	notification.Method = Method
+	notification.Params.AdditionalFields = map[string]any{"requestId": float64(7), "reason": "user aborted"}
+	r.NotificationHandler(context.Background(), notification)
+
+	select {
+	case cause := <-causes:
+		if !errors.Is(cause, ErrCancelled) || !strings.Contains(cause.Error(), "user aborted") {
+			t.Errorf("cause of the cancellation = %v, want ErrCancelled with the reason", cause)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the tool call wasn't cancelled")
+	}
+}
+
+func TestInterceptStdio(t *testing.T) {
+	r := NewRegistry()
+	causes := make(chan error, 1)
+	s := newServer(r, causes)
+	go s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"block"}}`))
+	waitForCall(t, r)
+
+	input := `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"a"}}` + "\n"
+	got, err := io.ReadAll(r.InterceptStdio(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("reading the intercepted input failed: %v", err)
+	}
+	if string(got) != input {
+		t.Errorf("intercepted input = %q, want it unchanged: %q", got, input)
+	}
+	select {
+	case cause := <-causes:
+		if !errors.Is(cause, ErrCancelled) {
+			t.Errorf("cause of the cancellation = %v, want ErrCancelled", cause)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the tool call wasn't cancelled")
+	}
+}
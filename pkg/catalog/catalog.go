@@ -0,0 +1,147 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package catalog describes what the tools of the server do: their category,
+// the kind of effect they have, which sets their MCP annotations
+// consistently, and the IAM permissions they need.
+//
+// Every tool declares its entry with Describe instead of setting annotations
+// one by one.
+package catalog
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Categories of tools.
+const (
+	Clusters      = "clusters"
+	Fleet         = "fleet"
+	Observability = "observability"
+	Optimization  = "optimization"
+	Security      = "security"
+	AI            = "ai"
+	Guidance      = "guidance"
+	Server        = "server"
+)
+
+// Categories lists the categories in the order they are presented.
+var Categories = []string{Clusters, Fleet, Observability, Optimization, Security, AI, Guidance, Server}
+
+// Kind is the kind of effect a tool has.
+type Kind string
+
+const (
+	// Read reads GCP resources or other external data.
+	Read Kind = "read"
+	// Query reads external data that keeps changing, e.g. logs, so repeated
+	// calls return different results.
+	Query Kind = "query"
+	// Local reads or adjusts the server's own state, e.g. the session
+	// context, without touching any resource.
+	Local Kind = "local"
+	// Write creates or updates resources.
+	Write Kind = "write"
+	// Delete deletes resources or data.
+	Delete Kind = "delete"
+	// Deploy rolls workloads out or back, replacing what runs, so it is
+	// confirmed like a deletion.
+	Deploy Kind = "deploy"
+	// Dismiss mutes or resolves findings or alerts, hiding them from
+	// everyone, so it is confirmed like a deletion.
+	Dismiss Kind = "dismiss"
+)
+
+// annotations are the MCP annotations of each kind: read-only, destructive,
+// idempotent and open-world.
+var annotations = map[Kind][4]bool{
+	Read:    {true, false, true, true},
+	Query:   {true, false, false, true},
+	Local:   {true, false, true, false},
+	Write:   {false, false, false, true},
+	Delete:  {false, true, true, true},
+	Deploy:  {false, true, false, true},
+	Dismiss: {false, true, true, true},
+}
+
+// Entry describes a tool.
+type Entry struct {
+	Category    string   `json:"category"`
+	Kind        Kind     `json:"kind"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+var (
+	mu      sync.Mutex
+	entries = map[string]Entry{}
+)
+
+// Describe declares the category and kind of a tool, and the IAM
+// permissions it needs on the project or scope it acts on. It sets the
+// tool's annotations according to its kind, overriding any set before.
+func Describe(category string, kind Kind, permissions ...string) mcp.ToolOption {
+	hints, ok := annotations[kind]
+	if !ok {
+		panic(fmt.Sprintf("unknown tool kind %q", kind))
+	}
+	if !slices.Contains(Categories, category) {
+		panic(fmt.Sprintf("unknown tool category %q", category))
+	}
+	return func(t *mcp.Tool) {
+		t.Annotations.ReadOnlyHint = mcp.ToBoolPtr(hints[0])
+		t.Annotations.DestructiveHint = mcp.ToBoolPtr(hints[1])
+		t.Annotations.IdempotentHint = mcp.ToBoolPtr(hints[2])
+		t.Annotations.OpenWorldHint = mcp.ToBoolPtr(hints[3])
+		mu.Lock()
+		defer mu.Unlock()
+		entries[t.Name] = Entry{Category: category, Kind: kind, Permissions: permissions}
+	}
+}
+
+// Lookup returns the entry of a tool.
+func Lookup(name string) (Entry, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	e, ok := entries[name]
+	return e, ok
+}
+
+// Entries returns the entries of every described tool, by tool name.
+func Entries() map[string]Entry {
+	mu.Lock()
+	defer mu.Unlock()
+	return maps.Clone(entries)
+}
+
+// Check makes sure a tool was described, and that its annotations weren't
+// changed afterwards.
+func Check(tool mcp.Tool) error {
+	e, ok := Lookup(tool.Name)
+	if !ok {
+		return fmt.Errorf("tool %q is not described in the catalog", tool.Name)
+	}
+	hints := annotations[e.Kind]
+	a := tool.Annotations
+	for i, hint := range []*bool{a.ReadOnlyHint, a.DestructiveHint, a.IdempotentHint, a.OpenWorldHint} {
+		if hint == nil || *hint != hints[i] {
+			return fmt.Errorf("the annotations of tool %q don't match its kind %s", tool.Name, e.Kind)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clientrequest exchanges the requests the MCP library the server is
+// built on doesn't support with the client: it sends requests to the client,
+// e.g. to elicit input from the user or sample the client's model, and
+// answers requests of methods the library doesn't know, e.g. completions.
+//
+// The Transport does so by intercepting the stdio transport. Over other
+// transports these requests aren't supported.
+package clientrequest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// idPrefix marks the IDs of the requests the Transport sends, to tell their
+// responses apart from client requests.
+const idPrefix = "gke-mcp-"
+
+// Handler answers a request from the client.
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+type handler struct {
+	capability string
+	h          Handler
+}
+
+// Transport exchanges requests with the client.
+type Transport struct {
+	mu           sync.Mutex
+	out          io.Writer
+	capabilities map[string]json.RawMessage
+	initID       json.RawMessage
+	nextID       int
+	pending      map[string]chan response
+	handlers     map[string]handler
+}
+
+// Default is the transport used by the server.
+var Default = New()
+
+func New() *Transport {
+	return &Transport{pending: map[string]chan response{}, handlers: map[string]handler{}}
+}
+
+// Handle makes the Transport answer the client's requests for method with
+// h, and declare capability to the client.
+func (t *Transport) Handle(method, capability string, h Handler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers[method] = handler{capability, h}
+}
+
+// output serializes the messages written by the stdio transport and by the
+// Transport, and adds the capabilities of the handlers to the response to
+// the initialize request.
+type output struct {
+	t  *Transport
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (o output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if line := o.t.declareCapabilities(p); line != nil {
+		if _, err := o.w.Write(line); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return o.w.Write(p)
+}
+
+// declareCapabilities returns line with the capabilities of the handlers
+// added if it is the response to the initialize request, and otherwise nil.
+func (t *Transport) declareCapabilities(line []byte) []byte {
+	t.mu.Lock()
+	initID := t.initID
+	var capabilities []string
+	for _, h := range t.handlers {
+		capabilities = append(capabilities, h.capability)
+	}
+	t.mu.Unlock()
+	if initID == nil || len(capabilities) == 0 {
+		return nil
+	}
+	var m map[string]json.RawMessage
+	if json.Unmarshal(line, &m) != nil || !bytes.Equal(m["id"], initID) || m["result"] == nil {
+		return nil
+	}
+	var result map[string]any
+	if json.Unmarshal(m["result"], &result) != nil {
+		return nil
+	}
+	declared, _ := result["capabilities"].(map[string]any)
+	if declared == nil {
+		declared = map[string]any{}
+	}
+	for _, capability := range capabilities {
+		declared[capability] = map[string]any{}
+	}
+	result["capabilities"] = declared
+	patched, err := json.Marshal(result)
+	if err != nil {
+		return nil
+	}
+	m["result"] = patched
+	out, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	t.mu.Lock()
+	t.initID = nil
+	t.mu.Unlock()
+	return append(out, '\n')
+}
+
+// message is a JSON-RPC message read from the client.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		Capabilities map[string]json.RawMessage `json:"capabilities"`
+	} `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type response struct {
+	result json.RawMessage
+	err    error
+}
+
+// InterceptStdio returns the input and output the stdio transport should
+// use instead of in and out, so the Transport can send requests to the
+// client and read their responses.
+func (t *Transport) InterceptStdio(in io.Reader, out io.Writer) (io.Reader, io.Writer) {
+	var writeMu sync.Mutex
+	t.mu.Lock()
+	t.out = output{t, &writeMu, out}
+	t.mu.Unlock()
+
+	pr, pw := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 && !t.handle(line) {
+				if _, werr := pw.Write(line); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr, output{t, &writeMu, out}
+}
+
+// handle looks at a message from the client, and reports whether the
+// Transport handled it: a response to one of its requests, or a request it
+// answers. The stdio transport mustn't see those.
+func (t *Transport) handle(line []byte) bool {
+	var m message
+	if json.Unmarshal(line, &m) != nil {
+		return false
+	}
+	if m.Method == string(mcp.MethodInitialize) {
+		t.mu.Lock()
+		t.capabilities = m.Params.Capabilities
+		t.initID = m.ID
+		t.mu.Unlock()
+		return false
+	}
+	t.mu.Lock()
+	h, ok := t.handlers[m.Method]
+	t.mu.Unlock()
+	if ok && m.ID != nil {
+		go t.answer(m.ID, h.h, line)
+		return true
+	}
+	var id string
+	if m.Method != "" || json.Unmarshal(m.ID, &id) != nil || !strings.HasPrefix(id, idPrefix) {
+		return false
+	}
+	t.mu.Lock()
+	ch, ok := t.pending[id]
+	delete(t.pending, id)
+	t.mu.Unlock()
+	if ok {
+		if m.Error != nil {
+			ch <- response{err: fmt.Errorf("the client failed the request: %s (code %d)", m.Error.Message, m.Error.Code)}
+		} else {
+			ch <- response{result: m.Result}
+		}
+	}
+	return true
+}
+
+// answer answers the request in line, of the given ID, with h.
+func (t *Transport) answer(id json.RawMessage, h Handler, line []byte) {
+	var request struct {
+		Params json.RawMessage `json:"params"`
+	}
+	json.Unmarshal(line, &request)
+	response := map[string]any{"jsonrpc": mcp.JSONRPC_VERSION, "id": id}
+	if result, err := h(context.Background(), request.Params); err != nil {
+		response["error"] = map[string]any{"code": mcp.INVALID_PARAMS, "message": err.Error()}
+	} else {
+		response["result"] = result
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	out := t.out
+	t.mu.Unlock()
+	out.Write(append(data, '\n'))
+}
+
+// Supports reports whether the client declared capability, e.g. elicitation
+// or sampling.
+func (t *Transport) Supports(capability string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.capabilities[capability]
+	return t.out != nil && ok
+}
+
+// stdioSessionID is the ID the MCP library gives the session of the stdio
+// transport.
+const stdioSessionID = "stdio"
+
+// SupportedBy reports whether the client calling with ctx declared
+// capability. Only the client of the stdio transport, which the Transport
+// intercepts, can have; the sessions of other transports never do.
+func (t *Transport) SupportedBy(ctx context.Context, capability string) bool {
+	s := server.ClientSessionFromContext(ctx)
+	return s != nil && s.SessionID() == stdioSessionID && t.Supports(capability)
+}
+
+// Request sends a request to the client and returns the result of its
+// response.
+func (t *Transport) Request(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	t.mu.Lock()
+	if t.out == nil {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("requests to the client are only supported over stdio")
+	}
+	t.nextID++
+	id := fmt.Sprintf("%s%d", idPrefix, t.nextID)
+	ch := make(chan response, 1)
+	t.pending[id] = ch
+	out := t.out
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+	}()
+
+	request, err := json.Marshal(map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := out.Write(append(request, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to send the %s request: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		return r.result, r.err
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientrequest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHandle(t *testing.T) {
+	tr := New()
+	tr.Handle("example/echo", "echo", func(_ context.Context, params json.RawMessage) (any, error) {
+		if string(params) == `{"fail":true}` {
+			return nil, errors.New("failed")
+		}
+		return map[string]json.RawMessage{"echoed": params}, nil
+	})
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer inW.Close()
+	defer outR.Close()
+	forwarded, out := tr.InterceptStdio(inR, outW)
+	fromServer := bufio.NewReader(outR)
+	toServer := bufio.NewReader(forwarded)
+
+	io.WriteString(inW, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":{}}}`+"\n")
+	if line, err := toServer.ReadString('\n'); err != nil || !strings.Contains(line, "initialize") {
+		t.Fatalf("forwarded %q, %v; want the initialize request", line, err)
+	}
+	// The response to the initialize request declares the capability.
+	go io.WriteString(out, `{"jsonrpc":"2.0","id":1,"result":{"capabilities":{"tools":{}}}}`+"\n")
+	line, err := fromServer.ReadString('\n')
+	if err != nil || !strings.Contains(line, `"echo":{}`) || !strings.Contains(line, `"tools":{}`) {
+		t.Errorf("initialize response = %q, %v; want it to declare the echo and tools capabilities", line, err)
+	}
+
+	for _, tc := range []struct {
+		request, want string
+	}{
+		{`{"jsonrpc":"2.0","id":2,"method":"example/echo","params":{"a":1}}`, `"result":{"echoed":{"a":1}}`},
+		{`{"jsonrpc":"2.0","id":3,"method":"example/echo","params":{"fail":true}}`, `"message":"failed"`},
+	} {
+		io.WriteString(inW, tc.request+"\n")
+		if line, err := fromServer.ReadString('\n'); err != nil || !strings.Contains(line, tc.want) {
+			t.Errorf("response to %s = %q, %v; want it to contain %s", tc.request, line, err, tc.want)
+		}
+	}
+
+	// Other requests are forwarded.
+	io.WriteString(inW, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`+"\n")
+	if line, err := toServer.ReadString('\n'); err != nil || !strings.Contains(line, "tools/list") {
+		t.Errorf("forwarded %q, %v; want the tools/list request", line, err)
+	}
+}
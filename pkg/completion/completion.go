@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package completion completes the arguments of prompts and the variables of
+// resource templates, e.g. project IDs and cluster names, so clients can
+// offer autocompletion.
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Method is the method of completion requests.
+const Method = "completion/complete"
+
+// Capability is the server capability of completions.
+const Capability = "completions"
+
+const (
+	// maxValues is the maximum number of values of a completion.
+	maxValues = 100
+	// timeout bounds how long completers may take, as users are typing.
+	timeout = 5 * time.Second
+)
+
+// Completer returns the values of an argument, given the values of the
+// other arguments filled in so far. The values are filtered by the prefix
+// the user typed afterwards. Completers may return values along with an
+// error, e.g. defaults when an API can't be reached.
+type Completer func(ctx context.Context, args map[string]string) ([]string, error)
+
+// Values returns a completer of fixed values.
+func Values(values ...string) Completer {
+	return func(context.Context, map[string]string) ([]string, error) {
+		return values, nil
+	}
+}
+
+// Registry holds the completers of arguments, by argument name.
+type Registry struct {
+	mu         sync.Mutex
+	completers map[string]Completer
+}
+
+// Default is the registry used by the server.
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{completers: map[string]Completer{}}
+}
+
+// Register makes completer complete the arguments with the given names,
+// wherever they appear.
+func (r *Registry) Register(completer Completer, names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		r.completers[name] = completer
+	}
+}
+
+// params are the parameters of a completion request. The context was added
+// in protocol version 2025-06-18.
+type params struct {
+	mcp.CompleteParams
+	Context struct {
+		Arguments map[string]string `json:"arguments"`
+	} `json:"context"`
+}
+
+// Handle answers a completion request. It is a clientrequest.Handler.
+func (r *Registry) Handle(ctx context.Context, raw json.RawMessage) (any, error) {
+	var p params
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("invalid completion request: %w", err)
+	}
+	return r.Complete(ctx, p.Argument.Name, p.Argument.Value, p.Context.Arguments), nil
+}
+
+// Complete returns the values of the argument name starting with prefix,
+// ignoring case. Arguments without a completer have no values.
+func (r *Registry) Complete(ctx context.Context, name, prefix string, args map[string]string) *mcp.CompleteResult {
+	result := &mcp.CompleteResult{}
+	result.Completion.Values = []string{}
+	r.mu.Lock()
+	completer, ok := r.completers[name]
+	r.mu.Unlock()
+	if !ok {
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	values, err := completer(ctx, args)
+	if err != nil {
+		slog.Debug("Failed to complete an argument", "argument", name, "err", err)
+	}
+	prefix = strings.ToLower(prefix)
+	var matches []string
+	for _, v := range values {
+		if v != "" && strings.HasPrefix(strings.ToLower(v), prefix) {
+			matches = append(matches, v)
+		}
+	}
+	slices.Sort(matches)
+	matches = slices.Compact(matches)
+	result.Completion.Total = len(matches)
+	if len(matches) > maxValues {
+		matches = matches[:maxValues]
+		result.Completion.HasMore = true
+	}
+	if len(matches) > 0 {
+		result.Completion.Values = matches
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandle(t *testing.T) {
+	r := NewRegistry()
+	r.Register(func(_ context.Context, args map[string]string) ([]string, error) {
+		if args["project_id"] == "other" {
+			return []string{"other-cluster"}, nil
+		}
+		return []string{"prod-b", "staging", "Prod-a", "prod-b"}, nil
+	}, "cluster")
+	r.Register(func(context.Context, map[string]string) ([]string, error) {
+		return []string{"us-central1"}, errors.New("API unavailable")
+	}, "location")
+	var many []string
+	for i := range 150 {
+		many = append(many, fmt.Sprintf("ns-%03d", i))
+	}
+	r.Register(Values(many...), "namespace")
+
+	testCases := []struct {
+		name        string
+		params      string
+		want        []string
+		wantTotal   int
+		wantHasMore bool
+	}{
+		{
+			name:      "prefix ignoring case",
+			params:    `{"ref":{"type":"ref/prompt","name":"p"},"argument":{"name":"cluster","value":"prod"}}`,
+			want:      []string{"Prod-a", "prod-b"},
+			wantTotal: 2,
+		},
+		{
+			name:      "context arguments",
+			params:    `{"ref":{"type":"ref/prompt","name":"p"},"argument":{"name":"cluster","value":""},"context":{"arguments":{"project_id":"other"}}}`,
+			want:      []string{"other-cluster"},
+			wantTotal: 1,
+		},
+		{
+			name:      "values despite an error",
+			params:    `{"ref":{"type":"ref/resource","uri":"gke://{project}/{location}/{cluster}"},"argument":{"name":"location","value":"us"}}`,
+			want:      []string{"us-central1"},
+			wantTotal: 1,
+		},
+		{
+			name:        "too many values",
+			params:      `{"ref":{"type":"ref/prompt","name":"p"},"argument":{"name":"namespace","value":"ns-"}}`,
+			want:        many[:maxValues],
+			wantTotal:   150,
+			wantHasMore: true,
+		},
+		{
+			name:   "unknown argument",
+			params: `{"ref":{"type":"ref/prompt","name":"p"},"argument":{"name":"deployment","value":"x"}}`,
+			want:   []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.Handle(context.Background(), json.RawMessage(tc.params))
+			if err != nil {
+				t.Fatalf("Handle() failed: %v", err)
+			}
+			result := got.(*mcp.CompleteResult)
+			if diff := cmp.Diff(tc.want, result.Completion.Values); diff != "" {
+				t.Errorf("Handle() values mismatch (-want +got):\n%s", diff)
+			}
+			if result.Completion.Total != tc.wantTotal || result.Completion.HasMore != tc.wantHasMore {
+				t.Errorf("Handle() total = %d, hasMore = %v; want %d, %v", result.Completion.Total, result.Completion.HasMore, tc.wantTotal, tc.wantHasMore)
+			}
+		})
+	}
+}
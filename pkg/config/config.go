@@ -15,15 +15,383 @@
 package config
 
 import (
-	"log"
+	"log/slog"
+	"maps"
 	"os/exec"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/mock"
 )
 
 type Config struct {
-	userAgent        string
-	defaultProjectID string
-	defaultLocation  string
+	version                   string
+	userAgent                 string
+	defaultProjectID          string
+	defaultLocation           string
+	defaultCluster            string
+	enabledTools              []string
+	serverMode                string
+	requireSessionCredentials bool
+	impersonateServiceAccount string
+	impersonationAllowlist    []string
+	readOnly                  bool
+	dryRun                    bool
+	cacheTTLs                 map[string]time.Duration
+	maxResponseTokens         int
+	samplingSummaries         bool
+	explainCommands           bool
+	hideUnavailableTools      bool
+	inventoryRefresh          time.Duration
+	mock                      *mock.Server
+	toolTimeout               time.Duration
+	toolTimeouts              map[string]time.Duration
+	maxResultBytes            int
+	memoryLimit               int64
+	toolMemoryLimits          map[string]int64
+	endpoints                 map[string]string
+	quotaProject              string
+	embeddings                string
+	embeddingModel            string
+	bm25K1                    float64
+	bm25B                     float64
+	titleWeight               float64
+	headingsWeight            float64
+	instructionsDir           string
+	instructionsDocs          []string
+	plugins                   []Plugin
+}
+
+// Kinds of cached responses. See CacheTTL.
+const (
+	CacheClusters     = "clusters"
+	CacheServerConfig = "server_config"
+	CacheProjects     = "projects"
+	CacheKnownIssues  = "known_issues"
+	CachePrices       = "prices"
+)
+
+// GCP APIs called by the tools. See WithEndpoint.
+const (
+	APIContainer            = "container"
+	APILogging              = "logging"
+	APIMonitoring           = "monitoring"
+	APIRecommender          = "recommender"
+	APIResourceManager      = "cloudresourcemanager"
+	APICloudAsset           = "cloudasset"
+	APIAIPlatform           = "aiplatform"
+	APIServiceUsage         = "serviceusage"
+	APICloudDeploy          = "clouddeploy"
+	APICloudBuild           = "cloudbuild"
+	APIArtifactRegistry     = "artifactregistry"
+	APIContainerAnalysis    = "containeranalysis"
+	APICompute              = "compute"
+	APIBigQuery             = "bigquery"
+	APIStorage              = "storage"
+	APIBigQueryDataTransfer = "bigquerydatatransfer"
+	APICloudBilling         = "cloudbilling"
+	APISecurityCenter       = "securitycenter"
+	APIIAM                  = "iam"
+	APIPolicyAnalyzer       = "policyanalyzer"
+	APIBinaryAuthorization  = "binaryauthorization"
+)
+
+// APIs lists the GCP APIs called by the tools.
+var APIs = []string{APIContainer, APILogging, APIMonitoring, APIRecommender, APIResourceManager, APICloudAsset, APIAIPlatform, APIServiceUsage, APICloudDeploy, APICloudBuild, APIArtifactRegistry, APIContainerAnalysis, APICompute, APIBigQuery, APIStorage, APIBigQueryDataTransfer, APICloudBilling, APISecurityCenter, APIIAM, APIPolicyAnalyzer, APIBinaryAuthorization}
+
+// Providers of the embeddings used to search the instructions. See
+// WithEmbeddings.
+const (
+	EmbeddingsVertex = "vertex"
+	EmbeddingsOllama = "ollama"
+)
+
+// Defaults of the BM25 parameters set by WithBM25.
+const (
+	DefaultBM25K1 = 1.2
+	DefaultBM25B  = 0.75
+)
+
+// Defaults of the ranking weights set by WithRankingWeights.
+const (
+	DefaultTitleWeight    = 2
+	DefaultHeadingsWeight = 1
+)
+
+// DefaultToolTimeout is the default for WithToolTimeout.
+const DefaultToolTimeout = 2 * time.Minute
+
+// DefaultMaxResponseTokens is the default for WithMaxResponseTokens.
+const DefaultMaxResponseTokens = 20000
+
+// DefaultMaxResultBytes is the default for WithMaxResultBytes.
+const DefaultMaxResultBytes = 1 << 20
+
+var defaultCacheTTLs = map[string]time.Duration{
+	CacheClusters:     30 * time.Second,
+	CacheServerConfig: time.Hour,
+	CacheProjects:     5 * time.Minute,
+	CacheKnownIssues:  6 * time.Hour,
+	CachePrices:       24 * time.Hour,
+}
+
+// Option customizes a Config created by New.
+type Option func(*Config)
+
+// WithDefaultProjectID sets the project used when the user doesn't name one,
+// instead of the one configured in gcloud.
+func WithDefaultProjectID(projectID string) Option {
+	return func(c *Config) {
+		c.defaultProjectID = projectID
+	}
+}
+
+// WithDefaultLocation sets the location used when the user doesn't name one,
+// instead of the region or zone configured in gcloud.
+func WithDefaultLocation(location string) Option {
+	return func(c *Config) {
+		c.defaultLocation = location
+	}
+}
+
+// WithDefaultCluster sets the cluster the user works with unless they name
+// another one.
+func WithDefaultCluster(cluster string) Option {
+	return func(c *Config) {
+		c.defaultCluster = cluster
+	}
+}
+
+// WithEnabledTools limits the server to the named tools. An empty list
+// enables every tool.
+func WithEnabledTools(tools []string) Option {
+	return func(c *Config) {
+		c.enabledTools = tools
+	}
+}
+
+// WithServerMode records the transport the server is served over, stdio or
+// http. See SingleUser.
+func WithServerMode(mode string) Option {
+	return func(c *Config) {
+		c.serverMode = mode
+	}
+}
+
+// WithRequireSessionCredentials makes every GCP API call use credentials
+// supplied by the MCP session instead of falling back to the server's
+// Application Default Credentials.
+func WithRequireSessionCredentials(require bool) Option {
+	return func(c *Config) {
+		c.requireSessionCredentials = require
+	}
+}
+
+// WithImpersonateServiceAccount makes GCP API calls impersonate the given
+// service account by default.
+func WithImpersonateServiceAccount(serviceAccount string) Option {
+	return func(c *Config) {
+		c.impersonateServiceAccount = serviceAccount
+	}
+}
+
+// WithImpersonationAllowlist lets HTTP requests that don't carry their own
+// credentials impersonate the given service accounts with the
+// X-Goog-Impersonate-Service-Account header, using the server's credentials.
+func WithImpersonationAllowlist(serviceAccounts []string) Option {
+	return func(c *Config) {
+		c.impersonationAllowlist = serviceAccounts
+	}
+}
+
+// WithReadOnly removes every tool that can modify resources.
+func WithReadOnly(readOnly bool) Option {
+	return func(c *Config) {
+		c.readOnly = readOnly
+	}
+}
+
+// WithDryRun makes mutating tools default to a dry run.
+func WithDryRun(dryRun bool) Option {
+	return func(c *Config) {
+		c.dryRun = dryRun
+	}
+}
+
+// WithCacheTTL sets how long responses for a kind of resource are cached.
+// A zero TTL disables caching for it.
+func WithCacheTTL(resource string, ttl time.Duration) Option {
+	return func(c *Config) {
+		c.cacheTTLs[resource] = ttl
+	}
+}
+
+// WithMaxResponseTokens sets the approximate size in tokens above which tool
+// results are summarized. Zero disables summarization.
+func WithMaxResponseTokens(tokens int) Option {
+	return func(c *Config) {
+		c.maxResponseTokens = tokens
+	}
+}
+
+// WithSamplingSummaries makes large tool results be summarized by the
+// client's model, through MCP sampling, when the client supports it.
+func WithSamplingSummaries(sampling bool) Option {
+	return func(c *Config) {
+		c.samplingSummaries = sampling
+	}
+}
+
+// WithHideUnavailableTools hides the tools whose API is disabled on the
+// project, or whose IAM permissions the caller lacks.
+func WithHideUnavailableTools(hide bool) Option {
+	return func(c *Config) {
+		c.hideUnavailableTools = hide
+	}
+}
+
+// WithInventoryRefresh refreshes the cluster inventory in the background at
+// the given interval. Zero disables the refresh.
+func WithInventoryRefresh(interval time.Duration) Option {
+	return func(c *Config) {
+		c.inventoryRefresh = interval
+	}
+}
+
+// WithMock serves the calls of the GCP and Kubernetes APIs from the
+// fixtures of s, and makes its demo cluster the default one.
+func WithMock(s *mock.Server) Option {
+	return func(c *Config) {
+		c.mock = s
+		c.defaultProjectID = mock.Project
+		c.defaultLocation = mock.Location
+		c.defaultCluster = mock.Cluster
+	}
+}
+
+// WithExplainCommands appends the equivalent gcloud or kubectl commands to
+// every tool result, not only to dry runs.
+func WithExplainCommands(explain bool) Option {
+	return func(c *Config) {
+		c.explainCommands = explain
+	}
+}
+
+// WithToolTimeout sets how long a tool call may run before it is canceled.
+// Zero disables the timeout.
+func WithToolTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.toolTimeout = timeout
+	}
+}
+
+// WithMaxResultBytes sets the size above which tool results are truncated,
+// even if the caller asked for the full output. Zero disables the limit.
+func WithMaxResultBytes(bytes int) Option {
+	return func(c *Config) {
+		c.maxResultBytes = bytes
+	}
+}
+
+// WithMemoryLimit sets the heap size, in bytes, above which running tool
+// calls are stopped. Zero disables the limit.
+func WithMemoryLimit(bytes int64) Option {
+	return func(c *Config) {
+		c.memoryLimit = bytes
+	}
+}
+
+// WithToolMemoryLimit sets how much, in bytes, the heap may grow during a
+// call of tool before the call is stopped.
+func WithToolMemoryLimit(tool string, bytes int64) Option {
+	return func(c *Config) {
+		c.toolMemoryLimits[tool] = bytes
+	}
+}
+
+// WithToolTimeoutOverride sets the timeout of a single tool, overriding the
+// one set by WithToolTimeout.
+func WithToolTimeoutOverride(tool string, timeout time.Duration) Option {
+	return func(c *Config) {
+		c.toolTimeouts[tool] = timeout
+	}
+}
+
+// WithEndpoint overrides the endpoint, as host:port, of one of the APIs,
+// e.g. to use a regional or Private Service Connect endpoint.
+func WithEndpoint(api, endpoint string) Option {
+	return func(c *Config) {
+		c.endpoints[api] = endpoint
+	}
+}
+
+// WithQuotaProject bills API calls, and counts them against the quota, of
+// the given project instead of the one owning the credentials.
+func WithQuotaProject(projectID string) Option {
+	return func(c *Config) {
+		c.quotaProject = projectID
+	}
+}
+
+// WithEmbeddings makes get_instructions rank sections by the similarity of
+// their embeddings, computed by provider (EmbeddingsVertex or
+// EmbeddingsOllama) with model, or the provider's default model if empty.
+// An empty provider keeps the keyword search.
+func WithEmbeddings(provider, model string) Option {
+	return func(c *Config) {
+		c.embeddings = provider
+		c.embeddingModel = model
+	}
+}
+
+// WithBM25 sets the parameters of the BM25 keyword search of the
+// instructions: k1 controls how quickly repeated terms stop adding to the
+// score, and b, between 0 and 1, how much long sections are penalized.
+func WithBM25(k1, b float64) Option {
+	return func(c *Config) {
+		c.bm25K1 = k1
+		c.bm25B = b
+	}
+}
+
+// WithRankingWeights sets how much the terms of a section's title and of the
+// titles of its enclosing sections count in the keyword search of the
+// instructions, as occurrences added to those in its content.
+func WithRankingWeights(title, headings float64) Option {
+	return func(c *Config) {
+		c.titleWeight = title
+		c.headingsWeight = headings
+	}
+}
+
+// WithInstructionsDir adds the markdown files in dir, e.g. the organization's
+// runbooks, to the instructions searched by get_instructions.
+func WithInstructionsDir(dir string) Option {
+	return func(c *Config) {
+		c.instructionsDir = dir
+	}
+}
+
+// WithInstructionsDocs adds GKE documentation pages, as URLs or paths
+// relative to https://cloud.google.com/kubernetes-engine/docs/, to the
+// instructions searched by get_instructions.
+func WithInstructionsDocs(pages []string) Option {
+	return func(c *Config) {
+		c.instructionsDocs = pages
+	}
+}
+
+// WithPlugins adds the tools of external plugin processes to the server.
+func WithPlugins(plugins []Plugin) Option {
+	return func(c *Config) {
+		c.plugins = plugins
+	}
+}
+
+// Version returns the version of the server.
+func (c *Config) Version() string {
+	return c.version
 }
 
 func (c *Config) UserAgent() string {
@@ -38,18 +406,206 @@ func (c *Config) DefaultLocation() string {
 	return c.defaultLocation
 }
 
-func New(version string) *Config {
-	return &Config{
-		userAgent:        "gke-mcp/" + version,
-		defaultProjectID: getDefaultProjectID(),
-		defaultLocation:  getDefaultLocation(),
+// DefaultCluster returns the cluster the user works with unless they name
+// another one, or "" if there is none.
+func (c *Config) DefaultCluster() string {
+	return c.defaultCluster
+}
+
+// EnabledTools returns the names of the tools the server is limited to, or
+// nil if every tool is enabled.
+func (c *Config) EnabledTools() []string {
+	return c.enabledTools
+}
+
+// SingleUser reports whether the server only serves the user who started
+// it, over stdio. Over HTTP its sessions may belong to different users, so
+// the server's own state must not be changed or revealed on behalf of one of
+// them.
+func (c *Config) SingleUser() bool {
+	return c.serverMode != "http"
+}
+
+// RequireSessionCredentials reports whether GCP API calls must use
+// credentials supplied by the MCP session.
+func (c *Config) RequireSessionCredentials() bool {
+	return c.requireSessionCredentials
+}
+
+// ImpersonateServiceAccount returns the service account GCP API calls
+// impersonate by default, or "" to use the caller's identity directly.
+func (c *Config) ImpersonateServiceAccount() string {
+	return c.impersonateServiceAccount
+}
+
+// ImpersonationAllowed reports whether a request without its own
+// credentials may impersonate serviceAccount with the server's credentials.
+// The service account impersonated by default is always allowed.
+func (c *Config) ImpersonationAllowed(serviceAccount string) bool {
+	return serviceAccount == c.impersonateServiceAccount || slices.Contains(c.impersonationAllowlist, serviceAccount)
+}
+
+// ReadOnly reports whether tools that can modify resources are disabled.
+func (c *Config) ReadOnly() bool {
+	return c.readOnly
+}
+
+// DryRun reports whether mutating tools default to a dry run.
+func (c *Config) DryRun() bool {
+	return c.dryRun
+}
+
+// MaxResponseTokens returns the approximate size in tokens above which tool
+// results are summarized, or 0 if they never are.
+func (c *Config) MaxResponseTokens() int {
+	return c.maxResponseTokens
+}
+
+// SamplingSummaries reports whether large tool results are summarized by
+// the client's model when it supports sampling.
+func (c *Config) SamplingSummaries() bool {
+	return c.samplingSummaries
+}
+
+// HideUnavailableTools reports whether the tools the caller can't use on the
+// project are hidden.
+func (c *Config) HideUnavailableTools() bool {
+	return c.hideUnavailableTools
+}
+
+// InventoryRefresh returns the interval at which the cluster inventory is
+// refreshed in the background, or 0 if it isn't.
+func (c *Config) InventoryRefresh() time.Duration {
+	return c.inventoryRefresh
+}
+
+// Mock returns the server of the fixtures the API calls are served from, or
+// nil if they are sent to the real APIs.
+func (c *Config) Mock() *mock.Server {
+	return c.mock
+}
+
+// ExplainCommands reports whether every tool result shows the equivalent
+// gcloud or kubectl commands.
+func (c *Config) ExplainCommands() bool {
+	return c.explainCommands
+}
+
+// ToolTimeout returns how long a call of tool may run, or 0 if it may run
+// forever, and whether it was set for this tool rather than by default.
+func (c *Config) ToolTimeout(tool string) (time.Duration, bool) {
+	if timeout, ok := c.toolTimeouts[tool]; ok {
+		return timeout, true
+	}
+	return c.toolTimeout, false
+}
+
+// MaxResultBytes returns the size above which tool results are truncated, or
+// 0 if they aren't.
+func (c *Config) MaxResultBytes() int {
+	return c.maxResultBytes
+}
+
+// MemoryLimit returns the heap size, in bytes, above which running tool calls
+// are stopped, or 0 if there is no limit.
+func (c *Config) MemoryLimit() int64 {
+	return c.memoryLimit
+}
+
+// ToolMemoryLimit returns how much, in bytes, the heap may grow during a call
+// of tool, or 0 if there is no limit.
+func (c *Config) ToolMemoryLimit(tool string) int64 {
+	return c.toolMemoryLimits[tool]
+}
+
+// Endpoint returns the endpoint override of an API, or "" to use the default
+// endpoint.
+func (c *Config) Endpoint(api string) string {
+	return c.endpoints[api]
+}
+
+// QuotaProject returns the project API calls are billed to, or "" for the
+// default project of the credentials.
+func (c *Config) QuotaProject() string {
+	return c.quotaProject
+}
+
+// Embeddings returns the provider and model of the embeddings used to search
+// the instructions. The provider is "" if keyword search is used.
+func (c *Config) Embeddings() (provider, model string) {
+	return c.embeddings, c.embeddingModel
+}
+
+// BM25 returns the parameters of the BM25 keyword search of the
+// instructions.
+func (c *Config) BM25() (k1, b float64) {
+	return c.bm25K1, c.bm25B
+}
+
+// RankingWeights returns the weights of the terms of section titles and of
+// their enclosing sections' titles in the keyword search of the instructions.
+func (c *Config) RankingWeights() (title, headings float64) {
+	return c.titleWeight, c.headingsWeight
+}
+
+// InstructionsDir returns the directory of additional instructions, or "" if
+// there is none.
+func (c *Config) InstructionsDir() string {
+	return c.instructionsDir
+}
+
+// InstructionsDocs returns the documentation pages added to the
+// instructions.
+func (c *Config) InstructionsDocs() []string {
+	return c.instructionsDocs
+}
+
+// Plugins returns the external plugin processes whose tools are served.
+func (c *Config) Plugins() []Plugin {
+	return c.plugins
+}
+
+// CacheTTL returns how long responses for a kind of resource are cached.
+func (c *Config) CacheTTL(resource string) time.Duration {
+	return c.cacheTTLs[resource]
+}
+
+func New(version string, opts ...Option) *Config {
+	c := &Config{
+		version:           version,
+		userAgent:         "gke-mcp/" + version,
+		cacheTTLs:         maps.Clone(defaultCacheTTLs),
+		maxResponseTokens: DefaultMaxResponseTokens,
+		toolTimeout:       DefaultToolTimeout,
+		toolTimeouts:      map[string]time.Duration{},
+		maxResultBytes:    DefaultMaxResultBytes,
+		toolMemoryLimits:  map[string]int64{},
+		endpoints:         map[string]string{},
+		bm25K1:            DefaultBM25K1,
+		bm25B:             DefaultBM25B,
+		titleWeight:       DefaultTitleWeight,
+		headingsWeight:    DefaultHeadingsWeight,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	// The mock has its own defaults, and must not read the gcloud
+	// configuration of the machine.
+	if c.mock == nil {
+		if c.defaultProjectID == "" {
+			c.defaultProjectID = getDefaultProjectID()
+		}
+		if c.defaultLocation == "" {
+			c.defaultLocation = getDefaultLocation()
+		}
 	}
+	return c
 }
 
 func getDefaultProjectID() string {
 	projectID, err := getGcloudConfig("core/project")
 	if err != nil {
-		log.Printf("Failed to get default project: %v", err)
+		slog.Warn("Failed to get default project", "err", err)
 		return ""
 	}
 	return projectID
@@ -0,0 +1,116 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// EnvPrefix is the prefix of the environment variables that set flags: the
+// --read-only flag, for example, can be set with GKE_MCP_READ_ONLY=true.
+const EnvPrefix = "GKE_MCP_"
+
+// EnvName returns the name of the environment variable setting a flag.
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// ApplyEnv sets every flag that wasn't given on the command line from its
+// environment variable, if set. Flags on the command line take precedence.
+func ApplyEnv(flags *pflag.FlagSet) error {
+	var errs []string
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+		value, ok := os.LookupEnv(EnvName(f.Name))
+		if !ok {
+			return
+		}
+		if err := flags.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid value %q for %s: %v", value, EnvName(f.Name), err))
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ToolTimeoutOptions parses per-tool timeouts given as tool name to
+// duration, e.g. "query_logs" to "5m".
+func ToolTimeoutOptions(timeouts map[string]string) ([]Option, error) {
+	var opts []Option
+	for _, tool := range slices.Sorted(maps.Keys(timeouts)) {
+		timeout, err := time.ParseDuration(timeouts[tool])
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout for tool %q: %w", tool, err)
+		}
+		opts = append(opts, WithToolTimeoutOverride(tool, timeout))
+	}
+	return opts, nil
+}
+
+// ToolMemoryLimitOptions parses per-tool memory limits given as tool name to
+// MiB, e.g. "query_logs" to "256".
+func ToolMemoryLimitOptions(limits map[string]string) ([]Option, error) {
+	var opts []Option
+	for _, tool := range slices.Sorted(maps.Keys(limits)) {
+		mib, err := strconv.ParseInt(limits[tool], 10, 64)
+		if err != nil || mib < 0 {
+			return nil, fmt.Errorf("invalid memory limit for tool %q: %q is not a number of MiB", tool, limits[tool])
+		}
+		opts = append(opts, WithToolMemoryLimit(tool, mib<<20))
+	}
+	return opts, nil
+}
+
+// EndpointOptions parses endpoint overrides given as API to host:port, e.g.
+// "container" to "container-myendpoint.p.googleapis.com:443".
+func EndpointOptions(endpoints map[string]string) ([]Option, error) {
+	var opts []Option
+	for _, api := range slices.Sorted(maps.Keys(endpoints)) {
+		if !slices.Contains(APIs, api) {
+			return nil, fmt.Errorf("unknown API %q, must be one of %v", api, APIs)
+		}
+		opts = append(opts, WithEndpoint(api, endpoints[api]))
+	}
+	return opts, nil
+}
+
+// CacheTTLOptions parses cache TTLs given as resource kind to duration, e.g.
+// "clusters" to "1m".
+func CacheTTLOptions(ttls map[string]string) ([]Option, error) {
+	var opts []Option
+	for _, resource := range slices.Sorted(maps.Keys(ttls)) {
+		if _, ok := defaultCacheTTLs[resource]; !ok {
+			return nil, fmt.Errorf("unknown cache %q, must be one of %v", resource, slices.Sorted(maps.Keys(defaultCacheTTLs)))
+		}
+		ttl, err := time.ParseDuration(ttls[resource])
+		if err != nil {
+			return nil, fmt.Errorf("invalid TTL for cache %q: %w", resource, err)
+		}
+		opts = append(opts, WithCacheTTL(resource, ttl))
+	}
+	return opts, nil
+}
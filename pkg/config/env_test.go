@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func TestApplyEnv(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	readOnly := flags.Bool("read-only", false, "")
+	serverMode := flags.String("server-mode", "stdio", "")
+	port := flags.Int("server-port", 8080, "")
+	if err := flags.Parse([]string{"--server-mode=stdio"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GKE_MCP_READ_ONLY", "true")
+	t.Setenv("GKE_MCP_SERVER_MODE", "http")
+
+	if err := ApplyEnv(flags); err != nil {
+		t.Fatalf("ApplyEnv() failed: %v", err)
+	}
+	if !*readOnly {
+		t.Errorf("read-only = false, want true from the environment")
+	}
+	if *serverMode != "stdio" {
+		t.Errorf("server-mode = %q, want the command line value %q", *serverMode, "stdio")
+	}
+	if *port != 8080 {
+		t.Errorf("server-port = %d, want the default 8080", *port)
+	}
+
+	t.Setenv("GKE_MCP_SERVER_PORT", "not a number")
+	if err := ApplyEnv(flags); err == nil {
+		t.Errorf("ApplyEnv() succeeded with an invalid value, want error")
+	}
+}
+
+func TestCacheTTLOptions(t *testing.T) {
+	opts, err := CacheTTLOptions(map[string]string{CacheClusters: "1m", CacheServerConfig: "0"})
+	if err != nil {
+		t.Fatalf("CacheTTLOptions() failed: %v", err)
+	}
+	c := New("test", opts...)
+	if got := c.CacheTTL(CacheClusters); got != time.Minute {
+		t.Errorf("CacheTTL(%q) = %v, want %v", CacheClusters, got, time.Minute)
+	}
+	if got := c.CacheTTL(CacheServerConfig); got != 0 {
+		t.Errorf("CacheTTL(%q) = %v, want 0", CacheServerConfig, got)
+	}
+
+	if _, err := CacheTTLOptions(map[string]string{"unknown": "1m"}); err == nil {
+		t.Errorf("CacheTTLOptions() succeeded for an unknown cache, want error")
+	}
+}
+
+func TestEndpointOptions(t *testing.T) {
+	opts, err := EndpointOptions(map[string]string{APIContainer: "container-psc.p.googleapis.com:443"})
+	if err != nil {
+		t.Fatalf("EndpointOptions() failed: %v", err)
+	}
+	c := New("test", opts...)
+	if got, want := c.Endpoint(APIContainer), "container-psc.p.googleapis.com:443"; got != want {
+		t.Errorf("Endpoint(%q) = %q, want %q", APIContainer, got, want)
+	}
+	if got := c.Endpoint(APILogging); got != "" {
+		t.Errorf("Endpoint(%q) = %q, want the default", APILogging, got)
+	}
+
+	if _, err := EndpointOptions(map[string]string{"unknown": "example.com:443"}); err == nil {
+		t.Errorf("EndpointOptions() succeeded for an unknown API, want error")
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnv is the environment variable selecting a profile when the
+// --profile flag is not set.
+const ProfileEnv = "GKE_MCP_PROFILE"
+
+// File is the content of the configuration file.
+type File struct {
+	// DefaultProfile is the profile used when none is selected.
+	DefaultProfile string `yaml:"default_profile"`
+	// Profiles are named sets of settings, e.g. one per environment.
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile is a named set of settings from the configuration file.
+type Profile struct {
+	Project                   string   `yaml:"project"`
+	Location                  string   `yaml:"location"`
+	Cluster                   string   `yaml:"cluster"`
+	ImpersonateServiceAccount string   `yaml:"impersonate_service_account"`
+	QuotaProject              string   `yaml:"quota_project"`
+	ReadOnly                  bool     `yaml:"read_only"`
+	EnabledTools              []string `yaml:"enabled_tools"`
+	InstructionsDir           string   `yaml:"instructions_dir"`
+	InstructionsDocs          []string `yaml:"instructions_docs"`
+	// Endpoints overrides API endpoints, e.g. container: container-myendpoint.p.googleapis.com:443.
+	Endpoints map[string]string `yaml:"endpoints"`
+	// Plugins are external MCP servers, started over stdio, whose tools are
+	// served alongside the built-in ones.
+	Plugins []Plugin `yaml:"plugins"`
+}
+
+// Plugin is an external process serving tools over MCP on its stdin and
+// stdout, e.g. a company's own checks.
+type Plugin struct {
+	// Name identifies the plugin in logs and errors.
+	Name    string            `yaml:"name"`
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args"`
+	Env     map[string]string `yaml:"env"`
+	// Prefix is prepended to the names of the plugin's tools, to keep them
+	// apart from the built-in ones.
+	Prefix string `yaml:"prefix"`
+	// Category is the catalog category of the plugin's tools. Defaults to
+	// clusters.
+	Category string `yaml:"category"`
+}
+
+// DefaultFilePath returns the path of the configuration file,
+// ~/.config/gke-mcp/config.yaml on Linux.
+func DefaultFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gke-mcp", "config.yaml"), nil
+}
+
+// LoadFile reads the configuration file at path. A missing file is an empty
+// configuration.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Profile returns the named profile, or the default profile if name is "".
+// It returns an empty profile if neither is set.
+func (f *File) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = f.DefaultProfile
+	}
+	if name == "" {
+		return Profile{}, nil
+	}
+	p, ok := f.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %q not found, available profiles: %v", name, slices.Sorted(maps.Keys(f.Profiles)))
+	}
+	return p, nil
+}
+
+// Options returns the options applying the profile's settings. Settings the
+// profile leaves empty are left unchanged.
+func (p Profile) Options() ([]Option, error) {
+	opts, err := EndpointOptions(p.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+	if p.Project != "" {
+		opts = append(opts, WithDefaultProjectID(p.Project))
+	}
+	if p.Location != "" {
+		opts = append(opts, WithDefaultLocation(p.Location))
+	}
+	if p.Cluster != "" {
+		opts = append(opts, WithDefaultCluster(p.Cluster))
+	}
+	if p.ImpersonateServiceAccount != "" {
+		opts = append(opts, WithImpersonateServiceAccount(p.ImpersonateServiceAccount))
+	}
+	if p.QuotaProject != "" {
+		opts = append(opts, WithQuotaProject(p.QuotaProject))
+	}
+	if p.ReadOnly {
+		opts = append(opts, WithReadOnly(true))
+	}
+	if len(p.EnabledTools) > 0 {
+		opts = append(opts, WithEnabledTools(p.EnabledTools))
+	}
+	if p.InstructionsDir != "" {
+		opts = append(opts, WithInstructionsDir(p.InstructionsDir))
+	}
+	if len(p.InstructionsDocs) > 0 {
+		opts = append(opts, WithInstructionsDocs(p.InstructionsDocs))
+	}
+	for _, plugin := range p.Plugins {
+		if plugin.Name == "" || plugin.Command == "" {
+			return nil, fmt.Errorf("plugins need a name and a command, got %+v", plugin)
+		}
+	}
+	if len(p.Plugins) > 0 {
+		opts = append(opts, WithPlugins(p.Plugins))
+	}
+	return opts, nil
+}
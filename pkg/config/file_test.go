@@ -0,0 +1,131 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testFile = `
+default_profile: dev
+profiles:
+  dev:
+    project: dev-project
+    location: us-central1
+  prod:
+    project: prod-project
+    location: europe-west1
+    cluster: prod-cluster
+    impersonate_service_account: reader@prod-project.iam.gserviceaccount.com
+    read_only: true
+    enabled_tools: [list_clusters, get_cluster]
+`
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		profile string
+		want    Profile
+		wantErr bool
+	}{
+		{
+			name: "default profile",
+			want: Profile{Project: "dev-project", Location: "us-central1"},
+		},
+		{
+			name:    "named profile",
+			profile: "prod",
+			want: Profile{
+				Project:                   "prod-project",
+				Location:                  "europe-west1",
+				Cluster:                   "prod-cluster",
+				ImpersonateServiceAccount: "reader@prod-project.iam.gserviceaccount.com",
+				ReadOnly:                  true,
+				EnabledTools:              []string{"list_clusters", "get_cluster"},
+			},
+		},
+		{
+			name:    "unknown profile",
+			profile: "staging",
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := f.Profile(tc.profile)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Profile(%q) error = %v, want error %v", tc.profile, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Profile(%q) mismatch (-want +got):\n%s", tc.profile, diff)
+			}
+		})
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	f, err := LoadFile(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	p, err := f.Profile("")
+	if err != nil {
+		t.Fatalf("Profile() failed: %v", err)
+	}
+	if diff := cmp.Diff(Profile{}, p); diff != "" {
+		t.Errorf("Profile() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProfileOptions(t *testing.T) {
+	p := Profile{Project: "p", Location: "l", Cluster: "c", QuotaProject: "q", ReadOnly: true, EnabledTools: []string{"t"}}
+	opts, err := p.Options()
+	if err != nil {
+		t.Fatalf("Options() failed: %v", err)
+	}
+	c := New("test", append(opts, WithImpersonateServiceAccount("sa"))...)
+	got := []any{c.DefaultProjectID(), c.DefaultLocation(), c.DefaultCluster(), c.QuotaProject(), c.ReadOnly(), c.EnabledTools(), c.ImpersonateServiceAccount()}
+	want := []any{"p", "l", "c", "q", true, []string{"t"}, "sa"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("config mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProfilePlugins(t *testing.T) {
+	plugins := []Plugin{{Name: "paved-road", Command: "/usr/local/bin/paved-road-mcp", Prefix: "corp_"}}
+	opts, err := Profile{Plugins: plugins}.Options()
+	if err != nil {
+		t.Fatalf("Options() failed: %v", err)
+	}
+	if diff := cmp.Diff(plugins, New("test", opts...).Plugins()); diff != "" {
+		t.Errorf("Plugins() mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := (Profile{Plugins: []Plugin{{Name: "no-command"}}}).Options(); err == nil {
+		t.Error("Options() succeeded for a plugin without a command, want an error")
+	}
+}
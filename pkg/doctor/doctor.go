@@ -0,0 +1,337 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package doctor checks that the environment is set up for the server: the
+// gcloud tools, the credentials, the APIs enabled on the default project and
+// the reachability of the API endpoints. Every failed check comes with a fix.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// reachTimeout bounds checking that an endpoint is reachable.
+const reachTimeout = 5 * time.Second
+
+// Status is the outcome of a check.
+type Status int
+
+const (
+	OK Status = iota
+	// Warning is a problem that only affects some tools.
+	Warning
+	// Failure is a problem that keeps the server from working.
+	Failure
+)
+
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "ok"
+	case Warning:
+		return "warning"
+	default:
+		return "failure"
+	}
+}
+
+// Result is the result of a check.
+type Result struct {
+	Check  string
+	Status Status
+	Detail string
+	// Fix is a command or instruction that fixes the problem, if any.
+	Fix string
+}
+
+// requiredAPIs are the APIs most tools need, and optionalAPIs the ones only
+// some tools need, with what needs them.
+var (
+	requiredAPIs = []string{config.APIContainer, config.APILogging, config.APIMonitoring}
+	optionalAPIs = map[string]string{
+		config.APIRecommender:     "the recommendation tools",
+		config.APIResourceManager: "the project tools",
+		config.APICloudAsset:      "the inventory tools",
+		config.APIAIPlatform:      "the Vertex AI embeddings of get_instructions",
+	}
+)
+
+// doctor runs the checks, with its probes of the environment replaceable in
+// tests.
+type doctor struct {
+	c                *config.Config
+	lookPath         func(file string) (string, error)
+	checkCredentials func(ctx context.Context, c *config.Config) error
+	// enabledServices returns which of services, e.g.
+	// container.googleapis.com, are enabled on project.
+	enabledServices func(ctx context.Context, project string, services []string) (map[string]bool, error)
+	// reach returns an error if host can't be reached over HTTPS.
+	reach func(ctx context.Context, host string) error
+}
+
+// Run runs every check with the configuration c.
+func Run(ctx context.Context, c *config.Config) []Result {
+	d := &doctor{
+		c:                c,
+		lookPath:         exec.LookPath,
+		checkCredentials: auth.CheckCredentials,
+		reach:            reach,
+	}
+	d.enabledServices = func(ctx context.Context, project string, services []string) (map[string]bool, error) {
+		return EnabledServices(ctx, c, project, services)
+	}
+	return d.run(ctx)
+}
+
+func (d *doctor) run(ctx context.Context) []Result {
+	results := []Result{d.checkGcloud(), d.checkAuthPlugin()}
+	credentials := d.checkADC(ctx)
+	project := d.checkProject()
+	results = append(results, credentials, project)
+	if credentials.Status == OK && project.Status == OK {
+		results = append(results, d.checkAPIs(ctx))
+	}
+	return append(results, d.checkNetwork(ctx))
+}
+
+func (d *doctor) checkGcloud() Result {
+	path, err := d.lookPath("gcloud")
+	if err != nil {
+		return Result{
+			Check:  "gcloud",
+			Status: Failure,
+			Detail: "gcloud was not found in PATH. Tools that run gcloud and the default project and location need it.",
+			Fix:    "Install the Google Cloud CLI: https://cloud.google.com/sdk/docs/install",
+		}
+	}
+	return Result{Check: "gcloud", Status: OK, Detail: "found at " + path}
+}
+
+func (d *doctor) checkAuthPlugin() Result {
+	path, err := d.lookPath("gke-gcloud-auth-plugin")
+	if err != nil {
+		return Result{
+			Check:  "gke-gcloud-auth-plugin",
+			Status: Warning,
+			Detail: "gke-gcloud-auth-plugin was not found in PATH, so kubectl can't authenticate to GKE clusters.",
+			Fix:    "gcloud components install gke-gcloud-auth-plugin",
+		}
+	}
+	return Result{Check: "gke-gcloud-auth-plugin", Status: OK, Detail: "found at " + path}
+}
+
+func (d *doctor) checkADC(ctx context.Context) Result {
+	if err := d.checkCredentials(ctx, d.c); err != nil {
+		r := Result{
+			Check:  "credentials",
+			Status: Failure,
+			Detail: err.Error(),
+			Fix:    "gcloud auth application-default login",
+		}
+		if sa := d.c.ImpersonateServiceAccount(); sa != "" {
+			r.Fix += fmt.Sprintf("\ngcloud iam service-accounts add-iam-policy-binding %s --member=user:$(gcloud config get account) --role=roles/iam.serviceAccountTokenCreator", sa)
+		}
+		return r
+	}
+	detail := "Application Default Credentials are valid"
+	if sa := d.c.ImpersonateServiceAccount(); sa != "" {
+		detail += " and can impersonate " + sa
+	}
+	return Result{Check: "credentials", Status: OK, Detail: detail}
+}
+
+func (d *doctor) checkProject() Result {
+	project := d.c.DefaultProjectID()
+	if project == "" {
+		return Result{
+			Check:  "default project",
+			Status: Failure,
+			Detail: "no default project is set, so every tool call needs one and the APIs can't be checked.",
+			Fix:    "gcloud config set project PROJECT_ID",
+		}
+	}
+	return Result{Check: "default project", Status: OK, Detail: project}
+}
+
+// apis returns the APIs the configuration uses.
+func (d *doctor) apis() []string {
+	apis := append(slices.Clone(requiredAPIs), config.APIRecommender, config.APIResourceManager, config.APICloudAsset)
+	if provider, _ := d.c.Embeddings(); provider == config.EmbeddingsVertex {
+		apis = append(apis, config.APIAIPlatform)
+	}
+	return apis
+}
+
+func (d *doctor) checkAPIs(ctx context.Context) Result {
+	project := d.c.DefaultProjectID()
+	var services []string
+	for _, api := range d.apis() {
+		services = append(services, api+".googleapis.com")
+	}
+	enabled, err := d.enabledServices(ctx, project, services)
+	if err != nil {
+		return Result{
+			Check:  "APIs",
+			Status: Warning,
+			Detail: fmt.Sprintf("could not check the APIs enabled on project %s: %v", project, err),
+			Fix:    fmt.Sprintf("gcloud services list --enabled --project %s", project),
+		}
+	}
+	status := OK
+	var disabled, notes []string
+	for _, api := range d.apis() {
+		service := api + ".googleapis.com"
+		if enabled[service] {
+			continue
+		}
+		disabled = append(disabled, service)
+		if slices.Contains(requiredAPIs, api) {
+			status = Failure
+			notes = append(notes, service+" is disabled")
+		} else {
+			status = max(status, Warning)
+			notes = append(notes, fmt.Sprintf("%s is disabled, which %s need", service, optionalAPIs[api]))
+		}
+	}
+	if len(disabled) == 0 {
+		return Result{Check: "APIs", Status: OK, Detail: fmt.Sprintf("%s are enabled on project %s", strings.Join(services, ", "), project)}
+	}
+	return Result{
+		Check:  "APIs",
+		Status: status,
+		Detail: fmt.Sprintf("on project %s, %s.", project, strings.Join(notes, "; ")),
+		Fix:    fmt.Sprintf("gcloud services enable %s --project %s", strings.Join(disabled, " "), project),
+	}
+}
+
+func (d *doctor) checkNetwork(ctx context.Context) Result {
+	hosts := []string{"oauth2.googleapis.com"}
+	overridden := map[string]string{}
+	for _, api := range d.apis() {
+		host := api + ".googleapis.com"
+		if api == config.APIAIPlatform {
+			host = "us-central1-aiplatform.googleapis.com"
+		}
+		if endpoint := d.c.Endpoint(api); endpoint != "" {
+			host = endpoint
+			overridden[host] = api
+		}
+		hosts = append(hosts, host)
+	}
+	var unreachable, details []string
+	for _, host := range hosts {
+		if err := d.reach(ctx, host); err != nil {
+			unreachable = append(unreachable, host)
+			details = append(details, fmt.Sprintf("%s: %v", host, err))
+		}
+	}
+	if len(unreachable) == 0 {
+		return Result{Check: "network", Status: OK, Detail: fmt.Sprintf("reached %d endpoints", len(hosts))}
+	}
+	fix := fmt.Sprintf("Allow HTTPS connections to %s, e.g. through a proxy set in HTTPS_PROXY", strings.Join(unreachable, ", "))
+	for _, host := range unreachable {
+		if api, ok := overridden[host]; ok {
+			fix += fmt.Sprintf(", or fix the endpoint of %s set with --endpoint", api)
+		}
+	}
+	return Result{
+		Check:  "network",
+		Status: Failure,
+		Detail: strings.Join(details, "; "),
+		Fix:    fix + ".",
+	}
+}
+
+// EnabledServices returns which of services, e.g. container.googleapis.com,
+// are enabled on project, from the Service Usage API.
+func EnabledServices(ctx context.Context, c *config.Config, project string, services []string) (map[string]bool, error) {
+	opts, err := auth.ClientOptions(ctx, c, config.APIServiceUsage)
+	if err != nil {
+		return nil, err
+	}
+	client, _, err := htransport.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	host := "serviceusage.googleapis.com"
+	if endpoint := c.Endpoint(config.APIServiceUsage); endpoint != "" {
+		host = strings.TrimSuffix(endpoint, ":443")
+	}
+	q := url.Values{}
+	for _, service := range services {
+		q.Add("names", fmt.Sprintf("projects/%s/services/%s", project, service))
+	}
+	u := fmt.Sprintf("https://%s/v1/projects/%s/services:batchGet?%s", host, url.PathEscape(project), q.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	var body struct {
+		Services []struct {
+			Config struct {
+				Name string `json:"name"`
+			} `json:"config"`
+			State string `json:"state"`
+		} `json:"services"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	enabled := map[string]bool{}
+	for _, s := range body.Services {
+		enabled[s.Config.Name] = s.State == "ENABLED"
+	}
+	return enabled, nil
+}
+
+// reach checks that host, with an optional port, answers HTTPS requests.
+// Any HTTP response will do, since requests aren't authenticated.
+func reach(ctx context.Context, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, reachTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://"+host+"/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The error is reported with the host already.
+		if ue, ok := err.(*url.Error); ok {
+			return ue.Err
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doctor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRun(t *testing.T) {
+	found := func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	valid := func(context.Context, *config.Config) error { return nil }
+	allEnabled := func(_ context.Context, _ string, services []string) (map[string]bool, error) {
+		enabled := map[string]bool{}
+		for _, s := range services {
+			enabled[s] = true
+		}
+		return enabled, nil
+	}
+	reachable := func(context.Context, string) error { return nil }
+
+	tests := []struct {
+		name   string
+		c      *config.Config
+		doctor doctor
+		want   []Result
+	}{
+		{
+			name:   "healthy",
+			c:      config.New("test", config.WithDefaultProjectID("my-project")),
+			doctor: doctor{lookPath: found, checkCredentials: valid, enabledServices: allEnabled, reach: reachable},
+			want: []Result{
+				{Check: "gcloud", Status: OK, Detail: "found at /usr/bin/gcloud"},
+				{Check: "gke-gcloud-auth-plugin", Status: OK, Detail: "found at /usr/bin/gke-gcloud-auth-plugin"},
+				{Check: "credentials", Status: OK, Detail: "Application Default Credentials are valid"},
+				{Check: "default project", Status: OK, Detail: "my-project"},
+				{Check: "APIs", Status: OK, Detail: "container.googleapis.com, logging.googleapis.com, monitoring.googleapis.com, recommender.googleapis.com, cloudresourcemanager.googleapis.com, cloudasset.googleapis.com are enabled on project my-project"},
+				{Check: "network", Status: OK, Detail: "reached 7 endpoints"},
+			},
+		},
+		{
+			name: "broken",
+			c:    config.New("test", config.WithDefaultProjectID("my-project"), config.WithEndpoint(config.APIContainer, "container.example.com:443")),
+			doctor: doctor{
+				lookPath:         func(string) (string, error) { return "", errors.New("not found") },
+				checkCredentials: valid,
+				enabledServices: func(context.Context, string, []string) (map[string]bool, error) {
+					return map[string]bool{"logging.googleapis.com": true, "monitoring.googleapis.com": true, "recommender.googleapis.com": true, "cloudresourcemanager.googleapis.com": true}, nil
+				},
+				reach: func(_ context.Context, host string) error {
+					if host == "container.example.com:443" {
+						return errors.New("timeout")
+					}
+					return nil
+				},
+			},
+			want: []Result{
+				{
+					Check:  "gcloud",
+					Status: Failure,
+					Detail: "gcloud was not found in PATH. Tools that run gcloud and the default project and location need it.",
+					Fix:    "Install the Google Cloud CLI: https://cloud.google.com/sdk/docs/install",
+				},
+				{
+					Check:  "gke-gcloud-auth-plugin",
+					Status: Warning,
+					Detail: "gke-gcloud-auth-plugin was not found in PATH, so kubectl can't authenticate to GKE clusters.",
+					Fix:    "gcloud components install gke-gcloud-auth-plugin",
+				},
+				{Check: "credentials", Status: OK, Detail: "Application Default Credentials are valid"},
+				{Check: "default project", Status: OK, Detail: "my-project"},
+				{
+					Check:  "APIs",
+					Status: Failure,
+					Detail: "on project my-project, container.googleapis.com is disabled; cloudasset.googleapis.com is disabled, which the inventory tools need.",
+					Fix:    "gcloud services enable container.googleapis.com cloudasset.googleapis.com --project my-project",
+				},
+				{
+					Check:  "network",
+					Status: Failure,
+					Detail: "container.example.com:443: timeout",
+					Fix:    "Allow HTTPS connections to container.example.com:443, e.g. through a proxy set in HTTPS_PROXY, or fix the endpoint of container set with --endpoint.",
+				},
+			},
+		},
+		{
+			name: "no credentials",
+			c:    config.New("test", config.WithDefaultProjectID("my-project")),
+			doctor: doctor{
+				lookPath:         found,
+				checkCredentials: func(context.Context, *config.Config) error { return errors.New("could not find default credentials") },
+				reach:            reachable,
+			},
+			want: []Result{
+				{Check: "gcloud", Status: OK, Detail: "found at /usr/bin/gcloud"},
+				{Check: "gke-gcloud-auth-plugin", Status: OK, Detail: "found at /usr/bin/gke-gcloud-auth-plugin"},
+				{Check: "credentials", Status: Failure, Detail: "could not find default credentials", Fix: "gcloud auth application-default login"},
+				{Check: "default project", Status: OK, Detail: "my-project"},
+				{Check: "network", Status: OK, Detail: "reached 7 endpoints"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := tc.doctor
+			d.c = tc.c
+			if diff := cmp.Diff(tc.want, d.run(context.Background())); diff != "" {
+				t.Errorf("run() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
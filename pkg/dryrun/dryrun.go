@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dryrun lets mutating tools show what they would do without doing it.
+//
+// Every mutating tool accepts a dry_run argument, declared with Argument and
+// read with Enabled. When it is set the tool returns Result instead of
+// calling the API.
+package dryrun
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ArgumentName is the name of the tool argument that requests a dry run.
+const ArgumentName = "dry_run"
+
+// Argument declares the dry_run argument on a mutating tool. Its default
+// follows the server-wide setting.
+func Argument(c *config.Config) mcp.ToolOption {
+	return mcp.WithBoolean(ArgumentName,
+		mcp.DefaultBool(c.DryRun()),
+		mcp.Description("If true, don't change anything: return the exact API request and the equivalent gcloud command instead, so the user can review them before applying. Prefer a dry run first and ask the user to confirm before running the tool for real."),
+	)
+}
+
+// Enabled reports whether request asks for a dry run.
+func Enabled(request mcp.CallToolRequest, c *config.Config) bool {
+	return request.GetBool(ArgumentName, c.DryRun())
+}
+
+// Result describes an API request that was not sent. method is the fully
+// qualified API method, e.g. "container.projects.locations.clusters.update",
+// and commands are equivalent CLI invocations, if any.
+func Result(method string, req proto.Message, commands ...string) *mcp.CallToolResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run: no changes were made.\n\nAPI method: %s\nRequest:\n```json\n%s\n```\n", method, protojson.Format(req))
+	if len(commands) > 0 {
+		fmt.Fprintf(&b, "\nEquivalent command:\n```sh\n%s\n```\n", strings.Join(commands, "\n"))
+	}
+	b.WriteString("\nCall the tool again with dry_run=false to apply the change.")
+	return mcp.NewToolResultText(b.String())
+}
+
+// Describe describes an action that was not taken, for tools that don't call
+// an API.
+func Describe(action string) *mcp.CallToolResult {
+	return mcp.NewToolResultText(fmt.Sprintf("Dry run: no changes were made.\n\nWould %s.\n\nCall the tool again with dry_run=false to apply the change.", action))
+}
+
+// Manifests describes Kubernetes objects that were not created, as YAML
+// manifests, and commands are equivalent CLI invocations, if any.
+func Manifests(action, manifests string, commands ...string) *mcp.CallToolResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run: no changes were made.\n\nWould %s:\n```yaml\n%s```\n", action, manifests)
+	if len(commands) > 0 {
+		fmt.Fprintf(&b, "\nEquivalent command:\n```sh\n%s\n```\n", strings.Join(commands, "\n"))
+	}
+	b.WriteString("\nCall the tool again with dry_run=false to apply the change.")
+	return mcp.NewToolResultText(b.String())
+}
+
+// Supported reports whether tool accepts the dry_run argument.
+func Supported(tool mcp.Tool) bool {
+	_, ok := tool.InputSchema.Properties[ArgumentName]
+	return ok
+}
@@ -0,0 +1,248 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package elicitation asks the user to confirm destructive tool calls through
+// MCP elicitation, instead of trusting the model to have asked them. Clients
+// that don't support elicitation must pass the user's typed confirmation in
+// the confirm argument instead, or the calls are rejected.
+package elicitation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/clientrequest"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Method is the method of elicitation requests.
+const Method = "elicitation/create"
+
+// ConfirmArgument is the argument destructive tools take the confirmation
+// typed by the user in when the client doesn't support elicitation.
+const ConfirmArgument = "confirm"
+
+// targetArguments are the tool arguments naming the resource a call acts
+// on, which the user is asked to type to confirm it. The namespace comes
+// first, as the tools acting on one also name its cluster.
+var targetArguments = []string{"namespace", "cluster", "cluster_name", "name"}
+
+// Response is the response of the user to an elicitation request.
+type Response struct {
+	// Action is accept, decline or cancel.
+	Action  string         `json:"action"`
+	Content map[string]any `json:"content,omitempty"`
+}
+
+// Elicitor sends elicitation requests to the client.
+type Elicitor struct {
+	t           *clientrequest.Transport
+	mu          sync.Mutex
+	destructive map[string]bool
+}
+
+// Default is the elicitor used by the server.
+var Default = New(clientrequest.Default)
+
+// New returns an elicitor sending its requests over t.
+func New(t *clientrequest.Transport) *Elicitor {
+	return &Elicitor{t: t, destructive: map[string]bool{}}
+}
+
+// Supported reports whether the client calling with ctx can answer
+// elicitation requests.
+func (e *Elicitor) Supported(ctx context.Context) bool {
+	return e.t.SupportedBy(ctx, "elicitation")
+}
+
+// Elicit asks the user for the content described by schema, a JSON schema of
+// an object with primitive properties.
+func (e *Elicitor) Elicit(ctx context.Context, prompt string, schema map[string]any) (*Response, error) {
+	if !e.Supported(ctx) {
+		return nil, fmt.Errorf("the client doesn't support elicitation")
+	}
+	result, err := e.t.Request(ctx, Method, map[string]any{
+		"message":         prompt,
+		"requestedSchema": schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var r Response
+	if err := json.Unmarshal(result, &r); err != nil {
+		return nil, fmt.Errorf("invalid elicitation response: %w", err)
+	}
+	return &r, nil
+}
+
+// Protect makes the middleware confirm calls to the destructive tools among
+// tools. The destructive hint only matters for tools that aren't read-only,
+// and the MCP library sets it on every tool by default.
+func (e *Elicitor) Protect(tools []mcp.Tool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, tool := range tools {
+		readOnly := tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint
+		if hint := tool.Annotations.DestructiveHint; !readOnly && hint != nil && *hint {
+			e.destructive[tool.Name] = true
+		}
+	}
+}
+
+func (e *Elicitor) protected(tool string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.destructive[tool]
+}
+
+// Filter declares ConfirmArgument on the protected tools listed to clients
+// that don't support elicitation.
+func (e *Elicitor) Filter() server.ToolFilterFunc {
+	return func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		if e.Supported(ctx) {
+			return tools
+		}
+		filtered := make([]mcp.Tool, len(tools))
+		for i, tool := range tools {
+			if e.protected(tool.Name) {
+				properties := maps.Clone(tool.InputSchema.Properties)
+				if properties == nil {
+					properties = map[string]any{}
+				}
+				properties[ConfirmArgument] = map[string]any{
+					"type":        "string",
+					"description": "Confirmation typed by the user, required unless dry_run is true: the name of what the call acts on, like the namespace or cluster, or else the name of the tool. Show the user what the call would do and ask them to type it; never fill it in yourself.",
+				}
+				tool.InputSchema.Properties = properties
+			}
+			filtered[i] = tool
+		}
+		return filtered
+	}
+}
+
+// Middleware asks the user to confirm calls to protected tools, unless they
+// are dry runs. If the call names a cluster or another target, the user
+// confirms by typing its name. If the client can't be asked, the call must
+// pass what the user typed as ConfirmArgument instead.
+func (e *Elicitor) Middleware(c *config.Config) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !e.protected(request.Params.Name) || dryrun.Enabled(request, c) {
+				return next(ctx, request)
+			}
+			if !e.Supported(ctx) {
+				return typedConfirmation(ctx, request, next)
+			}
+			confirmed, err := e.confirm(ctx, request)
+			if err != nil {
+				slog.Warn("Failed to ask the user to confirm a destructive tool call", "tool", request.Params.Name, "err", err)
+				return mcp.NewToolResultError(fmt.Sprintf("%s was not run: the user couldn't be asked to confirm it: %v", request.Params.Name, err)), nil
+			}
+			if !confirmed {
+				return mcp.NewToolResultError(fmt.Sprintf("%s was not run: the user didn't confirm it. Don't retry unless they ask.", request.Params.Name)), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// target returns the name of what a call acts on, or "" if it names none.
+func target(args map[string]any) string {
+	for _, arg := range targetArguments {
+		if v, ok := args[arg].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// typedConfirmation calls next if the call passes the name of its target, or
+// of the tool if it has none, as ConfirmArgument, and rejects it otherwise.
+func typedConfirmation(ctx context.Context, request mcp.CallToolRequest, next server.ToolHandlerFunc) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+	want := target(args)
+	if want == "" {
+		want = request.Params.Name
+	}
+	typed, _ := args[ConfirmArgument].(string)
+	if strings.TrimSpace(typed) != want {
+		return mcp.NewToolResultError(fmt.Sprintf("%s was not run: the client can't ask the user to confirm it, so the call needs %s=%q. Show the user what it would do, e.g. with dry_run=true, ask them to type %s to confirm, and call it again with what they typed as %s. Never fill it in yourself.", request.Params.Name, ConfirmArgument, want, want, ConfirmArgument)), nil
+	}
+	// The tool doesn't declare the argument, so it doesn't get it.
+	args = maps.Clone(args)
+	delete(args, ConfirmArgument)
+	request.Params.Arguments = args
+	return next(ctx, request)
+}
+
+func (e *Elicitor) confirm(ctx context.Context, request mcp.CallToolRequest) (bool, error) {
+	args := request.GetArguments()
+	target := target(args)
+	var described []string
+	for _, k := range slices.Sorted(maps.Keys(args)) {
+		described = append(described, fmt.Sprintf("%s=%v", k, args[k]))
+	}
+	effect := "which can't be undone"
+	if entry, ok := catalog.Lookup(request.Params.Name); ok {
+		switch entry.Kind {
+		case catalog.Deploy:
+			effect = "which changes the workloads that run"
+		case catalog.Dismiss:
+			effect = "which changes the findings everyone sees"
+		}
+	}
+	prompt := fmt.Sprintf("The agent wants to run %s, %s.", request.Params.Name, effect)
+	if len(described) > 0 {
+		prompt += " Arguments: " + strings.Join(described, ", ") + "."
+	}
+
+	if target == "" {
+		r, err := e.Elicit(ctx, prompt+" Do you want to run it?", map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"confirm": map[string]any{"type": "boolean", "title": "Run " + request.Params.Name},
+			},
+			"required": []string{"confirm"},
+		})
+		if err != nil {
+			return false, err
+		}
+		confirmed, _ := r.Content["confirm"].(bool)
+		return r.Action == "accept" && confirmed, nil
+	}
+
+	r, err := e.Elicit(ctx, fmt.Sprintf("%s Type %s to confirm.", prompt, target), map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string", "title": "Name", "description": "Type " + target + " to confirm"},
+		},
+		"required": []string{"name"},
+	})
+	if err != nil {
+		return false, err
+	}
+	typed, _ := r.Content["name"].(string)
+	return r.Action == "accept" && strings.TrimSpace(typed) == target, nil
+}
@@ -0,0 +1,280 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elicitation
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/clientrequest"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// client plays the part of an MCP client on the other end of the stdio
+// transport intercepted by a clientrequest.Transport.
+type client struct {
+	in  *io.PipeWriter
+	out *bufio.Reader
+	// forwarded reads what is passed on to the stdio transport.
+	forwarded *bufio.Reader
+}
+
+func newClient(t *testing.T, tr *clientrequest.Transport, capabilities string) *client {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	t.Cleanup(func() {
+		inW.Close()
+		outR.Close()
+	})
+	forwarded, _ := tr.InterceptStdio(inR, outW)
+	c := &client{in: inW, out: bufio.NewReader(outR), forwarded: bufio.NewReader(forwarded)}
+	c.send(t, fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":%s}}`, capabilities))
+	if _, err := c.forwarded.ReadBytes('\n'); err != nil {
+		t.Fatalf("the initialize request wasn't forwarded: %v", err)
+	}
+	return c
+}
+
+func (c *client) send(t *testing.T, line string) {
+	t.Helper()
+	if _, err := io.WriteString(c.in, line+"\n"); err != nil {
+		t.Fatalf("failed to send %s: %v", line, err)
+	}
+}
+
+// answer reads an elicitation request and responds to it with result.
+func (c *client) answer(t *testing.T, result string) {
+	t.Helper()
+	line, err := c.out.ReadBytes('\n')
+	if err != nil {
+		t.Errorf("failed to read the elicitation request: %v", err)
+		return
+	}
+	var request struct {
+		ID     string `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(line, &request); err != nil || request.Method != Method {
+		t.Errorf("got %s, %v; want an elicitation request", line, err)
+		return
+	}
+	c.send(t, fmt.Sprintf(`{"jsonrpc":"2.0","id":%q,"result":%s}`, request.ID, result))
+}
+
+// session is a client session of the MCP server.
+type session string
+
+func (session) Initialize()                                         {}
+func (session) Initialized() bool                                   { return true }
+func (session) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s session) SessionID() string                                 { return string(s) }
+
+// withSession returns a context of a call from the session with id.
+func withSession(id string) context.Context {
+	return server.NewMCPServer("test", "0.0.0").WithContext(context.Background(), session(id))
+}
+
+func TestFilter(t *testing.T) {
+	destructive := true
+	e := New(clientrequest.New())
+	e.Protect([]mcp.Tool{{Name: "delete_cluster", Annotations: mcp.ToolAnnotation{DestructiveHint: &destructive}}})
+	tools := []mcp.Tool{
+		mcp.NewTool("delete_cluster", mcp.WithString("name")),
+		mcp.NewTool("get_cluster", mcp.WithString("name")),
+	}
+	filtered := e.Filter()(withSession("http-session"), tools)
+	if _, ok := filtered[0].InputSchema.Properties[ConfirmArgument]; !ok {
+		t.Errorf("delete_cluster properties = %v, want %s", filtered[0].InputSchema.Properties, ConfirmArgument)
+	}
+	if _, ok := filtered[1].InputSchema.Properties[ConfirmArgument]; ok {
+		t.Errorf("get_cluster got the %s argument", ConfirmArgument)
+	}
+	if _, ok := tools[0].InputSchema.Properties[ConfirmArgument]; ok {
+		t.Error("the registered tool was changed")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	destructive, readOnly := true, true
+	tools := []mcp.Tool{
+		{Name: "delete_cluster", Annotations: mcp.ToolAnnotation{DestructiveHint: &destructive}},
+		{Name: "delete_namespace", Annotations: mcp.ToolAnnotation{DestructiveHint: &destructive}},
+		{Name: "clear_server_state", Annotations: mcp.ToolAnnotation{DestructiveHint: &destructive}},
+		{Name: "get_cluster", Annotations: mcp.ToolAnnotation{ReadOnlyHint: &readOnly, DestructiveHint: &destructive}},
+	}
+	testCases := []struct {
+		name         string
+		capabilities string
+		tool         string
+		args         map[string]any
+		// answer is the result of the elicitation, empty if the user mustn't
+		// be asked.
+		answer string
+		// session is the ID of the calling session, stdio by default.
+		session string
+		wantRun bool
+	}{
+		{
+			name:         "typed cluster name",
+			capabilities: `{"elicitation":{}}`,
+			tool:         "delete_cluster",
+			args:         map[string]any{"name": "prod"},
+			answer:       `{"action":"accept","content":{"name":"prod"}}`,
+			wantRun:      true,
+		},
+		{
+			name:         "wrong cluster name",
+			capabilities: `{"elicitation":{}}`,
+			tool:         "delete_cluster",
+			args:         map[string]any{"name": "prod"},
+			answer:       `{"action":"accept","content":{"name":"staging"}}`,
+		},
+		{
+			name:         "declined",
+			capabilities: `{"elicitation":{}}`,
+			tool:         "delete_cluster",
+			args:         map[string]any{"name": "prod"},
+			answer:       `{"action":"decline"}`,
+		},
+		{
+			name:         "typed namespace rather than cluster",
+			capabilities: `{"elicitation":{}}`,
+			tool:         "delete_namespace",
+			args:         map[string]any{"cluster": "prod", "namespace": "shop"},
+			answer:       `{"action":"accept","content":{"name":"shop"}}`,
+			wantRun:      true,
+		},
+		{
+			name:         "yes",
+			capabilities: `{"elicitation":{}}`,
+			tool:         "clear_server_state",
+			answer:       `{"action":"accept","content":{"confirm":true}}`,
+			wantRun:      true,
+		},
+		{
+			name:         "no",
+			capabilities: `{"elicitation":{}}`,
+			tool:         "clear_server_state",
+			answer:       `{"action":"accept","content":{"confirm":false}}`,
+		},
+		{
+			name:         "dry run",
+			capabilities: `{"elicitation":{}}`,
+			tool:         "delete_cluster",
+			args:         map[string]any{"name": "prod", "dry_run": true},
+			wantRun:      true,
+		},
+		{
+			name:         "not destructive",
+			capabilities: `{"elicitation":{}}`,
+			tool:         "get_cluster",
+			args:         map[string]any{"name": "prod"},
+			wantRun:      true,
+		},
+		{
+			name:         "unsupported",
+			capabilities: `{}`,
+			tool:         "delete_cluster",
+			args:         map[string]any{"name": "prod"},
+		},
+		{
+			name:         "unsupported, typed cluster name",
+			capabilities: `{}`,
+			tool:         "delete_cluster",
+			args:         map[string]any{"name": "prod", "confirm": "prod"},
+			wantRun:      true,
+		},
+		{
+			name:         "unsupported, typed tool name",
+			capabilities: `{}`,
+			tool:         "clear_server_state",
+			args:         map[string]any{"confirm": "clear_server_state"},
+			wantRun:      true,
+		},
+		{
+			name:         "unsupported, wrong name",
+			capabilities: `{}`,
+			tool:         "delete_namespace",
+			args:         map[string]any{"cluster": "prod", "namespace": "shop", "confirm": "prod"},
+		},
+		{
+			name:         "other session",
+			capabilities: `{"elicitation":{}}`,
+			session:      "http-session",
+			tool:         "delete_cluster",
+			args:         map[string]any{"name": "prod"},
+		},
+		{
+			name:         "unsupported dry run",
+			capabilities: `{}`,
+			tool:         "delete_cluster",
+			args:         map[string]any{"name": "prod", "dry_run": true},
+			wantRun:      true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := clientrequest.New()
+			c := newClient(t, tr, tc.capabilities)
+			e := New(tr)
+			e.Protect(tools)
+			ran := false
+			handler := e.Middleware(config.New("test"))(func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				ran = true
+				if _, ok := request.GetArguments()[ConfirmArgument]; ok {
+					t.Errorf("the tool got the %s argument", ConfirmArgument)
+				}
+				return mcp.NewToolResultText("done"), nil
+			})
+			if tc.answer != "" {
+				go c.answer(t, tc.answer)
+			}
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tc.tool
+			request.Params.Arguments = tc.args
+			session := tc.session
+			if session == "" {
+				session = "stdio"
+			}
+			result, err := handler(withSession(session), request)
+			if err != nil {
+				t.Fatalf("handler() failed: %v", err)
+			}
+			if ran != tc.wantRun || result.IsError == tc.wantRun {
+				t.Errorf("handler() ran = %v with result %v; want ran = %v", ran, result, tc.wantRun)
+			}
+		})
+	}
+}
+
+func TestElicitCancelled(t *testing.T) {
+	tr := clientrequest.New()
+	c := newClient(t, tr, `{"elicitation":{}}`)
+	e := New(tr)
+	go c.out.ReadBytes('\n')
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.Elicit(ctx, "Sure?", map[string]any{"type": "object"}); err == nil {
+		t.Errorf("Elicit() with a cancelled context succeeded")
+	}
+}
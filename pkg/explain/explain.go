@@ -0,0 +1,146 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package explain shows the gcloud and kubectl commands equivalent to tool
+// calls, so users can learn and audit what the agent does.
+//
+// Tools declare how to build their commands with Command. The commands are
+// appended to dry run results, to every result with --explain-commands, and
+// returned by the explain_command tool without calling the tool.
+package explain
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// header introduces the commands in tool results.
+const header = "Equivalent command:"
+
+// Builder returns the commands equivalent to a call of a tool, or none if
+// the call has no equivalent, e.g. because required arguments are missing.
+type Builder func(ctx context.Context, request mcp.CallToolRequest) []string
+
+var (
+	mu       sync.Mutex
+	builders = map[string]Builder{}
+)
+
+// Command declares how to build the commands equivalent to calls of a tool.
+func Command(b Builder) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mu.Lock()
+		defer mu.Unlock()
+		builders[t.Name] = b
+	}
+}
+
+// Commands returns the commands equivalent to a call of tool, and whether
+// the tool declared how to build them.
+func Commands(ctx context.Context, tool string, request mcp.CallToolRequest) ([]string, bool) {
+	mu.Lock()
+	b, ok := builders[tool]
+	mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return b(ctx, request), true
+}
+
+// Middleware appends the equivalent commands to the successful results of
+// dry runs, and of every call if c enables it.
+func Middleware(c *config.Config) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError {
+				return result, err
+			}
+			if !c.ExplainCommands() && !dryrun.Enabled(request, c) {
+				return result, nil
+			}
+			commands, _ := Commands(ctx, request.Params.Name, request)
+			if len(commands) == 0 || mentions(result) {
+				return result, nil
+			}
+			result.Content = append(result.Content, mcp.NewTextContent(Format(commands)))
+			return result, nil
+		}
+	}
+}
+
+// mentions reports whether result already shows the equivalent command, as
+// dry run results of API calls do.
+func mentions(result *mcp.CallToolResult) bool {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok && strings.Contains(text.Text, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// Format renders commands for a tool result.
+func Format(commands []string) string {
+	return fmt.Sprintf("%s\n```sh\n%s\n```", header, strings.Join(commands, "\n"))
+}
+
+var safe = regexp.MustCompile(`^[-\w@%+=:,./]+$`)
+
+// Quote quotes s for a POSIX shell, if needed.
+func Quote(s string) string {
+	if safe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Join joins a command and its arguments, quoting the arguments, and
+// dropping the empty ones, so optional flags can be passed as "".
+func Join(command string, args ...string) string {
+	parts := []string{command}
+	for _, arg := range args {
+		if arg == "" {
+			continue
+		}
+		if name, value, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(name, "--") {
+			parts = append(parts, name+"="+Quote(value))
+			continue
+		}
+		parts = append(parts, Quote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Flag returns --name=value, or "" if value is empty, for Join.
+func Flag(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	return "--" + name + "=" + value
+}
+
+// GetCredentials returns the command configuring kubectl for a cluster,
+// which precedes the kubectl and helm commands.
+func GetCredentials(projectID, location, cluster string) string {
+	return Join("gcloud container clusters get-credentials", cluster, Flag("location", location), Flag("project", projectID))
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestJoin(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "plain",
+			args: []string{"prod-1", Flag("location", "us-central1"), Flag("project", "")},
+			want: "gcloud container clusters describe prod-1 --location=us-central1",
+		},
+		{
+			name: "quoted",
+			args: []string{`resource.type="k8s_container" AND severity>=ERROR`, Flag("filter", "it's")},
+			want: `gcloud container clusters describe 'resource.type="k8s_container" AND severity>=ERROR' --filter='it'\''s'`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Join("gcloud container clusters describe", tc.args...); got != tc.want {
+				t.Errorf("Join() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	// Declaring the tool registers its builder.
+	mcp.NewTool("explain_test_tool", Command(func(_ context.Context, request mcp.CallToolRequest) []string {
+		return []string{Join("gcloud container clusters describe", request.GetString("name", ""))}
+	}))
+	next := func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetBool("fail", false) {
+			return mcp.NewToolResultError("failed"), nil
+		}
+		return mcp.NewToolResultText("result"), nil
+	}
+
+	for _, tc := range []struct {
+		name      string
+		explain   bool
+		arguments map[string]any
+		want      []string
+	}{
+		{
+			name:      "disabled",
+			arguments: map[string]any{"name": "prod-1"},
+			want:      []string{"result"},
+		},
+		{
+			name:      "enabled",
+			explain:   true,
+			arguments: map[string]any{"name": "prod-1"},
+			want:      []string{"result", "Equivalent command:\n```sh\ngcloud container clusters describe prod-1\n```"},
+		},
+		{
+			name:      "dry run",
+			arguments: map[string]any{"name": "prod-1", "dry_run": true},
+			want:      []string{"result", "Equivalent command:\n```sh\ngcloud container clusters describe prod-1\n```"},
+		},
+		{
+			name:      "error",
+			explain:   true,
+			arguments: map[string]any{"name": "prod-1", "fail": true},
+			want:      []string{"failed"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.New("test", config.WithExplainCommands(tc.explain))
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "explain_test_tool"
+			request.Params.Arguments = tc.arguments
+			result, err := Middleware(c)(next)(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Middleware() failed: %v", err)
+			}
+			var got []string
+			for _, content := range result.Content {
+				got = append(got, content.(mcp.TextContent).Text)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Middleware() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gcp constructs the clients of the GCP APIs, and of the Kubernetes
+// API of GKE clusters, and keeps them for the calls that follow, so tools
+// reuse their connections instead of dialing, negotiating TLS and fetching
+// credentials on every call.
+//
+// Clients are kept per configuration and per caller identity, as
+// auth.CacheKey tells them apart, so no caller ever acts with another's
+// credentials. They are shared between concurrent calls and must not be
+// closed by their users; clients left unused for idleTimeout are closed.
+package gcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	container "cloud.google.com/go/container/apiv1"
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	logging "cloud.google.com/go/logging/apiv2"
+	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"google.golang.org/api/option"
+)
+
+const (
+	// idleTimeout is how long a client is kept after it was last used.
+	idleTimeout = 10 * time.Minute
+	// maxAge is how long a client is kept at most, so what it caches, like
+	// the resources discovered in a cluster, is eventually refreshed.
+	maxAge = time.Hour
+)
+
+type key struct {
+	c        *config.Config
+	kind     string
+	identity string
+}
+
+type entry struct {
+	client   io.Closer
+	created  time.Time
+	lastUsed time.Time
+}
+
+var (
+	mu      sync.Mutex
+	clients = map[key]*entry{}
+	now     = time.Now
+	// closeDelay is how long an expired client is kept open for the calls
+	// that got it just before it expired.
+	closeDelay = time.Minute
+)
+
+func init() {
+	// The clients of rejected server credentials would keep failing until
+	// they expire.
+	auth.OnReset(func() { Forget(auth.ServerIdentity) })
+}
+
+// get returns the client of a kind for the caller identified by ctx,
+// creating it with the client options of api if there is none yet.
+func get[T io.Closer](ctx context.Context, c *config.Config, api, kind string, create func(context.Context, ...option.ClientOption) (T, error)) (T, error) {
+	return reuse(ctx, c, kind, func(ctx context.Context) (T, error) {
+		var zero T
+		opts, err := auth.ClientOptions(ctx, c, api)
+		if err != nil {
+			return zero, err
+		}
+		client, err := create(ctx, opts...)
+		if err != nil {
+			return zero, fmt.Errorf("failed to create %s client: %w", kind, err)
+		}
+		return client, nil
+	})
+}
+
+// reuse returns the client of a kind for the caller identified by ctx,
+// creating it if there is none yet. Creation happens under the lock, so
+// concurrent first calls don't create the same client twice.
+func reuse[T io.Closer](ctx context.Context, c *config.Config, kind string, create func(context.Context) (T, error)) (T, error) {
+	k := key{c: c, kind: kind, identity: auth.CacheKey(ctx, c)}
+	mu.Lock()
+	defer mu.Unlock()
+	sweep()
+	if e, ok := clients[k]; ok {
+		e.lastUsed = now()
+		return e.client.(T), nil
+	}
+	// The client outlives the call creating it, so it must not be bound to
+	// its cancellation.
+	client, err := create(context.WithoutCancel(ctx))
+	if err != nil {
+		return client, err
+	}
+	clients[k] = &entry{client: client, created: now(), lastUsed: now()}
+	return client, nil
+}
+
+// sweep closes the clients left unused for idleTimeout, or older than
+// maxAge. mu must be held.
+func sweep() {
+	for k, e := range clients {
+		if now().Sub(e.lastUsed) < idleTimeout && now().Sub(e.created) < maxAge {
+			continue
+		}
+		delete(clients, k)
+		closeLater(k, e)
+	}
+}
+
+// Forget drops the clients of the caller identities, as auth.CacheKey
+// returns them, matching identity, e.g. after their credentials were
+// rejected, so the next call creates new ones.
+func Forget(identity func(string) bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	for k, e := range clients {
+		if identity(k.identity) {
+			delete(clients, k)
+			closeLater(k, e)
+		}
+	}
+}
+
+// closeLater closes the client of a dropped entry after closeDelay.
+func closeLater(k key, e *entry) {
+	time.AfterFunc(closeDelay, func() {
+		if err := e.client.Close(); err != nil {
+			slog.Debug("Failed to close dropped client", "kind", k.kind, "err", err)
+		}
+	})
+}
+
+// Close closes every client, e.g. when the server stops.
+func Close() {
+	mu.Lock()
+	defer mu.Unlock()
+	for k, e := range clients {
+		if err := e.client.Close(); err != nil {
+			slog.Debug("Failed to close client", "kind", k.kind, "err", err)
+		}
+	}
+	clear(clients)
+}
+
+// ClusterManager returns a client of the GKE API.
+func ClusterManager(ctx context.Context, c *config.Config) (*container.ClusterManagerClient, error) {
+	return get(ctx, c, config.APIContainer, "cluster manager", container.NewClusterManagerClient)
+}
+
+// Logging returns a client of the Cloud Logging API.
+func Logging(ctx context.Context, c *config.Config) (*logging.Client, error) {
+	return get(ctx, c, config.APILogging, "logging", logging.NewClient)
+}
+
+// Metrics returns a client of the Cloud Monitoring metrics API.
+func Metrics(ctx context.Context, c *config.Config) (*monitoring.MetricClient, error) {
+	return get(ctx, c, config.APIMonitoring, "metric", monitoring.NewMetricClient)
+}
+
+// Projects returns a client of the Resource Manager projects.
+func Projects(ctx context.Context, c *config.Config) (*resourcemanager.ProjectsClient, error) {
+	return get(ctx, c, config.APIResourceManager, "projects", resourcemanager.NewProjectsClient)
+}
+
+// Firewalls returns a client of the Compute Engine firewall rules.
+func Firewalls(ctx context.Context, c *config.Config) (*compute.FirewallsClient, error) {
+	return get(ctx, c, config.APICompute, "firewalls", compute.NewFirewallsRESTClient)
+}
+
+// Regions returns a client of the Compute Engine regions.
+func Regions(ctx context.Context, c *config.Config) (*compute.RegionsClient, error) {
+	return get(ctx, c, config.APICompute, "regions", compute.NewRegionsRESTClient)
+}
+
+// MachineTypes returns a client of the Compute Engine machine types.
+func MachineTypes(ctx context.Context, c *config.Config) (*compute.MachineTypesClient, error) {
+	return get(ctx, c, config.APICompute, "machine types", compute.NewMachineTypesRESTClient)
+}
+
+// SslCertificates returns a client of the Compute Engine SSL certificates.
+func SslCertificates(ctx context.Context, c *config.Config) (*compute.SslCertificatesClient, error) {
+	return get(ctx, c, config.APICompute, "SSL certificates", compute.NewSslCertificatesRESTClient)
+}
+
+// endpointCache keeps the clusters whose API was called, for their endpoint
+// and CA certificate.
+var endpointCache = cache.New[*containerpb.Cluster]("kube_endpoints")
+
+// Kubernetes returns a client of the Kubernetes API of a GKE cluster, acting
+// with the credentials of the caller identified by ctx.
+func Kubernetes(ctx context.Context, c *config.Config, projectID, location, name string) (*kube.Client, error) {
+	clusterName := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)
+	cluster, _, err := endpointCache.Get(ctx, clusterName+"|"+auth.CacheKey(ctx, c), c.CacheTTL(config.CacheClusters), false, func(ctx context.Context) (*containerpb.Cluster, error) {
+		cmClient, err := ClusterManager(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		return cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: clusterName})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefer the IP endpoint, which is signed by the cluster CA, and fall
+	// back to the DNS endpoint, which has a publicly trusted certificate.
+	var host string
+	var ca []byte
+	if endpoint := cluster.GetEndpoint(); endpoint != "" {
+		ca, err = base64.StdEncoding.DecodeString(cluster.GetMasterAuth().GetClusterCaCertificate())
+		if err != nil {
+			return nil, fmt.Errorf("invalid CA certificate of cluster %s: %w", name, err)
+		}
+		host = "https://" + endpoint
+	} else if dns := cluster.GetControlPlaneEndpointsConfig().GetDnsEndpointConfig(); dns.GetAllowExternalTraffic() && dns.GetEndpoint() != "" {
+		host = "https://" + dns.GetEndpoint()
+	} else {
+		return nil, fmt.Errorf("cluster %s has no reachable control plane endpoint", name)
+	}
+
+	// The client is kept per endpoint and CA, so one of a recreated cluster,
+	// or after a CA rotation, is not reused.
+	sum := sha256.Sum256(ca)
+	kind := fmt.Sprintf("kubernetes %s %x", host, sum[:8])
+	return reuse(ctx, c, kind, func(ctx context.Context) (*kube.Client, error) {
+		ts, err := auth.TokenSource(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if m := c.Mock(); m != nil {
+			return kube.NewWithTransport(host, m, ts), nil
+		}
+		return kube.New(host, ca, ts)
+	})
+}
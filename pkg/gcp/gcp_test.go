@@ -0,0 +1,120 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"golang.org/x/oauth2"
+)
+
+type fakeClient struct {
+	closed chan struct{}
+}
+
+func (f *fakeClient) Close() error {
+	close(f.closed)
+	return nil
+}
+
+func newFake(context.Context) (*fakeClient, error) {
+	return &fakeClient{closed: make(chan struct{})}, nil
+}
+
+func withToken(token string) context.Context {
+	return auth.WithTokenSource(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+}
+
+func TestReuse(t *testing.T) {
+	t.Cleanup(Close)
+	c := config.New("test")
+
+	first, err := reuse(withToken("alice"), c, "fake", newFake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := reuse(withToken("alice"), c, "fake", newFake)
+	if again != first {
+		t.Error("the client wasn't reused for the same caller")
+	}
+	if other, _ := reuse(withToken("bob"), c, "fake", newFake); other == first {
+		t.Error("the client of another caller was reused")
+	}
+	if other, _ := reuse(withToken("alice"), c, "other", newFake); other == first {
+		t.Error("the client of another kind was reused")
+	}
+	if other, _ := reuse(withToken("alice"), config.New("test"), "fake", newFake); other == first {
+		t.Error("the client of another configuration was reused")
+	}
+}
+
+func TestReuseClosesIdleClients(t *testing.T) {
+	t.Cleanup(Close)
+	start := time.Now()
+	current := start
+	now = func() time.Time { return current }
+	closeDelay = 0
+	t.Cleanup(func() { now, closeDelay = time.Now, time.Minute })
+	c := config.New("test")
+
+	idle, _ := reuse(withToken("alice"), c, "fake", newFake)
+	used, _ := reuse(withToken("bob"), c, "fake", newFake)
+	current = start.Add(idleTimeout / 2)
+	reuse(withToken("bob"), c, "fake", newFake)
+	current = start.Add(idleTimeout)
+
+	if again, _ := reuse(withToken("bob"), c, "fake", newFake); again != used {
+		t.Error("a client in use was replaced")
+	}
+	select {
+	case <-idle.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the idle client wasn't closed")
+	}
+	if again, _ := reuse(withToken("alice"), c, "fake", newFake); again == idle {
+		t.Error("a closed client was reused")
+	}
+
+	current = start.Add(maxAge)
+	if again, _ := reuse(withToken("bob"), c, "fake", newFake); again == used {
+		t.Error("a client older than maxAge was reused")
+	}
+}
+
+func TestForget(t *testing.T) {
+	t.Cleanup(Close)
+	closeDelay = 0
+	t.Cleanup(func() { closeDelay = time.Minute })
+	c := config.New("test")
+
+	server, _ := reuse(context.Background(), c, "fake", newFake)
+	session, _ := reuse(withToken("alice"), c, "fake", newFake)
+	Forget(auth.ServerIdentity)
+	select {
+	case <-server.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the client of the server's credentials wasn't closed")
+	}
+	if again, _ := reuse(context.Background(), c, "fake", newFake); again == server {
+		t.Error("a forgotten client was reused")
+	}
+	if again, _ := reuse(withToken("alice"), c, "fake", newFake); again != session {
+		t.Error("the client of a session was forgotten")
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package governor keeps tool results from flooding the client's context
+// window. Results above a size limit are replaced by a summary (counts, the
+// first few items and anything that looks wrong), or one written by the
+// client's model, unless the caller asks for the full output. The raw result
+// stays available as a resource.
+package governor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// FullArgument is the name of the tool argument that disables summarization.
+const FullArgument = "full_output"
+
+const (
+	// topN is the number of items or lines quoted in a summary.
+	topN = 10
+	// noteTokens is the size under which result items, such as notes, are
+	// always kept as they are.
+	noteTokens = 500
+)
+
+// FullOption declares the argument that disables summarization on a tool
+// that can return large results.
+func FullOption() mcp.ToolOption {
+	return mcp.WithBoolean(FullArgument, mcp.Description("Return the full result even if it is very large. Only set this if a previous call returned a summary and the details are really needed; prefer narrowing the query instead."))
+}
+
+// fullTools keeps the names of the tools declaring FullArgument, the only
+// ones whose summaries suggest it.
+var fullTools sync.Map
+
+// Declare records which of tools declare FullArgument.
+func Declare(tools []mcp.Tool) {
+	for _, tool := range tools {
+		if _, ok := tool.InputSchema.Properties[FullArgument]; ok {
+			fullTools.Store(tool.Name, true)
+		}
+	}
+}
+
+// EstimateTokens returns the approximate number of tokens in s, assuming about
+// four bytes per token.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// Summarizer summarizes text, the result of a call of tool, e.g. by sampling
+// the client's model. If it fails, the result is summarized by Summarize.
+type Summarizer func(ctx context.Context, tool, text string) (string, error)
+
+// Middleware returns a tool middleware that summarizes results larger than
+// maxTokens, with summarize if it isn't nil. It does nothing if maxTokens is
+// 0.
+func Middleware(maxTokens int, summarize Summarizer) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if maxTokens <= 0 || result == nil || result.IsError || request.GetBool(FullArgument, false) {
+				return result, err
+			}
+			total := 0
+			for _, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					total += EstimateTokens(text.Text)
+				}
+			}
+			if total <= maxTokens {
+				return result, err
+			}
+			// The structured result is as large as the text, so it is
+			// dropped along with the details.
+			var contents []mcp.Content
+			for _, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok && EstimateTokens(text.Text) > noteTokens {
+					content = mcp.NewTextContent(summarizeResult(ctx, request.Params.Name, text.Text, maxTokens, summarize))
+				} else if structured.IsStructured(content) {
+					delete(result.Meta, structured.SchemaMetaField)
+					continue
+				}
+				contents = append(contents, content)
+			}
+			result.Content = contents
+			return result, err
+		}
+	}
+}
+
+// summarizeResult returns a summary of text, the result of a call of tool,
+// with summarize if it isn't nil, and the URI of the raw result.
+func summarizeResult(ctx context.Context, tool, text string, maxTokens int, summarize Summarizer) string {
+	var summary string
+	if summarize != nil {
+		s, err := summarize(ctx, tool, text)
+		if err != nil {
+			slog.Debug("Failed to summarize the result with the summarizer", "tool", tool, "err", err)
+		} else {
+			summary = header(tool, text, maxTokens) + "Summary written by your model:\n\n" + strings.TrimSpace(s) + "\n"
+		}
+	}
+	if summary == "" {
+		summary = Summarize(tool, text, maxTokens)
+	}
+	return summary + fmt.Sprintf("\nThe raw result can be read as the resource %s, for a while.\n", results.put(text))
+}
+
+func header(tool, text string, maxTokens int) string {
+	h := fmt.Sprintf("The full result is about %d tokens, more than the limit of %d, so it was summarized. Narrow the query", EstimateTokens(text), maxTokens)
+	if _, ok := fullTools.Load(tool); ok {
+		h += fmt.Sprintf(", or call the tool again with %s=true if the details are really needed", FullArgument)
+	}
+	return h + ".\n\n"
+}
+
+// Summarize returns a summary of text, the result of a call of tool, which is
+// too large to return as is.
+func Summarize(tool, text string, maxTokens int) string {
+	b := new(strings.Builder)
+	b.WriteString(header(tool, text, maxTokens))
+
+	dec := json.NewDecoder(strings.NewReader(text))
+	var v any
+	if err := dec.Decode(&v); err == nil {
+		summarizeJSON(b, "", v)
+		// Keep short trailing notes, e.g. on caching or paging.
+		if rest := strings.TrimSpace(text[dec.InputOffset():]); rest != "" && EstimateTokens(rest) <= noteTokens {
+			fmt.Fprintf(b, "\n%s\n", rest)
+		}
+		return b.String()
+	}
+	summarizeText(b, text)
+	return b.String()
+}
+
+func summarizeJSON(b *strings.Builder, path string, v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := slices.Sorted(maps.Keys(v))
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			switch field := v[k].(type) {
+			case []any:
+				summarizeList(b, p, field)
+			case map[string]any:
+				if path == "" {
+					summarizeJSON(b, p, field)
+				} else {
+					fmt.Fprintf(b, "%s: object with %d fields\n", p, len(field))
+				}
+			default:
+				fmt.Fprintf(b, "%s: %s\n", p, truncate(fmt.Sprint(field), 200))
+			}
+		}
+	case []any:
+		summarizeList(b, path, v)
+	default:
+		fmt.Fprintf(b, "%s\n", truncate(fmt.Sprint(v), 200))
+	}
+}
+
+// statusFields are item fields worth counting by value.
+var statusFields = []string{"status", "state", "severity", "priority"}
+
+// anomalous matches status values that need attention.
+var anomalous = regexp.MustCompile(`(?i)^(error|degraded|failed|failure|critical|alert|emergency|stopping|unhealthy|p1)$`)
+
+func summarizeList(b *strings.Builder, path string, items []any) {
+	if path == "" {
+		path = "items"
+	}
+	fmt.Fprintf(b, "%s: %d items\n", path, len(items))
+
+	counts := map[string]map[string]int{}
+	var labels, anomalies []string
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			if len(labels) < topN {
+				labels = append(labels, truncate(fmt.Sprint(item), 100))
+			}
+			continue
+		}
+		label := itemLabel(obj)
+		if len(labels) < topN {
+			labels = append(labels, label)
+		}
+		for _, field := range statusFields {
+			value, ok := obj[field].(string)
+			if !ok {
+				continue
+			}
+			if counts[field] == nil {
+				counts[field] = map[string]int{}
+			}
+			counts[field][value]++
+			if anomalous.MatchString(value) && len(anomalies) < topN {
+				anomalies = append(anomalies, fmt.Sprintf("%s (%s=%s)", label, field, value))
+			}
+		}
+	}
+	for _, field := range statusFields {
+		if counts[field] == nil {
+			continue
+		}
+		var parts []string
+		for _, value := range slices.Sorted(maps.Keys(counts[field])) {
+			parts = append(parts, fmt.Sprintf("%s=%d", value, counts[field][value]))
+		}
+		fmt.Fprintf(b, "  by %s: %s\n", field, strings.Join(parts, ", "))
+	}
+	if len(labels) > 0 {
+		fmt.Fprintf(b, "  first %d: %s\n", len(labels), strings.Join(labels, ", "))
+	}
+	if len(anomalies) > 0 {
+		fmt.Fprintf(b, "  needing attention: %s\n", strings.Join(anomalies, ", "))
+	}
+}
+
+// itemLabel returns a short name for a list item.
+func itemLabel(obj map[string]any) string {
+	for _, field := range []string{"name", "displayName", "id", "insertId", "selfLink"} {
+		if s, ok := obj[field].(string); ok && s != "" {
+			return truncate(s, 100)
+		}
+	}
+	return fmt.Sprintf("object with %d fields", len(obj))
+}
+
+// problem matches log lines that need attention.
+var problem = regexp.MustCompile(`\b(ERROR|CRITICAL|ALERT|EMERGENCY|WARNING|FATAL|panic)\b`)
+
+func summarizeText(b *strings.Builder, text string) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	fmt.Fprintf(b, "%d lines\n", len(lines))
+
+	var problems []string
+	count := 0
+	for _, line := range lines {
+		if problem.MatchString(line) {
+			count++
+			if len(problems) < topN {
+				problems = append(problems, truncate(line, 300))
+			}
+		}
+	}
+
+	fmt.Fprintf(b, "\nFirst lines:\n")
+	for _, line := range lines[:min(topN, len(lines))] {
+		fmt.Fprintf(b, "%s\n", truncate(line, 300))
+	}
+	if count > 0 {
+		fmt.Fprintf(b, "\n%d lines mention errors or warnings, the first ones are:\n", count)
+		for _, line := range problems {
+			fmt.Fprintf(b, "%s\n", line)
+		}
+	}
+	if len(lines) > topN {
+		fmt.Fprintf(b, "\nLast lines:\n")
+		for _, line := range lines[max(topN, len(lines)-3):] {
+			fmt.Fprintf(b, "%s\n", truncate(line, 300))
+		}
+	}
+}
+
+// truncate cuts s to at most n bytes, on a rune boundary.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
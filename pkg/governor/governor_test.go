@@ -0,0 +1,207 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package governor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSummarizeJSON(t *testing.T) {
+	var clusters []string
+	for i := range 30 {
+		status := "RUNNING"
+		if i == 7 {
+			status = "DEGRADED"
+		}
+		clusters = append(clusters, fmt.Sprintf(`{"name":"cluster-%d","status":%q}`, i, status))
+	}
+	text := `{"clusters":[` + strings.Join(clusters, ",") + "]}\nNote: cached 5s ago."
+
+	got := Summarize("list_clusters", text, 100)
+	for _, want := range []string{
+		"clusters: 30 items",
+		"by status: DEGRADED=1, RUNNING=29",
+		"first 10: cluster-0, cluster-1",
+		"needing attention: cluster-7 (status=DEGRADED)",
+		"Note: cached 5s ago.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Summarize() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestSummarizeText(t *testing.T) {
+	var lines []string
+	for i := range 50 {
+		severity := "INFO"
+		if i == 42 {
+			severity = "ERROR"
+		}
+		lines = append(lines, fmt.Sprintf("line %d [%s] something happened", i, severity))
+	}
+
+	got := Summarize("query_logs", strings.Join(lines, "\n"), 100)
+	for _, want := range []string{
+		"50 lines",
+		"line 0 [INFO]",
+		"1 lines mention errors or warnings",
+		"line 42 [ERROR]",
+		"line 49 [INFO]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Summarize() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	large := strings.Repeat("x", 4000)
+	tests := []struct {
+		name          string
+		maxTokens     int
+		args          map[string]any
+		wantSummarize bool
+	}{
+		{
+			name:          "large result is summarized",
+			maxTokens:     100,
+			wantSummarize: true,
+		},
+		{
+			name:      "small result is kept",
+			maxTokens: 10000,
+		},
+		{
+			name:      "full output is kept",
+			maxTokens: 100,
+			args:      map[string]any{FullArgument: true},
+		},
+		{
+			name: "disabled",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := Middleware(tc.maxTokens, nil)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return structured.Result(large, structured.Findings, map[string]any{"findings": []string{large}})
+			})
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.args
+			result, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if summarized := text != large; summarized != tc.wantSummarize {
+				t.Errorf("result summarized = %v, want %v", summarized, tc.wantSummarize)
+			}
+			// The structured result is dropped with the details.
+			if got := len(result.Content); got != 1 && tc.wantSummarize || got != 2 && !tc.wantSummarize {
+				t.Errorf("result has %d contents, want the structured result only if it isn't summarized", got)
+			}
+		})
+	}
+}
+
+func TestMiddlewareSummarizer(t *testing.T) {
+	large := strings.Repeat("log line\n", 1000)
+	tests := []struct {
+		name      string
+		summarize Summarizer
+		want      string
+	}{
+		{
+			name: "summarizer",
+			summarize: func(_ context.Context, tool, _ string) (string, error) {
+				return "summary of " + tool, nil
+			},
+			want: "summary of query_logs",
+		},
+		{
+			name: "failing summarizer",
+			summarize: func(context.Context, string, string) (string, error) {
+				return "", errors.New("sampling is not supported")
+			},
+			want: "1000 lines",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := Middleware(100, tc.summarize)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(large), nil
+			})
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "query_logs"
+			result, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if !strings.Contains(text, tc.want) {
+				t.Errorf("result = %q, want it to contain %q", text, tc.want)
+			}
+
+			// The raw result can be read from the URI in the summary.
+			uri := regexp.MustCompile(regexp.QuoteMeta(resultPrefix) + `\w+`).FindString(text)
+			read := mcp.ReadResourceRequest{}
+			read.Params.URI = uri
+			contents, err := readResult(context.Background(), read)
+			if err != nil {
+				t.Fatalf("readResult(%q) failed: %v", uri, err)
+			}
+			if got := contents[0].(mcp.TextResourceContents).Text; got != large {
+				t.Errorf("raw result = %q, want the full result", got)
+			}
+		})
+	}
+}
+
+func TestResultStore(t *testing.T) {
+	r := &resultStore{texts: map[string]string{}}
+	first := r.put("first")
+	for range maxResults {
+		r.put("later")
+	}
+	if _, ok := r.get(first); ok {
+		t.Errorf("get() found the oldest result after %d more were stored", maxResults)
+	}
+}
+
+func TestTruncateKeepsRunes(t *testing.T) {
+	got := truncate("héllo", 2)
+	if !utf8.ValidString(got) || got != "h..." {
+		t.Errorf("truncate() = %q, want %q", got, "h...")
+	}
+}
+
+func TestSummarizeSuggestsFullOutput(t *testing.T) {
+	Declare([]mcp.Tool{mcp.NewTool("query_logs", FullOption()), mcp.NewTool("list_clusters")})
+	text := strings.Repeat("line\n", 200)
+	for tool, want := range map[string]bool{"query_logs": true, "list_clusters": false} {
+		if got := strings.Contains(Summarize(tool, text, 100), FullArgument); got != want {
+			t.Errorf("Summarize() for %s suggests %s = %v, want %v", tool, FullArgument, got, want)
+		}
+	}
+}
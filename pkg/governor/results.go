@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package governor
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// resultPrefix is the URI prefix of the raw results of summarized tool
+	// calls.
+	resultPrefix = "gke-mcp://raw/"
+	// maxResults is the number of raw results kept.
+	maxResults = 20
+)
+
+// resultStore keeps the raw results of the last summarized tool calls. Their
+// IDs are random, so only the client a result was returned to can read it.
+type resultStore struct {
+	mu    sync.Mutex
+	texts map[string]string
+	order []string
+}
+
+var results = &resultStore{texts: map[string]string{}}
+
+// put stores text, dropping the oldest result if there are too many, and
+// returns its URI.
+func (r *resultStore) put(text string) string {
+	id := rand.Text()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.texts[id] = text
+	r.order = append(r.order, id)
+	if len(r.order) > maxResults {
+		delete(r.texts, r.order[0])
+		r.order = r.order[1:]
+	}
+	return resultPrefix + id
+}
+
+func (r *resultStore) get(uri string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	text, ok := r.texts[strings.TrimPrefix(uri, resultPrefix)]
+	return text, ok
+}
+
+// Install publishes the raw results of summarized tool calls as resources.
+func Install(s *server.MCPServer) {
+	s.AddResourceTemplate(mcp.NewResourceTemplate(resultPrefix+"{id}", "Raw tool result",
+		mcp.WithTemplateDescription("The raw result of a tool call that was too large to return and was summarized instead."),
+		mcp.WithTemplateMIMEType("text/plain"),
+	), readResult)
+}
+
+func readResult(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	text, ok := results.get(request.Params.URI)
+	if !ok {
+		return nil, fmt.Errorf("raw result %s not found: only the last %d are kept; call the tool again", request.Params.URI, maxResults)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "text/plain",
+			Text:     text,
+		},
+	}, nil
+}
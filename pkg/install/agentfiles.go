@@ -0,0 +1,111 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package install
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AgentFiles are the names of the instruction files of other agents, by the
+// format names accepted by WriteAgentFile.
+var AgentFiles = map[string]string{
+	"claude-md":   "CLAUDE.md",
+	"cursorrules": ".cursorrules",
+	"agents-md":   "AGENTS.md",
+}
+
+// The generated instructions are kept between these markers, so the rest of
+// an existing file is kept and installing again replaces them.
+const (
+	beginMarker = "<!-- BEGIN gke-mcp: generated by `gke-mcp install`, edits between these markers are overwritten -->"
+	endMarker   = "<!-- END gke-mcp -->"
+)
+
+// Defaults are the defaults the generated instructions tell the agent to
+// use, each omitted if empty.
+type Defaults struct {
+	Project  string
+	Location string
+	Cluster  string
+}
+
+// RenderInstructions returns the bundled instructions for any agent, with
+// the defaults to use.
+func RenderInstructions(d Defaults) []byte {
+	md := string(GeminiMarkdown)
+	// The bundled instructions are written for Gemini CLI.
+	md = strings.Replace(md, "# GKE MCP Extension for Gemini CLI", "# GKE MCP Server", 1)
+	md = strings.ReplaceAll(md, "using the Gemini CLI with GIQ", "with GIQ")
+
+	var defaults []string
+	for _, v := range []struct{ name, value string }{
+		{"Project", d.Project},
+		{"Location", d.Location},
+		{"Cluster", d.Cluster},
+	} {
+		if v.value != "" {
+			defaults = append(defaults, fmt.Sprintf("- %s: `%s`", v.name, v.value))
+		}
+	}
+	if len(defaults) > 0 {
+		title, rest, _ := strings.Cut(md, "\n")
+		md = fmt.Sprintf("%s\n\n## Defaults\n\nUnless the user says otherwise, work with:\n\n%s\n%s", title, strings.Join(defaults, "\n"), rest)
+	}
+	return []byte(md)
+}
+
+// WriteAgentFile writes the instructions rendered with d to the agent
+// instruction file at path, replacing the ones written before and keeping
+// the rest of the file.
+func WriteAgentFile(path string, d Defaults) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not read %s: %w", path, err)
+	}
+	block := fmt.Appendf(nil, "%s\n%s\n%s\n", beginMarker, bytes.TrimSpace(RenderInstructions(d)), endMarker)
+	if rest, start := removeBlock(data); start >= 0 {
+		// Replace the block where it was.
+		data = append(append(rest[:start:start], block...), rest[start:]...)
+	} else if len(bytes.TrimSpace(data)) > 0 {
+		data = append(append(bytes.TrimRight(data, "\n"), "\n\n"...), block...)
+	} else {
+		data = block
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	return nil
+}
+
+// removeBlock returns data without the generated instructions and where
+// they started, or data and -1 if it doesn't have them.
+func removeBlock(data []byte) ([]byte, int) {
+	start := bytes.Index(data, []byte(beginMarker))
+	if start < 0 {
+		return data, -1
+	}
+	end := bytes.Index(data[start:], []byte(endMarker))
+	if end < 0 {
+		return data, -1
+	}
+	end += start + len(endMarker)
+	if end < len(data) && data[end] == '\n' {
+		end++
+	}
+	return append(bytes.Clone(data[:start]), data[end:]...), start
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderInstructions(t *testing.T) {
+	got := string(RenderInstructions(Defaults{Project: "my-project", Cluster: "prod"}))
+	if strings.Contains(got, "Gemini CLI") {
+		t.Errorf("RenderInstructions() mentions Gemini CLI:\n%s", got)
+	}
+	want := "# GKE MCP Server\n\n## Defaults\n\nUnless the user says otherwise, work with:\n\n- Project: `my-project`\n- Cluster: `prod`\n"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("RenderInstructions() starts with:\n%s\nwant:\n%s", got[:min(len(got), len(want))], want)
+	}
+	if got := string(RenderInstructions(Defaults{})); strings.Contains(got, "## Defaults") {
+		t.Errorf("RenderInstructions() without defaults has a Defaults section")
+	}
+}
+
+func TestWriteAgentFile(t *testing.T) {
+	baseDir := t.TempDir()
+	path := filepath.Join(baseDir, "CLAUDE.md")
+	before := "# My project\n\nRun `make test` before committing.\n"
+	after := "\n## Style\n\nUse tabs.\n"
+	if err := os.WriteFile(path, []byte(before), 0600); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	if err := WriteAgentFile(path, Defaults{Project: "old-project"}); err != nil {
+		t.Fatalf("WriteAgentFile() failed: %v", err)
+	}
+	// Content added after the generated instructions is kept when they are
+	// replaced.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, after...), 0600); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	if err := WriteAgentFile(path, Defaults{Project: "new-project"}); err != nil {
+		t.Fatalf("WriteAgentFile() again failed: %v", err)
+	}
+
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %v", err)
+	}
+	got := string(data)
+	if !strings.HasPrefix(got, before+"\n"+beginMarker) || !strings.HasSuffix(got, endMarker+"\n"+after) {
+		t.Errorf("WriteAgentFile() didn't keep the rest of the file:\n%s", got)
+	}
+	if strings.Count(got, beginMarker) != 1 || strings.Contains(got, "old-project") || !strings.Contains(got, "new-project") {
+		t.Errorf("WriteAgentFile() didn't replace the generated instructions:\n%s", got)
+	}
+
+	// Uninstalling removes the generated instructions only, and deletes the
+	// files that only had them.
+	agents := filepath.Join(baseDir, "AGENTS.md")
+	if err := WriteAgentFile(agents, Defaults{}); err != nil {
+		t.Fatalf("WriteAgentFile() failed: %v", err)
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	if _, err := Uninstall(baseDir, false); err != nil {
+		t.Fatalf("Uninstall() failed: %v", err)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil || string(data) != before+after {
+		t.Errorf("CLAUDE.md after Uninstall() = %q, %v; want %q", data, err, before+after)
+	}
+	if _, err := os.Stat(agents); !os.IsNotExist(err) {
+		t.Errorf("Stat(AGENTS.md) after Uninstall() = %v, want it deleted", err)
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package install
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ServerName is the name of the server's entry in the MCP configuration of
+// the clients.
+const ServerName = "gke-mcp"
+
+// Scopes of the MCP configuration of a client.
+const (
+	// ScopeUser configures the server for every project of the user.
+	ScopeUser = "user"
+	// ScopeProject configures the server for the project in the current
+	// directory, in a file that can be checked in.
+	ScopeProject = "project"
+)
+
+// ClaudeDesktopConfigPath returns the path of the Claude Desktop
+// configuration file, in the user configuration directory of the OS, e.g.
+// ~/Library/Application Support/Claude on macOS and %APPDATA%\Claude on
+// Windows.
+func ClaudeDesktopConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "Claude", "claude_desktop_config.json"), nil
+}
+
+// ClaudeDesktop adds the server, run as exePath with args, to the Claude
+// Desktop configuration file at configPath, keeping the other servers and
+// settings.
+func ClaudeDesktop(configPath, exePath string, args []string) error {
+	return addServer(configPath, "mcpServers", map[string]any{
+		"command": exePath,
+		"args":    serverArgs(args),
+	})
+}
+
+// ClaudeCodeConfigPath returns the path of the Claude Code configuration
+// file of scope: ~/.claude.json for the user, or .mcp.json in baseDir for
+// the project.
+func ClaudeCodeConfigPath(scope, baseDir string) (string, error) {
+	switch scope {
+	case ScopeUser:
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".claude.json"), nil
+	case ScopeProject:
+		return filepath.Join(baseDir, ".mcp.json"), nil
+	default:
+		return "", fmt.Errorf("unsupported scope %q for Claude Code, must be %s or %s", scope, ScopeUser, ScopeProject)
+	}
+}
+
+// ClaudeCode adds the server, run as exePath with args, to the Claude Code
+// configuration file at configPath, keeping the other servers and settings.
+func ClaudeCode(configPath, exePath string, args []string) error {
+	return addServer(configPath, "mcpServers", map[string]any{
+		"type":    "stdio",
+		"command": exePath,
+		"args":    serverArgs(args),
+	})
+}
+
+// CursorConfigPath returns the path of the Cursor MCP configuration file of
+// scope: ~/.cursor/mcp.json for the user, or .cursor/mcp.json in baseDir for
+// the project.
+func CursorConfigPath(scope, baseDir string) (string, error) {
+	switch scope {
+	case ScopeUser:
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".cursor", "mcp.json"), nil
+	case ScopeProject:
+		return filepath.Join(baseDir, ".cursor", "mcp.json"), nil
+	default:
+		return "", fmt.Errorf("unsupported scope %q for Cursor, must be %s or %s", scope, ScopeUser, ScopeProject)
+	}
+}
+
+// Cursor adds the server, run as exePath with args, to the Cursor MCP
+// configuration file at configPath, keeping the other servers and settings.
+// If rulesDir isn't empty, the instructions are also written there as an
+// agent-requested rule, since Cursor reads them from project rules.
+func Cursor(configPath, rulesDir, exePath string, args []string) error {
+	if err := addServer(configPath, "mcpServers", map[string]any{
+		"type":    "stdio",
+		"command": exePath,
+		"args":    serverArgs(args),
+	}); err != nil {
+		return err
+	}
+	if rulesDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(rulesDir, 0755); err != nil {
+		return fmt.Errorf("could not create rules directory: %w", err)
+	}
+	rule := append([]byte(cursorRuleMetadata), GeminiMarkdown...)
+	if err := os.WriteFile(filepath.Join(rulesDir, "gke-mcp.mdc"), rule, 0644); err != nil {
+		return fmt.Errorf("could not write gke-mcp.mdc: %w", err)
+	}
+	return nil
+}
+
+// cursorRuleMetadata makes the instructions an agent-requested Cursor rule,
+// included when relevant to the conversation.
+const cursorRuleMetadata = `---
+description: Provides guidance for using the gke-mcp tools to work with Google Kubernetes Engine.
+alwaysApply: false
+---
+
+`
+
+// VSCodeConfigPath returns the path of the VS Code MCP configuration file
+// of scope: mcp.json in the VS Code user directory for the user, or
+// .vscode/mcp.json in baseDir for the project.
+func VSCodeConfigPath(scope, baseDir string) (string, error) {
+	switch scope {
+	case ScopeUser:
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "Code", "User", "mcp.json"), nil
+	case ScopeProject:
+		return filepath.Join(baseDir, ".vscode", "mcp.json"), nil
+	default:
+		return "", fmt.Errorf("unsupported scope %q for VS Code, must be %s or %s", scope, ScopeUser, ScopeProject)
+	}
+}
+
+// VSCode adds the server, run as exePath with args, to the VS Code MCP
+// configuration file at configPath, keeping the other servers and settings.
+// The instructions are written to .github/instructions in workspaceDir, from
+// where Copilot applies them to every request in the workspace.
+func VSCode(configPath, workspaceDir, exePath string, args []string) error {
+	if err := addServer(configPath, "servers", map[string]any{
+		"type":    "stdio",
+		"command": exePath,
+		"args":    serverArgs(args),
+	}); err != nil {
+		return err
+	}
+	instructionsDir := filepath.Join(workspaceDir, ".github", "instructions")
+	if err := os.MkdirAll(instructionsDir, 0755); err != nil {
+		return fmt.Errorf("could not create instructions directory: %w", err)
+	}
+	instructions := append([]byte(vsCodeInstructionsMetadata), GeminiMarkdown...)
+	if err := os.WriteFile(filepath.Join(instructionsDir, "gke-mcp.instructions.md"), instructions, 0644); err != nil {
+		return fmt.Errorf("could not write gke-mcp.instructions.md: %w", err)
+	}
+	return nil
+}
+
+// vsCodeInstructionsMetadata applies the instructions to every file of the
+// workspace.
+const vsCodeInstructionsMetadata = `---
+description: Guidance for using the gke-mcp tools to work with Google Kubernetes Engine.
+applyTo: "**"
+---
+
+`
+
+// WindsurfConfigPath returns the path of the Windsurf MCP configuration
+// file, ~/.codeium/windsurf/mcp_config.json. Windsurf has no project
+// configuration.
+func WindsurfConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".codeium", "windsurf", "mcp_config.json"), nil
+}
+
+// Windsurf adds the server, run as exePath with args, to the Windsurf MCP
+// configuration file at configPath, keeping the other servers and settings.
+func Windsurf(configPath, exePath string, args []string) error {
+	return addServer(configPath, "mcpServers", map[string]any{
+		"command": exePath,
+		"args":    serverArgs(args),
+	})
+}
+
+// ZedConfigPath returns the path of the Zed settings file of scope:
+// settings.json in the Zed configuration directory for the user, or
+// .zed/settings.json in baseDir for the project.
+func ZedConfigPath(scope, baseDir string) (string, error) {
+	switch scope {
+	case ScopeUser:
+		// Zed uses ~/.config on macOS too.
+		if runtime.GOOS == "windows" {
+			dir, err := os.UserConfigDir()
+			if err != nil {
+				return "", err
+			}
+			return filepath.Join(dir, "Zed", "settings.json"), nil
+		}
+		dir := os.Getenv("XDG_CONFIG_HOME")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dir = filepath.Join(home, ".config")
+		}
+		return filepath.Join(dir, "zed", "settings.json"), nil
+	case ScopeProject:
+		return filepath.Join(baseDir, ".zed", "settings.json"), nil
+	default:
+		return "", fmt.Errorf("unsupported scope %q for Zed, must be %s or %s", scope, ScopeUser, ScopeProject)
+	}
+}
+
+// Zed adds the server, run as exePath with args, to the context servers of
+// the Zed settings file at configPath, keeping the other servers and
+// settings.
+func Zed(configPath, exePath string, args []string) error {
+	return addServer(configPath, "context_servers", map[string]any{
+		"source":  "custom",
+		"command": exePath,
+		"args":    serverArgs(args),
+	})
+}
+
+// serverArgs returns args, or an empty list rather than null if there are
+// none.
+func serverArgs(args []string) []string {
+	if args == nil {
+		return []string{}
+	}
+	return args
+}
+
+// addServer sets the server's entry in the object under key of the JSON
+// configuration file at path, creating the file if it doesn't exist. Other
+// entries and settings are kept, so installing again only updates the
+// entry.
+func addServer(path, key string, entry map[string]any) error {
+	config, original, err := readConfig(path)
+	if err != nil {
+		return err
+	}
+	servers, ok := config[key].(map[string]any)
+	if !ok {
+		if _, exists := config[key]; exists {
+			return fmt.Errorf("could not update %s: %q is not an object", path, key)
+		}
+		servers = map[string]any{}
+	}
+	servers[ServerName] = entry
+	config[key] = servers
+	return writeConfig(path, config, original)
+}
+
+// readConfig returns the settings of the JSON configuration file at path,
+// which may have comments and trailing commas as several editors allow, and
+// its original content. A missing file has no settings.
+func readConfig(path string) (map[string]any, []byte, error) {
+	config := map[string]any{}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return config, nil, nil
+	case err != nil:
+		return nil, nil, fmt.Errorf("could not read %s: %w", path, err)
+	case len(bytes.TrimSpace(data)) == 0:
+		return config, data, nil
+	}
+	if err := json.Unmarshal(standardizeJSON(data), &config); err != nil {
+		return nil, nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return config, data, nil
+}
+
+// writeConfig writes the settings of the JSON configuration file at path.
+// Comments and trailing commas of the original content are dropped, and the
+// original is then kept with a .bak suffix.
+func writeConfig(path string, config map[string]any, original []byte) error {
+	if !bytes.Equal(standardizeJSON(original), original) {
+		if err := os.WriteFile(path+".bak", original, 0600); err != nil {
+			return fmt.Errorf("could not back up %s: %w", path, err)
+		}
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	return nil
+}
+
+// standardizeJSON removes the comments and trailing commas of JSON with
+// comments.
+func standardizeJSON(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	// comma is the position in out of the last comma if only whitespace and
+	// comments followed it, or -1.
+	comma := -1
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			out = append(out, c)
+			continue
+		case (c == '}' || c == ']') && comma >= 0:
+			out = append(out[:comma], out[comma+1:]...)
+		}
+		comma = -1
+		switch c {
+		case '"':
+			// Copy strings as is, with their escapes.
+			j := i + 1
+			for j < len(data) && data[j] != '"' {
+				if data[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			end := min(j+1, len(data))
+			out = append(out, data[i:end]...)
+			i = end - 1
+		case ',':
+			comma = len(out)
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
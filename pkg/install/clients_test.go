@@ -0,0 +1,255 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package install
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func readJSON(t *testing.T, path string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	var actual map[string]any
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatalf("Failed to unmarshal %s: %v", path, err)
+	}
+	return actual
+}
+
+func TestClaudeDesktop(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "Claude", "claude_desktop_config.json")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatalf("os.MkdirAll() failed: %v", err)
+	}
+	existing := `{"mcpServers": {"other": {"command": "other-mcp"}}, "globalShortcut": "Ctrl+Space"}`
+	if err := os.WriteFile(configPath, []byte(existing), 0600); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+
+	// Installing twice updates the entry instead of adding another one.
+	for _, exePath := range []string{"/old/gke-mcp", "/usr/local/bin/gke-mcp"} {
+		if err := ClaudeDesktop(configPath, exePath, []string{"--read-only"}); err != nil {
+			t.Fatalf("ClaudeDesktop() failed: %v", err)
+		}
+	}
+
+	expected := map[string]any{
+		"globalShortcut": "Ctrl+Space",
+		"mcpServers": map[string]any{
+			"other":   map[string]any{"command": "other-mcp"},
+			"gke-mcp": map[string]any{"command": "/usr/local/bin/gke-mcp", "args": []any{"--read-only"}},
+		},
+	}
+	if diff := cmp.Diff(expected, readJSON(t, configPath)); diff != "" {
+		t.Errorf("Config content mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClaudeCode(t *testing.T) {
+	baseDir := t.TempDir()
+	configPath, err := ClaudeCodeConfigPath(ScopeProject, baseDir)
+	if err != nil {
+		t.Fatalf("ClaudeCodeConfigPath() failed: %v", err)
+	}
+	if want := filepath.Join(baseDir, ".mcp.json"); configPath != want {
+		t.Errorf("ClaudeCodeConfigPath(%q) = %q, want %q", ScopeProject, configPath, want)
+	}
+	if err := ClaudeCode(configPath, "/usr/local/bin/gke-mcp", nil); err != nil {
+		t.Fatalf("ClaudeCode() failed: %v", err)
+	}
+
+	expected := map[string]any{
+		"mcpServers": map[string]any{
+			"gke-mcp": map[string]any{
+				"type":    "stdio",
+				"command": "/usr/local/bin/gke-mcp",
+				"args":    []any{},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, readJSON(t, configPath)); diff != "" {
+		t.Errorf("Config content mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := ClaudeCodeConfigPath("workspace", baseDir); err == nil {
+		t.Errorf("ClaudeCodeConfigPath() with an unsupported scope succeeded, want an error")
+	}
+}
+
+func TestAddServerInvalidConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	for _, content := range []string{`not json`, `{"mcpServers": []}`} {
+		if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+			t.Fatalf("os.WriteFile() failed: %v", err)
+		}
+		if err := ClaudeDesktop(configPath, "gke-mcp", nil); err == nil {
+			t.Errorf("ClaudeDesktop() with config %s succeeded, want an error", content)
+		}
+		if data, _ := os.ReadFile(configPath); string(data) != content {
+			t.Errorf("ClaudeDesktop() changed invalid config to %s", data)
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	baseDir := t.TempDir()
+	configPath, err := CursorConfigPath(ScopeProject, baseDir)
+	if err != nil {
+		t.Fatalf("CursorConfigPath() failed: %v", err)
+	}
+	rulesDir := filepath.Join(baseDir, ".cursor", "rules")
+	for range 2 {
+		if err := Cursor(configPath, rulesDir, "/usr/local/bin/gke-mcp", []string{"--location", "us-central1"}); err != nil {
+			t.Fatalf("Cursor() failed: %v", err)
+		}
+	}
+
+	expected := map[string]any{
+		"mcpServers": map[string]any{
+			"gke-mcp": map[string]any{
+				"type":    "stdio",
+				"command": "/usr/local/bin/gke-mcp",
+				"args":    []any{"--location", "us-central1"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, readJSON(t, filepath.Join(baseDir, ".cursor", "mcp.json"))); diff != "" {
+		t.Errorf("Config content mismatch (-want +got):\n%s", diff)
+	}
+
+	rule, err := os.ReadFile(filepath.Join(rulesDir, "gke-mcp.mdc"))
+	if err != nil {
+		t.Fatalf("Failed to read the rule: %v", err)
+	}
+	if !strings.HasPrefix(string(rule), "---\n") || !strings.HasSuffix(string(rule), string(GeminiMarkdown)) {
+		t.Errorf("Rule doesn't hold the metadata followed by the instructions:\n%s", rule)
+	}
+}
+
+func TestVSCode(t *testing.T) {
+	workspaceDir := t.TempDir()
+	configPath, err := VSCodeConfigPath(ScopeProject, workspaceDir)
+	if err != nil {
+		t.Fatalf("VSCodeConfigPath() failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatalf("os.MkdirAll() failed: %v", err)
+	}
+	existing := `{"inputs": [], "servers": {"github": {"type": "http", "url": "https://api.githubcopilot.com/mcp/"}}}`
+	if err := os.WriteFile(configPath, []byte(existing), 0600); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	if err := VSCode(configPath, workspaceDir, "/usr/local/bin/gke-mcp", nil); err != nil {
+		t.Fatalf("VSCode() failed: %v", err)
+	}
+
+	expected := map[string]any{
+		"inputs": []any{},
+		"servers": map[string]any{
+			"github": map[string]any{"type": "http", "url": "https://api.githubcopilot.com/mcp/"},
+			"gke-mcp": map[string]any{
+				"type":    "stdio",
+				"command": "/usr/local/bin/gke-mcp",
+				"args":    []any{},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, readJSON(t, filepath.Join(workspaceDir, ".vscode", "mcp.json"))); diff != "" {
+		t.Errorf("Config content mismatch (-want +got):\n%s", diff)
+	}
+
+	instructions, err := os.ReadFile(filepath.Join(workspaceDir, ".github", "instructions", "gke-mcp.instructions.md"))
+	if err != nil {
+		t.Fatalf("Failed to read the instructions: %v", err)
+	}
+	if !strings.Contains(string(instructions), "applyTo: \"**\"") || !strings.HasSuffix(string(instructions), string(GeminiMarkdown)) {
+		t.Errorf("Instructions don't hold the metadata followed by the instructions:\n%s", instructions)
+	}
+}
+
+func TestZed(t *testing.T) {
+	baseDir := t.TempDir()
+	configPath, err := ZedConfigPath(ScopeProject, baseDir)
+	if err != nil {
+		t.Fatalf("ZedConfigPath() failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatalf("os.MkdirAll() failed: %v", err)
+	}
+	// Zed settings allow comments and trailing commas.
+	existing := `// Zed settings
+{
+  /* Theme */
+  "theme": "One Dark", // the default
+  "url": "https://example.com/a//b",
+  "languages": ["Go", "YAML",],
+}
+`
+	if err := os.WriteFile(configPath, []byte(existing), 0600); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	if err := Zed(configPath, "/usr/local/bin/gke-mcp", nil); err != nil {
+		t.Fatalf("Zed() failed: %v", err)
+	}
+
+	expected := map[string]any{
+		"theme":     "One Dark",
+		"url":       "https://example.com/a//b",
+		"languages": []any{"Go", "YAML"},
+		"context_servers": map[string]any{
+			"gke-mcp": map[string]any{
+				"source":  "custom",
+				"command": "/usr/local/bin/gke-mcp",
+				"args":    []any{},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, readJSON(t, configPath)); diff != "" {
+		t.Errorf("Config content mismatch (-want +got):\n%s", diff)
+	}
+	if backup, err := os.ReadFile(configPath + ".bak"); err != nil || string(backup) != existing {
+		t.Errorf("Backup of the settings with comments = %q, %v; want the original settings", backup, err)
+	}
+}
+
+func TestWindsurf(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".codeium", "windsurf", "mcp_config.json")
+	if err := Windsurf(configPath, "/usr/local/bin/gke-mcp", []string{"--read-only"}); err != nil {
+		t.Fatalf("Windsurf() failed: %v", err)
+	}
+
+	expected := map[string]any{
+		"mcpServers": map[string]any{
+			"gke-mcp": map[string]any{
+				"command": "/usr/local/bin/gke-mcp",
+				"args":    []any{"--read-only"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, readJSON(t, configPath)); diff != "" {
+		t.Errorf("Config content mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(configPath + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("Stat() of the backup = %v, want no backup of a new config", err)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package install
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Change is an edit made, or that would be made, by Uninstall.
+type Change struct {
+	Path string
+	// Key is the object of the configuration file the server's entry is
+	// removed from.
+	Key string
+	// Instructions is set if the generated instructions are removed from
+	// the agent instruction file.
+	Instructions bool
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Key != "":
+		return fmt.Sprintf("remove %s from %s in %s", ServerName, c.Key, c.Path)
+	case c.Instructions:
+		return "remove the generated instructions from " + c.Path
+	default:
+		return "delete " + c.Path
+	}
+}
+
+// configLocation is a configuration file the server may be installed in.
+type configLocation struct {
+	path string
+	// key is the object holding the servers.
+	key string
+}
+
+// Uninstall removes the server from the configuration of every client, for
+// the user and for the project in baseDir, and deletes the extension and
+// instruction files installed with it. The generated instructions are
+// removed from the agent instruction files. Files left empty are deleted. It returns the changes, which are only listed if
+// dryRun.
+func Uninstall(baseDir string, dryRun bool) ([]Change, error) {
+	locations, err := configLocations(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, l := range locations {
+		change, err := removeServer(l.path, l.key, dryRun)
+		if err != nil {
+			return changes, err
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Values(AgentFiles)) {
+		change, err := removeInstructions(filepath.Join(baseDir, name), dryRun)
+		if err != nil {
+			return changes, err
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(baseDir, ".gemini", "extensions", "gke-mcp"),
+		filepath.Join(baseDir, ".cursor", "rules", "gke-mcp.mdc"),
+		filepath.Join(baseDir, ".github", "instructions", "gke-mcp.instructions.md"),
+	} {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return changes, err
+		}
+		if !dryRun {
+			if err := os.RemoveAll(path); err != nil {
+				return changes, fmt.Errorf("could not delete %s: %w", path, err)
+			}
+		}
+		changes = append(changes, Change{Path: path})
+	}
+	return changes, nil
+}
+
+// configLocations returns the configuration files of every client, for the
+// user and for the project in baseDir.
+func configLocations(baseDir string) ([]configLocation, error) {
+	var locations []configLocation
+	add := func(key string, path string, err error) error {
+		if err != nil {
+			return err
+		}
+		// The user's and the project's files are the same in the home
+		// directory.
+		if slices.ContainsFunc(locations, func(l configLocation) bool { return l.path == path }) {
+			return nil
+		}
+		locations = append(locations, configLocation{path: path, key: key})
+		return nil
+	}
+	claudeDesktop, err := ClaudeDesktopConfigPath()
+	if err := add("mcpServers", claudeDesktop, err); err != nil {
+		return nil, err
+	}
+	windsurf, err := WindsurfConfigPath()
+	if err := add("mcpServers", windsurf, err); err != nil {
+		return nil, err
+	}
+	for _, scope := range []string{ScopeUser, ScopeProject} {
+		claudeCode, err := ClaudeCodeConfigPath(scope, baseDir)
+		if err := add("mcpServers", claudeCode, err); err != nil {
+			return nil, err
+		}
+		cursor, err := CursorConfigPath(scope, baseDir)
+		if err := add("mcpServers", cursor, err); err != nil {
+			return nil, err
+		}
+		vsCode, err := VSCodeConfigPath(scope, baseDir)
+		if err := add("servers", vsCode, err); err != nil {
+			return nil, err
+		}
+		zed, err := ZedConfigPath(scope, baseDir)
+		if err := add("context_servers", zed, err); err != nil {
+			return nil, err
+		}
+	}
+	return locations, nil
+}
+
+// removeServer removes the server's entry from the object under key of the
+// configuration file at path, deleting the file if nothing else is left in
+// it. It returns the change, or nil if the server isn't configured there,
+// and only returns it if dryRun.
+func removeServer(path, key string, dryRun bool) (*Change, error) {
+	config, original, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	servers, ok := config[key].(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	if _, ok := servers[ServerName]; !ok {
+		return nil, nil
+	}
+	delete(servers, ServerName)
+	if len(servers) == 0 {
+		delete(config, key)
+	}
+
+	if len(config) == 0 {
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("could not delete %s: %w", path, err)
+			}
+		}
+		return &Change{Path: path}, nil
+	}
+	if !dryRun {
+		if err := writeConfig(path, config, original); err != nil {
+			return nil, err
+		}
+	}
+	return &Change{Path: path, Key: key}, nil
+}
+
+// removeInstructions removes the generated instructions from the agent
+// instruction file at path, deleting the file if nothing else is left in it.
+// It returns the change, or nil if the file has no generated instructions,
+// and only returns it if dryRun.
+func removeInstructions(path string, dryRun bool) (*Change, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	rest, start := removeBlock(data)
+	if start < 0 {
+		return nil, nil
+	}
+	// Drop the blank line WriteAgentFile put before the instructions.
+	if start >= 2 && rest[start-1] == '\n' && rest[start-2] == '\n' {
+		rest = append(rest[:start-1], rest[start:]...)
+	}
+	if len(bytes.TrimSpace(rest)) == 0 {
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("could not delete %s: %w", path, err)
+			}
+		}
+		return &Change{Path: path}, nil
+	}
+	if !dryRun {
+		if err := os.WriteFile(path, append(bytes.TrimRight(rest, "\n"), '\n'), 0644); err != nil {
+			return nil, fmt.Errorf("could not write %s: %w", path, err)
+		}
+	}
+	return &Change{Path: path, Instructions: true}, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUninstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	baseDir := t.TempDir()
+
+	claudeDesktop, err := ClaudeDesktopConfigPath()
+	if err != nil {
+		t.Fatalf("ClaudeDesktopConfigPath() failed: %v", err)
+	}
+	if err := ClaudeDesktop(claudeDesktop, "gke-mcp", nil); err != nil {
+		t.Fatalf("ClaudeDesktop() failed: %v", err)
+	}
+	cursor, err := CursorConfigPath(ScopeProject, baseDir)
+	if err != nil {
+		t.Fatalf("CursorConfigPath() failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cursor), 0700); err != nil {
+		t.Fatalf("os.MkdirAll() failed: %v", err)
+	}
+	if err := os.WriteFile(cursor, []byte(`{"mcpServers": {"other": {"command": "other-mcp"}}}`), 0600); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	rulesDir := filepath.Join(baseDir, ".cursor", "rules")
+	if err := Cursor(cursor, rulesDir, "gke-mcp", nil); err != nil {
+		t.Fatalf("Cursor() failed: %v", err)
+	}
+	if err := GeminiCLIExtension(baseDir, "0.1.0-test", "gke-mcp", false); err != nil {
+		t.Fatalf("GeminiCLIExtension() failed: %v", err)
+	}
+
+	want := []Change{
+		{Path: claudeDesktop},
+		{Path: cursor, Key: "mcpServers"},
+		{Path: filepath.Join(baseDir, ".gemini", "extensions", "gke-mcp")},
+		{Path: filepath.Join(rulesDir, "gke-mcp.mdc")},
+	}
+
+	// A dry run only lists the changes.
+	changes, err := Uninstall(baseDir, true)
+	if err != nil {
+		t.Fatalf("Uninstall() dry run failed: %v", err)
+	}
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("Uninstall() dry run changes mismatch (-want +got):\n%s", diff)
+	}
+	for _, c := range want {
+		if _, err := os.Stat(c.Path); err != nil {
+			t.Errorf("Uninstall() dry run changed %s: %v", c.Path, err)
+		}
+	}
+
+	changes, err = Uninstall(baseDir, false)
+	if err != nil {
+		t.Fatalf("Uninstall() failed: %v", err)
+	}
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("Uninstall() changes mismatch (-want +got):\n%s", diff)
+	}
+	for _, c := range want {
+		if _, err := os.Stat(c.Path); c.Key == "" && !os.IsNotExist(err) {
+			t.Errorf("Stat(%s) after Uninstall() = %v, want it deleted", c.Path, err)
+		}
+	}
+	expected := map[string]any{
+		"mcpServers": map[string]any{
+			"other": map[string]any{"command": "other-mcp"},
+		},
+	}
+	if diff := cmp.Diff(expected, readJSON(t, cursor)); diff != "" {
+		t.Errorf("Config content after Uninstall() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Nothing is left to uninstall.
+	if changes, err := Uninstall(baseDir, false); err != nil || len(changes) != 0 {
+		t.Errorf("Uninstall() again = %v, %v; want no changes", changes, err)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Container is a container running in a pod.
+type Container struct {
+	// Workload is the workload running the pod, as namespace/Kind/name.
+	Workload   string
+	Image      string
+	Repository string
+	// Digest is the digest of the image the container runs, "" if the
+	// kubelet didn't report it yet.
+	Digest string
+}
+
+// pod is the part of a pod used to list its containers.
+type pod struct {
+	Metadata struct {
+		Name            string            `json:"name"`
+		Namespace       string            `json:"namespace"`
+		Labels          map[string]string `json:"labels"`
+		OwnerReferences []OwnerReference  `json:"ownerReferences"`
+	} `json:"metadata"`
+	Status struct {
+		InitContainerStatuses []struct {
+			Image   string `json:"image"`
+			ImageID string `json:"imageID"`
+		} `json:"initContainerStatuses"`
+		ContainerStatuses []struct {
+			Image   string `json:"image"`
+			ImageID string `json:"imageID"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// OwnerReference is the part of an owner reference used to find workloads.
+type OwnerReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// Workload returns the workload running a pod, as namespace/Kind/name,
+// given its metadata. Pods of a ReplicaSet are attributed to its Deployment.
+func Workload(namespace, name string, labels map[string]string, owners []OwnerReference) string {
+	kind := "Pod"
+	if len(owners) > 0 {
+		kind, name = owners[0].Kind, owners[0].Name
+		if hash := labels["pod-template-hash"]; kind == "ReplicaSet" && strings.HasSuffix(name, "-"+hash) {
+			kind, name = "Deployment", strings.TrimSuffix(name, "-"+hash)
+		}
+	}
+	return namespace + "/" + kind + "/" + name
+}
+
+// Containers lists the containers, including init containers, of the pods
+// in namespace, or all namespaces if empty.
+func (k *Client) Containers(ctx context.Context, namespace string) ([]Container, error) {
+	path := "/api/v1/pods"
+	if namespace != "" {
+		path = "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods"
+	}
+	var list struct {
+		Items []pod `json:"items"`
+	}
+	if err := k.Get(ctx, path, &list); err != nil {
+		return nil, err
+	}
+	var containers []Container
+	for _, p := range list.Items {
+		m := p.Metadata
+		workload := Workload(m.Namespace, m.Name, m.Labels, m.OwnerReferences)
+		for _, cs := range slices.Concat(p.Status.InitContainerStatuses, p.Status.ContainerStatuses) {
+			containers = append(containers, Container{
+				Workload:   workload,
+				Image:      cs.Image,
+				Repository: Repository(cs.Image),
+				Digest:     Digest(cs.ImageID),
+			})
+		}
+	}
+	return containers, nil
+}
+
+// Repository returns the repository of an image reference, without its tag
+// or digest.
+func Repository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// Digest returns the digest of an image ID reported by the kubelet, or of
+// an image reference, or "" if it has none.
+func Digest(imageID string) string {
+	if _, d, ok := strings.Cut(imageID, "@"); ok {
+		return d
+	}
+	if strings.HasPrefix(imageID, "sha256:") {
+		return imageID
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Resource is a Kubernetes API resource, as listed by API discovery.
+type Resource struct {
+	// GroupVersion is the apiVersion of the resource, e.g. apps/v1.
+	GroupVersion string
+	// Name is the plural name of the resource in paths, e.g. deployments.
+	Name       string
+	Kind       string
+	Namespaced bool
+}
+
+// Path returns the API path of the object name of the resource in namespace,
+// which is ignored for cluster scoped resources. An empty name gives the path
+// of the collection.
+func (r Resource) Path(namespace, name string) string {
+	path := "/apis/" + r.GroupVersion
+	if !strings.Contains(r.GroupVersion, "/") {
+		path = "/api/" + r.GroupVersion
+	}
+	if r.Namespaced && namespace != "" {
+		path += "/namespaces/" + url.PathEscape(namespace)
+	}
+	path += "/" + r.Name
+	if name != "" {
+		path += "/" + url.PathEscape(name)
+	}
+	return path
+}
+
+// Resource returns the resource of the objects of a kind in apiVersion,
+// discovering the resources of apiVersion on first use.
+func (k *Client) Resource(ctx context.Context, apiVersion, kind string) (Resource, error) {
+	k.discoveryMu.Lock()
+	defer k.discoveryMu.Unlock()
+
+	resources, ok := k.discovery[apiVersion]
+	if !ok {
+		path := "/apis/" + apiVersion
+		if !strings.Contains(apiVersion, "/") {
+			path = "/api/" + apiVersion
+		}
+		var list struct {
+			Resources []struct {
+				Name       string `json:"name"`
+				Kind       string `json:"kind"`
+				Namespaced bool   `json:"namespaced"`
+			} `json:"resources"`
+		}
+		if err := k.Get(ctx, path, &list); err != nil {
+			if IsNotFound(err) {
+				return Resource{}, fmt.Errorf("apiVersion %s is not served by the cluster", apiVersion)
+			}
+			return Resource{}, fmt.Errorf("failed to discover the resources of %s: %w", apiVersion, err)
+		}
+		for _, r := range list.Resources {
+			// Subresources, like deployments/scale, have the kind of
+			// their parent.
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			resources = append(resources, Resource{GroupVersion: apiVersion, Name: r.Name, Kind: r.Kind, Namespaced: r.Namespaced})
+		}
+		if k.discovery == nil {
+			k.discovery = map[string][]Resource{}
+		}
+		k.discovery[apiVersion] = resources
+	}
+	for _, r := range resources {
+		if r.Kind == kind {
+			return r, nil
+		}
+	}
+	return Resource{}, fmt.Errorf("kind %s is not served by the cluster in %s", kind, apiVersion)
+}
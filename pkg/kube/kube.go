@@ -0,0 +1,182 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kube calls the Kubernetes API of GKE clusters, authenticating with
+// the caller's Google credentials the way kubectl does with the
+// gke-gcloud-auth-plugin. The caller needs Kubernetes RBAC permissions on the
+// resources, granted to its Google identity or through IAM.
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/apierrors"
+	"golang.org/x/oauth2"
+)
+
+// Client calls the Kubernetes API of a cluster.
+type Client struct {
+	host   string
+	client *http.Client
+	ts     oauth2.TokenSource
+
+	discoveryMu sync.Mutex
+	// discovery holds the resources of every discovered apiVersion.
+	discovery map[string][]Resource
+}
+
+// StatusError is returned for Kubernetes API responses other than 2xx.
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Kubernetes API returned %d %s: %s", e.Code, http.StatusText(e.Code), e.Message)
+}
+
+// HTTPStatus returns the status code of the response.
+func (e *StatusError) HTTPStatus() int {
+	return e.Code
+}
+
+// IsNotFound reports whether err is a Kubernetes API 404 Not Found error.
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound
+}
+
+// New returns a client of the Kubernetes API at host, verified with the PEM
+// encoded CA certificates, or the system roots if nil, and authenticated with
+// the access tokens of ts.
+func New(host string, ca []byte, ts oauth2.TokenSource) (*Client, error) {
+	tlsConfig := &tls.Config{}
+	if ca != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid cluster CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return &Client{
+		host: host,
+		client: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           http.ProxyFromEnvironment,
+		}},
+		ts: ts,
+	}, nil
+}
+
+// NewWithTransport returns a client of the Kubernetes API at host sending
+// its requests with rt, e.g. to serve them from fixtures, and authenticated
+// with the access tokens of ts.
+func NewWithTransport(host string, rt http.RoundTripper, ts oauth2.TokenSource) *Client {
+	return &Client{host: host, client: &http.Client{Transport: rt}, ts: ts}
+}
+
+// Close closes the idle connections of the client.
+func (k *Client) Close() error {
+	k.client.CloseIdleConnections()
+	return nil
+}
+
+// Get gets the resource or list at path, e.g. /api/v1/namespaces, and
+// decodes it into out.
+func (k *Client) Get(ctx context.Context, path string, out any) error {
+	return k.Do(ctx, http.MethodGet, path, "", nil, out)
+}
+
+// Do sends a request with the body in, encoded as JSON unless it is a
+// []byte, to the Kubernetes API, and decodes the response into out, if not
+// nil. contentType defaults to application/json. GET requests failing with
+// a transient error are retried.
+func (k *Client) Do(ctx context.Context, method, path, contentType string, in, out any) error {
+	var data []byte
+	switch in := in.(type) {
+	case nil:
+	case []byte:
+		data = in
+	default:
+		var err error
+		if data, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+	if method != http.MethodGet {
+		return k.send(ctx, method, path, contentType, data, out)
+	}
+	return apierrors.Retry(ctx, func() error {
+		return k.send(ctx, method, path, contentType, data, out)
+	})
+}
+
+// send sends a single request to the Kubernetes API.
+func (k *Client) send(ctx context.Context, method, path, contentType string, data []byte, out any) error {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, k.host+path, body)
+	if err != nil {
+		return err
+	}
+	token, err := k.ts.Token()
+	if err != nil {
+		return fmt.Errorf("failed to get an access token: %w", err)
+	}
+	token.SetAuthHeader(req)
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return &StatusError{Code: resp.StatusCode, Message: statusMessage(resp.Body)}
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode the response of %s %s: %w", method, path, err)
+		}
+	}
+	return nil
+}
+
+// statusMessage returns the message of a Kubernetes Status response body,
+// or the start of the body if it is something else.
+func statusMessage(r io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(r, 4096))
+	var status struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &status) == nil && status.Message != "" {
+		return status.Message
+	}
+	return string(bytes.TrimSpace(data))
+}
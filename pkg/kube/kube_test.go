@@ -0,0 +1,240 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	k, err := New(srv.URL, ca, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return k
+}
+
+func TestGet(t *testing.T) {
+	k := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q, want Bearer token", got)
+		}
+		if r.URL.Path != "/api/v1/namespaces" {
+			t.Errorf("path = %q, want /api/v1/namespaces", r.URL.Path)
+		}
+		io.WriteString(w, `{"items":[{"metadata":{"name":"default"}}]}`)
+	})
+
+	var got struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := k.Get(context.Background(), "/api/v1/namespaces", &got); err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if len(got.Items) != 1 || got.Items[0].Metadata.Name != "default" {
+		t.Errorf("Get() = %+v, want the default namespace", got)
+	}
+}
+
+func TestDoError(t *testing.T) {
+	k := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"kind":"Status","message":"secrets \"x\" not found"}`)
+	})
+
+	err := k.Get(context.Background(), "/api/v1/namespaces/default/secrets/x", nil)
+	if !IsNotFound(err) {
+		t.Fatalf("Get() = %v, want a not found error", err)
+	}
+	if diff := cmp.Diff(`Kubernetes API returned 404 Not Found: secrets "x" not found`, err.Error()); diff != "" {
+		t.Errorf("Get() error mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestResource(t *testing.T) {
+	var calls int
+	k := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/apis/apps/v1":
+			io.WriteString(w, `{"resources":[{"name":"deployments","kind":"Deployment","namespaced":true},{"name":"deployments/scale","kind":"Scale","namespaced":true}]}`)
+		case "/api/v1":
+			io.WriteString(w, `{"resources":[{"name":"namespaces","kind":"Namespace","namespaced":false}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		apiVersion, kind string
+		wantPath         string
+	}{
+		{"apps/v1", "Deployment", "/apis/apps/v1/namespaces/default/deployments/web"},
+		{"v1", "Namespace", "/api/v1/namespaces/web"},
+	} {
+		r, err := k.Resource(ctx, tc.apiVersion, tc.kind)
+		if err != nil {
+			t.Fatalf("Resource(%s, %s) failed: %v", tc.apiVersion, tc.kind, err)
+		}
+		if got := r.Path("default", "web"); got != tc.wantPath {
+			t.Errorf("Resource(%s, %s).Path() = %q, want %q", tc.apiVersion, tc.kind, got, tc.wantPath)
+		}
+	}
+	if _, err := k.Resource(ctx, "apps/v1", "Scale"); err == nil {
+		t.Errorf("Resource(apps/v1, Scale) succeeded, want an error for a subresource")
+	}
+	if _, err := k.Resource(ctx, "example.com/v1", "Widget"); err == nil {
+		t.Errorf("Resource(example.com/v1, Widget) succeeded, want an error")
+	}
+	if calls != 3 {
+		t.Errorf("discovery calls = %d, want 3", calls)
+	}
+}
+
+func TestContainers(t *testing.T) {
+	k := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/prod/pods" {
+			t.Errorf("path = %q, want /api/v1/namespaces/prod/pods", r.URL.Path)
+		}
+		io.WriteString(w, `{"items":[
+  {
+    "metadata": {"name": "web-7d9f8-abcde", "namespace": "prod", "labels": {"pod-template-hash": "7d9f8"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "web-7d9f8"}]},
+    "status": {"containerStatuses": [{"image": "us-docker.pkg.dev/p/r/web:v1", "imageID": "us-docker.pkg.dev/p/r/web@sha256:old"}]}
+  },
+  {
+    "metadata": {"name": "worker-0", "namespace": "prod", "ownerReferences": [{"kind": "StatefulSet", "name": "worker"}]},
+    "status": {
+      "initContainerStatuses": [{"image": "busybox", "imageID": "docker.io/library/busybox@sha256:bb"}],
+      "containerStatuses": [{"image": "us-docker.pkg.dev/p/r/worker:v3", "imageID": ""}]
+    }
+  },
+  {"metadata": {"name": "debug", "namespace": "prod"}, "status": {}}
+]}`)
+	})
+
+	got, err := k.Containers(context.Background(), "prod")
+	if err != nil {
+		t.Fatalf("Containers() failed: %v", err)
+	}
+	want := []Container{
+		{Workload: "prod/Deployment/web", Image: "us-docker.pkg.dev/p/r/web:v1", Repository: "us-docker.pkg.dev/p/r/web", Digest: "sha256:old"},
+		{Workload: "prod/StatefulSet/worker", Image: "busybox", Repository: "busybox", Digest: "sha256:bb"},
+		{Workload: "prod/StatefulSet/worker", Image: "us-docker.pkg.dev/p/r/worker:v3", Repository: "us-docker.pkg.dev/p/r/worker"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Containers() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRepository(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "nginx", want: "nginx"},
+		{image: "us-docker.pkg.dev/p/r/app:v1", want: "us-docker.pkg.dev/p/r/app"},
+		{image: "us-docker.pkg.dev/p/r/app@sha256:abc", want: "us-docker.pkg.dev/p/r/app"},
+		{image: "localhost:5000/app", want: "localhost:5000/app"},
+		{image: "localhost:5000/app:v2", want: "localhost:5000/app"},
+	}
+	for _, tc := range tests {
+		if got := Repository(tc.image); got != tc.want {
+			t.Errorf("Repository(%q) = %q, want %q", tc.image, got, tc.want)
+		}
+	}
+}
+
+func TestDigest(t *testing.T) {
+	tests := []struct {
+		imageID string
+		want    string
+	}{
+		{imageID: "us-docker.pkg.dev/p/r/app@sha256:abc", want: "sha256:abc"},
+		{imageID: "docker-pullable://gcr.io/p/app@sha256:def", want: "sha256:def"},
+		{imageID: "sha256:123", want: "sha256:123"},
+		{imageID: "", want: ""},
+	}
+	for _, tc := range tests {
+		if got := Digest(tc.imageID); got != tc.want {
+			t.Errorf("Digest(%q) = %q, want %q", tc.imageID, got, tc.want)
+		}
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		quantity string
+		want     float64
+		wantErr  bool
+	}{
+		{quantity: "2", want: 2},
+		{quantity: "250m", want: 0.25},
+		{quantity: "1.5Gi", want: 1.5 * (1 << 30)},
+		{quantity: "512Mi", want: 512 << 20},
+		{quantity: "1M", want: 1e6},
+		{quantity: "1e3", want: 1000},
+		{quantity: "Gi", wantErr: true},
+		{quantity: "lots", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := ParseQuantity(tc.quantity)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseQuantity(%q) error = %v, wantErr %v", tc.quantity, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseQuantity(%q) = %v, want %v", tc.quantity, got, tc.want)
+		}
+	}
+}
+
+func TestDoRetriesGets(t *testing.T) {
+	calls := map[string]int{}
+	k := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls[r.Method]++
+		if calls[r.Method] == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{}`)
+	})
+
+	if err := k.Get(context.Background(), "/api/v1/namespaces", nil); err != nil {
+		t.Errorf("Get() failed: %v", err)
+	}
+	if err := k.Do(context.Background(), http.MethodPost, "/api/v1/namespaces", "", map[string]string{}, nil); err == nil {
+		t.Error("Do(POST) succeeded, want the first error, since writes aren't retried")
+	}
+	if want := map[string]int{http.MethodGet: 2, http.MethodPost: 1}; !cmp.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMatches(t *testing.T) {
+	var s LabelSelector
+	if err := json.Unmarshal([]byte(`{"matchLabels":{"team":"web"},"matchExpressions":[{"key":"kubernetes.io/metadata.name","operator":"NotIn","values":["kube-system"]},{"key":"legacy","operator":"DoesNotExist"}]}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		labels map[string]string
+		want   bool
+	}{
+		{map[string]string{"team": "web", "kubernetes.io/metadata.name": "web"}, true},
+		{map[string]string{"team": "web", "kubernetes.io/metadata.name": "kube-system"}, false},
+		{map[string]string{"team": "web", "legacy": "true"}, false},
+		{map[string]string{"kubernetes.io/metadata.name": "web"}, false},
+	}
+	for _, tc := range tests {
+		if got := s.Matches(tc.labels); got != tc.want {
+			t.Errorf("Matches(%v) = %v, want %v", tc.labels, got, tc.want)
+		}
+	}
+	if !(*LabelSelector)(nil).Matches(nil) {
+		t.Error("nil selector doesn't match everything")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logger configures the server's structured, leveled logging.
+//
+// Logs are always written to stderr: in stdio mode stdout carries the MCP
+// stream, and any stray output there would corrupt it.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const (
+	// LevelEnv and FormatEnv provide defaults for the --log-level and
+	// --log-format flags.
+	LevelEnv  = "GKE_MCP_LOG_LEVEL"
+	FormatEnv = "GKE_MCP_LOG_FORMAT"
+)
+
+// Setup installs the default slog logger, writing to stderr. level is one of
+// debug, info, warn or error and format is text or json; empty values fall
+// back to the environment and then to info and text. Output of the standard
+// log package is routed through the same logger.
+func Setup(level, format string) error {
+	return setup(os.Stderr, level, format)
+}
+
+func setup(w io.Writer, level, format string) error {
+	if level == "" {
+		level = os.Getenv(LevelEnv)
+	}
+	if format == "" {
+		format = os.Getenv(FormatEnv)
+	}
+
+	var l slog.Level
+	if level != "" {
+		if err := l.UnmarshalText([]byte(level)); err != nil {
+			return fmt.Errorf("invalid log level %q: must be one of debug, info, warn or error", level)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: l}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		h = slog.NewTextHandler(w, opts)
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid log format %q: must be text or json", format)
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
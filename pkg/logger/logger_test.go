@@ -0,0 +1,54 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	var buf bytes.Buffer
+	if err := setup(&buf, "info", "json"); err != nil {
+		t.Fatalf("setup() failed: %v", err)
+	}
+	slog.Debug("dropped")
+	log.Printf("from the log package")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log output %q is not a single JSON entry: %v", buf.String(), err)
+	}
+	if got, want := entry["msg"], "from the log package"; got != want {
+		t.Errorf("msg = %q, want %q", got, want)
+	}
+}
+
+func TestSetupInvalid(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	var buf bytes.Buffer
+	if err := setup(&buf, "verbose", ""); err == nil {
+		t.Errorf("setup() with an invalid level succeeded, want error")
+	}
+	if err := setup(&buf, "", "xml"); err == nil {
+		t.Errorf("setup() with an invalid format succeeded, want error")
+	}
+}
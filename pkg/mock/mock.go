@@ -0,0 +1,450 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mock serves the calls of the GCP APIs, and of the Kubernetes API
+// of GKE clusters, from recorded fixtures instead of the network, so the
+// server can be demonstrated, and tested end to end, without a project or
+// credentials.
+//
+// Fixtures are JSON files holding a list of calls and their responses. gRPC
+// calls are named by their full method, e.g.
+// /google.container.v1.ClusterManager/ListClusters, and their requests and
+// responses are in the JSON form of their protocol buffers. HTTP calls, of
+// the REST APIs and of Kubernetes, are named by their method and matched on
+// the path of the request, e.g. GET /api/v1/nodes. The first fixture that
+// matches a call serves it, so the fixtures of a directory take precedence
+// over the bundled ones, of the demo project.
+package mock
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	// The APIs of the bundled fixtures, so they can be decoded.
+	_ "cloud.google.com/go/container/apiv1/containerpb"
+	_ "cloud.google.com/go/logging/apiv2/loggingpb"
+	_ "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// The demo project, location and cluster of the bundled fixtures.
+const (
+	Project  = "gke-mcp-demo"
+	Location = "us-central1"
+	Cluster  = "demo-cluster"
+)
+
+//go:embed fixtures/*.json
+var bundled embed.FS
+
+// Fixture is a recorded call and its response.
+type Fixture struct {
+	// Method is the full method of a gRPC call, or the method of an HTTP
+	// request.
+	Method string `json:"method"`
+	// Path is the path of HTTP requests, as a path.Match pattern, e.g.
+	// /api/v1/namespaces/*/pods.
+	Path string `json:"path,omitempty"`
+	// Query is the query parameters HTTP requests must have, their values
+	// being path.Match patterns, e.g. {"fieldSelector": "status.phase=Pending"}.
+	Query map[string]string `json:"query,omitempty"`
+	// Request is a subset of the request, in its JSON form, that calls must
+	// match, e.g. {"parent": "projects/p/locations/*"}, its strings being
+	// path.Match patterns. It matches every call if empty.
+	Request json.RawMessage `json:"request,omitempty"`
+	// Response is the response, or the body of the HTTP response.
+	Response json.RawMessage `json:"response,omitempty"`
+	// Stream is the responses of a streaming gRPC call, sent in order.
+	Stream []json.RawMessage `json:"stream,omitempty"`
+	// Status is the status code of the HTTP response, 200 by default.
+	Status int `json:"status,omitempty"`
+	// Error fails a gRPC call with a status, e.g.
+	// {"code": "NOT_FOUND", "message": "cluster not found"}.
+	Error *Error `json:"error,omitempty"`
+}
+
+// Error is the status of a failed gRPC call.
+type Error struct {
+	Code    codes.Code `json:"code"`
+	Message string     `json:"message"`
+}
+
+// fixture is a Fixture with its request decoded, and, for gRPC calls, its
+// responses too.
+type fixture struct {
+	Fixture
+	file      string
+	request   any
+	responses []proto.Message
+}
+
+// Server serves the calls matching its fixtures. It is both a gRPC server,
+// reached with the connections of Dial, and an http.RoundTripper.
+type Server struct {
+	fixtures []*fixture
+	listener *bufconn.Listener
+	server   *grpc.Server
+}
+
+// New returns a server of the fixtures of dir, if not "", and of the bundled
+// ones. It must be closed.
+func New(dir string) (*Server, error) {
+	s := &Server{listener: bufconn.Listen(1 << 20)}
+	if dir != "" {
+		if err := s.load(os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.load(bundled, "fixtures"); err != nil {
+		return nil, err
+	}
+	s.server = grpc.NewServer(grpc.UnknownServiceHandler(s.handle))
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil {
+			slog.Debug("Mock server stopped", "err", err)
+		}
+	}()
+	return s, nil
+}
+
+// load loads the fixtures of the JSON files of dir.
+func (s *Server) load(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		var fixtures []Fixture
+		if err := json.Unmarshal(data, &fixtures); err != nil {
+			return fmt.Errorf("invalid fixtures %s: %w", file, err)
+		}
+		for i, f := range fixtures {
+			parsed, err := parse(f)
+			if err != nil {
+				return fmt.Errorf("invalid fixture %d of %s: %w", i, file, err)
+			}
+			parsed.file = filepath.Base(file)
+			s.fixtures = append(s.fixtures, parsed)
+		}
+	}
+	return nil
+}
+
+// parse decodes the request and responses of f.
+func parse(f Fixture) (*fixture, error) {
+	parsed := &fixture{Fixture: f}
+	if f.Method == "" {
+		return nil, errors.New("method not set")
+	}
+	if !isGRPC(f.Method) {
+		if f.Path == "" {
+			return nil, errors.New("path not set")
+		}
+		if _, err := path.Match(f.Path, ""); err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", f.Path, err)
+		}
+		if len(f.Request) > 0 {
+			if err := json.Unmarshal(f.Request, &parsed.request); err != nil {
+				return nil, fmt.Errorf("invalid request: %w", err)
+			}
+		}
+		return parsed, nil
+	}
+
+	md, err := method(f.Method)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Request) > 0 {
+		// The request is decoded into its message, and encoded back, so
+		// it is compared in the same form as the calls.
+		in, err := decode(md.Input(), f.Request)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+		if parsed.request, err = generic(in); err != nil {
+			return nil, err
+		}
+	}
+	responses := f.Stream
+	if len(f.Response) > 0 {
+		responses = append([]json.RawMessage{f.Response}, responses...)
+	}
+	for _, r := range responses {
+		out, err := decode(md.Output(), r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid response: %w", err)
+		}
+		parsed.responses = append(parsed.responses, out)
+	}
+	if len(parsed.responses) == 0 && f.Error == nil {
+		return nil, errors.New("neither response nor error set")
+	}
+	return parsed, nil
+}
+
+// isGRPC reports whether method is the full method of a gRPC call.
+func isGRPC(method string) bool {
+	return strings.HasPrefix(method, "/")
+}
+
+// method returns the descriptor of a full gRPC method, of the APIs linked
+// into the binary.
+func method(fullMethod string) (protoreflect.MethodDescriptor, error) {
+	service, name, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid gRPC method %q", fullMethod)
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("unknown gRPC service %q", service)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a gRPC service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(name))
+	if md == nil {
+		return nil, fmt.Errorf("unknown gRPC method %q", fullMethod)
+	}
+	return md, nil
+}
+
+// newMessage returns an empty message of the type of d.
+func newMessage(d protoreflect.MessageDescriptor) (proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(d.FullName())
+	if err != nil {
+		return nil, err
+	}
+	return mt.New().Interface(), nil
+}
+
+// decode decodes the JSON form of a message of the type of d.
+func decode(d protoreflect.MessageDescriptor, data []byte) (proto.Message, error) {
+	m, err := newMessage(d)
+	if err != nil {
+		return nil, err
+	}
+	return m, protojson.Unmarshal(data, m)
+}
+
+// generic returns the JSON form of m as maps, slices and values.
+func generic(m proto.Message) (any, error) {
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	return v, json.Unmarshal(data, &v)
+}
+
+// contains reports whether want is a subset of got: objects must have the
+// fields of want, with matching values, lists the same length, and strings
+// match want as a path.Match pattern.
+func contains(got, want any) bool {
+	switch want := want.(type) {
+	case map[string]any:
+		got, ok := got.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range want {
+			if !contains(got[k], v) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		got, ok := got.([]any)
+		if !ok || len(got) != len(want) {
+			return false
+		}
+		for i := range want {
+			if !contains(got[i], want[i]) {
+				return false
+			}
+		}
+		return true
+	case string:
+		got, ok := got.(string)
+		if !ok {
+			return false
+		}
+		if matched, err := path.Match(want, got); err == nil && matched {
+			return true
+		}
+		return got == want
+	default:
+		return reflect.DeepEqual(got, want)
+	}
+}
+
+// match returns the first fixture of the call, or nil if there is none.
+// The path and query are those of HTTP requests.
+func (s *Server) match(method, path string, query url.Values, request any) *fixture {
+	i := slices.IndexFunc(s.fixtures, func(f *fixture) bool {
+		if f.Method != method {
+			return false
+		}
+		if f.Path != "" {
+			if ok, _ := pathMatch(f.Path, path); !ok {
+				return false
+			}
+		}
+		for k, v := range f.Query {
+			if !contains(query.Get(k), v) {
+				return false
+			}
+		}
+		return f.request == nil || contains(request, f.request)
+	})
+	if i < 0 {
+		slog.Warn("No mock fixture for the call", "method", method, "path", path)
+		return nil
+	}
+	return s.fixtures[i]
+}
+
+// pathMatch reports whether path matches pattern, ignoring a trailing slash.
+func pathMatch(pattern, p string) (bool, error) {
+	return path.Match(strings.TrimSuffix(pattern, "/"), strings.TrimSuffix(p, "/"))
+}
+
+// Dial returns a connection to the gRPC server.
+func (s *Server) Dial(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append(opts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return s.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	return grpc.NewClient("passthrough:///mock", opts...)
+}
+
+// handle serves every gRPC call. Streaming calls are served from their
+// first request.
+func (s *Server) handle(_ any, stream grpc.ServerStream) error {
+	fullMethod, _ := grpc.MethodFromServerStream(stream)
+	md, err := method(fullMethod)
+	if err != nil {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	in, err := newMessage(md.Input())
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	request, err := generic(in)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	f := s.match(fullMethod, "", nil, request)
+	if f == nil {
+		return status.Errorf(codes.Unimplemented, "no mock fixture for %s", fullMethod)
+	}
+	for _, r := range f.responses {
+		if err := stream.SendMsg(r); err != nil {
+			return err
+		}
+		if !md.IsStreamingServer() {
+			break
+		}
+	}
+	if f.Error != nil {
+		return status.Error(f.Error.Code, f.Error.Message)
+	}
+	return nil
+}
+
+// RoundTrip serves an HTTP request.
+func (s *Server) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+	var request any
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		// Bodies that aren't JSON, like patches in YAML, only match
+		// fixtures without a request.
+		_ = json.Unmarshal(data, &request)
+	}
+	f := s.match(req.Method, req.URL.Path, req.URL.Query(), request)
+	code, body := http.StatusOK, []byte("{}")
+	switch {
+	case f == nil:
+		// The error is understood by both the Google REST clients and
+		// kube.Client.
+		code = http.StatusNotFound
+		message := fmt.Sprintf("no mock fixture for %s %s", req.Method, req.URL.Path)
+		body, _ = json.Marshal(map[string]any{
+			"message": message,
+			"error":   map[string]any{"code": code, "message": message, "status": "NOT_FOUND"},
+		})
+	default:
+		if f.Status != 0 {
+			code = f.Status
+		}
+		if len(f.Response) > 0 {
+			body = f.Response
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(string(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Close stops the gRPC server.
+func (s *Server) Close() {
+	s.server.Stop()
+}
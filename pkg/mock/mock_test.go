@@ -0,0 +1,191 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	container "cloud.google.com/go/container/apiv1"
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newServer(t *testing.T, dir string) *Server {
+	t.Helper()
+	s, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+func clusterManager(t *testing.T, s *Server) *container.ClusterManagerClient {
+	t.Helper()
+	conn, err := s.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := container.NewClusterManagerClient(context.Background(), option.WithGRPCConn(conn), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestGRPC(t *testing.T) {
+	client := clusterManager(t, newServer(t, ""))
+	ctx := context.Background()
+
+	resp, err := client.ListClusters(ctx, &containerpb.ListClustersRequest{Parent: "projects/" + Project + "/locations/-"})
+	if err != nil {
+		t.Fatalf("ListClusters() failed: %v", err)
+	}
+	if len(resp.GetClusters()) != 1 || resp.GetClusters()[0].GetName() != Cluster {
+		t.Errorf("ListClusters() of the demo project = %v, want the demo cluster", resp.GetClusters())
+	}
+	resp, err = client.ListClusters(ctx, &containerpb.ListClustersRequest{Parent: "projects/other/locations/-"})
+	if err != nil || len(resp.GetClusters()) != 0 {
+		t.Errorf("ListClusters() of another project = %v, %v, want no clusters", resp.GetClusters(), err)
+	}
+
+	_, err = client.GetCluster(ctx, &containerpb.GetClusterRequest{Name: "projects/other/locations/us-east1/clusters/c"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetCluster() of an unknown cluster failed with %v, want NotFound", err)
+	}
+}
+
+func TestFixturesDir(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := []Fixture{{
+		Method:   "/google.container.v1.ClusterManager/ListClusters",
+		Request:  json.RawMessage(`{"parent": "projects/mine/locations/*"}`),
+		Response: json.RawMessage(`{"clusters": [{"name": "mine", "status": "RUNNING"}]}`),
+	}}
+	data, err := json.Marshal(fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mine.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client := clusterManager(t, newServer(t, dir))
+
+	resp, err := client.ListClusters(context.Background(), &containerpb.ListClustersRequest{Parent: "projects/mine/locations/us-central1"})
+	if err != nil {
+		t.Fatalf("ListClusters() failed: %v", err)
+	}
+	if len(resp.GetClusters()) != 1 || resp.GetClusters()[0].GetName() != "mine" {
+		t.Errorf("ListClusters() = %v, want the cluster of the fixtures directory", resp.GetClusters())
+	}
+}
+
+func TestInvalidFixtures(t *testing.T) {
+	for name, fixture := range map[string]string{
+		"unknown method":   `[{"method": "/google.container.v1.ClusterManager/Nope", "response": {}}]`,
+		"invalid response": `[{"method": "/google.container.v1.ClusterManager/GetCluster", "response": {"nope": 1}}]`,
+		"no response":      `[{"method": "/google.container.v1.ClusterManager/GetCluster"}]`,
+		"no path":          `[{"method": "GET", "response": {}}]`,
+		"not a list":       `{"method": "GET", "path": "/version"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "f.json"), []byte(fixture), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := New(dir); err == nil {
+				t.Error("New() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	client := &http.Client{Transport: newServer(t, "")}
+
+	resp, err := client.Get("https://34.68.10.20/api/v1/nodes?limit=500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var nodes struct {
+		Items []any `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&nodes); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || len(nodes.Items) != 6 {
+		t.Errorf("GET /api/v1/nodes = %s with %d nodes, want 200 OK with 6", resp.Status, len(nodes.Items))
+	}
+
+	resp, err = client.Get("https://34.68.10.20/api/v1/pods?fieldSelector=status.phase%3DPending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var pods struct {
+		Items []any `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pods); err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 1 {
+		t.Errorf("GET of the pending pods = %d pods, want the 1 matching the query", len(pods.Items))
+	}
+
+	resp, err = client.Get("https://34.68.10.20/api/v1/namespaces/default/secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET of a path without fixture = %s, want 404 Not Found", resp.Status)
+	}
+}
+
+func TestContains(t *testing.T) {
+	for _, tc := range []struct {
+		got, want string
+		contains  bool
+	}{
+		{`{"a": 1, "b": "x"}`, `{"a": 1}`, true},
+		{`{"a": 1}`, `{"a": 2}`, false},
+		{`{"a": 1}`, `{"b": 1}`, false},
+		{`{"name": "projects/p/locations/us-central1"}`, `{"name": "projects/p/locations/*"}`, true},
+		{`{"name": "projects/p/locations/us-central1/clusters/c"}`, `{"name": "projects/p/locations/*"}`, false},
+		{`{"ids": ["a", "b"]}`, `{"ids": ["a", "b"]}`, true},
+		{`{"ids": ["a", "b"]}`, `{"ids": ["a"]}`, false},
+		{`{"a": {"b": [{"c": 1, "d": 2}]}}`, `{"a": {"b": [{"c": 1}]}}`, true},
+	} {
+		var got, want any
+		if err := json.Unmarshal([]byte(tc.got), &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+			t.Fatal(err)
+		}
+		if contains(got, want) != tc.contains {
+			t.Errorf("contains(%s, %s) = %v, want %v", tc.got, tc.want, !tc.contains, tc.contains)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operations keeps track of in-flight tool calls and of the GKE
+// long-running operations they start, so the server can shut down
+// gracefully and users can resume tracking operations after a restart.
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Operation is a long-running GCP operation started by a tool.
+type Operation struct {
+	// Name is the full resource name of the operation, e.g.
+	// "projects/p/locations/us-central1/operations/operation-123".
+	Name string `json:"name"`
+	// Tool is the tool that started the operation.
+	Tool string `json:"tool"`
+	// Target is the resource the operation acts on, e.g. a cluster name.
+	Target    string    `json:"target,omitempty"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// Tracker records in-flight tool calls and pending operations.
+type Tracker struct {
+	mu       sync.Mutex
+	ops      map[string]Operation
+	inflight int
+	draining bool
+}
+
+// Default is the tracker used by the server and its tools.
+var Default = NewTracker()
+
+func NewTracker() *Tracker {
+	return &Tracker{ops: map[string]Operation{}}
+}
+
+// Track records op as pending. Call the returned function once the operation
+// is done, or has been handed over to the user to follow.
+func (t *Tracker) Track(op Operation) (done func()) {
+	if op.StartTime.IsZero() {
+		op.StartTime = time.Now()
+	}
+	t.mu.Lock()
+	t.ops[op.Name] = op
+	t.mu.Unlock()
+	return func() { t.Forget(op.Name) }
+}
+
+// Forget stops tracking the operation with the given name.
+func (t *Tracker) Forget(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.ops, name)
+}
+
+// Pending returns the operations that haven't completed, oldest first.
+func (t *Tracker) Pending() []Operation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ops := make([]Operation, 0, len(t.ops))
+	for _, op := range t.ops {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].StartTime.Before(ops[j].StartTime)
+	})
+	return ops
+}
+
+// Draining reports whether the server is shutting down. Tools that wait for
+// long-running operations should stop waiting and return the operation name
+// once this is true.
+func (t *Tracker) Draining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// Middleware counts in-flight tool calls and rejects new ones once the
+// server is shutting down.
+func (t *Tracker) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		t.mu.Lock()
+		if t.draining {
+			t.mu.Unlock()
+			return mcp.NewToolResultError("The GKE MCP server is shutting down and is not accepting new tool calls. Retry once it has restarted."), nil
+		}
+		t.inflight++
+		t.mu.Unlock()
+
+		defer func() {
+			t.mu.Lock()
+			t.inflight--
+			t.mu.Unlock()
+		}()
+		return next(ctx, request)
+	}
+}
+
+// Drain stops accepting new tool calls and waits until the in-flight ones
+// finish or ctx is done.
+func (t *Tracker) Drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		t.mu.Lock()
+		inflight := t.inflight
+		t.mu.Unlock()
+		if inflight == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d tool calls still running: %w", inflight, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Snapshot returns the pending operations to persist across restarts, or nil
+// if there are none.
+func (t *Tracker) Snapshot() (json.RawMessage, error) {
+	ops := t.Pending()
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(ops)
+}
+
+// Restore replaces the pending operations with a snapshot.
+func (t *Tracker) Restore(data json.RawMessage) error {
+	var ops []Operation
+	if data != nil {
+		if err := json.Unmarshal(data, &ops); err != nil {
+			return err
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.ops)
+	for _, op := range ops {
+		t.ops[op.Name] = op
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestDrain(t *testing.T) {
+	tr := NewTracker()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := tr.Middleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+	go handler(context.Background(), mcp.CallToolRequest{})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := tr.Drain(ctx); err == nil {
+		t.Fatalf("Drain() returned before the in-flight call finished")
+	}
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !result.IsError {
+		t.Errorf("new tool call while draining = %v, %v; want an error result", result, err)
+	}
+
+	close(release)
+	if err := tr.Drain(context.Background()); err != nil {
+		t.Errorf("Drain() after the in-flight call finished failed: %v", err)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	start := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	tr := NewTracker()
+	tr.Track(Operation{Name: "projects/p/locations/l/operations/b", Tool: "upgrade_cluster", StartTime: start.Add(time.Minute)})
+	done := tr.Track(Operation{Name: "projects/p/locations/l/operations/done", Tool: "upgrade_cluster", StartTime: start})
+	tr.Track(Operation{Name: "projects/p/locations/l/operations/a", Tool: "create_node_pool", Target: "c", StartTime: start})
+	done()
+
+	data, err := tr.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() failed: %v", err)
+	}
+	restored := NewTracker()
+	if err := restored.Restore(data); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	want := []Operation{
+		{Name: "projects/p/locations/l/operations/a", Tool: "create_node_pool", Target: "c", StartTime: start},
+		{Name: "projects/p/locations/l/operations/b", Tool: "upgrade_cluster", StartTime: start.Add(time.Minute)},
+	}
+	if diff := cmp.Diff(want, restored.Pending()); diff != "" {
+		t.Errorf("Pending() after Restore() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Nothing pending has no snapshot, and restoring nil clears.
+	if data, err := NewTracker().Snapshot(); err != nil || data != nil {
+		t.Errorf("Snapshot() with nothing pending = %s, %v; want nil", data, err)
+	}
+	if err := restored.Restore(nil); err != nil || len(restored.Pending()) != 0 {
+		t.Errorf("Restore(nil) left %v, %v; want no operations", restored.Pending(), err)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// PollInterval is how often Wait polls the operations it waits for.
+var PollInterval = 10 * time.Second
+
+// PollFunc gets the latest state of the GKE operation with the given full
+// resource name.
+type PollFunc func(ctx context.Context, name string) (*containerpb.Operation, error)
+
+// CancelFunc asks GKE to cancel the operation with the given full resource
+// name. Not every operation can be cancelled.
+type CancelFunc func(ctx context.Context, name string) error
+
+// cancelTimeout bounds the call cancelling an operation, which is made once
+// the tool call is already cancelled.
+const cancelTimeout = 30 * time.Second
+
+// Progress sends MCP progress notifications for a tool call, if the client
+// asked for them.
+type Progress struct {
+	ctx   context.Context
+	token mcp.ProgressToken
+}
+
+// NewProgress returns the progress reporter of the tool call request.
+func NewProgress(ctx context.Context, request mcp.CallToolRequest) *Progress {
+	p := &Progress{ctx: ctx}
+	if request.Params.Meta != nil {
+		p.token = request.Params.Meta.ProgressToken
+	}
+	return p
+}
+
+// Report sends the progress of the call, in percent, with the current step.
+// A negative percentage means the progress is unknown.
+func (p *Progress) Report(percent float64, step string) {
+	s := server.ServerFromContext(p.ctx)
+	if p.token == nil || s == nil {
+		return
+	}
+	params := map[string]any{
+		"progressToken": p.token,
+		"message":       step,
+	}
+	if percent >= 0 {
+		params["progress"] = percent
+		params["total"] = 100
+	} else {
+		params["progress"] = 0
+	}
+	if err := s.SendNotificationToClient(p.ctx, "notifications/progress", params); err != nil {
+		slog.Debug("Failed to send a progress notification", "err", err)
+	}
+}
+
+// Wait polls the GKE operation op until it is done, reporting its progress
+// to the client of request, and tracks it meanwhile so it can be resumed
+// after a restart. It returns the latest state of the operation, which is
+// still running if ctx is done, along with the cause of ctx's error, or if
+// the server started shutting down.
+//
+// If ctx is cancelled, e.g. because the client cancelled the tool call, and
+// cancel isn't nil, Wait also cancels the operation. It stays tracked until
+// it's done either way.
+func (t *Tracker) Wait(ctx context.Context, request mcp.CallToolRequest, op Operation, poll PollFunc, cancel CancelFunc) (*containerpb.Operation, error) {
+	done := t.Track(op)
+	progress := NewProgress(ctx, request)
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		latest, err := poll(ctx, op.Name)
+		if err != nil {
+			return nil, err
+		}
+		if latest.GetStatus() == containerpb.Operation_DONE {
+			done()
+			progress.Report(100, Step(latest))
+			return latest, nil
+		}
+		progress.Report(Percent(latest), Step(latest))
+		if t.Draining() {
+			return latest, nil
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) && cancel != nil {
+				cancelCtx, stop := context.WithTimeout(context.WithoutCancel(ctx), cancelTimeout)
+				defer stop()
+				if err := cancel(cancelCtx, op.Name); err != nil {
+					slog.Warn("Failed to cancel the operation of a cancelled tool call", "operation", op.Name, "err", err)
+				} else {
+					slog.Info("Cancelled the operation of a cancelled tool call", "operation", op.Name)
+				}
+			}
+			return latest, context.Cause(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Percent estimates how far along op is, in percent, from its progress
+// metrics or its stages, or returns -1 if it can't tell.
+func Percent(op *containerpb.Operation) float64 {
+	if op.GetStatus() == containerpb.Operation_DONE {
+		return 100
+	}
+	p := op.GetProgress()
+	metrics := map[string]float64{}
+	for _, m := range p.GetMetrics() {
+		switch v := m.GetValue().(type) {
+		case *containerpb.OperationProgress_Metric_IntValue:
+			metrics[strings.ToLower(m.GetName())] = float64(v.IntValue)
+		case *containerpb.OperationProgress_Metric_DoubleValue:
+			metrics[strings.ToLower(m.GetName())] = v.DoubleValue
+		}
+	}
+	if v, ok := metrics["percent done"]; ok {
+		return v
+	}
+	if scale := metrics["progress scale"]; scale > 0 {
+		return 100 * metrics["progress"] / scale
+	}
+	for name, total := range metrics {
+		if what, ok := strings.CutSuffix(name, " total"); ok && total > 0 {
+			if done, ok := metrics[what+" done"]; ok {
+				return 100 * done / total
+			}
+		}
+	}
+	if stages := p.GetStages(); len(stages) > 0 {
+		var done int
+		for _, stage := range stages {
+			if stage.GetStatus() == containerpb.Operation_DONE {
+				done++
+			}
+		}
+		return 100 * float64(done) / float64(len(stages))
+	}
+	return -1
+}
+
+// Step describes the current step of op.
+func Step(op *containerpb.Operation) string {
+	step := op.GetOperationType().String()
+	for _, stage := range op.GetProgress().GetStages() {
+		if stage.GetStatus() == containerpb.Operation_RUNNING && stage.GetName() != "" {
+			step += ": " + stage.GetName()
+			break
+		}
+	}
+	if detail := op.GetDetail(); detail != "" {
+		step += " (" + detail + ")"
+	}
+	return fmt.Sprintf("%s, %s", step, op.GetStatus())
+}
+
+// Describe summarizes the outcome of waiting for op, with its ID so the
+// user can follow it up with get_operation.
+func Describe(op *containerpb.Operation) string {
+	switch {
+	case op.GetStatus() != containerpb.Operation_DONE:
+		return fmt.Sprintf("Operation %s is still running (%s). Follow it up with get_operation.", op.GetName(), Step(op))
+	case op.GetError() != nil:
+		return fmt.Sprintf("Operation %s failed: %s", op.GetName(), op.GetError().GetMessage())
+	case op.GetStatusMessage() != "":
+		return fmt.Sprintf("Operation %s failed: %s", op.GetName(), op.GetStatusMessage())
+	default:
+		return fmt.Sprintf("Operation %s is done.", op.GetName())
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func metric(name string, v int64) *containerpb.OperationProgress_Metric {
+	return &containerpb.OperationProgress_Metric{Name: name, Value: &containerpb.OperationProgress_Metric_IntValue{IntValue: v}}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		name string
+		op   *containerpb.Operation
+		want float64
+	}{
+		{name: "done", op: &containerpb.Operation{Status: containerpb.Operation_DONE}, want: 100},
+		{name: "unknown", op: &containerpb.Operation{Status: containerpb.Operation_RUNNING}, want: -1},
+		{
+			name: "nodes",
+			op: &containerpb.Operation{Status: containerpb.Operation_RUNNING, Progress: &containerpb.OperationProgress{
+				Metrics: []*containerpb.OperationProgress_Metric{metric("nodes total", 8), metric("nodes done", 2)},
+			}},
+			want: 25,
+		},
+		{
+			name: "stages",
+			op: &containerpb.Operation{Status: containerpb.Operation_RUNNING, Progress: &containerpb.OperationProgress{
+				Stages: []*containerpb.OperationProgress{
+					{Name: "drain", Status: containerpb.Operation_DONE},
+					{Name: "upgrade", Status: containerpb.Operation_RUNNING},
+				},
+			}},
+			want: 50,
+		},
+	}
+	for _, tc := range tests {
+		if got := Percent(tc.op); got != tc.want {
+			t.Errorf("Percent(%s) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWait(t *testing.T) {
+	saved := PollInterval
+	t.Cleanup(func() { PollInterval = saved })
+	PollInterval = time.Millisecond
+
+	tr := NewTracker()
+	name := "projects/p/locations/l/operations/operation-1"
+	polls := 0
+	poll := func(context.Context, string) (*containerpb.Operation, error) {
+		polls++
+		if polls < 3 {
+			if len(tr.Pending()) != 1 {
+				t.Errorf("Pending() while waiting = %v, want the operation", tr.Pending())
+			}
+			return &containerpb.Operation{Name: "operation-1", Status: containerpb.Operation_RUNNING}, nil
+		}
+		return &containerpb.Operation{Name: "operation-1", Status: containerpb.Operation_DONE}, nil
+	}
+	op, err := tr.Wait(context.Background(), mcp.CallToolRequest{}, Operation{Name: name, Tool: "t"}, poll, nil)
+	if err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+	if op.GetStatus() != containerpb.Operation_DONE || polls != 3 {
+		t.Errorf("Wait() = %v after %d polls, want the operation done after 3", op, polls)
+	}
+	if len(tr.Pending()) != 0 {
+		t.Errorf("Pending() after Wait() = %v, want nothing", tr.Pending())
+	}
+
+	// Operations still running when the context is done stay tracked.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	PollInterval = time.Hour
+	polls = 0
+	var cancelled string
+	cancelOp := func(_ context.Context, name string) error {
+		cancelled = name
+		return nil
+	}
+	op, err = tr.Wait(ctx, mcp.CallToolRequest{}, Operation{Name: name, Tool: "t"}, poll, cancelOp)
+	if !errors.Is(err, context.Canceled) || op.GetStatus() != containerpb.Operation_RUNNING {
+		t.Errorf("Wait() with a cancelled context = %v, %v, want the running operation and a cancellation", op, err)
+	}
+	if cancelled != name {
+		t.Errorf("Wait() with a cancelled context cancelled operation %q, want %q", cancelled, name)
+	}
+	if len(tr.Pending()) != 1 {
+		t.Errorf("Pending() after a cancelled Wait() = %v, want the operation", tr.Pending())
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package paging lets tools return large results one page at a time.
+//
+// A tool returns a page of items plus an opaque cursor; passing the cursor
+// back in the next call returns the following page. Cursors wrap either an
+// upstream API page token or an offset into a client-side list, together
+// with the exact query that produced them, so later pages stay consistent
+// even for relative queries like "the last hour".
+package paging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CursorArgument is the name of the tool argument carrying a cursor.
+const CursorArgument = "cursor"
+
+// CursorOption declares the cursor argument on a paged tool.
+func CursorOption() mcp.ToolOption {
+	return mcp.WithString(CursorArgument, mcp.Description("Opaque cursor returned by a previous call of this tool to fetch the next page of results. Leave empty for the first page, and don't change the other arguments when passing it."))
+}
+
+// Cursor is the decoded form of a cursor.
+type Cursor struct {
+	// PageToken is the upstream API page token, if any.
+	PageToken string `json:"t,omitempty"`
+	// Offset is the index of the first item of the page in a client-side list.
+	Offset int `json:"o,omitempty"`
+	// Query is the resolved query that produced the cursor.
+	Query string `json:"q,omitempty"`
+}
+
+// Encode returns the opaque form of c.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a cursor returned by Encode. An empty string is the cursor of
+// the first page.
+func Decode(s string) (Cursor, error) {
+	var c Cursor
+	if s == "" {
+		return c, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if c.Offset < 0 {
+		return c, fmt.Errorf("invalid cursor: negative offset")
+	}
+	return c, nil
+}
+
+// Slice returns the page of items starting at the cursor's offset, and the
+// cursor of the next page, or nil if this is the last one.
+func Slice[T any](items []T, c Cursor, pageSize int) ([]T, *Cursor) {
+	start := min(c.Offset, len(items))
+	end := min(start+pageSize, len(items))
+	if end >= len(items) {
+		return items[start:end], nil
+	}
+	return items[start:end], &Cursor{Offset: end, Query: c.Query}
+}
+
+// Footer tells the agent how to fetch the next page, or returns "" if next
+// is nil.
+func Footer(next *Cursor) string {
+	if next == nil {
+		return ""
+	}
+	return fmt.Sprintf("More results are available. Call the tool again with the same arguments and %s=%q to get the next page.", CursorArgument, next.Encode())
+}
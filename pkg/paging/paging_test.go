@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paging
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{PageToken: "abc", Offset: 3, Query: `severity>=ERROR timestamp>="2025-01-01T00:00:00Z"`}
+	got, err := Decode(want.Encode())
+	if err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Decode() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, s := range []string{"not base64!", "bm90IGpzb24", Cursor{Offset: -1}.Encode()} {
+		if _, err := Decode(s); err == nil {
+			t.Errorf("Decode(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestSlice(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name     string
+		cursor   Cursor
+		want     []int
+		wantNext *Cursor
+	}{
+		{
+			name:     "first page",
+			want:     []int{1, 2},
+			wantNext: &Cursor{Offset: 2},
+		},
+		{
+			name:     "middle page keeps query",
+			cursor:   Cursor{Offset: 2, Query: "q"},
+			want:     []int{3, 4},
+			wantNext: &Cursor{Offset: 4, Query: "q"},
+		},
+		{
+			name:   "last page",
+			cursor: Cursor{Offset: 4},
+			want:   []int{5},
+		},
+		{
+			name:   "past the end",
+			cursor: Cursor{Offset: 10},
+			want:   []int{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, next := Slice(items, tc.cursor, 2)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Slice() page mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantNext, next); diff != "" {
+				t.Errorf("Slice() next cursor mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
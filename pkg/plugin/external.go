@@ -0,0 +1,194 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// startTimeout is how long an external plugin has to start and list its
+// tools.
+const startTimeout = 30 * time.Second
+
+// toolClient is the part of an MCP client the tools of external plugins are
+// served through.
+type toolClient interface {
+	ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error)
+	CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+}
+
+var (
+	processesMu sync.Mutex
+	processes   []*client.Client
+)
+
+// Attach starts the external plugins of the configuration and serves their
+// tools, forwarding the calls to them. A plugin that fails to start is
+// logged and skipped, so it can't take the built-in tools down with it.
+func Attach(ctx context.Context, s *server.MCPServer, c *config.Config) {
+	for _, p := range c.Plugins() {
+		names, err := start(ctx, s, p, c.Version())
+		if err != nil {
+			slog.Warn("Failed to start plugin", "plugin", p.Name, "err", err)
+			continue
+		}
+		slog.Info("Started plugin", "plugin", p.Name, "tools", names)
+	}
+}
+
+// start starts an external plugin and serves its tools.
+func start(ctx context.Context, s *server.MCPServer, p config.Plugin, version string) ([]string, error) {
+	var env []string
+	for k, v := range p.Env {
+		env = append(env, k+"="+v)
+	}
+	cl, err := client.NewStdioMCPClient(p.Command, env, p.Args...)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+	initialize := mcp.InitializeRequest{}
+	initialize.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initialize.Params.ClientInfo = mcp.Implementation{Name: "gke-mcp", Version: version}
+	if _, err := cl.Initialize(ctx, initialize); err != nil {
+		cl.Close()
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+	names, err := serve(ctx, s, p, cl)
+	if err != nil {
+		cl.Close()
+		return nil, err
+	}
+	processesMu.Lock()
+	defer processesMu.Unlock()
+	processes = append(processes, cl)
+	return names, nil
+}
+
+// Close stops the external plugins.
+func Close() {
+	processesMu.Lock()
+	defer processesMu.Unlock()
+	for _, cl := range processes {
+		if err := cl.Close(); err != nil {
+			slog.Warn("Failed to stop plugin", "err", err)
+		}
+	}
+	processes = nil
+}
+
+// serve registers the tools of an external plugin on s, and returns their
+// names. Tools clashing with the ones served already, and mutating tools
+// that can't be dry run, are skipped.
+func serve(ctx context.Context, s *server.MCPServer, p config.Plugin, cl toolClient) ([]string, error) {
+	category := p.Category
+	if category == "" {
+		category = catalog.Clusters
+	}
+	if !slices.Contains(catalog.Categories, category) {
+		return nil, fmt.Errorf("unknown category %q, must be one of %v", category, catalog.Categories)
+	}
+	list, err := cl.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	served, err := toolNames(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, tool := range list.Tools {
+		remote := tool.Name
+		tool.Name = p.Prefix + remote
+		kind := kindOf(tool.Annotations)
+		switch {
+		case served[tool.Name]:
+			slog.Warn("Skipping plugin tool clashing with a served tool", "plugin", p.Name, "tool", tool.Name)
+			continue
+		case !isReadOnly(kind) && !dryrun.Supported(tool):
+			slog.Warn("Skipping mutating plugin tool without the "+dryrun.ArgumentName+" argument", "plugin", p.Name, "tool", tool.Name)
+			continue
+		}
+		catalog.Describe(category, kind)(&tool)
+		s.AddTool(tool, forward(cl, remote))
+		served[tool.Name] = true
+		names = append(names, tool.Name)
+	}
+	return names, nil
+}
+
+// forward calls a tool of an external plugin.
+func forward(cl toolClient, name string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		request.Params.Name = name
+		result, err := cl.CallTool(ctx, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return result, nil
+	}
+}
+
+// kindOf returns the catalog kind matching the annotations of a plugin's
+// tool. Like MCP, it takes a tool that doesn't say it is read-only to write,
+// and one that doesn't say it isn't destructive to be destructive.
+func kindOf(a mcp.ToolAnnotation) catalog.Kind {
+	switch {
+	case a.ReadOnlyHint != nil && *a.ReadOnlyHint:
+		if a.IdempotentHint != nil && !*a.IdempotentHint {
+			return catalog.Query
+		}
+		return catalog.Read
+	case a.DestructiveHint == nil || *a.DestructiveHint:
+		return catalog.Delete
+	}
+	return catalog.Write
+}
+
+func isReadOnly(kind catalog.Kind) bool {
+	return kind == catalog.Read || kind == catalog.Query || kind == catalog.Local
+}
+
+// toolNames returns the names of the tools s serves.
+func toolNames(ctx context.Context, s *server.MCPServer) (map[string]bool, error) {
+	resp := s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":0,"method":"tools/list"}`))
+	r, ok := resp.(mcp.JSONRPCResponse)
+	if !ok {
+		return nil, fmt.Errorf("failed to list the served tools: %v", resp)
+	}
+	result, ok := r.Result.(mcp.ListToolsResult)
+	if !ok {
+		return nil, fmt.Errorf("unexpected tools/list result type %T", r.Result)
+	}
+	names := map[string]bool{}
+	for _, tool := range result.Tools {
+		names[tool.Name] = true
+	}
+	return names, nil
+}
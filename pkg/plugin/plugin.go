@@ -0,0 +1,99 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugin registers the modules that add tools to the server. Every
+// package of pkg/tools registers itself from its init function, and so can
+// packages outside of this repository: a company's own build of the server
+// only needs to import its package next to the cmd package to serve its
+// tools. Tools can also come from external processes, see Attach.
+package plugin
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Plugin adds tools, resources or prompts to the server.
+type Plugin interface {
+	// Name identifies the plugin, e.g. the name of its package.
+	Name() string
+	// Install registers the plugin's tools on s.
+	Install(ctx context.Context, s *server.MCPServer, c *config.Config) error
+}
+
+// InstallFunc installs the tools of a plugin, like the Install functions of
+// the packages of pkg/tools.
+type InstallFunc func(ctx context.Context, s *server.MCPServer, c *config.Config) error
+
+type funcPlugin struct {
+	name    string
+	install InstallFunc
+}
+
+func (p funcPlugin) Name() string { return p.name }
+
+func (p funcPlugin) Install(ctx context.Context, s *server.MCPServer, c *config.Config) error {
+	return p.install(ctx, s, c)
+}
+
+// New returns a plugin installed by install.
+func New(name string, install InstallFunc) Plugin {
+	return funcPlugin{name: name, install: install}
+}
+
+var (
+	mu      sync.Mutex
+	plugins = map[string]Plugin{}
+)
+
+// Register adds a plugin to those installed by the server. It's meant to be
+// called from init functions, and panics if a plugin of the same name is
+// already registered.
+func Register(p Plugin) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := plugins[p.Name()]; ok {
+		panic(fmt.Sprintf("plugin %q registered twice", p.Name()))
+	}
+	plugins[p.Name()] = p
+}
+
+// Registered returns the registered plugins, sorted by name so the tools
+// are always installed in the same order.
+func Registered() []Plugin {
+	mu.Lock()
+	defer mu.Unlock()
+	list := make([]Plugin, 0, len(plugins))
+	for _, p := range plugins {
+		list = append(list, p)
+	}
+	slices.SortFunc(list, func(a, b Plugin) int { return cmp.Compare(a.Name(), b.Name()) })
+	return list
+}
+
+// Install installs the registered plugins.
+func Install(ctx context.Context, s *server.MCPServer, c *config.Config) error {
+	for _, p := range Registered() {
+		if err := p.Install(ctx, s, c); err != nil {
+			return fmt.Errorf("failed to install plugin %s: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
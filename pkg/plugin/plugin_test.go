@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/google/go-cmp/cmp"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestRegister(t *testing.T) {
+	noop := func(context.Context, *server.MCPServer, *config.Config) error { return nil }
+	Register(New("test-b", noop))
+	Register(New("test-a", noop))
+	t.Cleanup(func() {
+		delete(plugins, "test-a")
+		delete(plugins, "test-b")
+	})
+
+	var names []string
+	for _, p := range Registered() {
+		names = append(names, p.Name())
+	}
+	if diff := cmp.Diff([]string{"test-a", "test-b"}, names); diff != "" {
+		t.Errorf("Registered() mismatch (-want +got):\n%s", diff)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() of a duplicate name didn't panic")
+		}
+	}()
+	Register(New("test-a", noop))
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations mcp.ToolAnnotation
+		want        catalog.Kind
+	}{
+		{name: "no annotations", want: catalog.Delete},
+		{name: "not destructive", annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(false), DestructiveHint: mcp.ToBoolPtr(false)}, want: catalog.Write},
+		{name: "read-only", annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)}, want: catalog.Read},
+		{name: "changing reads", annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true), IdempotentHint: mcp.ToBoolPtr(false)}, want: catalog.Query},
+		{name: "destructive", annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(false), DestructiveHint: mcp.ToBoolPtr(true)}, want: catalog.Delete},
+	}
+	for _, tc := range tests {
+		if got := kindOf(tc.annotations); got != tc.want {
+			t.Errorf("%s: kindOf() = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	external := server.NewMCPServer("paved-road", "1.0")
+	echo := func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(request.Params.Name + " " + request.GetString("team", "")), nil
+	}
+	external.AddTool(mcp.NewTool("check_paved_road", mcp.WithReadOnlyHintAnnotation(true), mcp.WithString("team")), echo)
+	external.AddTool(mcp.NewTool("onboard_team", mcp.WithString("team")), echo)
+	external.AddTool(mcp.NewTool("offboard_team", mcp.WithDestructiveHintAnnotation(true), mcp.WithString("team"), mcp.WithBoolean(dryrun.ArgumentName)), echo)
+	external.AddTool(mcp.NewTool("list_clusters", mcp.WithReadOnlyHintAnnotation(true)), echo)
+	// A tool without annotations, which mcp.NewTool would fill in.
+	external.AddTool(mcp.Tool{Name: "archive_team", InputSchema: mcp.ToolInputSchema{Type: "object", Properties: map[string]any{dryrun.ArgumentName: map[string]any{"type": "boolean"}}}}, echo)
+
+	cl, err := client.NewInProcessClient(external)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	initialize := mcp.InitializeRequest{}
+	initialize.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := cl.Initialize(ctx, initialize); err != nil {
+		t.Fatal(err)
+	}
+
+	s := server.NewMCPServer("test", "1.0")
+	s.AddTool(mcp.NewTool("corp_list_clusters"), echo)
+	names, err := serve(ctx, s, config.Plugin{Name: "paved-road", Prefix: "corp_", Category: catalog.Security}, cl)
+	if err != nil {
+		t.Fatalf("serve() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"corp_archive_team", "corp_check_paved_road", "corp_offboard_team"}, names); diff != "" {
+		t.Errorf("serve() tools mismatch (-want +got):\n%s", diff)
+	}
+	if e, _ := catalog.Lookup("corp_offboard_team"); e.Kind != catalog.Delete || e.Category != catalog.Security {
+		t.Errorf("corp_offboard_team catalog entry = %+v, want a security delete tool", e)
+	}
+	if e, _ := catalog.Lookup("corp_archive_team"); e.Kind != catalog.Delete {
+		t.Errorf("corp_archive_team catalog entry = %+v, want a delete tool", e)
+	}
+
+	resp := s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"corp_check_paved_road","arguments":{"team":"payments"}}}`))
+	r, ok := resp.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("tools/call failed: %+v", resp)
+	}
+	result := r.Result.(mcp.CallToolResult)
+	if text := result.Content[0].(mcp.TextContent).Text; text != "check_paved_road payments" {
+		t.Errorf("forwarded call returned %q, want the plugin's answer", text)
+	}
+
+	if _, err := serve(ctx, s, config.Plugin{Name: "bad", Category: "paved-road"}, cl); err == nil {
+		t.Error("serve() succeeded with an unknown category, want an error")
+	}
+}
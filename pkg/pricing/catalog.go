@@ -0,0 +1,273 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"google.golang.org/api/cloudbilling/v1"
+)
+
+// Services of the Cloud Billing Catalog whose SKUs price GKE nodes, disks
+// and Autopilot pods.
+const (
+	computeService    = "services/6F81-5844-456A"
+	kubernetesService = "services/CCD8-9BF1-090E"
+)
+
+// CatalogURLs are the REST URLs listing the SKUs the prices are read from.
+var CatalogURLs = []string{
+	"https://cloudbilling.googleapis.com/v1/" + computeService + "/skus?currencyCode=" + Currency,
+	"https://cloudbilling.googleapis.com/v1/" + kubernetesService + "/skus?currencyCode=" + Currency,
+}
+
+// catalogCache keeps the prices of every region, as the catalog can only be
+// listed whole.
+var catalogCache = cache.New[map[string]*Prices]("prices")
+
+// Region returns the region of a location, which may be a zone.
+func Region(location string) string {
+	if parts := strings.Split(location, "-"); len(parts) == 3 {
+		return parts[0] + "-" + parts[1]
+	}
+	return location
+}
+
+// Lookup returns the current list prices of the region of a location from
+// the Cloud Billing Catalog, and when they were fetched.
+func Lookup(ctx context.Context, c *config.Config, location string, refresh bool) (*Prices, time.Time, error) {
+	region := Region(location)
+	regions, fetchedAt, err := catalogCache.Get(ctx, "catalog", c.CacheTTL(config.CachePrices), refresh, func(ctx context.Context) (map[string]*Prices, error) {
+		return fetchCatalog(ctx, c)
+	})
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to list the Cloud Billing Catalog: %w", err)
+	}
+	p, ok := regions[region]
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("the Cloud Billing Catalog has no GKE list prices in %s", region)
+	}
+	return p, fetchedAt, nil
+}
+
+// ForLocation returns the current list prices of the region of a location,
+// or the embedded ones, with a note saying why, if they can't be looked up.
+func ForLocation(ctx context.Context, c *config.Config, location string) *Prices {
+	p, _, err := Lookup(ctx, c, location, false)
+	if err != nil {
+		fallback := *Embedded
+		fallback.Note = fmt.Sprintf("%s The list prices of %s couldn't be looked up: %v", Note, Region(location), err)
+		return &fallback
+	}
+	return p
+}
+
+func fetchCatalog(ctx context.Context, c *config.Config) (map[string]*Prices, error) {
+	opts, err := auth.ClientOptions(ctx, c, config.APICloudBilling)
+	if err != nil {
+		return nil, err
+	}
+	svc, err := cloudbilling.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var skus []*cloudbilling.Sku
+	for _, service := range []string{computeService, kubernetesService} {
+		err := svc.Services.Skus.List(service).CurrencyCode(Currency).PageSize(5000).Pages(ctx, func(r *cloudbilling.ListSkusResponse) error {
+			skus = append(skus, r.Skus...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return parseCatalog(skus), nil
+}
+
+var (
+	// machineSKU matches the SKUs of the vCPUs and memory of predefined
+	// machine types, like "N2D AMD Instance Core running in Americas" or
+	// "Spot Preemptible E2 Instance Ram running in Belgium". C2 is "Compute
+	// optimized".
+	machineSKU = regexp.MustCompile(`^(?:([a-z][0-9][a-z]?)(?: predefined| amd| arm| intel)? instance|(compute optimized)) (core|ram) running in `)
+	// gpuSKU matches the SKUs of GPUs, like "Nvidia L4 GPU running in
+	// Americas".
+	gpuSKU = regexp.MustCompile(`^(nvidia [a-z0-9 ]+?) gpu running in `)
+	// autopilotSKU matches the SKUs of the requests of general-purpose
+	// Autopilot pods, like "Autopilot Spot Pod Memory Requests (us-east1)".
+	autopilotSKU = regexp.MustCompile(`^autopilot (spot )?pod (mcpu|memory) requests`)
+)
+
+// diskSKUs are the zonal disk types of the descriptions of their capacity
+// SKUs.
+var diskSKUs = map[string]string{
+	"storage pd capacity":         "pd-standard",
+	"balanced pd capacity":        "pd-balanced",
+	"ssd backed pd capacity":      "pd-ssd",
+	"extreme pd capacity":         "pd-extreme",
+	"hyperdisk balanced capacity": "hyperdisk-balanced",
+}
+
+// parseCatalog returns the prices of every region with the prices of
+// on-demand N2 nodes and Autopilot pods, from the SKUs of the catalog.
+func parseCatalog(skus []*cloudbilling.Sku) map[string]*Prices {
+	all := map[string]*Prices{}
+	for _, sku := range skus {
+		if sku.Category == nil {
+			continue
+		}
+		usage := sku.Category.UsageType
+		if usage != "OnDemand" && usage != "Preemptible" {
+			continue
+		}
+		price, unit, ok := basePrice(sku)
+		if !ok {
+			continue
+		}
+		desc := strings.ToLower(sku.Description)
+		isSpot := usage == "Preemptible"
+		for _, prefix := range []string{"spot preemptible ", "preemptible "} {
+			if after, ok := strings.CutPrefix(desc, prefix); ok {
+				desc, isSpot = after, true
+			}
+		}
+		var apply func(p *Prices)
+		if m := machineSKU.FindStringSubmatch(desc); m != nil {
+			family := m[1]
+			if m[2] != "" {
+				family = "c2"
+			}
+			switch {
+			case m[3] == "core" && unit == "s":
+				apply = func(p *Prices) { setRate(p.rates(isSpot), family, price*3600, -1) }
+			case m[3] == "ram" && unit == "By.s":
+				apply = func(p *Prices) { setRate(p.rates(isSpot), family, -1, price*3600*(1<<30)) }
+			}
+		}
+		if m := gpuSKU.FindStringSubmatch(desc); m != nil && unit == "s" && !strings.Contains(desc, "workstation") {
+			name := strings.ReplaceAll(m[1], " ", "-")
+			apply = func(p *Prices) {
+				if isSpot {
+					p.SpotGPUs[name] = price * 3600
+				} else {
+					p.GPUs[name] = price * 3600
+				}
+			}
+		}
+		capacity, _, _ := strings.Cut(desc, " in ")
+		if disk, ok := diskSKUs[capacity]; ok && !isSpot && unit == "By.s" {
+			apply = func(p *Prices) { p.Disks[disk] = price * 3600 * HoursPerMonth * (1 << 30) }
+		}
+		if m := autopilotSKU.FindStringSubmatch(desc); m != nil {
+			rate := func(p *Prices) *Rate {
+				if m[1] != "" {
+					return &p.AutopilotSpotPods
+				}
+				return &p.AutopilotPods
+			}
+			switch {
+			case m[2] == "mcpu" && unit == "s":
+				apply = func(p *Prices) { rate(p).CPU = price * 3600 }
+			case m[2] == "memory" && unit == "By.s":
+				apply = func(p *Prices) { rate(p).Memory = price * 3600 * (1 << 30) }
+			}
+		}
+		if apply == nil {
+			continue
+		}
+		for _, region := range sku.ServiceRegions {
+			p := all[region]
+			if p == nil {
+				p = &Prices{
+					Region:   region,
+					Note:     fmt.Sprintf("On-demand and Spot list prices in %s from the Cloud Billing Catalog, without committed use discounts, GPUs, storage or networking.", region),
+					OnDemand: map[string]Rate{},
+					Spot:     map[string]Rate{},
+					GPUs:     map[string]float64{},
+					SpotGPUs: map[string]float64{},
+					Disks:    map[string]float64{},
+				}
+				all[region] = p
+			}
+			apply(p)
+		}
+	}
+
+	regions := map[string]*Prices{}
+	for region, p := range all {
+		for _, rates := range []map[string]Rate{p.OnDemand, p.Spot} {
+			for family, r := range rates {
+				if r.CPU == 0 || r.Memory == 0 {
+					delete(rates, family)
+				}
+			}
+		}
+		n2 := p.OnDemand["n2"]
+		if n2.CPU == 0 || p.AutopilotPods.CPU == 0 || p.AutopilotPods.Memory == 0 {
+			continue
+		}
+		regions[region] = p
+	}
+	return regions
+}
+
+func (p *Prices) rates(isSpot bool) map[string]Rate {
+	if isSpot {
+		return p.Spot
+	}
+	return p.OnDemand
+}
+
+// setRate sets the price of a vCPU or GiB of memory of a machine family,
+// leaving negative ones unchanged.
+func setRate(rates map[string]Rate, family string, cpu, memory float64) {
+	r := rates[family]
+	if cpu >= 0 {
+		r.CPU = cpu
+	}
+	if memory >= 0 {
+		r.Memory = memory
+	}
+	rates[family] = r
+}
+
+// basePrice returns the price of a SKU per base unit, like seconds for
+// vCPUs or byte-seconds for memory and disks, and the base unit. Tiered
+// prices use the first one that isn't free.
+func basePrice(sku *cloudbilling.Sku) (float64, string, bool) {
+	if len(sku.PricingInfo) == 0 {
+		return 0, "", false
+	}
+	expr := sku.PricingInfo[len(sku.PricingInfo)-1].PricingExpression
+	if expr == nil || expr.BaseUnitConversionFactor == 0 {
+		return 0, "", false
+	}
+	for _, t := range expr.TieredRates {
+		if t.UnitPrice == nil {
+			continue
+		}
+		if v := float64(t.UnitPrice.Units) + float64(t.UnitPrice.Nanos)/1e9; v > 0 {
+			return v / expr.BaseUnitConversionFactor, expr.BaseUnit, true
+		}
+	}
+	return 0, "", false
+}
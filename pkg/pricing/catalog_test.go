@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pricing
+
+import (
+	"math"
+	"testing"
+
+	"google.golang.org/api/cloudbilling/v1"
+)
+
+// testSKU returns a SKU of regions priced nanos per hour, or per GiB and
+// hour or month for memory and disks.
+func testSKU(description, usageType, baseUnit string, nanos int64, regions ...string) *cloudbilling.Sku {
+	var factor float64
+	switch baseUnit {
+	case "s":
+		factor = 3600
+	case "By.s":
+		factor = 3600 * (1 << 30)
+	case "By.mo":
+		baseUnit, factor = "By.s", 3600*HoursPerMonth*(1<<30)
+	}
+	return &cloudbilling.Sku{
+		Description:    description,
+		Category:       &cloudbilling.Category{UsageType: usageType},
+		ServiceRegions: regions,
+		PricingInfo: []*cloudbilling.PricingInfo{{PricingExpression: &cloudbilling.PricingExpression{
+			BaseUnit:                 baseUnit,
+			BaseUnitConversionFactor: factor,
+			TieredRates: []*cloudbilling.TierRate{
+				{UnitPrice: &cloudbilling.Money{}},
+				{StartUsageAmount: 10, UnitPrice: &cloudbilling.Money{Nanos: nanos}},
+			},
+		}}},
+	}
+}
+
+func TestParseCatalog(t *testing.T) {
+	skus := []*cloudbilling.Sku{
+		testSKU("N2 Instance Core running in Americas", "OnDemand", "s", 31611000, "us-central1", "us-east1"),
+		testSKU("N2 Instance Ram running in Americas", "OnDemand", "By.s", 4237000, "us-central1", "us-east1"),
+		testSKU("Spot Preemptible N2D AMD Instance Core running in Americas", "Preemptible", "s", 3920000, "us-central1"),
+		testSKU("Spot Preemptible N2D AMD Instance Ram running in Americas", "Preemptible", "By.s", 525000, "us-central1"),
+		testSKU("Compute optimized Core running in Americas", "OnDemand", "s", 33980000, "us-central1"),
+		testSKU("Compute optimized Ram running in Americas", "OnDemand", "By.s", 4550000, "us-central1"),
+		testSKU("N2 Custom Instance Core running in Americas", "OnDemand", "s", 33191000, "us-central1"),
+		testSKU("Commitment v1: N2 Cpu in Americas for 1 Year", "Commit1Yr", "s", 19915000, "us-central1"),
+		testSKU("C3 Instance Core running in Americas", "OnDemand", "s", 34650000, "us-central1"),
+		testSKU("Nvidia L4 GPU running in Americas", "OnDemand", "s", 560000000, "us-central1"),
+		testSKU("Spot Preemptible Nvidia Tesla T4 GPU running in Americas", "Preemptible", "s", 140000000, "us-central1"),
+		testSKU("Nvidia Tesla T4 Virtual Workstation GPU running in Americas", "OnDemand", "s", 550000000, "us-central1"),
+		testSKU("Balanced PD Capacity", "OnDemand", "By.mo", 100000000, "us-central1"),
+		testSKU("Regional Balanced PD Capacity", "OnDemand", "By.mo", 200000000, "us-central1"),
+		testSKU("Autopilot Pod mCPU Requests (us-central1)", "OnDemand", "s", 44500000, "us-central1"),
+		testSKU("Autopilot Pod Memory Requests (us-central1)", "OnDemand", "By.s", 4922500, "us-central1"),
+		testSKU("Autopilot Spot Pod mCPU Requests (us-central1)", "OnDemand", "s", 13300000, "us-central1"),
+		testSKU("Autopilot Spot Pod Memory Requests (us-central1)", "OnDemand", "By.s", 1476700, "us-central1"),
+	}
+	regions := parseCatalog(skus)
+	if _, ok := regions["us-east1"]; ok || len(regions) != 1 {
+		t.Fatalf("parseCatalog() regions = %v, want only us-central1, as us-east1 has no Autopilot prices", regions)
+	}
+	p := regions["us-central1"]
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	rates := []struct {
+		name      string
+		got, want Rate
+	}{
+		{"n2", p.OnDemand["n2"], Rate{CPU: 0.031611, Memory: 0.004237}},
+		{"c2", p.OnDemand["c2"], Rate{CPU: 0.03398, Memory: 0.00455}},
+		{"spot n2d", p.Spot["n2d"], Rate{CPU: 0.00392, Memory: 0.000525}},
+		{"autopilot", p.AutopilotPods, Rate{CPU: 0.0445, Memory: 0.0049225}},
+		{"spot autopilot", p.AutopilotSpotPods, Rate{CPU: 0.0133, Memory: 0.0014767}},
+	}
+	for _, r := range rates {
+		if !near(r.got.CPU, r.want.CPU) || !near(r.got.Memory, r.want.Memory) {
+			t.Errorf("parseCatalog() %s rate = %v, want %v", r.name, r.got, r.want)
+		}
+	}
+	if _, ok := p.OnDemand["c3"]; ok {
+		t.Errorf("parseCatalog() kept c3 without a memory price")
+	}
+	if len(p.OnDemand) != 2 || len(p.Spot) != 1 {
+		t.Errorf("parseCatalog() families = %v and %v, want n2 and c2 on demand, n2d on Spot", p.OnDemand, p.Spot)
+	}
+	if len(p.GPUs) != 1 || !near(p.GPUs["nvidia-l4"], 0.56) || !near(p.SpotGPUs["nvidia-tesla-t4"], 0.14) {
+		t.Errorf("parseCatalog() GPUs = %v and %v on Spot, want nvidia-l4 at 0.56 and nvidia-tesla-t4 at 0.14 on Spot", p.GPUs, p.SpotGPUs)
+	}
+	if len(p.Disks) != 1 || !near(p.Disks["pd-balanced"], 0.1) {
+		t.Errorf("parseCatalog() disks = %v, want pd-balanced at 0.1", p.Disks)
+	}
+}
+
+func TestPricesMachine(t *testing.T) {
+	p := &Prices{OnDemand: map[string]Rate{"n2": {CPU: 1, Memory: 0.1}}, Spot: map[string]Rate{}}
+	if r, ok := p.Machine("n2-standard-4", false); !ok || r != p.OnDemand["n2"] {
+		t.Errorf("Machine(n2-standard-4) = %v, %v, want %v, true", r, ok, p.OnDemand["n2"])
+	}
+	if r, ok := p.Machine("x4-megamem-960", false); ok || r != p.OnDemand["n2"] {
+		t.Errorf("Machine(x4-megamem-960) = %v, %v, want the N2 rate %v, false", r, ok, p.OnDemand["n2"])
+	}
+	if r, ok := p.Machine("e2-medium", true); ok || r != Embedded.Spot["n2"] {
+		t.Errorf("Machine(e2-medium, spot) = %v, %v, want the embedded Spot N2 rate %v, false", r, ok, Embedded.Spot["n2"])
+	}
+}
+
+func TestRegion(t *testing.T) {
+	for location, want := range map[string]string{"us-central1-a": "us-central1", "europe-west4": "europe-west4"} {
+		if got := Region(location); got != want {
+			t.Errorf("Region(%q) = %q, want %q", location, got, want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pricing estimates what GKE compute costs, from the list prices of
+// the Cloud Billing Catalog in the cluster's region, or list prices embedded
+// in the server when the catalog can't be read. The estimates are meant for
+// comparisons and rough budgets, not billing.
+package pricing
+
+import (
+	"math"
+	"strings"
+)
+
+// HoursPerMonth is the average number of hours in a month.
+const HoursPerMonth = 730
+
+// Currency is the currency of the prices.
+const Currency = "USD"
+
+// Note describes the embedded prices, for the notes of estimates.
+const Note = "On-demand and Spot list prices in us-central1, without committed use discounts, GPUs, storage or networking."
+
+// Rate is the hourly price of a vCPU and a GiB of memory.
+type Rate struct {
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memoryGiB"`
+}
+
+// Price returns the hourly price of vCPUs and GiB of memory.
+func (r Rate) Price(cpu, memory float64) float64 {
+	return cpu*r.CPU + memory*r.Memory
+}
+
+// Prices are the list prices of a region, in USD.
+type Prices struct {
+	Region string `json:"region"`
+	// Note describes the prices, for the notes of estimates.
+	Note string `json:"note"`
+	// OnDemand and Spot are the hourly rates of the machine families, like
+	// n2.
+	OnDemand map[string]Rate `json:"onDemand"`
+	Spot     map[string]Rate `json:"spot"`
+	// AutopilotPods and AutopilotSpotPods are the hourly rates of the
+	// general-purpose Autopilot pods.
+	AutopilotPods     Rate `json:"autopilot"`
+	AutopilotSpotPods Rate `json:"autopilotSpot"`
+	// GPUs and SpotGPUs are the hourly prices of the accelerators attached
+	// to nodes, by their GKE name like nvidia-l4.
+	GPUs     map[string]float64 `json:"gpus,omitempty"`
+	SpotGPUs map[string]float64 `json:"spotGpus,omitempty"`
+	// Disks are the monthly prices of a GiB of the zonal disk types, like
+	// pd-balanced.
+	Disks map[string]float64 `json:"disks,omitempty"`
+}
+
+// Embedded are the hourly list prices in us-central1. Spot prices change
+// over time; these are typical ones.
+var Embedded = &Prices{
+	Region:            "us-central1",
+	Note:              Note,
+	AutopilotPods:     Rate{CPU: 0.0445, Memory: 0.0049225},
+	AutopilotSpotPods: Rate{CPU: 0.0133, Memory: 0.0014767},
+	OnDemand: map[string]Rate{
+		"e2":  {CPU: 0.021811, Memory: 0.002923},
+		"n1":  {CPU: 0.031611, Memory: 0.004237},
+		"n2":  {CPU: 0.031611, Memory: 0.004237},
+		"n2d": {CPU: 0.027502, Memory: 0.003686},
+		"t2d": {CPU: 0.027502, Memory: 0.003686},
+		"c2":  {CPU: 0.03398, Memory: 0.00455},
+		"c2d": {CPU: 0.029563, Memory: 0.003959},
+		"c3":  {CPU: 0.03465, Memory: 0.003938},
+	},
+	Spot: map[string]Rate{
+		"e2":  {CPU: 0.006543, Memory: 0.000877},
+		"n1":  {CPU: 0.006655, Memory: 0.000892},
+		"n2":  {CPU: 0.007650, Memory: 0.001025},
+		"n2d": {CPU: 0.003920, Memory: 0.000525},
+		"t2d": {CPU: 0.005770, Memory: 0.000774},
+		"c2":  {CPU: 0.008230, Memory: 0.001102},
+		"c2d": {CPU: 0.003920, Memory: 0.000525},
+		"c3":  {CPU: 0.008380, Memory: 0.000952},
+	},
+}
+
+// Family returns the machine family of a machine type, e.g. n2 for
+// n2-standard-4.
+func Family(machineType string) string {
+	family, _, _ := strings.Cut(machineType, "-")
+	return strings.ToLower(family)
+}
+
+// Machine returns the rate of the nodes of a machine type, and false if its
+// family isn't known, in which case it's priced like N2.
+func (p *Prices) Machine(machineType string, isSpot bool) (Rate, bool) {
+	rates, fallback := p.OnDemand, Embedded.OnDemand
+	if isSpot {
+		rates, fallback = p.Spot, Embedded.Spot
+	}
+	if r, ok := rates[Family(machineType)]; ok {
+		return r, true
+	}
+	if r, ok := rates["n2"]; ok {
+		return r, false
+	}
+	return fallback["n2"], false
+}
+
+// Autopilot returns the rate of the general-purpose Autopilot pods.
+func (p *Prices) Autopilot(isSpot bool) Rate {
+	if isSpot {
+		return p.AutopilotSpotPods
+	}
+	return p.AutopilotPods
+}
+
+// Machine returns the embedded rate of the nodes of a machine type. See
+// Prices.Machine.
+func Machine(machineType string, isSpot bool) (Rate, bool) {
+	return Embedded.Machine(machineType, isSpot)
+}
+
+// Autopilot returns the embedded rate of the general-purpose Autopilot pods.
+func Autopilot(isSpot bool) Rate {
+	return Embedded.Autopilot(isSpot)
+}
+
+// AutopilotResources returns the vCPUs and GiB of memory Autopilot bills for
+// a pod requesting cpu and memory, applying its minimums and memory to CPU
+// ratio. Containers without requests count as requesting the defaults of
+// AutopilotDefaults.
+func AutopilotResources(cpu, memory float64) (float64, float64) {
+	cpu = math.Max(cpu, 0.05)
+	memory = math.Max(memory, 52.0/1024)
+	// Memory must be 1 to 6.5 GiB per vCPU.
+	memory = math.Max(memory, cpu)
+	cpu = math.Max(cpu, memory/6.5)
+	return cpu, memory
+}
+
+// AutopilotDefaults are the vCPUs and GiB of memory Autopilot requests for
+// containers that request neither.
+var AutopilotDefaults = struct{ CPU, Memory float64 }{CPU: 0.5, Memory: 2}
+
+// Monthly returns the monthly price of an hourly one, rounded to cents.
+func Monthly(hourly float64) float64 {
+	return Round(hourly * HoursPerMonth)
+}
+
+// Round rounds a price to cents.
+func Round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
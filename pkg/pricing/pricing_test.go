@@ -0,0 +1,64 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pricing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMachine(t *testing.T) {
+	tests := []struct {
+		machineType string
+		spot        bool
+		want        Rate
+		wantOK      bool
+	}{
+		{"e2-standard-4", false, Embedded.OnDemand["e2"], true},
+		{"n2d-highmem-8", true, Embedded.Spot["n2d"], true},
+		{"a3-highgpu-8g", false, Embedded.OnDemand["n2"], false},
+		{"", true, Embedded.Spot["n2"], false},
+	}
+	for _, tc := range tests {
+		got, ok := Machine(tc.machineType, tc.spot)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("Machine(%q, %v) = %v, %v, want %v, %v", tc.machineType, tc.spot, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestAutopilotResources(t *testing.T) {
+	tests := []struct {
+		cpu, memory         float64
+		wantCPU, wantMemory float64
+	}{
+		{1, 4, 1, 4},
+		{0.01, 0.01, 0.05, 52.0 / 1024},
+		{2, 0.5, 2, 2},
+		{0.5, 13, 2, 13},
+	}
+	for _, tc := range tests {
+		cpu, memory := AutopilotResources(tc.cpu, tc.memory)
+		if math.Abs(cpu-tc.wantCPU) > 1e-9 || math.Abs(memory-tc.wantMemory) > 1e-9 {
+			t.Errorf("AutopilotResources(%v, %v) = %v, %v, want %v, %v", tc.cpu, tc.memory, cpu, memory, tc.wantCPU, tc.wantMemory)
+		}
+	}
+}
+
+func TestMonthly(t *testing.T) {
+	if got := Monthly(Rate{CPU: 0.02, Memory: 0.003}.Price(4, 16)); got != 93.44 {
+		t.Errorf("Monthly() = %v, want 93.44", got)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prompts provides MCP prompts for common GKE workflows. Clients
+// typically expose them as slash commands; each one walks the agent through
+// the tools to call for the workflow.
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+// clusterArguments are the arguments of prompts about a cluster. They
+// default to the session context, then to the configuration.
+var clusterArguments = []mcp.PromptOption{
+	mcp.WithArgument("cluster", mcp.ArgumentDescription("GKE cluster name. Defaults to the session context.")),
+	mcp.WithArgument("location", mcp.ArgumentDescription("GKE cluster location. Defaults to the session context.")),
+	mcp.WithArgument("project_id", mcp.ArgumentDescription("GCP project ID. Defaults to the session context.")),
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	s.AddPrompt(mcp.NewPrompt("triage_deployment", append([]mcp.PromptOption{
+		mcp.WithPromptDescription("Triage a failing Kubernetes deployment: find why its pods don't become ready from the cluster status, logs and events, and propose a fix."),
+		mcp.WithArgument("deployment", mcp.RequiredArgument(), mcp.ArgumentDescription("Name of the deployment.")),
+		mcp.WithArgument("namespace", mcp.ArgumentDescription("Namespace of the deployment. Defaults to default.")),
+	}, clusterArguments...)...), h.triageDeployment)
+
+	s.AddPrompt(mcp.NewPrompt("plan_cluster_upgrade", append([]mcp.PromptOption{
+		mcp.WithPromptDescription("Plan the upgrade of a GKE cluster: pick the target version, check for deprecated APIs and known issues, and order the control plane and node pool upgrades."),
+		mcp.WithArgument("target_version", mcp.ArgumentDescription("GKE version to upgrade to. Defaults to the default version of the cluster's release channel.")),
+	}, clusterArguments...)...), h.planClusterUpgrade)
+
+	s.AddPrompt(mcp.NewPrompt("analyze_cluster_cost", append([]mcp.PromptOption{
+		mcp.WithPromptDescription("Analyze the cost of a GKE cluster, or of every cluster of a project: break it down by namespace and workload and find savings."),
+		mcp.WithArgument("period", mcp.ArgumentDescription("Period to analyze, e.g. last 7 days. Defaults to the last 30 days.")),
+	}, clusterArguments...)...), h.analyzeClusterCost)
+
+	return nil
+}
+
+// target returns the cluster a prompt is about, as a phrase for the prompt,
+// along with what the agent should do if the cluster isn't known.
+func (h *handlers) target(ctx context.Context, request mcp.GetPromptRequest) string {
+	args := request.Params.Arguments
+	sc := session.Default.Get(ctx)
+	projectID := firstNonEmpty(args["project_id"], sc.ProjectID, h.c.DefaultProjectID())
+	location := firstNonEmpty(args["location"], sc.Location, h.c.DefaultLocation())
+	cluster := firstNonEmpty(args["cluster"], sc.Cluster, h.c.DefaultCluster())
+
+	if cluster == "" || location == "" || projectID == "" {
+		known := []string{}
+		for _, kv := range [][2]string{{"project", projectID}, {"location", location}, {"cluster", cluster}} {
+			if kv[1] != "" {
+				known = append(known, fmt.Sprintf("%s %s", kv[0], kv[1]))
+			}
+		}
+		phrase := "a GKE cluster"
+		if len(known) > 0 {
+			phrase += " (" + strings.Join(known, ", ") + ")"
+		}
+		return phrase + ". The cluster isn't fully known: find it with list_clusters and confirm it with me before going further"
+	}
+	return fmt.Sprintf("the GKE cluster %s in location %s of project %s", cluster, location, projectID)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// userPrompt returns a prompt made of a single user message.
+func userPrompt(description, text string) *mcp.GetPromptResult {
+	return mcp.NewGetPromptResult(description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+	})
+}
+
+func (h *handlers) triageDeployment(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	deployment := request.Params.Arguments["deployment"]
+	if deployment == "" {
+		return nil, fmt.Errorf("deployment argument not set")
+	}
+	namespace := firstNonEmpty(request.Params.Arguments["namespace"], "default")
+
+	return userPrompt("Triage a failing deployment", fmt.Sprintf(`The deployment %[1]s in namespace %[2]s of %[3]s is failing. Find out why and how to fix it:
+
+1. Call get_cluster to check that the cluster and its node pools are running, and note any node pool that is being upgraded or repaired.
+2. Call get_log_schema, then query_logs for the container logs of the pods of %[1]s in namespace %[2]s over the last hour, errors and warnings first.
+3. Call query_logs for the Kubernetes events of namespace %[2]s, looking for failed scheduling, image pulls, probes, OOM kills and crash loops.
+4. If kubectl is available, run kubectl rollout status and kubectl describe on the deployment and its pods for their current state.
+5. Call get_instructions for the symptoms you found, e.g. known issues of the cluster version.
+
+Then tell me the most likely cause, the evidence for it, and the fix. Don't change anything in the cluster without asking me first.`, deployment, namespace, h.target(ctx, request))), nil
+}
+
+func (h *handlers) planClusterUpgrade(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	targetVersion := "the default version of the cluster's release channel"
+	if v := request.Params.Arguments["target_version"]; v != "" {
+		targetVersion = "version " + v
+	}
+
+	return userPrompt("Plan a cluster upgrade", fmt.Sprintf(`Plan the upgrade of %[1]s to %[2]s:
+
+1. Call get_cluster for the current control plane and node pool versions, the release channel, the maintenance window and the surge settings of the node pools.
+2. Call get_server_config for the cluster's location, and check that the target version is valid for the release channel and how far it is from the current versions.
+3. Call list_recommendations for the cluster, looking for deprecated API usage and other insights that would block or break the upgrade.
+4. Call get_instructions for the GKE known issues and release notes of the target version.
+
+Then give me a step by step plan: the control plane upgrade first, then each node pool with its surge or blue-green settings, what to check between steps and how to roll back. Only plan the upgrade, don't start it.`, h.target(ctx, request), targetVersion)), nil
+}
+
+func (h *handlers) analyzeClusterCost(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	period := firstNonEmpty(request.Params.Arguments["period"], "the last 30 days")
+
+	return userPrompt("Analyze cluster cost", fmt.Sprintf(`Analyze the cost of %[1]s over %[2]s:
+
+1. Call get_instructions for GKE cost, and follow it to query the billing export in BigQuery for the cost of the cluster by namespace and workload.
+2. Call get_cluster for the machine types, spot usage and autoscaling of the node pools.
+3. Call list_recommendations for the cluster, looking for idle resources and rightsizing recommendations.
+
+Then summarize where the money goes, the biggest savings opportunities with their estimated impact, and the trade-offs of each.`, h.target(ctx, request), period)), nil
+}
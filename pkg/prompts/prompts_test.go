@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func getPrompt(t *testing.T, s *server.MCPServer, name string, args map[string]string) string {
+	t.Helper()
+	message, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "prompts/get",
+		"params":  map[string]any{"name": name, "arguments": args},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := s.HandleMessage(context.Background(), message)
+	r, ok := resp.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("prompts/get %s failed: %+v", name, resp)
+	}
+	result := r.Result.(mcp.GetPromptResult)
+	return result.Messages[0].Content.(mcp.TextContent).Text
+}
+
+func TestPrompts(t *testing.T) {
+	s := server.NewMCPServer("test", "0.0.0", server.WithPromptCapabilities(false))
+	c := config.New("test", config.WithDefaultProjectID("p"), config.WithDefaultLocation("us-central1"))
+	if err := Install(context.Background(), s, c); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]string
+		want []string
+	}{
+		{
+			name: "triage_deployment",
+			args: map[string]string{"deployment": "web", "cluster": "c"},
+			want: []string{"deployment web in namespace default", "cluster c in location us-central1 of project p", "query_logs"},
+		},
+		{
+			name: "plan_cluster_upgrade",
+			args: map[string]string{"target_version": "1.31.1-gke.100"},
+			want: []string{"project p, location us-central1", "list_clusters", "version 1.31.1-gke.100", "get_server_config"},
+		},
+		{
+			name: "analyze_cluster_cost",
+			args: map[string]string{"cluster": "c", "period": "last week"},
+			want: []string{"over last week", "list_recommendations"},
+		},
+	}
+	for _, tc := range tests {
+		got := getPrompt(t, s, tc.name, tc.args)
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("prompt %s = %q, want it to contain %q", tc.name, got, want)
+			}
+		}
+	}
+}
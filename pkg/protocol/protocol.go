@@ -0,0 +1,125 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package protocol reports the MCP protocol versions the server supports and
+// keeps track of the clients connected to it, so the server can warn about
+// clients that negotiate a version it doesn't support.
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Supported returns the MCP protocol versions the server supports, oldest
+// first.
+func Supported() []string {
+	return slices.Sorted(slices.Values(mcp.ValidProtocolVersions))
+}
+
+// Latest returns the latest MCP protocol version the server supports. It
+// answers with it when a client asks for a version it doesn't support.
+func Latest() string {
+	return mcp.LATEST_PROTOCOL_VERSION
+}
+
+// Incompatibility explains why a client asking for the protocol version
+// requested may not work with the server, or returns "" if the server
+// supports it.
+func Incompatibility(requested string) string {
+	switch {
+	case slices.Contains(mcp.ValidProtocolVersions, requested):
+		return ""
+	case requested == "":
+		return fmt.Sprintf("the client didn't ask for an MCP protocol version, the server answered with %s", Latest())
+	// Protocol versions are dates, so they compare as strings.
+	case requested > Latest():
+		return fmt.Sprintf("the client asked for MCP protocol version %s, which is newer than the server supports; the server answered with %s, so the client may disconnect or miss the features added since", requested, Latest())
+	default:
+		return fmt.Sprintf("the client asked for MCP protocol version %s, which the server doesn't support; the server answered with %s, which the client may not understand", requested, Latest())
+	}
+}
+
+// Client is an MCP client connected to the server.
+type Client struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Requested is the protocol version the client asked for, and Negotiated
+	// the one the server answered with.
+	Requested  string `json:"requested_protocol_version"`
+	Negotiated string `json:"negotiated_protocol_version"`
+}
+
+// Clients holds the client of every session.
+type Clients struct {
+	mu      sync.Mutex
+	clients map[string]Client
+}
+
+// Default is the registry used by the server and its tools.
+var Default = NewClients()
+
+func NewClients() *Clients {
+	return &Clients{clients: map[string]Client{}}
+}
+
+// sessionID returns the ID of the MCP session of ctx, or "" outside of one.
+func sessionID(ctx context.Context) string {
+	if s := server.ClientSessionFromContext(ctx); s != nil {
+		return s.SessionID()
+	}
+	return ""
+}
+
+// Get returns the client of the session of ctx, if it has initialized.
+func (c *Clients) Get(ctx context.Context) (Client, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	client, ok := c.clients[sessionID(ctx)]
+	return client, ok
+}
+
+// AfterInitializeHook records the client of a session once it has
+// initialized, and warns if it asked for a protocol version the server
+// doesn't support.
+func (c *Clients) AfterInitializeHook(ctx context.Context, _ any, request *mcp.InitializeRequest, result *mcp.InitializeResult) {
+	client := Client{
+		Name:       request.Params.ClientInfo.Name,
+		Version:    request.Params.ClientInfo.Version,
+		Requested:  request.Params.ProtocolVersion,
+		Negotiated: result.ProtocolVersion,
+	}
+	c.mu.Lock()
+	c.clients[sessionID(ctx)] = client
+	c.mu.Unlock()
+
+	if reason := Incompatibility(client.Requested); reason != "" {
+		slog.Warn("MCP client may be incompatible with the server", "client", client.Name, "client_version", client.Version, "reason", reason)
+	} else {
+		slog.Info("MCP client connected", "client", client.Name, "client_version", client.Version, "protocol_version", client.Negotiated)
+	}
+}
+
+// UnregisterHook forgets the client of sessions that end.
+func (c *Clients) UnregisterHook(_ context.Context, session server.ClientSession) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.clients, session.SessionID())
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestIncompatibility(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{requested: Latest()},
+		{requested: Supported()[0]},
+		{requested: "2099-01-01", want: "newer than the server supports"},
+		{requested: "2024-01-01", want: "doesn't support"},
+		{requested: "", want: "didn't ask"},
+	}
+	for _, tc := range tests {
+		got := Incompatibility(tc.requested)
+		if tc.want == "" && got != "" || !strings.Contains(got, tc.want) {
+			t.Errorf("Incompatibility(%q) = %q, want it to contain %q", tc.requested, got, tc.want)
+		}
+	}
+}
+
+func TestClients(t *testing.T) {
+	clients := NewClients()
+	ctx := context.Background()
+
+	request := &mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = "2099-01-01"
+	request.Params.ClientInfo = mcp.Implementation{Name: "client", Version: "1.0"}
+	clients.AfterInitializeHook(ctx, 1, request, &mcp.InitializeResult{ProtocolVersion: Latest()})
+
+	want := Client{Name: "client", Version: "1.0", Requested: "2099-01-01", Negotiated: Latest()}
+	got, ok := clients.Get(ctx)
+	if !ok {
+		t.Fatalf("Get() found no client after initialization")
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit throttles GCP API calls so that large scans stay within
+// per-API quotas instead of burning through them, and retries the reads
+// among them with apierrors.Retry.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/apierrors"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc"
+)
+
+// Limit is the sustained rate and burst allowed for a single API.
+type Limit struct {
+	PerSecond float64
+	Burst     int
+}
+
+// defaultLimit applies to APIs without an entry in apiLimits.
+var defaultLimit = Limit{PerSecond: 10, Burst: 20}
+
+// apiLimits keeps each API comfortably under its default per-project quota.
+// Keys are gRPC service name prefixes.
+var apiLimits = map[string]Limit{
+	// Cloud Logging allows 60 entries.list calls per minute.
+	"google.logging":    {PerSecond: 1, Burst: 5},
+	"google.container":  {PerSecond: 10, Burst: 20},
+	"google.monitoring": {PerSecond: 20, Burst: 40},
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = map[string]*rate.Limiter{}
+)
+
+// limiterFor returns the shared limiter for the API serving a full gRPC
+// method name like "/google.container.v1.ClusterManager/ListClusters", or for
+// a REST API named like config.APICompute.
+func limiterFor(method string) *rate.Limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	api, limit := apiFor(method)
+	l, ok := limiters[api]
+	if !ok {
+		l = rate.NewLimiter(rate.Limit(limit.PerSecond), limit.Burst)
+		limiters[api] = l
+	}
+	return l
+}
+
+// apiFor returns the API serving method and its limit. limitersMu must be
+// held.
+func apiFor(method string) (string, Limit) {
+	method = strings.TrimPrefix(method, "/")
+	for prefix, limit := range apiLimits {
+		if strings.HasPrefix(method, prefix) {
+			return prefix, limit
+		}
+	}
+	api, _, _ := strings.Cut(method, "/")
+	return api, defaultLimit
+}
+
+// SetLimit overrides the limit of the API identified by prefix.
+func SetLimit(prefix string, limit Limit) {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	apiLimits[prefix] = limit
+	delete(limiters, prefix)
+}
+
+// Stats counts how often the GCP API calls made for one tool call were
+// throttled.
+type Stats struct {
+	waits   atomic.Int64
+	retries atomic.Int64
+}
+
+type statsKey struct{}
+
+func statsFromContext(ctx context.Context) *Stats {
+	s, _ := ctx.Value(statsKey{}).(*Stats)
+	return s
+}
+
+// wait blocks until the limiter for method admits another call.
+func wait(ctx context.Context, method string) error {
+	l := limiterFor(method)
+	r := l.Reserve()
+	if !r.OK() {
+		return fmt.Errorf("rate limiter for %s cannot admit the call", method)
+	}
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if s := statsFromContext(ctx); s != nil {
+		s.waits.Add(1)
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retry calls fn with apierrors.Retry, counting its retries in the stats of
+// ctx.
+func retry(ctx context.Context, fn func() error) error {
+	attempt := 0
+	return apierrors.Retry(ctx, func() error {
+		if attempt++; attempt > 1 {
+			if s := statsFromContext(ctx); s != nil {
+				s.retries.Add(1)
+			}
+		}
+		return fn()
+	})
+}
+
+// idempotentPrefixes start the names of the gRPC methods that only read, so
+// repeating them never applies a change twice.
+var idempotentPrefixes = []string{"Get", "List", "Search", "Query", "BatchGet", "Check", "TestIamPermissions", "Lookup", "Read"}
+
+// idempotent reports whether the gRPC method, a full name like
+// "/google.container.v1.ClusterManager/ListClusters", is safe to repeat.
+// Mutations like CreateNodePool may have been applied even when they fail
+// with UNAVAILABLE, so they are never retried.
+func idempotent(method string) bool {
+	name := method[strings.LastIndex(method, "/")+1:]
+	for _, prefix := range idempotentPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryClientInterceptor rate limits gRPC calls and retries the reads failing
+// with an apierrors.Retryable error.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	call := func() error {
+		if err := wait(ctx, method); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	if !idempotent(method) {
+		return call()
+	}
+	return retry(ctx, call)
+}
+
+// DialOption installs the rate limiter on a gRPC client connection.
+func DialOption() grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(UnaryClientInterceptor)
+}
+
+// Transport rate limits the requests of the REST clients of api, one of the
+// config.API constants, sharing a limiter per API like gRPC calls do, and
+// retries the GET and HEAD requests answered with an apierrors.Retryable
+// status.
+func Transport(api string, base http.RoundTripper) http.RoundTripper {
+	return transport{api: api, base: base}
+}
+
+type transport struct {
+	api  string
+	base http.RoundTripper
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		if err := wait(ctx, t.api); err != nil {
+			return nil, err
+		}
+		return t.base.RoundTrip(req)
+	}
+	var resp *http.Response
+	err := retry(ctx, func() error {
+		if resp != nil {
+			resp.Body.Close()
+			resp = nil
+		}
+		if err := wait(ctx, t.api); err != nil {
+			return err
+		}
+		var err error
+		if resp, err = t.base.RoundTrip(req); err != nil {
+			return err
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			return &googleapi.Error{Code: resp.StatusCode}
+		}
+		return nil
+	})
+	// A response with an error status is returned as is once retries give
+	// up, for the client to decode.
+	var statusErr *googleapi.Error
+	if err == nil || errors.As(err, &statusErr) {
+		return resp, nil
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return nil, err
+}
+
+// Middleware tracks throttling during a tool call and reports it in the
+// tool result.
+func Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		stats := &Stats{}
+		result, err := next(context.WithValue(ctx, statsKey{}, stats), request)
+		if result == nil {
+			return result, err
+		}
+		if note := stats.note(); note != "" {
+			result.Content = append(result.Content, mcp.NewTextContent(note))
+		}
+		return result, err
+	}
+}
+
+func (s *Stats) note() string {
+	waits, retries := s.waits.Load(), s.retries.Load()
+	if waits == 0 && retries == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Note: GCP API calls were throttled to stay within quota (%d delayed by the client-side rate limiter, %d retried after quota or availability errors). Narrow the scope of large scans to make them faster.", waits, retries)
+}
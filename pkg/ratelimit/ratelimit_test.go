@@ -0,0 +1,202 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/apierrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryClientInterceptorRetries(t *testing.T) {
+	apierrors.SetBackoff(time.Millisecond)
+	defer apierrors.SetBackoff(time.Second)
+
+	tests := []struct {
+		name        string
+		method      string
+		errs        []error
+		wantCalls   int
+		wantCode    codes.Code
+		wantRetries int64
+	}{
+		{
+			name:      "success",
+			method:    "/google.container.v1.ClusterManager/GetCluster",
+			errs:      []error{nil},
+			wantCalls: 1,
+			wantCode:  codes.OK,
+		},
+		{
+			name:        "quota exceeded then success",
+			method:      "/google.container.v1.ClusterManager/ListClusters",
+			errs:        []error{status.Error(codes.ResourceExhausted, "quota"), status.Error(codes.Unavailable, "unavailable"), nil},
+			wantCalls:   3,
+			wantCode:    codes.OK,
+			wantRetries: 2,
+		},
+		{
+			name:      "permission denied is not retried",
+			method:    "/google.container.v1.ClusterManager/GetCluster",
+			errs:      []error{status.Error(codes.PermissionDenied, "denied")},
+			wantCalls: 1,
+			wantCode:  codes.PermissionDenied,
+		},
+		{
+			name:        "gives up after max attempts",
+			method:      "/google.container.v1.ClusterManager/GetCluster",
+			errs:        []error{status.Error(codes.ResourceExhausted, "quota"), status.Error(codes.ResourceExhausted, "quota"), status.Error(codes.ResourceExhausted, "quota"), status.Error(codes.ResourceExhausted, "quota")},
+			wantCalls:   4,
+			wantCode:    codes.ResourceExhausted,
+			wantRetries: 3,
+		},
+		{
+			name:      "mutation is not retried",
+			method:    "/google.container.v1.ClusterManager/CreateNodePool",
+			errs:      []error{status.Error(codes.Unavailable, "unavailable")},
+			wantCalls: 1,
+			wantCode:  codes.Unavailable,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stats := &Stats{}
+			ctx := context.WithValue(context.Background(), statsKey{}, stats)
+			calls := 0
+			invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				err := tc.errs[calls]
+				calls++
+				return err
+			}
+			err := UnaryClientInterceptor(ctx, tc.method, nil, nil, nil, invoker)
+			if got := status.Code(err); got != tc.wantCode {
+				t.Errorf("UnaryClientInterceptor() code = %v, want %v", got, tc.wantCode)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("invoker called %d times, want %d", calls, tc.wantCalls)
+			}
+			if got := stats.retries.Load(); got != tc.wantRetries {
+				t.Errorf("retries = %d, want %d", got, tc.wantRetries)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	SetLimit("test-api", Limit{PerSecond: 10, Burst: 1})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	stats := &Stats{}
+	ctx := context.WithValue(context.Background(), statsKey{}, stats)
+	client := &http.Client{Transport: Transport("test-api", http.DefaultTransport)}
+	for range 2 {
+		req, err := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if got := stats.waits.Load(); got != 1 {
+		t.Errorf("waits = %d, want 1", got)
+	}
+}
+
+func TestTransportRetries(t *testing.T) {
+	apierrors.SetBackoff(time.Millisecond)
+	defer apierrors.SetBackoff(time.Second)
+
+	tests := []struct {
+		name        string
+		method      string
+		statuses    []int
+		wantCalls   int
+		wantStatus  int
+		wantRetries int64
+	}{
+		{
+			name:        "rate limited then success",
+			method:      http.MethodGet,
+			statuses:    []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK},
+			wantCalls:   3,
+			wantStatus:  http.StatusOK,
+			wantRetries: 2,
+		},
+		{
+			name:       "not found is not retried",
+			method:     http.MethodGet,
+			statuses:   []int{http.StatusNotFound},
+			wantCalls:  1,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:        "gives up after max attempts",
+			method:      http.MethodGet,
+			statuses:    []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			wantCalls:   4,
+			wantStatus:  http.StatusServiceUnavailable,
+			wantRetries: 3,
+		},
+		{
+			name:       "mutation is not retried",
+			method:     http.MethodPost,
+			statuses:   []int{http.StatusServiceUnavailable},
+			wantCalls:  1,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.statuses[calls])
+				calls++
+			}))
+			defer srv.Close()
+
+			stats := &Stats{}
+			ctx := context.WithValue(context.Background(), statsKey{}, stats)
+			req, err := http.NewRequestWithContext(ctx, tc.method, srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := Transport("retry-api", http.DefaultTransport).RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip() failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("RoundTrip() status = %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("server called %d times, want %d", calls, tc.wantCalls)
+			}
+			if got := stats.retries.Load(); got != tc.wantRetries {
+				t.Errorf("retries = %d, want %d", got, tc.wantRetries)
+			}
+		})
+	}
+}
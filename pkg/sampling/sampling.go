@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sampling asks the client's model to summarize tool results that
+// are too large to return, through MCP sampling.
+package sampling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/clientrequest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Method is the method of sampling requests.
+const Method = "sampling/createMessage"
+
+const (
+	// summaryTokens is the maximum size of a summary.
+	summaryTokens = 2000
+	// maxInputBytes is the size above which results are truncated before
+	// they are sent to the model, about 100k tokens.
+	maxInputBytes = 400000
+)
+
+// genericPrompt is the system prompt used to summarize the results of tools
+// without a prompt of their own.
+const genericPrompt = `You summarize the output of a Google Kubernetes Engine (GKE) tool for another AI agent helping an engineer. Keep everything needed to act on it: counts, the names of affected clusters, node pools, workloads and resources, and anything failing, degraded or unusual. Quote exact names and messages. Don't speculate beyond the data, and don't add advice. Be concise.`
+
+// logsPrompt is the system prompt used to summarize log entries.
+const logsPrompt = `You summarize Cloud Logging entries from a Google Kubernetes Engine (GKE) environment for another AI agent troubleshooting it. Report the time range covered, the most frequent messages with their counts, every distinct error and warning with the workloads, pods, nodes or clusters it affects and when it first and last occurred, and any sequence of events that looks like the cause of an incident. Quote exact messages. Don't speculate beyond the entries.`
+
+// prompts are the system prompts used to summarize the results of specific
+// tools.
+var prompts = map[string]string{
+	"query_logs":           logsPrompt,
+	"tail_logs":            logsPrompt,
+	"list_recommendations": `You summarize recommendations, insights and security findings for Google Kubernetes Engine (GKE) clusters for another AI agent. Group them by priority or severity, highest first. For each group, list the affected clusters and resources and what should be done, and call out critical vulnerabilities and findings that can be fixed automatically. Keep exact identifiers, e.g. CVEs and recommendation names. Don't speculate beyond the data.`,
+}
+
+// Sampler sends sampling requests to the client.
+type Sampler struct {
+	t *clientrequest.Transport
+}
+
+// Default is the sampler used by the server.
+var Default = New(clientrequest.Default)
+
+// New returns a sampler sending its requests over t.
+func New(t *clientrequest.Transport) *Sampler {
+	return &Sampler{t: t}
+}
+
+// Supported reports whether the client can answer sampling requests.
+func (s *Sampler) Supported() bool {
+	return s.t.Supports("sampling")
+}
+
+// message is a message of a sampling request or response.
+type message struct {
+	Role    string `json:"role"`
+	Content struct {
+		Type string `json:"type"`
+		Text string `json:"text,omitempty"`
+	} `json:"content"`
+}
+
+// CreateMessage asks the client's model to respond to prompt, with the
+// given system prompt, in at most maxTokens tokens.
+func (s *Sampler) CreateMessage(ctx context.Context, systemPrompt, prompt string, maxTokens int) (string, error) {
+	if !s.Supported() {
+		return "", fmt.Errorf("the client doesn't support sampling")
+	}
+	request := message{Role: string(mcp.RoleUser)}
+	request.Content.Type = "text"
+	request.Content.Text = prompt
+	result, err := s.t.Request(ctx, Method, map[string]any{
+		"messages":       []message{request},
+		"systemPrompt":   systemPrompt,
+		"includeContext": "none",
+		"maxTokens":      maxTokens,
+		"modelPreferences": map[string]any{
+			"speedPriority":        0.8,
+			"costPriority":         0.5,
+			"intelligencePriority": 0.3,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	var response message
+	if err := json.Unmarshal(result, &response); err != nil {
+		return "", fmt.Errorf("invalid sampling response: %w", err)
+	}
+	if response.Content.Type != "text" || response.Content.Text == "" {
+		return "", fmt.Errorf("the client's model didn't respond with text")
+	}
+	return response.Content.Text, nil
+}
+
+// Summarize asks the client's model to summarize text, the result of a call
+// of tool, with a prompt for that tool. It is a governor.Summarizer.
+func (s *Sampler) Summarize(ctx context.Context, tool, text string) (string, error) {
+	systemPrompt, ok := prompts[tool]
+	if !ok {
+		systemPrompt = genericPrompt
+	}
+	prompt := fmt.Sprintf("Summarize this output of the %s tool.\n\n", tool)
+	if len(text) > maxInputBytes {
+		prompt = fmt.Sprintf("Summarize this output of the %s tool. It was truncated to its first %d bytes out of %d, so mention that the summary is partial.\n\n", tool, maxInputBytes, len(text))
+		text = text[:maxInputBytes]
+	}
+	return s.CreateMessage(ctx, systemPrompt, prompt+text, summaryTokens)
+}
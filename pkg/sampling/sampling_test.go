@@ -0,0 +1,94 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/clientrequest"
+)
+
+// connect intercepts a stdio transport whose client declared capabilities,
+// and returns the writer of the client's messages and the reader of the
+// server's.
+func connect(t *testing.T, tr *clientrequest.Transport, capabilities string) (io.Writer, *bufio.Reader) {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	t.Cleanup(func() {
+		inW.Close()
+		outR.Close()
+	})
+	forwarded, _ := tr.InterceptStdio(inR, outW)
+	fmt.Fprintf(inW, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":%s}}`+"\n", capabilities)
+	if _, err := bufio.NewReader(forwarded).ReadBytes('\n'); err != nil {
+		t.Fatalf("the initialize request wasn't forwarded: %v", err)
+	}
+	return inW, bufio.NewReader(outR)
+}
+
+func TestSummarize(t *testing.T) {
+	tr := clientrequest.New()
+	in, out := connect(t, tr, `{"sampling":{}}`)
+	s := New(tr)
+
+	requests := make(chan map[string]any, 1)
+	go func() {
+		line, err := out.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var request struct {
+			ID     string         `json:"id"`
+			Method string         `json:"method"`
+			Params map[string]any `json:"params"`
+		}
+		json.Unmarshal(line, &request)
+		if request.Method != Method {
+			t.Errorf("got a %s request, want %s", request.Method, Method)
+		}
+		requests <- request.Params
+		fmt.Fprintf(in, `{"jsonrpc":"2.0","id":%q,"result":{"role":"assistant","content":{"type":"text","text":"3 errors in pod web-1"},"model":"m"}}`+"\n", request.ID)
+	}()
+
+	got, err := s.Summarize(context.Background(), "query_logs", "ERROR web-1 crashed\n")
+	if err != nil {
+		t.Fatalf("Summarize() failed: %v", err)
+	}
+	if want := "3 errors in pod web-1"; got != want {
+		t.Errorf("Summarize() = %q, want %q", got, want)
+	}
+	params := <-requests
+	if params["systemPrompt"] != prompts["query_logs"] {
+		t.Errorf("systemPrompt = %q, want the query_logs prompt", params["systemPrompt"])
+	}
+	if b, _ := json.Marshal(params["messages"]); !strings.Contains(string(b), "web-1 crashed") {
+		t.Errorf("messages = %s, want them to contain the result", b)
+	}
+}
+
+func TestSummarizeUnsupported(t *testing.T) {
+	tr := clientrequest.New()
+	connect(t, tr, `{}`)
+	if _, err := New(tr).Summarize(context.Background(), "query_logs", "text"); err == nil {
+		t.Errorf("Summarize() succeeded with a client that doesn't support sampling")
+	}
+}
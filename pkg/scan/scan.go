@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scan fans a query out over many targets, such as projects or
+// locations, with bounded concurrency.
+package scan
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultWorkers is the number of targets scanned concurrently when the
+// caller doesn't choose.
+const DefaultWorkers = 8
+
+// Result is the outcome of scanning a single target.
+type Result[T, R any] struct {
+	Target T
+	Value  R
+	Err    error
+}
+
+// Run calls fn for each target using at most workers concurrent calls, and
+// returns the results in the order of targets. A failing target doesn't stop
+// the others; its error is reported in its result. Targets not yet started
+// when ctx is done fail with the context's error.
+func Run[T, R any](ctx context.Context, targets []T, workers int, fn func(context.Context, T) (R, error)) []Result[T, R] {
+	results := make([]Result[T, R], len(targets))
+	run(ctx, targets, workers, fn, func(i int, r Result[T, R]) { results[i] = r })
+	return results
+}
+
+// Each is like Run, but passes each result to emit as soon as its target is
+// scanned instead of keeping them all, e.g. to stream them. The calls of emit
+// don't overlap.
+func Each[T, R any](ctx context.Context, targets []T, workers int, fn func(context.Context, T) (R, error), emit func(Result[T, R])) {
+	var mu sync.Mutex
+	run(ctx, targets, workers, fn, func(_ int, r Result[T, R]) {
+		mu.Lock()
+		defer mu.Unlock()
+		emit(r)
+	})
+}
+
+// run calls fn for each target using at most workers concurrent calls, and
+// done with the index and result of each target.
+func run[T, R any](ctx context.Context, targets []T, workers int, fn func(context.Context, T) (R, error), done func(int, Result[T, R])) {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			done(i, Result[T, R]{Target: target, Err: ctx.Err()})
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			r := Result[T, R]{Target: target}
+			r.Value, r.Err = fn(ctx, target)
+			done(i, r)
+		}()
+	}
+	wg.Wait()
+}
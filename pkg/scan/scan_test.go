@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunKeepsOrder(t *testing.T) {
+	targets := []int{5, 1, 4, 2, 3}
+	results := Run(context.Background(), targets, 2, func(_ context.Context, n int) (string, error) {
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		if n == 4 {
+			return "", errors.New("boom")
+		}
+		return fmt.Sprint(n * 10), nil
+	})
+
+	var got []string
+	for _, r := range results {
+		if r.Err != nil {
+			got = append(got, fmt.Sprintf("%d:%v", r.Target, r.Err))
+			continue
+		}
+		got = append(got, fmt.Sprintf("%d:%s", r.Target, r.Value))
+	}
+	want := []string{"5:50", "1:10", "4:boom", "2:20", "3:30"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Run() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	Run(context.Background(), make([]int, 20), 3, func(context.Context, int) (struct{}, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return struct{}{}, nil
+	})
+	if got := peak.Load(); got > 3 {
+		t.Errorf("Run() ran %d calls concurrently, want at most 3", got)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := Run(ctx, []int{1, 2, 3}, 1, func(ctx context.Context, n int) (int, error) {
+		return n, ctx.Err()
+	})
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("Run() target %d error = %v, want %v", r.Target, r.Err, context.Canceled)
+		}
+	}
+}
+
+func TestEachEmitsAsTargetsAreScanned(t *testing.T) {
+	var got []int
+	Each(context.Background(), []int{30, 1, 10}, 3, func(_ context.Context, n int) (int, error) {
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		return n, nil
+	}, func(r Result[int, int]) {
+		got = append(got, r.Value)
+	})
+	if diff := cmp.Diff([]int{1, 10, 30}, got); diff != "" {
+		t.Errorf("Each() emitted results in the wrong order (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package selector implements Kubernetes-style label selectors such as
+// "env=prod,team!=payments,critical", used to target a slice of the fleet.
+package selector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Argument is the name of the tool argument carrying a label selector.
+const Argument = "label_selector"
+
+// Option declares the label selector argument on a tool.
+func Option() mcp.ToolOption {
+	return mcp.WithString(Argument, mcp.Description("Only include clusters whose labels match this selector: comma-separated requirements that must all hold, each one of key=value, key!=value, key (label is set) or !key (label is not set). Example: env=prod,team=payments."))
+}
+
+type operator int
+
+const (
+	equals operator = iota
+	notEquals
+	exists
+	notExists
+)
+
+type requirement struct {
+	key   string
+	op    operator
+	value string
+}
+
+// Selector matches label sets. The zero Selector matches everything.
+type Selector struct {
+	requirements []requirement
+}
+
+// Parse parses a selector. An empty string selects everything.
+func Parse(s string) (Selector, error) {
+	var sel Selector
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var r requirement
+		switch {
+		case strings.Contains(part, "!="):
+			r.key, r.value, _ = strings.Cut(part, "!=")
+			r.op = notEquals
+		case strings.Contains(part, "=="):
+			r.key, r.value, _ = strings.Cut(part, "==")
+		case strings.Contains(part, "="):
+			r.key, r.value, _ = strings.Cut(part, "=")
+		case strings.HasPrefix(part, "!"):
+			r.key = strings.TrimPrefix(part, "!")
+			r.op = notExists
+		default:
+			r.key = part
+			r.op = exists
+		}
+		r.key, r.value = strings.TrimSpace(r.key), strings.TrimSpace(r.value)
+		if r.key == "" || strings.ContainsAny(r.key, "=! ") {
+			return Selector{}, fmt.Errorf("invalid label selector requirement %q", part)
+		}
+		sel.requirements = append(sel.requirements, r)
+	}
+	return sel, nil
+}
+
+// Empty reports whether the selector matches everything.
+func (s Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// Matches reports whether labels satisfy every requirement of the selector.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s.requirements {
+		value, ok := labels[r.key]
+		switch r.op {
+		case equals:
+			if !ok || value != r.value {
+				return false
+			}
+		case notEquals:
+			if ok && value == r.value {
+				return false
+			}
+		case exists:
+			if !ok {
+				return false
+			}
+		case notExists:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import "testing"
+
+func TestMatches(t *testing.T) {
+	labels := map[string]string{"env": "prod", "team": "payments"}
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"env=prod", true},
+		{"env==prod", true},
+		{"env=prod,team=payments", true},
+		{"env=prod, team=search", false},
+		{"env!=dev", true},
+		{"env!=prod", false},
+		{"missing!=x", true},
+		{"team", true},
+		{"critical", false},
+		{"!critical", true},
+		{"!env", false},
+	}
+	for _, tc := range tests {
+		sel, err := Parse(tc.selector)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tc.selector, err)
+		}
+		if got := sel.Matches(labels); got != tc.want {
+			t.Errorf("Parse(%q).Matches(%v) = %v, want %v", tc.selector, labels, got, tc.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{"=prod", "!", "env prod=x", "!=x"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", s)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package session keeps the working context of each MCP session: the
+// project, location and cluster the user is working with, so tools can
+// default to them instead of asking for them on every call.
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Context is the working context of a session.
+type Context struct {
+	ProjectID string `json:"project_id,omitempty"`
+	Location  string `json:"location,omitempty"`
+	Cluster   string `json:"cluster,omitempty"`
+}
+
+// Store holds the working context of every session.
+type Store struct {
+	mu       sync.Mutex
+	contexts map[string]Context
+	// initial is the context of sessions that haven't set their own.
+	initial Context
+}
+
+// Default is the store used by the server and its tools.
+var Default = NewStore()
+
+func NewStore() *Store {
+	return &Store{contexts: map[string]Context{}}
+}
+
+// sessionID returns the ID of the MCP session of ctx, or "" outside of one.
+func sessionID(ctx context.Context) string {
+	if s := server.ClientSessionFromContext(ctx); s != nil {
+		return s.SessionID()
+	}
+	return ""
+}
+
+// Get returns the working context of the session of ctx.
+func (s *Store) Get(ctx context.Context) Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.contexts[sessionID(ctx)]; ok {
+		return c
+	}
+	return s.initial
+}
+
+// Set replaces the working context of the session of ctx.
+func (s *Store) Set(ctx context.Context, c Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.contexts[sessionID(ctx)] = c
+}
+
+// Forget drops the working context of a session, e.g. once it has ended.
+func (s *Store) Forget(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.contexts, sessionID)
+}
+
+// UnregisterHook forgets the working context of sessions that end.
+func (s *Store) UnregisterHook(_ context.Context, session server.ClientSession) {
+	s.Forget(session.SessionID())
+}
+
+// SetInitial makes c the initial context of new sessions. It is persisted
+// across restarts by the state store.
+func (s *Store) SetInitial(c Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.initial = c
+}
+
+// Snapshot returns the initial context to persist, or nil if it is empty.
+func (s *Store) Snapshot() (json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.initial == (Context{}) {
+		return nil, nil
+	}
+	return json.Marshal(s.initial)
+}
+
+// Restore replaces the initial context with a snapshot.
+func (s *Store) Restore(data json.RawMessage) error {
+	var c Context
+	if data != nil {
+		if err := json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+	}
+	s.SetInitial(c)
+	return nil
+}
+
+// ProjectID returns the project_id argument of request, falling back to the
+// session's project and then to the configured default.
+func ProjectID(ctx context.Context, request mcp.CallToolRequest, c *config.Config) string {
+	if projectID := request.GetString("project_id", ""); projectID != "" {
+		return projectID
+	}
+	if projectID := Default.Get(ctx).ProjectID; projectID != "" {
+		return projectID
+	}
+	return c.DefaultProjectID()
+}
+
+// Location returns the location argument of request, falling back to the
+// session's location and then to the configured default.
+func Location(ctx context.Context, request mcp.CallToolRequest, c *config.Config) string {
+	if location := request.GetString("location", ""); location != "" {
+		return location
+	}
+	if location := Default.Get(ctx).Location; location != "" {
+		return location
+	}
+	return c.DefaultLocation()
+}
+
+// Cluster returns the cluster name in the argument arg of request, falling
+// back to the session's cluster and then to the configured default.
+func Cluster(ctx context.Context, request mcp.CallToolRequest, c *config.Config, arg string) string {
+	if cluster := request.GetString(arg, ""); cluster != "" {
+		return cluster
+	}
+	if cluster := Default.Get(ctx).Cluster; cluster != "" {
+		return cluster
+	}
+	return c.DefaultCluster()
+}
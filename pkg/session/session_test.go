@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	want := Context{ProjectID: "p", Location: "us-central1", Cluster: "c"}
+	saved := NewStore()
+	saved.SetInitial(want)
+	data, err := saved.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() failed: %v", err)
+	}
+
+	s := NewStore()
+	if err := s.Restore(data); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	if diff := cmp.Diff(want, s.Get(ctx)); diff != "" {
+		t.Errorf("Get() after Restore() mismatch (-want +got):\n%s", diff)
+	}
+
+	// A session's own context takes precedence over the initial one.
+	s.Set(ctx, Context{ProjectID: "other"})
+	if diff := cmp.Diff(Context{ProjectID: "other"}, s.Get(ctx)); diff != "" {
+		t.Errorf("Get() after Set() mismatch (-want +got):\n%s", diff)
+	}
+	s.Forget("")
+	if diff := cmp.Diff(want, s.Get(ctx)); diff != "" {
+		t.Errorf("Get() after Forget() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProjectID(t *testing.T) {
+	ctx := context.Background()
+	c := config.New("test", config.WithDefaultProjectID("configured"))
+	t.Cleanup(func() { Default.Forget("") })
+
+	request := mcp.CallToolRequest{}
+	if got := ProjectID(ctx, request, c); got != "configured" {
+		t.Errorf("ProjectID() without context = %q, want %q", got, "configured")
+	}
+
+	Default.Set(ctx, Context{ProjectID: "pinned"})
+	if got := ProjectID(ctx, request, c); got != "pinned" {
+		t.Errorf("ProjectID() with context = %q, want %q", got, "pinned")
+	}
+
+	request.Params.Arguments = map[string]any{"project_id": "explicit"}
+	if got := ProjectID(ctx, request, c); got != "explicit" {
+		t.Errorf("ProjectID() with argument = %q, want %q", got, "explicit")
+	}
+}
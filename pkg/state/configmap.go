@@ -0,0 +1,169 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	// configMapKey is the ConfigMap data key holding the state document.
+	configMapKey = "state.json"
+	// DefaultConfigMapName is the name of the ConfigMap used by default.
+	DefaultConfigMapName = "gke-mcp-state"
+)
+
+// ConfigMap is a Backend keeping the state in a Kubernetes ConfigMap of the
+// cluster the server runs in, for hosted deployments without a persistent
+// disk. The server's Kubernetes service account needs get, create, update
+// and delete permissions on the ConfigMap.
+type ConfigMap struct {
+	namespace, name string
+	host            string
+	client          *http.Client
+}
+
+// InClusterConfigMap returns a ConfigMap backend using the in-cluster
+// service account. An empty namespace means the server's own namespace.
+func InClusterConfigMap(namespace, name string) (*ConfigMap, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+	if namespace == "" {
+		data, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("could not determine the server's namespace: %w", err)
+		}
+		namespace = strings.TrimSpace(string(data))
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("could not read the cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("invalid cluster CA in %s/ca.crt", serviceAccountDir)
+	}
+	return &ConfigMap{
+		namespace: namespace,
+		name:      name,
+		host:      "https://" + net.JoinHostPort(host, port),
+		client: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		}},
+	}, nil
+}
+
+type configMap struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   map[string]any    `json:"metadata"`
+	Data       map[string]string `json:"data"`
+}
+
+func (c *ConfigMap) Read(ctx context.Context) ([]byte, error) {
+	var cm configMap
+	status, err := c.do(ctx, http.MethodGet, c.path(), nil, &cm)
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if data, ok := cm.Data[configMapKey]; ok {
+		return []byte(data), nil
+	}
+	return nil, nil
+}
+
+func (c *ConfigMap) Write(ctx context.Context, data []byte) error {
+	if data == nil {
+		status, err := c.do(ctx, http.MethodDelete, c.path(), nil, nil)
+		if status == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	cm := configMap{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Metadata:   map[string]any{"name": c.name, "namespace": c.namespace},
+		Data:       map[string]string{configMapKey: string(data)},
+	}
+	status, err := c.do(ctx, http.MethodPut, c.path(), cm, nil)
+	if status == http.StatusNotFound {
+		_, err = c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v1/namespaces/%s/configmaps", c.namespace), cm, nil)
+	}
+	return err
+}
+
+func (c *ConfigMap) String() string {
+	return fmt.Sprintf("configmap %s/%s", c.namespace, c.name)
+}
+
+func (c *ConfigMap) path() string {
+	return fmt.Sprintf("/api/v1/namespaces/%s/configmaps/%s", c.namespace, c.name)
+}
+
+// do sends a request to the Kubernetes API and decodes the response into out,
+// if not nil. It returns the response status, or 0 if there was none.
+func (c *ConfigMap) do(ctx context.Context, method, path string, in, out any) (int, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.host+path, body)
+	if err != nil {
+		return 0, err
+	}
+	// Projected service account tokens are rotated, so read it every time.
+	token, err := os.ReadFile(serviceAccountDir + "/token")
+	if err != nil {
+		return 0, fmt.Errorf("could not read service account token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, msg)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, err
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package state persists server state between restarts: the session context
+// saved with save_context, the long-running operations still being tracked
+// and cached cluster inventory.
+//
+// Each kind of state is a Component registered under a name. The Store
+// snapshots every component into one JSON document kept by a Backend: a
+// local file, or a ConfigMap when the server runs in a cluster.
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Component is a part of the server state that is persisted.
+type Component interface {
+	// Snapshot returns the state to persist, or nil if there is none.
+	Snapshot() (json.RawMessage, error)
+	// Restore replaces the state with a snapshot; nil clears it.
+	Restore(data json.RawMessage) error
+}
+
+// Keyed is implemented by components whose state is made of entries under
+// keys, like caches, so it can be snapshotted selectively.
+type Keyed interface {
+	Component
+	// SnapshotMatching is like Snapshot, but only keeps the entries whose
+	// key matches.
+	SnapshotMatching(match func(key string) bool) (json.RawMessage, error)
+}
+
+// Backend stores the persisted state document.
+type Backend interface {
+	// Read returns the stored document, or nil if there is none.
+	Read(ctx context.Context) ([]byte, error)
+	// Write replaces the stored document; nil deletes it.
+	Write(ctx context.Context, data []byte) error
+	// String describes where the state is stored.
+	String() string
+}
+
+// Store persists the state of its registered components.
+type Store struct {
+	mu         sync.Mutex
+	backend    Backend
+	components map[string]Component
+	// pending holds loaded snapshots of components not registered yet.
+	pending map[string]json.RawMessage
+}
+
+// Default is the store used by the server.
+var Default = NewStore()
+
+func NewStore() *Store {
+	return &Store{
+		components: map[string]Component{},
+		pending:    map[string]json.RawMessage{},
+	}
+}
+
+// SetBackend sets where the state is persisted. A nil backend disables
+// persistence.
+func (s *Store) SetBackend(b Backend) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backend = b
+}
+
+// Backend returns where the state is persisted, or nil if it isn't.
+func (s *Store) Backend() Backend {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend
+}
+
+// Register adds a component under name. If state for it was already loaded,
+// the component is restored from it right away.
+func (s *Store) Register(name string, c Component) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.components[name] = c
+	if data, ok := s.pending[name]; ok {
+		delete(s.pending, name)
+		if err := c.Restore(data); err != nil {
+			return fmt.Errorf("could not restore %s state: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Load reads the persisted state and restores the registered components.
+// State of components registered later is restored when they register.
+func (s *Store) Load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.backend == nil {
+		return nil
+	}
+	data, err := s.backend.Read(ctx)
+	if err != nil || data == nil {
+		return err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("could not parse state from %s: %w", s.backend, err)
+	}
+	var errs []error
+	for name, snapshot := range doc {
+		c, ok := s.components[name]
+		if !ok {
+			s.pending[name] = snapshot
+			continue
+		}
+		if err := c.Restore(snapshot); err != nil {
+			errs = append(errs, fmt.Errorf("could not restore %s state: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Snapshot returns the current state of every registered component, keyed
+// by name. Components without state are left out.
+func (s *Store) Snapshot() (map[string]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+func (s *Store) snapshot() (map[string]json.RawMessage, error) {
+	doc := map[string]json.RawMessage{}
+	for _, name := range slices.Sorted(maps.Keys(s.components)) {
+		data, err := s.components[name].Snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("could not snapshot %s state: %w", name, err)
+		}
+		if data != nil {
+			doc[name] = data
+		}
+	}
+	return doc, nil
+}
+
+// SnapshotMatching is like Snapshot, but only keeps the entries of Keyed
+// components whose key matches. Other components are left out unless
+// unkeyed is true.
+func (s *Store) SnapshotMatching(match func(key string) bool, unkeyed bool) (map[string]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc := map[string]json.RawMessage{}
+	for _, name := range slices.Sorted(maps.Keys(s.components)) {
+		var data json.RawMessage
+		var err error
+		if k, ok := s.components[name].(Keyed); ok {
+			data, err = k.SnapshotMatching(match)
+		} else if unkeyed {
+			data, err = s.components[name].Snapshot()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not snapshot %s state: %w", name, err)
+		}
+		if data != nil {
+			doc[name] = data
+		}
+	}
+	return doc, nil
+}
+
+// Save persists the current state of every registered component.
+func (s *Store) Save(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.backend == nil {
+		return nil
+	}
+	doc, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+	if len(doc) == 0 {
+		return s.backend.Write(ctx, nil)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal state: %w", err)
+	}
+	return s.backend.Write(ctx, data)
+}
+
+// Clear drops the state of every component, in memory and persisted.
+func (s *Store) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, c := range s.components {
+		if err := c.Restore(nil); err != nil {
+			return fmt.Errorf("could not clear %s state: %w", name, err)
+		}
+	}
+	clear(s.pending)
+	if s.backend == nil {
+		return nil
+	}
+	return s.backend.Write(ctx, nil)
+}
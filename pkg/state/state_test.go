@@ -0,0 +1,177 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// value is a Component holding a single string.
+type value struct {
+	s string
+}
+
+func (v *value) Snapshot() (json.RawMessage, error) {
+	if v.s == "" {
+		return nil, nil
+	}
+	return json.Marshal(v.s)
+}
+
+func (v *value) Restore(data json.RawMessage) error {
+	v.s = ""
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &v.s)
+}
+
+func TestSaveLoad(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "gke-mcp", "state.json")
+
+	s := NewStore()
+	s.SetBackend(File(path))
+	a, b := &value{s: "a"}, &value{}
+	if err := s.Register("a", a); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Register("b", b); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(ctx); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	// A component registered after the state is loaded is restored too.
+	restored := NewStore()
+	restored.SetBackend(File(path))
+	early := &value{}
+	if err := restored.Register("b", early); err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.Load(ctx); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	late := &value{}
+	if err := restored.Register("a", late); err != nil {
+		t.Fatal(err)
+	}
+	if late.s != "a" || early.s != "" {
+		t.Errorf("restored components = %q, %q; want \"a\", \"\"", late.s, early.s)
+	}
+
+	snapshot, err := restored.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() failed: %v", err)
+	}
+	want := map[string]json.RawMessage{"a": json.RawMessage(`"a"`)}
+	if diff := cmp.Diff(want, snapshot); diff != "" {
+		t.Errorf("Snapshot() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := restored.Clear(ctx); err != nil {
+		t.Fatalf("Clear() failed: %v", err)
+	}
+	if late.s != "" {
+		t.Errorf("component after Clear() = %q, want it cleared", late.s)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("state file after Clear(): %v, want it removed", err)
+	}
+}
+
+// entries is a Keyed component holding strings under keys.
+type entries map[string]string
+
+func (e entries) Snapshot() (json.RawMessage, error) {
+	return e.SnapshotMatching(func(string) bool { return true })
+}
+
+func (e entries) SnapshotMatching(match func(key string) bool) (json.RawMessage, error) {
+	kept := map[string]string{}
+	for k, v := range e {
+		if match(k) {
+			kept[k] = v
+		}
+	}
+	if len(kept) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(kept)
+}
+
+func (e entries) Restore(json.RawMessage) error { return nil }
+
+func TestSnapshotMatching(t *testing.T) {
+	s := NewStore()
+	if err := s.Register("cache", entries{"a|alice": "1", "b|bob": "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Register("other", &value{s: "shared"}); err != nil {
+		t.Fatal(err)
+	}
+	alice := func(key string) bool { return key == "a|alice" }
+
+	for _, tc := range []struct {
+		name    string
+		unkeyed bool
+		want    map[string]json.RawMessage
+	}{
+		{
+			name: "keyed only",
+			want: map[string]json.RawMessage{"cache": json.RawMessage(`{"a|alice":"1"}`)},
+		},
+		{
+			name:    "with unkeyed",
+			unkeyed: true,
+			want: map[string]json.RawMessage{
+				"cache": json.RawMessage(`{"a|alice":"1"}`),
+				"other": json.RawMessage(`"shared"`),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.SnapshotMatching(alice, tc.unkeyed)
+			if err != nil {
+				t.Fatalf("SnapshotMatching() failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("SnapshotMatching() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadWithoutState(t *testing.T) {
+	s := NewStore()
+	s.SetBackend(File(filepath.Join(t.TempDir(), "state.json")))
+	v := &value{s: "unchanged"}
+	if err := s.Register("v", v); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Load(context.Background()); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if v.s != "unchanged" {
+		t.Errorf("component = %q after loading a missing file, want it unchanged", v.s)
+	}
+}
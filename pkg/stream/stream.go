@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stream sends the partial results of tools producing a lot of
+// output, such as log queries, log tails and fleet scans, to the client while
+// they are read, instead of buffering them until the tool returns. This
+// bounds the memory of the server to a chunk of the result.
+//
+// Partial results are progress notifications whose message is a chunk of the
+// result's text, so streaming is opt-in: the call must set the stream
+// argument and a progress token.
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Argument is the name of the tool argument that asks for streaming.
+const Argument = "stream"
+
+var (
+	// chunkBytes is the size of the text sent in each notification.
+	chunkBytes = 16 << 10
+	// blockedRetry is how long to wait before sending a notification
+	// again when the client's notification queue is full.
+	blockedRetry = 50 * time.Millisecond
+)
+
+// Option declares the argument that asks for streaming on a tool that can
+// produce a lot of output.
+func Option() mcp.ToolOption {
+	return mcp.WithBoolean(Argument, mcp.Description("Send the results as progress notifications while they are read, instead of in the tool result, allowing much larger results. Only set this if progress notifications are shown to you."))
+}
+
+// Writer accumulates the text of a tool result, sending it to the client in
+// chunks if the call asked for streaming.
+type Writer struct {
+	ctx    context.Context
+	s      *server.MCPServer
+	token  mcp.ProgressToken
+	buf    strings.Builder
+	chunks int
+}
+
+// NewWriter returns the writer of the result of the tool call request. It
+// fails if the call asked for streaming without a progress token.
+func NewWriter(ctx context.Context, request mcp.CallToolRequest) (*Writer, error) {
+	w := &Writer{ctx: ctx}
+	if !request.GetBool(Argument, false) {
+		return w, nil
+	}
+	w.s = server.ServerFromContext(ctx)
+	if request.Params.Meta != nil {
+		w.token = request.Params.Meta.ProgressToken
+	}
+	if w.s == nil || w.token == nil {
+		return nil, fmt.Errorf("%s requires a progress token in the request metadata; call the tool again without %s", Argument, Argument)
+	}
+	return w, nil
+}
+
+// Streaming reports whether the text is sent to the client as it is written.
+func (w *Writer) Streaming() bool {
+	return w.token != nil
+}
+
+// Write appends text, which should be whole items such as log entries so
+// chunks don't split them, and sends the text written so far if it is large
+// enough. Sending blocks while the client's notification queue is full.
+func (w *Writer) Write(text string) error {
+	w.buf.WriteString(text)
+	if w.Streaming() && w.buf.Len() >= chunkBytes {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush sends the text written since the last notification, if streaming.
+func (w *Writer) Flush() error {
+	if !w.Streaming() || w.buf.Len() == 0 {
+		return nil
+	}
+	params := map[string]any{
+		"progressToken": w.token,
+		"progress":      w.chunks + 1,
+		"message":       w.buf.String(),
+	}
+	for {
+		err := w.s.SendNotificationToClient(w.ctx, "notifications/progress", params)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, server.ErrNotificationChannelBlocked) {
+			return fmt.Errorf("failed to stream the results: %w", err)
+		}
+		select {
+		case <-w.ctx.Done():
+			return context.Cause(w.ctx)
+		case <-time.After(blockedRetry):
+		}
+	}
+	w.chunks++
+	w.buf.Reset()
+	return nil
+}
+
+// String returns the text that wasn't sent: all of it unless streaming.
+func (w *Writer) String() string {
+	return w.buf.String()
+}
+
+// Chunks returns the number of notifications sent.
+func (w *Writer) Chunks() int {
+	return w.chunks
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type fakeSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *fakeSession) Initialize()                                         {}
+func (s *fakeSession) Initialized() bool                                   { return true }
+func (s *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *fakeSession) SessionID() string                                   { return "test" }
+
+// call calls a tool writing items through a Writer, with the given
+// arguments and progress token, and returns the text of its result.
+func call(t *testing.T, session *fakeSession, items []string, args map[string]any, token mcp.ProgressToken) string {
+	t.Helper()
+	s := server.NewMCPServer("test", "1")
+	s.AddTool(mcp.NewTool("items", Option()), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		w, err := NewWriter(ctx, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, item := range items {
+			if err := w.Write(item); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if w.Streaming() {
+			return mcp.NewToolResultText("streamed"), nil
+		}
+		return mcp.NewToolResultText(w.String()), nil
+	})
+	params := map[string]any{"name": "items", "arguments": args}
+	if token != nil {
+		params["_meta"] = map[string]any{"progressToken": token}
+	}
+	message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, ok := s.HandleMessage(s.WithContext(context.Background(), session), message).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("tools/call failed")
+	}
+	result := response.Result.(mcp.CallToolResult)
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+func TestWriter(t *testing.T) {
+	defer func(old int) { chunkBytes = old }(chunkBytes)
+	chunkBytes = 4
+	items := []string{"ab", "cd", "ef", "g"}
+
+	session := &fakeSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	if got := call(t, session, items, nil, "token"); got != "abcdefg" {
+		t.Errorf("result without streaming = %q, want %q", got, "abcdefg")
+	}
+	if n := len(session.notifications); n != 0 {
+		t.Errorf("%d notifications sent without streaming, want 0", n)
+	}
+
+	if got := call(t, session, items, map[string]any{Argument: true}, "token"); got != "streamed" {
+		t.Errorf("result with streaming = %q, want %q", got, "streamed")
+	}
+	close(session.notifications)
+	var messages []string
+	for n := range session.notifications {
+		if n.Method != "notifications/progress" || n.Params.AdditionalFields["progressToken"] != "token" {
+			t.Errorf("unexpected notification %+v", n)
+		}
+		messages = append(messages, n.Params.AdditionalFields["message"].(string))
+	}
+	if got, want := strings.Join(messages, "|"), "abcd|efg"; got != want {
+		t.Errorf("streamed chunks = %q, want %q", got, want)
+	}
+
+	if got := call(t, session, items, map[string]any{Argument: true}, nil); !strings.Contains(got, "progress token") {
+		t.Errorf("result with streaming and without a progress token = %q, want an error about the token", got)
+	}
+}
+
+func TestWriterWaitsForTheClient(t *testing.T) {
+	defer func(old int, retry time.Duration) { chunkBytes, blockedRetry = old, retry }(chunkBytes, blockedRetry)
+	chunkBytes, blockedRetry = 1, time.Millisecond
+
+	session := &fakeSession{notifications: make(chan mcp.JSONRPCNotification)}
+	received := make(chan string)
+	go func() {
+		var messages []string
+		for n := range session.notifications {
+			messages = append(messages, n.Params.AdditionalFields["message"].(string))
+		}
+		received <- strings.Join(messages, "")
+	}()
+	call(t, session, []string{"a", "b", "c"}, map[string]any{Argument: true}, 1)
+	close(session.notifications)
+	if got := <-received; got != "abc" {
+		t.Errorf("streamed text = %q, want %q", got, "abc")
+	}
+}
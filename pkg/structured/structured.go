@@ -0,0 +1,137 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package structured returns tool results in a machine-readable form next to
+// their text, following JSON schemas the server publishes as resources, so
+// automation and stricter clients can parse them reliably.
+//
+// The MCP protocol versions the server supports predate structuredContent
+// and output schemas, so the structured result is an embedded JSON resource
+// and the schema is named in the result metadata and the tool description.
+package structured
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Names of the schemas of structured results.
+const (
+	Cluster  = "cluster"
+	Clusters = "clusters"
+	Findings = "findings"
+	Costs    = "costs"
+)
+
+const (
+	schemaPrefix = "gke-mcp://schemas/"
+	resultPrefix = "gke-mcp://results/"
+	// SchemaMetaField is the field of the result metadata naming the schema
+	// of the structured result.
+	SchemaMetaField = "outputSchema"
+)
+
+//go:embed schemas/*.json
+var schemas embed.FS
+
+// SchemaURI returns the URI of the resource of a schema.
+func SchemaURI(schema string) string {
+	return schemaPrefix + schema
+}
+
+// Schema returns the JSON schema with the given name.
+func Schema(schema string) ([]byte, error) {
+	data, err := schemas.ReadFile(path.Join("schemas", schema+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown output schema %q", schema)
+	}
+	return data, nil
+}
+
+// Output declares the schema of the structured results of a tool. It must
+// come after the tool's description.
+func Output(schema string) mcp.ToolOption {
+	if _, err := Schema(schema); err != nil {
+		panic(err)
+	}
+	return func(t *mcp.Tool) {
+		t.Description += fmt.Sprintf(" Results include a JSON resource following the schema %s.", SchemaURI(schema))
+	}
+}
+
+// Result returns text as the result of a tool, along with v as its
+// structured result following schema.
+func Result(text, schema string, v any) (*mcp.CallToolResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the structured result: %w", err)
+	}
+	result := mcp.NewToolResultText(text)
+	result.Content = append(result.Content, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+		URI:      resultPrefix + schema,
+		MIMEType: "application/json",
+		Text:     string(data),
+	}))
+	result.Meta = map[string]any{SchemaMetaField: SchemaURI(schema)}
+	return result, nil
+}
+
+// IsStructured reports whether content is the structured result of a tool.
+func IsStructured(content mcp.Content) bool {
+	r, ok := content.(mcp.EmbeddedResource)
+	if !ok {
+		return false
+	}
+	text, ok := r.Resource.(mcp.TextResourceContents)
+	return ok && strings.HasPrefix(text.URI, resultPrefix)
+}
+
+// Install publishes the schemas as resources.
+func Install(s *server.MCPServer) error {
+	entries, err := schemas.ReadDir("schemas")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		data, err := Schema(name)
+		if err != nil {
+			return err
+		}
+		var schema struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return fmt.Errorf("invalid output schema %s: %w", name, err)
+		}
+		uri := SchemaURI(name)
+		s.AddResource(mcp.NewResource(uri, schema.Title+" schema",
+			mcp.WithResourceDescription("JSON schema of structured tool results: "+schema.Description),
+			mcp.WithMIMEType("application/schema+json"),
+		), func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: uri, MIMEType: "application/schema+json", Text: string(data)},
+			}, nil
+		})
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structured
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSchemas(t *testing.T) {
+	for _, name := range []string{Cluster, Clusters, Findings, Costs} {
+		data, err := Schema(name)
+		if err != nil {
+			t.Fatalf("Schema(%q) failed: %v", name, err)
+		}
+		var schema struct {
+			ID   string `json:"$id"`
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			t.Fatalf("schema %s isn't valid JSON: %v", name, err)
+		}
+		if schema.ID != SchemaURI(name) || schema.Type != "object" {
+			t.Errorf("schema %s has $id %q and type %q, want %q and object", name, schema.ID, schema.Type, SchemaURI(name))
+		}
+	}
+
+	s := server.NewMCPServer("test", "0.0.0", server.WithResourceCapabilities(false, false))
+	if err := Install(s); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	resp := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"gke-mcp://schemas/findings"}}`))
+	if _, ok := resp.(mcp.JSONRPCResponse); !ok {
+		t.Errorf("reading the findings schema failed: %+v", resp)
+	}
+}
+
+func TestResult(t *testing.T) {
+	result, err := Result("2 findings", Findings, map[string]any{"findings": []any{}})
+	if err != nil {
+		t.Fatalf("Result() failed: %v", err)
+	}
+	if len(result.Content) != 2 || IsStructured(result.Content[0]) || !IsStructured(result.Content[1]) {
+		t.Fatalf("Result() contents = %+v, want the text then the structured result", result.Content)
+	}
+	text := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents).Text
+	if text != `{"findings":[]}` {
+		t.Errorf("structured result = %s, want the JSON of the value", text)
+	}
+	if got := result.Meta[SchemaMetaField]; got != SchemaURI(Findings) {
+		t.Errorf("result metadata names schema %v, want %s", got, SchemaURI(Findings))
+	}
+}
+
+func TestOutput(t *testing.T) {
+	tool := mcp.NewTool("t", mcp.WithDescription("Do things."), Output(Clusters))
+	if want := "Do things. Results include a JSON resource following the schema gke-mcp://schemas/clusters."; tool.Description != want {
+		t.Errorf("description = %q, want %q", tool.Description, want)
+	}
+}
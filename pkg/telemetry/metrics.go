@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gke_mcp_tool_calls_total",
+		Help: "Number of MCP tool calls, by tool and result.",
+	}, []string{"tool", "result"})
+
+	toolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gke_mcp_tool_call_duration_seconds",
+		Help:    "Duration of MCP tool calls.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"tool"})
+
+	gcpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gke_mcp_gcp_request_duration_seconds",
+		Help:    "Latency of GCP API requests, by method and gRPC status code.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"method", "code"})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gke_mcp_cache_lookups_total",
+		Help: "Number of cache lookups, by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		toolCalls,
+		toolDuration,
+		gcpRequestDuration,
+		cacheLookups,
+	)
+}
+
+// MetricsPath is where MetricsHandler is served in HTTP mode.
+const MetricsPath = "/metrics"
+
+// MetricsHandler serves the server metrics in the Prometheus exposition format.
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// MetricsMiddleware counts and times every tool call.
+func MetricsMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+		toolDuration.WithLabelValues(request.Params.Name).Observe(time.Since(start).Seconds())
+
+		outcome := "ok"
+		if err != nil || (result != nil && result.IsError) {
+			outcome = "error"
+		}
+		toolCalls.WithLabelValues(request.Params.Name, outcome).Inc()
+		return result, err
+	}
+}
+
+// metricsInterceptor times every GCP API request made over gRPC.
+func metricsInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	gcpRequestDuration.WithLabelValues(strings.TrimPrefix(method, "/"), status.Code(err).String()).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// MetricsDialOption installs GCP API latency metrics on a gRPC client connection.
+func MetricsDialOption() grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(metricsInterceptor)
+}
+
+// RecordCacheLookup counts a hit or miss in the named cache.
+func RecordCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(cache, result).Inc()
+}
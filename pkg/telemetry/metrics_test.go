@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// toolCallCount returns the number of calls of tool timed by toolDuration.
+func toolCallCount(t *testing.T, tool string) uint64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != "gke_mcp_tool_call_duration_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "tool" && l.GetValue() == tool {
+					return m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		result  *mcp.CallToolResult
+		err     error
+		outcome string
+	}{
+		{name: "ok", result: mcp.NewToolResultText("done"), outcome: "ok"},
+		{name: "error result", result: mcp.NewToolResultError("failed"), outcome: "error"},
+		{name: "error", err: errors.New("failed"), outcome: "error"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := "metrics_test_" + tc.outcome
+			calls := testutil.ToFloat64(toolCalls.WithLabelValues(tool, tc.outcome))
+			timed := toolCallCount(t, tool)
+
+			handler := MetricsMiddleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tc.result, tc.err
+			})
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tool
+			handler(context.Background(), request)
+
+			if got := testutil.ToFloat64(toolCalls.WithLabelValues(tool, tc.outcome)); got != calls+1 {
+				t.Errorf("%s calls with result %s = %v, want %v", tool, tc.outcome, got, calls+1)
+			}
+			if got := toolCallCount(t, tool); got != timed+1 {
+				t.Errorf("%s timed calls = %d, want %d", tool, got, timed+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package telemetry_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/mock"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/telemetry"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		tp.Shutdown(context.Background())
+	})
+
+	m, err := mock.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := config.New("test", config.WithMock(m))
+	handler := telemetry.TracingMiddleware(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cm, err := gcp.ClusterManager(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := cm.ListClusters(ctx, &containerpb.ListClustersRequest{Parent: "projects/" + c.DefaultProjectID() + "/locations/-"}); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultError("cluster is unhealthy"), nil
+	})
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "cluster_health_check"
+	if _, err := handler(context.Background(), request); err != nil {
+		t.Fatalf("handler() failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	var tool *tracetest.SpanStub
+	for i := range spans {
+		if spans[i].Name == "tools/call cluster_health_check" {
+			tool = &spans[i]
+		}
+	}
+	if tool == nil {
+		t.Fatalf("no span of the tool call in %d spans", len(spans))
+	}
+	if tool.Status.Code != codes.Error {
+		t.Errorf("tool span status = %v, want %v", tool.Status.Code, codes.Error)
+	}
+	found := false
+	for _, a := range tool.Attributes {
+		if a.Key == "mcp.tool.name" && a.Value.AsString() == "cluster_health_check" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("tool span attributes = %v, want mcp.tool.name", tool.Attributes)
+	}
+
+	found = false
+	for _, s := range spans {
+		if s.Name == "google.container.v1.ClusterManager/ListClusters" && s.Parent.SpanID() == tool.SpanContext.SpanID() {
+			found = true
+		}
+	}
+	if !found {
+		var names []string
+		for _, s := range spans {
+			names = append(names, s.Name)
+		}
+		t.Errorf("no child span of the ListClusters request in %v", names)
+	}
+}
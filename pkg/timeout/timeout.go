@@ -0,0 +1,108 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package timeout bounds how long tool calls may run, so a hung GCP or
+// Kubernetes call can't stall the session.
+package timeout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/operations"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type result struct {
+	result *mcp.CallToolResult
+	err    error
+}
+
+// toolTimeout returns how long a call of tool may run, or 0 if it may run
+// forever. Tools that change resources, and may wait for the GKE operations
+// applying the change, are only bounded by a timeout set for them: one
+// stopping them midway would leave the change half-reported.
+func toolTimeout(c *config.Config, tool string) time.Duration {
+	timeout, explicit := c.ToolTimeout(tool)
+	if explicit {
+		return timeout
+	}
+	if e, ok := catalog.Lookup(tool); ok {
+		switch e.Kind {
+		case catalog.Read, catalog.Query, catalog.Local:
+		default:
+			return 0
+		}
+	}
+	return timeout
+}
+
+// Middleware returns a tool middleware that gives every tool call a deadline
+// of c.ToolTimeout, except for the tools changing resources. Calls still
+// running at the deadline are answered with an error right away, even if the
+// tool doesn't stop when its context is canceled.
+func Middleware(c *config.Config) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			timeout := toolTimeout(c, request.Params.Name)
+			if timeout <= 0 {
+				return next(ctx, request)
+			}
+			start := time.Now()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan result, 1)
+			go func() {
+				r, err := next(ctx, request)
+				done <- result{r, err}
+			}()
+			select {
+			case r := <-done:
+				if r.err == nil && r.result != nil && r.result.IsError && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return timeoutResult(request.Params.Name, timeout, start), nil
+				}
+				return r.result, r.err
+			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					slog.Warn("Tool call timed out", "tool", request.Params.Name, "timeout", timeout)
+					return timeoutResult(request.Params.Name, timeout, start), nil
+				}
+				return nil, ctx.Err()
+			}
+		}
+	}
+}
+
+// timeoutResult reports that a call of tool started at start timed out,
+// along with the GKE operations it started, which keep running.
+func timeoutResult(tool string, timeout time.Duration, start time.Time) *mcp.CallToolResult {
+	var started []string
+	for _, op := range operations.Default.Pending() {
+		if op.Tool == tool && !op.StartTime.Before(start) {
+			started = append(started, op.Name)
+		}
+	}
+	if len(started) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("%s timed out after %v while waiting for GKE operations it started, which may still complete: %s. Check on them with get_operation before retrying.", tool, timeout, strings.Join(started, ", ")))
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("%s timed out after %v. Narrow the request, e.g. with a shorter time range or a more specific filter, and try again.", tool, timeout))
+}
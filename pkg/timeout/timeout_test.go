@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timeout
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/operations"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMiddleware(t *testing.T) {
+	c := config.New("test",
+		config.WithToolTimeout(10*time.Millisecond),
+		config.WithToolTimeoutOverride("slow_but_allowed", time.Minute),
+	)
+	mcp.NewTool("test_create_node_pool", catalog.Describe(catalog.Clusters, catalog.Write))
+	// hang ignores its context, like a stuck call would.
+	hang := func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		time.Sleep(50 * time.Millisecond)
+		return mcp.NewToolResultText("done"), nil
+	}
+	handler := Middleware(c)(hang)
+
+	tests := []struct {
+		tool        string
+		wantTimeout bool
+	}{
+		{tool: "hung_tool", wantTimeout: true},
+		{tool: "slow_but_allowed"},
+		{tool: "test_create_node_pool"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.tool, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tc.tool
+			result, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if timedOut := result.IsError && strings.Contains(text, "timed out"); timedOut != tc.wantTimeout {
+				t.Errorf("result = %q, want timeout %v", text, tc.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestMiddlewareReportsOperations(t *testing.T) {
+	c := config.New("test", config.WithToolTimeoutOverride("test_create_cluster", 10*time.Millisecond))
+	handler := Middleware(c)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		done := operations.Default.Track(operations.Operation{Name: "operation-123", Tool: request.Params.Name})
+		defer done()
+		time.Sleep(50 * time.Millisecond)
+		return mcp.NewToolResultText("done"), nil
+	})
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "test_create_cluster"
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !result.IsError || !strings.Contains(text, "operation-123") || !strings.Contains(text, "may still complete") {
+		t.Errorf("result = %q, want a timeout naming operation-123", text)
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// applications is the API path of the Argo CD Application resources.
+const applications = "/apis/argoproj.io/v1alpha1"
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("argocd", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	listArgoCDApplicationsTool := mcp.NewTool("list_argocd_applications",
+		mcp.WithDescription("List the Argo CD Applications of the GKE cluster running Argo CD, with their sync and health status, the last sync error and the resources that drifted from Git. Start incident triage with it when deployments are managed by Argo CD: an OutOfSync or Degraded application points to what changed. Given an application name, also lists its unhealthy and out of sync resources."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.get", "container.thirdPartyObjects.list"),
+		explain.Command(h.listArgoCDApplicationsCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster Argo CD runs in. Defaults to the session context.")),
+		mcp.WithString("namespace", mcp.Description("Namespace of the Application resources, usually argocd. Leave empty for all namespaces.")),
+		mcp.WithString("application", mcp.Description("Name of a single application to show in detail.")),
+		mcp.WithBoolean("only_problems", mcp.Description("Only list the applications that are out of sync, not healthy or failed their last sync.")),
+		governor.FullOption(),
+	)
+	s.AddTool(listArgoCDApplicationsTool, h.listArgoCDApplications)
+
+	return nil
+}
+
+// application is the part of an Argo CD Application used here.
+type application struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		Project string `json:"project"`
+		Source  *struct {
+			RepoURL        string `json:"repoURL"`
+			Path           string `json:"path"`
+			Chart          string `json:"chart"`
+			TargetRevision string `json:"targetRevision"`
+		} `json:"source"`
+		Destination struct {
+			Server    string `json:"server"`
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"destination"`
+		SyncPolicy *struct {
+			Automated *struct {
+				Prune    bool `json:"prune"`
+				SelfHeal bool `json:"selfHeal"`
+			} `json:"automated"`
+		} `json:"syncPolicy"`
+	} `json:"spec"`
+	Status struct {
+		Sync struct {
+			Status   string `json:"status"`
+			Revision string `json:"revision"`
+		} `json:"sync"`
+		Health struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"health"`
+		OperationState *struct {
+			Phase      string `json:"phase"`
+			Message    string `json:"message"`
+			StartedAt  string `json:"startedAt"`
+			FinishedAt string `json:"finishedAt"`
+		} `json:"operationState"`
+		Conditions []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+		Resources    []resourceStatus `json:"resources"`
+		ReconciledAt string           `json:"reconciledAt"`
+	} `json:"status"`
+}
+
+type resourceStatus struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Status    string `json:"status,omitempty"`
+	Health    *struct {
+		Status  string `json:"status"`
+		Message string `json:"message,omitempty"`
+	} `json:"health,omitempty"`
+}
+
+// summary is the tool result entry of an application.
+type summary struct {
+	Name        string   `json:"name"`
+	Namespace   string   `json:"namespace"`
+	Project     string   `json:"project,omitempty"`
+	Source      string   `json:"source,omitempty"`
+	Destination string   `json:"destination"`
+	AutoSync    bool     `json:"autoSync"`
+	Sync        string   `json:"sync"`
+	Revision    string   `json:"revision,omitempty"`
+	Health      string   `json:"health"`
+	HealthInfo  string   `json:"healthMessage,omitempty"`
+	LastSync    string   `json:"lastSync,omitempty"`
+	SyncError   string   `json:"syncError,omitempty"`
+	Conditions  []string `json:"conditions,omitempty"`
+	// OutOfSync counts the resources that drifted from Git.
+	OutOfSync int `json:"outOfSyncResources,omitempty"`
+	// Unhealthy counts the resources whose health is assessed and isn't
+	// Healthy, e.g. Progressing or Degraded.
+	Unhealthy int `json:"unhealthyResources,omitempty"`
+	// Resources lists the out of sync and unhealthy resources, for a single
+	// application.
+	Resources []resourceStatus `json:"resources,omitempty"`
+}
+
+func (h *handlers) listArgoCDApplications(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	cluster := session.Cluster(ctx, request, h.c, "cluster")
+	if cluster == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, cluster)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := request.GetString("namespace", "")
+	name := request.GetString("application", "")
+	apps, err := listApplications(ctx, k, namespace, name)
+	if kube.IsNotFound(err) {
+		return mcp.NewToolResultError(fmt.Sprintf("Argo CD is not installed in cluster %s: the Application resource isn't served. If Argo CD runs in another cluster, query that one.", cluster)), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if name != "" && len(apps) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Argo CD application %q not found", name)), nil
+	}
+
+	onlyProblems := request.GetBool("only_problems", false)
+	summaries := []summary{}
+	for _, app := range apps {
+		s := summarize(app, name != "")
+		if onlyProblems && !s.hasProblem() {
+			continue
+		}
+		summaries = append(summaries, s)
+	}
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func (h *handlers) listArgoCDApplicationsCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	namespace := explain.Flag("namespace", request.GetString("namespace", ""))
+	if name := request.GetString("application", ""); name != "" {
+		return []string{
+			explain.GetCredentials(projectID, location, cluster),
+			explain.Join("kubectl get applications.argoproj.io", name, namespace, "--output=yaml"),
+		}
+	}
+	if namespace == "" {
+		namespace = "--all-namespaces"
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		explain.Join("kubectl get applications.argoproj.io", namespace),
+	}
+}
+
+// listApplications gets the applications in namespace, or all namespaces if
+// empty, optionally only the one named name.
+func listApplications(ctx context.Context, k *kube.Client, namespace, name string) ([]application, error) {
+	path := applications
+	if namespace != "" {
+		path += "/namespaces/" + url.PathEscape(namespace)
+	}
+	path += "/applications"
+	if name != "" {
+		path += "?" + url.Values{"fieldSelector": {"metadata.name=" + name}}.Encode()
+	}
+	var list struct {
+		Items []application `json:"items"`
+	}
+	if err := k.Get(ctx, path, &list); err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		a, b := list.Items[i].Metadata, list.Items[j].Metadata
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return list.Items, nil
+}
+
+// summarize returns the summary of app, listing its problematic resources if
+// detailed.
+func summarize(app application, detailed bool) summary {
+	st := app.Status
+	s := summary{
+		Name:       app.Metadata.Name,
+		Namespace:  app.Metadata.Namespace,
+		Project:    app.Spec.Project,
+		Sync:       st.Sync.Status,
+		Revision:   st.Sync.Revision,
+		Health:     st.Health.Status,
+		HealthInfo: st.Health.Message,
+		AutoSync:   app.Spec.SyncPolicy != nil && app.Spec.SyncPolicy.Automated != nil,
+	}
+	if src := app.Spec.Source; src != nil {
+		s.Source = strings.TrimSuffix(src.RepoURL+" "+src.Path+src.Chart, " ")
+		if src.TargetRevision != "" {
+			s.Source += "@" + src.TargetRevision
+		}
+	}
+	dest := app.Spec.Destination
+	s.Destination = dest.Server
+	if dest.Name != "" {
+		s.Destination = dest.Name
+	}
+	if dest.Namespace != "" {
+		s.Destination += "/" + dest.Namespace
+	}
+	if op := st.OperationState; op != nil {
+		s.LastSync = op.Phase
+		if op.FinishedAt != "" {
+			s.LastSync += " at " + op.FinishedAt
+		}
+		if op.Phase == "Failed" || op.Phase == "Error" {
+			s.SyncError = op.Message
+		}
+	}
+	for _, c := range st.Conditions {
+		s.Conditions = append(s.Conditions, c.Type+": "+c.Message)
+	}
+	for _, r := range st.Resources {
+		outOfSync := r.Status == "OutOfSync"
+		unhealthy := r.Health != nil && r.Health.Status != "Healthy"
+		if outOfSync {
+			s.OutOfSync++
+		}
+		if unhealthy {
+			s.Unhealthy++
+		}
+		if detailed && (outOfSync || unhealthy) {
+			s.Resources = append(s.Resources, r)
+		}
+	}
+	return s
+}
+
+// hasProblem reports whether the application needs attention.
+func (s summary) hasProblem() bool {
+	return s.Sync != "Synced" || s.Health != "Healthy" || s.SyncError != "" || len(s.Conditions) > 0
+}
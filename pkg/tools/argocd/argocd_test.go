@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+)
+
+const applicationList = `{"items":[
+{
+  "metadata": {"name": "web", "namespace": "argocd"},
+  "spec": {
+    "project": "default",
+    "source": {"repoURL": "https://github.com/example/deploy", "path": "web", "targetRevision": "main"},
+    "destination": {"server": "https://kubernetes.default.svc", "namespace": "web"},
+    "syncPolicy": {"automated": {"selfHeal": true}}
+  },
+  "status": {
+    "sync": {"status": "OutOfSync", "revision": "abc123"},
+    "health": {"status": "Degraded"},
+    "operationState": {"phase": "Failed", "message": "one or more objects failed to apply", "finishedAt": "2025-06-01T10:00:00Z"},
+    "conditions": [{"type": "SyncError", "message": "Failed sync attempt"}],
+    "resources": [
+      {"group": "apps", "kind": "Deployment", "namespace": "web", "name": "web", "status": "OutOfSync", "health": {"status": "Degraded", "message": "Deployment exceeded its progress deadline"}},
+      {"kind": "Service", "namespace": "web", "name": "web", "status": "Synced", "health": {"status": "Healthy"}},
+      {"kind": "ConfigMap", "namespace": "web", "name": "web-config", "status": "Synced"}
+    ]
+  }
+},
+{
+  "metadata": {"name": "api", "namespace": "argocd"},
+  "spec": {"destination": {"name": "prod", "namespace": "api"}},
+  "status": {"sync": {"status": "Synced"}, "health": {"status": "Healthy"}, "operationState": {"phase": "Succeeded"}}
+}
+]}`
+
+func TestListApplications(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/argoproj.io/v1alpha1/namespaces/argocd/applications" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, applicationList)
+	}))
+	defer srv.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	k, err := kube.New(srv.URL, ca, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}))
+	if err != nil {
+		t.Fatalf("kube.New() failed: %v", err)
+	}
+
+	apps, err := listApplications(context.Background(), k, "argocd", "")
+	if err != nil {
+		t.Fatalf("listApplications() failed: %v", err)
+	}
+	var got []summary
+	for _, app := range apps {
+		got = append(got, summarize(app, app.Metadata.Name == "web"))
+	}
+
+	web := summary{
+		Name:        "web",
+		Namespace:   "argocd",
+		Project:     "default",
+		Source:      "https://github.com/example/deploy web@main",
+		Destination: "https://kubernetes.default.svc/web",
+		AutoSync:    true,
+		Sync:        "OutOfSync",
+		Revision:    "abc123",
+		Health:      "Degraded",
+		LastSync:    "Failed at 2025-06-01T10:00:00Z",
+		SyncError:   "one or more objects failed to apply",
+		Conditions:  []string{"SyncError: Failed sync attempt"},
+		OutOfSync:   1,
+		Unhealthy:   1,
+		Resources:   []resourceStatus{apps[1].Status.Resources[0]},
+	}
+	want := []summary{
+		{Name: "api", Namespace: "argocd", Destination: "prod/api", Sync: "Synced", Health: "Healthy", LastSync: "Succeeded"},
+		web,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("summaries mismatch (-want +got):\n%s", diff)
+	}
+	if got[0].hasProblem() || !got[1].hasProblem() {
+		t.Errorf("hasProblem() = %v, %v, want false, true", got[0].hasProblem(), got[1].hasProblem())
+	}
+
+	if _, err := listApplications(context.Background(), k, "", ""); !kube.IsNotFound(err) {
+		t.Errorf("listApplications() without Argo CD = %v, want a not found error", err)
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	artifactregistry "cloud.google.com/go/artifactregistry/apiv1"
+	"cloud.google.com/go/artifactregistry/apiv1/artifactregistrypb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("artifacts", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	listArtifactImagesTool := mcp.NewTool("list_artifact_images",
+		mcp.WithDescription("List the Docker repositories of Artifact Registry in a location, or the images of a repository with their tags, digest, size and upload time, most recently updated first."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "artifactregistry.repositories.list", "artifactregistry.dockerimages.list"),
+		explain.Command(h.listArtifactImagesCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID of the registry. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("Artifact Registry location, a region such as us-central1 or a multi-region such as us. Defaults to the region of the session context's location.")),
+		mcp.WithString("repository", mcp.Description("ID of the repository to list the images of. Leave empty to list the repositories.")),
+		mcp.WithString("image", mcp.Description("Only list the images whose name contains this string.")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Number of images to list. Defaults to %d, at most %d.", defaultLimit, maxLimit))),
+		governor.FullOption(),
+	)
+	s.AddTool(listArtifactImagesTool, h.listArtifactImages)
+
+	findImageUsageTool := mcp.NewTool("find_image_usage",
+		mcp.WithDescription("Find the GKE clusters and workloads running an image digest. Use it to know what a vulnerable or broken image affects, or whether an image can be deleted."),
+		catalog.Describe(catalog.Fleet, catalog.Read, "container.clusters.list", "container.clusters.get", "container.pods.list"),
+		explain.Command(h.findImageUsageCommands),
+		mcp.WithString("image", mcp.Required(), mcp.Description("Image digest, e.g. sha256:..., or image reference with a digest, e.g. us-docker.pkg.dev/my-project/my-repo/my-image@sha256:....")),
+		mcp.WithString("project_id", mcp.Description("GCP project ID of the clusters. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("Only search the clusters in this location. Leave empty to search every location.")),
+		mcp.WithString("namespace", mcp.Description("Only search this namespace.")),
+	)
+	s.AddTool(findImageUsageTool, h.findImageUsage)
+
+	getImageDetailsTool := mcp.NewTool("get_image_details",
+		mcp.WithDescription("Get the details of an Artifact Registry image: its tags, size, layers per platform and vulnerability scan results, with the most severe vulnerabilities first and whether a fix is available."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "artifactregistry.dockerimages.get", "artifactregistry.repositories.downloadArtifacts", "containeranalysis.occurrences.list"),
+		explain.Command(h.getImageDetailsCommands),
+		mcp.WithString("image", mcp.Required(), mcp.Description("Image reference with a tag or digest, e.g. us-docker.pkg.dev/my-project/my-repo/my-image:v1. Defaults to the latest tag.")),
+		mcp.WithNumber("max_vulnerabilities", mcp.Description(fmt.Sprintf("Number of vulnerabilities to list. Defaults to %d.", defaultVulnerabilities))),
+		mcp.WithBoolean("only_fixable", mcp.Description("Only list the vulnerabilities with a fix available.")),
+	)
+	s.AddTool(getImageDetailsTool, h.getImageDetails)
+
+	verifyImageAttestationsTool := mcp.NewTool("verify_image_attestations",
+		mcp.WithDescription("Verify the images running in a namespace of a cluster against the Binary Authorization policy of the project: whether each image digest has the attestations its rule requires, or is exempt by an allowlist pattern, whether it has a sigstore (cosign) signature and which build provenance Artifact Analysis has for it. Reports the unsigned and unattested images with their provenance gaps, and whether the cluster enforces the policy at all."),
+		catalog.Describe(catalog.Security, catalog.Read, "container.clusters.get", "container.pods.list", "binaryauthorization.policy.get", "binaryauthorization.attestors.get", "containeranalysis.notes.listOccurrences", "containeranalysis.occurrences.list", "artifactregistry.repositories.downloadArtifacts"),
+		explain.Command(h.verifyImageAttestationsCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID of the cluster and its Binary Authorization policy. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace whose running images to verify.")),
+	)
+	s.AddTool(verifyImageAttestationsTool, h.verifyImageAttestations)
+
+	return nil
+}
+
+var zone = regexp.MustCompile(`^([a-z]+-[a-z]+\d+)-[a-z]$`)
+
+// region returns the region of a location, which may be a zone.
+func region(location string) string {
+	if m := zone.FindStringSubmatch(location); m != nil {
+		return m[1]
+	}
+	return location
+}
+
+// imageRef is a reference to an image in Artifact Registry.
+type imageRef struct {
+	Host       string
+	Location   string
+	Project    string
+	Repository string
+	Image      string
+	Tag        string
+	Digest     string
+}
+
+// parseImage parses an Artifact Registry image reference, which defaults to
+// the latest tag.
+func parseImage(ref string) (imageRef, error) {
+	name, digest, _ := strings.Cut(ref, "@")
+	var tag string
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	parts := strings.SplitN(name, "/", 4)
+	if len(parts) < 4 || !strings.HasSuffix(parts[0], "-docker.pkg.dev") {
+		return imageRef{}, fmt.Errorf("%q isn't an Artifact Registry image: expected LOCATION-docker.pkg.dev/PROJECT/REPOSITORY/IMAGE", ref)
+	}
+	if tag == "" && digest == "" {
+		tag = "latest"
+	}
+	return imageRef{
+		Host:       parts[0],
+		Location:   strings.TrimSuffix(parts[0], "-docker.pkg.dev"),
+		Project:    parts[1],
+		Repository: parts[2],
+		Image:      parts[3],
+		Tag:        tag,
+		Digest:     digest,
+	}, nil
+}
+
+// URI returns the image without tag or digest.
+func (r imageRef) URI() string {
+	return path.Join(r.Host, r.Project, r.Repository, r.Image)
+}
+
+// reference returns the digest of the image, or its tag if the digest isn't
+// known.
+func (r imageRef) reference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// dockerImageName returns the Artifact Registry resource name of the image
+// with the given digest.
+func (r imageRef) dockerImageName(digest string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/repositories/%s/dockerImages/%s@%s", r.Project, r.Location, r.Repository, url.PathEscape(r.Image), digest)
+}
+
+// dockerImage is the tool result entry of an image.
+type dockerImage struct {
+	Image    string   `json:"image"`
+	Digest   string   `json:"digest"`
+	Tags     []string `json:"tags,omitempty"`
+	Size     string   `json:"size,omitempty"`
+	Uploaded string   `json:"uploaded,omitempty"`
+	Built    string   `json:"built,omitempty"`
+}
+
+func toDockerImage(i *artifactregistrypb.DockerImage) dockerImage {
+	name, digest, _ := strings.Cut(i.GetUri(), "@")
+	return dockerImage{
+		Image:    name,
+		Digest:   digest,
+		Tags:     i.GetTags(),
+		Size:     formatSize(i.GetImageSizeBytes()),
+		Uploaded: formatTime(i.GetUploadTime()),
+		Built:    formatTime(i.GetBuildTime()),
+	}
+}
+
+// repository is the tool result entry of a repository.
+type repository struct {
+	Name        string `json:"name"`
+	URI         string `json:"uri"`
+	Mode        string `json:"mode,omitempty"`
+	Description string `json:"description,omitempty"`
+	Size        string `json:"size,omitempty"`
+	Scanning    string `json:"vulnerabilityScanning,omitempty"`
+}
+
+func (h *handlers) newClient(ctx context.Context) (*artifactregistry.Client, error) {
+	opts, err := auth.ClientOptions(ctx, h.c, config.APIArtifactRegistry)
+	if err != nil {
+		return nil, err
+	}
+	client, err := artifactregistry.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact registry client: %w", err)
+	}
+	return client, nil
+}
+
+func (h *handlers) listArtifactImages(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := region(session.Location(ctx, request, h.c))
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	limit := request.GetInt("limit", defaultLimit)
+	if limit <= 0 || limit > maxLimit {
+		return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d", maxLimit)), nil
+	}
+	client, err := h.newClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer client.Close()
+
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
+	var result any
+	if repo := request.GetString("repository", ""); repo != "" {
+		result, err = listImages(ctx, client, parent+"/repositories/"+repo, request.GetString("image", ""), limit)
+	} else {
+		result, err = listRepositories(ctx, client, parent)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// listRepositories gets the Docker repositories of a location.
+func listRepositories(ctx context.Context, client *artifactregistry.Client, parent string) ([]repository, error) {
+	repos := []repository{}
+	it := client.ListRepositories(ctx, &artifactregistrypb.ListRepositoriesRequest{Parent: parent})
+	for {
+		r, err := it.Next()
+		if err == iterator.Done {
+			return repos, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.GetFormat() != artifactregistrypb.Repository_DOCKER {
+			continue
+		}
+		repos = append(repos, repository{
+			Name:        path.Base(r.GetName()),
+			URI:         r.GetRegistryUri(),
+			Mode:        r.GetMode().String(),
+			Description: r.GetDescription(),
+			Size:        formatSize(r.GetSizeBytes()),
+			Scanning:    r.GetVulnerabilityScanningConfig().GetEnablementState().String(),
+		})
+	}
+}
+
+// listImages gets the latest images of a repository whose name contains
+// filter.
+func listImages(ctx context.Context, client *artifactregistry.Client, parent, filter string, limit int) ([]dockerImage, error) {
+	images := []dockerImage{}
+	it := client.ListDockerImages(ctx, &artifactregistrypb.ListDockerImagesRequest{Parent: parent, OrderBy: "update_time desc"})
+	for len(images) < limit {
+		i, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		image := toDockerImage(i)
+		if strings.Contains(path.Base(image.Image), filter) {
+			images = append(images, image)
+		}
+	}
+	return images, nil
+}
+
+func (h *handlers) listArtifactImagesCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location := session.ProjectID(ctx, request, h.c), region(session.Location(ctx, request, h.c))
+	if projectID == "" || location == "" {
+		return nil
+	}
+	repo := request.GetString("repository", "")
+	if repo == "" {
+		return []string{explain.Join("gcloud artifacts repositories list", explain.Flag("location", location), explain.Flag("project", projectID))}
+	}
+	return []string{explain.Join("gcloud artifacts docker images list", fmt.Sprintf("%s-docker.pkg.dev/%s/%s", location, projectID, repo), "--include-tags", "--sort-by=~UPDATE_TIME", explain.Flag("limit", fmt.Sprint(request.GetInt("limit", defaultLimit))))}
+}
+
+func formatTime(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	return ts.AsTime().Format(time.RFC3339)
+}
+
+// formatSize formats a size in bytes for humans.
+func formatSize(bytes int64) string {
+	if bytes <= 0 {
+		return ""
+	}
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifacts
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/api/binaryauthorization/v1"
+	grafeaspb "google.golang.org/genproto/googleapis/grafeas/v1"
+)
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    imageRef
+		wantErr bool
+	}{
+		{
+			ref:  "us-docker.pkg.dev/p/r/web:v1",
+			want: imageRef{Host: "us-docker.pkg.dev", Location: "us", Project: "p", Repository: "r", Image: "web", Tag: "v1"},
+		},
+		{
+			ref:  "europe-west4-docker.pkg.dev/p/r/team/web@sha256:abc",
+			want: imageRef{Host: "europe-west4-docker.pkg.dev", Location: "europe-west4", Project: "p", Repository: "r", Image: "team/web", Digest: "sha256:abc"},
+		},
+		{
+			ref:  "us-docker.pkg.dev/p/r/web",
+			want: imageRef{Host: "us-docker.pkg.dev", Location: "us", Project: "p", Repository: "r", Image: "web", Tag: "latest"},
+		},
+		{ref: "gcr.io/p/web:v1", wantErr: true},
+		{ref: "us-docker.pkg.dev/p/web", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := parseImage(tc.ref)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseImage(%q) error = %v, wantErr %v", tc.ref, err, tc.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("parseImage(%q) mismatch (-want +got):\n%s", tc.ref, diff)
+		}
+	}
+
+	ref, _ := parseImage("us-docker.pkg.dev/p/r/team/web:v1")
+	if got, want := ref.dockerImageName("sha256:abc"), "projects/p/locations/us/repositories/r/dockerImages/team%2Fweb@sha256:abc"; got != want {
+		t.Errorf("dockerImageName() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for size, want := range map[int64]string{0: "", 512: "512 B", 2048: "2.0 KiB", 5 << 20: "5.0 MiB", 3 << 30: "3.0 GiB"} {
+		if got := formatSize(size); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestWorkloadsRunning(t *testing.T) {
+	containers := []kube.Container{
+		{Workload: "prod/Deployment/web", Repository: "us-docker.pkg.dev/p/r/web", Digest: "sha256:a"},
+		{Workload: "prod/Deployment/web", Repository: "us-docker.pkg.dev/p/r/web", Digest: "sha256:a"},
+		{Workload: "dev/Deployment/web", Repository: "us-docker.pkg.dev/p/r/web", Digest: "sha256:b"},
+		{Workload: "dev/Job/copy", Repository: "us-docker.pkg.dev/p/other/web", Digest: "sha256:a"},
+	}
+	if diff := cmp.Diff([]string{"dev/Job/copy", "prod/Deployment/web"}, workloadsRunning(containers, "", "sha256:a")); diff != "" {
+		t.Errorf("workloadsRunning(any) mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"prod/Deployment/web"}, workloadsRunning(containers, "us-docker.pkg.dev/p/r/web", "sha256:a")); diff != "" {
+		t.Errorf("workloadsRunning(repo) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func vulnerabilityOccurrence(cve string, severity grafeaspb.Severity, cvss float32, fixed string) *grafeaspb.Occurrence {
+	issue := &grafeaspb.VulnerabilityOccurrence_PackageIssue{
+		AffectedPackage: "openssl",
+		AffectedVersion: &grafeaspb.Version{FullName: "3.0.1"},
+	}
+	if fixed != "" {
+		issue.FixAvailable = true
+		issue.FixedVersion = &grafeaspb.Version{FullName: fixed}
+	}
+	return &grafeaspb.Occurrence{
+		NoteName: "projects/goog-vulnz/notes/" + cve,
+		Details: &grafeaspb.Occurrence_Vulnerability{Vulnerability: &grafeaspb.VulnerabilityOccurrence{
+			EffectiveSeverity: severity,
+			CvssScore:         cvss,
+			FixAvailable:      fixed != "",
+			PackageIssue:      []*grafeaspb.VulnerabilityOccurrence_PackageIssue{issue},
+		}},
+	}
+}
+
+func TestSummarizeScan(t *testing.T) {
+	occurrences := []*grafeaspb.Occurrence{
+		vulnerabilityOccurrence("CVE-1", grafeaspb.Severity_MEDIUM, 5, ""),
+		vulnerabilityOccurrence("CVE-2", grafeaspb.Severity_CRITICAL, 9.8, "3.0.8"),
+		vulnerabilityOccurrence("CVE-3", grafeaspb.Severity_HIGH, 7.5, ""),
+		vulnerabilityOccurrence("CVE-4", grafeaspb.Severity_HIGH, 8.1, "3.0.2"),
+		{Details: &grafeaspb.Occurrence_Discovery{Discovery: &grafeaspb.DiscoveryOccurrence{AnalysisStatus: grafeaspb.DiscoveryOccurrence_FINISHED_SUCCESS}}},
+	}
+
+	want := &scanInfo{
+		Status:  "FINISHED_SUCCESS",
+		Counts:  map[string]int{"CRITICAL": 1, "HIGH": 2, "MEDIUM": 1},
+		Fixable: 2,
+		Vulnerabilities: []vulnerability{
+			{ID: "CVE-2", Severity: "CRITICAL", CVSS: 9.8, Package: "openssl", Version: "3.0.1", FixedIn: "3.0.8"},
+			{ID: "CVE-4", Severity: "HIGH", CVSS: 8.1, Package: "openssl", Version: "3.0.1", FixedIn: "3.0.2"},
+			{ID: "CVE-3", Severity: "HIGH", CVSS: 7.5, Package: "openssl", Version: "3.0.1"},
+		},
+	}
+	if diff := cmp.Diff(want, summarizeScan(occurrences, 3, false), cmpopts.IgnoreUnexported(vulnerability{})); diff != "" {
+		t.Errorf("summarizeScan() mismatch (-want +got):\n%s", diff)
+	}
+
+	fixable := summarizeScan(occurrences, 10, true)
+	if len(fixable.Vulnerabilities) != 2 {
+		t.Errorf("summarizeScan(only fixable) listed %d vulnerabilities, want 2", len(fixable.Vulnerabilities))
+	}
+	if got := summarizeScan(nil, 10, false).Status; got != "not scanned" {
+		t.Errorf("summarizeScan(nil).Status = %q, want not scanned", got)
+	}
+}
+
+func TestCollectImages(t *testing.T) {
+	policy := &binaryauthorization.Policy{
+		AdmissionWhitelistPatterns: []*binaryauthorization.AdmissionWhitelistPattern{{NamePattern: "gcr.io/google-containers/**"}},
+		DefaultAdmissionRule:       &binaryauthorization.AdmissionRule{EvaluationMode: "ALWAYS_ALLOW", EnforcementMode: "ENFORCED_BLOCK_AND_AUDIT_LOG"},
+		ClusterAdmissionRules: map[string]binaryauthorization.AdmissionRule{
+			"us-central1.prod": {EvaluationMode: "REQUIRE_ATTESTATION", EnforcementMode: "ENFORCED_BLOCK_AND_AUDIT_LOG", RequireAttestationsBy: []string{"projects/p/attestors/built"}},
+		},
+		KubernetesServiceAccountAdmissionRules: map[string]binaryauthorization.AdmissionRule{
+			"shop:deployer": {EvaluationMode: "REQUIRE_ATTESTATION", EnforcementMode: "DRYRUN_AUDIT_LOG_ONLY", RequireAttestationsBy: []string{"projects/p/attestors/qa"}},
+		},
+	}
+	pod := func(name, sa string, images ...string) verifiedPod {
+		var p verifiedPod
+		p.Metadata.Name, p.Metadata.Namespace, p.Spec.ServiceAccountName = name, "shop", sa
+		for _, image := range images {
+			p.Status.ContainerStatuses = append(p.Status.ContainerStatuses, containerStatus{Image: image, ImageID: image + "@sha256:1"})
+		}
+		return p
+	}
+	pods := []verifiedPod{
+		pod("web", "", "us-docker.pkg.dev/p/r/web:v1", "gcr.io/google-containers/pause:3.9"),
+		pod("job", "deployer", "us-docker.pkg.dev/p/r/web:v1"),
+	}
+
+	got := collectImages(pods, policy, "us-central1.prod")
+	want := []*imageVerification{
+		{
+			Image: "us-docker.pkg.dev/p/r/web", Digest: "sha256:1", Workloads: []string{"shop/Pod/web", "shop/Pod/job"},
+			Rule:              "service account shop:deployer: REQUIRE_ATTESTATION, DRYRUN_AUDIT_LOG_ONLY",
+			RequiredAttestors: []string{"projects/p/attestors/built", "projects/p/attestors/qa"},
+		},
+		{Image: "gcr.io/google-containers/pause", Digest: "sha256:1", Workloads: []string{"shop/Pod/web"}, ExemptBy: "gcr.io/google-containers/**"},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(imageVerification{})); diff != "" {
+		t.Errorf("collectImages() mismatch (-want +got):\n%s", diff)
+	}
+	if got := collectImages(pods, policy, "us-central1.dev")[0].RequiredAttestors; !cmp.Equal(got, []string{"projects/p/attestors/qa"}) {
+		t.Errorf("collectImages() on another cluster requires %q, want the service account's attestor only", got)
+	}
+}
+
+func TestFindGaps(t *testing.T) {
+	signed, unsigned := true, false
+	tests := []struct {
+		name       string
+		v          imageVerification
+		inRegistry bool
+		want       int
+	}{
+		{name: "verified", v: imageVerification{Digest: "sha256:1", RequiredAttestors: []string{"a"}, AttestedBy: []string{"a"}, SigstoreSigned: &signed, Provenance: &provenance{}}, inRegistry: true},
+		{name: "no digest", v: imageVerification{RequiredAttestors: []string{"a"}}, want: 1},
+		{name: "unattested and unsigned", v: imageVerification{Digest: "sha256:1", RequiredAttestors: []string{"a", "b"}, AttestedBy: []string{"b"}, SigstoreSigned: &unsigned}, inRegistry: true, want: 3},
+		{name: "other registry", v: imageVerification{Digest: "sha256:1"}, want: 1},
+	}
+	for _, tc := range tests {
+		tc.v.findGaps(tc.inRegistry)
+		if len(tc.v.Gaps) != tc.want {
+			t.Errorf("%s: findGaps() = %q, want %d gaps", tc.name, tc.v.Gaps, tc.want)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	containeranalysis "cloud.google.com/go/containeranalysis/apiv1"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/scan"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/api/binaryauthorization/v1"
+	"google.golang.org/api/iterator"
+	grafeaspb "google.golang.org/genproto/googleapis/grafeas/v1"
+)
+
+// verifiedPod is the part of a pod whose images are verified.
+type verifiedPod struct {
+	Metadata struct {
+		Name            string                `json:"name"`
+		Namespace       string                `json:"namespace"`
+		Labels          map[string]string     `json:"labels"`
+		OwnerReferences []kube.OwnerReference `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		ServiceAccountName string `json:"serviceAccountName"`
+	} `json:"spec"`
+	Status struct {
+		InitContainerStatuses []containerStatus `json:"initContainerStatuses"`
+		ContainerStatuses     []containerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type containerStatus struct {
+	Image   string `json:"image"`
+	ImageID string `json:"imageID"`
+}
+
+// imageVerification is what is known of the attestations, signatures and
+// provenance of an image running in the namespace.
+type imageVerification struct {
+	Image     string   `json:"image"`
+	Digest    string   `json:"digest,omitempty"`
+	Workloads []string `json:"workloads"`
+	// Rule is the Binary Authorization rule that applies to the image, and
+	// ExemptBy the allowlist pattern that exempts it from the rule.
+	Rule              string      `json:"rule,omitempty"`
+	ExemptBy          string      `json:"exemptBy,omitempty"`
+	RequiredAttestors []string    `json:"requiredAttestors,omitempty"`
+	AttestedBy        []string    `json:"attestedBy,omitempty"`
+	SigstoreSigned    *bool       `json:"sigstoreSigned,omitempty"`
+	Provenance        *provenance `json:"provenance,omitempty"`
+	Gaps              []string    `json:"gaps,omitempty"`
+
+	evaluationMode string
+}
+
+type provenance struct {
+	Builder   string `json:"builder,omitempty"`
+	BuildType string `json:"buildType,omitempty"`
+	BuildID   string `json:"buildId,omitempty"`
+}
+
+// admissionRule returns the Binary Authorization rule that applies to the
+// pods of a service account in a namespace of a cluster, named
+// location.cluster, and where it comes from. Rules for service accounts take
+// precedence over those for namespaces, and those over the ones for
+// clusters and the default rule.
+func admissionRule(policy *binaryauthorization.Policy, cluster, namespace, serviceAccount string) (string, *binaryauthorization.AdmissionRule) {
+	if r, ok := policy.KubernetesServiceAccountAdmissionRules[namespace+":"+serviceAccount]; ok {
+		return "service account " + namespace + ":" + serviceAccount, &r
+	}
+	if r, ok := policy.KubernetesNamespaceAdmissionRules[namespace]; ok {
+		return "namespace " + namespace, &r
+	}
+	if r, ok := policy.ClusterAdmissionRules[cluster]; ok {
+		return "cluster " + cluster, &r
+	}
+	return "default", policy.DefaultAdmissionRule
+}
+
+// allowlisted returns the allowlist pattern of the policy exempting an
+// image, where * matches within a path segment and a trailing ** matches
+// any suffix, or "" if none does.
+func allowlisted(policy *binaryauthorization.Policy, image string) string {
+	for _, p := range policy.AdmissionWhitelistPatterns {
+		pattern := regexp.QuoteMeta(p.NamePattern)
+		pattern = strings.ReplaceAll(pattern, `\*\*`, ".*")
+		pattern = strings.ReplaceAll(pattern, `\*`, "[^/]*")
+		if ok, _ := regexp.MatchString("^"+pattern+"$", image); ok {
+			return p.NamePattern
+		}
+	}
+	return ""
+}
+
+// collectImages groups the images the pods run by repository and digest,
+// with the workloads running them and the attestors their rules require.
+func collectImages(pods []verifiedPod, policy *binaryauthorization.Policy, cluster string) []*imageVerification {
+	images := map[string]*imageVerification{}
+	var order []string
+	for _, p := range pods {
+		m := p.Metadata
+		workload := kube.Workload(m.Namespace, m.Name, m.Labels, m.OwnerReferences)
+		for _, cs := range slices.Concat(p.Status.InitContainerStatuses, p.Status.ContainerStatuses) {
+			repository, digest := kube.Repository(cs.Image), kube.Digest(cs.ImageID)
+			key := repository + "@" + digest
+			v := images[key]
+			if v == nil {
+				v = &imageVerification{Image: repository, Digest: digest}
+				images[key] = v
+				order = append(order, key)
+			}
+			if !slices.Contains(v.Workloads, workload) {
+				v.Workloads = append(v.Workloads, workload)
+			}
+			if policy == nil {
+				continue
+			}
+			if v.ExemptBy = allowlisted(policy, cs.Image); v.ExemptBy == "" {
+				v.ExemptBy = allowlisted(policy, repository)
+			}
+			sa := p.Spec.ServiceAccountName
+			if sa == "" {
+				sa = "default"
+			}
+			scope, rule := admissionRule(policy, cluster, m.Namespace, sa)
+			if rule == nil || v.ExemptBy != "" {
+				continue
+			}
+			if v.Rule == "" || rule.EvaluationMode == "REQUIRE_ATTESTATION" {
+				v.Rule = fmt.Sprintf("%s: %s, %s", scope, rule.EvaluationMode, rule.EnforcementMode)
+				v.evaluationMode = rule.EvaluationMode
+			}
+			if rule.EvaluationMode == "REQUIRE_ATTESTATION" {
+				for _, a := range rule.RequireAttestationsBy {
+					if !slices.Contains(v.RequiredAttestors, a) {
+						v.RequiredAttestors = append(v.RequiredAttestors, a)
+					}
+				}
+			}
+		}
+	}
+	var result []*imageVerification
+	for _, key := range order {
+		result = append(result, images[key])
+	}
+	return result
+}
+
+// findGaps lists what keeps an image from being verified, from its
+// attestations, signature and provenance.
+func (v *imageVerification) findGaps(inRegistry bool) {
+	if v.Digest == "" {
+		v.Gaps = append(v.Gaps, "The kubelet reported no digest for the image, so nothing about it can be verified.")
+		return
+	}
+	if v.evaluationMode == "ALWAYS_DENY" {
+		v.Gaps = append(v.Gaps, "The Binary Authorization rule denies every image, so the pods were admitted before the rule or by breakglass.")
+	}
+	for _, a := range v.RequiredAttestors {
+		if !slices.Contains(v.AttestedBy, a) {
+			v.Gaps = append(v.Gaps, fmt.Sprintf("No attestation by %s, which the Binary Authorization policy requires.", a))
+		}
+	}
+	if !inRegistry {
+		v.Gaps = append(v.Gaps, "The image isn't in Artifact Registry, so its sigstore signature and build provenance couldn't be checked.")
+		return
+	}
+	if v.SigstoreSigned != nil && !*v.SigstoreSigned {
+		v.Gaps = append(v.Gaps, "No sigstore signature: the registry has no "+sigstoreTag(v.Digest, "sig")+" tag, which cosign sign pushes.")
+	}
+	if v.Provenance == nil {
+		v.Gaps = append(v.Gaps, "No build provenance in Artifact Analysis; build the image with Cloud Build, or upload SLSA provenance, to know where it comes from.")
+	}
+}
+
+// sigstoreTag returns the tag under which cosign stores the signatures, sig,
+// or attestations, att, of a digest.
+func sigstoreTag(digest, suffix string) string {
+	return strings.Replace(digest, ":", "-", 1) + "." + suffix
+}
+
+// toProvenance returns the provenance of a build occurrence.
+func toProvenance(o *grafeaspb.Occurrence) *provenance {
+	b := o.GetBuild()
+	p := &provenance{BuildID: b.GetProvenance().GetId()}
+	if slsa := b.GetInTotoSlsaProvenanceV1().GetPredicate(); slsa != nil {
+		p.Builder = slsa.GetRunDetails().GetBuilder().GetId()
+		p.BuildType = slsa.GetBuildDefinition().GetBuildType()
+	}
+	if p.Builder == "" {
+		p.Builder = b.GetIntotoStatement().GetSlsaProvenance().GetBuilder().GetId()
+	}
+	if p.Builder == "" {
+		p.Builder = b.GetProvenance().GetBuilderVersion()
+	}
+	return p
+}
+
+type findingList struct {
+	Findings []structuredFinding `json:"findings"`
+}
+
+type structuredFinding struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Category    string `json:"category"`
+	Priority    string `json:"priority"`
+	Resource    string `json:"resource"`
+}
+
+func imageFindings(images []*imageVerification) findingList {
+	list := findingList{Findings: []structuredFinding{}}
+	for _, v := range images {
+		if len(v.Gaps) == 0 {
+			continue
+		}
+		priority := "P3"
+		if len(v.RequiredAttestors) > len(v.AttestedBy) {
+			priority = "P2"
+		}
+		list.Findings = append(list.Findings, structuredFinding{
+			Name:        "attestation/" + v.Image + "@" + v.Digest,
+			Description: strings.Join(v.Gaps, " "),
+			Type:        "unverified-image",
+			Category:    "SECURITY",
+			Priority:    priority,
+			Resource:    v.Image + "@" + v.Digest,
+		})
+	}
+	return list
+}
+
+// verifyImage looks up the attestations of the image by the attestors with
+// the given notes, its sigstore signature and its build provenance.
+func (h *handlers) verifyImage(ctx context.Context, client *containeranalysis.Client, v *imageVerification, notes map[string]string) error {
+	resourceURL := fmt.Sprintf("https://%s@%s", v.Image, v.Digest)
+	for _, attestor := range v.RequiredAttestors {
+		note := notes[attestor]
+		if note == "" {
+			continue
+		}
+		it := client.GetGrafeasClient().ListNoteOccurrences(ctx, &grafeaspb.ListNoteOccurrencesRequest{Name: note, Filter: fmt.Sprintf("resourceUrl=%q", resourceURL)})
+		if _, err := it.Next(); err == nil {
+			v.AttestedBy = append(v.AttestedBy, attestor)
+		} else if err != iterator.Done {
+			return fmt.Errorf("failed to list the attestations of %s: %w", attestor, err)
+		}
+	}
+
+	ref, err := parseImage(v.Image + "@" + v.Digest)
+	if err != nil {
+		v.findGaps(false)
+		return nil
+	}
+	resp, err := h.manifestRequest(ctx, http.MethodHead, ref, sigstoreTag(v.Digest, "sig"))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		v.SigstoreSigned = new(bool)
+		*v.SigstoreSigned = true
+	case http.StatusNotFound:
+		v.SigstoreSigned = new(bool)
+	}
+
+	it := client.GetGrafeasClient().ListOccurrences(ctx, &grafeaspb.ListOccurrencesRequest{
+		Parent: "projects/" + ref.Project,
+		Filter: fmt.Sprintf(`resourceUrl=%q AND kind="BUILD"`, resourceURL),
+	})
+	o, err := it.Next()
+	switch {
+	case err == nil:
+		v.Provenance = toProvenance(o)
+	case err != iterator.Done:
+		return fmt.Errorf("failed to list the build provenance of %s: %w", v.Image, err)
+	}
+	v.findGaps(true)
+	return nil
+}
+
+func (h *handlers) verifyImageAttestations(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	namespace, err := request.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var pods struct {
+		Items []verifiedPod `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/namespaces/"+url.PathEscape(namespace)+"/pods?fieldSelector=status.phase%3DRunning", &pods); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var notes []string
+	opts, err := auth.ClientOptions(ctx, h.c, config.APIBinaryAuthorization)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	binauthz, err := binaryauthorization.NewService(ctx, opts...)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create binary authorization client: %v", err)), nil
+	}
+	policy, err := binauthz.Projects.GetPolicy("projects/" + projectID + "/policy").Context(ctx).Do()
+	if err != nil {
+		notes = append(notes, fmt.Sprintf("The Binary Authorization policy couldn't be read, so required attestations weren't checked: %v", err))
+		policy = nil
+	}
+	images := collectImages(pods.Items, policy, location+"."+name)
+
+	attestorNotes := map[string]string{}
+	for _, v := range images {
+		for _, a := range v.RequiredAttestors {
+			if _, ok := attestorNotes[a]; ok {
+				continue
+			}
+			attestor, err := binauthz.Projects.Attestors.Get(a).Context(ctx).Do()
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("The attestor %s couldn't be read: %v", a, err))
+				attestorNotes[a] = ""
+				continue
+			}
+			if attestor.UserOwnedGrafeasNote != nil {
+				attestorNotes[a] = attestor.UserOwnedGrafeasNote.NoteReference
+			}
+		}
+	}
+
+	opts, err = auth.ClientOptions(ctx, h.c, config.APIContainerAnalysis)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	caClient, err := containeranalysis.NewClient(ctx, opts...)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create container analysis client: %v", err)), nil
+	}
+	defer caClient.Close()
+	for _, r := range scan.Run(ctx, images, scan.DefaultWorkers, func(ctx context.Context, v *imageVerification) (struct{}, error) {
+		if v.Digest == "" {
+			v.findGaps(false)
+			return struct{}{}, nil
+		}
+		return struct{}{}, h.verifyImage(ctx, caClient, v, attestorNotes)
+	}) {
+		if r.Err != nil {
+			r.Target.Gaps = append(r.Target.Gaps, fmt.Sprintf("Couldn't be fully verified: %v", r.Err))
+		}
+	}
+
+	var unverified int
+	for _, v := range images {
+		if len(v.Gaps) > 0 {
+			unverified++
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Verified %d images running in namespace %s of cluster %s: %d have gaps in their attestations, signatures or provenance.", len(images), namespace, name, unverified)
+	ba := cluster.GetBinaryAuthorization()
+	switch mode := ba.GetEvaluationMode(); {
+	case mode == containerpb.BinaryAuthorization_DISABLED || (mode == containerpb.BinaryAuthorization_EVALUATION_MODE_UNSPECIFIED && !ba.GetEnabled()):
+		b.WriteString(" Binary Authorization isn't enforced on the cluster, so its policy doesn't keep unattested images from running; enable it with gcloud container clusters update --binauthz-evaluation-mode=PROJECT_SINGLETON_POLICY_ENFORCE.")
+	case mode != containerpb.BinaryAuthorization_EVALUATION_MODE_UNSPECIFIED:
+		fmt.Fprintf(&b, " Binary Authorization evaluates the cluster's pods in mode %s.", mode)
+	}
+	for _, n := range notes {
+		b.WriteString(" " + n)
+	}
+	data, err := json.MarshalIndent(images, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(b.String()+"\n\n"+string(data), structured.Findings, imageFindings(images))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) verifyImageAttestationsCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	namespace := request.GetString("namespace", "")
+	if projectID == "" || location == "" || cluster == "" || namespace == "" {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud container clusters describe", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--format=yaml(binaryAuthorization)"),
+		explain.Join("gcloud container binauthz policy export", explain.Flag("project", projectID)),
+		explain.GetCredentials(projectID, location, cluster),
+		explain.Join("kubectl get pods", explain.Flag("namespace", namespace), "--field-selector=status.phase=Running", "--output=jsonpath={range .items[*].status.containerStatuses[*]}{.imageID}{\"\\n\"}{end}"),
+		"gcloud container binauthz attestations list --attestor=ATTESTOR --artifact-url=IMAGE@DIGEST",
+		"gcloud artifacts docker images describe IMAGE@DIGEST --show-provenance",
+		"cosign verify IMAGE@DIGEST --key=KEY",
+	}
+}
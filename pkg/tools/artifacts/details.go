@@ -0,0 +1,313 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/artifactregistry/apiv1/artifactregistrypb"
+	containeranalysis "cloud.google.com/go/containeranalysis/apiv1"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/api/iterator"
+	grafeaspb "google.golang.org/genproto/googleapis/grafeas/v1"
+)
+
+// defaultVulnerabilities is the number of vulnerabilities listed by default.
+const defaultVulnerabilities = 20
+
+// manifestTypes are the manifest media types accepted from the registry.
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// manifest is an image manifest or index.
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Config    descriptor   `json:"config"`
+	Layers    []descriptor `json:"layers"`
+	Manifests []struct {
+		descriptor
+		Platform *struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+			Variant      string `json:"variant"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// details is the result of get_image_details.
+type details struct {
+	dockerImage
+	Platforms []platform `json:"platforms"`
+	Scan      *scanInfo  `json:"scan,omitempty"`
+}
+
+type platform struct {
+	Platform string  `json:"platform,omitempty"`
+	Digest   string  `json:"digest"`
+	Size     string  `json:"compressedSize"`
+	Layers   []layer `json:"layers"`
+}
+
+type layer struct {
+	Digest string `json:"digest"`
+	Size   string `json:"size"`
+}
+
+type scanInfo struct {
+	Status          string          `json:"status"`
+	LastScan        string          `json:"lastScan,omitempty"`
+	Counts          map[string]int  `json:"counts,omitempty"`
+	Fixable         int             `json:"fixable,omitempty"`
+	Vulnerabilities []vulnerability `json:"vulnerabilities,omitempty"`
+}
+
+type vulnerability struct {
+	ID       string  `json:"id"`
+	Severity string  `json:"severity"`
+	CVSS     float32 `json:"cvss,omitempty"`
+	Package  string  `json:"package"`
+	Version  string  `json:"version,omitempty"`
+	FixedIn  string  `json:"fixedIn,omitempty"`
+	Summary  string  `json:"summary,omitempty"`
+
+	severity grafeaspb.Severity
+	fixable  bool
+}
+
+func (h *handlers) getImageDetails(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	image, err := request.RequireString("image")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	ref, err := parseImage(image)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	m, digest, err := h.fetchManifest(ctx, ref, ref.reference())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	ref.Digest = digest
+	result := details{dockerImage: dockerImage{Image: ref.URI(), Digest: digest}}
+
+	client, err := h.newClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer client.Close()
+	if i, err := client.GetDockerImage(ctx, &artifactregistrypb.GetDockerImageRequest{Name: ref.dockerImageName(digest)}); err == nil {
+		result.dockerImage = toDockerImage(i)
+	}
+
+	if len(m.Manifests) == 0 {
+		result.Platforms = []platform{toPlatform("", digest, m)}
+	}
+	for _, d := range m.Manifests {
+		if d.Platform == nil || d.Platform.OS == "unknown" {
+			// Attestations are stored as unknown platforms.
+			continue
+		}
+		pm, _, err := h.fetchManifest(ctx, ref, d.Digest)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		name := d.Platform.OS + "/" + d.Platform.Architecture
+		if d.Platform.Variant != "" {
+			name += "/" + d.Platform.Variant
+		}
+		result.Platforms = append(result.Platforms, toPlatform(name, d.Digest, pm))
+	}
+
+	result.Scan, err = h.scanResults(ctx, ref, request.GetInt("max_vulnerabilities", defaultVulnerabilities), request.GetBool("only_fixable", false))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func toPlatform(name, digest string, m manifest) platform {
+	p := platform{Platform: name, Digest: digest, Layers: []layer{}}
+	var total int64
+	for _, l := range m.Layers {
+		total += l.Size
+		p.Layers = append(p.Layers, layer{Digest: l.Digest, Size: formatSize(l.Size)})
+	}
+	p.Size = formatSize(total)
+	return p
+}
+
+// manifestRequest requests a manifest of the image by tag or digest from
+// the registry.
+func (h *handlers) manifestRequest(ctx context.Context, method string, ref imageRef, reference string) (*http.Response, error) {
+	ts, err := auth.TokenSource(ctx, h.c)
+	if err != nil {
+		return nil, err
+	}
+	token, err := ts.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get an access token: %w", err)
+	}
+	u := fmt.Sprintf("https://%s/v2/%s/%s/%s/manifests/%s", ref.Host, ref.Project, ref.Repository, ref.Image, reference)
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth("oauth2accesstoken", token.AccessToken)
+	req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	return http.DefaultClient.Do(req)
+}
+
+// fetchManifest gets a manifest of the image by tag or digest from the
+// registry, and returns it with its digest.
+func (h *handlers) fetchManifest(ctx context.Context, ref imageRef, reference string) (manifest, string, error) {
+	resp, err := h.manifestRequest(ctx, http.MethodGet, ref, reference)
+	if err != nil {
+		return manifest{}, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return manifest{}, "", fmt.Errorf("failed to get manifest %s of %s: %s: %s", reference, ref.URI(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	var m manifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return manifest{}, "", fmt.Errorf("failed to decode manifest %s of %s: %w", reference, ref.URI(), err)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = reference
+	}
+	return m, digest, nil
+}
+
+// scanResults gets the vulnerability scan results of an image, listing at
+// most limit vulnerabilities.
+func (h *handlers) scanResults(ctx context.Context, ref imageRef, limit int, onlyFixable bool) (*scanInfo, error) {
+	opts, err := auth.ClientOptions(ctx, h.c, config.APIContainerAnalysis)
+	if err != nil {
+		return nil, err
+	}
+	client, err := containeranalysis.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create container analysis client: %w", err)
+	}
+	defer client.Close()
+
+	var occurrences []*grafeaspb.Occurrence
+	it := client.GetGrafeasClient().ListOccurrences(ctx, &grafeaspb.ListOccurrencesRequest{
+		Parent: "projects/" + ref.Project,
+		Filter: fmt.Sprintf(`resourceUrl="https://%s@%s" AND (kind="VULNERABILITY" OR kind="DISCOVERY")`, ref.URI(), ref.Digest),
+	})
+	for {
+		o, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the scan results of %s: %w", ref.URI(), err)
+		}
+		occurrences = append(occurrences, o)
+	}
+	return summarizeScan(occurrences, limit, onlyFixable), nil
+}
+
+// summarizeScan sums up the vulnerability and discovery occurrences of an
+// image, listing the limit most severe vulnerabilities.
+func summarizeScan(occurrences []*grafeaspb.Occurrence, limit int, onlyFixable bool) *scanInfo {
+	info := &scanInfo{Status: "not scanned", Counts: map[string]int{}}
+	var vulns []vulnerability
+	for _, o := range occurrences {
+		if d := o.GetDiscovery(); d != nil {
+			info.Status = d.GetAnalysisStatus().String()
+			info.LastScan = formatTime(d.GetLastScanTime())
+			continue
+		}
+		v := o.GetVulnerability()
+		if v == nil {
+			continue
+		}
+		entry := vulnerability{
+			ID:       path.Base(o.GetNoteName()),
+			Severity: v.GetEffectiveSeverity().String(),
+			CVSS:     v.GetCvssScore(),
+			Summary:  v.GetShortDescription(),
+			severity: v.GetEffectiveSeverity(),
+			fixable:  v.GetFixAvailable(),
+		}
+		if issues := v.GetPackageIssue(); len(issues) > 0 {
+			entry.Package = issues[0].GetAffectedPackage()
+			entry.Version = issues[0].GetAffectedVersion().GetFullName()
+			if issues[0].GetFixAvailable() {
+				entry.FixedIn = issues[0].GetFixedVersion().GetFullName()
+			}
+		}
+		info.Counts[entry.Severity]++
+		if entry.fixable {
+			info.Fixable++
+		}
+		if !onlyFixable || entry.fixable {
+			vulns = append(vulns, entry)
+		}
+	}
+	sort.SliceStable(vulns, func(i, j int) bool {
+		if vulns[i].severity != vulns[j].severity {
+			return vulns[i].severity > vulns[j].severity
+		}
+		return vulns[i].CVSS > vulns[j].CVSS
+	})
+	info.Vulnerabilities = vulns[:min(len(vulns), limit)]
+	return info
+}
+
+func (h *handlers) getImageDetailsCommands(_ context.Context, request mcp.CallToolRequest) []string {
+	ref, err := parseImage(request.GetString("image", ""))
+	if err != nil {
+		return nil
+	}
+	image := ref.URI() + ":" + ref.Tag
+	if ref.Digest != "" {
+		image = ref.URI() + "@" + ref.Digest
+	}
+	return []string{
+		explain.Join("gcloud artifacts docker images describe", image, "--show-package-vulnerability"),
+		explain.Join("docker manifest inspect --verbose", image),
+	}
+}
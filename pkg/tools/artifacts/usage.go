@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/scan"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// usage is the result of find_image_usage.
+type usage struct {
+	Digest   string         `json:"digest"`
+	Clusters []clusterUsage `json:"clusters"`
+	// Unreachable lists the clusters that couldn't be searched.
+	Unreachable []clusterUsage `json:"unreachable,omitempty"`
+}
+
+type clusterUsage struct {
+	Cluster   string   `json:"cluster"`
+	Workloads []string `json:"workloads,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func (h *handlers) findImageUsage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	image, err := request.RequireString("image")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	digest := kube.Digest(image)
+	if digest == "" {
+		return mcp.NewToolResultError("image must be a digest or an image reference with a digest; use get_image_details to resolve a tag"), nil
+	}
+	// A bare digest matches any repository.
+	var repo string
+	if digest != image {
+		repo = kube.Repository(image)
+	}
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := request.GetString("location", "-")
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resp, err := cmClient.ListClusters(ctx, &containerpb.ListClustersRequest{Parent: fmt.Sprintf("projects/%s/locations/%s", projectID, location)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	namespace := request.GetString("namespace", "")
+	results := scan.Run(ctx, resp.GetClusters(), scan.DefaultWorkers, func(ctx context.Context, cluster *containerpb.Cluster) ([]string, error) {
+		if s := cluster.GetStatus(); s != containerpb.Cluster_RUNNING && s != containerpb.Cluster_RECONCILING {
+			return nil, fmt.Errorf("cluster is %s", s)
+		}
+		k, err := gcp.Kubernetes(ctx, h.c, projectID, cluster.GetLocation(), cluster.GetName())
+		if err != nil {
+			return nil, err
+		}
+		containers, err := k.Containers(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return workloadsRunning(containers, repo, digest), nil
+	})
+
+	result := usage{Digest: digest, Clusters: []clusterUsage{}}
+	for _, r := range results {
+		name := r.Target.GetLocation() + "/" + r.Target.GetName()
+		switch {
+		case r.Err != nil:
+			result.Unreachable = append(result.Unreachable, clusterUsage{Cluster: name, Error: r.Err.Error()})
+		case len(r.Value) > 0:
+			result.Clusters = append(result.Clusters, clusterUsage{Cluster: name, Workloads: r.Value})
+		}
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s\n\nSearched %d clusters of project %s.", data, len(results), projectID)), nil
+}
+
+// workloadsRunning returns the workloads with a container running the image
+// digest of repo, or of any repository if repo is empty.
+func workloadsRunning(containers []kube.Container, repo, digest string) []string {
+	var workloads []string
+	for _, c := range containers {
+		if c.Digest != digest || (repo != "" && c.Repository != repo) {
+			continue
+		}
+		if !slices.Contains(workloads, c.Workload) {
+			workloads = append(workloads, c.Workload)
+		}
+	}
+	slices.Sort(workloads)
+	return workloads
+}
+
+func (h *handlers) findImageUsageCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, image := session.ProjectID(ctx, request, h.c), request.GetString("image", "")
+	digest := kube.Digest(image)
+	if projectID == "" || digest == "" {
+		return nil
+	}
+	namespace := explain.Flag("namespace", request.GetString("namespace", ""))
+	if namespace == "" {
+		namespace = "--all-namespaces"
+	}
+	return []string{
+		explain.Join("gcloud container clusters list", explain.Flag("location", request.GetString("location", "")), explain.Flag("project", projectID)),
+		"# For each cluster:",
+		explain.Join("kubectl get pods", namespace, `--output=jsonpath={range .items[*]}{.metadata.namespace}/{.metadata.name}{" "}{.status.containerStatuses[*].imageID}{"\n"}{end}`) + " | grep " + explain.Quote(digest),
+	}
+}
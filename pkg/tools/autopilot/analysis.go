@@ -0,0 +1,442 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autopilot
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+)
+
+// Severities of the findings.
+const (
+	// blocker findings can't run on Autopilot as they are.
+	blocker = "blocker"
+	// change findings need a change to their manifests.
+	change = "change"
+	// info findings run on Autopilot but behave or are billed differently.
+	info = "info"
+)
+
+// pod is the part of a pod analyzed.
+type pod struct {
+	Metadata struct {
+		Name            string                `json:"name"`
+		Namespace       string                `json:"namespace"`
+		Labels          map[string]string     `json:"labels"`
+		OwnerReferences []kube.OwnerReference `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeSelector map[string]string `json:"nodeSelector"`
+		Affinity     *struct {
+			NodeAffinity *struct {
+				Required *struct {
+					NodeSelectorTerms []struct {
+						MatchExpressions []struct {
+							Key    string   `json:"key"`
+							Values []string `json:"values"`
+						} `json:"matchExpressions"`
+					} `json:"nodeSelectorTerms"`
+				} `json:"requiredDuringSchedulingIgnoredDuringExecution"`
+			} `json:"nodeAffinity"`
+		} `json:"affinity"`
+		HostNetwork bool `json:"hostNetwork"`
+		HostPID     bool `json:"hostPID"`
+		HostIPC     bool `json:"hostIPC"`
+		Volumes     []struct {
+			Name     string `json:"name"`
+			HostPath *struct {
+				Path string `json:"path"`
+			} `json:"hostPath"`
+		} `json:"volumes"`
+		InitContainers []containerSpec `json:"initContainers"`
+		Containers     []containerSpec `json:"containers"`
+	} `json:"spec"`
+}
+
+type containerSpec struct {
+	Name  string `json:"name"`
+	Ports []struct {
+		HostPort int `json:"hostPort"`
+	} `json:"ports"`
+	SecurityContext *struct {
+		Privileged   *bool `json:"privileged"`
+		Capabilities *struct {
+			Add []string `json:"add"`
+		} `json:"capabilities"`
+	} `json:"securityContext"`
+	Resources struct {
+		Requests map[string]string `json:"requests"`
+		Limits   map[string]string `json:"limits"`
+	} `json:"resources"`
+	VolumeMounts []struct {
+		Name     string `json:"name"`
+		ReadOnly bool   `json:"readOnly"`
+	} `json:"volumeMounts"`
+}
+
+// node is the part of a node used to estimate its cost.
+type node struct {
+	Metadata struct {
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Status struct {
+		Capacity map[string]string `json:"capacity"`
+	} `json:"status"`
+}
+
+func (p pod) workload() string {
+	m := p.Metadata
+	return kube.Workload(m.Namespace, m.Name, m.Labels, m.OwnerReferences)
+}
+
+// managed reports whether the pod runs in a namespace managed by GKE, which
+// Autopilot runs and doesn't bill.
+func (p pod) managed() bool {
+	ns := p.Metadata.Namespace
+	return ns == "kube-system" || strings.HasPrefix(ns, "gke-") || strings.HasPrefix(ns, "gmp-")
+}
+
+// nodeSelectors returns the node labels the pod selects.
+func (p pod) nodeSelectors() map[string]string {
+	selectors := map[string]string{}
+	for k, v := range p.Spec.NodeSelector {
+		selectors[k] = v
+	}
+	if a := p.Spec.Affinity; a != nil && a.NodeAffinity != nil && a.NodeAffinity.Required != nil {
+		for _, term := range a.NodeAffinity.Required.NodeSelectorTerms {
+			for _, e := range term.MatchExpressions {
+				selectors[e.Key] = strings.Join(e.Values, ",")
+			}
+		}
+	}
+	return selectors
+}
+
+// allowedCapabilities are the capabilities Autopilot lets containers add.
+var allowedCapabilities = []string{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD", "NET_BIND_SERVICE", "NET_RAW", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT", "SYS_PTRACE"}
+
+// allowedSelectors are the node labels Autopilot lets pods select.
+var allowedSelectors = []string{
+	"cloud.google.com/compute-class",
+	"cloud.google.com/gke-spot",
+	"cloud.google.com/gke-accelerator",
+	"cloud.google.com/gke-accelerator-count",
+	"cloud.google.com/gke-tpu-accelerator",
+	"cloud.google.com/gke-tpu-topology",
+	"cloud.google.com/machine-family",
+	"cloud.google.com/gke-placement-group",
+	"cloud.google.com/gke-ephemeral-storage-local-ssd",
+	"cloud.google.com/reservation-name",
+	"cloud.google.com/reservation-affinity",
+	"kubernetes.io/arch",
+	"kubernetes.io/os",
+	"topology.kubernetes.io/region",
+	"topology.kubernetes.io/zone",
+	"failure-domain.beta.kubernetes.io/region",
+	"failure-domain.beta.kubernetes.io/zone",
+}
+
+// gpuTypes are the GPU types Autopilot runs.
+var gpuTypes = []string{"nvidia-tesla-t4", "nvidia-l4", "nvidia-tesla-a100", "nvidia-a100-80gb", "nvidia-h100-80gb", "nvidia-h100-mega-80gb", "nvidia-h200-141gb", "nvidia-b200"}
+
+// issue is a reason a pod needs attention to run on Autopilot.
+type issue struct {
+	Severity string
+	Check    string
+	Message  string
+}
+
+// checkPod returns the issues of a pod.
+func checkPod(p pod) []issue {
+	var issues []issue
+	add := func(severity, check, format string, args ...any) {
+		issues = append(issues, issue{Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if p.Spec.HostNetwork || p.Spec.HostPID || p.Spec.HostIPC {
+		add(blocker, "host-namespaces", "Uses the host network, PID or IPC namespace, which Autopilot doesn't allow.")
+	}
+	readOnly := map[string]bool{}
+	containers := slices.Concat(p.Spec.InitContainers, p.Spec.Containers)
+	for _, c := range containers {
+		for _, m := range c.VolumeMounts {
+			if _, ok := readOnly[m.Name]; !ok {
+				readOnly[m.Name] = true
+			}
+			readOnly[m.Name] = readOnly[m.Name] && m.ReadOnly
+		}
+	}
+	for _, v := range p.Spec.Volumes {
+		if v.HostPath == nil {
+			continue
+		}
+		if logs := path.Clean(v.HostPath.Path); (logs == "/var/log" || strings.HasPrefix(logs, "/var/log/")) && readOnly[v.Name] {
+			continue
+		}
+		add(blocker, "host-path", "Mounts the host path %s; Autopilot only allows mounting /var/log read-only.", v.HostPath.Path)
+	}
+
+	gpus := false
+	for i, c := range containers {
+		if sc := c.SecurityContext; sc != nil {
+			if sc.Privileged != nil && *sc.Privileged {
+				add(blocker, "privileged", "Container %s is privileged, which Autopilot doesn't allow outside of allowlisted partner workloads.", c.Name)
+			}
+			if sc.Capabilities != nil {
+				for _, capability := range sc.Capabilities.Add {
+					if !slices.Contains(allowedCapabilities, strings.TrimPrefix(capability, "CAP_")) {
+						add(blocker, "capabilities", "Container %s adds the %s capability, which Autopilot doesn't allow.", c.Name, capability)
+					}
+				}
+			}
+		}
+		for _, port := range c.Ports {
+			if port.HostPort != 0 {
+				add(change, "host-port", "Container %s uses host port %d; expose it with a Service instead.", c.Name, port.HostPort)
+				break
+			}
+		}
+		if _, ok := c.Resources.Requests["nvidia.com/gpu"]; ok {
+			gpus = true
+		} else if _, ok := c.Resources.Limits["nvidia.com/gpu"]; ok {
+			gpus = true
+		}
+		_, cpu := c.Resources.Requests["cpu"]
+		_, memory := c.Resources.Requests["memory"]
+		if !cpu && !memory && len(c.Resources.Limits) == 0 && i >= len(p.Spec.InitContainers) {
+			add(info, "requests", "Container %s sets no resource requests; Autopilot requests 0.5 vCPU and 2 GiB for it, which it bills.", c.Name)
+		}
+	}
+
+	selectors := p.nodeSelectors()
+	keys := make([]string, 0, len(selectors))
+	for k := range selectors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := selectors[k]
+		switch {
+		case k == "kubernetes.io/os" && v == "windows":
+			add(blocker, "windows", "Runs on Windows nodes, which Autopilot doesn't support.")
+		case k == "cloud.google.com/gke-accelerator":
+			for _, gpu := range strings.Split(v, ",") {
+				if !slices.Contains(gpuTypes, gpu) {
+					add(blocker, "gpu-type", "Requests %s GPUs, which Autopilot doesn't offer.", gpu)
+				}
+			}
+		case slices.Contains(allowedSelectors, k):
+		case strings.Contains(k, "kubernetes.io/") || strings.HasPrefix(k, "cloud.google.com/"):
+			add(change, "node-selector", "Selects nodes by %s, which Autopilot manages; select a compute class or machine family instead.", k)
+		default:
+			add(change, "node-selector", "Selects nodes by the custom label %s; on Autopilot this needs workload separation, with a toleration for the same key.", k)
+		}
+	}
+	if _, ok := selectors["cloud.google.com/gke-accelerator"]; gpus && !ok {
+		add(change, "gpu-selector", "Requests GPUs without selecting their type; add a cloud.google.com/gke-accelerator node selector.")
+	}
+
+	if owners := p.Metadata.OwnerReferences; len(owners) > 0 && owners[0].Kind == "DaemonSet" {
+		add(info, "daemonset", "DaemonSet pods run on every Autopilot node, which Autopilot sizes and adds by itself, and are billed like other pods.")
+	}
+	return issues
+}
+
+// podResources returns the vCPUs and GiB of memory Autopilot bills for a
+// pod, applying its defaults, minimums and memory to CPU ratio.
+func podResources(p pod) (float64, float64) {
+	var cpu, memory float64
+	for _, c := range p.Spec.Containers {
+		ccpu, cmem := quantity(c, "cpu"), quantity(c, "memory")/(1<<30)
+		if ccpu == 0 && cmem == 0 {
+			ccpu, cmem = pricing.AutopilotDefaults.CPU, pricing.AutopilotDefaults.Memory
+		}
+		cpu += ccpu
+		memory += cmem
+	}
+	return pricing.AutopilotResources(cpu, memory)
+}
+
+// quantity returns the request of a resource of a container, defaulting to
+// its limit.
+func quantity(c containerSpec, resource string) float64 {
+	q, ok := c.Resources.Requests[resource]
+	if !ok {
+		q = c.Resources.Limits[resource]
+	}
+	v, _ := kube.ParseQuantity(q)
+	return v
+}
+
+// nodePrice returns the hourly price of a node, and false if its machine
+// family isn't known, in which case it's priced like N2.
+func nodePrice(prices *pricing.Prices, n node) (float64, bool) {
+	r, ok := prices.Machine(n.Metadata.Labels["node.kubernetes.io/instance-type"], false)
+	cpu, _ := kube.ParseQuantity(n.Status.Capacity["cpu"])
+	memory, _ := kube.ParseQuantity(n.Status.Capacity["memory"])
+	return r.Price(cpu, memory/(1<<30)), ok
+}
+
+// report is the result of autopilot_migration_report.
+type report struct {
+	Feasibility string    `json:"feasibility"`
+	Blockers    int       `json:"blockers"`
+	Changes     int       `json:"changes"`
+	Cost        costs     `json:"estimatedMonthlyCost"`
+	Findings    []finding `json:"findings"`
+
+	seen map[finding]bool
+}
+
+type costs struct {
+	Currency  string  `json:"currency"`
+	Standard  float64 `json:"standardNodes"`
+	Autopilot float64 `json:"autopilotPods"`
+	Delta     float64 `json:"delta"`
+	// Notes lists the assumptions of the estimate.
+	Notes []string `json:"notes"`
+}
+
+type finding struct {
+	Workload string `json:"workload"`
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+// add adds a finding, unless the workload already has it.
+func (r *report) add(workload, severity, check, message string) {
+	f := finding{Workload: workload, Severity: severity, Check: check, Message: message}
+	if r.seen[f] {
+		return
+	}
+	r.seen[f] = true
+	r.Findings = append(r.Findings, f)
+	switch severity {
+	case blocker:
+		r.Blockers++
+	case change:
+		r.Changes++
+	}
+	r.Feasibility = "feasible"
+	if r.Changes > 0 {
+		r.Feasibility = "feasible with changes"
+	}
+	if r.Blockers > 0 {
+		r.Feasibility = "blocked"
+	}
+}
+
+// sort orders the findings by severity, then workload.
+func (r *report) sort() {
+	rank := map[string]int{blocker: 0, change: 1, info: 2}
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.Severity != b.Severity {
+			return rank[a.Severity] < rank[b.Severity]
+		}
+		return a.Workload < b.Workload
+	})
+}
+
+// analyze checks the pods against the constraints of Autopilot, and
+// estimates the cost of running them on Autopilot rather than on the nodes.
+func analyze(pods []pod, nodes []node, prices *pricing.Prices) *report {
+	r := &report{Feasibility: "feasible", Findings: []finding{}, seen: map[finding]bool{}}
+	var cpu, memory float64
+	for _, p := range pods {
+		if p.managed() {
+			continue
+		}
+		for _, i := range checkPod(p) {
+			r.add(p.workload(), i.Severity, i.Check, i.Message)
+		}
+		c, m := podResources(p)
+		cpu += c
+		memory += m
+	}
+
+	var standard float64
+	var unknown int
+	for _, n := range nodes {
+		p, ok := nodePrice(prices, n)
+		standard += p
+		if !ok {
+			unknown++
+		}
+	}
+	r.Cost = costs{
+		Currency:  pricing.Currency,
+		Standard:  pricing.Monthly(standard),
+		Autopilot: pricing.Monthly(prices.Autopilot(false).Price(cpu, memory)),
+		Notes: []string{
+			prices.Note,
+			"Autopilot bills the requests of the running pods, with its defaults and minimums applied, and not the system pods.",
+		},
+	}
+	r.Cost.Delta = pricing.Round(r.Cost.Autopilot - r.Cost.Standard)
+	if unknown > 0 {
+		r.Cost.Notes = append(r.Cost.Notes, fmt.Sprintf("%d nodes of an unknown machine family are priced like N2.", unknown))
+	}
+	return r
+}
+
+// summary sums the report up in a sentence.
+func (r *report) summary(cluster string) string {
+	s := fmt.Sprintf("Migrating cluster %s to Autopilot is %s: %d blockers and %d changes needed.", cluster, r.Feasibility, r.Blockers, r.Changes)
+	direction := "more"
+	if r.Cost.Delta < 0 {
+		direction = "less"
+	}
+	return s + fmt.Sprintf(" Autopilot would cost an estimated %.2f %s a month %s than the current nodes.", math.Abs(r.Cost.Delta), r.Cost.Currency, direction)
+}
+
+// findingList is the structured result of autopilot_migration_report,
+// following the findings schema.
+type findingList struct {
+	Findings []structuredFinding `json:"findings"`
+}
+
+type structuredFinding struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Category    string `json:"category"`
+	Priority    string `json:"priority"`
+	Resource    string `json:"resource"`
+}
+
+func (r *report) findingList() findingList {
+	priorities := map[string]string{blocker: "P1", change: "P2", info: "P4"}
+	list := findingList{Findings: []structuredFinding{}}
+	for _, f := range r.Findings {
+		list.Findings = append(list.Findings, structuredFinding{
+			Name:        "autopilot/" + f.Check + "/" + f.Workload,
+			Description: f.Message,
+			Type:        f.Check,
+			Category:    "COMPATIBILITY",
+			Priority:    priorities[f.Severity],
+			Resource:    f.Workload,
+		})
+	}
+	return list
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autopilot
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/google/go-cmp/cmp"
+)
+
+func parsePod(t *testing.T, data string) pod {
+	t.Helper()
+	var p pod
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestCheckPod(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  string
+		want []issue
+	}{
+		{
+			name: "compatible",
+			pod:  `{"spec":{"nodeSelector":{"kubernetes.io/arch":"arm64"},"containers":[{"name":"web","resources":{"requests":{"cpu":"1"}}}]}}`,
+		},
+		{
+			name: "privileged with host path",
+			pod: `{"spec":{"hostNetwork":true,"volumes":[{"name":"root","hostPath":{"path":"/"}},{"name":"logs","hostPath":{"path":"/var/log"}}],
+				"containers":[{"name":"agent","securityContext":{"privileged":true,"capabilities":{"add":["NET_RAW","SYS_ADMIN"]}},
+				"volumeMounts":[{"name":"root"},{"name":"logs","readOnly":true}],"resources":{"requests":{"cpu":"100m"}}}]}}`,
+			want: []issue{
+				{Severity: blocker, Check: "host-namespaces", Message: "Uses the host network, PID or IPC namespace, which Autopilot doesn't allow."},
+				{Severity: blocker, Check: "host-path", Message: "Mounts the host path /; Autopilot only allows mounting /var/log read-only."},
+				{Severity: blocker, Check: "privileged", Message: "Container agent is privileged, which Autopilot doesn't allow outside of allowlisted partner workloads."},
+				{Severity: blocker, Check: "capabilities", Message: "Container agent adds the SYS_ADMIN capability, which Autopilot doesn't allow."},
+			},
+		},
+		{
+			name: "node selectors",
+			pod: `{"spec":{"nodeSelector":{"cloud.google.com/gke-nodepool":"pool-1","team":"data"},
+				"containers":[{"name":"job","ports":[{"containerPort":80,"hostPort":8080}],"resources":{"limits":{"memory":"1Gi"}}}]}}`,
+			want: []issue{
+				{Severity: change, Check: "host-port", Message: "Container job uses host port 8080; expose it with a Service instead."},
+				{Severity: change, Check: "node-selector", Message: "Selects nodes by cloud.google.com/gke-nodepool, which Autopilot manages; select a compute class or machine family instead."},
+				{Severity: change, Check: "node-selector", Message: "Selects nodes by the custom label team; on Autopilot this needs workload separation, with a toleration for the same key."},
+			},
+		},
+		{
+			name: "gpus",
+			pod: `{"spec":{"affinity":{"nodeAffinity":{"requiredDuringSchedulingIgnoredDuringExecution":{"nodeSelectorTerms":[{"matchExpressions":[{"key":"cloud.google.com/gke-accelerator","operator":"In","values":["nvidia-tesla-v100"]}]}]}}},
+				"containers":[{"name":"train","resources":{"limits":{"nvidia.com/gpu":"1"}}}]}}`,
+			want: []issue{
+				{Severity: blocker, Check: "gpu-type", Message: "Requests nvidia-tesla-v100 GPUs, which Autopilot doesn't offer."},
+			},
+		},
+		{
+			name: "daemonset without requests",
+			pod: `{"metadata":{"ownerReferences":[{"kind":"DaemonSet","name":"agent"}]},
+				"spec":{"initContainers":[{"name":"init"}],"containers":[{"name":"agent"}]}}`,
+			want: []issue{
+				{Severity: info, Check: "requests", Message: "Container agent sets no resource requests; Autopilot requests 0.5 vCPU and 2 GiB for it, which it bills."},
+				{Severity: info, Check: "daemonset", Message: "DaemonSet pods run on every Autopilot node, which Autopilot sizes and adds by itself, and are billed like other pods."},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, checkPod(parsePod(t, tc.pod))); diff != "" {
+				t.Errorf("checkPod() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPodResources(t *testing.T) {
+	tests := []struct {
+		name             string
+		pod              string
+		wantCPU, wantMem float64
+	}{
+		{name: "defaults", pod: `{"spec":{"containers":[{"name":"a"}]}}`, wantCPU: 0.5, wantMem: 2},
+		{name: "minimums", pod: `{"spec":{"containers":[{"name":"a","resources":{"requests":{"cpu":"10m","memory":"10Mi"}}}]}}`, wantCPU: 0.05, wantMem: 52.0 / 1024},
+		{name: "memory ratio", pod: `{"spec":{"containers":[{"name":"a","resources":{"requests":{"cpu":"1","memory":"13Gi"}}}]}}`, wantCPU: 2, wantMem: 13},
+		{name: "limits", pod: `{"spec":{"containers":[{"name":"a","resources":{"limits":{"cpu":"2","memory":"4Gi"}}},{"name":"b","resources":{"requests":{"cpu":"2","memory":"4Gi"}}}]}}`, wantCPU: 4, wantMem: 8},
+	}
+	for _, tc := range tests {
+		cpu, memory := podResources(parsePod(t, tc.pod))
+		if math.Abs(cpu-tc.wantCPU) > 1e-9 || math.Abs(memory-tc.wantMem) > 1e-9 {
+			t.Errorf("%s: podResources() = %v, %v, want %v, %v", tc.name, cpu, memory, tc.wantCPU, tc.wantMem)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	pods := []pod{
+		parsePod(t, `{"metadata":{"name":"web-1","namespace":"prod","labels":{"pod-template-hash":"1"},"ownerReferences":[{"kind":"ReplicaSet","name":"web-1"}]},
+			"spec":{"containers":[{"name":"web","ports":[{"hostPort":80}],"resources":{"requests":{"cpu":"1","memory":"4Gi"}}}]}}`),
+		parsePod(t, `{"metadata":{"name":"web-2","namespace":"prod","labels":{"pod-template-hash":"1"},"ownerReferences":[{"kind":"ReplicaSet","name":"web-1"}]},
+			"spec":{"containers":[{"name":"web","ports":[{"hostPort":80}],"resources":{"requests":{"cpu":"1","memory":"4Gi"}}}]}}`),
+		parsePod(t, `{"metadata":{"name":"kube-dns","namespace":"kube-system"},"spec":{"hostNetwork":true,"containers":[{"name":"dns"}]}}`),
+	}
+	nodes := []node{{}, {}}
+	for i := range nodes {
+		nodes[i].Metadata.Labels = map[string]string{"node.kubernetes.io/instance-type": "e2-standard-4"}
+		nodes[i].Status.Capacity = map[string]string{"cpu": "4", "memory": "16Gi"}
+	}
+	nodes[1].Metadata.Labels["node.kubernetes.io/instance-type"] = "x9-standard-4"
+
+	r := analyze(pods, nodes, pricing.Embedded)
+	want := []finding{{Workload: "prod/Deployment/web", Severity: change, Check: "host-port", Message: "Container web uses host port 80; expose it with a Service instead."}}
+	if diff := cmp.Diff(want, r.Findings); diff != "" {
+		t.Errorf("analyze() findings mismatch (-want +got):\n%s", diff)
+	}
+	if r.Feasibility != "feasible with changes" || r.Blockers != 0 || r.Changes != 1 {
+		t.Errorf("analyze() = %s with %d blockers and %d changes, want feasible with changes, 0 and 1", r.Feasibility, r.Blockers, r.Changes)
+	}
+	wantStandard := pricing.Round((4*0.021811 + 16*0.002923 + 4*0.031611 + 16*0.004237) * pricing.HoursPerMonth)
+	wantAutopilot := pricing.Round((2*0.0445 + 8*0.0049225) * pricing.HoursPerMonth)
+	if r.Cost.Standard != wantStandard || r.Cost.Autopilot != wantAutopilot {
+		t.Errorf("analyze() cost = %v standard, %v autopilot, want %v, %v", r.Cost.Standard, r.Cost.Autopilot, wantStandard, wantAutopilot)
+	}
+	if len(r.Cost.Notes) != 3 {
+		t.Errorf("analyze() cost notes = %q, want a note about the unknown machine family", r.Cost.Notes)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autopilot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("autopilot", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	autopilotMigrationReportTool := mcp.NewTool("autopilot_migration_report",
+		mcp.WithDescription("Analyze the workloads of a GKE Standard cluster against the constraints of Autopilot, such as privileged pods, hostPath volumes, host namespaces, capabilities, node selectors, DaemonSets and GPU types, and report whether migrating is feasible, the changes each workload needs and an estimate of the cost difference."),
+		catalog.Describe(catalog.Optimization, catalog.Read, "container.clusters.get", "container.pods.list", "container.nodes.list"),
+		explain.Command(h.autopilotMigrationReportCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE Standard cluster name. Defaults to the session context.")),
+	)
+	s.AddTool(autopilotMigrationReportTool, h.autopilotMigrationReport)
+
+	return nil
+}
+
+func (h *handlers) autopilotMigrationReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if cluster.GetAutopilot().GetEnabled() {
+		return mcp.NewToolResultError(fmt.Sprintf("cluster %s already runs in Autopilot mode", name)), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var pods struct {
+		Items []pod `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/pods?fieldSelector=status.phase%3DRunning", &pods); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var nodes struct {
+		Items []node `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/nodes", &nodes); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	r := analyze(pods.Items, nodes.Items, pricing.ForLocation(ctx, h.c, location))
+	for _, np := range cluster.GetNodePools() {
+		if strings.HasPrefix(strings.ToUpper(np.GetConfig().GetImageType()), "WINDOWS") {
+			r.add("node pool "+np.GetName(), blocker, "windows", "Autopilot doesn't run Windows nodes.")
+		}
+	}
+	r.sort()
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(r.summary(name)+"\n\n"+string(data), structured.Findings, r.findingList())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) autopilotMigrationReportCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get pods --all-namespaces --field-selector=status.phase=Running --output=yaml",
+		"kubectl get nodes --label-columns=node.kubernetes.io/instance-type,cloud.google.com/gke-spot",
+	}
+}
@@ -0,0 +1,434 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cloudbuild "cloud.google.com/go/cloudbuild/apiv1/v2"
+	"cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	logging "cloud.google.com/go/logging/apiv2"
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultMaxBuilds = 100
+	maxMaxBuilds     = 500
+	defaultLogLines  = 20
+	// maxLaterBuilds is the number of builds listed after the one that
+	// produced a deployed image.
+	maxLaterBuilds = 5
+	// maxFailedLogs is the number of failed builds whose logs are read.
+	maxFailedLogs = 3
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("builds", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	listImageBuildsTool := mcp.NewTool("list_image_builds",
+		mcp.WithDescription("List the images running in a GKE cluster by digest, with the Cloud Build run that produced each one and the later builds of the same image, including the failure and the end of the logs of failed builds. Use it to answer why a cluster runs an old image: a later build failed, or succeeded but was never deployed."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.get", "container.pods.list", "cloudbuild.builds.list", "logging.logEntries.list"),
+		explain.Command(h.listImageBuildsCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("namespace", mcp.Description("Only list the images running in this namespace. Leave empty for all namespaces.")),
+		mcp.WithString("image", mcp.Description("Only list the images whose repository contains this string, e.g. the image name.")),
+		mcp.WithString("build_project", mcp.Description("Project the images are built in. Defaults to project_id.")),
+		mcp.WithString("build_region", mcp.Description("Region of the Cloud Build builds, for builds that use regional pools or triggers. Defaults to global builds.")),
+		mcp.WithNumber("max_builds", mcp.Description(fmt.Sprintf("Number of recent builds searched, newest first. Defaults to %d, at most %d.", defaultMaxBuilds, maxMaxBuilds))),
+		mcp.WithNumber("log_lines", mcp.Description(fmt.Sprintf("Number of log lines shown from the end of failed builds. Defaults to %d; 0 shows none.", defaultLogLines))),
+		governor.FullOption(),
+	)
+	s.AddTool(listImageBuildsTool, h.listImageBuilds)
+
+	return nil
+}
+
+// image is the tool result entry of an image running in the cluster.
+type image struct {
+	Repository string   `json:"repository"`
+	Digest     string   `json:"digest"`
+	Workloads  []string `json:"workloads"`
+	// Status sums up the builds of the image.
+	Status      string  `json:"status"`
+	BuiltBy     *build  `json:"builtBy,omitempty"`
+	LaterBuilds []build `json:"laterBuilds,omitempty"`
+}
+
+type build struct {
+	ID       string   `json:"id"`
+	Status   string   `json:"status"`
+	Created  string   `json:"created"`
+	Finished string   `json:"finished,omitempty"`
+	Trigger  string   `json:"trigger,omitempty"`
+	Source   string   `json:"source,omitempty"`
+	Digest   string   `json:"digest,omitempty"`
+	Failure  string   `json:"failure,omitempty"`
+	LogURL   string   `json:"logUrl,omitempty"`
+	Logs     []string `json:"logs,omitempty"`
+
+	failed bool
+}
+
+func (h *handlers) listImageBuilds(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	cluster := session.Cluster(ctx, request, h.c, "cluster")
+	if cluster == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	maxBuilds := request.GetInt("max_builds", defaultMaxBuilds)
+	if maxBuilds <= 0 || maxBuilds > maxMaxBuilds {
+		return mcp.NewToolResultError(fmt.Sprintf("max_builds must be between 1 and %d", maxMaxBuilds)), nil
+	}
+	buildProject := request.GetString("build_project", projectID)
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, cluster)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	containers, err := k.Containers(ctx, request.GetString("namespace", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	images := runningImages(containers, request.GetString("image", ""))
+	if len(images) == 0 {
+		return mcp.NewToolResultText("No running images found."), nil
+	}
+
+	builds, err := h.listBuilds(ctx, buildProject, request.GetString("build_region", ""), maxBuilds)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	history(images, builds)
+
+	if logLines := request.GetInt("log_lines", defaultLogLines); logLines > 0 {
+		if err := h.addFailedLogs(ctx, buildProject, images, logLines); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	data, err := json.MarshalIndent(images, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result := string(data)
+	if len(builds) == maxBuilds {
+		result += fmt.Sprintf("\n\nOnly the %d most recent builds of project %s were searched; raise max_builds to search older ones.", maxBuilds, buildProject)
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+func (h *handlers) listImageBuildsCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	namespace := explain.Flag("namespace", request.GetString("namespace", ""))
+	if namespace == "" {
+		namespace = "--all-namespaces"
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		explain.Join("kubectl get pods", namespace, `--output=jsonpath={range .items[*].status.containerStatuses[*]}{.imageID}{"\n"}{end}`),
+		explain.Join("gcloud builds list", explain.Flag("project", request.GetString("build_project", projectID)), explain.Flag("region", request.GetString("build_region", "")), explain.Flag("limit", strconv.Itoa(request.GetInt("max_builds", defaultMaxBuilds)))),
+	}
+}
+
+// runningImages returns the images the containers run, by repository and
+// digest, keeping the repositories containing filter.
+func runningImages(containers []kube.Container, filter string) []*image {
+	byKey := map[string]*image{}
+	for _, c := range containers {
+		if c.Digest == "" || !strings.Contains(c.Repository, filter) {
+			continue
+		}
+		key := c.Repository + "@" + c.Digest
+		img, ok := byKey[key]
+		if !ok {
+			img = &image{Repository: c.Repository, Digest: c.Digest}
+			byKey[key] = img
+		}
+		if !slices.Contains(img.Workloads, c.Workload) {
+			img.Workloads = append(img.Workloads, c.Workload)
+		}
+	}
+	images := make([]*image, 0, len(byKey))
+	for _, img := range byKey {
+		sort.Strings(img.Workloads)
+		images = append(images, img)
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Repository != images[j].Repository {
+			return images[i].Repository < images[j].Repository
+		}
+		return images[i].Digest < images[j].Digest
+	})
+	return images
+}
+
+// listBuilds gets the most recent builds of a project, newest first.
+func (h *handlers) listBuilds(ctx context.Context, projectID, region string, limit int) ([]*cloudbuildpb.Build, error) {
+	opts, err := auth.ClientOptions(ctx, h.c, config.APICloudBuild)
+	if err != nil {
+		return nil, err
+	}
+	client, err := cloudbuild.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cloud build client: %w", err)
+	}
+	defer client.Close()
+
+	req := &cloudbuildpb.ListBuildsRequest{ProjectId: projectID, PageSize: int32(min(limit, 100))}
+	if region != "" {
+		req.Parent = fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	}
+	var builds []*cloudbuildpb.Build
+	it := client.ListBuilds(ctx, req)
+	for len(builds) < limit {
+		b, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list builds of project %s: %w", projectID, err)
+		}
+		builds = append(builds, b)
+	}
+	return builds, nil
+}
+
+// history fills the builds of images from builds, newest first.
+func history(images []*image, builds []*cloudbuildpb.Build) {
+	for _, img := range images {
+		var later []*cloudbuildpb.Build
+		for _, b := range builds {
+			if !pushes(b, img.Repository) {
+				continue
+			}
+			if builtDigest(b, img.Repository) == img.Digest {
+				s := summarize(b, img.Repository)
+				img.BuiltBy = &s
+				break
+			}
+			later = append(later, b)
+		}
+		if img.BuiltBy == nil {
+			// The digest wasn't found, so nothing is known to be later.
+			later = later[:0]
+		}
+		for _, b := range later[:min(len(later), maxLaterBuilds)] {
+			img.LaterBuilds = append(img.LaterBuilds, summarize(b, img.Repository))
+		}
+		img.Status = status(img)
+	}
+}
+
+// pushes reports whether the build pushes an image of repository.
+func pushes(b *cloudbuildpb.Build, repo string) bool {
+	for _, name := range b.GetImages() {
+		if kube.Repository(name) == repo {
+			return true
+		}
+	}
+	for _, bi := range b.GetResults().GetImages() {
+		if kube.Repository(bi.GetName()) == repo {
+			return true
+		}
+	}
+	return false
+}
+
+// builtDigest returns the digest of the image of repository the build
+// pushed, or "" if it pushed none.
+func builtDigest(b *cloudbuildpb.Build, repo string) string {
+	for _, bi := range b.GetResults().GetImages() {
+		if kube.Repository(bi.GetName()) == repo {
+			return bi.GetDigest()
+		}
+	}
+	return ""
+}
+
+func summarize(b *cloudbuildpb.Build, repo string) build {
+	s := build{
+		ID:       b.GetId(),
+		Status:   b.GetStatus().String(),
+		Created:  formatTime(b.GetCreateTime()),
+		Finished: formatTime(b.GetFinishTime()),
+		Trigger:  b.GetSubstitutions()["TRIGGER_NAME"],
+		Digest:   builtDigest(b, repo),
+		LogURL:   b.GetLogUrl(),
+	}
+	if s.Trigger == "" {
+		s.Trigger = b.GetBuildTriggerId()
+	}
+	sub := b.GetSubstitutions()
+	if repoName := sub["REPO_NAME"]; repoName != "" {
+		s.Source = repoName
+		if branch := sub["BRANCH_NAME"]; branch != "" {
+			s.Source += "@" + branch
+		}
+		if commit := sub["SHORT_SHA"]; commit != "" {
+			s.Source += " " + commit
+		}
+	}
+	if failed(b.GetStatus()) {
+		s.failed = true
+		s.Failure = b.GetFailureInfo().GetDetail()
+		if s.Failure == "" {
+			s.Failure = b.GetStatusDetail()
+		}
+	}
+	return s
+}
+
+func failed(s cloudbuildpb.Build_Status) bool {
+	switch s {
+	case cloudbuildpb.Build_FAILURE, cloudbuildpb.Build_INTERNAL_ERROR, cloudbuildpb.Build_TIMEOUT, cloudbuildpb.Build_EXPIRED:
+		return true
+	}
+	return false
+}
+
+// status sums up whether the image running is the latest one built.
+func status(img *image) string {
+	if img.BuiltBy == nil {
+		return "not built by any of the builds searched"
+	}
+	if len(img.LaterBuilds) == 0 {
+		return "built by the latest build of the image"
+	}
+	for _, b := range img.LaterBuilds {
+		if b.Status == cloudbuildpb.Build_SUCCESS.String() && b.Digest != "" && b.Digest != img.Digest {
+			when := ""
+			if b.Finished != "" {
+				when = " at " + b.Finished
+			}
+			return fmt.Sprintf("outdated: build %s%s pushed a newer image that isn't deployed", b.ID, when)
+		}
+	}
+	latest := img.LaterBuilds[0]
+	if latest.failed {
+		return fmt.Sprintf("later builds didn't produce a newer image: the latest one, %s, ended in %s", latest.ID, latest.Status)
+	}
+	return fmt.Sprintf("later builds didn't produce a newer image yet: the latest one, %s, is %s", latest.ID, latest.Status)
+}
+
+// addFailedLogs adds the end of the logs of the first failed later builds.
+func (h *handlers) addFailedLogs(ctx context.Context, projectID string, images []*image, lines int) error {
+	var failedBuilds []*build
+	seen := map[string]bool{}
+	for _, img := range images {
+		for i := range img.LaterBuilds {
+			b := &img.LaterBuilds[i]
+			if !b.failed {
+				continue
+			}
+			if !seen[b.ID] && len(seen) == maxFailedLogs {
+				continue
+			}
+			seen[b.ID] = true
+			failedBuilds = append(failedBuilds, b)
+		}
+	}
+	if len(failedBuilds) == 0 {
+		return nil
+	}
+
+	client, err := gcp.Logging(ctx, h.c)
+	if err != nil {
+		return err
+	}
+
+	logs := map[string][]string{}
+	for _, b := range failedBuilds {
+		if _, ok := logs[b.ID]; !ok {
+			if logs[b.ID], err = buildLogs(ctx, client, projectID, b.ID, lines); err != nil {
+				return err
+			}
+		}
+		b.Logs = logs[b.ID]
+	}
+	return nil
+}
+
+// buildLogs reads the last lines of the logs of a build.
+func buildLogs(ctx context.Context, client *logging.Client, projectID, buildID string, lines int) ([]string, error) {
+	req := &loggingpb.ListLogEntriesRequest{
+		ResourceNames: []string{"projects/" + projectID},
+		Filter:        fmt.Sprintf(`logName="projects/%s/logs/cloudbuild" AND resource.type="build" AND resource.labels.build_id="%s"`, projectID, buildID),
+		OrderBy:       "timestamp desc",
+		PageSize:      int32(lines),
+	}
+	var logs []string
+	it := client.ListLogEntries(ctx, req)
+	for len(logs) < lines {
+		e, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the logs of build %s: %w", buildID, err)
+		}
+		logs = append(logs, e.GetTextPayload())
+	}
+	slices.Reverse(logs)
+	return logs, nil
+}
+
+func formatTime(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	return ts.AsTime().Format(time.RFC3339)
+}
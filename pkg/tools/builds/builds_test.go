@@ -0,0 +1,111 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builds
+
+import (
+	"testing"
+
+	"cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const (
+	web    = "us-docker.pkg.dev/p/r/web"
+	worker = "us-docker.pkg.dev/p/r/worker"
+)
+
+var containers = []kube.Container{
+	{Workload: "prod/Deployment/web", Repository: web, Digest: "sha256:old"},
+	{Workload: "prod/Deployment/web", Repository: web, Digest: "sha256:old"},
+	{Workload: "prod/StatefulSet/worker", Repository: "busybox", Digest: "sha256:bb"},
+	{Workload: "prod/StatefulSet/worker", Repository: worker, Digest: "sha256:w3"},
+	{Workload: "dev/Deployment/web", Repository: web},
+}
+
+func TestRunningImages(t *testing.T) {
+	want := []*image{
+		{Repository: "busybox", Digest: "sha256:bb", Workloads: []string{"prod/StatefulSet/worker"}},
+		{Repository: web, Digest: "sha256:old", Workloads: []string{"prod/Deployment/web"}},
+		{Repository: worker, Digest: "sha256:w3", Workloads: []string{"prod/StatefulSet/worker"}},
+	}
+	if diff := cmp.Diff(want, runningImages(containers, "")); diff != "" {
+		t.Errorf("runningImages() mismatch (-want +got):\n%s", diff)
+	}
+	if got := runningImages(containers, "/r/"); len(got) != 2 {
+		t.Errorf("runningImages(/r/) returned %d images, want 2", len(got))
+	}
+}
+
+func newBuild(id string, status cloudbuildpb.Build_Status, image, digest string) *cloudbuildpb.Build {
+	b := &cloudbuildpb.Build{
+		Id:     id,
+		Status: status,
+		Images: []string{image + ":" + id},
+	}
+	if digest != "" {
+		b.Results = &cloudbuildpb.Results{Images: []*cloudbuildpb.BuiltImage{{Name: image + ":" + id, Digest: digest}}}
+	}
+	if status == cloudbuildpb.Build_FAILURE {
+		b.FailureInfo = &cloudbuildpb.Build_FailureInfo{Detail: "step 0 failed"}
+	}
+	return b
+}
+
+func TestHistory(t *testing.T) {
+	builds := []*cloudbuildpb.Build{
+		newBuild("b5", cloudbuildpb.Build_FAILURE, worker, ""),
+		newBuild("b4", cloudbuildpb.Build_SUCCESS, web, "sha256:new"),
+		newBuild("b3", cloudbuildpb.Build_SUCCESS, worker, "sha256:w3"),
+		newBuild("b2", cloudbuildpb.Build_FAILURE, web, ""),
+		newBuild("b1", cloudbuildpb.Build_SUCCESS, web, "sha256:old"),
+	}
+	images := runningImages(containers, "/r/")
+	history(images, builds)
+
+	want := []*image{
+		{
+			Repository:  web,
+			Digest:      "sha256:old",
+			Workloads:   []string{"prod/Deployment/web"},
+			Status:      "outdated: build b4 pushed a newer image that isn't deployed",
+			BuiltBy:     &build{ID: "b1", Status: "SUCCESS", Digest: "sha256:old"},
+			LaterBuilds: []build{{ID: "b4", Status: "SUCCESS", Digest: "sha256:new"}, {ID: "b2", Status: "FAILURE", Failure: "step 0 failed"}},
+		},
+		{
+			Repository:  worker,
+			Digest:      "sha256:w3",
+			Workloads:   []string{"prod/StatefulSet/worker"},
+			Status:      "later builds didn't produce a newer image: the latest one, b5, ended in FAILURE",
+			BuiltBy:     &build{ID: "b3", Status: "SUCCESS", Digest: "sha256:w3"},
+			LaterBuilds: []build{{ID: "b5", Status: "FAILURE", Failure: "step 0 failed"}},
+		},
+	}
+	if diff := cmp.Diff(want, images, cmpopts.IgnoreUnexported(build{})); diff != "" {
+		t.Errorf("history() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHistoryNotFound(t *testing.T) {
+	images := runningImages(containers, "/web")
+	history(images, []*cloudbuildpb.Build{newBuild("b9", cloudbuildpb.Build_SUCCESS, web, "sha256:other")})
+	if got, want := images[0].Status, "not built by any of the builds searched"; got != want {
+		t.Errorf("status = %q, want %q", got, want)
+	}
+	if images[0].LaterBuilds != nil {
+		t.Errorf("LaterBuilds = %v, want none", images[0].LaterBuilds)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Capabilities describes the tools served, by category, and the IAM
+// permissions they need.
+type Capabilities struct {
+	Categories []Category `json:"categories"`
+	// Permissions are the IAM permissions needed by every tool served.
+	Permissions []string `json:"permissions"`
+}
+
+// Category describes the tools of a category.
+type Category struct {
+	Name        string           `json:"name"`
+	Tools       []ToolCapability `json:"tools"`
+	Permissions []string         `json:"permissions,omitempty"`
+}
+
+// ToolCapability describes a tool.
+type ToolCapability struct {
+	Name        string       `json:"name"`
+	Kind        catalog.Kind `json:"kind"`
+	ReadOnly    bool         `json:"read_only"`
+	Destructive bool         `json:"destructive"`
+	Idempotent  bool         `json:"idempotent"`
+	OpenWorld   bool         `json:"open_world"`
+	Permissions []string     `json:"permissions,omitempty"`
+}
+
+// checkCatalog makes sure every tool is described in the catalog, with
+// annotations matching its kind.
+func checkCatalog(ctx context.Context, s *server.MCPServer) error {
+	tools, err := ListTools(ctx, s)
+	if err != nil {
+		return err
+	}
+	for _, tool := range tools {
+		if err := catalog.Check(tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addCapabilitiesTool registers list_capabilities. Like server_info, it is
+// added once the tool set is final.
+func addCapabilitiesTool(s *server.MCPServer) {
+	tool := mcp.NewTool("list_capabilities",
+		mcp.WithDescription("List the tools of the GKE MCP server by category, with what they can change and the IAM permissions they need. Use it to check what the server can do, or which roles the user needs when a tool fails with a permission error."),
+		catalog.Describe(catalog.Server, catalog.Local),
+	)
+	s.AddTool(tool, func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		c, err := capabilities(ctx, s)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		data, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	})
+}
+
+func capabilities(ctx context.Context, s *server.MCPServer) (*Capabilities, error) {
+	tools, err := ListTools(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	byCategory := map[string]*Category{}
+	c := &Capabilities{Permissions: []string{}}
+	for _, tool := range tools {
+		e, ok := catalog.Lookup(tool.Name)
+		if !ok {
+			return nil, fmt.Errorf("tool %q is not described in the catalog", tool.Name)
+		}
+		category, ok := byCategory[e.Category]
+		if !ok {
+			category = &Category{Name: e.Category}
+			byCategory[e.Category] = category
+		}
+		a := tool.Annotations
+		category.Tools = append(category.Tools, ToolCapability{
+			Name:        tool.Name,
+			Kind:        e.Kind,
+			ReadOnly:    *a.ReadOnlyHint,
+			Destructive: *a.DestructiveHint,
+			Idempotent:  *a.IdempotentHint,
+			OpenWorld:   *a.OpenWorldHint,
+			Permissions: e.Permissions,
+		})
+		category.Permissions = append(category.Permissions, e.Permissions...)
+		c.Permissions = append(c.Permissions, e.Permissions...)
+	}
+	for _, name := range catalog.Categories {
+		if category, ok := byCategory[name]; ok {
+			slices.Sort(category.Permissions)
+			category.Permissions = slices.Compact(category.Permissions)
+			c.Categories = append(c.Categories, *category)
+		}
+	}
+	slices.Sort(c.Permissions)
+	c.Permissions = slices.Compact(c.Permissions)
+	return c, nil
+}
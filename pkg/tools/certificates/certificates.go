@@ -0,0 +1,313 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificates
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultWindowDays is the number of days ahead expiring certificates are
+// reported for.
+const defaultWindowDays = 30
+
+// preSharedCertAnnotation lists the Compute Engine SSL certificates of an
+// Ingress, comma-separated.
+const preSharedCertAnnotation = "ingress.gcp.kubernetes.io/pre-shared-cert"
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("certificates", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	auditCertificateExpiryTool := mcp.NewTool("audit_certificate_expiry",
+		mcp.WithDescription("Audit when the certificates of a GKE cluster expire: the cluster CA, the CA bundles of admission webhooks, cert-manager Certificates, the TLS Secrets of Ingresses and the Google-managed and pre-shared certificates of Ingresses. Reports the expired ones, the ones expiring within a window and the ones cert-manager or Google failed to issue."),
+		catalog.Describe(catalog.Observability, catalog.Read, "container.clusters.get", "container.validatingWebhookConfigurations.list", "container.mutatingWebhookConfigurations.list", "container.thirdPartyObjects.list", "container.ingresses.list", "container.secrets.get", "compute.sslCertificates.get"),
+		explain.Command(h.auditCertificateExpiryCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithNumber("within_days", mcp.Description(fmt.Sprintf("Certificates expiring within this many days are reported as expiring. Defaults to %d.", defaultWindowDays))),
+	)
+	s.AddTool(auditCertificateExpiryTool, h.auditCertificateExpiry)
+
+	return nil
+}
+
+func (h *handlers) auditCertificateExpiry(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	days := request.GetInt("within_days", defaultWindowDays)
+	if days < 0 {
+		return mcp.NewToolResultError("within_days must not be negative"), nil
+	}
+	window := time.Duration(days) * 24 * time.Hour
+	now := time.Now()
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	ca, err := base64.StdEncoding.DecodeString(cluster.GetMasterAuth().GetClusterCaCertificate())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to decode the cluster CA certificate: %v", err)), nil
+	}
+	items := fromPEM(clusterCA, "", name, ca, now, window)
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	for _, collect := range []func(context.Context, *kube.Client, time.Time, time.Duration) ([]item, []string, error){
+		webhookItems,
+		certManagerItems,
+		ingressItems,
+	} {
+		found, gcpCerts, err := collect(ctx, k, now, window)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		items = append(items, found...)
+		if len(gcpCerts) > 0 {
+			found, err := h.sslCertificateItems(ctx, projectID, gcpCerts, now, window)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			items = append(items, found...)
+		}
+	}
+	sortItems(items)
+
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(summary(name, items, window) + "\n\n" + string(data)), nil
+}
+
+// webhookItems returns the certificates of the CA bundles of the admission
+// webhooks.
+func webhookItems(ctx context.Context, k *kube.Client, now time.Time, window time.Duration) ([]item, []string, error) {
+	var items []item
+	for _, resource := range []string{"validatingwebhookconfigurations", "mutatingwebhookconfigurations"} {
+		var list struct {
+			Items []struct {
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+				Webhooks []struct {
+					Name         string `json:"name"`
+					ClientConfig struct {
+						CABundle []byte `json:"caBundle"`
+					} `json:"clientConfig"`
+				} `json:"webhooks"`
+			} `json:"items"`
+		}
+		if err := k.Get(ctx, "/apis/admissionregistration.k8s.io/v1/"+resource, &list); err != nil {
+			return nil, nil, err
+		}
+		for _, c := range list.Items {
+			for _, w := range c.Webhooks {
+				if len(w.ClientConfig.CABundle) == 0 {
+					continue
+				}
+				items = append(items, fromPEM(webhookCABundle, "", c.Metadata.Name+"/"+w.Name, w.ClientConfig.CABundle, now, window)...)
+			}
+		}
+	}
+	return items, nil, nil
+}
+
+// certManagerItems returns the cert-manager Certificates, if cert-manager is
+// installed.
+func certManagerItems(ctx context.Context, k *kube.Client, now time.Time, window time.Duration) ([]item, []string, error) {
+	var list struct {
+		Items []certManagerCertificate `json:"items"`
+	}
+	if err := k.Get(ctx, "/apis/cert-manager.io/v1/certificates", &list); err != nil {
+		if kube.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	var items []item
+	for _, c := range list.Items {
+		items = append(items, fromCertManager(c, now, window))
+	}
+	return items, nil, nil
+}
+
+// ingressItems returns the certificates of the TLS Secrets of the Ingresses,
+// the Google-managed certificates that aren't active, and the names of the
+// Compute Engine SSL certificates the Ingresses use.
+func ingressItems(ctx context.Context, k *kube.Client, now time.Time, window time.Duration) ([]item, []string, error) {
+	var ingresses struct {
+		Items []struct {
+			Metadata struct {
+				Namespace   string            `json:"namespace"`
+				Name        string            `json:"name"`
+				Annotations map[string]string `json:"annotations"`
+			} `json:"metadata"`
+			Spec struct {
+				TLS []struct {
+					SecretName string `json:"secretName"`
+				} `json:"tls"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := k.Get(ctx, "/apis/networking.k8s.io/v1/ingresses", &ingresses); err != nil {
+		return nil, nil, err
+	}
+	var items []item
+	var gcpCerts []string
+	seen := map[string]bool{}
+	for _, ing := range ingresses.Items {
+		for _, tls := range ing.Spec.TLS {
+			key := ing.Metadata.Namespace + "/" + tls.SecretName
+			if tls.SecretName == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			var secret struct {
+				Data map[string][]byte `json:"data"`
+			}
+			if err := k.Get(ctx, fmt.Sprintf("/api/v1/namespaces/%s/secrets/%s", ing.Metadata.Namespace, tls.SecretName), &secret); err != nil {
+				if !kube.IsNotFound(err) {
+					return nil, nil, err
+				}
+				items = append(items, item{Kind: ingressSecret, Namespace: ing.Metadata.Namespace, Name: tls.SecretName, Status: unknown, Message: fmt.Sprintf("The Secret of Ingress %s doesn't exist.", ing.Metadata.Name)})
+				continue
+			}
+			items = append(items, fromPEM(ingressSecret, ing.Metadata.Namespace, tls.SecretName, secret.Data["tls.crt"], now, window)...)
+		}
+		for _, name := range strings.Split(ing.Metadata.Annotations[preSharedCertAnnotation], ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				gcpCerts = append(gcpCerts, name)
+			}
+		}
+	}
+
+	var managed struct {
+		Items []struct {
+			Metadata struct {
+				Namespace string `json:"namespace"`
+				Name      string `json:"name"`
+			} `json:"metadata"`
+			Status struct {
+				CertificateName   string `json:"certificateName"`
+				CertificateStatus string `json:"certificateStatus"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := k.Get(ctx, "/apis/networking.gke.io/v1/managedcertificates", &managed); err != nil && !kube.IsNotFound(err) {
+		return nil, nil, err
+	}
+	for _, m := range managed.Items {
+		if m.Status.CertificateName != "" {
+			gcpCerts = append(gcpCerts, m.Status.CertificateName)
+		}
+		if m.Status.CertificateStatus != "Active" {
+			items = append(items, item{Kind: managedCert, Namespace: m.Metadata.Namespace, Name: m.Metadata.Name, Status: notReady, Message: fmt.Sprintf("The ManagedCertificate is %q; check that its domains resolve to the load balancer.", m.Status.CertificateStatus)})
+		}
+	}
+	return items, gcpCerts, nil
+}
+
+// sslCertificateItems gets when the global Compute Engine SSL certificates
+// expire.
+func (h *handlers) sslCertificateItems(ctx context.Context, projectID string, names []string, now time.Time, window time.Duration) ([]item, error) {
+	client, err := gcp.SslCertificates(ctx, h.c)
+	if err != nil {
+		return nil, err
+	}
+	var items []item
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		i := item{Kind: preSharedCert, Name: name, Status: unknown}
+		cert, err := client.Get(ctx, &computepb.GetSslCertificateRequest{Project: projectID, SslCertificate: name})
+		if err != nil {
+			i.Message = err.Error()
+			items = append(items, i)
+			continue
+		}
+		if cert.GetType() == computepb.SslCertificate_MANAGED.String() {
+			i.Kind = managedCert
+			i.Subject = strings.Join(cert.GetManaged().GetDomains(), ",")
+		} else {
+			i.Subject = strings.Join(cert.GetSubjectAlternativeNames(), ",")
+		}
+		if expire, err := time.Parse(time.RFC3339, cert.GetExpireTime()); err == nil {
+			i.expiry(expire, now, window)
+		}
+		items = append(items, i)
+	}
+	return items, nil
+}
+
+func (h *handlers) auditCertificateExpiryCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud container clusters describe", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--format=value(masterAuth.clusterCaCertificate)") + " | base64 --decode | openssl x509 -noout -enddate",
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get certificates.cert-manager.io --all-namespaces",
+		"kubectl get ingresses,managedcertificates --all-namespaces",
+		explain.Join("gcloud compute ssl-certificates list", explain.Flag("project", projectID), "--format=table(name,type,expireTime)"),
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificates
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Statuses of the certificates, from worst to best.
+const (
+	expired  = "expired"
+	expiring = "expiring"
+	notReady = "not ready"
+	unknown  = "unknown"
+	valid    = "valid"
+)
+
+// Kinds of certificates checked.
+const (
+	clusterCA       = "cluster CA"
+	webhookCABundle = "webhook CA bundle"
+	certManager     = "cert-manager Certificate"
+	ingressSecret   = "Ingress TLS Secret"
+	managedCert     = "Google-managed certificate"
+	preSharedCert   = "pre-shared certificate"
+)
+
+// item is a certificate and when it expires.
+type item struct {
+	Kind      string     `json:"kind"`
+	Namespace string     `json:"namespace,omitempty"`
+	Name      string     `json:"name"`
+	Subject   string     `json:"subject,omitempty"`
+	NotAfter  *time.Time `json:"notAfter,omitempty"`
+	DaysLeft  *int       `json:"daysLeft,omitempty"`
+	Status    string     `json:"status"`
+	Message   string     `json:"message,omitempty"`
+}
+
+// expiry sets when the item expires, and its status against the window.
+func (i *item) expiry(notAfter, now time.Time, window time.Duration) {
+	days := int(math.Floor(notAfter.Sub(now).Hours() / 24))
+	i.NotAfter, i.DaysLeft = &notAfter, &days
+	switch left := notAfter.Sub(now); {
+	case left <= 0:
+		i.Status = expired
+	case left < window:
+		i.Status = expiring
+	default:
+		i.Status = valid
+	}
+}
+
+// fromPEM returns an item for every certificate of PEM data, such as a CA
+// bundle.
+func fromPEM(kind, namespace, name string, data []byte, now time.Time, window time.Duration) []item {
+	var items []item
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		i := item{Kind: kind, Namespace: namespace, Name: name}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			i.Status, i.Message = unknown, fmt.Sprintf("The certificate couldn't be parsed: %v", err)
+		} else {
+			i.Subject = cert.Subject.CommonName
+			i.expiry(cert.NotAfter, now, window)
+		}
+		items = append(items, i)
+	}
+	if len(items) == 0 {
+		items = append(items, item{Kind: kind, Namespace: namespace, Name: name, Status: unknown, Message: "No PEM encoded certificate found."})
+	}
+	return items
+}
+
+// certManagerCertificate is the part of a cert-manager Certificate checked.
+type certManagerCertificate struct {
+	Metadata struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		CommonName string   `json:"commonName"`
+		DNSNames   []string `json:"dnsNames"`
+	} `json:"spec"`
+	Status struct {
+		NotAfter    *time.Time `json:"notAfter"`
+		RenewalTime *time.Time `json:"renewalTime"`
+		Conditions  []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// fromCertManager returns the item of a cert-manager Certificate, which is
+// not ready when cert-manager failed to issue or renew it.
+func fromCertManager(c certManagerCertificate, now time.Time, window time.Duration) item {
+	i := item{Kind: certManager, Namespace: c.Metadata.Namespace, Name: c.Metadata.Name, Subject: c.Spec.CommonName, Status: unknown}
+	if i.Subject == "" && len(c.Spec.DNSNames) > 0 {
+		i.Subject = c.Spec.DNSNames[0]
+	}
+	if c.Status.NotAfter != nil {
+		i.expiry(*c.Status.NotAfter, now, window)
+	}
+	for _, cond := range c.Status.Conditions {
+		if cond.Type == "Ready" && cond.Status != "True" {
+			i.Message = cond.Message
+			if i.Status != expired {
+				i.Status = notReady
+			}
+		}
+	}
+	if i.Message == "" && i.Status == expiring && c.Status.RenewalTime != nil && c.Status.RenewalTime.Before(now) {
+		i.Message = fmt.Sprintf("cert-manager should have renewed it on %s.", c.Status.RenewalTime.Format(time.DateOnly))
+	}
+	return i
+}
+
+// sortItems orders the items worst status first, then by expiry.
+func sortItems(items []item) {
+	rank := map[string]int{expired: 0, expiring: 1, notReady: 2, unknown: 3, valid: 4}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Status != b.Status {
+			return rank[a.Status] < rank[b.Status]
+		}
+		if a.NotAfter != nil && b.NotAfter != nil {
+			return a.NotAfter.Before(*b.NotAfter)
+		}
+		return a.NotAfter != nil
+	})
+}
+
+// summary counts the items by status.
+func summary(cluster string, items []item, window time.Duration) string {
+	counts := map[string]int{}
+	for _, i := range items {
+		counts[i.Status]++
+	}
+	return fmt.Sprintf("Checked %d certificates of cluster %s: %d expired, %d expiring within %d days, %d not ready, %d unknown.", len(items), cluster, counts[expired], counts[expiring], int(window.Hours()/24), counts[notReady], counts[unknown])
+}
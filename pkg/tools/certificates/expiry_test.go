@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificates
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	now    = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	window = defaultWindowDays * 24 * time.Hour
+)
+
+func certificate(t *testing.T, commonName string, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestFromPEM(t *testing.T) {
+	bundle := append(certificate(t, "old-ca", now.AddDate(0, 0, 10)), certificate(t, "new-ca", now.AddDate(2, 0, 0))...)
+	want := []item{
+		{Kind: webhookCABundle, Name: "policy/validate.example.com", Subject: "old-ca", NotAfter: ptr(now.AddDate(0, 0, 10)), DaysLeft: ptr(10), Status: expiring},
+		{Kind: webhookCABundle, Name: "policy/validate.example.com", Subject: "new-ca", NotAfter: ptr(now.AddDate(2, 0, 0)), DaysLeft: ptr(730), Status: valid},
+	}
+	if diff := cmp.Diff(want, fromPEM(webhookCABundle, "", "policy/validate.example.com", bundle, now, window)); diff != "" {
+		t.Errorf("fromPEM() mismatch (-want +got):\n%s", diff)
+	}
+
+	want = []item{{Kind: ingressSecret, Namespace: "web", Name: "tls", Status: unknown, Message: "No PEM encoded certificate found."}}
+	if diff := cmp.Diff(want, fromPEM(ingressSecret, "web", "tls", nil, now, window)); diff != "" {
+		t.Errorf("fromPEM() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFromCertManager(t *testing.T) {
+	tests := []struct {
+		name string
+		cert string
+		want item
+	}{
+		{
+			name: "valid",
+			cert: `{"metadata":{"namespace":"web","name":"shop"},"spec":{"dnsNames":["shop.example.com"]},
+				"status":{"notAfter":"2025-08-30T12:00:00Z","conditions":[{"type":"Ready","status":"True"}]}}`,
+			want: item{Kind: certManager, Namespace: "web", Name: "shop", Subject: "shop.example.com", NotAfter: ptr(now.AddDate(0, 0, 90)), DaysLeft: ptr(90), Status: valid},
+		},
+		{
+			name: "renewal overdue",
+			cert: `{"metadata":{"namespace":"web","name":"api"},"spec":{"commonName":"api.example.com"},
+				"status":{"notAfter":"2025-06-11T12:00:00Z","renewalTime":"2025-05-12T12:00:00Z","conditions":[{"type":"Ready","status":"True"}]}}`,
+			want: item{Kind: certManager, Namespace: "web", Name: "api", Subject: "api.example.com", NotAfter: ptr(now.AddDate(0, 0, 10)), DaysLeft: ptr(10), Status: expiring, Message: "cert-manager should have renewed it on 2025-05-12."},
+		},
+		{
+			name: "failed to issue",
+			cert: `{"metadata":{"namespace":"web","name":"new"},
+				"status":{"conditions":[{"type":"Ready","status":"False","message":"Issuing certificate as Secret does not exist"}]}}`,
+			want: item{Kind: certManager, Namespace: "web", Name: "new", Status: notReady, Message: "Issuing certificate as Secret does not exist"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var c certManagerCertificate
+			if err := json.Unmarshal([]byte(tc.cert), &c); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, fromCertManager(c, now, window)); diff != "" {
+				t.Errorf("fromCertManager() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSortItems(t *testing.T) {
+	items := []item{
+		{Name: "valid", Status: valid, NotAfter: ptr(now.AddDate(1, 0, 0))},
+		{Name: "unknown", Status: unknown},
+		{Name: "later", Status: expiring, NotAfter: ptr(now.AddDate(0, 0, 20))},
+		{Name: "sooner", Status: expiring, NotAfter: ptr(now.AddDate(0, 0, 5))},
+		{Name: "expired", Status: expired, NotAfter: ptr(now.AddDate(0, 0, -1))},
+	}
+	sortItems(items)
+	var got []string
+	for _, i := range items {
+		got = append(got, i.Name)
+	}
+	want := []string{"expired", "sooner", "later", "unknown", "valid"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("sortItems() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := summary("prod", items, window), "Checked 5 certificates of cluster prod: 1 expired, 2 expiring within 30 days, 0 not ready, 1 unknown."; got != want {
+		t.Errorf("summary() = %q, want %q", got, want)
+	}
+}
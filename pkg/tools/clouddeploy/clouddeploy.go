@@ -0,0 +1,548 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clouddeploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	deploy "cloud.google.com/go/deploy/apiv1"
+	deploypb "cloud.google.com/go/deploy/apiv1/deploypb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultReleases is the number of releases listed by default.
+const defaultReleases = 5
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("clouddeploy", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	listDeliveryPipelinesTool := mcp.NewTool("list_delivery_pipelines",
+		mcp.WithDescription("List the Cloud Deploy delivery pipelines that deploy to GKE clusters in a region, with their stages in promotion order and the cluster each target deploys to."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "clouddeploy.deliveryPipelines.list", "clouddeploy.targets.list"),
+		explain.Command(h.listDeliveryPipelinesCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("Cloud Deploy region. Defaults to the region of the session context's location.")),
+		mcp.WithString("cluster", mcp.Description("Only list the pipelines deploying to this GKE cluster.")),
+	)
+	s.AddTool(listDeliveryPipelinesTool, h.listDeliveryPipelines)
+
+	listReleasesTool := mcp.NewTool("list_releases",
+		mcp.WithDescription("List the latest releases of a Cloud Deploy delivery pipeline, with their images and the status of their rollouts to each target, including pending approvals and failure causes. Use it to answer what is deployed where and why a deployment failed."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "clouddeploy.releases.list", "clouddeploy.rollouts.list"),
+		explain.Command(h.listReleasesCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("Cloud Deploy region. Defaults to the region of the session context's location.")),
+		mcp.WithString("pipeline", mcp.Required(), mcp.Description("ID of the delivery pipeline.")),
+		mcp.WithString("release", mcp.Description("ID of a single release to show.")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Number of releases to list, newest first. Defaults to %d.", defaultReleases))),
+	)
+	s.AddTool(listReleasesTool, h.listReleases)
+
+	promoteReleaseTool := mcp.NewTool("promote_release",
+		mcp.WithDescription("Promote a Cloud Deploy release to the next target of its delivery pipeline, or to a given target, by creating a rollout. The rollout may wait for approval if the target requires it. Always do a dry run first and ask the user to confirm before promoting."),
+		catalog.Describe(catalog.Clusters, catalog.Deploy, "clouddeploy.releases.get", "clouddeploy.rollouts.list", "clouddeploy.rollouts.create"),
+		explain.Command(h.promoteReleaseCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("Cloud Deploy region. Defaults to the region of the session context's location.")),
+		mcp.WithString("pipeline", mcp.Required(), mcp.Description("ID of the delivery pipeline.")),
+		mcp.WithString("release", mcp.Required(), mcp.Description("ID of the release to promote.")),
+		mcp.WithString("to_target", mcp.Description("ID of the target to promote to. Defaults to the stage after the last one the release was deployed to.")),
+		dryrun.Argument(c),
+	)
+	s.AddTool(promoteReleaseTool, h.promoteRelease)
+
+	rollbackTargetTool := mcp.NewTool("rollback_target",
+		mcp.WithDescription("Roll a Cloud Deploy target back to the release it ran before, or to a given release, by creating a rollback rollout. Always do a dry run first and ask the user to confirm before rolling back."),
+		catalog.Describe(catalog.Clusters, catalog.Deploy, "clouddeploy.releases.list", "clouddeploy.rollouts.create"),
+		explain.Command(h.rollbackTargetCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("Cloud Deploy region. Defaults to the region of the session context's location.")),
+		mcp.WithString("pipeline", mcp.Required(), mcp.Description("ID of the delivery pipeline.")),
+		mcp.WithString("target", mcp.Required(), mcp.Description("ID of the target to roll back.")),
+		mcp.WithString("release", mcp.Description("ID of the release to roll back to. Defaults to the last release successfully deployed to the target before the current one.")),
+		dryrun.Argument(c),
+	)
+	s.AddTool(rollbackTargetTool, h.rollbackTarget)
+
+	return nil
+}
+
+var zone = regexp.MustCompile(`^([a-z]+-[a-z]+\d+)-[a-z]$`)
+
+// region returns the region of a location, which may be a zone, as Cloud
+// Deploy is regional.
+func region(location string) string {
+	if m := zone.FindStringSubmatch(location); m != nil {
+		return m[1]
+	}
+	return location
+}
+
+// scope returns the project and region of the request.
+func (h *handlers) scope(ctx context.Context, request mcp.CallToolRequest) (string, string, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return "", "", fmt.Errorf("project_id argument not set")
+	}
+	location := region(session.Location(ctx, request, h.c))
+	if location == "" {
+		return "", "", fmt.Errorf("location argument not set")
+	}
+	return projectID, location, nil
+}
+
+// pipelineName returns the resource name of the pipeline of the request.
+func (h *handlers) pipelineName(ctx context.Context, request mcp.CallToolRequest) (string, error) {
+	projectID, location, err := h.scope(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	pipeline, err := request.RequireString("pipeline")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("projects/%s/locations/%s/deliveryPipelines/%s", projectID, location, pipeline), nil
+}
+
+// newClient creates a client acting with the credentials of the calling
+// session.
+func (h *handlers) newClient(ctx context.Context) (*deploy.CloudDeployClient, error) {
+	opts, err := auth.ClientOptions(ctx, h.c, config.APICloudDeploy)
+	if err != nil {
+		return nil, err
+	}
+	client, err := deploy.NewCloudDeployClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cloud deploy client: %w", err)
+	}
+	return client, nil
+}
+
+// pipeline is the tool result entry of a delivery pipeline.
+type pipeline struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Suspended   bool    `json:"suspended,omitempty"`
+	Stages      []stage `json:"stages"`
+}
+
+type stage struct {
+	Target          string `json:"target"`
+	Cluster         string `json:"cluster,omitempty"`
+	RequireApproval bool   `json:"requireApproval,omitempty"`
+}
+
+func (h *handlers) listDeliveryPipelines(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID, location, err := h.scope(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	client, err := h.newClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer client.Close()
+
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
+	targets := map[string]*deploypb.Target{}
+	it := client.ListTargets(ctx, &deploypb.ListTargetsRequest{Parent: parent})
+	for {
+		t, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		targets[t.GetTargetId()] = t
+	}
+
+	var pipelines []*deploypb.DeliveryPipeline
+	pit := client.ListDeliveryPipelines(ctx, &deploypb.ListDeliveryPipelinesRequest{Parent: parent})
+	for {
+		p, err := pit.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		pipelines = append(pipelines, p)
+	}
+
+	result := gkePipelines(pipelines, targets, request.GetString("cluster", ""))
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// gkePipelines returns the pipelines with a stage deploying to a GKE
+// cluster, or to the cluster named cluster if not empty.
+func gkePipelines(pipelines []*deploypb.DeliveryPipeline, targets map[string]*deploypb.Target, cluster string) []pipeline {
+	result := []pipeline{}
+	for _, p := range pipelines {
+		entry := pipeline{
+			Name:        path.Base(p.GetName()),
+			Description: p.GetDescription(),
+			Suspended:   p.GetSuspended(),
+		}
+		matches := false
+		for _, s := range p.GetSerialPipeline().GetStages() {
+			t := targets[s.GetTargetId()]
+			gke := t.GetGke().GetCluster()
+			if gke != "" && (cluster == "" || path.Base(gke) == cluster) {
+				matches = true
+			}
+			entry.Stages = append(entry.Stages, stage{Target: s.GetTargetId(), Cluster: gke, RequireApproval: t.GetRequireApproval()})
+		}
+		if matches {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// release is the tool result entry of a release.
+type release struct {
+	Name        string    `json:"name"`
+	Created     string    `json:"created,omitempty"`
+	RenderState string    `json:"renderState"`
+	Abandoned   bool      `json:"abandoned,omitempty"`
+	Images      []string  `json:"images,omitempty"`
+	Rollouts    []rollout `json:"rollouts"`
+}
+
+type rollout struct {
+	Name          string `json:"name"`
+	Target        string `json:"target"`
+	State         string `json:"state"`
+	Approval      string `json:"approval,omitempty"`
+	FailureCause  string `json:"failureCause,omitempty"`
+	FailureReason string `json:"failureReason,omitempty"`
+	Created       string `json:"created,omitempty"`
+	Deployed      string `json:"deployed,omitempty"`
+	RollbackOf    string `json:"rollbackOf,omitempty"`
+}
+
+func (h *handlers) listReleases(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pipelineName, err := h.pipelineName(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	client, err := h.newClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer client.Close()
+
+	var releases []*deploypb.Release
+	if id := request.GetString("release", ""); id != "" {
+		r, err := client.GetRelease(ctx, &deploypb.GetReleaseRequest{Name: pipelineName + "/releases/" + id})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		releases = append(releases, r)
+	} else {
+		limit := request.GetInt("limit", defaultReleases)
+		it := client.ListReleases(ctx, &deploypb.ListReleasesRequest{Parent: pipelineName, OrderBy: "create_time desc", PageSize: int32(limit)})
+		for len(releases) < limit {
+			r, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			releases = append(releases, r)
+		}
+	}
+
+	result := make([]release, 0, len(releases))
+	for _, r := range releases {
+		rollouts, err := listRollouts(ctx, client, r.GetName())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		result = append(result, toRelease(r, rollouts))
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// listRollouts returns the rollouts of a release, newest first.
+func listRollouts(ctx context.Context, client *deploy.CloudDeployClient, releaseName string) ([]*deploypb.Rollout, error) {
+	var rollouts []*deploypb.Rollout
+	it := client.ListRollouts(ctx, &deploypb.ListRolloutsRequest{Parent: releaseName, OrderBy: "create_time desc"})
+	for {
+		r, err := it.Next()
+		if err == iterator.Done {
+			return rollouts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rollouts = append(rollouts, r)
+	}
+}
+
+func toRelease(r *deploypb.Release, rollouts []*deploypb.Rollout) release {
+	result := release{
+		Name:        path.Base(r.GetName()),
+		Created:     formatTime(r.GetCreateTime()),
+		RenderState: r.GetRenderState().String(),
+		Abandoned:   r.GetAbandoned(),
+		Rollouts:    []rollout{},
+	}
+	for _, a := range r.GetBuildArtifacts() {
+		result.Images = append(result.Images, a.GetTag())
+	}
+	for _, ro := range rollouts {
+		entry := rollout{
+			Name:          path.Base(ro.GetName()),
+			Target:        ro.GetTargetId(),
+			State:         ro.GetState().String(),
+			FailureReason: ro.GetFailureReason(),
+			Created:       formatTime(ro.GetCreateTime()),
+			Deployed:      formatTime(ro.GetDeployEndTime()),
+			RollbackOf:    path.Base(ro.GetRollbackOfRollout()),
+		}
+		if ro.GetApprovalState() != deploypb.Rollout_APPROVAL_STATE_UNSPECIFIED && ro.GetApprovalState() != deploypb.Rollout_DOES_NOT_NEED_APPROVAL {
+			entry.Approval = ro.GetApprovalState().String()
+		}
+		if ro.GetDeployFailureCause() != deploypb.Rollout_FAILURE_CAUSE_UNSPECIFIED {
+			entry.FailureCause = ro.GetDeployFailureCause().String()
+		}
+		if entry.RollbackOf == "." {
+			entry.RollbackOf = ""
+		}
+		result.Rollouts = append(result.Rollouts, entry)
+	}
+	return result
+}
+
+func formatTime(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	return ts.AsTime().Format(time.RFC3339)
+}
+
+func (h *handlers) promoteRelease(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pipelineName, err := h.pipelineName(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	releaseID, err := request.RequireString("release")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	client, err := h.newClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer client.Close()
+
+	r, err := client.GetRelease(ctx, &deploypb.GetReleaseRequest{Name: pipelineName + "/releases/" + releaseID})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if r.GetAbandoned() {
+		return mcp.NewToolResultError(fmt.Sprintf("release %s is abandoned and can't be promoted", releaseID)), nil
+	}
+	rollouts, err := listRollouts(ctx, client, r.GetName())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var stages []string
+	for _, s := range r.GetDeliveryPipelineSnapshot().GetSerialPipeline().GetStages() {
+		stages = append(stages, s.GetTargetId())
+	}
+	target := request.GetString("to_target", "")
+	if target == "" {
+		if target, err = nextTarget(stages, rollouts); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	req := &deploypb.CreateRolloutRequest{
+		Parent:    r.GetName(),
+		RolloutId: rolloutID(releaseID, target, rollouts),
+		Rollout:   &deploypb.Rollout{TargetId: target},
+	}
+	if dryrun.Enabled(request, h.c) {
+		return dryrun.Result("clouddeploy.projects.locations.deliveryPipelines.releases.rollouts.create", req), nil
+	}
+	op, err := client.CreateRollout(ctx, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	created, err := op.Wait(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("rollout %s was requested but creating it failed: %v", req.RolloutId, err)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Created rollout %s of release %s to target %s, now %s. Follow it with list_releases.", path.Base(created.GetName()), releaseID, target, describeState(created))), nil
+}
+
+// nextTarget returns the target after the last stage the release was
+// successfully deployed to, given its rollouts.
+func nextTarget(stages []string, rollouts []*deploypb.Rollout) (string, error) {
+	if len(stages) == 0 {
+		return "", fmt.Errorf("the delivery pipeline of the release has no stages")
+	}
+	last := -1
+	for i, target := range stages {
+		for _, r := range rollouts {
+			if r.GetTargetId() == target && r.GetState() == deploypb.Rollout_SUCCEEDED {
+				last = i
+			}
+		}
+	}
+	if last == len(stages)-1 {
+		return "", fmt.Errorf("the release is already deployed to the last target, %s", stages[last])
+	}
+	return stages[last+1], nil
+}
+
+// rolloutID returns the ID of the next rollout of a release to target,
+// following gcloud's naming.
+func rolloutID(releaseID, target string, rollouts []*deploypb.Rollout) string {
+	n := 1
+	for _, r := range rollouts {
+		if r.GetTargetId() == target {
+			n++
+		}
+	}
+	id := fmt.Sprintf("%s-to-%s-%04d", releaseID, target, n)
+	if len(id) > 63 {
+		id = strings.TrimRight(id[:58], "-") + fmt.Sprintf("-%04d", n)
+	}
+	return id
+}
+
+// describeState describes the state of a new rollout.
+func describeState(r *deploypb.Rollout) string {
+	if r.GetApprovalState() == deploypb.Rollout_NEEDS_APPROVAL {
+		return "waiting for approval"
+	}
+	return strings.ToLower(strings.ReplaceAll(r.GetState().String(), "_", " "))
+}
+
+func (h *handlers) rollbackTarget(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pipelineName, err := h.pipelineName(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	target, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	req := &deploypb.RollbackTargetRequest{
+		Name:      pipelineName,
+		TargetId:  target,
+		RolloutId: "rollback-" + time.Now().UTC().Format("20060102-150405"),
+		ReleaseId: request.GetString("release", ""),
+	}
+	if dryrun.Enabled(request, h.c) {
+		return dryrun.Result("clouddeploy.projects.locations.deliveryPipelines.rollbackTarget", req), nil
+	}
+	client, err := h.newClient(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer client.Close()
+	resp, err := client.RollbackTarget(ctx, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	r := resp.GetRollbackConfig().GetRollout()
+	return mcp.NewToolResultText(fmt.Sprintf("Created rollback rollout %s of target %s, now %s. Follow it with list_releases.", req.RolloutId, target, describeState(r))), nil
+}
+
+func (h *handlers) listDeliveryPipelinesCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, err := h.scope(ctx, request)
+	if err != nil {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud deploy delivery-pipelines list", explain.Flag("region", location), explain.Flag("project", projectID)),
+		explain.Join("gcloud deploy targets list", explain.Flag("region", location), explain.Flag("project", projectID)),
+	}
+}
+
+func (h *handlers) listReleasesCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, err := h.scope(ctx, request)
+	pipeline := request.GetString("pipeline", "")
+	if err != nil || pipeline == "" {
+		return nil
+	}
+	scope := []string{explain.Flag("delivery-pipeline", pipeline), explain.Flag("region", location), explain.Flag("project", projectID)}
+	if id := request.GetString("release", ""); id != "" {
+		return []string{
+			explain.Join("gcloud deploy releases describe", append([]string{id}, scope...)...),
+			explain.Join("gcloud deploy rollouts list", append([]string{explain.Flag("release", id)}, scope...)...),
+		}
+	}
+	return []string{explain.Join("gcloud deploy releases list", append(scope, "--sort-by=~createTime", explain.Flag("limit", fmt.Sprint(request.GetInt("limit", defaultReleases))))...)}
+}
+
+func (h *handlers) promoteReleaseCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, err := h.scope(ctx, request)
+	pipeline, releaseID := request.GetString("pipeline", ""), request.GetString("release", "")
+	if err != nil || pipeline == "" || releaseID == "" {
+		return nil
+	}
+	return []string{explain.Join("gcloud deploy releases promote", explain.Flag("release", releaseID), explain.Flag("delivery-pipeline", pipeline), explain.Flag("to-target", request.GetString("to_target", "")), explain.Flag("region", location), explain.Flag("project", projectID))}
+}
+
+func (h *handlers) rollbackTargetCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, err := h.scope(ctx, request)
+	pipeline, target := request.GetString("pipeline", ""), request.GetString("target", "")
+	if err != nil || pipeline == "" || target == "" {
+		return nil
+	}
+	return []string{explain.Join("gcloud deploy targets rollback", target, explain.Flag("delivery-pipeline", pipeline), explain.Flag("release", request.GetString("release", "")), explain.Flag("region", location), explain.Flag("project", projectID))}
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clouddeploy
+
+import (
+	"testing"
+
+	deploypb "cloud.google.com/go/deploy/apiv1/deploypb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegion(t *testing.T) {
+	tests := []struct {
+		location string
+		want     string
+	}{
+		{location: "us-central1", want: "us-central1"},
+		{location: "us-central1-a", want: "us-central1"},
+		{location: "europe-west4-b", want: "europe-west4"},
+		{location: "northamerica-northeast1-c", want: "northamerica-northeast1"},
+	}
+	for _, tc := range tests {
+		if got := region(tc.location); got != tc.want {
+			t.Errorf("region(%q) = %q, want %q", tc.location, got, tc.want)
+		}
+	}
+}
+
+func TestNextTarget(t *testing.T) {
+	stages := []string{"dev", "staging", "prod"}
+	tests := []struct {
+		name     string
+		rollouts []*deploypb.Rollout
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "not deployed",
+			want: "dev",
+		},
+		{
+			name: "deployed to dev",
+			rollouts: []*deploypb.Rollout{
+				{TargetId: "dev", State: deploypb.Rollout_SUCCEEDED},
+			},
+			want: "staging",
+		},
+		{
+			name: "failed in staging",
+			rollouts: []*deploypb.Rollout{
+				{TargetId: "staging", State: deploypb.Rollout_FAILED},
+				{TargetId: "dev", State: deploypb.Rollout_SUCCEEDED},
+			},
+			want: "staging",
+		},
+		{
+			name: "deployed everywhere",
+			rollouts: []*deploypb.Rollout{
+				{TargetId: "prod", State: deploypb.Rollout_SUCCEEDED},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := nextTarget(stages, tc.rollouts)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("nextTarget() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("nextTarget() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRolloutID(t *testing.T) {
+	rollouts := []*deploypb.Rollout{{TargetId: "dev"}, {TargetId: "staging"}}
+	if got, want := rolloutID("rel-1", "dev", rollouts), "rel-1-to-dev-0002"; got != want {
+		t.Errorf("rolloutID() = %q, want %q", got, want)
+	}
+	long := rolloutID("release-with-a-very-long-name-0123456789", "production-europe-west4", nil)
+	if len(long) > 63 {
+		t.Errorf("rolloutID() = %q, longer than 63 characters", long)
+	}
+}
+
+func TestGKEPipelines(t *testing.T) {
+	targets := map[string]*deploypb.Target{
+		"dev":  {TargetId: "dev", DeploymentTarget: &deploypb.Target_Gke{Gke: &deploypb.GkeCluster{Cluster: "projects/p/locations/us-central1/clusters/dev"}}},
+		"prod": {TargetId: "prod", RequireApproval: true, DeploymentTarget: &deploypb.Target_Gke{Gke: &deploypb.GkeCluster{Cluster: "projects/p/locations/us-central1/clusters/prod"}}},
+		"run":  {TargetId: "run", DeploymentTarget: &deploypb.Target_Run{Run: &deploypb.CloudRunLocation{Location: "projects/p/locations/us-central1"}}},
+	}
+	serial := func(targets ...string) *deploypb.DeliveryPipeline_SerialPipeline {
+		p := &deploypb.SerialPipeline{}
+		for _, t := range targets {
+			p.Stages = append(p.Stages, &deploypb.Stage{TargetId: t})
+		}
+		return &deploypb.DeliveryPipeline_SerialPipeline{SerialPipeline: p}
+	}
+	pipelines := []*deploypb.DeliveryPipeline{
+		{Name: "projects/p/locations/us-central1/deliveryPipelines/web", Pipeline: serial("dev", "prod")},
+		{Name: "projects/p/locations/us-central1/deliveryPipelines/api", Pipeline: serial("run")},
+	}
+
+	want := []pipeline{{
+		Name: "web",
+		Stages: []stage{
+			{Target: "dev", Cluster: "projects/p/locations/us-central1/clusters/dev"},
+			{Target: "prod", Cluster: "projects/p/locations/us-central1/clusters/prod", RequireApproval: true},
+		},
+	}}
+	if diff := cmp.Diff(want, gkePipelines(pipelines, targets, "")); diff != "" {
+		t.Errorf("gkePipelines() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want, gkePipelines(pipelines, targets, "prod")); diff != "" {
+		t.Errorf("gkePipelines(prod) mismatch (-want +got):\n%s", diff)
+	}
+	if got := gkePipelines(pipelines, targets, "other"); len(got) != 0 {
+		t.Errorf("gkePipelines(other) = %v, want none", got)
+	}
+}
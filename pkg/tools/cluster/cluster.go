@@ -17,94 +17,380 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
-	container "cloud.google.com/go/container/apiv1"
 	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/completion"
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/operations"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/scan"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/selector"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/state"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/stream"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
-	"google.golang.org/api/option"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 type handlers struct {
-	c        *config.Config
-	cmClient *container.ClusterManagerClient
+	c       *config.Config
+	watched watchlist
 }
 
-func Install(ctx context.Context, s *server.MCPServer, c *config.Config) error {
+var (
+	clustersCache     = cache.New[*containerpb.ListClustersResponse]("clusters")
+	clusterCache      = cache.New[*containerpb.Cluster]("cluster")
+	serverConfigCache = cache.New[*containerpb.ServerConfig]("server_config")
+)
 
-	cmClient, err := container.NewClusterManagerClient(ctx, option.WithUserAgent(c.UserAgent()))
-	if err != nil {
-		return fmt.Errorf("failed to create cluster manager client: %w", err)
+// cacheKey scopes a cached response for resource to the caller's identity.
+func (h *handlers) cacheKey(ctx context.Context, resource string) string {
+	return resource + "|" + auth.CacheKey(ctx, h.c)
+}
+
+// formatResponse formats a possibly cached response.
+func formatResponse(resp proto.Message, fetchedAt time.Time) string {
+	text := protojson.Format(resp)
+	if note := cache.Note(fetchedAt); note != "" {
+		text += "\n" + note
 	}
+	return text
+}
+
+func marshalProto[M proto.Message](m M) ([]byte, error) {
+	return protojson.Marshal(m)
+}
+
+func unmarshalProto[T any, M interface {
+	*T
+	proto.Message
+}](data []byte) (M, error) {
+	m := M(new(T))
+	return m, protojson.Unmarshal(data, m)
+}
+
+// textResult returns a possibly cached response as the tool result.
+func textResult(resp proto.Message, fetchedAt time.Time) *mcp.CallToolResult {
+	return mcp.NewToolResultText(formatResponse(resp, fetchedAt))
+}
+
+func init() {
+	plugin.Register(plugin.New("cluster", Install))
+}
+
+func Install(ctx context.Context, s *server.MCPServer, c *config.Config) error {
 
 	h := &handlers{
-		c:        c,
-		cmClient: cmClient,
+		c: c,
+	}
+
+	// Keep the cluster inventory across restarts.
+	if err := state.Default.Register("clusters_cache", clustersCache.Persister(marshalProto, unmarshalProto[containerpb.ListClustersResponse])); err != nil {
+		slog.Warn("Failed to restore cached clusters", "err", err)
+	}
+	if err := state.Default.Register("cluster_cache", clusterCache.Persister(marshalProto, unmarshalProto[containerpb.Cluster])); err != nil {
+		slog.Warn("Failed to restore cached clusters", "err", err)
 	}
 
 	listClustersTool := mcp.NewTool("list_clusters",
-		mcp.WithDescription("List GKE clusters. Prefer to use this tool instead of gcloud"),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithIdempotentHintAnnotation(true),
-		mcp.WithString("project_id", mcp.DefaultString(c.DefaultProjectID()), mcp.Description("GCP project ID. Use the default if the user doesn't provide it.")),
+		mcp.WithDescription("List GKE clusters. Prefer to use this tool instead of gcloud."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.list"),
+		explain.Command(h.listClustersCommands),
+		structured.Output(structured.Clusters),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
 		mcp.WithString("location", mcp.Description("GKE cluster location. Leave this empty if the user doesn't doesn't provide it.")),
+		mcp.WithArray("project_ids", mcp.Items(map[string]any{"type": "string"}), mcp.Description("GCP project IDs to list clusters from concurrently, for questions spanning several projects. Overrides project_id.")),
+		selector.Option(),
+		cache.RefreshOption(),
+		stream.Option(),
+		governor.FullOption(),
 	)
 	s.AddTool(listClustersTool, h.listClusters)
 
 	getClusterTool := mcp.NewTool("get_cluster",
-		mcp.WithDescription("Get / describe a GKE cluster. Prefer to use this tool instead of gcloud"),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithIdempotentHintAnnotation(true),
-		mcp.WithString("project_id", mcp.Required(), mcp.Description("GCP project ID. Use the default if the user doesn't provide it.")),
-		mcp.WithString("location", mcp.Required(), mcp.Description("GKE cluster location. Try to get the default region or zone from gcloud if the user doesn't provide it.")),
-		mcp.WithString("name", mcp.Required(), mcp.Description("GKE cluster name. Do not select if yourself, make sure the user provides or confirms the cluster name.")),
+		mcp.WithDescription("Get / describe a GKE cluster. Prefer to use this tool instead of gcloud."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.get"),
+		explain.Command(h.getClusterCommands),
+		structured.Output(structured.Cluster),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("name", mcp.Description("GKE cluster name. Defaults to the session context. Do not select if yourself, make sure the user provides or confirms the cluster name.")),
+		cache.RefreshOption(),
+		governor.FullOption(),
 	)
 	s.AddTool(getClusterTool, h.getCluster)
 
+	getServerConfigTool := mcp.NewTool("get_server_config",
+		mcp.WithDescription("Get the GKE server config for a location: the default and valid control plane and node versions, image types and the versions available in each release channel. Use it to plan cluster creation and upgrades."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.list"),
+		explain.Command(h.getServerConfigCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE location (region or zone). Defaults to the session context.")),
+		cache.RefreshOption(),
+	)
+	s.AddTool(getServerConfigTool, h.getServerConfig)
+
+	getOperationTool := mcp.NewTool("get_operation",
+		mcp.WithDescription("Get the status of a GKE long-running operation, such as a cluster upgrade. Use it to follow up on operations started earlier, including ones that were still running when the server restarted."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.operations.get"),
+		explain.Command(h.getOperationCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE location of the operation. Defaults to the session context.")),
+		mcp.WithString("operation_id", mcp.Required(), mcp.Description("ID of the operation, e.g. operation-1234567890123-abcdef12. A full operation resource name is accepted too.")),
+		mcp.WithBoolean("wait", mcp.Description("Wait until the operation is done, reporting its progress, instead of returning its current status. Only set it if the user wants to wait.")),
+	)
+	s.AddTool(getOperationTool, h.getOperation)
+
+	h.addExportTerraformTool(s)
+	h.addConfigConnectorTool(s)
+
+	h.addClusterResources(s)
+	h.registerCompleters(completion.Default)
+
+	if interval := c.InventoryRefresh(); interval > 0 {
+		go h.refreshInventory(ctx, interval)
+	}
+
 	return nil
 }
 
 func (h *handlers) listClusters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	projectID := request.GetString("project_id", h.c.DefaultProjectID())
-	location, _ := request.RequireString("location")
+	projectID := session.ProjectID(ctx, request, h.c)
+	location := request.GetString("location", "")
 	if location == "" {
 		location = "-"
 	}
 
+	refresh := request.GetBool(cache.RefreshArgument, false)
+	sel, err := selector.Parse(request.GetString(selector.Argument, ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	projectIDs := request.GetStringSlice("project_ids", nil)
+	if len(projectIDs) == 0 {
+		h.watched.add(projectID)
+		resp, fetchedAt, err := h.listClustersIn(ctx, projectID, location, refresh)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		resp = filterClusters(resp, sel)
+		list := describeClusters(projectID, resp)
+		list.setFetchedAt(fetchedAt)
+		result, err := structured.Result(formatResponse(resp, fetchedAt), structured.Clusters, list)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return result, nil
+	}
+
+	w, err := stream.NewWriter(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	type listed struct {
+		resp      *containerpb.ListClustersResponse
+		fetchedAt time.Time
+	}
+	section := func(r scan.Result[string, listed]) string {
+		if r.Err != nil {
+			return fmt.Sprintf("Project %s:\nError: %v\n\n", r.Target, r.Err)
+		}
+		return fmt.Sprintf("Project %s:\n%s\n\n", r.Target, formatResponse(r.Value.resp, r.Value.fetchedAt))
+	}
+	// Projects are reported as they are listed when streaming, and in the
+	// order of project_ids otherwise.
+	results := map[string]scan.Result[string, listed]{}
+	var streamErr error
+	for _, p := range projectIDs {
+		h.watched.add(p)
+	}
+	scan.Each(ctx, projectIDs, scan.DefaultWorkers, func(ctx context.Context, projectID string) (listed, error) {
+		resp, fetchedAt, err := h.listClustersIn(ctx, projectID, location, refresh)
+		if err != nil {
+			return listed{}, err
+		}
+		return listed{filterClusters(resp, sel), fetchedAt}, nil
+	}, func(r scan.Result[string, listed]) {
+		results[r.Target] = r
+		if w.Streaming() && streamErr == nil {
+			if streamErr = w.Write(section(r)); streamErr == nil {
+				streamErr = w.Flush()
+			}
+		}
+	})
+	if streamErr != nil {
+		return mcp.NewToolResultError(streamErr.Error()), nil
+	}
+	builder := new(strings.Builder)
+	all := clusterList{Clusters: []clusterDescription{}}
+	for _, projectID := range projectIDs {
+		r := results[projectID]
+		if !w.Streaming() {
+			builder.WriteString(section(r))
+		}
+		if r.Err != nil {
+			all.Errors = append(all.Errors, projectError{Project: r.Target, Error: r.Err.Error()})
+			continue
+		}
+		all.Clusters = append(all.Clusters, describeClusters(r.Target, r.Value.resp).Clusters...)
+		all.setFetchedAt(r.Value.fetchedAt)
+	}
+	if w.Streaming() {
+		fmt.Fprintf(builder, "Streamed the clusters of %d projects: %d clusters found, %d projects failed.", len(projectIDs), len(all.Clusters), len(all.Errors))
+	}
+	result, err := structured.Result(builder.String(), structured.Clusters, all)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+// filterClusters returns the clusters of resp whose labels match sel. resp
+// may be cached, so it is never modified.
+func filterClusters(resp *containerpb.ListClustersResponse, sel selector.Selector) *containerpb.ListClustersResponse {
+	if sel.Empty() {
+		return resp
+	}
+	filtered := &containerpb.ListClustersResponse{MissingZones: resp.GetMissingZones()}
+	for _, cluster := range resp.GetClusters() {
+		if sel.Matches(cluster.GetResourceLabels()) {
+			filtered.Clusters = append(filtered.Clusters, cluster)
+		}
+	}
+	return filtered
+}
+
+// listClustersIn lists the clusters of a project in location, which may be "-"
+// for all locations.
+func (h *handlers) listClustersIn(ctx context.Context, projectID, location string, refresh bool) (*containerpb.ListClustersResponse, time.Time, error) {
 	req := &containerpb.ListClustersRequest{
 		Parent: fmt.Sprintf("projects/%s/locations/%s", projectID, location),
 	}
-	resp, err := h.cmClient.ListClusters(ctx, req)
+	return clustersCache.Get(ctx, h.cacheKey(ctx, req.Parent), h.clustersTTL(), refresh, func(ctx context.Context) (*containerpb.ListClustersResponse, error) {
+		cmClient, err := gcp.ClusterManager(ctx, h.c)
+		if err != nil {
+			return nil, err
+		}
+		return cmClient.ListClusters(ctx, req)
+	})
+}
+
+func (h *handlers) getCluster(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "name")
+	if name == "" {
+		return mcp.NewToolResultError("name argument not set"), nil
+	}
+
+	h.watched.add(projectID)
+	resp, fetchedAt, err := h.fetchCluster(ctx, projectID, location, name, request.GetBool(cache.RefreshArgument, false))
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	return mcp.NewToolResultText(protojson.Format(resp)), nil
+	result, err := structured.Result(formatResponse(resp, fetchedAt), structured.Cluster, describeCluster(projectID, resp))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
 }
 
-func (h *handlers) getCluster(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	projectID, err := request.RequireString("project_id")
+// fetchCluster gets a cluster, from the cache unless refresh is set.
+func (h *handlers) fetchCluster(ctx context.Context, projectID, location, name string, refresh bool) (*containerpb.Cluster, time.Time, error) {
+	req := clusterRequest(projectID, location, name)
+	return clusterCache.Get(ctx, h.cacheKey(ctx, req.Name), h.clustersTTL(), refresh, func(ctx context.Context) (*containerpb.Cluster, error) {
+		cmClient, err := gcp.ClusterManager(ctx, h.c)
+		if err != nil {
+			return nil, err
+		}
+		return cmClient.GetCluster(ctx, req)
+	})
+}
+
+func (h *handlers) getServerConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+
+	req := &containerpb.GetServerConfigRequest{
+		Name: fmt.Sprintf("projects/%s/locations/%s", projectID, location),
+	}
+	refresh := request.GetBool(cache.RefreshArgument, false)
+	resp, fetchedAt, err := serverConfigCache.Get(ctx, h.cacheKey(ctx, req.Name), h.c.CacheTTL(config.CacheServerConfig), refresh, func(ctx context.Context) (*containerpb.ServerConfig, error) {
+		cmClient, err := gcp.ClusterManager(ctx, h.c)
+		if err != nil {
+			return nil, err
+		}
+		return cmClient.GetServerConfig(ctx, req)
+	})
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	location, err := request.RequireString("location")
+
+	return textResult(resp, fetchedAt), nil
+}
+
+func (h *handlers) getOperation(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	location := session.Location(ctx, request, h.c)
+	operationID, err := request.RequireString("operation_id")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	name, err := request.RequireString("name")
+
+	name := operationID
+	if !strings.HasPrefix(name, "projects/") {
+		name = fmt.Sprintf("projects/%s/locations/%s/operations/%s", projectID, location, operationID)
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	req := &containerpb.GetClusterRequest{
-		Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name),
+	poll := func(ctx context.Context, name string) (*containerpb.Operation, error) {
+		return cmClient.GetOperation(ctx, &containerpb.GetOperationRequest{Name: name})
 	}
-	resp, err := h.cmClient.GetCluster(ctx, req)
+	resp, err := poll(ctx, name)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if resp.GetStatus() == containerpb.Operation_DONE {
+		operations.Default.Forget(name)
+	} else if request.GetBool("wait", false) {
+		// The call may time out before the operation is done, then it
+		// returns the latest status.
+		resp, err = operations.Default.Wait(ctx, request, operations.Operation{Name: name, Tool: "get_operation", Target: resp.GetTargetLink()}, poll, nil)
+		if resp == nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(protojson.Format(resp) + "\n" + operations.Describe(resp)), nil
+	}
 
 	return mcp.NewToolResultText(protojson.Format(resp)), nil
 }
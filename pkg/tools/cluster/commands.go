@@ -0,0 +1,91 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"path"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (h *handlers) listClustersCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectIDs := request.GetStringSlice("project_ids", nil)
+	if len(projectIDs) == 0 {
+		projectIDs = []string{session.ProjectID(ctx, request, h.c)}
+	}
+	location := request.GetString("location", "")
+	var commands []string
+	for _, projectID := range projectIDs {
+		if projectID == "" {
+			continue
+		}
+		commands = append(commands, explain.Join("gcloud container clusters list", explain.Flag("location", location), explain.Flag("project", projectID)))
+	}
+	return commands
+}
+
+func (h *handlers) getClusterCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, name := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "name")
+	if projectID == "" || location == "" || name == "" {
+		return nil
+	}
+	return []string{explain.Join("gcloud container clusters describe", name, explain.Flag("location", location), explain.Flag("project", projectID))}
+}
+
+func (h *handlers) getServerConfigCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c)
+	if projectID == "" || location == "" {
+		return nil
+	}
+	return []string{explain.Join("gcloud container get-server-config", explain.Flag("location", location), explain.Flag("project", projectID))}
+}
+
+func (h *handlers) getOperationCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c)
+	operationID := path.Base(request.GetString("operation_id", ""))
+	if projectID == "" || location == "" || operationID == "." {
+		return nil
+	}
+	command := "gcloud container operations describe"
+	if request.GetBool("wait", false) {
+		command = "gcloud container operations wait"
+	}
+	return []string{explain.Join(command, operationID, explain.Flag("location", location), explain.Flag("project", projectID))}
+}
+
+// bulkExportCommands returns the command exporting the clusters of a
+// project, which gcloud only does for all of them at once, in format.
+func (h *handlers) bulkExportCommands(ctx context.Context, request mcp.CallToolRequest, format string) []string {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return nil
+	}
+	return []string{explain.Join("gcloud beta resource-config bulk-export", "--resource-types=ContainerCluster,ContainerNodePool", explain.Flag("resource-format", format), explain.Flag("project", projectID), "--path=.")}
+}
+
+func (h *handlers) exportTerraformCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	return h.bulkExportCommands(ctx, request, "terraform")
+}
+
+func (h *handlers) configConnectorCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	// Desired clusters are only described by the tool.
+	if request.GetString("source", "existing") != "existing" {
+		return nil
+	}
+	return h.bulkExportCommands(ctx, request, "krm")
+}
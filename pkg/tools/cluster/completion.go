@@ -0,0 +1,69 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/completion"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+)
+
+// registerCompleters completes cluster names and locations from the cached
+// clusters of the project being filled in, or the session's project, and
+// namespaces from the cluster being filled in.
+func (h *handlers) registerCompleters(r *completion.Registry) {
+	r.Register(h.completeClusters, "cluster", "cluster_name")
+	r.Register(h.completeLocations, "location")
+	r.Register(h.completeNamespaces, "namespace")
+}
+
+// projectOf returns the project of the arguments filled in so far, falling
+// back to the session's project and then to the configured default.
+func (h *handlers) projectOf(ctx context.Context, args map[string]string) string {
+	for _, projectID := range []string{args["project_id"], args["project"], session.Default.Get(ctx).ProjectID} {
+		if projectID != "" {
+			return projectID
+		}
+	}
+	return h.c.DefaultProjectID()
+}
+
+func (h *handlers) completeClusters(ctx context.Context, args map[string]string) ([]string, error) {
+	location := args["location"]
+	if location == "" {
+		location = "-"
+	}
+	resp, _, err := h.listClustersIn(ctx, h.projectOf(ctx, args), location, false)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, cluster := range resp.GetClusters() {
+		names = append(names, cluster.GetName())
+	}
+	return names, nil
+}
+
+func (h *handlers) completeLocations(ctx context.Context, args map[string]string) ([]string, error) {
+	locations := []string{session.Default.Get(ctx).Location, h.c.DefaultLocation()}
+	resp, _, err := h.listClustersIn(ctx, h.projectOf(ctx, args), "-", false)
+	for _, cluster := range resp.GetClusters() {
+		if args["cluster"] == "" || args["cluster"] == cluster.GetName() {
+			locations = append(locations, cluster.GetLocation())
+		}
+	}
+	return locations, err
+}
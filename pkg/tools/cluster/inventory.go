@@ -0,0 +1,182 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/scan"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+)
+
+// namespacesCache holds the namespaces of clusters, by cluster resource
+// name.
+var namespacesCache = cache.New[[]string]("namespaces")
+
+// maxWatchedProjects bounds the projects whose inventory is refreshed.
+const maxWatchedProjects = 20
+
+// builtinNamespaces exist in every cluster, so they are completed even if
+// the cluster's namespaces can't be listed.
+var builtinNamespaces = []string{"default", "kube-system", "kube-public", "kube-node-lease", "gke-managed-system"}
+
+// watchlist is the projects whose inventory is refreshed, the most recently
+// queried last.
+type watchlist struct {
+	mu       sync.Mutex
+	projects []string
+}
+
+// add moves projectID to the end of the list, dropping the least recently
+// queried project beyond maxWatchedProjects.
+func (w *watchlist) add(projectID string) {
+	if projectID == "" {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.projects = append(slices.DeleteFunc(w.projects, func(p string) bool { return p == projectID }), projectID)
+	if len(w.projects) > maxWatchedProjects {
+		w.projects = slices.Delete(w.projects, 0, len(w.projects)-maxWatchedProjects)
+	}
+}
+
+func (w *watchlist) list() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.projects)
+}
+
+// clustersTTL is how long clusters are served from the cache. When the
+// inventory is refreshed in the background, cached clusters are served until
+// the second refresh after they were fetched is overdue, so queries don't
+// wait for the API while the refresher is running; results show their age.
+func (h *handlers) clustersTTL() time.Duration {
+	ttl := h.c.CacheTTL(config.CacheClusters)
+	if interval := h.c.InventoryRefresh(); interval > 0 {
+		ttl = max(ttl, 2*interval)
+	}
+	return ttl
+}
+
+// refreshInventory refreshes the inventory of the default project and of the
+// projects queried since, every interval until ctx is done. It uses the
+// server's own credentials.
+func (h *handlers) refreshInventory(ctx context.Context, interval time.Duration) {
+	h.watched.add(h.c.DefaultProjectID())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		h.refreshProjects(ctx, h.watched.list())
+		slog.Debug("Refreshed the cluster inventory", "duration", time.Since(start))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshProjects fetches the clusters of projects, with their node pools,
+// and the namespaces of their running clusters into the caches.
+func (h *handlers) refreshProjects(ctx context.Context, projects []string) {
+	for _, r := range scan.Run(ctx, projects, scan.DefaultWorkers, func(ctx context.Context, projectID string) (struct{}, error) {
+		resp, _, err := h.listClustersIn(ctx, projectID, "-", true)
+		if err != nil {
+			return struct{}{}, err
+		}
+		for _, cluster := range resp.GetClusters() {
+			req := clusterRequest(projectID, cluster.GetLocation(), cluster.GetName())
+			// Store the listed cluster, which is as fresh as a get.
+			_, _, _ = clusterCache.Get(ctx, h.cacheKey(ctx, req.Name), h.clustersTTL(), true, func(context.Context) (*containerpb.Cluster, error) {
+				return cluster, nil
+			})
+			if cluster.GetStatus() != containerpb.Cluster_RUNNING {
+				continue
+			}
+			if _, _, err := h.listNamespaces(ctx, projectID, cluster.GetLocation(), cluster.GetName(), true); err != nil {
+				slog.Debug("Failed to refresh the namespaces of a cluster", "cluster", req.Name, "err", err)
+			}
+		}
+		return struct{}{}, nil
+	}) {
+		if r.Err != nil && ctx.Err() == nil {
+			slog.Warn("Failed to refresh the cluster inventory of a project", "project", r.Target, "err", r.Err)
+		}
+	}
+}
+
+func clusterRequest(projectID, location, name string) *containerpb.GetClusterRequest {
+	return &containerpb.GetClusterRequest{
+		Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name),
+	}
+}
+
+// InvalidateNamespaces drops the cached namespaces of a cluster, for the
+// tools creating or deleting them.
+func InvalidateNamespaces(projectID, location, name string) {
+	namespacesCache.Invalidate(clusterRequest(projectID, location, name).Name)
+}
+
+// listNamespaces returns the namespaces of a cluster and when they were
+// listed, possibly from the cache.
+func (h *handlers) listNamespaces(ctx context.Context, projectID, location, name string, refresh bool) ([]string, time.Time, error) {
+	req := clusterRequest(projectID, location, name)
+	return namespacesCache.Get(ctx, h.cacheKey(ctx, req.Name), h.clustersTTL(), refresh, func(ctx context.Context) ([]string, error) {
+		k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+		if err != nil {
+			return nil, err
+		}
+		var list struct {
+			Items []struct {
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+			} `json:"items"`
+		}
+		if err := k.Get(ctx, "/api/v1/namespaces", &list); err != nil {
+			return nil, err
+		}
+		var namespaces []string
+		for _, item := range list.Items {
+			namespaces = append(namespaces, item.Metadata.Name)
+		}
+		return namespaces, nil
+	})
+}
+
+// completeNamespaces completes namespaces from the namespaces of the cluster
+// being filled in, or the session's cluster, along with the built-in ones.
+func (h *handlers) completeNamespaces(ctx context.Context, args map[string]string) ([]string, error) {
+	sc := session.Default.Get(ctx)
+	location := cmp.Or(args["location"], sc.Location, h.c.DefaultLocation())
+	name := cmp.Or(args["cluster"], args["cluster_name"], sc.Cluster, h.c.DefaultCluster())
+	if location == "" || name == "" {
+		return builtinNamespaces, nil
+	}
+	namespaces, _, err := h.listNamespaces(ctx, h.projectOf(ctx, args), location, name, false)
+	return append(namespaces, builtinNamespaces...), err
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWatchlist(t *testing.T) {
+	var w watchlist
+	for i := range maxWatchedProjects + 2 {
+		w.add(fmt.Sprintf("p%d", i))
+	}
+	w.add("p3")
+	w.add("")
+	got := w.list()
+	if len(got) != maxWatchedProjects {
+		t.Fatalf("watchlist has %d projects, want %d", len(got), maxWatchedProjects)
+	}
+	if got[0] != "p2" || got[len(got)-1] != "p3" {
+		t.Errorf("watchlist = %v, want p2 first and p3 last", got)
+	}
+}
+
+func TestClustersTTL(t *testing.T) {
+	h := &handlers{c: config.New("test", config.WithCacheTTL(config.CacheClusters, 30*time.Second))}
+	if got := h.clustersTTL(); got != 30*time.Second {
+		t.Errorf("clustersTTL() without refresh = %v, want 30s", got)
+	}
+	h = &handlers{c: config.New("test", config.WithCacheTTL(config.CacheClusters, 30*time.Second), config.WithInventoryRefresh(5*time.Minute))}
+	if got := h.clustersTTL(); got != 10*time.Minute {
+		t.Errorf("clustersTTL() with a 5m refresh = %v, want 10m", got)
+	}
+}
+
+func TestSetFetchedAt(t *testing.T) {
+	var l clusterList
+	newer := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	older := newer.Add(-time.Minute)
+	for _, at := range []time.Time{newer, {}, older, newer} {
+		l.setFetchedAt(at)
+	}
+	if want := "2025-06-01T11:59:00Z"; l.FetchedAt != want {
+		t.Errorf("FetchedAt = %q, want %q", l.FetchedAt, want)
+	}
+}
+
+func TestCompleteNamespaces(t *testing.T) {
+	h := &handlers{c: config.New("test", config.WithInventoryRefresh(time.Minute))}
+	ctx := context.Background()
+	req := clusterRequest("p", "us-central1", "c")
+	_, _, err := namespacesCache.Get(ctx, h.cacheKey(ctx, req.Name), time.Minute, true, func(context.Context) ([]string, error) {
+		return []string{"payments", "default"}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer namespacesCache.Invalidate(req.Name)
+
+	got, err := h.completeNamespaces(ctx, map[string]string{"project_id": "p", "location": "us-central1", "cluster": "c"})
+	if err != nil {
+		t.Fatalf("completeNamespaces() failed: %v", err)
+	}
+	want := append([]string{"payments", "default"}, builtinNamespaces...)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("completeNamespaces() mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = h.completeNamespaces(ctx, map[string]string{"project_id": "p"})
+	if err != nil {
+		t.Fatalf("completeNamespaces() without a cluster failed: %v", err)
+	}
+	if diff := cmp.Diff(builtinNamespaces, got); diff != "" {
+		t.Errorf("completeNamespaces() without a cluster mismatch (-want +got):\n%s", diff)
+	}
+}
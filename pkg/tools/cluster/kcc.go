@@ -0,0 +1,380 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// kccAPIVersion is the API version of the Config Connector GKE resources.
+const kccAPIVersion = "container.cnrm.cloud.google.com/v1beta1"
+
+func (h *handlers) addConfigConnectorTool(s *server.MCPServer) {
+	tool := mcp.NewTool("generate_config_connector",
+		mcp.WithDescription("Generate Config Connector (KCC) ContainerCluster and ContainerNodePool manifests, either for an existing GKE cluster, so GitOps teams can manage it through Kubernetes, or for a desired cluster described by the arguments. The manifests are only returned, never applied."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.get"),
+		explain.Command(h.configConnectorCommands),
+		mcp.WithString("source", mcp.Enum("existing", "desired"), mcp.DefaultString("existing"), mcp.Description("existing to export a cluster that exists, or desired to describe a new one with the arguments below.")),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("name", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("namespace", mcp.Description("Kubernetes namespace of the manifests, e.g. the namespace Config Connector manages the project from. Omitted if empty.")),
+		mcp.WithBoolean("autopilot", mcp.Description("For a desired cluster: create an Autopilot cluster.")),
+		mcp.WithString("release_channel", mcp.Enum("RAPID", "REGULAR", "STABLE", "EXTENDED"), mcp.Description("For a desired cluster: release channel. Defaults to REGULAR.")),
+		mcp.WithString("network", mcp.Description("For a desired cluster: VPC network name. Defaults to default.")),
+		mcp.WithString("subnetwork", mcp.Description("For a desired cluster: subnetwork name.")),
+		mcp.WithString("machine_type", mcp.Description("For a desired Standard cluster: machine type of the node pool. Defaults to e2-standard-4.")),
+		mcp.WithNumber("node_count", mcp.Description("For a desired Standard cluster: nodes per zone of the node pool. Defaults to 1.")),
+		mcp.WithNumber("max_node_count", mcp.Description("For a desired Standard cluster: enable autoscaling of the node pool up to this many nodes per zone.")),
+		mcp.WithBoolean("spot", mcp.Description("For a desired Standard cluster: use Spot VMs for the node pool.")),
+		cache.RefreshOption(),
+	)
+	s.AddTool(tool, h.generateConfigConnector)
+}
+
+func (h *handlers) generateConfigConnector(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "name")
+	if name == "" {
+		return mcp.NewToolResultError("name argument not set"), nil
+	}
+	namespace := request.GetString("namespace", "")
+
+	source := request.GetString("source", "existing")
+	var cluster *containerpb.Cluster
+	switch source {
+	case "existing":
+		var err error
+		cluster, _, err = h.fetchCluster(ctx, projectID, location, name, request.GetBool(cache.RefreshArgument, false))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	case "desired":
+		cluster = desiredCluster(name, location, request)
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid source %q, must be existing or desired", source)), nil
+	}
+
+	manifests, err := configConnector(projectID, namespace, cluster, source == "existing")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(manifests), nil
+}
+
+// desiredCluster returns the cluster described by the arguments of request.
+func desiredCluster(name, location string, request mcp.CallToolRequest) *containerpb.Cluster {
+	channel := containerpb.ReleaseChannel_Channel(containerpb.ReleaseChannel_Channel_value[request.GetString("release_channel", "REGULAR")])
+	cluster := &containerpb.Cluster{
+		Name:                   name,
+		Location:               location,
+		Network:                request.GetString("network", "default"),
+		Subnetwork:             request.GetString("subnetwork", ""),
+		ReleaseChannel:         &containerpb.ReleaseChannel{Channel: channel},
+		IpAllocationPolicy:     &containerpb.IPAllocationPolicy{UseIpAliases: true},
+		WorkloadIdentityConfig: &containerpb.WorkloadIdentityConfig{},
+	}
+	if request.GetBool("autopilot", false) {
+		cluster.Autopilot = &containerpb.Autopilot{Enabled: true}
+		return cluster
+	}
+	pool := &containerpb.NodePool{
+		Name:             "default-pool",
+		InitialNodeCount: int32(request.GetInt("node_count", 1)),
+		Management:       &containerpb.NodeManagement{AutoRepair: true, AutoUpgrade: true},
+		Config: &containerpb.NodeConfig{
+			MachineType:            request.GetString("machine_type", "e2-standard-4"),
+			Spot:                   request.GetBool("spot", false),
+			WorkloadMetadataConfig: &containerpb.WorkloadMetadataConfig{Mode: containerpb.WorkloadMetadataConfig_GKE_METADATA},
+		},
+	}
+	if maxNodes := request.GetInt("max_node_count", 0); maxNodes > 0 {
+		pool.Autoscaling = &containerpb.NodePoolAutoscaling{Enabled: true, MinNodeCount: pool.InitialNodeCount, MaxNodeCount: int32(maxNodes)}
+	}
+	cluster.NodePools = []*containerpb.NodePool{pool}
+	return cluster
+}
+
+type kccResource struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   kccMetadata `yaml:"metadata"`
+	Spec       any         `yaml:"spec"`
+}
+
+type kccMetadata struct {
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+}
+
+type kccRef struct {
+	Name     string `yaml:"name,omitempty"`
+	External string `yaml:"external,omitempty"`
+}
+
+type kccClusterSpec struct {
+	ResourceID                     string                     `yaml:"resourceID,omitempty"`
+	Location                       string                     `yaml:"location"`
+	EnableAutopilot                bool                       `yaml:"enableAutopilot,omitempty"`
+	InitialNodeCount               int32                      `yaml:"initialNodeCount,omitempty"`
+	NodeLocations                  []string                   `yaml:"nodeLocations,omitempty"`
+	NetworkRef                     *kccRef                    `yaml:"networkRef,omitempty"`
+	SubnetworkRef                  *kccRef                    `yaml:"subnetworkRef,omitempty"`
+	NetworkingMode                 string                     `yaml:"networkingMode,omitempty"`
+	DatapathProvider               string                     `yaml:"datapathProvider,omitempty"`
+	ReleaseChannel                 map[string]string          `yaml:"releaseChannel,omitempty"`
+	IPAllocationPolicy             map[string]string          `yaml:"ipAllocationPolicy,omitempty"`
+	PrivateClusterConfig           map[string]any             `yaml:"privateClusterConfig,omitempty"`
+	MasterAuthorizedNetworksConfig map[string]any             `yaml:"masterAuthorizedNetworksConfig,omitempty"`
+	WorkloadIdentityConfig         map[string]string          `yaml:"workloadIdentityConfig,omitempty"`
+	MaintenancePolicy              map[string]any             `yaml:"maintenancePolicy,omitempty"`
+	AddonsConfig                   map[string]map[string]bool `yaml:"addonsConfig,omitempty"`
+}
+
+type kccNodePoolSpec struct {
+	ResourceID       string           `yaml:"resourceID,omitempty"`
+	Location         string           `yaml:"location"`
+	ClusterRef       kccRef           `yaml:"clusterRef"`
+	NodeLocations    []string         `yaml:"nodeLocations,omitempty"`
+	InitialNodeCount int32            `yaml:"initialNodeCount,omitempty"`
+	Autoscaling      map[string]any   `yaml:"autoscaling,omitempty"`
+	Management       map[string]bool  `yaml:"management,omitempty"`
+	UpgradeSettings  map[string]int32 `yaml:"upgradeSettings,omitempty"`
+	NodeConfig       kccNodeConfig    `yaml:"nodeConfig"`
+}
+
+type kccNodeConfig struct {
+	MachineType            string              `yaml:"machineType,omitempty"`
+	DiskSizeGb             int32               `yaml:"diskSizeGb,omitempty"`
+	DiskType               string              `yaml:"diskType,omitempty"`
+	ImageType              string              `yaml:"imageType,omitempty"`
+	Spot                   bool                `yaml:"spot,omitempty"`
+	Preemptible            bool                `yaml:"preemptible,omitempty"`
+	ServiceAccountRef      *kccRef             `yaml:"serviceAccountRef,omitempty"`
+	OauthScopes            []string            `yaml:"oauthScopes,omitempty"`
+	Tags                   []string            `yaml:"tags,omitempty"`
+	Labels                 map[string]string   `yaml:"labels,omitempty"`
+	GuestAccelerator       []map[string]any    `yaml:"guestAccelerator,omitempty"`
+	Taint                  []map[string]string `yaml:"taint,omitempty"`
+	WorkloadMetadataConfig map[string]string   `yaml:"workloadMetadataConfig,omitempty"`
+}
+
+var kubernetesName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?$`)
+
+// kccMeta returns the metadata of a resource named after a GCP resource,
+// which is kept as its resource ID if it isn't a valid Kubernetes name.
+func kccMeta(name, namespace string, annotations map[string]string) (kccMetadata, string) {
+	m := kccMetadata{Name: name, Namespace: namespace, Annotations: annotations}
+	if kubernetesName.MatchString(name) {
+		return m, ""
+	}
+	m.Name = strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(name), "-"), "-_")
+	m.Name = strings.ReplaceAll(m.Name, "_", "-")
+	return m, name
+}
+
+// configConnector renders a cluster and its node pools as Config Connector
+// manifests. Manifests of existing resources are annotated so deleting them
+// abandons the resources instead of deleting them.
+func configConnector(projectID, namespace string, cluster *containerpb.Cluster, existing bool) (string, error) {
+	annotations := func() map[string]string {
+		a := map[string]string{"cnrm.cloud.google.com/project-id": projectID}
+		if existing {
+			a["cnrm.cloud.google.com/deletion-policy"] = "abandon"
+		}
+		return a
+	}
+	autopilot := cluster.GetAutopilot().GetEnabled()
+
+	clusterAnnotations := annotations()
+	if !autopilot {
+		clusterAnnotations["cnrm.cloud.google.com/remove-default-node-pool"] = "true"
+	}
+	meta, resourceID := kccMeta(cluster.GetName(), namespace, clusterAnnotations)
+	meta.Labels = cluster.GetResourceLabels()
+	spec := kccClusterSpec{
+		ResourceID:      resourceID,
+		Location:        cluster.GetLocation(),
+		EnableAutopilot: autopilot,
+	}
+	if !autopilot {
+		spec.InitialNodeCount = 1
+		if locations := cluster.GetLocations(); len(locations) > 1 || len(locations) == 1 && locations[0] != cluster.GetLocation() {
+			spec.NodeLocations = locations
+		}
+	}
+	if network := cluster.GetNetwork(); network != "" {
+		spec.NetworkRef = &kccRef{External: network}
+	}
+	if subnetwork := cluster.GetSubnetwork(); subnetwork != "" {
+		spec.SubnetworkRef = &kccRef{External: subnetwork}
+	}
+	if cluster.GetNetworkConfig().GetDatapathProvider() == containerpb.DatapathProvider_ADVANCED_DATAPATH {
+		spec.DatapathProvider = "ADVANCED_DATAPATH"
+	}
+	if channel := cluster.GetReleaseChannel().GetChannel(); channel != containerpb.ReleaseChannel_UNSPECIFIED {
+		spec.ReleaseChannel = map[string]string{"channel": channel.String()}
+	}
+	if p := cluster.GetIpAllocationPolicy(); p.GetUseIpAliases() {
+		spec.NetworkingMode = "VPC_NATIVE"
+		spec.IPAllocationPolicy = map[string]string{}
+		if p.GetClusterSecondaryRangeName() != "" || p.GetServicesSecondaryRangeName() != "" {
+			spec.IPAllocationPolicy["clusterSecondaryRangeName"] = p.GetClusterSecondaryRangeName()
+			spec.IPAllocationPolicy["servicesSecondaryRangeName"] = p.GetServicesSecondaryRangeName()
+		} else if p.GetClusterIpv4CidrBlock() != "" {
+			spec.IPAllocationPolicy["clusterIpv4CidrBlock"] = p.GetClusterIpv4CidrBlock()
+			spec.IPAllocationPolicy["servicesIpv4CidrBlock"] = p.GetServicesIpv4CidrBlock()
+		}
+	}
+	if p := cluster.GetPrivateClusterConfig(); p.GetEnablePrivateNodes() {
+		spec.PrivateClusterConfig = map[string]any{
+			"enablePrivateNodes":    true,
+			"enablePrivateEndpoint": p.GetEnablePrivateEndpoint(),
+		}
+		if p.GetMasterIpv4CidrBlock() != "" {
+			spec.PrivateClusterConfig["masterIpv4CidrBlock"] = p.GetMasterIpv4CidrBlock()
+		}
+	}
+	if m := cluster.GetMasterAuthorizedNetworksConfig(); m.GetEnabled() {
+		var blocks []map[string]string
+		for _, block := range m.GetCidrBlocks() {
+			b := map[string]string{"cidrBlock": block.GetCidrBlock()}
+			if block.GetDisplayName() != "" {
+				b["displayName"] = block.GetDisplayName()
+			}
+			blocks = append(blocks, b)
+		}
+		spec.MasterAuthorizedNetworksConfig = map[string]any{"cidrBlocks": blocks}
+	}
+	if wi := cluster.GetWorkloadIdentityConfig(); wi != nil {
+		pool := wi.GetWorkloadPool()
+		if pool == "" && !existing {
+			pool = projectID + ".svc.id.goog"
+		}
+		if pool != "" {
+			spec.WorkloadIdentityConfig = map[string]string{"workloadPool": pool}
+		}
+	}
+	if daily := cluster.GetMaintenancePolicy().GetWindow().GetDailyMaintenanceWindow(); daily != nil {
+		spec.MaintenancePolicy = map[string]any{"dailyMaintenanceWindow": map[string]string{"startTime": daily.GetStartTime()}}
+	}
+	if addons := cluster.GetAddonsConfig(); !autopilot && (addons.GetHttpLoadBalancing().GetDisabled() || addons.GetHorizontalPodAutoscaling().GetDisabled()) {
+		spec.AddonsConfig = map[string]map[string]bool{
+			"httpLoadBalancing":        {"disabled": addons.GetHttpLoadBalancing().GetDisabled()},
+			"horizontalPodAutoscaling": {"disabled": addons.GetHorizontalPodAutoscaling().GetDisabled()},
+		}
+	}
+
+	resources := []kccResource{{APIVersion: kccAPIVersion, Kind: "ContainerCluster", Metadata: meta, Spec: spec}}
+	if !autopilot {
+		for _, np := range cluster.GetNodePools() {
+			resources = append(resources, kccNodePool(namespace, meta.Name, cluster.GetLocation(), np, annotations()))
+		}
+	}
+
+	var b bytes.Buffer
+	for i, r := range resources {
+		if i > 0 {
+			b.WriteString("---\n")
+		}
+		enc := yaml.NewEncoder(&b)
+		enc.SetIndent(2)
+		if err := enc.Encode(r); err != nil {
+			return "", fmt.Errorf("failed to render the %s manifest: %w", r.Kind, err)
+		}
+		enc.Close()
+	}
+	return b.String(), nil
+}
+
+func kccNodePool(namespace, clusterName, location string, np *containerpb.NodePool, annotations map[string]string) kccResource {
+	// Node pool names are only unique within their cluster, so the manifest
+	// is named after both and keeps the pool name as its resource ID.
+	meta, _ := kccMeta(clusterName+"-"+np.GetName(), namespace, annotations)
+	spec := kccNodePoolSpec{
+		ResourceID:       np.GetName(),
+		Location:         location,
+		ClusterRef:       kccRef{Name: clusterName},
+		NodeLocations:    np.GetLocations(),
+		InitialNodeCount: np.GetInitialNodeCount(),
+	}
+	if a := np.GetAutoscaling(); a.GetEnabled() {
+		spec.Autoscaling = map[string]any{}
+		if a.GetTotalMaxNodeCount() > 0 {
+			spec.Autoscaling["totalMinNodeCount"] = a.GetTotalMinNodeCount()
+			spec.Autoscaling["totalMaxNodeCount"] = a.GetTotalMaxNodeCount()
+		} else {
+			spec.Autoscaling["minNodeCount"] = a.GetMinNodeCount()
+			spec.Autoscaling["maxNodeCount"] = a.GetMaxNodeCount()
+		}
+		if policy := a.GetLocationPolicy(); policy != containerpb.NodePoolAutoscaling_LOCATION_POLICY_UNSPECIFIED {
+			spec.Autoscaling["locationPolicy"] = policy.String()
+		}
+	}
+	if m := np.GetManagement(); m != nil {
+		spec.Management = map[string]bool{"autoRepair": m.GetAutoRepair(), "autoUpgrade": m.GetAutoUpgrade()}
+	}
+	if u := np.GetUpgradeSettings(); u != nil && u.GetStrategy() != containerpb.NodePoolUpdateStrategy_BLUE_GREEN {
+		spec.UpgradeSettings = map[string]int32{"maxSurge": u.GetMaxSurge(), "maxUnavailable": u.GetMaxUnavailable()}
+	}
+
+	config := np.GetConfig()
+	spec.NodeConfig = kccNodeConfig{
+		MachineType: config.GetMachineType(),
+		DiskSizeGb:  config.GetDiskSizeGb(),
+		DiskType:    config.GetDiskType(),
+		ImageType:   config.GetImageType(),
+		Spot:        config.GetSpot(),
+		Preemptible: config.GetPreemptible() && !config.GetSpot(),
+		OauthScopes: config.GetOauthScopes(),
+		Tags:        config.GetTags(),
+		Labels:      config.GetLabels(),
+	}
+	if sa := config.GetServiceAccount(); sa != "" && sa != "default" {
+		spec.NodeConfig.ServiceAccountRef = &kccRef{External: sa}
+	}
+	for _, accelerator := range config.GetAccelerators() {
+		spec.NodeConfig.GuestAccelerator = append(spec.NodeConfig.GuestAccelerator, map[string]any{"type": accelerator.GetAcceleratorType(), "count": accelerator.GetAcceleratorCount()})
+	}
+	for _, taint := range config.GetTaints() {
+		spec.NodeConfig.Taint = append(spec.NodeConfig.Taint, map[string]string{"key": taint.GetKey(), "value": taint.GetValue(), "effect": taint.GetEffect().String()})
+	}
+	if config.GetWorkloadMetadataConfig().GetMode() == containerpb.WorkloadMetadataConfig_GKE_METADATA {
+		spec.NodeConfig.WorkloadMetadataConfig = map[string]string{"mode": "GKE_METADATA"}
+	}
+	return kccResource{APIVersion: kccAPIVersion, Kind: "ContainerNodePool", Metadata: meta, Spec: spec}
+}
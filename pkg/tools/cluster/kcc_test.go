@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestConfigConnector(t *testing.T) {
+	cluster := &containerpb.Cluster{
+		Name:           "prod-1",
+		Location:       "us-central1",
+		Network:        "vpc",
+		ResourceLabels: map[string]string{"team": "payments"},
+		ReleaseChannel: &containerpb.ReleaseChannel{Channel: containerpb.ReleaseChannel_STABLE},
+		IpAllocationPolicy: &containerpb.IPAllocationPolicy{
+			UseIpAliases:               true,
+			ClusterSecondaryRangeName:  "pods",
+			ServicesSecondaryRangeName: "services",
+		},
+		WorkloadIdentityConfig: &containerpb.WorkloadIdentityConfig{WorkloadPool: "p.svc.id.goog"},
+		NodePools: []*containerpb.NodePool{
+			{
+				Name:        "gpu-pool",
+				Autoscaling: &containerpb.NodePoolAutoscaling{Enabled: true, MinNodeCount: 0, MaxNodeCount: 4},
+				Config: &containerpb.NodeConfig{
+					MachineType:  "g2-standard-8",
+					Accelerators: []*containerpb.AcceleratorConfig{{AcceleratorType: "nvidia-l4", AcceleratorCount: 1}},
+					Taints:       []*containerpb.NodeTaint{{Key: "nvidia.com/gpu", Value: "present", Effect: containerpb.NodeTaint_NO_SCHEDULE}},
+				},
+			},
+		},
+	}
+
+	got, err := configConnector("p", "config-control", cluster, true)
+	if err != nil {
+		t.Fatalf("configConnector() failed: %v", err)
+	}
+	for _, want := range []string{
+		"apiVersion: container.cnrm.cloud.google.com/v1beta1\nkind: ContainerCluster\nmetadata:\n  name: prod-1\n  namespace: config-control",
+		"cnrm.cloud.google.com/deletion-policy: abandon",
+		"cnrm.cloud.google.com/project-id: p",
+		"cnrm.cloud.google.com/remove-default-node-pool: \"true\"",
+		"labels:\n    team: payments",
+		"networkRef:\n    external: vpc",
+		"releaseChannel:\n    channel: STABLE",
+		"clusterSecondaryRangeName: pods",
+		"workloadPool: p.svc.id.goog",
+		"---\napiVersion: container.cnrm.cloud.google.com/v1beta1\nkind: ContainerNodePool\nmetadata:\n  name: prod-1-gpu-pool",
+		"resourceID: gpu-pool",
+		"clusterRef:\n    name: prod-1",
+		"maxNodeCount: 4",
+		"guestAccelerator:\n      - count: 1\n        type: nvidia-l4",
+		"effect: NO_SCHEDULE",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("configConnector() doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestConfigConnectorDesired(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		arguments map[string]any
+		want      []string
+		notWant   []string
+	}{
+		{
+			name:      "standard",
+			arguments: map[string]any{"machine_type": "n2-standard-8", "node_count": 2, "max_node_count": 5, "spot": true},
+			want: []string{
+				"releaseChannel:\n    channel: REGULAR",
+				"networkingMode: VPC_NATIVE",
+				"workloadPool: p.svc.id.goog",
+				"kind: ContainerNodePool",
+				"initialNodeCount: 2",
+				"maxNodeCount: 5",
+				"machineType: n2-standard-8",
+				"spot: true",
+				"mode: GKE_METADATA",
+			},
+			notWant: []string{"deletion-policy"},
+		},
+		{
+			name:      "autopilot",
+			arguments: map[string]any{"autopilot": true, "release_channel": "RAPID"},
+			want:      []string{"enableAutopilot: true", "channel: RAPID"},
+			notWant:   []string{"ContainerNodePool", "remove-default-node-pool"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.arguments
+			got, err := configConnector("p", "", desiredCluster("new", "us-central1", request), false)
+			if err != nil {
+				t.Fatalf("configConnector() failed: %v", err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("configConnector() doesn't contain %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("configConnector() contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
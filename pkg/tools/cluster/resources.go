@@ -0,0 +1,219 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// clusterURITemplate is the URI of clusters published as resources. Reading
+// one returns its description as JSON, or as YAML with format=yaml.
+const clusterURITemplate = "gke://{project}/{location}/{cluster}{?format}"
+
+// clusterURI returns the URI of a cluster resource.
+func clusterURI(projectID, location, name string) string {
+	return fmt.Sprintf("gke://%s/%s/%s", projectID, location, name)
+}
+
+// addClusterResources publishes every cluster as a resource, so clients can
+// attach one as context without calling a tool. The default cluster, if
+// any, is listed as well.
+func (h *handlers) addClusterResources(s *server.MCPServer) {
+	s.AddResourceTemplate(mcp.NewResourceTemplate(clusterURITemplate, "GKE cluster",
+		mcp.WithTemplateDescription("A GKE cluster: its version, release channel, networking and node pools. Add ?format=yaml to the URI for YAML instead of JSON."),
+		mcp.WithTemplateMIMEType("application/json"),
+	), h.readCluster)
+
+	if h.c.DefaultProjectID() != "" && h.c.DefaultLocation() != "" && h.c.DefaultCluster() != "" {
+		s.AddResource(mcp.NewResource(clusterURI(h.c.DefaultProjectID(), h.c.DefaultLocation(), h.c.DefaultCluster()), h.c.DefaultCluster(),
+			mcp.WithResourceDescription("The default GKE cluster"),
+			mcp.WithMIMEType("application/json"),
+		), h.readCluster)
+	}
+}
+
+// parseClusterURI returns the cluster and the format of a cluster resource
+// URI.
+func parseClusterURI(uri string) (projectID, location, name, format string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("invalid cluster URI %q: %w", uri, err)
+	}
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if u.Scheme != "gke" || u.Host == "" || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", "", fmt.Errorf("invalid cluster URI %q, want gke://PROJECT/LOCATION/CLUSTER", uri)
+	}
+	format = u.Query().Get("format")
+	switch format {
+	case "":
+		format = "json"
+	case "json", "yaml":
+	default:
+		return "", "", "", "", fmt.Errorf("unsupported format %q, must be json or yaml", format)
+	}
+	return u.Host, parts[0], parts[1], format, nil
+}
+
+func (h *handlers) readCluster(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	projectID, location, name, format, err := parseClusterURI(request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	cluster, _, err := h.fetchCluster(ctx, projectID, location, name, false)
+	if err != nil {
+		return nil, err
+	}
+	d := describeCluster(projectID, cluster)
+
+	var data []byte
+	mimeType := "application/json"
+	if format == "yaml" {
+		mimeType = "application/yaml"
+		data, err = yaml.Marshal(d)
+	} else {
+		data, err = json.MarshalIndent(d, "", "  ")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: mimeType,
+			Text:     string(data),
+		},
+	}, nil
+}
+
+// clusterList is the structured result of list_clusters.
+type clusterList struct {
+	Clusters []clusterDescription `json:"clusters"`
+	Errors   []projectError       `json:"errors,omitempty"`
+	// FetchedAt is when the oldest of the clusters were read from the API,
+	// in RFC 3339 format.
+	FetchedAt string `json:"fetched_at,omitempty"`
+}
+
+// setFetchedAt records that some of the clusters were read from the API at
+// t, keeping the oldest time.
+func (l *clusterList) setFetchedAt(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	if old, err := time.Parse(time.RFC3339, l.FetchedAt); err == nil && old.Before(t) {
+		return
+	}
+	l.FetchedAt = t.UTC().Format(time.RFC3339)
+}
+
+type projectError struct {
+	Project string `json:"project"`
+	Error   string `json:"error"`
+}
+
+func describeClusters(projectID string, resp *containerpb.ListClustersResponse) clusterList {
+	l := clusterList{Clusters: []clusterDescription{}}
+	for _, cluster := range resp.GetClusters() {
+		l.Clusters = append(l.Clusters, describeCluster(projectID, cluster))
+	}
+	return l
+}
+
+// clusterDescription is the normalized description of a cluster: the fields
+// an agent needs for context, without the full API response. It is also the
+// structured result of get_cluster, following the cluster schema.
+type clusterDescription struct {
+	Name                 string                `json:"name" yaml:"name"`
+	Project              string                `json:"project" yaml:"project"`
+	Location             string                `json:"location" yaml:"location"`
+	Mode                 string                `json:"mode" yaml:"mode"`
+	Status               string                `json:"status" yaml:"status"`
+	Version              string                `json:"version" yaml:"version"`
+	ReleaseChannel       string                `json:"release_channel,omitempty" yaml:"release_channel,omitempty"`
+	Endpoint             string                `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Network              string                `json:"network,omitempty" yaml:"network,omitempty"`
+	Subnetwork           string                `json:"subnetwork,omitempty" yaml:"subnetwork,omitempty"`
+	PrivateNodes         bool                  `json:"private_nodes" yaml:"private_nodes"`
+	WorkloadIdentityPool string                `json:"workload_identity_pool,omitempty" yaml:"workload_identity_pool,omitempty"`
+	Labels               map[string]string     `json:"labels,omitempty" yaml:"labels,omitempty"`
+	CreateTime           string                `json:"create_time,omitempty" yaml:"create_time,omitempty"`
+	NodePools            []nodePoolDescription `json:"node_pools,omitempty" yaml:"node_pools,omitempty"`
+}
+
+type nodePoolDescription struct {
+	Name             string   `json:"name" yaml:"name"`
+	Status           string   `json:"status" yaml:"status"`
+	Version          string   `json:"version" yaml:"version"`
+	MachineType      string   `json:"machine_type,omitempty" yaml:"machine_type,omitempty"`
+	Spot             bool     `json:"spot,omitempty" yaml:"spot,omitempty"`
+	Locations        []string `json:"locations,omitempty" yaml:"locations,omitempty"`
+	InitialNodeCount int32    `json:"initial_node_count,omitempty" yaml:"initial_node_count,omitempty"`
+	// Autoscaling is the range of nodes per zone, e.g. "1-3", or "" if the
+	// pool doesn't autoscale.
+	Autoscaling string `json:"autoscaling,omitempty" yaml:"autoscaling,omitempty"`
+}
+
+func describeCluster(projectID string, cluster *containerpb.Cluster) clusterDescription {
+	d := clusterDescription{
+		Name:                 cluster.GetName(),
+		Project:              projectID,
+		Location:             cluster.GetLocation(),
+		Mode:                 "standard",
+		Status:               cluster.GetStatus().String(),
+		Version:              cluster.GetCurrentMasterVersion(),
+		Endpoint:             cluster.GetEndpoint(),
+		Network:              cluster.GetNetwork(),
+		Subnetwork:           cluster.GetSubnetwork(),
+		PrivateNodes:         cluster.GetPrivateClusterConfig().GetEnablePrivateNodes() || cluster.GetNetworkConfig().GetDefaultEnablePrivateNodes(),
+		WorkloadIdentityPool: cluster.GetWorkloadIdentityConfig().GetWorkloadPool(),
+		Labels:               cluster.GetResourceLabels(),
+		CreateTime:           cluster.GetCreateTime(),
+	}
+	if cluster.GetAutopilot().GetEnabled() {
+		d.Mode = "autopilot"
+	}
+	if channel := cluster.GetReleaseChannel().GetChannel(); channel != containerpb.ReleaseChannel_UNSPECIFIED {
+		d.ReleaseChannel = channel.String()
+	}
+	for _, np := range cluster.GetNodePools() {
+		npd := nodePoolDescription{
+			Name:             np.GetName(),
+			Status:           np.GetStatus().String(),
+			Version:          np.GetVersion(),
+			MachineType:      np.GetConfig().GetMachineType(),
+			Spot:             np.GetConfig().GetSpot(),
+			Locations:        np.GetLocations(),
+			InitialNodeCount: np.GetInitialNodeCount(),
+		}
+		if a := np.GetAutoscaling(); a.GetEnabled() {
+			npd.Autoscaling = fmt.Sprintf("%d-%d", a.GetMinNodeCount(), a.GetMaxNodeCount())
+			if a.GetTotalMaxNodeCount() > 0 {
+				npd.Autoscaling = fmt.Sprintf("%d-%d in total", a.GetTotalMinNodeCount(), a.GetTotalMaxNodeCount())
+			}
+		}
+		d.NodePools = append(d.NodePools, npd)
+	}
+	return d
+}
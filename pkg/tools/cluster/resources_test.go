@@ -0,0 +1,91 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseClusterURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    []string
+		wantErr bool
+	}{
+		{uri: "gke://p/us-central1/c", want: []string{"p", "us-central1", "c", "json"}},
+		{uri: "gke://p/us-central1-a/c?format=yaml", want: []string{"p", "us-central1-a", "c", "yaml"}},
+		{uri: "gke://p/us-central1/c?format=xml", wantErr: true},
+		{uri: "gke://p/us-central1", wantErr: true},
+		{uri: "gke://p/us-central1/c/extra", wantErr: true},
+		{uri: "https://p/us-central1/c", wantErr: true},
+	}
+	for _, tc := range tests {
+		projectID, location, name, format, err := parseClusterURI(tc.uri)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseClusterURI(%q) succeeded, want an error", tc.uri)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseClusterURI(%q) failed: %v", tc.uri, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, []string{projectID, location, name, format}); diff != "" {
+			t.Errorf("parseClusterURI(%q) mismatch (-want +got):\n%s", tc.uri, diff)
+		}
+	}
+}
+
+func TestDescribeCluster(t *testing.T) {
+	cluster := &containerpb.Cluster{
+		Name:                 "c",
+		Location:             "us-central1",
+		Status:               containerpb.Cluster_RUNNING,
+		CurrentMasterVersion: "1.30.5-gke.100",
+		ReleaseChannel:       &containerpb.ReleaseChannel{Channel: containerpb.ReleaseChannel_REGULAR},
+		Network:              "default",
+		PrivateClusterConfig: &containerpb.PrivateClusterConfig{EnablePrivateNodes: true},
+		NodePools: []*containerpb.NodePool{
+			{
+				Name:        "pool",
+				Status:      containerpb.NodePool_RUNNING,
+				Version:     "1.30.5-gke.100",
+				Config:      &containerpb.NodeConfig{MachineType: "e2-standard-4", Spot: true},
+				Autoscaling: &containerpb.NodePoolAutoscaling{Enabled: true, MinNodeCount: 1, MaxNodeCount: 3},
+			},
+		},
+	}
+	want := clusterDescription{
+		Name:           "c",
+		Project:        "p",
+		Location:       "us-central1",
+		Mode:           "standard",
+		Status:         "RUNNING",
+		Version:        "1.30.5-gke.100",
+		ReleaseChannel: "REGULAR",
+		Network:        "default",
+		PrivateNodes:   true,
+		NodePools: []nodePoolDescription{
+			{Name: "pool", Status: "RUNNING", Version: "1.30.5-gke.100", MachineType: "e2-standard-4", Spot: true, Autoscaling: "1-3"},
+		},
+	}
+	if diff := cmp.Diff(want, describeCluster("p", cluster)); diff != "" {
+		t.Errorf("describeCluster() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func (h *handlers) addExportTerraformTool(s *server.MCPServer) {
+	tool := mcp.NewTool("export_terraform",
+		mcp.WithDescription("Export an existing GKE cluster and its node pools as Terraform HCL (google_container_cluster and google_container_node_pool resources), with import blocks to adopt them without recreating anything. Use it when the user wants to manage a cluster created by hand with Terraform. For every resource of a project, suggest `gcloud beta resource-config bulk-export --resource-format=terraform` instead."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.get"),
+		explain.Command(h.exportTerraformCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("name", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithBoolean("imports", mcp.DefaultBool(true), mcp.Description("Add import blocks (Terraform 1.5 or later) for the cluster and its node pools.")),
+		cache.RefreshOption(),
+	)
+	s.AddTool(tool, h.exportTerraform)
+}
+
+func (h *handlers) exportTerraform(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "name")
+	if name == "" {
+		return mcp.NewToolResultError("name argument not set"), nil
+	}
+
+	cluster, _, err := h.fetchCluster(ctx, projectID, location, name, request.GetBool(cache.RefreshArgument, false))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(terraform(projectID, cluster, request.GetBool("imports", true))), nil
+}
+
+// hclWriter writes Terraform HCL.
+type hclWriter struct {
+	b      strings.Builder
+	indent int
+}
+
+func (w *hclWriter) line(format string, args ...any) {
+	fmt.Fprintf(&w.b, "%s%s\n", strings.Repeat("  ", w.indent), fmt.Sprintf(format, args...))
+}
+
+func (w *hclWriter) block(header string, body func()) {
+	w.line("%s {", header)
+	w.indent++
+	body()
+	w.indent--
+	w.line("}")
+}
+
+// attr writes an attribute, unless its value is empty or zero. Booleans are
+// always written.
+func (w *hclWriter) attr(name string, value any) {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			w.line("%s = %s", name, hclString(v))
+		}
+	case bool:
+		w.line("%s = %t", name, v)
+	case int32:
+		if v != 0 {
+			w.line("%s = %d", name, v)
+		}
+	case int64:
+		if v != 0 {
+			w.line("%s = %d", name, v)
+		}
+	case []string:
+		if len(v) > 0 {
+			var quoted []string
+			for _, s := range v {
+				quoted = append(quoted, hclString(s))
+			}
+			w.line("%s = [%s]", name, strings.Join(quoted, ", "))
+		}
+	case map[string]string:
+		if len(v) > 0 {
+			w.block(name+" =", func() {
+				for _, k := range slices.Sorted(maps.Keys(v)) {
+					w.line("%s = %s", hclString(k), hclString(v[k]))
+				}
+			})
+		}
+	default:
+		panic(fmt.Sprintf("unsupported HCL value %T", value))
+	}
+}
+
+// hclString quotes s, escaping template sequences.
+func hclString(s string) string {
+	q := strconv.Quote(s)
+	q = strings.ReplaceAll(q, "${", "$${")
+	return strings.ReplaceAll(q, "%{", "%%{")
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// identifier returns a Terraform resource name for a GKE resource name.
+func identifier(name string) string {
+	id := nonIdentifier.ReplaceAllString(name, "_")
+	if id == "" || id[0] >= '0' && id[0] <= '9' {
+		id = "r_" + id
+	}
+	return id
+}
+
+// terraform renders a cluster and its node pools as Terraform resources of
+// the Google provider. Only the settings teams usually manage are exported,
+// and computed ones such as versions are left to the release channel.
+func terraform(projectID string, cluster *containerpb.Cluster, imports bool) string {
+	w := &hclWriter{}
+	autopilot := cluster.GetAutopilot().GetEnabled()
+	clusterID := identifier(cluster.GetName())
+	clusterName := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, cluster.GetLocation(), cluster.GetName())
+
+	w.line("# Exported by gke-mcp from %s.", clusterName)
+	w.line("# Format it with terraform fmt, and review terraform plan before applying: only commonly managed settings are exported.")
+	w.line("")
+	if imports {
+		w.block("import", func() {
+			w.line("to = google_container_cluster.%s", clusterID)
+			w.attr("id", clusterName)
+		})
+		w.line("")
+	}
+
+	w.block(fmt.Sprintf("resource \"google_container_cluster\" %q", clusterID), func() {
+		w.attr("name", cluster.GetName())
+		w.attr("project", projectID)
+		w.attr("location", cluster.GetLocation())
+		w.attr("description", cluster.GetDescription())
+		if autopilot {
+			w.attr("enable_autopilot", true)
+		} else {
+			w.line("# Node pools are managed by the google_container_node_pool resources below.")
+			w.attr("remove_default_node_pool", true)
+			w.attr("initial_node_count", int32(1))
+			if locations := cluster.GetLocations(); len(locations) > 1 || len(locations) == 1 && locations[0] != cluster.GetLocation() {
+				w.attr("node_locations", locations)
+			}
+		}
+		w.attr("network", cluster.GetNetwork())
+		w.attr("subnetwork", cluster.GetSubnetwork())
+		if cluster.GetNetworkConfig().GetDatapathProvider() == containerpb.DatapathProvider_ADVANCED_DATAPATH {
+			w.attr("datapath_provider", "ADVANCED_DATAPATH")
+		}
+		w.attr("resource_labels", cluster.GetResourceLabels())
+		w.attr("deletion_protection", true)
+
+		if channel := cluster.GetReleaseChannel().GetChannel(); channel != containerpb.ReleaseChannel_UNSPECIFIED {
+			w.block("release_channel", func() {
+				w.attr("channel", channel.String())
+			})
+		}
+		if p := cluster.GetIpAllocationPolicy(); p.GetUseIpAliases() {
+			w.attr("networking_mode", "VPC_NATIVE")
+			w.block("ip_allocation_policy", func() {
+				if p.GetClusterSecondaryRangeName() != "" || p.GetServicesSecondaryRangeName() != "" {
+					w.attr("cluster_secondary_range_name", p.GetClusterSecondaryRangeName())
+					w.attr("services_secondary_range_name", p.GetServicesSecondaryRangeName())
+				} else {
+					w.attr("cluster_ipv4_cidr_block", p.GetClusterIpv4CidrBlock())
+					w.attr("services_ipv4_cidr_block", p.GetServicesIpv4CidrBlock())
+				}
+			})
+		}
+		if p := cluster.GetPrivateClusterConfig(); p.GetEnablePrivateNodes() {
+			w.block("private_cluster_config", func() {
+				w.attr("enable_private_nodes", true)
+				w.attr("enable_private_endpoint", p.GetEnablePrivateEndpoint())
+				w.attr("master_ipv4_cidr_block", p.GetMasterIpv4CidrBlock())
+			})
+		}
+		if m := cluster.GetMasterAuthorizedNetworksConfig(); m.GetEnabled() {
+			w.block("master_authorized_networks_config", func() {
+				for _, block := range m.GetCidrBlocks() {
+					w.block("cidr_blocks", func() {
+						w.attr("cidr_block", block.GetCidrBlock())
+						w.attr("display_name", block.GetDisplayName())
+					})
+				}
+			})
+		}
+		if pool := cluster.GetWorkloadIdentityConfig().GetWorkloadPool(); pool != "" {
+			w.block("workload_identity_config", func() {
+				w.attr("workload_pool", pool)
+			})
+		}
+		if window := cluster.GetMaintenancePolicy().GetWindow(); window != nil {
+			if daily := window.GetDailyMaintenanceWindow(); daily != nil {
+				w.block("maintenance_policy", func() {
+					w.block("daily_maintenance_window", func() {
+						w.attr("start_time", daily.GetStartTime())
+					})
+				})
+			} else if recurring := window.GetRecurringWindow(); recurring != nil {
+				w.block("maintenance_policy", func() {
+					w.block("recurring_window", func() {
+						w.attr("start_time", recurring.GetWindow().GetStartTime().AsTime().UTC().Format("2006-01-02T15:04:05Z"))
+						w.attr("end_time", recurring.GetWindow().GetEndTime().AsTime().UTC().Format("2006-01-02T15:04:05Z"))
+						w.attr("recurrence", recurring.GetRecurrence())
+					})
+				})
+			}
+		}
+		if !autopilot {
+			if addons := cluster.GetAddonsConfig(); addons.GetHttpLoadBalancing().GetDisabled() || addons.GetHorizontalPodAutoscaling().GetDisabled() {
+				w.block("addons_config", func() {
+					w.block("http_load_balancing", func() {
+						w.attr("disabled", addons.GetHttpLoadBalancing().GetDisabled())
+					})
+					w.block("horizontal_pod_autoscaling", func() {
+						w.attr("disabled", addons.GetHorizontalPodAutoscaling().GetDisabled())
+					})
+				})
+			}
+		}
+	})
+
+	if autopilot {
+		return w.b.String()
+	}
+	for _, np := range cluster.GetNodePools() {
+		poolID := identifier(cluster.GetName() + "_" + np.GetName())
+		w.line("")
+		if imports {
+			w.block("import", func() {
+				w.line("to = google_container_node_pool.%s", poolID)
+				w.attr("id", clusterName+"/nodePools/"+np.GetName())
+			})
+			w.line("")
+		}
+		w.block(fmt.Sprintf("resource \"google_container_node_pool\" %q", poolID), func() {
+			w.attr("name", np.GetName())
+			w.attr("project", projectID)
+			w.attr("location", cluster.GetLocation())
+			w.line("cluster = google_container_cluster.%s.name", clusterID)
+			w.attr("node_locations", np.GetLocations())
+
+			if a := np.GetAutoscaling(); a.GetEnabled() {
+				w.block("autoscaling", func() {
+					if a.GetTotalMaxNodeCount() > 0 {
+						w.attr("total_min_node_count", a.GetTotalMinNodeCount())
+						w.attr("total_max_node_count", a.GetTotalMaxNodeCount())
+					} else {
+						w.line("min_node_count = %d", a.GetMinNodeCount())
+						w.attr("max_node_count", a.GetMaxNodeCount())
+					}
+					if policy := a.GetLocationPolicy(); policy != containerpb.NodePoolAutoscaling_LOCATION_POLICY_UNSPECIFIED {
+						w.attr("location_policy", policy.String())
+					}
+				})
+			} else {
+				w.line("# The node count of the pool when it was created; set the current one.")
+				w.line("node_count = %d", np.GetInitialNodeCount())
+			}
+			if m := np.GetManagement(); m != nil {
+				w.block("management", func() {
+					w.attr("auto_repair", m.GetAutoRepair())
+					w.attr("auto_upgrade", m.GetAutoUpgrade())
+				})
+			}
+			if u := np.GetUpgradeSettings(); u != nil && u.GetStrategy() != containerpb.NodePoolUpdateStrategy_BLUE_GREEN {
+				w.block("upgrade_settings", func() {
+					w.line("max_surge = %d", u.GetMaxSurge())
+					w.line("max_unavailable = %d", u.GetMaxUnavailable())
+				})
+			}
+
+			config := np.GetConfig()
+			w.block("node_config", func() {
+				w.attr("machine_type", config.GetMachineType())
+				w.attr("disk_size_gb", config.GetDiskSizeGb())
+				w.attr("disk_type", config.GetDiskType())
+				w.attr("image_type", config.GetImageType())
+				if config.GetSpot() {
+					w.attr("spot", true)
+				} else if config.GetPreemptible() {
+					w.attr("preemptible", true)
+				}
+				w.attr("service_account", config.GetServiceAccount())
+				w.attr("oauth_scopes", config.GetOauthScopes())
+				w.attr("tags", config.GetTags())
+				w.attr("labels", config.GetLabels())
+				for _, accelerator := range config.GetAccelerators() {
+					w.block("guest_accelerator", func() {
+						w.attr("type", accelerator.GetAcceleratorType())
+						w.attr("count", accelerator.GetAcceleratorCount())
+					})
+				}
+				for _, taint := range config.GetTaints() {
+					w.block("taint", func() {
+						w.attr("key", taint.GetKey())
+						w.attr("value", taint.GetValue())
+						w.attr("effect", taint.GetEffect().String())
+					})
+				}
+				if config.GetWorkloadMetadataConfig().GetMode() == containerpb.WorkloadMetadataConfig_GKE_METADATA {
+					w.block("workload_metadata_config", func() {
+						w.attr("mode", "GKE_METADATA")
+					})
+				}
+			})
+		})
+	}
+	return w.b.String()
+}
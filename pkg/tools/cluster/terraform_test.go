@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+)
+
+func TestTerraform(t *testing.T) {
+	cluster := &containerpb.Cluster{
+		Name:           "prod-1",
+		Location:       "us-central1",
+		Network:        "vpc",
+		Subnetwork:     "gke-${env}",
+		ResourceLabels: map[string]string{"team": "payments", "env": "prod"},
+		ReleaseChannel: &containerpb.ReleaseChannel{Channel: containerpb.ReleaseChannel_REGULAR},
+		IpAllocationPolicy: &containerpb.IPAllocationPolicy{
+			UseIpAliases:               true,
+			ClusterSecondaryRangeName:  "pods",
+			ServicesSecondaryRangeName: "services",
+		},
+		PrivateClusterConfig:   &containerpb.PrivateClusterConfig{EnablePrivateNodes: true, MasterIpv4CidrBlock: "172.16.0.0/28"},
+		WorkloadIdentityConfig: &containerpb.WorkloadIdentityConfig{WorkloadPool: "p.svc.id.goog"},
+		NodePools: []*containerpb.NodePool{
+			{
+				Name:        "gpu-pool",
+				Autoscaling: &containerpb.NodePoolAutoscaling{Enabled: true, MinNodeCount: 0, MaxNodeCount: 4},
+				Management:  &containerpb.NodeManagement{AutoRepair: true, AutoUpgrade: true},
+				Config: &containerpb.NodeConfig{
+					MachineType:  "g2-standard-8",
+					Spot:         true,
+					Accelerators: []*containerpb.AcceleratorConfig{{AcceleratorType: "nvidia-l4", AcceleratorCount: 1}},
+					Taints:       []*containerpb.NodeTaint{{Key: "nvidia.com/gpu", Value: "present", Effect: containerpb.NodeTaint_NO_SCHEDULE}},
+				},
+			},
+		},
+	}
+
+	got := terraform("p", cluster, true)
+	for _, want := range []string{
+		"to = google_container_cluster.prod_1\n  id = \"projects/p/locations/us-central1/clusters/prod-1\"",
+		`resource "google_container_cluster" "prod_1" {`,
+		"remove_default_node_pool = true",
+		`subnetwork = "gke-$${env}"`,
+		"resource_labels = {\n    \"env\" = \"prod\"\n    \"team\" = \"payments\"\n  }",
+		"release_channel {\n    channel = \"REGULAR\"\n  }",
+		`cluster_secondary_range_name = "pods"`,
+		`master_ipv4_cidr_block = "172.16.0.0/28"`,
+		`workload_pool = "p.svc.id.goog"`,
+		`id = "projects/p/locations/us-central1/clusters/prod-1/nodePools/gpu-pool"`,
+		`resource "google_container_node_pool" "prod_1_gpu_pool" {`,
+		"cluster = google_container_cluster.prod_1.name",
+		"min_node_count = 0\n    max_node_count = 4",
+		"spot = true",
+		"guest_accelerator {\n      type = \"nvidia-l4\"\n      count = 1",
+		`effect = "NO_SCHEDULE"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("terraform() = %s\nwant it to contain %q", got, want)
+		}
+	}
+
+	// Autopilot clusters have no node pools to manage, and imports are
+	// optional.
+	cluster.Autopilot = &containerpb.Autopilot{Enabled: true}
+	got = terraform("p", cluster, false)
+	if !strings.Contains(got, "enable_autopilot = true") || strings.Contains(got, "google_container_node_pool") || strings.Contains(got, "import {") {
+		t.Errorf("terraform() of an Autopilot cluster without imports = %s", got)
+	}
+}
@@ -16,40 +16,76 @@ package clustertoolkit
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func Install(_ context.Context, s *server.MCPServer, _ *config.Config) error {
+const repositoryURL = "https://github.com/GoogleCloudPlatform/cluster-toolkit.git"
+
+func init() {
+	plugin.Register(plugin.New("clustertoolkit", Install))
+}
+
+type handlers struct {
+	c *config.Config
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
 	clusterToolkitDownloadTool := mcp.NewTool("cluster_toolkit_download",
 		mcp.WithDescription("Cluster Toolkit, is open-source software offered by Google Cloud which simplifies the process for you to create Google Kubernetes Engine clusters and deploy high performance computing (HPC), artificial intelligence (AI), and machine learning (ML). It is designed to be highly customizable and extensible, and intends to address the deployment needs of a broad range of use cases. This tool will download the public git repository so that Cluster Toolkit can be used."),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithIdempotentHintAnnotation(true),
+		catalog.Describe(catalog.AI, catalog.Write),
+		explain.Command(clusterToolkitDownloadCommands),
 		mcp.WithString("download_directory", mcp.Required(), mcp.Description("Download directory for the git repo. By default use the absolute path to the current working directory.")),
+		dryrun.Argument(c),
 	)
-	s.AddTool(clusterToolkitDownloadTool, clusterToolkitDownload)
+	s.AddTool(clusterToolkitDownloadTool, h.clusterToolkitDownload)
 
 	return nil
 }
 
-func clusterToolkitDownload(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// downloadDir returns the directory the repository is cloned into, a
+// sub-directory of dir unless dir is already one.
+func downloadDir(dir string) string {
+	if !strings.HasSuffix(dir, "cluster-toolkit") {
+		dir = filepath.Join(dir, "cluster-toolkit")
+	}
+	return dir
+}
+
+func clusterToolkitDownloadCommands(_ context.Context, request mcp.CallToolRequest) []string {
+	dir := request.GetString("download_directory", "")
+	if dir == "" {
+		return nil
+	}
+	return []string{explain.Join("git clone", repositoryURL, downloadDir(dir))}
+}
+
+func (h *handlers) clusterToolkitDownload(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	download_dir, err := request.RequireString("download_directory")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	// Make sure we download into a sub-directory
-	if !strings.HasSuffix(download_dir, "cluster-toolkit") {
-		download_dir = filepath.Join(download_dir, "cluster-toolkit")
+	if dryrun.Enabled(request, h.c) {
+		return dryrun.Describe(fmt.Sprintf("clone %s into %s", repositoryURL, downloadDir(download_dir))), nil
 	}
-	out, err := exec.Command("git", "clone", "https://github.com/GoogleCloudPlatform/cluster-toolkit.git", download_dir).Output()
+	out, err := exec.CommandContext(ctx, "git", "clone", repositoryURL, downloadDir(download_dir)).Output()
 	if err != nil {
-		log.Printf("Failed to download Cluster Toolkit: %v %s", err, out)
+		slog.Error("Failed to download Cluster Toolkit", "err", err, "output", string(out))
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 	return mcp.NewToolResultText(string(out)), nil
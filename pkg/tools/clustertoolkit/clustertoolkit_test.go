@@ -0,0 +1,39 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package clustertoolkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestClusterToolkitDownloadDryRun(t *testing.T) {
+	h := &handlers{c: config.New("test")}
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"download_directory": "/work", "dry_run": true}
+	result, err := h.clusterToolkitDownload(context.Background(), request)
+	if err != nil {
+		t.Fatalf("clusterToolkitDownload() failed: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "/work/cluster-toolkit") {
+		t.Errorf("clusterToolkitDownload() = %q, want a dry run cloning into /work/cluster-toolkit", text)
+	}
+}
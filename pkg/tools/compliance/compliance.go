@@ -0,0 +1,152 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("compliance", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	cisBenchmarkReportTool := mcp.NewTool("cis_benchmark_report",
+		mcp.WithDescription(fmt.Sprintf("Evaluate a GKE cluster against the controls of the %s that can be checked through the GKE and Kubernetes APIs, such as logging, RBAC, Workload Identity, Shielded GKE Nodes, node pool settings and network configuration. Returns a score per section and overall, and for every control its status, the evidence and the remediation of failures.", benchmark)),
+		catalog.Describe(catalog.Security, catalog.Read, "container.clusters.get", "container.clusterRoleBindings.list", "container.clusterRoles.list", "container.roles.list", "container.serviceAccounts.list", "container.pods.list"),
+		explain.Command(h.cisBenchmarkReportCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithNumber("level", mcp.Description("Benchmark profile: 1 checks the Level 1 controls, 2 the Level 1 and Level 2 controls. Defaults to 2.")),
+	)
+	s.AddTool(cisBenchmarkReportTool, h.cisBenchmarkReport)
+
+	return nil
+}
+
+func (h *handlers) cisBenchmarkReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	level := request.GetInt("level", 2)
+	if level != 1 && level != 2 {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid level %d, must be 1 or 2", level)), nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resource := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: resource})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	e := &evidence{Cluster: cluster}
+	e.KubeErr = h.readObjects(ctx, e, projectID, location, name)
+
+	r := evaluate(e, level, strings.NewReplacer("{cluster}", name, "{location}", location, "{project}", projectID))
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(r.summary()+"\n\n"+string(data), structured.Findings, r.findingList(resource))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+// readObjects reads the Kubernetes objects the RBAC and namespace controls
+// check into e.
+func (h *handlers) readObjects(ctx context.Context, e *evidence, projectID, location, cluster string) error {
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, cluster)
+	if err != nil {
+		return err
+	}
+	var bindings struct {
+		Items []roleBinding `json:"items"`
+	}
+	var clusterRoles, roles struct {
+		Items []role `json:"items"`
+	}
+	var serviceAccounts struct {
+		Items []serviceAccount `json:"items"`
+	}
+	var pods struct {
+		Items []pod `json:"items"`
+	}
+	for path, into := range map[string]any{
+		"/apis/rbac.authorization.k8s.io/v1/clusterrolebindings": &bindings,
+		"/apis/rbac.authorization.k8s.io/v1/clusterroles":        &clusterRoles,
+		"/apis/rbac.authorization.k8s.io/v1/roles":               &roles,
+		"/api/v1/serviceaccounts":                                &serviceAccounts,
+		"/api/v1/namespaces/default/pods":                        &pods,
+	} {
+		if err := k.Get(ctx, path, into); err != nil {
+			return err
+		}
+	}
+	e.ClusterRoleBindings, e.ClusterRoles, e.Roles, e.ServiceAccounts, e.DefaultPods = bindings.Items, clusterRoles.Items, roles.Items, serviceAccounts.Items, pods.Items
+	return nil
+}
+
+func (h *handlers) cisBenchmarkReportCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud container clusters describe", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--format=yaml"),
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get clusterrolebindings,clusterroles --output=yaml",
+		"kubectl get roles,serviceaccounts --all-namespaces --output=yaml",
+		"kubectl get pods --namespace=default",
+	}
+}
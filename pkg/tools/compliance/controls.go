@@ -0,0 +1,733 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+)
+
+// benchmark is the version of the CIS GKE Benchmark the controls follow.
+const benchmark = "CIS Google Kubernetes Engine (GKE) Benchmark v1.5.0"
+
+// Statuses of the controls.
+const (
+	pass          = "pass"
+	fail          = "fail"
+	notApplicable = "not-applicable"
+	unknown       = "unknown"
+)
+
+// maxEvidence is the number of evidence lines kept per control.
+const maxEvidence = 10
+
+// subject is a subject of a role binding.
+type subject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+func (s subject) String() string {
+	if s.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", s.Kind, s.Namespace, s.Name)
+	}
+	return s.Kind + " " + s.Name
+}
+
+// roleBinding is the part of a ClusterRoleBinding or RoleBinding checked.
+type roleBinding struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	RoleRef struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"roleRef"`
+	Subjects []subject `json:"subjects"`
+}
+
+// role is the part of a ClusterRole or Role checked.
+type role struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Rules []struct {
+		APIGroups []string `json:"apiGroups"`
+		Resources []string `json:"resources"`
+		Verbs     []string `json:"verbs"`
+	} `json:"rules"`
+}
+
+// serviceAccount is the part of a Kubernetes service account checked.
+type serviceAccount struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	AutomountServiceAccountToken *bool `json:"automountServiceAccountToken"`
+}
+
+// pod is the part of a pod checked.
+type pod struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+}
+
+// evidence is what the controls are checked against.
+type evidence struct {
+	Cluster *containerpb.Cluster
+	// KubeErr is why the Kubernetes objects couldn't be read, if they
+	// couldn't, in which case the controls needing them are unknown.
+	KubeErr             error
+	ClusterRoleBindings []roleBinding
+	ClusterRoles        []role
+	Roles               []role
+	ServiceAccounts     []serviceAccount
+	DefaultPods         []pod
+}
+
+// outcome is the status of a control and the evidence for it.
+type outcome struct {
+	status   string
+	evidence []string
+}
+
+func passed(format string, args ...any) outcome {
+	return outcome{status: pass, evidence: []string{fmt.Sprintf(format, args...)}}
+}
+
+func failed(format string, args ...any) outcome {
+	return outcome{status: fail, evidence: []string{fmt.Sprintf(format, args...)}}
+}
+
+// control is a control of the benchmark that can be checked through the
+// GKE and Kubernetes APIs.
+type control struct {
+	id      string
+	section string
+	title   string
+	level   int
+	// kube tells whether the control checks Kubernetes objects.
+	kube bool
+	// fix remediates the control; {cluster}, {location}, {project} and
+	// {pool} are replaced with those of the cluster, or of the node pools
+	// failing.
+	fix   string
+	check func(e *evidence) outcome
+}
+
+// Sections of the benchmark.
+const (
+	rbacSection     = "4.1 RBAC and Service Accounts"
+	generalSection  = "4.6 General Policies"
+	imageSection    = "5.1 Image Registry and Image Scanning"
+	iamSection      = "5.2 Identity and Access Management (IAM)"
+	kmsSection      = "5.3 Cloud Key Management Service (Cloud KMS)"
+	metadataSection = "5.4 Node Metadata"
+	nodeSection     = "5.5 Node Configuration and Maintenance"
+	networkSection  = "5.6 Cluster Networking"
+	loggingSection  = "5.7 Logging"
+	authSection     = "5.8 Authentication and Authorization"
+	storageSection  = "5.9 Storage"
+	otherSection    = "5.10 Other Cluster Configurations"
+)
+
+// defaultComputeSA is the suffix of the Compute Engine default service
+// account.
+const defaultComputeSA = "-compute@developer.gserviceaccount.com"
+
+// kubernetesLogging is the legacy logging service of GKE clusters.
+const kubernetesLogging = "logging.googleapis.com/kubernetes"
+
+// systemNamespace tells whether GKE or Kubernetes manages the namespace.
+func systemNamespace(ns string) bool {
+	return strings.HasPrefix(ns, "kube-") || strings.HasPrefix(ns, "gke-") || strings.HasPrefix(ns, "gmp-")
+}
+
+// systemName tells whether Kubernetes or GKE created the RBAC object.
+func systemName(name string) bool {
+	return strings.HasPrefix(name, "system:") || strings.HasPrefix(name, "gce:") || strings.HasPrefix(name, "gke-") || strings.HasPrefix(name, "gke:") || strings.HasPrefix(name, "cloud-provider")
+}
+
+// defaultRoles are the user-facing roles of Kubernetes, which use wildcards
+// by design.
+var defaultRoles = []string{"cluster-admin", "admin", "edit", "view"}
+
+// limit keeps the first maxEvidence lines of evidence.
+func limit(lines []string) []string {
+	if len(lines) > maxEvidence {
+		return append(lines[:maxEvidence:maxEvidence], fmt.Sprintf("... and %d more", len(lines)-maxEvidence))
+	}
+	return lines
+}
+
+// poolCheck fails for the node pools bad returns a reason for. Google
+// manages the nodes of Autopilot clusters, so it doesn't apply to them.
+func poolCheck(bad func(np *containerpb.NodePool) string) func(e *evidence) outcome {
+	return func(e *evidence) outcome {
+		if e.Cluster.GetAutopilot().GetEnabled() {
+			return outcome{status: notApplicable, evidence: []string{"Google manages the nodes of Autopilot clusters."}}
+		}
+		var reasons []string
+		for _, np := range e.Cluster.GetNodePools() {
+			if reason := bad(np); reason != "" {
+				reasons = append(reasons, fmt.Sprintf("Node pool %s %s.", np.GetName(), reason))
+			}
+		}
+		if len(reasons) > 0 {
+			return outcome{status: fail, evidence: limit(reasons)}
+		}
+		return passed("All %d node pools comply.", len(e.Cluster.GetNodePools()))
+	}
+}
+
+// flag passes if on tells the setting of the cluster is on.
+func flag(on func(c *containerpb.Cluster) bool, yes, no string) func(e *evidence) outcome {
+	return func(e *evidence) outcome {
+		if on(e.Cluster) {
+			return passed("%s", yes)
+		}
+		return failed("%s", no)
+	}
+}
+
+// publicEndpoint tells whether the control plane has a public endpoint.
+func publicEndpoint(c *containerpb.Cluster) bool {
+	if ip := c.GetControlPlaneEndpointsConfig().GetIpEndpointsConfig(); ip != nil {
+		return ip.GetEnabled() && ip.GetEnablePublicEndpoint()
+	}
+	return !c.GetPrivateClusterConfig().GetEnablePrivateEndpoint()
+}
+
+// authorizedNetworks returns the CIDR blocks allowed to reach the control
+// plane, and whether the allowlist is enabled.
+func authorizedNetworks(c *containerpb.Cluster) ([]string, bool) {
+	config := c.GetMasterAuthorizedNetworksConfig()
+	if ip := c.GetControlPlaneEndpointsConfig().GetIpEndpointsConfig(); ip.GetAuthorizedNetworksConfig() != nil {
+		config = ip.GetAuthorizedNetworksConfig()
+	}
+	var cidrs []string
+	for _, b := range config.GetCidrBlocks() {
+		cidrs = append(cidrs, b.GetCidrBlock())
+	}
+	return cidrs, config.GetEnabled()
+}
+
+// controls are the controls checked, in the order of the benchmark.
+var controls = []control{
+	{
+		id: "4.1.1", section: rbacSection, level: 1, kube: true,
+		title: "Ensure that the cluster-admin role is only used where required",
+		fix:   "Bind narrower roles to the subjects listed, then delete the ClusterRoleBindings with kubectl delete clusterrolebinding.",
+		check: func(e *evidence) outcome {
+			var uses []string
+			for _, b := range e.ClusterRoleBindings {
+				if b.RoleRef.Name != "cluster-admin" || systemName(b.Metadata.Name) {
+					continue
+				}
+				for _, s := range b.Subjects {
+					if s.Kind == "Group" && s.Name == "system:masters" {
+						continue
+					}
+					uses = append(uses, fmt.Sprintf("ClusterRoleBinding %s grants cluster-admin to %s.", b.Metadata.Name, s))
+				}
+			}
+			if len(uses) > 0 {
+				return outcome{status: fail, evidence: limit(uses)}
+			}
+			return passed("Only system bindings grant cluster-admin.")
+		},
+	},
+	{
+		id: "4.1.3", section: rbacSection, level: 1, kube: true,
+		title: "Minimize wildcard use in Roles and ClusterRoles",
+		fix:   "Replace the wildcards of the roles listed with the API groups, resources and verbs they need.",
+		check: func(e *evidence) outcome {
+			var wildcards []string
+			for _, r := range append(slices.Clone(e.ClusterRoles), e.Roles...) {
+				name := r.Metadata.Name
+				if systemName(name) || systemNamespace(r.Metadata.Namespace) || (r.Metadata.Namespace == "" && slices.Contains(defaultRoles, name)) {
+					continue
+				}
+				kind := "ClusterRole " + name
+				if r.Metadata.Namespace != "" {
+					kind = fmt.Sprintf("Role %s/%s", r.Metadata.Namespace, name)
+				}
+				for _, rule := range r.Rules {
+					if slices.Contains(rule.APIGroups, "*") || slices.Contains(rule.Resources, "*") || slices.Contains(rule.Verbs, "*") {
+						wildcards = append(wildcards, kind+" uses wildcards.")
+						break
+					}
+				}
+			}
+			if len(wildcards) > 0 {
+				return outcome{status: fail, evidence: limit(wildcards)}
+			}
+			return passed("No custom role uses wildcards.")
+		},
+	},
+	{
+		id: "4.1.5", section: rbacSection, level: 1, kube: true,
+		title: "Ensure that default service accounts are not actively used",
+		fix:   `Set automountServiceAccountToken: false on the default service accounts listed, e.g. kubectl patch serviceaccount default --namespace=NAMESPACE -p '{"automountServiceAccountToken":false}', and give the workloads needing a token their own service account.`,
+		check: func(e *evidence) outcome {
+			var mounted []string
+			for _, sa := range e.ServiceAccounts {
+				if sa.Metadata.Name != "default" || systemNamespace(sa.Metadata.Namespace) {
+					continue
+				}
+				if sa.AutomountServiceAccountToken == nil || *sa.AutomountServiceAccountToken {
+					mounted = append(mounted, fmt.Sprintf("The default service account of namespace %s mounts its token.", sa.Metadata.Namespace))
+				}
+			}
+			if len(mounted) > 0 {
+				return outcome{status: fail, evidence: limit(mounted)}
+			}
+			return passed("No default service account mounts its token.")
+		},
+	},
+	{
+		id: "4.6.4", section: generalSection, level: 2, kube: true,
+		title: "The default namespace should not be used",
+		fix:   "Move the workloads of the default namespace to namespaces of their own.",
+		check: func(e *evidence) outcome {
+			if len(e.DefaultPods) == 0 {
+				return passed("No pod runs in the default namespace.")
+			}
+			var names []string
+			for _, p := range e.DefaultPods {
+				names = append(names, p.Metadata.Name)
+			}
+			return failed("%d pods run in the default namespace: %s.", len(names), strings.Join(limit(names), ", "))
+		},
+	},
+	{
+		id: "5.1.1", section: imageSection, level: 1,
+		title: "Ensure Image Vulnerability Scanning is enabled",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --workload-vulnerability-scanning=standard",
+		check: func(e *evidence) outcome {
+			mode := e.Cluster.GetSecurityPostureConfig().GetVulnerabilityMode()
+			if mode == containerpb.SecurityPostureConfig_VULNERABILITY_BASIC || mode == containerpb.SecurityPostureConfig_VULNERABILITY_ENTERPRISE {
+				return passed("Workload vulnerability scanning is %s.", mode)
+			}
+			return failed("Workload vulnerability scanning is disabled.")
+		},
+	},
+	{
+		id: "5.2.1", section: iamSection, level: 1,
+		title: "Ensure GKE clusters are not running using the Compute Engine default service account",
+		fix:   "Create a node pool running as a least privileged service account with gcloud container node-pools create --service-account, move the workloads to it and delete node pool {pool}.",
+		check: poolCheck(func(np *containerpb.NodePool) string {
+			if sa := np.GetConfig().GetServiceAccount(); sa == "" || sa == "default" || strings.HasSuffix(sa, defaultComputeSA) {
+				return "runs as the Compute Engine default service account"
+			}
+			return ""
+		}),
+	},
+	{
+		id: "5.2.2", section: iamSection, level: 1,
+		title: "Prefer using dedicated GCP Service Accounts and Workload Identity",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --workload-pool={project}.svc.id.goog",
+		check: func(e *evidence) outcome {
+			if pool := e.Cluster.GetWorkloadIdentityConfig().GetWorkloadPool(); pool != "" {
+				return passed("Workload Identity is enabled with workload pool %s.", pool)
+			}
+			return failed("Workload Identity is disabled, so workloads call Google Cloud as the node service account.")
+		},
+	},
+	{
+		id: "5.3.1", section: kmsSection, level: 1,
+		title: "Ensure Kubernetes Secrets are encrypted using keys managed in Cloud KMS",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --database-encryption-key=projects/KMS_PROJECT/locations/KMS_LOCATION/keyRings/RING/cryptoKeys/KEY",
+		check: func(e *evidence) outcome {
+			if db := e.Cluster.GetDatabaseEncryption(); db.GetState() == containerpb.DatabaseEncryption_ENCRYPTED {
+				return passed("Secrets are encrypted at the application layer with %s.", db.GetKeyName())
+			}
+			return failed("Application-layer secrets encryption is disabled.")
+		},
+	},
+	{
+		id: "5.4.1", section: metadataSection, level: 1,
+		title: "Ensure legacy Compute Engine instance metadata APIs are Disabled",
+		fix:   "Recreate node pool {pool} without the disable-legacy-endpoints=false metadata.",
+		check: poolCheck(func(np *containerpb.NodePool) string {
+			if np.GetConfig().GetMetadata()["disable-legacy-endpoints"] == "false" {
+				return "serves the legacy metadata endpoints"
+			}
+			return ""
+		}),
+	},
+	{
+		id: "5.4.2", section: metadataSection, level: 1,
+		title: "Ensure the GKE Metadata Server is Enabled",
+		fix:   "gcloud container node-pools update {pool} --cluster={cluster} --location={location} --project={project} --workload-metadata=GKE_METADATA",
+		check: poolCheck(func(np *containerpb.NodePool) string {
+			if np.GetConfig().GetWorkloadMetadataConfig().GetMode() != containerpb.WorkloadMetadataConfig_GKE_METADATA {
+				return "doesn't run the GKE metadata server"
+			}
+			return ""
+		}),
+	},
+	{
+		id: "5.5.1", section: nodeSection, level: 2,
+		title: "Ensure Container-Optimized OS (cos_containerd) is used for GKE node images",
+		fix:   "gcloud container clusters upgrade {cluster} --location={location} --project={project} --node-pool={pool} --image-type=COS_CONTAINERD",
+		check: poolCheck(func(np *containerpb.NodePool) string {
+			if image := np.GetConfig().GetImageType(); !strings.HasPrefix(strings.ToUpper(image), "COS") {
+				return "runs the " + image + " image"
+			}
+			return ""
+		}),
+	},
+	{
+		id: "5.5.2", section: nodeSection, level: 1,
+		title: "Ensure Node Auto-Repair is enabled for GKE nodes",
+		fix:   "gcloud container node-pools update {pool} --cluster={cluster} --location={location} --project={project} --enable-autorepair",
+		check: poolCheck(func(np *containerpb.NodePool) string {
+			if !np.GetManagement().GetAutoRepair() {
+				return "doesn't auto-repair its nodes"
+			}
+			return ""
+		}),
+	},
+	{
+		id: "5.5.3", section: nodeSection, level: 1,
+		title: "Ensure Node Auto-Upgrade is enabled for GKE nodes",
+		fix:   "gcloud container node-pools update {pool} --cluster={cluster} --location={location} --project={project} --enable-autoupgrade",
+		check: poolCheck(func(np *containerpb.NodePool) string {
+			if !np.GetManagement().GetAutoUpgrade() {
+				return "doesn't auto-upgrade its nodes"
+			}
+			return ""
+		}),
+	},
+	{
+		id: "5.5.4", section: nodeSection, level: 2,
+		title: "When creating New Clusters - Automate GKE version management using Release Channels",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --release-channel=regular",
+		check: flag(func(c *containerpb.Cluster) bool {
+			return c.GetReleaseChannel().GetChannel() != containerpb.ReleaseChannel_UNSPECIFIED
+		}, "The cluster is enrolled in a release channel.", "The cluster isn't enrolled in a release channel."),
+	},
+	{
+		id: "5.5.5", section: nodeSection, level: 1,
+		title: "Ensure Shielded GKE Nodes are Enabled",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --enable-shielded-nodes",
+		check: flag(func(c *containerpb.Cluster) bool { return c.GetShieldedNodes().GetEnabled() },
+			"Shielded GKE Nodes are enabled.", "Shielded GKE Nodes are disabled."),
+	},
+	{
+		id: "5.5.6", section: nodeSection, level: 1,
+		title: "Ensure Integrity Monitoring for Shielded GKE Nodes is Enabled",
+		fix:   "Create a node pool with gcloud container node-pools create --shielded-integrity-monitoring, move the workloads to it and delete node pool {pool}.",
+		check: poolCheck(func(np *containerpb.NodePool) string {
+			if !np.GetConfig().GetShieldedInstanceConfig().GetEnableIntegrityMonitoring() {
+				return "doesn't monitor the integrity of its nodes"
+			}
+			return ""
+		}),
+	},
+	{
+		id: "5.5.7", section: nodeSection, level: 2,
+		title: "Ensure Secure Boot for Shielded GKE Nodes is Enabled",
+		fix:   "Create a node pool with gcloud container node-pools create --shielded-secure-boot, move the workloads to it and delete node pool {pool}.",
+		check: poolCheck(func(np *containerpb.NodePool) string {
+			if !np.GetConfig().GetShieldedInstanceConfig().GetEnableSecureBoot() {
+				return "doesn't use secure boot"
+			}
+			return ""
+		}),
+	},
+	{
+		id: "5.6.1", section: networkSection, level: 2,
+		title: "Enable VPC Flow Logs and Intranode Visibility",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --enable-intra-node-visibility, and enable flow logs on the cluster's subnet.",
+		check: flag(func(c *containerpb.Cluster) bool { return c.GetNetworkConfig().GetEnableIntraNodeVisibility() },
+			"Intranode visibility is enabled.", "Intranode visibility is disabled, so the traffic between pods of the same node isn't in the flow logs."),
+	},
+	{
+		id: "5.6.2", section: networkSection, level: 1,
+		title: "Ensure use of VPC-native clusters",
+		fix:   "VPC-native can only be chosen at creation: create a cluster with --enable-ip-alias and migrate the workloads to it.",
+		check: flag(func(c *containerpb.Cluster) bool { return c.GetIpAllocationPolicy().GetUseIpAliases() },
+			"The cluster is VPC-native.", "The cluster uses routes instead of alias IPs."),
+	},
+	{
+		id: "5.6.3", section: networkSection, level: 1,
+		title: "Ensure Control Plane Authorized Networks is Enabled",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --enable-master-authorized-networks --master-authorized-networks=CIDR,...",
+		check: func(e *evidence) outcome {
+			cidrs, enabled := authorizedNetworks(e.Cluster)
+			switch {
+			case !enabled:
+				return failed("Authorized networks are disabled, so any address can reach the control plane.")
+			case slices.Contains(cidrs, "0.0.0.0/0"):
+				return failed("Authorized networks allow 0.0.0.0/0.")
+			}
+			return passed("Authorized networks allow %s.", strings.Join(cidrs, ", "))
+		},
+	},
+	{
+		id: "5.6.4", section: networkSection, level: 2,
+		title: "Ensure clusters are created with Private Endpoint Enabled and Public Access Disabled",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --no-enable-ip-access, reaching the control plane through its DNS endpoint, or --enable-private-endpoint.",
+		check: flag(func(c *containerpb.Cluster) bool { return !publicEndpoint(c) },
+			"The control plane has no public endpoint.", "The control plane has a public endpoint."),
+	},
+	{
+		id: "5.6.5", section: networkSection, level: 1,
+		title: "Ensure clusters are created with Private Nodes",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --enable-private-nodes, then upgrade the node pools to recreate their nodes.",
+		check: flag(func(c *containerpb.Cluster) bool {
+			return c.GetPrivateClusterConfig().GetEnablePrivateNodes() || c.GetNetworkConfig().GetDefaultEnablePrivateNodes()
+		}, "Nodes have no external IP.", "Nodes have external IPs."),
+	},
+	{
+		id: "5.6.7", section: networkSection, level: 1,
+		title: "Ensure Network Policy is Enabled and set as appropriate",
+		fix:   "Enable Dataplane V2 on a new cluster, or gcloud container clusters update {cluster} --location={location} --project={project} --enable-network-policy; then apply policies, e.g. from generate_network_policies.",
+		check: flag(func(c *containerpb.Cluster) bool {
+			return c.GetAutopilot().GetEnabled() || c.GetNetworkPolicy().GetEnabled() || c.GetNetworkConfig().GetDatapathProvider() == containerpb.DatapathProvider_ADVANCED_DATAPATH
+		}, "The cluster enforces NetworkPolicies.", "The cluster doesn't enforce NetworkPolicies."),
+	},
+	{
+		id: "5.7.1", section: loggingSection, level: 1,
+		title: "Ensure Logging and Cloud Monitoring is Enabled",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --logging=SYSTEM,WORKLOAD --monitoring=SYSTEM",
+		check: func(e *evidence) outcome {
+			c := e.Cluster
+			logging := c.GetLoggingService() == kubernetesLogging || len(c.GetLoggingConfig().GetComponentConfig().GetEnableComponents()) > 0
+			monitoring := strings.HasPrefix(c.GetMonitoringService(), "monitoring.googleapis.com") || len(c.GetMonitoringConfig().GetComponentConfig().GetEnableComponents()) > 0
+			switch {
+			case logging && monitoring:
+				return passed("Cloud Logging and Cloud Monitoring are enabled.")
+			case logging:
+				return failed("Cloud Monitoring is disabled.")
+			case monitoring:
+				return failed("Cloud Logging is disabled.")
+			}
+			return failed("Cloud Logging and Cloud Monitoring are disabled.")
+		},
+	},
+	{
+		id: "5.8.1", section: authSection, level: 1,
+		title: "Ensure authentication using Client Certificates is Disabled",
+		fix:   "Client certificates can't be turned off: create a cluster without --issue-client-certificate and migrate the workloads to it.",
+		check: flag(func(c *containerpb.Cluster) bool { return c.GetMasterAuth().GetClientCertificate() == "" },
+			"The cluster issues no client certificate.", "The cluster issued a client certificate, which can't be revoked."),
+	},
+	{
+		id: "5.8.2", section: authSection, level: 2,
+		title: "Manage Kubernetes RBAC users with Google Groups for GKE",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --security-group=gke-security-groups@DOMAIN",
+		check: flag(func(c *containerpb.Cluster) bool { return c.GetAuthenticatorGroupsConfig().GetEnabled() },
+			"Google Groups for RBAC are enabled.", "Google Groups for RBAC are disabled."),
+	},
+	{
+		id: "5.8.3", section: authSection, level: 1,
+		title: "Ensure Legacy Authorization (ABAC) is Disabled",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --no-enable-legacy-authorization",
+		check: flag(func(c *containerpb.Cluster) bool { return !c.GetLegacyAbac().GetEnabled() },
+			"Legacy ABAC is disabled.", "Legacy ABAC is enabled, which grants permissions outside of RBAC."),
+	},
+	{
+		id: "5.9.2", section: storageSection, level: 2,
+		title: "Enable Customer-Managed Encryption Keys (CMEK) for Boot Disks",
+		fix:   "Create a node pool with gcloud container node-pools create --boot-disk-kms-key, move the workloads to it and delete node pool {pool}.",
+		check: poolCheck(func(np *containerpb.NodePool) string {
+			if np.GetConfig().GetBootDiskKmsKey() == "" {
+				return "encrypts its boot disks with Google-managed keys"
+			}
+			return ""
+		}),
+	},
+	{
+		id: "5.10.1", section: otherSection, level: 1,
+		title: "Ensure Kubernetes Web UI is Disabled",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --update-addons=KubernetesDashboard=DISABLED",
+		check: flag(func(c *containerpb.Cluster) bool {
+			d := c.GetAddonsConfig().GetKubernetesDashboard()
+			return d == nil || d.GetDisabled()
+		}, "The Kubernetes Dashboard is disabled.", "The Kubernetes Dashboard is enabled."),
+	},
+	{
+		id: "5.10.2", section: otherSection, level: 1,
+		title: "Ensure that Alpha clusters are not used for production workloads",
+		fix:   "Alpha clusters can't be converted: create a cluster without --enable-kubernetes-alpha and migrate the workloads to it.",
+		check: flag(func(c *containerpb.Cluster) bool { return !c.GetEnableKubernetesAlpha() },
+			"The cluster isn't an alpha cluster.", "The cluster is an alpha cluster, which isn't covered by the GKE SLA and is deleted after 30 days."),
+	},
+	{
+		id: "5.10.5", section: otherSection, level: 2,
+		title: "Ensure use of Binary Authorization",
+		fix:   "gcloud container clusters update {cluster} --location={location} --project={project} --binauthz-evaluation-mode=PROJECT_SINGLETON_POLICY_ENFORCE",
+		check: func(e *evidence) outcome {
+			b := e.Cluster.GetBinaryAuthorization()
+			if mode := b.GetEvaluationMode(); mode != containerpb.BinaryAuthorization_EVALUATION_MODE_UNSPECIFIED && mode != containerpb.BinaryAuthorization_DISABLED {
+				return passed("Binary Authorization evaluates %s.", mode)
+			}
+			if b.GetEnabled() {
+				return passed("Binary Authorization is enabled.")
+			}
+			return failed("Binary Authorization is disabled.")
+		},
+	},
+}
+
+// result is the outcome of a control.
+type result struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Section     string   `json:"section"`
+	Level       int      `json:"level"`
+	Status      string   `json:"status"`
+	Evidence    []string `json:"evidence"`
+	Remediation []string `json:"remediation,omitempty"`
+}
+
+// score is the share of the controls passed, of those passed or failed.
+type score struct {
+	Name    string  `json:"name"`
+	Passed  int     `json:"passed"`
+	Failed  int     `json:"failed"`
+	Percent float64 `json:"percent"`
+}
+
+func (s *score) add(status string) {
+	switch status {
+	case pass:
+		s.Passed++
+	case fail:
+		s.Failed++
+	}
+	if total := s.Passed + s.Failed; total > 0 {
+		s.Percent = float64(s.Passed*1000/total) / 10
+	}
+}
+
+// report is the result of cis_benchmark_report.
+type report struct {
+	Cluster   string   `json:"cluster"`
+	Benchmark string   `json:"benchmark"`
+	Level     int      `json:"level"`
+	Score     score    `json:"score"`
+	Sections  []score  `json:"sections"`
+	Controls  []result `json:"controls"`
+}
+
+// evaluate checks the controls up to level against the evidence, replacing
+// the placeholders of their remediation with replace.
+func evaluate(e *evidence, level int, replace *strings.Replacer) report {
+	r := report{Cluster: e.Cluster.GetName(), Benchmark: benchmark, Level: level, Score: score{Name: "overall"}}
+	for _, c := range controls {
+		if c.level > level {
+			continue
+		}
+		o := outcome{status: unknown, evidence: []string{fmt.Sprintf("The Kubernetes objects couldn't be read: %v", e.KubeErr)}}
+		if !c.kube || e.KubeErr == nil {
+			o = c.check(e)
+		}
+		res := result{ID: c.id, Title: c.title, Section: c.section, Level: c.level, Status: o.status, Evidence: o.evidence}
+		if o.status == fail {
+			res.Remediation = remediation(c.fix, o.evidence, replace)
+		}
+		r.Controls = append(r.Controls, res)
+		r.Score.add(o.status)
+		if len(r.Sections) == 0 || r.Sections[len(r.Sections)-1].Name != c.section {
+			r.Sections = append(r.Sections, score{Name: c.section})
+		}
+		r.Sections[len(r.Sections)-1].add(o.status)
+	}
+	return r
+}
+
+// remediation fills in the placeholders of fix, once per node pool named
+// in the evidence if it remediates node pools.
+func remediation(fix string, evidence []string, replace *strings.Replacer) []string {
+	fix = replace.Replace(fix)
+	if !strings.Contains(fix, "{pool}") {
+		return []string{fix}
+	}
+	var fixes []string
+	for _, line := range evidence {
+		if pool, ok := strings.CutPrefix(line, "Node pool "); ok {
+			fixes = append(fixes, strings.ReplaceAll(fix, "{pool}", strings.Fields(pool)[0]))
+		}
+	}
+	return fixes
+}
+
+// summary lists the score of every section and the controls failed.
+func (r report) summary() string {
+	lines := []string{fmt.Sprintf("Cluster %s passes %d of the %d controls of the %s up to Level %d checked: %.1f%%.",
+		r.Cluster, r.Score.Passed, r.Score.Passed+r.Score.Failed, r.Benchmark, r.Level, r.Score.Percent)}
+	for _, s := range r.Sections {
+		lines = append(lines, fmt.Sprintf("- %s: %d of %d", s.Name, s.Passed, s.Passed+s.Failed))
+	}
+	var failures []string
+	for _, c := range r.Controls {
+		if c.Status == fail || c.Status == unknown {
+			failures = append(failures, fmt.Sprintf("- %s %s (Level %d): %s", strings.ToUpper(c.Status), c.ID, c.Level, c.Title))
+		}
+	}
+	if len(failures) > 0 {
+		lines = append(lines, "", "Controls to remediate:")
+		lines = append(lines, failures...)
+	}
+	lines = append(lines, "", "Controls that need manual review or access to the nodes, like those of sections 3 and 4.2 to 4.5, aren't checked.")
+	return strings.Join(lines, "\n")
+}
+
+// findingList is the structured result of cis_benchmark_report, following
+// the findings schema.
+type findingList struct {
+	Findings []structuredFinding `json:"findings"`
+}
+
+type structuredFinding struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Category    string `json:"category"`
+	Priority    string `json:"priority"`
+	Resource    string `json:"resource"`
+}
+
+func (r report) findingList(resource string) findingList {
+	priorities := map[int]string{1: "P2", 2: "P3"}
+	list := findingList{Findings: []structuredFinding{}}
+	for _, c := range r.Controls {
+		if c.Status != fail {
+			continue
+		}
+		list.Findings = append(list.Findings, structuredFinding{
+			Name:        "cis/" + c.ID + "/" + r.Cluster,
+			Description: c.Title + ": " + strings.Join(c.Evidence, " "),
+			Type:        "cis-" + c.ID,
+			Category:    "COMPLIANCE",
+			Priority:    priorities[c.Level],
+			Resource:    resource,
+		})
+	}
+	return list
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+)
+
+func hardenedCluster() *containerpb.Cluster {
+	pool := &containerpb.NodePool{
+		Name: "hardened",
+		Config: &containerpb.NodeConfig{
+			ServiceAccount:         "nodes@p.iam.gserviceaccount.com",
+			ImageType:              "COS_CONTAINERD",
+			BootDiskKmsKey:         "projects/p/locations/us/keyRings/r/cryptoKeys/k",
+			WorkloadMetadataConfig: &containerpb.WorkloadMetadataConfig{Mode: containerpb.WorkloadMetadataConfig_GKE_METADATA},
+			ShieldedInstanceConfig: &containerpb.ShieldedInstanceConfig{EnableSecureBoot: true, EnableIntegrityMonitoring: true},
+		},
+		Management: &containerpb.NodeManagement{AutoRepair: true, AutoUpgrade: true},
+	}
+	return &containerpb.Cluster{
+		Name:                   "prod",
+		NodePools:              []*containerpb.NodePool{pool},
+		SecurityPostureConfig:  &containerpb.SecurityPostureConfig{VulnerabilityMode: containerpb.SecurityPostureConfig_VULNERABILITY_BASIC.Enum()},
+		WorkloadIdentityConfig: &containerpb.WorkloadIdentityConfig{WorkloadPool: "p.svc.id.goog"},
+		DatabaseEncryption:     &containerpb.DatabaseEncryption{State: containerpb.DatabaseEncryption_ENCRYPTED, KeyName: "k"},
+		ReleaseChannel:         &containerpb.ReleaseChannel{Channel: containerpb.ReleaseChannel_REGULAR},
+		ShieldedNodes:          &containerpb.ShieldedNodes{Enabled: true},
+		NetworkConfig:          &containerpb.NetworkConfig{EnableIntraNodeVisibility: true, DatapathProvider: containerpb.DatapathProvider_ADVANCED_DATAPATH, DefaultEnablePrivateNodes: proto.Bool(true)},
+		IpAllocationPolicy:     &containerpb.IPAllocationPolicy{UseIpAliases: true},
+		ControlPlaneEndpointsConfig: &containerpb.ControlPlaneEndpointsConfig{IpEndpointsConfig: &containerpb.ControlPlaneEndpointsConfig_IPEndpointsConfig{
+			Enabled:                  proto.Bool(true),
+			AuthorizedNetworksConfig: &containerpb.MasterAuthorizedNetworksConfig{Enabled: true, CidrBlocks: []*containerpb.MasterAuthorizedNetworksConfig_CidrBlock{{CidrBlock: "10.0.0.0/8"}}},
+		}},
+		LoggingService:            kubernetesLogging,
+		MonitoringService:         "monitoring.googleapis.com/kubernetes",
+		AuthenticatorGroupsConfig: &containerpb.AuthenticatorGroupsConfig{Enabled: true},
+		BinaryAuthorization:       &containerpb.BinaryAuthorization{EvaluationMode: containerpb.BinaryAuthorization_PROJECT_SINGLETON_POLICY_ENFORCE},
+	}
+}
+
+func parse[T any](t *testing.T, data string) []T {
+	t.Helper()
+	var items []T
+	if err := json.Unmarshal([]byte(data), &items); err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+func statuses(r report) map[string]string {
+	s := map[string]string{}
+	for _, c := range r.Controls {
+		s[c.ID] = c.Status
+	}
+	return s
+}
+
+func TestEvaluateHardened(t *testing.T) {
+	e := &evidence{
+		Cluster: hardenedCluster(),
+		ClusterRoleBindings: parse[roleBinding](t, `[
+			{"metadata":{"name":"cluster-admin"},"roleRef":{"kind":"ClusterRole","name":"cluster-admin"},"subjects":[{"kind":"Group","name":"system:masters"}]},
+			{"metadata":{"name":"system:controller:x"},"roleRef":{"kind":"ClusterRole","name":"cluster-admin"},"subjects":[{"kind":"ServiceAccount","name":"x","namespace":"kube-system"}]}
+		]`),
+		ClusterRoles:    parse[role](t, `[{"metadata":{"name":"admin"},"rules":[{"apiGroups":["*"],"resources":["*"],"verbs":["*"]}]}]`),
+		ServiceAccounts: parse[serviceAccount](t, `[{"metadata":{"name":"default","namespace":"shop"},"automountServiceAccountToken":false},{"metadata":{"name":"default","namespace":"kube-system"}}]`),
+	}
+	r := evaluate(e, 2, strings.NewReplacer())
+	for id, status := range statuses(r) {
+		if status != pass {
+			t.Errorf("control %s is %s, want pass", id, status)
+		}
+	}
+	if r.Score.Failed != 0 || r.Score.Percent != 100 {
+		t.Errorf("score = %+v, want 100%%", r.Score)
+	}
+}
+
+func TestEvaluateFailures(t *testing.T) {
+	c := hardenedCluster()
+	c.NodePools = append(c.NodePools, &containerpb.NodePool{
+		Name:   "legacy",
+		Config: &containerpb.NodeConfig{ServiceAccount: "default", ImageType: "UBUNTU_CONTAINERD"},
+	})
+	c.ShieldedNodes = nil
+	c.LegacyAbac = &containerpb.LegacyAbac{Enabled: true}
+	c.ControlPlaneEndpointsConfig.IpEndpointsConfig.EnablePublicEndpoint = proto.Bool(true)
+	c.ControlPlaneEndpointsConfig.IpEndpointsConfig.AuthorizedNetworksConfig.CidrBlocks[0].CidrBlock = "0.0.0.0/0"
+	e := &evidence{
+		Cluster: c,
+		ClusterRoleBindings: parse[roleBinding](t, `[
+			{"metadata":{"name":"ci-admin"},"roleRef":{"kind":"ClusterRole","name":"cluster-admin"},"subjects":[{"kind":"ServiceAccount","name":"deployer","namespace":"ci"}]}
+		]`),
+		Roles:           parse[role](t, `[{"metadata":{"name":"ops","namespace":"shop"},"rules":[{"apiGroups":[""],"resources":["pods"],"verbs":["*"]}]}]`),
+		ServiceAccounts: parse[serviceAccount](t, `[{"metadata":{"name":"default","namespace":"shop"}}]`),
+		DefaultPods:     parse[pod](t, `[{"metadata":{"name":"debug","namespace":"default"}}]`),
+	}
+	r := evaluate(e, 2, strings.NewReplacer("{cluster}", "prod", "{location}", "us-central1", "{project}", "p"))
+
+	var failed []string
+	for _, c := range r.Controls {
+		if c.Status == fail {
+			failed = append(failed, c.ID)
+		}
+	}
+	want := []string{"4.1.1", "4.1.3", "4.1.5", "4.6.4", "5.2.1", "5.4.2", "5.5.1", "5.5.2", "5.5.3", "5.5.5", "5.5.6", "5.5.7", "5.6.3", "5.6.4", "5.8.3", "5.9.2"}
+	if diff := cmp.Diff(want, failed); diff != "" {
+		t.Errorf("failed controls mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, c := range r.Controls {
+		if c.ID != "5.5.2" {
+			continue
+		}
+		wantFix := []string{"gcloud container node-pools update legacy --cluster=prod --location=us-central1 --project=p --enable-autorepair"}
+		if diff := cmp.Diff(wantFix, c.Remediation); diff != "" {
+			t.Errorf("5.5.2 remediation mismatch (-want +got):\n%s", diff)
+		}
+	}
+	if got := len(r.findingList("clusters/prod").Findings); got != len(want) {
+		t.Errorf("findingList() has %d findings, want %d", got, len(want))
+	}
+
+	level1 := evaluate(e, 1, strings.NewReplacer())
+	for _, c := range level1.Controls {
+		if c.Level != 1 {
+			t.Errorf("Level 1 report checks Level %d control %s", c.Level, c.ID)
+		}
+	}
+}
+
+func TestEvaluateWithoutKubernetes(t *testing.T) {
+	c := hardenedCluster()
+	c.Autopilot = &containerpb.Autopilot{Enabled: true}
+	c.NodePools[0].Config.ImageType = "UBUNTU_CONTAINERD"
+	r := evaluate(&evidence{Cluster: c, KubeErr: errors.New("forbidden")}, 2, strings.NewReplacer())
+	got := statuses(r)
+	for _, id := range []string{"4.1.1", "4.1.3", "4.1.5", "4.6.4"} {
+		if got[id] != unknown {
+			t.Errorf("control %s is %s, want unknown without the Kubernetes objects", id, got[id])
+		}
+	}
+	if got["5.5.1"] != notApplicable {
+		t.Errorf("control 5.5.1 is %s on Autopilot, want not-applicable", got["5.5.1"])
+	}
+	if r.Score.Failed != 0 || r.Score.Passed == 0 {
+		t.Errorf("score = %+v, want the unknown and not applicable controls left out", r.Score)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/operations"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	bigquery "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/cloudbilling/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// defaultUsageDataset is the BigQuery dataset usage metering exports to,
+// unless the cluster already exports to another one.
+const defaultUsageDataset = "gke_usage_metering"
+
+// billingExportURL is where the Cloud Billing export to BigQuery is set up,
+// which has no API.
+const billingExportURL = "https://console.cloud.google.com/billing/export"
+
+// clusterUpdate is an update of a cluster, which GKE applies one at a time.
+type clusterUpdate struct {
+	Description string
+	Update      *containerpb.ClusterUpdate
+}
+
+// planAllocation returns the updates enabling GKE cost allocation and usage
+// metering into dataset on a cluster, and what is already enabled. Egress
+// metering is never turned off. Autopilot clusters don't support usage
+// metering.
+func planAllocation(cluster *containerpb.Cluster, dataset string, egress bool) ([]clusterUpdate, []string) {
+	var updates []clusterUpdate
+	var enabled []string
+	if cluster.GetCostManagementConfig().GetEnabled() {
+		enabled = append(enabled, "GKE cost allocation is enabled.")
+	} else {
+		updates = append(updates, clusterUpdate{
+			Description: "enable GKE cost allocation",
+			Update:      &containerpb.ClusterUpdate{DesiredCostManagementConfig: &containerpb.CostManagementConfig{Enabled: true}},
+		})
+	}
+	if cluster.GetAutopilot().GetEnabled() {
+		enabled = append(enabled, "Usage metering isn't supported on Autopilot; cost allocation breaks down its costs by namespace and label.")
+		return updates, enabled
+	}
+	current := cluster.GetResourceUsageExportConfig()
+	egress = egress || current.GetEnableNetworkEgressMetering()
+	if current.GetBigqueryDestination().GetDatasetId() == dataset && current.GetConsumptionMeteringConfig().GetEnabled() && current.GetEnableNetworkEgressMetering() == egress {
+		enabled = append(enabled, fmt.Sprintf("Usage metering exports to dataset %s.", dataset))
+		return updates, enabled
+	}
+	description := fmt.Sprintf("enable usage metering, with resource consumption metering, into dataset %s", dataset)
+	if egress {
+		description += ", with network egress metering"
+	}
+	updates = append(updates, clusterUpdate{
+		Description: description,
+		Update: &containerpb.ClusterUpdate{DesiredResourceUsageExportConfig: &containerpb.ResourceUsageExportConfig{
+			BigqueryDestination:         &containerpb.ResourceUsageExportConfig_BigQueryDestination{DatasetId: dataset},
+			EnableNetworkEgressMetering: egress,
+			ConsumptionMeteringConfig:   &containerpb.ResourceUsageExportConfig_ConsumptionMeteringConfig{Enabled: true},
+		}},
+	})
+	return updates, enabled
+}
+
+// usageDataset returns the dataset usage metering should export to: the
+// one asked for, the one the cluster already exports to, or the default.
+func usageDataset(cluster *containerpb.Cluster, dataset string) string {
+	if dataset != "" {
+		return dataset
+	}
+	if current := cluster.GetResourceUsageExportConfig().GetBigqueryDestination().GetDatasetId(); current != "" {
+		return current
+	}
+	return defaultUsageDataset
+}
+
+// exportCheckQuery counts the rows of the last days of a cluster in a Cloud
+// Billing detailed export table, and the ones attributed to namespaces by
+// cost allocation.
+const exportCheckQuery = `SELECT
+  FORMAT_TIMESTAMP('%%F', MAX(usage_start_time)) AS latest,
+  COUNT(*) AS total,
+  COUNTIF(EXISTS (SELECT 1 FROM UNNEST(labels) WHERE key = 'k8s-namespace')) AS allocated
+FROM ` + "`%s`" + `
+WHERE project.id = @project
+  AND usage_start_time >= TIMESTAMP(DATE_SUB(CURRENT_DATE(), INTERVAL 3 DAY))
+  AND EXISTS (SELECT 1 FROM UNNEST(labels) WHERE key = 'goog-k8s-cluster-name' AND value = @cluster)`
+
+// exportStatus describes the rows of the last days of a cluster in the
+// billing export, from the result of exportCheckQuery.
+func exportStatus(table, cluster, latest string, total, allocated int) string {
+	switch {
+	case total == 0:
+		return fmt.Sprintf("The billing export %s has no costs of cluster %s in the last 3 days. Check that it is a detailed usage cost export of the billing account of the project, set up at %s; new exports can take a day to fill.", table, cluster, billingExportURL)
+	case allocated == 0:
+		return fmt.Sprintf("The billing export %s has costs of cluster %s up to %s, but none broken down by namespace yet. Cost allocation data appears in the export within a day of enabling it.", table, cluster, latest)
+	}
+	return fmt.Sprintf("The billing export %s has costs of cluster %s up to %s, %d of %d rows broken down by namespace.", table, cluster, latest, allocated, total)
+}
+
+// verifyBilling checks that billing is enabled on the project and, if a
+// billing export table is given, that it has the cluster's costs.
+func (h *handlers) verifyBilling(ctx context.Context, projectID, cluster, table string) ([]string, error) {
+	opts, err := auth.ClientOptions(ctx, h.c, config.APICloudBilling)
+	if err != nil {
+		return nil, err
+	}
+	svc, err := cloudbilling.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	info, err := svc.Projects.GetBillingInfo("projects/" + projectID).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the billing info of project %s: %w", projectID, err)
+	}
+	if !info.BillingEnabled {
+		return []string{fmt.Sprintf("Billing isn't enabled on project %s, so it has no costs to allocate.", projectID)}, nil
+	}
+	lines := []string{fmt.Sprintf("Project %s is billed to %s.", projectID, info.BillingAccountName)}
+	if table == "" {
+		return append(lines, fmt.Sprintf("The cost tools need a Cloud Billing detailed usage cost export to BigQuery, which can only be set up in the console at %s; pass its table as billing_table to check it.", billingExportURL)), nil
+	}
+
+	billingProject, _, _, err := parseTable(table)
+	if err != nil {
+		return nil, err
+	}
+	bq, err := h.bigQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := runQuery(ctx, bq, billingProject, fmt.Sprintf(exportCheckQuery, table), map[string]string{"project": projectID, "cluster": cluster})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the billing export %s: %w", table, err)
+	}
+	var latest string
+	var total, allocated int
+	if len(rows) == 1 {
+		if rows[0][0] != nil {
+			latest = fmt.Sprint(rows[0][0])
+		}
+		total, _ = strconv.Atoi(fmt.Sprint(rows[0][1]))
+		allocated, _ = strconv.Atoi(fmt.Sprint(rows[0][2]))
+	}
+	return append(lines, exportStatus(table, cluster, latest, total, allocated)), nil
+}
+
+// ensureDataset creates a BigQuery dataset if it doesn't exist, and tells
+// whether it did.
+func ensureDataset(ctx context.Context, bq *bigquery.Service, project, dataset, location string) (bool, error) {
+	_, err := bq.Datasets.Get(project, dataset).Context(ctx).Do()
+	var apiErr *googleapi.Error
+	switch {
+	case err == nil:
+		return false, nil
+	case !errors.As(err, &apiErr) || apiErr.Code != http.StatusNotFound:
+		return false, err
+	}
+	_, err = bq.Datasets.Insert(project, &bigquery.Dataset{
+		DatasetReference: &bigquery.DatasetReference{ProjectId: project, DatasetId: dataset},
+		Location:         location,
+		Description:      "GKE usage metering",
+	}).Context(ctx).Do()
+	return err == nil, err
+}
+
+func (h *handlers) enableCostAllocation(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	billingTable := request.GetString("billing_table", "")
+	if billingTable != "" {
+		if _, _, _, err := parseTable(billingTable); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	datasetLocation := request.GetString("dataset_location", pricing.Region(location))
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	clusterName := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: clusterName})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dataset := usageDataset(cluster, request.GetString("dataset", ""))
+	updates, enabled := planAllocation(cluster, dataset, request.GetBool("network_egress", false))
+	metering := !cluster.GetAutopilot().GetEnabled()
+
+	if dryrun.Enabled(request, h.c) {
+		var actions []string
+		if metering {
+			actions = append(actions, fmt.Sprintf("create the BigQuery dataset %s.%s in %s if it doesn't exist", projectID, dataset, datasetLocation))
+		}
+		for _, u := range updates {
+			actions = append(actions, u.Description)
+		}
+		action := fmt.Sprintf("%s on cluster %s, then check its billing export", strings.Join(actions, ", "), name)
+		if len(updates) == 0 {
+			action = fmt.Sprintf("only check the billing export of cluster %s, which already has cost allocation and usage metering enabled", name)
+		}
+		result := dryrun.Describe(action)
+		for _, u := range updates {
+			req := &containerpb.UpdateClusterRequest{Name: clusterName, Update: u.Update}
+			result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf("API method: container.projects.locations.clusters.update\nRequest:\n```json\n%s\n```", protojson.Format(req))))
+		}
+		if commands := h.enableCostAllocationCommands(ctx, request); len(commands) > 0 {
+			result.Content = append(result.Content, mcp.NewTextContent(explain.Format(commands)))
+		}
+		return result, nil
+	}
+
+	lines := enabled
+	if metering {
+		bq, err := h.bigQuery(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		created, err := ensureDataset(ctx, bq, projectID, dataset, datasetLocation)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create the BigQuery dataset %s: %v", dataset, err)), nil
+		}
+		if created {
+			lines = append(lines, fmt.Sprintf("Created the BigQuery dataset %s.%s in %s.", projectID, dataset, datasetLocation))
+		}
+	}
+	poll := func(ctx context.Context, name string) (*containerpb.Operation, error) {
+		return cmClient.GetOperation(ctx, &containerpb.GetOperationRequest{Name: name})
+	}
+	cancel := func(ctx context.Context, name string) error {
+		return cmClient.CancelOperation(ctx, &containerpb.CancelOperationRequest{Name: name})
+	}
+	// GKE applies one update of a cluster at a time, so each waits for the
+	// previous one. If the call times out first, the rest is left for the
+	// next call.
+	for _, u := range updates {
+		op, err := cmClient.UpdateCluster(ctx, &containerpb.UpdateClusterRequest{Name: clusterName, Update: u.Update})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to %s: %v", u.Description, err)), nil
+		}
+		opName := fmt.Sprintf("projects/%s/locations/%s/operations/%s", projectID, location, op.GetName())
+		latest, err := operations.Default.Wait(ctx, request, operations.Operation{Name: opName, Tool: "enable_cost_allocation", Target: name}, poll, cancel)
+		if latest == nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		switch {
+		case latest.GetStatus() != containerpb.Operation_DONE:
+			lines = append(lines, fmt.Sprintf("Updating cluster %s to %s. %s Call the tool again once it is done to finish.", name, u.Description, operations.Describe(latest)))
+			return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+		case latest.GetError() != nil || latest.GetStatusMessage() != "":
+			return mcp.NewToolResultError(operations.Describe(latest)), nil
+		}
+		lines = append(lines, fmt.Sprintf("Updated cluster %s to %s.", name, u.Description))
+	}
+
+	billing, err := h.verifyBilling(ctx, projectID, name, billingTable)
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("The billing export couldn't be checked: %v", err))
+	}
+	lines = append(lines, billing...)
+	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+}
+
+func (h *handlers) enableCostAllocationCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	dataset := request.GetString("dataset", defaultUsageDataset)
+	egress := ""
+	if request.GetBool("network_egress", false) {
+		egress = "--enable-network-egress-metering"
+	}
+	commands := []string{
+		explain.Join("bq mk", "--dataset", explain.Flag("location", request.GetString("dataset_location", pricing.Region(location))), projectID+":"+dataset),
+		explain.Join("gcloud container clusters update", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--enable-cost-allocation"),
+		explain.Join("gcloud container clusters update", cluster, explain.Flag("location", location), explain.Flag("project", projectID), explain.Flag("resource-usage-bigquery-dataset", dataset), "--enable-resource-consumption-metering", egress),
+		explain.Join("gcloud billing projects describe", projectID),
+	}
+	if table := request.GetString("billing_table", ""); table != "" {
+		sql := fmt.Sprintf(exportCheckQuery, table)
+		sql = strings.ReplaceAll(sql, "@project", "'"+projectID+"'")
+		sql = strings.ReplaceAll(sql, "@cluster", "'"+cluster+"'")
+		commands = append(commands, explain.Join("bq query --use_legacy_sql=false --format=csv", sql))
+	}
+	return commands
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestPlanAllocation(t *testing.T) {
+	metering := func(dataset string, egress bool) *containerpb.ResourceUsageExportConfig {
+		return &containerpb.ResourceUsageExportConfig{
+			BigqueryDestination:         &containerpb.ResourceUsageExportConfig_BigQueryDestination{DatasetId: dataset},
+			EnableNetworkEgressMetering: egress,
+			ConsumptionMeteringConfig:   &containerpb.ResourceUsageExportConfig_ConsumptionMeteringConfig{Enabled: true},
+		}
+	}
+	costAllocation := &containerpb.ClusterUpdate{DesiredCostManagementConfig: &containerpb.CostManagementConfig{Enabled: true}}
+	tests := []struct {
+		name        string
+		cluster     *containerpb.Cluster
+		dataset     string
+		egress      bool
+		want        []*containerpb.ClusterUpdate
+		wantEnabled int
+	}{
+		{
+			name:    "nothing enabled",
+			cluster: &containerpb.Cluster{},
+			dataset: defaultUsageDataset,
+			want:    []*containerpb.ClusterUpdate{costAllocation, {DesiredResourceUsageExportConfig: metering(defaultUsageDataset, false)}},
+		},
+		{
+			name:        "everything enabled",
+			cluster:     &containerpb.Cluster{CostManagementConfig: &containerpb.CostManagementConfig{Enabled: true}, ResourceUsageExportConfig: metering("usage", true)},
+			dataset:     "usage",
+			wantEnabled: 2,
+		},
+		{
+			name:        "egress kept on another dataset",
+			cluster:     &containerpb.Cluster{CostManagementConfig: &containerpb.CostManagementConfig{Enabled: true}, ResourceUsageExportConfig: metering("old", true)},
+			dataset:     "usage",
+			want:        []*containerpb.ClusterUpdate{{DesiredResourceUsageExportConfig: metering("usage", true)}},
+			wantEnabled: 1,
+		},
+		{
+			name:    "egress added",
+			cluster: &containerpb.Cluster{CostManagementConfig: &containerpb.CostManagementConfig{Enabled: true}, ResourceUsageExportConfig: metering("usage", false)},
+			dataset: "usage", egress: true,
+			want:        []*containerpb.ClusterUpdate{{DesiredResourceUsageExportConfig: metering("usage", true)}},
+			wantEnabled: 1,
+		},
+		{
+			name:        "autopilot",
+			cluster:     &containerpb.Cluster{Autopilot: &containerpb.Autopilot{Enabled: true}},
+			dataset:     defaultUsageDataset,
+			want:        []*containerpb.ClusterUpdate{costAllocation},
+			wantEnabled: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			updates, enabled := planAllocation(tc.cluster, tc.dataset, tc.egress)
+			var got []*containerpb.ClusterUpdate
+			for _, u := range updates {
+				got = append(got, u.Update)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("planAllocation() updates mismatch (-want +got):\n%s", diff)
+			}
+			if len(enabled) != tc.wantEnabled {
+				t.Errorf("planAllocation() enabled = %q, want %d entries", enabled, tc.wantEnabled)
+			}
+		})
+	}
+}
+
+func TestUsageDataset(t *testing.T) {
+	exporting := &containerpb.Cluster{ResourceUsageExportConfig: &containerpb.ResourceUsageExportConfig{
+		BigqueryDestination: &containerpb.ResourceUsageExportConfig_BigQueryDestination{DatasetId: "usage"},
+	}}
+	for _, tc := range []struct {
+		cluster *containerpb.Cluster
+		dataset string
+		want    string
+	}{
+		{&containerpb.Cluster{}, "", defaultUsageDataset},
+		{exporting, "", "usage"},
+		{exporting, "metering", "metering"},
+	} {
+		if got := usageDataset(tc.cluster, tc.dataset); got != tc.want {
+			t.Errorf("usageDataset(%q) = %q, want %q", tc.dataset, got, tc.want)
+		}
+	}
+}
+
+func TestExportStatus(t *testing.T) {
+	for _, tc := range []struct {
+		total, allocated int
+		want             string
+	}{
+		{0, 0, "has no costs of cluster prod"},
+		{10, 0, "none broken down by namespace yet"},
+		{10, 4, "4 of 10 rows broken down by namespace"},
+	} {
+		if got := exportStatus("p.billing.export", "prod", "2026-10-16", tc.total, tc.allocated); !strings.Contains(got, tc.want) {
+			t.Errorf("exportStatus(%d, %d) = %q, want it to contain %q", tc.total, tc.allocated, got, tc.want)
+		}
+	}
+}
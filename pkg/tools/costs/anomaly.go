@@ -0,0 +1,488 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	datatransfer "google.golang.org/api/bigquerydatatransfer/v1"
+)
+
+// Defaults of the anomaly detection: the days of the trailing baseline, the
+// recent days checked against it, and how many standard deviations above
+// the baseline a day's cost must be.
+const (
+	defaultBaselineDays = 28
+	defaultRecentDays   = 3
+	defaultThreshold    = 3.0
+)
+
+// Costs deviating by less than minDeviation of the baseline, or less than
+// minIncrease, aren't anomalies, so flat series don't flag small changes.
+const (
+	minDeviation = 0.05
+	minIncrease  = 1.0
+)
+
+// dailyCost is the cost of a SKU in a node pool and namespace of a cluster on
+// a day.
+type dailyCost struct {
+	Day       string
+	Cluster   string
+	Namespace string
+	NodePool  string
+	SKU       string
+	Cost      float64
+}
+
+// dailyCostsQuery sums the daily costs, net of credits, of the GKE clusters
+// of a project in a Cloud Billing detailed export table.
+const dailyCostsQuery = `SELECT
+  FORMAT_DATE('%%F', DATE(usage_start_time)) AS day,
+  (SELECT value FROM UNNEST(labels) WHERE key = 'goog-k8s-cluster-name') AS cluster,
+  IFNULL((SELECT value FROM UNNEST(labels) WHERE key = 'k8s-namespace'), '') AS namespace,
+  IFNULL((SELECT value FROM UNNEST(labels) WHERE key = 'goog-k8s-node-pool-name'), '') AS node_pool,
+  sku.description AS sku,
+  SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) AS c), 0)) AS cost
+FROM ` + "`%s`" + `
+WHERE project.id = @project
+  AND usage_start_time >= TIMESTAMP(DATE_SUB(CURRENT_DATE(), INTERVAL %d DAY))
+  AND usage_start_time < TIMESTAMP(CURRENT_DATE())
+  AND EXISTS (SELECT 1 FROM UNNEST(labels) WHERE key = 'goog-k8s-cluster-name' AND (@cluster = '' OR value = @cluster))
+GROUP BY day, cluster, namespace, node_pool, sku`
+
+// costAnomaly is a day whose cost is anomalous compared to the baseline of
+// a cluster, or of a namespace of it.
+type costAnomaly struct {
+	Cluster string `json:"cluster"`
+	// Namespace is "" for the whole cluster.
+	Namespace string  `json:"namespace,omitempty"`
+	Day       string  `json:"day"`
+	Cost      float64 `json:"cost"`
+	Baseline  float64 `json:"baseline"`
+	StdDev    float64 `json:"stdDev"`
+	Increase  float64 `json:"increase"`
+	Percent   float64 `json:"percent"`
+	// Drivers are the node pools and SKUs that grew the most.
+	Drivers []string `json:"drivers,omitempty"`
+}
+
+func (a costAnomaly) resource() string {
+	if a.Namespace == "" {
+		return a.Cluster
+	}
+	return a.Cluster + "/" + a.Namespace
+}
+
+// days returns the days from start, on count days.
+func days(start time.Time, count int) []string {
+	var result []string
+	for i := range count {
+		result = append(result, start.AddDate(0, 0, i).Format(time.DateOnly))
+	}
+	return result
+}
+
+// meanStdDev returns the mean and population standard deviation of the
+// values of the days, counting missing days as 0.
+func meanStdDev(values map[string]float64, days []string) (float64, float64) {
+	var sum float64
+	for _, d := range days {
+		sum += values[d]
+	}
+	mean := sum / float64(len(days))
+	var variance float64
+	for _, d := range days {
+		variance += (values[d] - mean) * (values[d] - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(days)))
+}
+
+// findAnomalies checks the costs of every cluster, and of every namespace
+// of it, on the recent days before today against their mean over the
+// baseline days before those, the largest increase first.
+func findAnomalies(costs []dailyCost, today time.Time, baselineDays, recentDays int, threshold float64) []costAnomaly {
+	recentStart := today.AddDate(0, 0, -recentDays)
+	baseline := days(recentStart.AddDate(0, 0, -baselineDays), baselineDays)
+	recent := days(recentStart, recentDays)
+
+	// Daily costs by group, cluster or cluster/namespace, and by node pool
+	// and SKU within each group.
+	type group struct{ cluster, namespace string }
+	totals := map[group]map[string]float64{}
+	pools := map[group]map[string]map[string]float64{}
+	skus := map[group]map[string]map[string]float64{}
+	add := func(m map[group]map[string]map[string]float64, g group, key, day string, cost float64) {
+		if m[g] == nil {
+			m[g] = map[string]map[string]float64{}
+		}
+		if m[g][key] == nil {
+			m[g][key] = map[string]float64{}
+		}
+		m[g][key][day] += cost
+	}
+	for _, c := range costs {
+		groups := []group{{c.Cluster, ""}}
+		if c.Namespace != "" {
+			groups = append(groups, group{c.Cluster, c.Namespace})
+		}
+		for _, g := range groups {
+			if totals[g] == nil {
+				totals[g] = map[string]float64{}
+			}
+			totals[g][c.Day] += c.Cost
+			if c.NodePool != "" {
+				add(pools, g, c.NodePool, c.Day, c.Cost)
+			}
+			add(skus, g, c.SKU, c.Day, c.Cost)
+		}
+	}
+
+	var result []costAnomaly
+	for g, values := range totals {
+		mean, stdDev := meanStdDev(values, baseline)
+		for _, day := range recent {
+			cost := values[day]
+			if cost-mean < minIncrease || cost <= mean+threshold*max(stdDev, minDeviation*mean) {
+				continue
+			}
+			a := costAnomaly{Cluster: g.cluster, Namespace: g.namespace, Day: day, Cost: round2(cost), Baseline: round2(mean), StdDev: round2(stdDev), Increase: round2(cost - mean)}
+			if mean > 0 {
+				a.Percent = math.Round((cost-mean)/mean*1000) / 10
+			}
+			a.Drivers = drivers(pools[g], skus[g], baseline, day)
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Increase != result[j].Increase {
+			return result[i].Increase > result[j].Increase
+		}
+		if result[i].resource() != result[j].resource() {
+			return result[i].resource() < result[j].resource()
+		}
+		return result[i].Day < result[j].Day
+	})
+	return result
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// maxDrivers is the number of drivers reported per anomaly.
+const maxDrivers = 3
+
+// drivers explains an anomalous day by the node pools and SKUs whose cost
+// grew the most over their baseline: new node pools, node pools that scaled
+// out and SKUs such as network egress.
+func drivers(pools, skus map[string]map[string]float64, baseline []string, day string) []string {
+	type driver struct {
+		message  string
+		increase float64
+	}
+	var found []driver
+	for name, values := range pools {
+		mean, _ := meanStdDev(values, baseline)
+		increase := values[day] - mean
+		switch {
+		case increase < minIncrease:
+		case mean == 0:
+			found = append(found, driver{fmt.Sprintf("New node pool %s, costing %.2f.", name, values[day]), increase})
+		case values[day] > 1.5*mean:
+			found = append(found, driver{fmt.Sprintf("Node pool %s scaled out, costing %.2f against %.2f a day.", name, values[day], mean), increase})
+		}
+	}
+	for name, values := range skus {
+		mean, _ := meanStdDev(values, baseline)
+		increase := values[day] - mean
+		if increase < minIncrease || values[day] <= 1.5*mean {
+			continue
+		}
+		kind := "SKU"
+		if strings.Contains(strings.ToLower(name), "egress") {
+			kind = "Egress spike in"
+		}
+		found = append(found, driver{fmt.Sprintf("%s %s, costing %.2f against %.2f a day.", kind, name, values[day], mean), increase})
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].increase != found[j].increase {
+			return found[i].increase > found[j].increase
+		}
+		return found[i].message < found[j].message
+	})
+	var result []string
+	for i, d := range found {
+		if i == maxDrivers {
+			break
+		}
+		result = append(result, d.message)
+	}
+	return result
+}
+
+// anomalyArguments reads the arguments shared by the anomaly tools.
+func anomalyArguments(request mcp.CallToolRequest) (table string, baselineDays, recentDays int, threshold float64, err error) {
+	table, err = request.RequireString("billing_table")
+	if err != nil {
+		return "", 0, 0, 0, err
+	}
+	if _, _, _, err := parseTable(table); err != nil {
+		return "", 0, 0, 0, err
+	}
+	baselineDays = request.GetInt("baseline_days", defaultBaselineDays)
+	recentDays = request.GetInt("recent_days", defaultRecentDays)
+	threshold = request.GetFloat("threshold", defaultThreshold)
+	if baselineDays < 7 || recentDays < 1 || threshold <= 0 {
+		return "", 0, 0, 0, fmt.Errorf("baseline_days must be at least 7, recent_days at least 1 and threshold positive")
+	}
+	return table, baselineDays, recentDays, threshold, nil
+}
+
+func (h *handlers) detectCostAnomalies(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	table, baselineDays, recentDays, threshold, err := anomalyArguments(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	billingProject, _, _, _ := parseTable(table)
+	bq, err := h.bigQuery(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster := request.GetString("cluster", "")
+	rows, err := runQuery(ctx, bq, billingProject, fmt.Sprintf(dailyCostsQuery, table, baselineDays+recentDays), map[string]string{"project": projectID, "cluster": cluster})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var costs []dailyCost
+	for _, r := range rows {
+		cost, _ := strconv.ParseFloat(fmt.Sprint(r[5]), 64)
+		costs = append(costs, dailyCost{Day: fmt.Sprint(r[0]), Cluster: fmt.Sprint(r[1]), Namespace: fmt.Sprint(r[2]), NodePool: fmt.Sprint(r[3]), SKU: fmt.Sprint(r[4]), Cost: cost})
+	}
+	now := time.Now().UTC()
+	anomalies := findAnomalies(costs, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), baselineDays, recentDays, threshold)
+
+	text := fmt.Sprintf("Found %d cost anomalies in the last %d days of the GKE clusters of project %s, against their average over the %d days before.", len(anomalies), recentDays, projectID, baselineDays)
+	if len(costs) == 0 {
+		text = fmt.Sprintf("The billing export %s has no costs of GKE clusters of project %s in the last %d days. Check that the detailed usage cost export and GKE cost allocation are enabled.", table, projectID, baselineDays+recentDays)
+	}
+	data, err := json.MarshalIndent(struct {
+		Anomalies []costAnomaly `json:"anomalies"`
+		Notes     []string      `json:"notes"`
+	}{anomalies, []string{fmt.Sprintf("A day is anomalous when its cost, net of credits, exceeds the baseline by %g standard deviations, at least %.0f%% of the baseline, and by at least %.2f. Billing data of the last day may be incomplete.", threshold, minDeviation*100, minIncrease)}}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	list := findingList{Findings: []finding{}}
+	for _, a := range anomalies {
+		priority := "P3"
+		if a.Percent >= 50 || a.Baseline == 0 {
+			priority = "P2"
+		}
+		list.Findings = append(list.Findings, finding{
+			Name:        "cost-anomaly/" + a.resource() + "/" + a.Day,
+			Description: fmt.Sprintf("Cost of %s on %s was %.2f against %.2f a day. %s", a.resource(), a.Day, a.Cost, a.Baseline, strings.Join(a.Drivers, " ")),
+			Type:        "cost-anomaly",
+			Category:    "COST",
+			Priority:    priority,
+			Resource:    a.resource(),
+		})
+	}
+	result, err := structured.Result(text+"\n\n"+string(data), structured.Findings, list)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) detectCostAnomaliesCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID := session.ProjectID(ctx, request, h.c)
+	table, baselineDays, recentDays, _, err := anomalyArguments(request)
+	if projectID == "" || err != nil {
+		return nil
+	}
+	sql := fmt.Sprintf(dailyCostsQuery, table, baselineDays+recentDays)
+	sql = strings.ReplaceAll(sql, "@project", "'"+projectID+"'")
+	sql = strings.ReplaceAll(sql, "@cluster", "'"+request.GetString("cluster", "")+"'")
+	return []string{explain.Join("bq query --use_legacy_sql=false --format=csv", sql)}
+}
+
+// finding is a finding of the findings schema.
+type finding struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Category    string `json:"category"`
+	Priority    string `json:"priority"`
+	Resource    string `json:"resource"`
+}
+
+// findingList is the structured result of the tools reporting findings.
+type findingList struct {
+	Findings []finding `json:"findings"`
+}
+
+// projectPattern matches a project ID, which is embedded in scheduled queries as
+// they can't take parameters.
+var projectPattern = regexp.MustCompile(`^(?:[a-z0-9.-]+:)?[a-z][a-z0-9-]*[a-z0-9]$`)
+
+// scheduledAnomalyQuery appends yesterday's anomalous costs of the GKE
+// clusters of a project, or of their namespaces, to the destination table
+// of a BigQuery scheduled query. Unlike detect_cost_anomalies, days without
+// costs don't count in the baseline.
+const scheduledAnomalyQuery = `WITH daily AS (
+  SELECT
+    DATE(usage_start_time) AS day,
+    (SELECT value FROM UNNEST(labels) WHERE key = 'goog-k8s-cluster-name') AS cluster,
+    IFNULL((SELECT value FROM UNNEST(labels) WHERE key = 'k8s-namespace'), '') AS namespace,
+    SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) AS c), 0)) AS cost
+  FROM ` + "`%s`" + `
+  WHERE project.id = '%s'
+    AND usage_start_time >= TIMESTAMP(DATE_SUB(DATE(@run_time), INTERVAL %d DAY))
+    AND usage_start_time < TIMESTAMP(DATE(@run_time))
+    AND EXISTS (SELECT 1 FROM UNNEST(labels) WHERE key = 'goog-k8s-cluster-name')
+  GROUP BY day, cluster, namespace
+),
+stats AS (
+  SELECT cluster, namespace, AVG(cost) AS baseline, IFNULL(STDDEV_POP(cost), 0) AS stddev
+  FROM daily
+  WHERE day < DATE_SUB(DATE(@run_time), INTERVAL 1 DAY)
+  GROUP BY cluster, namespace
+)
+SELECT d.day, d.cluster, d.namespace, d.cost, s.baseline, s.stddev, @run_time AS detected_at
+FROM daily AS d JOIN stats AS s USING (cluster, namespace)
+WHERE d.day = DATE_SUB(DATE(@run_time), INTERVAL 1 DAY)
+  AND d.cost - s.baseline >= %g
+  AND d.cost > s.baseline + %g * GREATEST(s.stddev, %g * s.baseline)`
+
+// anomalyTransferConfig builds the BigQuery scheduled query that checks the
+// costs of a project's clusters every day, notifying a Pub/Sub topic after
+// every run.
+func anomalyTransferConfig(table, projectID, dataset, destinationTable, topic string, baselineDays int, threshold float64) (*datatransfer.TransferConfig, error) {
+	params, err := json.Marshal(map[string]string{
+		"query":                           fmt.Sprintf(scheduledAnomalyQuery, table, projectID, baselineDays+1, minIncrease, threshold, minDeviation),
+		"destination_table_name_template": destinationTable,
+		"write_disposition":               "WRITE_APPEND",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &datatransfer.TransferConfig{
+		DisplayName:             "GKE cost anomalies of " + projectID,
+		DataSourceId:            "scheduled_query",
+		DestinationDatasetId:    dataset,
+		Schedule:                "every 24 hours",
+		NotificationPubsubTopic: topic,
+		Params:                  params,
+	}, nil
+}
+
+func (h *handlers) scheduleCostAnomalyAlerts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	project := session.ProjectID(ctx, request, h.c)
+	if project == "" || !projectPattern.MatchString(project) {
+		return mcp.NewToolResultError("project_id argument not set or invalid"), nil
+	}
+	table, baselineDays, _, threshold, err := anomalyArguments(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dest, err := request.RequireString("destination_table")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	destProject, dataset, destTable, err := parseTable(dest)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	topic, err := request.RequireString("pubsub_topic")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !strings.HasPrefix(topic, "projects/") || !strings.Contains(topic, "/topics/") {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid pubsub_topic %q, want projects/PROJECT/topics/TOPIC", topic)), nil
+	}
+	location := request.GetString("dataset_location", "us")
+
+	cfg, err := anomalyTransferConfig(table, project, dataset, destTable, topic, baselineDays, threshold)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	parent := fmt.Sprintf("projects/%s/locations/%s", destProject, location)
+	if dryrun.Enabled(request, h.c) {
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		result := dryrun.Describe(fmt.Sprintf("create a daily BigQuery scheduled query in %s appending the cost anomalies of the GKE clusters of project %s to %s and notifying %s after each run", parent, project, dest, topic))
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf("Transfer config:\n```json\n%s\n```", data)))
+		return result, nil
+	}
+
+	opts, err := auth.ClientOptions(ctx, h.c, config.APIBigQueryDataTransfer)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dts, err := datatransfer.NewService(ctx, opts...)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	created, err := dts.Projects.Locations.TransferConfigs.Create(parent, cfg).Context(ctx).Do()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Created the scheduled query %s. It runs every 24 hours, next at %s, appends yesterday's anomalous costs to %s and publishes a message to %s after each run; subscribers can read the new rows of the table. Grant the BigQuery Data Transfer service agent the Pub/Sub Publisher role on the topic if runs fail to notify it.", created.Name, created.NextRunTime, dest, topic)), nil
+}
+
+func (h *handlers) scheduleCostAnomalyAlertsCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	project := session.ProjectID(ctx, request, h.c)
+	table, baselineDays, _, threshold, err := anomalyArguments(request)
+	dest, topic := request.GetString("destination_table", ""), request.GetString("pubsub_topic", "")
+	if project == "" || err != nil || dest == "" || topic == "" {
+		return nil
+	}
+	destProject, dataset, destTable, err := parseTable(dest)
+	if err != nil {
+		return nil
+	}
+	return []string{explain.Join("bq mk --transfer_config",
+		explain.Flag("project_id", destProject),
+		explain.Flag("location", request.GetString("dataset_location", "us")),
+		explain.Flag("data_source", "scheduled_query"),
+		explain.Flag("target_dataset", dataset),
+		explain.Flag("display_name", "GKE cost anomalies of "+project),
+		explain.Flag("schedule", "every 24 hours"),
+		explain.Flag("notification_pubsub_topic", topic),
+		explain.Flag("params", fmt.Sprintf(`{"query":%q,"destination_table_name_template":%q,"write_disposition":"WRITE_APPEND"}`, fmt.Sprintf(scheduledAnomalyQuery, table, project, baselineDays+1, minIncrease, threshold, minDeviation), destTable)),
+	)}
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindAnomalies(t *testing.T) {
+	today := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	var costs []dailyCost
+	for i, day := range days(today.AddDate(0, 0, -8), 7) {
+		costs = append(costs,
+			dailyCost{Day: day, Cluster: "prod", Namespace: "shop", NodePool: "default", SKU: "E2 Instance Core", Cost: 10},
+			dailyCost{Day: day, Cluster: "dev", NodePool: "default", SKU: "E2 Instance Core", Cost: 5 + float64(i%2)},
+		)
+	}
+	costs = append(costs,
+		dailyCost{Day: "2025-06-09", Cluster: "prod", Namespace: "shop", NodePool: "default", SKU: "E2 Instance Core", Cost: 10},
+		dailyCost{Day: "2025-06-09", Cluster: "prod", Namespace: "shop", NodePool: "gpu", SKU: "Nvidia L4 GPU", Cost: 15},
+		dailyCost{Day: "2025-06-09", Cluster: "prod", Namespace: "shop", SKU: "Network Internet Egress from Americas to Americas", Cost: 5},
+		dailyCost{Day: "2025-06-09", Cluster: "dev", NodePool: "default", SKU: "E2 Instance Core", Cost: 6.5},
+	)
+
+	drivers := []string{
+		"New node pool gpu, costing 15.00.",
+		"SKU Nvidia L4 GPU, costing 15.00 against 0.00 a day.",
+		"Egress spike in Network Internet Egress from Americas to Americas, costing 5.00 against 0.00 a day.",
+	}
+	want := []costAnomaly{
+		{Cluster: "prod", Day: "2025-06-09", Cost: 30, Baseline: 10, Increase: 20, Percent: 200, Drivers: drivers},
+		{Cluster: "prod", Namespace: "shop", Day: "2025-06-09", Cost: 30, Baseline: 10, Increase: 20, Percent: 200, Drivers: drivers},
+	}
+	if diff := cmp.Diff(want, findAnomalies(costs, today, 7, 1, 3)); diff != "" {
+		t.Errorf("findAnomalies() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAnomalyTransferConfig(t *testing.T) {
+	cfg, err := anomalyTransferConfig("billing.export.gcp_billing_export_resource_v1_X", "shop-prod", "finops", "gke_anomalies", "projects/finops/topics/alerts", 28, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var params map[string]string
+	if err := json.Unmarshal(cfg.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	if params["destination_table_name_template"] != "gke_anomalies" || params["write_disposition"] != "WRITE_APPEND" {
+		t.Errorf("anomalyTransferConfig() params = %v, want appending to gke_anomalies", params)
+	}
+	for _, s := range []string{"`billing.export.gcp_billing_export_resource_v1_X`", "project.id = 'shop-prod'", "INTERVAL 29 DAY", "s.baseline + 3 * GREATEST(s.stddev, 0.05 * s.baseline)"} {
+		if !strings.Contains(params["query"], s) {
+			t.Errorf("anomalyTransferConfig() query doesn't contain %q:\n%s", s, params["query"])
+		}
+	}
+	if cfg.DestinationDatasetId != "finops" || cfg.NotificationPubsubTopic != "projects/finops/topics/alerts" || cfg.DataSourceId != "scheduled_query" {
+		t.Errorf("anomalyTransferConfig() = %+v", cfg)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	bigquery "google.golang.org/api/bigquery/v2"
+)
+
+// tableName matches a BigQuery table as project.dataset.table, where the
+// project may be domain-scoped, e.g. example.com:project.
+var tableName = regexp.MustCompile(`^((?:[a-z0-9.-]+:)?[a-z][a-z0-9-]*[a-z0-9])\.(\w+)\.([\w-]+)$`)
+
+// parseTable splits a table name into its project, dataset and table IDs.
+func parseTable(name string) (project, dataset, table string, err error) {
+	m := tableName.FindStringSubmatch(name)
+	if m == nil {
+		return "", "", "", fmt.Errorf("invalid BigQuery table %q, want project.dataset.table", name)
+	}
+	return m[1], m[2], m[3], nil
+}
+
+// jobPollInterval is how often BigQuery jobs are polled.
+var jobPollInterval = 2 * time.Second
+
+func (h *handlers) bigQuery(ctx context.Context) (*bigquery.Service, error) {
+	opts, err := auth.ClientOptions(ctx, h.c, config.APIBigQuery)
+	if err != nil {
+		return nil, err
+	}
+	return bigquery.NewService(ctx, opts...)
+}
+
+// runQuery runs a standard SQL query billed to project and returns its rows.
+func runQuery(ctx context.Context, bq *bigquery.Service, project, sql string, params map[string]string) ([][]any, error) {
+	req := &bigquery.QueryRequest{Query: sql, UseLegacySql: new(bool), TimeoutMs: 60000}
+	for name, value := range params {
+		req.QueryParameters = append(req.QueryParameters, &bigquery.QueryParameter{
+			Name:           name,
+			ParameterType:  &bigquery.QueryParameterType{Type: "STRING"},
+			ParameterValue: &bigquery.QueryParameterValue{Value: value},
+		})
+	}
+	resp, err := bq.Jobs.Query(project, req).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	rows, complete, token, job := resp.Rows, resp.JobComplete, resp.PageToken, resp.JobReference
+	for !complete || token != "" {
+		if !complete {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(jobPollInterval):
+			}
+		}
+		call := bq.Jobs.GetQueryResults(job.ProjectId, job.JobId).Location(job.Location).PageToken(token).Context(ctx)
+		more, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		if more.JobComplete {
+			rows = append(rows, more.Rows...)
+		}
+		complete, token = more.JobComplete, more.PageToken
+	}
+	var result [][]any
+	for _, r := range rows {
+		var values []any
+		for _, cell := range r.F {
+			values = append(values, cell.V)
+		}
+		result = append(result, values)
+	}
+	return result, nil
+}
+
+// loadCSV appends CSV data, with a header row, to a table, creating it with
+// schema if needed, and waits for the load job to finish.
+func loadCSV(ctx context.Context, bq *bigquery.Service, project, dataset, table string, schema []*bigquery.TableFieldSchema, data []byte) error {
+	job := &bigquery.Job{Configuration: &bigquery.JobConfiguration{Load: &bigquery.JobConfigurationLoad{
+		DestinationTable:  &bigquery.TableReference{ProjectId: project, DatasetId: dataset, TableId: table},
+		Schema:            &bigquery.TableSchema{Fields: schema},
+		SourceFormat:      "CSV",
+		SkipLeadingRows:   1,
+		CreateDisposition: "CREATE_IF_NEEDED",
+		WriteDisposition:  "WRITE_APPEND",
+	}}}
+	job, err := bq.Jobs.Insert(project, job).Media(bytes.NewReader(data)).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	for job.Status == nil || job.Status.State != "DONE" {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("load job %s is still running: %w", job.JobReference.JobId, ctx.Err())
+		case <-time.After(jobPollInterval):
+		}
+		if job, err = bq.Jobs.Get(job.JobReference.ProjectId, job.JobReference.JobId).Location(job.JobReference.Location).Context(ctx).Do(); err != nil {
+			return err
+		}
+	}
+	if e := job.Status.ErrorResult; e != nil {
+		return fmt.Errorf("load job %s failed: %s", job.JobReference.JobId, e.Message)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Thresholds of the bin-packing report: nodes whose most requested
+// resource is below poorlyPacked are reported, and the nodes pods need are
+// counted filling them up to packingTarget.
+const (
+	poorlyPacked  = 0.5
+	packingTarget = 0.8
+)
+
+// poolPacking is how well the pods of a node pool are packed on its nodes.
+type poolPacking struct {
+	Pool        string `json:"pool"`
+	MachineType string `json:"machineType"`
+	Spot        bool   `json:"spot,omitempty"`
+	Nodes       int    `json:"nodes"`
+	// CPU and MemoryGiB are allocatable, Requested the part pods request
+	// and Stranded the rest.
+	CPU                float64 `json:"cpu"`
+	MemoryGiB          float64 `json:"memoryGiB"`
+	RequestedCPU       float64 `json:"requestedCPU"`
+	RequestedMemoryGiB float64 `json:"requestedMemoryGiB"`
+	StrandedCPU        float64 `json:"strandedCPU"`
+	StrandedMemoryGiB  float64 `json:"strandedMemoryGiB"`
+	// CPUPercent and MemoryPercent are the requested part of allocatable.
+	CPUPercent    float64 `json:"cpuPercent"`
+	MemoryPercent float64 `json:"memoryPercent"`
+	// StrandedMonthly is the monthly cost of the stranded resources.
+	StrandedMonthly float64 `json:"strandedMonthly"`
+	// PoorlyPacked lists the nodes whose most requested resource is below
+	// poorlyPacked.
+	PoorlyPacked []string `json:"poorlyPackedNodes,omitempty"`
+	// NeededNodes is the number of nodes the pods fit on, filled up to
+	// packingTarget, and Reduction how many fewer that is.
+	NeededNodes      int      `json:"neededNodes"`
+	Reduction        int      `json:"reduction"`
+	ReductionMonthly float64  `json:"reductionMonthly"`
+	Suggestions      []string `json:"suggestions,omitempty"`
+}
+
+func percent(part, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(part/total*1000) / 10
+}
+
+// packing reports how well the pods of each node pool are packed, the most
+// stranded cost first. DaemonSet pods run on every node, so they count
+// against the capacity of every node needed rather than being packed.
+func packing(a allocation) []poolPacking {
+	pools := map[string]*poolPacking{}
+	hourly := map[string]float64{}
+	daemons := map[string][2]float64{}
+	nodePool := map[string]string{}
+	for _, n := range a.Nodes {
+		name := n.Node.pool()
+		p := pools[name]
+		if p == nil {
+			p = &poolPacking{Pool: name, MachineType: n.MachineType, Spot: n.Spot}
+			pools[name] = p
+		}
+		nodePool[n.Node.Metadata.Name] = name
+		p.Nodes++
+		p.CPU += n.CPU
+		p.MemoryGiB += n.Memory
+		p.RequestedCPU += n.RequestedCPU
+		p.RequestedMemoryGiB += n.RequestedMemory
+		hourly[name] += n.Hourly
+		p.StrandedMonthly += n.Idle
+		if most := max(percent(n.RequestedCPU, n.CPU), percent(n.RequestedMemory, n.Memory)); most < poorlyPacked*100 {
+			p.PoorlyPacked = append(p.PoorlyPacked, fmt.Sprintf("%s: %.0f%% of CPU and %.0f%% of memory requested", n.Node.Metadata.Name, percent(n.RequestedCPU, n.CPU), percent(n.RequestedMemory, n.Memory)))
+		}
+	}
+	for _, p := range a.Pods {
+		if strings.Split(p.Workload, "/")[1] == "DaemonSet" {
+			pool := nodePool[p.Node]
+			d := daemons[pool]
+			daemons[pool] = [2]float64{d[0] + p.CPU, d[1] + p.Memory}
+		}
+	}
+
+	var result []poolPacking
+	for name, p := range pools {
+		nodes := float64(p.Nodes)
+		d := daemons[name]
+		perNodeCPU, perNodeMemory := (p.CPU-d[0])/nodes, (p.MemoryGiB-d[1])/nodes
+		packedCPU, packedMemory := p.RequestedCPU-d[0], p.RequestedMemoryGiB-d[1]
+		p.NeededNodes = 1
+		if perNodeCPU > 0 && perNodeMemory > 0 {
+			p.NeededNodes = max(1, int(math.Ceil(max(packedCPU/(perNodeCPU*packingTarget), packedMemory/(perNodeMemory*packingTarget)))))
+		}
+		p.Reduction = max(0, p.Nodes-p.NeededNodes)
+		p.ReductionMonthly = pricing.Monthly(hourly[name] / nodes * float64(p.Reduction))
+		p.StrandedMonthly = pricing.Monthly(p.StrandedMonthly)
+		p.StrandedCPU = round3(max(p.CPU-p.RequestedCPU, 0))
+		p.StrandedMemoryGiB = round3(max(p.MemoryGiB-p.RequestedMemoryGiB, 0))
+		p.CPUPercent, p.MemoryPercent = percent(p.RequestedCPU, p.CPU), percent(p.RequestedMemoryGiB, p.MemoryGiB)
+		p.CPU, p.MemoryGiB = round3(p.CPU), round3(p.MemoryGiB)
+		p.RequestedCPU, p.RequestedMemoryGiB = round3(p.RequestedCPU), round3(p.RequestedMemoryGiB)
+		p.suggest()
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].StrandedMonthly != result[j].StrandedMonthly {
+			return result[i].StrandedMonthly > result[j].StrandedMonthly
+		}
+		return result[i].Pool < result[j].Pool
+	})
+	return result
+}
+
+// shape returns the kind of machine type that would suit the requests of
+// the pool's pods better, highmem or highcpu, or "" if they're balanced.
+func (p *poolPacking) shape() string {
+	switch {
+	case p.MemoryPercent >= 70 && p.CPUPercent < 40:
+		return "highmem"
+	case p.CPUPercent >= 70 && p.MemoryPercent < 40:
+		return "highcpu"
+	}
+	return ""
+}
+
+// suggest suggests how to strand less capacity in the pool.
+func (p *poolPacking) suggest() {
+	if p.Reduction > 0 {
+		p.Suggestions = append(p.Suggestions, fmt.Sprintf("The pods fit on %d of the %d nodes at %.0f%% utilization, saving about %.2f %s a month; lower the minimum size of the pool if it keeps the nodes, or drain the poorly packed ones.", p.NeededNodes, p.Nodes, packingTarget*100, p.ReductionMonthly, pricing.Currency))
+	}
+	family := pricing.Family(p.MachineType)
+	switch p.shape() {
+	case "highmem":
+		p.Suggestions = append(p.Suggestions, fmt.Sprintf("Pods request mostly memory, %.0f%% of it but %.0f%% of CPU; a high-memory machine type such as %s-highmem strands less CPU.", p.MemoryPercent, p.CPUPercent, family))
+	case "highcpu":
+		p.Suggestions = append(p.Suggestions, fmt.Sprintf("Pods request mostly CPU, %.0f%% of it but %.0f%% of memory; a high-CPU machine type such as %s-highcpu strands less memory.", p.CPUPercent, p.MemoryPercent, family))
+	}
+}
+
+// packingAdvice suggests cluster-wide changes given the pools' packing.
+func packingAdvice(s *snapshot, pools []poolPacking) []string {
+	var advice []string
+	var reducible, unbalanced int
+	for _, p := range pools {
+		if p.Reduction > 0 {
+			reducible++
+		}
+		if p.shape() != "" {
+			unbalanced++
+		}
+	}
+	if reducible > 0 && !s.OptimizeUtilization {
+		advice = append(advice, "Use the optimize-utilization autoscaling profile, so the cluster autoscaler removes underused nodes sooner and the scheduler packs pods tighter.")
+	}
+	if unbalanced > 0 && !s.AutoProvisioning {
+		advice = append(advice, "Enable node auto-provisioning, so GKE creates node pools with machine types shaped for the pods' requests.")
+	}
+	return advice
+}
+
+func (h *handlers) binPackingReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s, name, err := h.clusterSnapshot(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if s.Autopilot {
+		return mcp.NewToolResultText(fmt.Sprintf("Cluster %s runs in Autopilot mode, which bills the requests of pods rather than nodes, so how they are packed doesn't change what it costs.", name)), nil
+	}
+
+	a := allocate(s)
+	pools := packing(a)
+	list := costList{Currency: pricing.Currency, Items: []costItem{}}
+	var reduction int
+	var savings float64
+	for _, p := range pools {
+		reduction += p.Reduction
+		savings += p.ReductionMonthly
+		list.add(costItem{Name: p.Pool, Dimension: "stranded capacity", Cost: p.StrandedMonthly, Labels: map[string]string{
+			"machine_type": p.MachineType,
+			"nodes":        strconv.Itoa(p.Nodes),
+			"needed_nodes": strconv.Itoa(p.NeededNodes),
+			"cpu_percent":  strconv.FormatFloat(p.CPUPercent, 'f', -1, 64),
+			"mem_percent":  strconv.FormatFloat(p.MemoryPercent, 'f', -1, 64),
+		}})
+	}
+
+	text := fmt.Sprintf("The %d node pools of cluster %s strand about %.2f %s a month of capacity no pod requests. Packing the pods better could remove %d nodes, saving about %.2f %s a month.", len(pools), name, list.Total, pricing.Currency, reduction, pricing.Round(savings), pricing.Currency)
+	data, err := json.MarshalIndent(struct {
+		Pools       []poolPacking `json:"nodePools"`
+		Suggestions []string      `json:"suggestions,omitempty"`
+		Notes       []string      `json:"notes"`
+	}{pools, packingAdvice(s, pools), append(a.Notes[:1:1], fmt.Sprintf("The nodes needed are counted filling them up to %.0f%% of their allocatable resources, with DaemonSet pods on every node; pod affinities, topology spread constraints and node pool minimum sizes may need more.", packingTarget*100))}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(text+"\n\n"+string(data), structured.Costs, list)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) binPackingReportCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl describe nodes | grep --after-context=8 'Allocated resources'",
+		explain.Join("gcloud container clusters describe", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--format=yaml(autoscaling,nodePools[].autoscaling)"),
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPacking(t *testing.T) {
+	s := &snapshot{
+		Nodes: decode[node](t, `[
+			{"metadata":{"name":"default-1","labels":{"node.kubernetes.io/instance-type":"e2-standard-4","cloud.google.com/gke-nodepool":"default"}},
+				"status":{"capacity":{"cpu":"4","memory":"16Gi"},"allocatable":{"cpu":"3500m","memory":"12Gi"}}},
+			{"metadata":{"name":"default-2","labels":{"node.kubernetes.io/instance-type":"e2-standard-4","cloud.google.com/gke-nodepool":"default"}},
+				"status":{"capacity":{"cpu":"4","memory":"16Gi"},"allocatable":{"cpu":"3500m","memory":"12Gi"}}},
+			{"metadata":{"name":"default-3","labels":{"node.kubernetes.io/instance-type":"e2-standard-4","cloud.google.com/gke-nodepool":"default"}},
+				"status":{"capacity":{"cpu":"4","memory":"16Gi"},"allocatable":{"cpu":"3500m","memory":"12Gi"}}},
+			{"metadata":{"name":"mem-1","labels":{"node.kubernetes.io/instance-type":"n2-standard-2","cloud.google.com/gke-nodepool":"mem"}},
+				"status":{"capacity":{"cpu":"2","memory":"8Gi"},"allocatable":{"cpu":"1900m","memory":"6Gi"}}}
+		]`),
+		Pods: decode[pod](t, `[
+			{"metadata":{"name":"agent-1","namespace":"ops","ownerReferences":[{"kind":"DaemonSet","name":"agent"}]},"spec":{"nodeName":"default-1","containers":[{"resources":{"requests":{"cpu":"100m","memory":"512Mi"}}}]}},
+			{"metadata":{"name":"agent-2","namespace":"ops","ownerReferences":[{"kind":"DaemonSet","name":"agent"}]},"spec":{"nodeName":"default-2","containers":[{"resources":{"requests":{"cpu":"100m","memory":"512Mi"}}}]}},
+			{"metadata":{"name":"agent-3","namespace":"ops","ownerReferences":[{"kind":"DaemonSet","name":"agent"}]},"spec":{"nodeName":"default-3","containers":[{"resources":{"requests":{"cpu":"100m","memory":"512Mi"}}}]}},
+			{"metadata":{"name":"a","namespace":"shop"},"spec":{"nodeName":"default-1","containers":[{"resources":{"requests":{"cpu":"2","memory":"2Gi"}}}]}},
+			{"metadata":{"name":"b","namespace":"shop"},"spec":{"nodeName":"default-2","containers":[{"resources":{"requests":{"cpu":"1","memory":"1Gi"}}}]}},
+			{"metadata":{"name":"c","namespace":"shop"},"spec":{"nodeName":"default-3","containers":[{"resources":{"requests":{"cpu":"500m","memory":"512Mi"}}}]}},
+			{"metadata":{"name":"cache","namespace":"shop"},"spec":{"nodeName":"mem-1","containers":[{"resources":{"requests":{"cpu":"500m","memory":"4608Mi"}}}]}}
+		]`),
+	}
+	type summary struct {
+		Pool                      string
+		Nodes, Needed, Reduction  int
+		CPUPercent, MemoryPercent float64
+		PoorlyPacked              int
+		Shape                     string
+	}
+	var got []summary
+	pools := packing(allocate(s))
+	for _, p := range pools {
+		got = append(got, summary{p.Pool, p.Nodes, p.NeededNodes, p.Reduction, p.CPUPercent, p.MemoryPercent, len(p.PoorlyPacked), p.shape()})
+	}
+	want := []summary{
+		{Pool: "default", Nodes: 3, Needed: 2, Reduction: 1, CPUPercent: 36.2, MemoryPercent: 13.9, PoorlyPacked: 2},
+		{Pool: "mem", Nodes: 1, Needed: 1, CPUPercent: 26.3, MemoryPercent: 75, Shape: "highmem"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("packing() mismatch (-want +got):\n%s", diff)
+	}
+	if pools[0].ReductionMonthly <= 0 || pools[0].ReductionMonthly >= pools[0].StrandedMonthly {
+		t.Errorf("packing() saves %v removing a node out of %v stranded, want a part of it", pools[0].ReductionMonthly, pools[0].StrandedMonthly)
+	}
+
+	if advice := packingAdvice(s, pools); len(advice) != 2 {
+		t.Errorf("packingAdvice() = %q, want the autoscaling profile and node auto-provisioning", advice)
+	}
+	s.OptimizeUtilization, s.AutoProvisioning = true, true
+	if advice := packingAdvice(s, pools); len(advice) != 0 {
+		t.Errorf("packingAdvice() = %q, want none when both are enabled", advice)
+	}
+}
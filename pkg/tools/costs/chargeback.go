@@ -0,0 +1,371 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	bigquery "google.golang.org/api/bigquery/v2"
+	storage "google.golang.org/api/storage/v1"
+)
+
+// Teams of the costs that aren't attributed to a team.
+const (
+	unlabeled      = "(unlabeled)"
+	sharedOverhead = "(shared overhead)"
+	idleCapacity   = "(idle)"
+)
+
+// defaultTeamLabel is the label chargeback reports group by by default.
+const defaultTeamLabel = "team"
+
+// chargebackRow is the cost of the pods of a team in a namespace over a
+// billing period.
+type chargebackRow struct {
+	Period    string
+	Cluster   string
+	Team      string
+	Namespace string
+	// CPU and MemoryGiB are the requested resources, for estimates.
+	CPU       float64
+	MemoryGiB float64
+	Cost      float64
+	Currency  string
+}
+
+// chargebackSchema is the schema of the BigQuery tables chargeback reports
+// are loaded into, in the order of the CSV columns.
+var chargebackSchema = []*bigquery.TableFieldSchema{
+	{Name: "period", Type: "STRING"},
+	{Name: "cluster", Type: "STRING"},
+	{Name: "team", Type: "STRING"},
+	{Name: "namespace", Type: "STRING"},
+	{Name: "cpu_requested", Type: "FLOAT"},
+	{Name: "memory_requested_gib", Type: "FLOAT"},
+	{Name: "cost", Type: "FLOAT"},
+	{Name: "currency", Type: "STRING"},
+}
+
+// encodeCSV encodes rows as CSV, with a header row.
+func encodeCSV(rows []chargebackRow) []byte {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	var header []string
+	for _, f := range chargebackSchema {
+		header = append(header, f.Name)
+	}
+	w.Write(header)
+	format := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for _, r := range rows {
+		w.Write([]string{r.Period, r.Cluster, r.Team, r.Namespace, format(r.CPU), format(r.MemoryGiB), format(r.Cost), r.Currency})
+	}
+	w.Flush()
+	return b.Bytes()
+}
+
+// parsePeriod parses a billing period as YYYY-MM. It defaults to the
+// current month, or to the previous one if it must be over.
+func parsePeriod(period string, now time.Time, over bool) (time.Time, error) {
+	if period == "" {
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if over {
+			month = month.AddDate(0, -1, 0)
+		}
+		return month, nil
+	}
+	month, err := time.Parse("2006-01", period)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid period %q, want YYYY-MM", period)
+	}
+	return month, nil
+}
+
+// teamOf returns the team of a pod: the value of its label, or of the label
+// of its namespace.
+func teamOf(p pod, namespaces map[string]map[string]string, label string) string {
+	if team := p.Metadata.Labels[label]; team != "" {
+		return team
+	}
+	if team := namespaces[p.Metadata.Namespace][label]; team != "" {
+		return team
+	}
+	return unlabeled
+}
+
+// estimateChargeback estimates the cost of every team and namespace over a
+// month at the current requests, with the shared overhead and idle capacity
+// of the nodes as rows of their own.
+func estimateChargeback(s *snapshot, a allocation, cluster, label string, month time.Time) []chargebackRow {
+	hours := month.AddDate(0, 1, 0).Sub(month).Hours()
+	period := month.Format("2006-01")
+	type key struct{ team, namespace string }
+	rows := map[key]*chargebackRow{}
+	for _, p := range a.Pods {
+		if p.system() {
+			continue
+		}
+		k := key{teamOf(p.Pod, s.Namespaces, label), p.Pod.Metadata.Namespace}
+		r := rows[k]
+		if r == nil {
+			r = &chargebackRow{Period: period, Cluster: cluster, Team: k.team, Namespace: k.namespace, Currency: pricing.Currency}
+			rows[k] = r
+		}
+		r.CPU += p.CPU
+		r.MemoryGiB += p.Memory
+		r.Cost += p.Hourly * hours
+	}
+	var result []chargebackRow
+	for _, r := range rows {
+		r.CPU, r.MemoryGiB, r.Cost = round3(r.CPU), round3(r.MemoryGiB), pricing.Round(r.Cost)
+		result = append(result, *r)
+	}
+	sortRows(result)
+	if !s.Autopilot {
+		var shared, idle float64
+		for _, n := range a.Nodes {
+			shared += n.Reserved + n.System
+			idle += n.Idle
+		}
+		result = append(result,
+			chargebackRow{Period: period, Cluster: cluster, Team: sharedOverhead, Cost: pricing.Round(shared * hours), Currency: pricing.Currency},
+			chargebackRow{Period: period, Cluster: cluster, Team: idleCapacity, Cost: pricing.Round(idle * hours), Currency: pricing.Currency},
+		)
+	}
+	return result
+}
+
+func sortRows(rows []chargebackRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Cost != rows[j].Cost {
+			return rows[i].Cost > rows[j].Cost
+		}
+		if rows[i].Team != rows[j].Team {
+			return rows[i].Team < rows[j].Team
+		}
+		return rows[i].Namespace < rows[j].Namespace
+	})
+}
+
+// billingQuery sums the costs, net of credits, of a cluster in a Cloud
+// Billing detailed export table, by the GKE cost allocation labels of a team
+// and namespace.
+const billingQuery = `SELECT
+  IFNULL((SELECT value FROM UNNEST(labels) WHERE key = @label), '') AS team,
+  IFNULL((SELECT value FROM UNNEST(labels) WHERE key = 'k8s-namespace'), '') AS namespace,
+  SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) AS c), 0)) AS cost,
+  ANY_VALUE(currency) AS currency
+FROM ` + "`%s`" + `
+WHERE invoice.month = @month
+  AND project.id = @project
+  AND EXISTS (SELECT 1 FROM UNNEST(labels) WHERE key = 'goog-k8s-cluster-name' AND value = @cluster)
+  AND EXISTS (SELECT 1 FROM UNNEST(labels) WHERE key = 'goog-k8s-cluster-location' AND value = @location)
+GROUP BY team, namespace
+ORDER BY cost DESC`
+
+// billedChargeback queries the billed costs of a cluster over a month from a
+// billing export table.
+func (h *handlers) billedChargeback(ctx context.Context, table, projectID, location, cluster, label string, month time.Time) ([]chargebackRow, error) {
+	billingProject, _, _, err := parseTable(table)
+	if err != nil {
+		return nil, err
+	}
+	bq, err := h.bigQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values, err := runQuery(ctx, bq, billingProject, fmt.Sprintf(billingQuery, table), map[string]string{
+		"label":    "k8s-label/" + label,
+		"month":    month.Format("200601"),
+		"project":  projectID,
+		"cluster":  cluster,
+		"location": location,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []chargebackRow
+	for _, v := range values {
+		r := chargebackRow{Period: month.Format("2006-01"), Cluster: cluster, Team: fmt.Sprint(v[0]), Namespace: fmt.Sprint(v[1]), Currency: fmt.Sprint(v[3])}
+		cost, _ := strconv.ParseFloat(fmt.Sprint(v[2]), 64)
+		r.Cost = pricing.Round(cost)
+		if r.Team == "" {
+			r.Team = unlabeled
+		}
+		rows = append(rows, r)
+	}
+	sortRows(rows)
+	return rows, nil
+}
+
+// destination is where a report is written: an absolute local path, a
+// gs://bucket/object URI or a bq://project.dataset.table table.
+type destination struct {
+	Path                    string
+	Bucket, Object          string
+	Project, Dataset, Table string
+}
+
+func parseDestination(s string) (destination, error) {
+	switch {
+	case strings.HasPrefix(s, "gs://"):
+		bucket, object, _ := strings.Cut(strings.TrimPrefix(s, "gs://"), "/")
+		if bucket == "" || object == "" {
+			return destination{}, fmt.Errorf("invalid destination %q, want gs://bucket/object", s)
+		}
+		return destination{Bucket: bucket, Object: object}, nil
+	case strings.HasPrefix(s, "bq://"):
+		project, dataset, table, err := parseTable(strings.TrimPrefix(s, "bq://"))
+		return destination{Project: project, Dataset: dataset, Table: table}, err
+	case filepath.IsAbs(s):
+		return destination{Path: s}, nil
+	}
+	return destination{}, fmt.Errorf("invalid destination %q, want an absolute path, a gs:// URI or a bq:// table", s)
+}
+
+// write writes the CSV data of a report to d.
+func (h *handlers) write(ctx context.Context, d destination, data []byte) error {
+	switch {
+	case d.Path != "":
+		return os.WriteFile(d.Path, data, 0644)
+	case d.Bucket != "":
+		opts, err := auth.ClientOptions(ctx, h.c, config.APIStorage)
+		if err != nil {
+			return err
+		}
+		gcs, err := storage.NewService(ctx, opts...)
+		if err != nil {
+			return err
+		}
+		_, err = gcs.Objects.Insert(d.Bucket, &storage.Object{Name: d.Object, ContentType: "text/csv"}).Media(bytes.NewReader(data)).Context(ctx).Do()
+		return err
+	}
+	bq, err := h.bigQuery(ctx)
+	if err != nil {
+		return err
+	}
+	return loadCSV(ctx, bq, d.Project, d.Dataset, d.Table, chargebackSchema, data)
+}
+
+func (h *handlers) exportChargebackReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	cluster := session.Cluster(ctx, request, h.c, "cluster")
+	if cluster == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	label := request.GetString("label", defaultTeamLabel)
+	billingTable := request.GetString("billing_table", "")
+	month, err := parsePeriod(request.GetString("period", ""), time.Now(), billingTable != "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var dest destination
+	target := request.GetString("destination", "")
+	if target != "" {
+		if dest, err = parseDestination(target); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	var rows []chargebackRow
+	var source string
+	if billingTable != "" {
+		if rows, err = h.billedChargeback(ctx, billingTable, projectID, location, cluster, label, month); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		source = fmt.Sprintf("billed costs, net of credits, from the billing export %s, attributed with the GKE cost allocation labels k8s-namespace and k8s-label/%s", billingTable, label)
+	} else {
+		s, _, err := h.clusterSnapshot(ctx, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		rows = estimateChargeback(s, allocate(s), cluster, label, month)
+		source = fmt.Sprintf("an estimate over the whole month at the current requests of the running pods, attributed with the %s label of the pods or their namespaces; pass billing_table for billed costs. %s", label, s.prices().Note)
+	}
+
+	list := costList{Currency: pricing.Currency, Items: []costItem{}}
+	for _, r := range rows {
+		list.Currency = r.Currency
+		list.add(costItem{Name: r.Team, Dimension: label, Cost: r.Cost, Labels: map[string]string{"namespace": r.Namespace, "period": r.Period}})
+	}
+	data := encodeCSV(rows)
+	text := fmt.Sprintf("Chargeback report of cluster %s for %s by %s: %d rows, %.2f %s in total, from %s.", cluster, month.Format("2006-01"), label, len(rows), list.Total, list.Currency, source)
+	report := fmt.Sprintf("\n\n```csv\n%s```", data)
+
+	switch {
+	case target == "":
+	case dryrun.Enabled(request, h.c):
+		result := dryrun.Describe(fmt.Sprintf("write the %d rows of the chargeback report to %s", len(rows), target))
+		result.Content = append(result.Content, mcp.NewTextContent(text+report))
+		return result, nil
+	default:
+		if err := h.write(ctx, dest, data); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("writing the report to %s failed: %v", target, err)), nil
+		}
+		text += fmt.Sprintf(" Wrote it to %s.", target)
+	}
+	result, err := structured.Result(text+report, structured.Costs, list)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) exportChargebackReportCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	label := request.GetString("label", defaultTeamLabel)
+	if table := request.GetString("billing_table", ""); table != "" {
+		month, err := parsePeriod(request.GetString("period", ""), time.Now(), true)
+		if err != nil {
+			return nil
+		}
+		sql := fmt.Sprintf(billingQuery, table)
+		for name, value := range map[string]string{"label": "k8s-label/" + label, "month": month.Format("200601"), "project": projectID, "cluster": cluster, "location": location} {
+			sql = strings.ReplaceAll(sql, "@"+name, "'"+value+"'")
+		}
+		return []string{explain.Join("bq query --use_legacy_sql=false --format=csv", sql)}
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get pods --all-namespaces --field-selector=status.phase=Running " + explain.Flag("label-columns", label) + " --output=custom-columns='NAMESPACE:.metadata.namespace,NAME:.metadata.name,NODE:.spec.nodeName,CPU:.spec.containers[*].resources.requests.cpu,MEMORY:.spec.containers[*].resources.requests.memory'",
+		explain.Join("kubectl get namespaces", explain.Flag("label-columns", label)),
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEstimateChargeback(t *testing.T) {
+	s := testSnapshot(t, false)
+	s.Pods[2].Metadata.Labels = map[string]string{"team": "data"}
+	s.Namespaces = map[string]map[string]string{"shop": {"team": "storefront"}}
+	a := allocate(s)
+	onDemand, _ := pricing.Machine("e2-standard-4", false)
+	spot, _ := pricing.Machine("e2-standard-4", true)
+	hours := 30 * 24.0
+
+	got := estimateChargeback(s, a, "prod", "team", time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC))
+	var shared, idle float64
+	for _, n := range a.Nodes {
+		shared += n.Reserved + n.System
+		idle += n.Idle
+	}
+	want := []chargebackRow{
+		{Period: "2025-09", Cluster: "prod", Team: "data", Namespace: "shop", CPU: 2, MemoryGiB: 2, Cost: pricing.Round(onDemand.Price(2, 2) * hours), Currency: "USD"},
+		{Period: "2025-09", Cluster: "prod", Team: "storefront", Namespace: "shop", CPU: 2, MemoryGiB: 8, Cost: pricing.Round((onDemand.Price(1, 4) + spot.Price(1, 4)) * hours), Currency: "USD"},
+		{Period: "2025-09", Cluster: "prod", Team: sharedOverhead, Cost: pricing.Round(shared * hours), Currency: "USD"},
+		{Period: "2025-09", Cluster: "prod", Team: idleCapacity, Cost: pricing.Round(idle * hours), Currency: "USD"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("estimateChargeback() mismatch (-want +got):\n%s", diff)
+	}
+
+	wantCSV := "period,cluster,team,namespace,cpu_requested,memory_requested_gib,cost,currency\n2025-09,prod,(idle),,0,0,1.5,USD\n"
+	if got := string(encodeCSV([]chargebackRow{{Period: "2025-09", Cluster: "prod", Team: idleCapacity, Cost: 1.5, Currency: "USD"}})); got != wantCSV {
+		t.Errorf("encodeCSV() = %q, want %q", got, wantCSV)
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		period  string
+		over    bool
+		want    string
+		wantErr bool
+	}{
+		{period: "", want: "2025-01"},
+		{period: "", over: true, want: "2024-12"},
+		{period: "2024-06", over: true, want: "2024-06"},
+		{period: "June", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := parsePeriod(tc.period, now, tc.over)
+		if (err != nil) != tc.wantErr || (err == nil && got.Format("2006-01") != tc.want) {
+			t.Errorf("parsePeriod(%q, %v) = %v, %v, want %s", tc.period, tc.over, got, err, tc.want)
+		}
+	}
+}
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    destination
+		wantErr bool
+	}{
+		{in: "/tmp/report.csv", want: destination{Path: "/tmp/report.csv"}},
+		{in: "gs://finops/gke/2025-09.csv", want: destination{Bucket: "finops", Object: "gke/2025-09.csv"}},
+		{in: "bq://my-project.finops.chargeback", want: destination{Project: "my-project", Dataset: "finops", Table: "chargeback"}},
+		{in: "bq://example.com:my-project.finops.chargeback", want: destination{Project: "example.com:my-project", Dataset: "finops", Table: "chargeback"}},
+		{in: "gs://finops", wantErr: true},
+		{in: "bq://finops.chargeback", wantErr: true},
+		{in: "report.csv", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := parseDestination(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("parseDestination(%q) = %+v, %v, want %+v", tc.in, got, err, tc.want)
+		}
+	}
+}
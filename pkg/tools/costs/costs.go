@@ -0,0 +1,423 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/selector"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("costs", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	estimateWorkloadCostTool := mcp.NewTool("estimate_workload_cost",
+		mcp.WithDescription("Estimate the monthly cost of the workloads of a GKE cluster, such as Deployments and StatefulSets, from the resource requests of their running pods priced at the rate of the node pools they run on, or at Autopilot rates. Shared node overhead, the capacity reserved for the system and GKE-managed pods, and idle capacity are reported apart rather than spread over workloads. Costs can also be grouped by namespace, node pool or labels such as team or environment, nested up to three levels, and filtered by a label selector."),
+		catalog.Describe(catalog.Optimization, catalog.Read, "container.clusters.get", "container.nodes.list", "container.pods.list", "container.namespaces.list"),
+		explain.Command(h.estimateWorkloadCostCommands),
+		structured.Output(structured.Costs),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("namespace", mcp.Description("Only estimate the workloads of this namespace.")),
+		mcp.WithString("workload", mcp.Description("Only estimate this workload, as Kind/name like Deployment/web, or a name.")),
+		mcp.WithString(selector.Argument, mcp.Description("Only estimate the pods whose labels, or the labels of their namespace, match this selector: comma-separated requirements that must all hold, each one of key=value, key!=value, key (label is set) or !key (label is not set). Example: environment=prod,team!=data.")),
+		mcp.WithString("group_by", mcp.Description(fmt.Sprintf("Group the costs by up to %d comma-separated dimensions, nested in order: namespace, workload, node_pool, or the key of a label of the pods or their namespaces, like team or app.kubernetes.io/name. Example: team,namespace. Defaults to grouping by workload.", maxGroupLevels))),
+	)
+	s.AddTool(estimateWorkloadCostTool, h.estimateWorkloadCost)
+
+	spotSavingsReportTool := mcp.NewTool("spot_savings_report",
+		mcp.WithDescription("Find the workloads of a GKE cluster that tolerate disruption, such as stateless Deployments with several replicas and PodDisruptionBudgets that allow evictions, and estimate the monthly savings of moving them to Spot nodes. Workloads that don't, like StatefulSets, pods with PersistentVolumeClaims or single replicas, are listed with the reasons."),
+		catalog.Describe(catalog.Optimization, catalog.Read, "container.clusters.get", "container.nodes.list", "container.pods.list", "container.namespaces.list", "container.podDisruptionBudgets.list"),
+		explain.Command(h.spotSavingsReportCommands),
+		structured.Output(structured.Costs),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("namespace", mcp.Description("Only report the workloads of this namespace.")),
+	)
+	s.AddTool(spotSavingsReportTool, h.spotSavingsReport)
+
+	binPackingReportTool := mcp.NewTool("bin_packing_report",
+		mcp.WithDescription("Report how well the pods of a GKE Standard cluster are packed on its nodes: the capacity each node pool strands, allocatable but not requested, and its cost, the poorly packed nodes, and how many fewer nodes the pods would fit on. Suggests machine types matching the pods' requests, the optimize-utilization autoscaling profile or node auto-provisioning."),
+		catalog.Describe(catalog.Optimization, catalog.Read, "container.clusters.get", "container.nodes.list", "container.pods.list", "container.namespaces.list"),
+		explain.Command(h.binPackingReportCommands),
+		structured.Output(structured.Costs),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+	)
+	s.AddTool(binPackingReportTool, h.binPackingReport)
+
+	compareAutopilotCostTool := mcp.NewTool("compare_autopilot_cost",
+		mcp.WithDescription("Model what the workloads of a GKE Standard cluster would cost on Autopilot, which bills the resource requests of pods rather than nodes, against what its nodes cost now, per workload and in total, and find the break-even utilization of the nodes below which Autopilot is cheaper."),
+		catalog.Describe(catalog.Optimization, catalog.Read, "container.clusters.get", "container.nodes.list", "container.pods.list", "container.namespaces.list"),
+		explain.Command(h.compareAutopilotCostCommands),
+		structured.Output(structured.Costs),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE Standard cluster name. Defaults to the session context.")),
+	)
+	s.AddTool(compareAutopilotCostTool, h.compareAutopilotCost)
+
+	exportChargebackReportTool := mcp.NewTool("export_chargeback_report",
+		mcp.WithDescription("Generate a per-team cost and usage report of a GKE cluster for a billing month, grouped by a Kubernetes label of the pods or their namespaces, such as team, and by namespace, and write it as CSV to a local file, a Cloud Storage object or a BigQuery table for chargeback. Costs are billed costs from a Cloud Billing export with GKE cost allocation if billing_table is set, or else an estimate from the current requests. Always do a dry run first and ask the user to confirm before writing the report."),
+		catalog.Describe(catalog.Optimization, catalog.Write, "container.clusters.get", "container.nodes.list", "container.pods.list", "container.namespaces.list", "bigquery.jobs.create", "bigquery.tables.getData", "bigquery.tables.create", "bigquery.tables.updateData", "storage.objects.create"),
+		explain.Command(h.exportChargebackReportCommands),
+		structured.Output(structured.Costs),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("label", mcp.Description(fmt.Sprintf("Label identifying the team of pods, or of their namespace. Defaults to %s.", defaultTeamLabel))),
+		mcp.WithString("period", mcp.Description("Billing month as YYYY-MM. Defaults to the previous month with billing_table, or the current one for estimates.")),
+		mcp.WithString("billing_table", mcp.Description("Cloud Billing detailed usage cost export table, as project.dataset.table, to report billed costs from. The cluster needs GKE cost allocation enabled.")),
+		mcp.WithString("destination", mcp.Description("Where to write the report as CSV: an absolute local path, a gs://bucket/object URI, or a BigQuery table as bq://project.dataset.table, which rows are appended to. If not set, the report is only returned.")),
+		dryrun.Argument(c),
+	)
+	s.AddTool(exportChargebackReportTool, h.exportChargebackReport)
+
+	detectCostAnomaliesTool := mcp.NewTool("detect_cost_anomalies",
+		mcp.WithDescription("Detect anomalies in the daily spend of the GKE clusters of a project, and of their namespaces, by comparing the recent days of a Cloud Billing detailed usage cost export against a trailing baseline, and explain each anomaly with its drivers, such as a new node pool, a node pool scaling out or an egress spike. Needs GKE cost allocation for namespaces."),
+		catalog.Describe(catalog.Optimization, catalog.Query, "bigquery.jobs.create", "bigquery.tables.getData"),
+		explain.Command(h.detectCostAnomaliesCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID of the clusters. Defaults to the session context.")),
+		mcp.WithString("billing_table", mcp.Required(), mcp.Description("Cloud Billing detailed usage cost export table, as project.dataset.table.")),
+		mcp.WithString("cluster", mcp.Description("Only check this cluster. Defaults to every cluster of the project.")),
+		mcp.WithNumber("baseline_days", mcp.Description(fmt.Sprintf("Number of days of the trailing baseline. Defaults to %d.", defaultBaselineDays))),
+		mcp.WithNumber("recent_days", mcp.Description(fmt.Sprintf("Number of recent days, up to yesterday, checked against the baseline. Defaults to %d.", defaultRecentDays))),
+		mcp.WithNumber("threshold", mcp.Description(fmt.Sprintf("Number of standard deviations above the baseline a day's cost must be to be anomalous. Defaults to %g.", defaultThreshold))),
+	)
+	s.AddTool(detectCostAnomaliesTool, h.detectCostAnomalies)
+
+	attributeEgressCostTool := mcp.NewTool("attribute_egress_cost",
+		mcp.WithDescription("Attribute the billed network egress of the GKE clusters of a project, from a Cloud Billing detailed usage cost export, to clusters and to inter-zone, inter-region, internet and other egress, and, for a cluster whose subnet has VPC flow logs, to the namespaces and workloads sending it, by their share of the bytes in the flow logs."),
+		catalog.Describe(catalog.Optimization, catalog.Query, "bigquery.jobs.create", "bigquery.tables.getData", "logging.logEntries.list"),
+		explain.Command(h.attributeEgressCostCommands),
+		structured.Output(structured.Costs),
+		mcp.WithString("project_id", mcp.Description("GCP project ID of the clusters. Defaults to the session context.")),
+		mcp.WithString("billing_table", mcp.Required(), mcp.Description("Cloud Billing detailed usage cost export table, as project.dataset.table.")),
+		mcp.WithString("cluster", mcp.Description("Only attribute the egress of this cluster, to its namespaces and workloads too. Defaults to every cluster of the project.")),
+		mcp.WithNumber("days", mcp.Description(fmt.Sprintf("Number of days of billed egress. Defaults to %d.", defaultEgressDays))),
+		mcp.WithNumber("flow_hours", mcp.Description(fmt.Sprintf("Number of hours of flow logs to split the egress of the cluster by. Flow logs are large, so keep it short. Defaults to %d.", defaultFlowHours))),
+	)
+	s.AddTool(attributeEgressCostTool, h.attributeEgressCost)
+
+	scheduleCostAnomalyAlertsTool := mcp.NewTool("schedule_cost_anomaly_alerts",
+		mcp.WithDescription("Schedule a daily BigQuery scheduled query that checks yesterday's spend of the GKE clusters of a project, and of their namespaces, against a trailing baseline from a Cloud Billing export, appends the anomalies to a table and notifies a Pub/Sub topic after each run. Always do a dry run first and ask the user to confirm before scheduling."),
+		catalog.Describe(catalog.Optimization, catalog.Write, "bigquery.transfers.update", "bigquery.jobs.create", "bigquery.tables.getData", "bigquery.tables.create", "bigquery.tables.updateData"),
+		explain.Command(h.scheduleCostAnomalyAlertsCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID of the clusters. Defaults to the session context.")),
+		mcp.WithString("billing_table", mcp.Required(), mcp.Description("Cloud Billing detailed usage cost export table, as project.dataset.table.")),
+		mcp.WithString("destination_table", mcp.Required(), mcp.Description("Table the anomalies are appended to, as project.dataset.table. The scheduled query is created in its project.")),
+		mcp.WithString("dataset_location", mcp.Description("Location of the dataset of the destination table, e.g. us, eu or us-central1. Defaults to us.")),
+		mcp.WithString("pubsub_topic", mcp.Required(), mcp.Description("Pub/Sub topic notified after each run, as projects/PROJECT/topics/TOPIC.")),
+		mcp.WithNumber("baseline_days", mcp.Description(fmt.Sprintf("Number of days of the trailing baseline. Defaults to %d.", defaultBaselineDays))),
+		mcp.WithNumber("threshold", mcp.Description(fmt.Sprintf("Number of standard deviations above the baseline a day's cost must be to be anomalous. Defaults to %g.", defaultThreshold))),
+		dryrun.Argument(c),
+	)
+	s.AddTool(scheduleCostAnomalyAlertsTool, h.scheduleCostAnomalyAlerts)
+
+	getListPricesTool := mcp.NewTool("get_list_prices",
+		mcp.WithDescription("Quote the current list prices of GKE compute in a region from the Cloud Billing Catalog: the hourly and monthly price of the nodes of a machine type, on demand and on Spot, of a GPU, of a GiB of a disk type, and of the vCPUs and memory requested by Autopilot pods. Without a machine type, GPU or disk type, quotes the prices of every machine family, GPU and disk type. Use it instead of guessing prices."),
+		catalog.Describe(catalog.Optimization, catalog.Read, "compute.machineTypes.list"),
+		explain.Command(h.getListPricesCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID, to look up the shape of machine_type. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("Region, or zone of the region, to quote prices in. Defaults to the session context.")),
+		mcp.WithString("machine_type", mcp.Description("Machine type of the nodes to quote, like n2-standard-8.")),
+		mcp.WithString("gpu", mcp.Description("GPU to quote, by its GKE accelerator name like nvidia-l4 or nvidia-tesla-t4.")),
+		mcp.WithString("disk_type", mcp.Description("Disk type to quote, like pd-balanced, pd-ssd or hyperdisk-balanced.")),
+		cache.RefreshOption(),
+	)
+	s.AddTool(getListPricesTool, h.getListPrices)
+
+	enableCostAllocationTool := mcp.NewTool("enable_cost_allocation",
+		mcp.WithDescription("Enable GKE cost allocation, which breaks down the billed costs of a cluster by namespace and label, and usage metering into a BigQuery dataset, created if needed, on a GKE cluster, waiting for each update, then verify that billing is enabled and that the Cloud Billing export to BigQuery has the cluster's costs. The cost tools that read billed costs depend on these. Always do a dry run first and ask the user to confirm before enabling them."),
+		catalog.Describe(catalog.Optimization, catalog.Write, "container.clusters.get", "container.clusters.update", "container.operations.get", "bigquery.datasets.get", "bigquery.datasets.create", "bigquery.jobs.create", "bigquery.tables.getData", "resourcemanager.projects.get"),
+		explain.Command(h.enableCostAllocationCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("dataset", mcp.Description(fmt.Sprintf("BigQuery dataset of the project usage metering exports to. Defaults to the one the cluster already exports to, or %s.", defaultUsageDataset))),
+		mcp.WithString("dataset_location", mcp.Description("Location of the dataset if it is created, e.g. us or us-central1. Defaults to the region of the cluster.")),
+		mcp.WithBoolean("network_egress", mcp.Description("Also meter network egress, which runs a metering agent on the nodes. Defaults to false.")),
+		mcp.WithString("billing_table", mcp.Description("Cloud Billing detailed usage cost export table, as project.dataset.table, to check for the cluster's costs.")),
+		dryrun.Argument(c),
+	)
+	s.AddTool(enableCostAllocationTool, h.enableCostAllocation)
+
+	createSpotNodePoolTool := mcp.NewTool("create_spot_node_pool",
+		mcp.WithDescription("Create an autoscaled node pool of Spot VMs in a GKE Standard cluster, tainted so that only workloads that tolerate preemption run on it, and return the toleration and node selector to add to those workloads. Always do a dry run first and ask the user to confirm before creating the node pool."),
+		catalog.Describe(catalog.Optimization, catalog.Write, "container.clusters.update", "container.operations.get"),
+		explain.Command(h.createSpotNodePoolCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the node pool to create.")),
+		mcp.WithString("machine_type", mcp.Description(fmt.Sprintf("Machine type of the nodes. Defaults to %s; Spot capacity is more likely to be available for common machine types.", defaultSpotMachineType))),
+		mcp.WithNumber("min_nodes", mcp.Description("Minimum number of nodes per zone the autoscaler keeps. Defaults to 0.")),
+		mcp.WithNumber("max_nodes", mcp.Description(fmt.Sprintf("Maximum number of nodes per zone the autoscaler adds. Defaults to %d.", defaultSpotMaxNodes))),
+		mcp.WithBoolean("taint", mcp.Description("Taint the nodes with cloud.google.com/gke-spot=true:NoSchedule, so that only workloads that tolerate it are scheduled on them. Defaults to true.")),
+		dryrun.Argument(c),
+	)
+	s.AddTool(createSpotNodePoolTool, h.createSpotNodePool)
+
+	return nil
+}
+
+// clusterSnapshot gets whether the cluster runs Autopilot, the nodes and
+// running pods of the cluster and the list prices of its region.
+func (h *handlers) clusterSnapshot(ctx context.Context, request mcp.CallToolRequest) (*snapshot, string, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return nil, "", fmt.Errorf("project_id argument not set")
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return nil, "", fmt.Errorf("location argument not set")
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return nil, "", fmt.Errorf("cluster argument not set")
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return nil, "", err
+	}
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+	if err != nil {
+		return nil, "", err
+	}
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return nil, "", err
+	}
+	s, err := load(ctx, k, cluster.GetAutopilot().GetEnabled())
+	if err != nil {
+		return nil, "", err
+	}
+	autoscaling := cluster.GetAutoscaling()
+	s.AutoProvisioning = autoscaling.GetEnableNodeAutoprovisioning()
+	s.OptimizeUtilization = autoscaling.GetAutoscalingProfile() == containerpb.ClusterAutoscaling_OPTIMIZE_UTILIZATION
+	s.Prices = pricing.ForLocation(ctx, h.c, location)
+	return s, name, nil
+}
+
+// workloadCost is the estimated cost of a workload.
+type workloadCost struct {
+	Workload  string   `json:"workload"`
+	Namespace string   `json:"namespace"`
+	Pods      int      `json:"pods"`
+	NodePools []string `json:"nodePools,omitempty"`
+	CPU       float64  `json:"cpu"`
+	MemoryGiB float64  `json:"memoryGiB"`
+	Monthly   float64  `json:"monthly"`
+	PerPod    float64  `json:"monthlyPerPod"`
+}
+
+// byWorkload sums the costs of the pods of every workload, most expensive
+// first, leaving out GKE-managed pods.
+func byWorkload(a allocation) []workloadCost {
+	costs := map[string]*workloadCost{}
+	var hourly = map[string]float64{}
+	for _, p := range a.Pods {
+		if p.system() {
+			continue
+		}
+		w := costs[p.Workload]
+		if w == nil {
+			w = &workloadCost{Workload: p.Workload, Namespace: p.Pod.Metadata.Namespace}
+			costs[p.Workload] = w
+		}
+		w.Pods++
+		w.CPU += p.CPU
+		w.MemoryGiB += p.Memory
+		hourly[p.Workload] += p.Hourly
+		if p.Pool != "" && !slices.Contains(w.NodePools, p.Pool) {
+			w.NodePools = append(w.NodePools, p.Pool)
+		}
+	}
+	var result []workloadCost
+	for key, w := range costs {
+		w.Monthly = pricing.Monthly(hourly[key])
+		w.PerPod = pricing.Monthly(hourly[key] / float64(w.Pods))
+		w.CPU = round3(w.CPU)
+		w.MemoryGiB = round3(w.MemoryGiB)
+		slices.Sort(w.NodePools)
+		result = append(result, *w)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Monthly != result[j].Monthly {
+			return result[i].Monthly > result[j].Monthly
+		}
+		return result[i].Workload < result[j].Workload
+	})
+	return result
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// overhead returns the monthly cost of the shared overhead and idle
+// resources of the nodes.
+func overhead(a allocation) (float64, float64) {
+	var shared, idle float64
+	for _, n := range a.Nodes {
+		shared += n.Reserved + n.System
+		idle += n.Idle
+	}
+	return pricing.Monthly(shared), pricing.Monthly(idle)
+}
+
+// matchesWorkload tells whether a workload, as namespace/Kind/name, is the
+// one asked for, as Kind/name or name.
+func matchesWorkload(workload, filter string) bool {
+	_, kindName, _ := strings.Cut(workload, "/")
+	if strings.Contains(filter, "/") {
+		return strings.EqualFold(kindName, filter)
+	}
+	_, name, _ := strings.Cut(kindName, "/")
+	return name == filter
+}
+
+// costItem is an item of the costs schema.
+type costItem struct {
+	Name      string            `json:"name"`
+	Dimension string            `json:"dimension"`
+	Cost      float64           `json:"cost"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// costList is the structured result of the cost tools, following the costs
+// schema.
+type costList struct {
+	Currency string     `json:"currency"`
+	Total    float64    `json:"total"`
+	Items    []costItem `json:"items"`
+}
+
+func (l *costList) add(item costItem) {
+	l.Items = append(l.Items, item)
+	l.Total = pricing.Round(l.Total + item.Cost)
+}
+
+func (h *handlers) estimateWorkloadCost(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s, name, err := h.clusterSnapshot(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace, filter := request.GetString("namespace", ""), request.GetString("workload", "")
+	sel, err := selector.Parse(request.GetString(selector.Argument, ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dims, err := parseGroupBy(request.GetString("group_by", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	a := filterPods(allocate(s), func(p podCost) bool {
+		return (namespace == "" || p.Pod.Metadata.Namespace == namespace) &&
+			(filter == "" || matchesWorkload(p.Workload, filter)) &&
+			sel.Matches(labelsOf(p.Pod, s.Namespaces))
+	})
+	if filter != "" && len(a.Pods) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("no running pods of workload %s found", filter)), nil
+	}
+	whole := namespace == "" && filter == "" && sel.Empty() && !s.Autopilot
+	if len(dims) > 0 {
+		return groupedCostResult(s, a, name, dims, whole)
+	}
+
+	list := costList{Currency: pricing.Currency, Items: []costItem{}}
+	var workloads []workloadCost
+	for _, w := range byWorkload(a) {
+		workloads = append(workloads, w)
+		list.add(costItem{Name: w.Workload, Dimension: "workload", Cost: w.Monthly, Labels: map[string]string{
+			"namespace":  w.Namespace,
+			"pods":       strconv.Itoa(w.Pods),
+			"cpu":        strconv.FormatFloat(w.CPU, 'f', -1, 64),
+			"memory_gib": strconv.FormatFloat(w.MemoryGiB, 'f', -1, 64),
+		}})
+	}
+
+	text := fmt.Sprintf("Estimated monthly cost of %d workloads of cluster %s: %.2f %s.", len(workloads), name, list.Total, pricing.Currency)
+	if whole {
+		shared, idle := overhead(a)
+		list.add(costItem{Name: "shared overhead", Dimension: "overhead", Cost: shared})
+		list.add(costItem{Name: "idle", Dimension: "overhead", Cost: idle})
+		text += fmt.Sprintf(" The nodes also cost %.2f for shared overhead and %.2f for idle capacity, %.2f in total.", shared, idle, list.Total)
+	}
+	data, err := json.MarshalIndent(struct {
+		Workloads []workloadCost `json:"workloads"`
+		Notes     []string       `json:"notes"`
+	}{workloads, a.Notes}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(text+"\n\n"+string(data), structured.Costs, list)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) estimateWorkloadCostCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	pods := explain.Join("kubectl get pods --all-namespaces", explain.Flag("selector", request.GetString(selector.Argument, "")))
+	if namespace := request.GetString("namespace", ""); namespace != "" {
+		pods = explain.Join("kubectl get pods", explain.Flag("namespace", namespace), explain.Flag("selector", request.GetString(selector.Argument, "")))
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get nodes --label-columns=node.kubernetes.io/instance-type,cloud.google.com/gke-nodepool,cloud.google.com/gke-spot",
+		pods + " --field-selector=status.phase=Running --output=custom-columns='NAME:.metadata.name,NODE:.spec.nodeName,CPU:.spec.containers[*].resources.requests.cpu,MEMORY:.spec.containers[*].resources.requests.memory'",
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Classes of network egress.
+const (
+	intraZone   = "intra-zone"
+	interZone   = "inter-zone"
+	interRegion = "inter-region"
+	internet    = "internet"
+	otherEgress = "other"
+)
+
+// Defaults of the egress attribution arguments.
+const (
+	defaultEgressDays = 30
+	defaultFlowHours  = 1
+)
+
+// maxFlows is the number of flow log entries read to attribute egress.
+const maxFlows = 20000
+
+// notGKE names the egress of the project's VMs outside of GKE clusters.
+const notGKE = "(not GKE)"
+
+// egressCostsQuery sums the network egress costs, net of credits, and usage
+// of the VMs of a project in a Cloud Billing detailed export table, by
+// cluster and SKU.
+const egressCostsQuery = `SELECT
+  IFNULL((SELECT value FROM UNNEST(labels) WHERE key = 'goog-k8s-cluster-name'), '') AS cluster,
+  sku.description AS sku,
+  SUM(usage.amount_in_pricing_units) AS usage,
+  ANY_VALUE(usage.pricing_unit) AS unit,
+  SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) AS c), 0)) AS cost,
+  ANY_VALUE(currency) AS currency
+FROM ` + "`%s`" + `
+WHERE project.id = @project
+  AND usage_start_time >= TIMESTAMP(DATE_SUB(CURRENT_DATE(), INTERVAL %d DAY))
+  AND service.description = 'Compute Engine'
+  AND (LOWER(sku.description) LIKE '%%egress%%' OR LOWER(sku.description) LIKE '%%data transfer%%')
+  AND (@cluster = '' OR EXISTS (SELECT 1 FROM UNNEST(labels) WHERE key = 'goog-k8s-cluster-name' AND value = @cluster))
+GROUP BY cluster, sku`
+
+// egressClass classifies an egress SKU, like "Network Inter Zone Data
+// Transfer Out" or "Network Internet Data Transfer Out from Americas to
+// Americas".
+func egressClass(sku string) string {
+	s := strings.ToLower(sku)
+	switch {
+	case strings.Contains(s, "inter zone"):
+		return interZone
+	case strings.Contains(s, "inter region"):
+		return interRegion
+	case strings.Contains(s, "internet"):
+		return internet
+	}
+	return otherEgress
+}
+
+// egressCost is the billed egress of a class from a cluster.
+type egressCost struct {
+	Cluster string  `json:"cluster"`
+	Class   string  `json:"class"`
+	Usage   float64 `json:"usage"`
+	Unit    string  `json:"unit"`
+	Cost    float64 `json:"cost"`
+}
+
+// sumEgress sums the billed egress rows by cluster and class, the most
+// expensive first.
+func sumEgress(rows [][]any) ([]egressCost, string) {
+	currency := pricing.Currency
+	sums := map[[2]string]*egressCost{}
+	for _, r := range rows {
+		cluster := fmt.Sprint(r[0])
+		if cluster == "" {
+			cluster = notGKE
+		}
+		k := [2]string{cluster, egressClass(fmt.Sprint(r[1]))}
+		c := sums[k]
+		if c == nil {
+			c = &egressCost{Cluster: k[0], Class: k[1], Unit: fmt.Sprint(r[3])}
+			sums[k] = c
+		}
+		usage, _ := strconv.ParseFloat(fmt.Sprint(r[2]), 64)
+		cost, _ := strconv.ParseFloat(fmt.Sprint(r[4]), 64)
+		c.Usage += usage
+		c.Cost += cost
+		currency = fmt.Sprint(r[5])
+	}
+	var result []egressCost
+	for _, c := range sums {
+		c.Usage, c.Cost = round3(c.Usage), pricing.Round(c.Cost)
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		if result[i].Cluster != result[j].Cluster {
+			return result[i].Cluster < result[j].Cluster
+		}
+		return result[i].Class < result[j].Class
+	})
+	return result, currency
+}
+
+// flowEndpoint is the VM of one end of a flow.
+type flowEndpoint struct {
+	Region string `json:"region"`
+	Zone   string `json:"zone"`
+}
+
+// flow is the part of a VPC flow log entry attributed.
+type flow struct {
+	BytesSent    json.Number   `json:"bytes_sent"`
+	SrcInstance  *flowEndpoint `json:"src_instance"`
+	DestInstance *flowEndpoint `json:"dest_instance"`
+	DestLocation *struct {
+		Country string `json:"country"`
+	} `json:"dest_location"`
+	SrcGKEDetails struct {
+		Pod struct {
+			Name      string `json:"pod_name"`
+			Namespace string `json:"pod_namespace"`
+		} `json:"pod"`
+	} `json:"src_gke_details"`
+}
+
+// class classifies the egress of a flow by where it goes. Flows to Google
+// services, peered networks and load balancers are other egress.
+func (f flow) class() string {
+	switch {
+	case f.DestInstance != nil && f.SrcInstance != nil:
+		switch {
+		case f.DestInstance.Zone == f.SrcInstance.Zone:
+			return intraZone
+		case f.DestInstance.Region == f.SrcInstance.Region:
+			return interZone
+		}
+		return interRegion
+	case f.DestLocation != nil && f.DestLocation.Country != "":
+		return internet
+	}
+	return otherEgress
+}
+
+// podSuffix matches the suffixes controllers add to the names of their
+// pods: a ReplicaSet hash and a pod hash, a pod hash, or a StatefulSet
+// ordinal.
+var podSuffix = regexp.MustCompile(`(-[a-z0-9]{6,10}-[a-z0-9]{5}|-[a-z0-9]{5}|-[0-9]+)$`)
+
+// workloadOfPod guesses the workload of a pod from its name, as flow logs
+// don't name it, and the pods may be gone.
+func workloadOfPod(name string) string {
+	if w := podSuffix.ReplaceAllString(name, ""); w != "" {
+		return w
+	}
+	return name
+}
+
+// workloadEgress is the share of the billed egress of a class of a cluster
+// attributed to a workload by its bytes in the flow logs.
+type workloadEgress struct {
+	Namespace string  `json:"namespace"`
+	Workload  string  `json:"workload"`
+	Class     string  `json:"class"`
+	GiB       float64 `json:"sampledGiB"`
+	Share     float64 `json:"share"`
+	Cost      float64 `json:"cost"`
+}
+
+// attributeEgress splits the billed cost of every class of egress of a
+// cluster over the workloads sending it, by their share of the bytes of the
+// class in the flows. Intra-zone egress is free, so left out.
+func attributeEgress(costs map[string]float64, flows []flow) []workloadEgress {
+	type key struct{ namespace, workload, class string }
+	bytes := map[key]float64{}
+	totals := map[string]float64{}
+	for _, f := range flows {
+		class := f.class()
+		n, err := f.BytesSent.Float64()
+		if class == intraZone || err != nil || f.SrcGKEDetails.Pod.Name == "" {
+			continue
+		}
+		k := key{f.SrcGKEDetails.Pod.Namespace, workloadOfPod(f.SrcGKEDetails.Pod.Name), class}
+		bytes[k] += n
+		totals[class] += n
+	}
+	var result []workloadEgress
+	for k, n := range bytes {
+		share := n / totals[k.class]
+		result = append(result, workloadEgress{
+			Namespace: k.namespace,
+			Workload:  k.workload,
+			Class:     k.class,
+			GiB:       round3(n / (1 << 30)),
+			Share:     round3(share),
+			Cost:      pricing.Round(share * costs[k.class]),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		if result[i].GiB != result[j].GiB {
+			return result[i].GiB > result[j].GiB
+		}
+		return result[i].Namespace+"/"+result[i].Workload+"/"+result[i].Class < result[j].Namespace+"/"+result[j].Workload+"/"+result[j].Class
+	})
+	return result
+}
+
+// flowFilter selects the flows a cluster's pods sent.
+func flowFilter(projectID, cluster string) string {
+	return fmt.Sprintf(`logName="projects/%s/logs/compute.googleapis.com%%2Fvpc_flows" AND jsonPayload.reporter="SRC" AND jsonPayload.src_gke_details.cluster.cluster_name="%s"`, projectID, cluster)
+}
+
+// clusterFlows reads up to maxFlows of the flows a cluster's pods sent over
+// the last hours.
+func (h *handlers) clusterFlows(ctx context.Context, projectID, cluster string, hours int) ([]flow, error) {
+	client, err := gcp.Logging(ctx, h.c)
+	if err != nil {
+		return nil, err
+	}
+	it := client.ListLogEntries(ctx, &loggingpb.ListLogEntriesRequest{
+		ResourceNames: []string{"projects/" + projectID},
+		Filter:        fmt.Sprintf(`%s AND timestamp>="%s"`, flowFilter(projectID, cluster), time.Now().Add(-time.Duration(hours)*time.Hour).UTC().Format(time.RFC3339)),
+		OrderBy:       "timestamp desc",
+		PageSize:      1000,
+	})
+	var flows []flow
+	for len(flows) < maxFlows {
+		e, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the flow logs of cluster %s: %w", cluster, err)
+		}
+		data, err := protojson.Marshal(e.GetJsonPayload())
+		if err != nil {
+			return nil, err
+		}
+		var f flow
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, err
+		}
+		flows = append(flows, f)
+	}
+	return flows, nil
+}
+
+func (h *handlers) attributeEgressCost(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	table, err := request.RequireString("billing_table")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	billingProject, _, _, err := parseTable(table)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster := request.GetString("cluster", "")
+	days, hours := request.GetInt("days", defaultEgressDays), request.GetInt("flow_hours", defaultFlowHours)
+	if days < 1 || hours < 1 {
+		return mcp.NewToolResultError("days and flow_hours must be at least 1"), nil
+	}
+
+	bq, err := h.bigQuery(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	rows, err := runQuery(ctx, bq, billingProject, fmt.Sprintf(egressCostsQuery, table, days), map[string]string{"project": projectID, "cluster": cluster})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	costs, currency := sumEgress(rows)
+	list := costList{Currency: currency, Items: []costItem{}}
+	for _, c := range costs {
+		list.add(costItem{Name: c.Cluster + "/" + c.Class, Dimension: "egress", Cost: c.Cost, Labels: map[string]string{"cluster": c.Cluster, "class": c.Class, "usage": strconv.FormatFloat(c.Usage, 'f', -1, 64) + " " + c.Unit}})
+	}
+	text := fmt.Sprintf("Network egress of the VMs of project %s over the last %d days: %.2f %s, billed by cluster and class.", projectID, days, list.Total, currency)
+
+	var workloads []workloadEgress
+	var notes []string
+	if cluster == "" {
+		notes = append(notes, "Set cluster to attribute its egress to namespaces and workloads from VPC flow logs.")
+	} else {
+		flows, err := h.clusterFlows(ctx, projectID, cluster, hours)
+		switch {
+		case err != nil:
+			notes = append(notes, fmt.Sprintf("The egress couldn't be attributed to workloads: %v", err))
+		case len(flows) == 0:
+			notes = append(notes, fmt.Sprintf("No VPC flow logs of the pods of cluster %s in the last %d hours, so the egress isn't attributed to workloads. Enable flow logs on the cluster's subnet; with Shared VPC they are in the host project.", cluster, hours))
+		default:
+			byClass := map[string]float64{}
+			for _, c := range costs {
+				byClass[c.Class] += c.Cost
+			}
+			workloads = attributeEgress(byClass, flows)
+			notes = append(notes, fmt.Sprintf("Billed egress is split over workloads by their share of the bytes of each class in %d sampled flows of the last %d hours, so the split assumes the traffic of that window is typical. Workloads are guessed from pod names.", len(flows), hours))
+		}
+	}
+	// The workloads' egress is part of the cluster's, so it isn't added to
+	// the total again.
+	for _, w := range workloads {
+		list.Items = append(list.Items, costItem{Name: w.Namespace + "/" + w.Workload + "/" + w.Class, Dimension: "workload egress", Cost: w.Cost, Labels: map[string]string{"namespace": w.Namespace, "workload": w.Workload, "class": w.Class, "share": strconv.FormatFloat(w.Share, 'f', -1, 64)}})
+	}
+	notes = append(notes, "Intra-zone traffic is free. Other egress includes traffic to Google services, peered networks and through load balancers.")
+
+	data, err := json.MarshalIndent(struct {
+		Egress    []egressCost     `json:"egress"`
+		Workloads []workloadEgress `json:"workloads,omitempty"`
+		Notes     []string         `json:"notes"`
+	}{costs, workloads, notes}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(text+"\n\n"+string(data), structured.Costs, list)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) attributeEgressCostCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, table := session.ProjectID(ctx, request, h.c), request.GetString("billing_table", "")
+	if projectID == "" || table == "" {
+		return nil
+	}
+	cluster := request.GetString("cluster", "")
+	sql := fmt.Sprintf(egressCostsQuery, table, request.GetInt("days", defaultEgressDays))
+	sql = strings.ReplaceAll(sql, "@project", "'"+projectID+"'")
+	sql = strings.ReplaceAll(sql, "@cluster", "'"+cluster+"'")
+	commands := []string{explain.Join("bq query --use_legacy_sql=false --format=csv", sql)}
+	if cluster != "" {
+		hours := request.GetInt("flow_hours", defaultFlowHours)
+		commands = append(commands, explain.Join("gcloud logging read", flowFilter(projectID, cluster), explain.Flag("project", projectID), explain.Flag("freshness", fmt.Sprintf("%dh", hours)), explain.Flag("limit", strconv.Itoa(maxFlows))))
+	}
+	return commands
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSumEgress(t *testing.T) {
+	rows := [][]any{
+		{"prod", "Network Inter Zone Data Transfer Out", "100", "gibibyte", "1", "USD"},
+		{"prod", "Network Internet Data Transfer Out from Americas to Americas", "50", "gibibyte", "6", "USD"},
+		{"prod", "Network Internet Data Transfer Out from Americas to EMEA", "10", "gibibyte", "1.2", "USD"},
+		{"prod", "Network Inter Region Data Transfer Out from Americas to EMEA", "20", "gibibyte", "0.4", "USD"},
+		{"", "Network Egress via Carrier Peering Network - Americas Based", "5", "gibibyte", "0.2", "USD"},
+	}
+	got, currency := sumEgress(rows)
+	want := []egressCost{
+		{Cluster: "prod", Class: internet, Usage: 60, Unit: "gibibyte", Cost: 7.2},
+		{Cluster: "prod", Class: interZone, Usage: 100, Unit: "gibibyte", Cost: 1},
+		{Cluster: "prod", Class: interRegion, Usage: 20, Unit: "gibibyte", Cost: 0.4},
+		{Cluster: notGKE, Class: otherEgress, Usage: 5, Unit: "gibibyte", Cost: 0.2},
+	}
+	if diff := cmp.Diff(want, got); diff != "" || currency != "USD" {
+		t.Errorf("sumEgress() in %s mismatch (-want +got):\n%s", currency, diff)
+	}
+}
+
+func TestWorkloadOfPod(t *testing.T) {
+	for name, want := range map[string]string{
+		"web-7d9f8b6c5d-x2k9p": "web",
+		"fluentbit-gke-x7k2p":  "fluentbit-gke",
+		"db-0":                 "db",
+		"standalone":           "standalone",
+	} {
+		if got := workloadOfPod(name); got != want {
+			t.Errorf("workloadOfPod(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestAttributeEgress(t *testing.T) {
+	flows := decode[flow](t, `[
+		{"bytes_sent":"3221225472","src_instance":{"region":"us-central1","zone":"us-central1-a"},"dest_location":{"country":"usa"},
+			"src_gke_details":{"pod":{"pod_name":"web-7d9f8b6c5d-x2k9p","pod_namespace":"shop"}}},
+		{"bytes_sent":"1073741824","src_instance":{"region":"us-central1","zone":"us-central1-a"},"dest_location":{"country":"deu"},
+			"src_gke_details":{"pod":{"pod_name":"api-0","pod_namespace":"shop"}}},
+		{"bytes_sent":"1073741824","src_instance":{"region":"us-central1","zone":"us-central1-a"},"dest_instance":{"region":"us-central1","zone":"us-central1-b"},
+			"src_gke_details":{"pod":{"pod_name":"api-1","pod_namespace":"shop"}}},
+		{"bytes_sent":"1073741824","src_instance":{"region":"us-central1","zone":"us-central1-a"},"dest_instance":{"region":"us-central1","zone":"us-central1-a"},
+			"src_gke_details":{"pod":{"pod_name":"web-7d9f8b6c5d-x2k9p","pod_namespace":"shop"}}}
+	]`)
+	got := attributeEgress(map[string]float64{internet: 8, interZone: 1}, flows)
+	want := []workloadEgress{
+		{Namespace: "shop", Workload: "web", Class: internet, GiB: 3, Share: 0.75, Cost: 6},
+		{Namespace: "shop", Workload: "api", Class: internet, GiB: 1, Share: 0.25, Cost: 2},
+		{Namespace: "shop", Workload: "api", Class: interZone, GiB: 1, Share: 1, Cost: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("attributeEgress() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Dimensions costs are grouped by other than labels.
+const (
+	dimNamespace = "namespace"
+	dimWorkload  = "workload"
+	dimNodePool  = "node_pool"
+)
+
+// maxGroupLevels is the number of dimensions costs can be grouped by.
+const maxGroupLevels = 3
+
+// maxTopGroups is the number of outermost groups summarized.
+const maxTopGroups = 5
+
+// parseGroupBy parses comma-separated dimensions, from the outermost group
+// in, where anything but namespace, workload and node_pool is a label key.
+func parseGroupBy(s string) ([]string, error) {
+	var dims []string
+	for _, d := range strings.Split(s, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		if slices.Contains(dims, d) {
+			return nil, fmt.Errorf("group_by names %s twice", d)
+		}
+		dims = append(dims, d)
+	}
+	if len(dims) > maxGroupLevels {
+		return nil, fmt.Errorf("group_by names %d dimensions, at most %d are allowed", len(dims), maxGroupLevels)
+	}
+	return dims, nil
+}
+
+// labelsOf returns the labels of a pod, with the labels of its namespace
+// that it doesn't set itself.
+func labelsOf(p pod, namespaces map[string]map[string]string) map[string]string {
+	labels := maps.Clone(namespaces[p.Metadata.Namespace])
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	maps.Copy(labels, p.Metadata.Labels)
+	return labels
+}
+
+// filterPods returns an allocation with only the pods to keep, and all the
+// nodes.
+func filterPods(a allocation, keep func(podCost) bool) allocation {
+	filtered := a
+	filtered.Pods = nil
+	for _, p := range a.Pods {
+		if keep(p) {
+			filtered.Pods = append(filtered.Pods, p)
+		}
+	}
+	return filtered
+}
+
+// costGroup is the estimated cost of the pods with the same value of a
+// dimension, within the group of the previous dimensions.
+type costGroup struct {
+	Dimension string       `json:"dimension"`
+	Value     string       `json:"value"`
+	Pods      int          `json:"pods"`
+	CPU       float64      `json:"cpu"`
+	MemoryGiB float64      `json:"memoryGiB"`
+	Monthly   float64      `json:"monthly"`
+	Groups    []*costGroup `json:"groups,omitempty"`
+
+	hourly float64
+	index  map[string]*costGroup
+}
+
+// groupValue returns the value of a dimension for a pod, whose labels
+// include the ones of its namespace.
+func groupValue(p podCost, dim string, labels map[string]string) string {
+	switch dim {
+	case dimNamespace:
+		return p.Pod.Metadata.Namespace
+	case dimWorkload:
+		return p.Workload
+	case dimNodePool:
+		if p.Pool == "" {
+			return "none"
+		}
+		return p.Pool
+	}
+	if v := labels[dim]; v != "" {
+		return v
+	}
+	return unlabeled
+}
+
+// groupCosts sums the costs of the pods, leaving out GKE-managed ones, into
+// groups nested by dimension, the most expensive first.
+func groupCosts(s *snapshot, a allocation, dims []string) []*costGroup {
+	root := &costGroup{index: map[string]*costGroup{}}
+	for _, p := range a.Pods {
+		if p.system() {
+			continue
+		}
+		labels := labelsOf(p.Pod, s.Namespaces)
+		parent := root
+		for _, dim := range dims {
+			value := groupValue(p, dim, labels)
+			g := parent.index[value]
+			if g == nil {
+				g = &costGroup{Dimension: dim, Value: value, index: map[string]*costGroup{}}
+				parent.index[value] = g
+				parent.Groups = append(parent.Groups, g)
+			}
+			g.Pods++
+			g.CPU += p.CPU
+			g.MemoryGiB += p.Memory
+			g.hourly += p.Hourly
+			parent = g
+		}
+	}
+	finish(root.Groups)
+	return root.Groups
+}
+
+// finish rounds the groups and sorts them, the most expensive first.
+func finish(groups []*costGroup) {
+	for _, g := range groups {
+		g.Monthly = pricing.Monthly(g.hourly)
+		g.CPU, g.MemoryGiB = round3(g.CPU), round3(g.MemoryGiB)
+		finish(g.Groups)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Monthly != groups[j].Monthly {
+			return groups[i].Monthly > groups[j].Monthly
+		}
+		return groups[i].Value < groups[j].Value
+	})
+}
+
+// leaves returns the innermost groups as cost items, named after their
+// value and the values of the groups they're in, with dims as dimension.
+func leaves(groups []*costGroup, dims []string, labels map[string]string) []costItem {
+	var items []costItem
+	for _, g := range groups {
+		labels := maps.Clone(labels)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[g.Dimension] = g.Value
+		if len(g.Groups) > 0 {
+			items = append(items, leaves(g.Groups, dims, labels)...)
+			continue
+		}
+		var path []string
+		for _, d := range dims {
+			path = append(path, labels[d])
+		}
+		items = append(items, costItem{Name: strings.Join(path, " / "), Dimension: strings.Join(dims, " / "), Cost: g.Monthly, Labels: labels})
+	}
+	return items
+}
+
+// groupedCostResult returns the estimated costs of the pods of an
+// allocation grouped by dims, and the shared overhead and idle capacity of
+// the nodes if the pods are the whole cluster's.
+func groupedCostResult(s *snapshot, a allocation, cluster string, dims []string, whole bool) (*mcp.CallToolResult, error) {
+	groups := groupCosts(s, a, dims)
+	list := costList{Currency: pricing.Currency, Items: []costItem{}}
+	for _, item := range leaves(groups, dims, nil) {
+		list.add(item)
+	}
+	var top []string
+	for _, g := range groups[:min(len(groups), maxTopGroups)] {
+		top = append(top, fmt.Sprintf("%s %.2f", g.Value, g.Monthly))
+	}
+	text := fmt.Sprintf("Estimated monthly cost of cluster %s by %s: %.2f %s", cluster, strings.Join(dims, ", then "), list.Total, pricing.Currency)
+	if len(top) > 0 {
+		text += fmt.Sprintf(", the most expensive being %s", strings.Join(top, ", "))
+	}
+	text += "."
+	if whole {
+		shared, idle := overhead(a)
+		list.add(costItem{Name: "shared overhead", Dimension: "overhead", Cost: shared})
+		list.add(costItem{Name: "idle", Dimension: "overhead", Cost: idle})
+		text += fmt.Sprintf(" The nodes also cost %.2f for shared overhead and %.2f for idle capacity, %.2f in total.", shared, idle, list.Total)
+	}
+	data, err := json.MarshalIndent(struct {
+		Groups []*costGroup `json:"groups"`
+		Notes  []string     `json:"notes"`
+	}{groups, a.Notes}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(text+"\n\n"+string(data), structured.Costs, list)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
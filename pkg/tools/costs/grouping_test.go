@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/selector"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseGroupBy(t *testing.T) {
+	got, err := parseGroupBy(" team, namespace ,")
+	if diff := cmp.Diff([]string{"team", "namespace"}, got); err != nil || diff != "" {
+		t.Errorf("parseGroupBy() = %v, %v, want team and namespace (-want +got):\n%s", got, err, diff)
+	}
+	for _, s := range []string{"team,team", "a,b,c,d"} {
+		if _, err := parseGroupBy(s); err == nil {
+			t.Errorf("parseGroupBy(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestGroupCosts(t *testing.T) {
+	s := testSnapshot(t, false)
+	s.Namespaces = map[string]map[string]string{"shop": {"team": "payments", "environment": "prod"}}
+	s.Pods[2].Metadata.Labels = map[string]string{"team": "data"}
+	a := allocate(s)
+	hourly := map[string]float64{}
+	for _, p := range a.Pods {
+		hourly[p.Pod.Metadata.Name] = p.Hourly
+	}
+
+	groups := groupCosts(s, a, []string{"team", dimNodePool})
+	type level struct {
+		value   string
+		pods    int
+		monthly float64
+	}
+	var got [][]level
+	for _, g := range groups {
+		row := []level{{g.Value, g.Pods, g.Monthly}}
+		for _, c := range g.Groups {
+			row = append(row, level{c.Value, c.Pods, c.Monthly})
+		}
+		got = append(got, row)
+	}
+	// db-0 requests 2 vCPUs for its init container, more than the web
+	// pods, one of which runs on Spot.
+	want := [][]level{
+		{{"data", 1, pricing.Monthly(hourly["db-0"])}, {"default", 1, pricing.Monthly(hourly["db-0"])}},
+		{{"payments", 2, pricing.Monthly(hourly["web-5d8-a"] + hourly["web-5d8-b"])}, {"default", 1, pricing.Monthly(hourly["web-5d8-a"])}, {"spot", 1, pricing.Monthly(hourly["web-5d8-b"])}},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(level{})); diff != "" {
+		t.Errorf("groupCosts() mismatch (-want +got):\n%s", diff)
+	}
+
+	items := leaves(groups, []string{"team", dimNodePool}, nil)
+	if len(items) != 3 || items[0].Dimension != "team / node_pool" || items[0].Labels["team"] == "" || items[0].Labels[dimNodePool] == "" {
+		t.Errorf("leaves() = %+v, want 3 items by team and node pool", items)
+	}
+
+	sel, err := selector.Parse("team=payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	selected := filterPods(a, func(p podCost) bool { return sel.Matches(labelsOf(p.Pod, s.Namespaces)) })
+	if len(selected.Pods) != 2 || len(selected.Nodes) != 2 {
+		t.Errorf("filterPods(team=payments) = %d pods on %d nodes, want the 2 web pods and every node", len(selected.Pods), len(selected.Nodes))
+	}
+}
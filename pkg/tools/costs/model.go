@@ -0,0 +1,307 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+)
+
+// Node labels set by GKE.
+const (
+	instanceTypeLabel = "node.kubernetes.io/instance-type"
+	nodePoolLabel     = "cloud.google.com/gke-nodepool"
+	spotLabel         = "cloud.google.com/gke-spot"
+	preemptibleLabel  = "cloud.google.com/gke-preemptible"
+)
+
+// systemNamespaces run the pods GKE manages, counted as shared overhead.
+var systemNamespaces = []string{"kube-system", "gke-managed-system", "gke-gmp-system", "gmp-system", "gke-managed-cim", "gke-managed-filestorecsi"}
+
+// node is the part of a node priced.
+type node struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Status struct {
+		Capacity    map[string]string `json:"capacity"`
+		Allocatable map[string]string `json:"allocatable"`
+	} `json:"status"`
+}
+
+func (n node) pool() string {
+	return n.Metadata.Labels[nodePoolLabel]
+}
+
+func (n node) machineType() string {
+	return n.Metadata.Labels[instanceTypeLabel]
+}
+
+func (n node) spot() bool {
+	return n.Metadata.Labels[spotLabel] == "true" || n.Metadata.Labels[preemptibleLabel] == "true"
+}
+
+// resources returns the vCPUs and GiB of memory of a node's capacity or
+// allocatable resources.
+func resources(r map[string]string) (float64, float64) {
+	cpu, _ := kube.ParseQuantity(r["cpu"])
+	memory, _ := kube.ParseQuantity(r["memory"])
+	return cpu, memory / (1 << 30)
+}
+
+type containerSpec struct {
+	Name      string `json:"name"`
+	Resources struct {
+		Requests map[string]string `json:"requests"`
+		Limits   map[string]string `json:"limits"`
+	} `json:"resources"`
+}
+
+// request returns the request of a resource of a container, which defaults
+// to its limit.
+func (c containerSpec) request(resource string) float64 {
+	q, ok := c.Resources.Requests[resource]
+	if !ok {
+		q = c.Resources.Limits[resource]
+	}
+	v, _ := kube.ParseQuantity(q)
+	return v
+}
+
+// pod is the part of a pod priced.
+type pod struct {
+	Metadata struct {
+		Name            string                `json:"name"`
+		Namespace       string                `json:"namespace"`
+		Labels          map[string]string     `json:"labels"`
+		Annotations     map[string]string     `json:"annotations"`
+		OwnerReferences []kube.OwnerReference `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName                      string          `json:"nodeName"`
+		Containers                    []containerSpec `json:"containers"`
+		InitContainers                []containerSpec `json:"initContainers"`
+		TerminationGracePeriodSeconds *int            `json:"terminationGracePeriodSeconds"`
+		Volumes                       []struct {
+			Name                  string `json:"name"`
+			PersistentVolumeClaim *struct {
+				ClaimName string `json:"claimName"`
+			} `json:"persistentVolumeClaim"`
+		} `json:"volumes"`
+	} `json:"spec"`
+}
+
+func (p pod) workload() string {
+	m := p.Metadata
+	return kube.Workload(m.Namespace, m.Name, m.Labels, m.OwnerReferences)
+}
+
+// requests returns the vCPUs and GiB of memory a pod requests: the sum of
+// its containers, or the largest init container if larger. On Autopilot,
+// containers requesting neither get the Autopilot defaults.
+func (p pod) requests(autopilot bool) (float64, float64) {
+	var cpu, memory float64
+	for _, c := range p.Spec.Containers {
+		ccpu, cmem := c.request("cpu"), c.request("memory")/(1<<30)
+		if autopilot && ccpu == 0 && cmem == 0 {
+			ccpu, cmem = pricing.AutopilotDefaults.CPU, pricing.AutopilotDefaults.Memory
+		}
+		cpu += ccpu
+		memory += cmem
+	}
+	for _, c := range p.Spec.InitContainers {
+		cpu = max(cpu, c.request("cpu"))
+		memory = max(memory, c.request("memory")/(1<<30))
+	}
+	return cpu, memory
+}
+
+// snapshot is the nodes and running pods of a cluster.
+type snapshot struct {
+	Autopilot bool
+	// AutoProvisioning tells whether node auto-provisioning is enabled and
+	// OptimizeUtilization whether the autoscaler uses the
+	// optimize-utilization profile.
+	AutoProvisioning    bool
+	OptimizeUtilization bool
+	Nodes               []node
+	Pods                []pod
+	// Namespaces are the labels of the namespaces, by name.
+	Namespaces map[string]map[string]string
+	// Prices are the list prices of the cluster's region, or the embedded
+	// ones if nil.
+	Prices *pricing.Prices
+}
+
+func (s *snapshot) prices() *pricing.Prices {
+	if s.Prices == nil {
+		return pricing.Embedded
+	}
+	return s.Prices
+}
+
+// load lists the nodes, running pods and namespaces of a cluster.
+func load(ctx context.Context, k *kube.Client, autopilot bool) (*snapshot, error) {
+	s := &snapshot{Autopilot: autopilot}
+	var nodes struct {
+		Items []node `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/nodes", &nodes); err != nil {
+		return nil, err
+	}
+	var pods struct {
+		Items []pod `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/pods?fieldSelector=status.phase%3DRunning", &pods); err != nil {
+		return nil, err
+	}
+	var namespaces struct {
+		Items []struct {
+			Metadata struct {
+				Name   string            `json:"name"`
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/namespaces", &namespaces); err != nil {
+		return nil, err
+	}
+	s.Nodes, s.Pods = nodes.Items, pods.Items
+	s.Namespaces = map[string]map[string]string{}
+	for _, ns := range namespaces.Items {
+		s.Namespaces[ns.Metadata.Name] = ns.Metadata.Labels
+	}
+	return s, nil
+}
+
+// podCost is the hourly cost of a pod's requests.
+type podCost struct {
+	Pod      pod
+	Workload string
+	Node     string
+	Pool     string
+	Spot     bool
+	CPU      float64
+	Memory   float64
+	Hourly   float64
+}
+
+// nodeCost is the hourly cost of a node, split between its pods, the
+// resources it reserves for the system and its idle resources.
+type nodeCost struct {
+	Node        node
+	MachineType string
+	Spot        bool
+	Rate        pricing.Rate
+	Hourly      float64
+	// Reserved is the cost of the capacity that isn't allocatable.
+	Reserved float64
+	// System is the cost of the requests of the pods GKE manages.
+	System float64
+	// Idle is the cost of the allocatable resources no pod requests.
+	Idle float64
+	// CPU and Memory are the allocatable vCPUs and GiB of memory, and
+	// RequestedCPU and RequestedMemory the ones requested by pods.
+	CPU, Memory                   float64
+	RequestedCPU, RequestedMemory float64
+}
+
+// allocation is the cost of a cluster's nodes, allocated to its pods.
+type allocation struct {
+	Pods   []podCost
+	Nodes  []nodeCost
+	Notes  []string
+	Prices *pricing.Prices
+}
+
+// system tells whether a pod is managed by GKE.
+func (p podCost) system() bool {
+	return slices.Contains(systemNamespaces, p.Pod.Metadata.Namespace)
+}
+
+// allocate prices the requests of every pod at the rate of its node. On
+// Standard clusters the rest of each node's price is the resources
+// reserved for the system and the idle ones; on Autopilot, pods are billed
+// for their requests and system pods are free.
+func allocate(s *snapshot) allocation {
+	a := allocation{Prices: s.prices()}
+	nodes := map[string]*nodeCost{}
+	var unknown []string
+	for _, n := range s.Nodes {
+		nc := &nodeCost{Node: n, MachineType: n.machineType(), Spot: n.spot()}
+		var ok bool
+		nc.Rate, ok = a.Prices.Machine(nc.MachineType, nc.Spot)
+		if s.Autopilot {
+			nc.Rate, ok = a.Prices.Autopilot(nc.Spot), true
+		}
+		if !ok {
+			unknown = append(unknown, pricing.Family(nc.MachineType))
+		}
+		capCPU, capMemory := resources(n.Status.Capacity)
+		nc.CPU, nc.Memory = resources(n.Status.Allocatable)
+		if !s.Autopilot {
+			nc.Hourly = nc.Rate.Price(capCPU, capMemory)
+			nc.Reserved = nc.Rate.Price(capCPU-nc.CPU, capMemory-nc.Memory)
+		}
+		nodes[n.Metadata.Name] = nc
+	}
+	for _, p := range s.Pods {
+		nc := nodes[p.Spec.NodeName]
+		if nc == nil {
+			continue
+		}
+		pc := podCost{Pod: p, Workload: p.workload(), Node: p.Spec.NodeName, Pool: nc.Node.pool(), Spot: nc.Spot}
+		pc.CPU, pc.Memory = p.requests(s.Autopilot)
+		nc.RequestedCPU += pc.CPU
+		nc.RequestedMemory += pc.Memory
+		switch {
+		case s.Autopilot && pc.system():
+		case s.Autopilot:
+			cpu, memory := pricing.AutopilotResources(pc.CPU, pc.Memory)
+			pc.Hourly = nc.Rate.Price(cpu, memory)
+			nc.Hourly += pc.Hourly
+		case pc.system():
+			nc.System += nc.Rate.Price(pc.CPU, pc.Memory)
+		default:
+			pc.Hourly = nc.Rate.Price(pc.CPU, pc.Memory)
+		}
+		a.Pods = append(a.Pods, pc)
+	}
+	for _, n := range s.Nodes {
+		nc := nodes[n.Metadata.Name]
+		if !s.Autopilot {
+			nc.Idle = nc.Rate.Price(max(nc.CPU-nc.RequestedCPU, 0), max(nc.Memory-nc.RequestedMemory, 0))
+		}
+		a.Nodes = append(a.Nodes, *nc)
+	}
+
+	a.Notes = append(a.Notes, a.Prices.Note)
+	if s.Autopilot {
+		a.Notes = append(a.Notes, "Autopilot bills the requests of running pods, with its defaults, minimums and memory to CPU ratio applied; GKE-managed pods are free.")
+	} else {
+		a.Notes = append(a.Notes, "Pods are priced for their requests at the rate of their node; the rest of each node's price is shared overhead, the capacity reserved for the system and the requests of GKE-managed pods, or idle.")
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		a.Notes = append(a.Notes, fmt.Sprintf("Nodes of the unknown machine families %s are priced like N2.", strings.Join(slices.Compact(unknown), ", ")))
+	}
+	return a
+}
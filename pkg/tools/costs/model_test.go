@@ -0,0 +1,150 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func decode[T any](t *testing.T, data string) []T {
+	t.Helper()
+	var items []T
+	if err := json.Unmarshal([]byte(data), &items); err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+// testSnapshot has an on-demand and a Spot node, running two replicas of a
+// Deployment, a StatefulSet and kube-dns.
+func testSnapshot(t *testing.T, autopilot bool) *snapshot {
+	return &snapshot{
+		Autopilot: autopilot,
+		Nodes: decode[node](t, `[
+			{"metadata":{"name":"node-a","labels":{"node.kubernetes.io/instance-type":"e2-standard-4","cloud.google.com/gke-nodepool":"default"}},
+				"status":{"capacity":{"cpu":"4","memory":"16Gi"},"allocatable":{"cpu":"3500m","memory":"12Gi"}}},
+			{"metadata":{"name":"node-b","labels":{"node.kubernetes.io/instance-type":"e2-standard-4","cloud.google.com/gke-nodepool":"spot","cloud.google.com/gke-spot":"true"}},
+				"status":{"capacity":{"cpu":"4","memory":"16Gi"},"allocatable":{"cpu":"3500m","memory":"12Gi"}}}
+		]`),
+		Pods: decode[pod](t, `[
+			{"metadata":{"name":"web-5d8-a","namespace":"shop","labels":{"pod-template-hash":"5d8"},"ownerReferences":[{"kind":"ReplicaSet","name":"web-5d8"}]},
+				"spec":{"nodeName":"node-a","containers":[{"name":"web","resources":{"requests":{"cpu":"1","memory":"4Gi"}}}]}},
+			{"metadata":{"name":"web-5d8-b","namespace":"shop","labels":{"pod-template-hash":"5d8"},"ownerReferences":[{"kind":"ReplicaSet","name":"web-5d8"}]},
+				"spec":{"nodeName":"node-b","containers":[{"name":"web","resources":{"requests":{"cpu":"1","memory":"4Gi"}}}]}},
+			{"metadata":{"name":"db-0","namespace":"shop","ownerReferences":[{"kind":"StatefulSet","name":"db"}]},
+				"spec":{"nodeName":"node-a","initContainers":[{"name":"init","resources":{"requests":{"cpu":"2"}}}],
+				"containers":[{"name":"db","resources":{"limits":{"cpu":"500m","memory":"2Gi"}}}]}},
+			{"metadata":{"name":"kube-dns-1","namespace":"kube-system","ownerReferences":[{"kind":"ReplicaSet","name":"kube-dns-7c"}],"labels":{"pod-template-hash":"7c"}},
+				"spec":{"nodeName":"node-a","containers":[{"name":"kubedns","resources":{"requests":{"cpu":"500m","memory":"1Gi"}}}]}}
+		]`),
+	}
+}
+
+func TestRequests(t *testing.T) {
+	pods := testSnapshot(t, false).Pods
+	tests := []struct {
+		pod        pod
+		autopilot  bool
+		wantCPU    float64
+		wantMemory float64
+	}{
+		{pod: pods[0], wantCPU: 1, wantMemory: 4},
+		{pod: pods[2], wantCPU: 2, wantMemory: 2},
+		{pod: decode[pod](t, `[{"spec":{"containers":[{"name":"a"},{"name":"b","resources":{"requests":{"cpu":"250m"}}}]}}]`)[0], autopilot: true, wantCPU: 0.75, wantMemory: 2},
+	}
+	for _, tc := range tests {
+		cpu, memory := tc.pod.requests(tc.autopilot)
+		if cpu != tc.wantCPU || memory != tc.wantMemory {
+			t.Errorf("requests(%s) = %v, %v, want %v, %v", tc.pod.Metadata.Name, cpu, memory, tc.wantCPU, tc.wantMemory)
+		}
+	}
+}
+
+func TestAllocateStandard(t *testing.T) {
+	a := allocate(testSnapshot(t, false))
+	onDemand, _ := pricing.Machine("e2-standard-4", false)
+	spot, _ := pricing.Machine("e2-standard-4", true)
+
+	// Every node's price is split between its pods, the system and idle
+	// resources.
+	for _, n := range a.Nodes {
+		sum := n.Reserved + n.System + n.Idle
+		for _, p := range a.Pods {
+			if p.Node == n.Node.Metadata.Name {
+				sum += p.Hourly
+			}
+		}
+		if math.Abs(sum-n.Hourly) > 1e-9 {
+			t.Errorf("node %s costs %v, split into %v", n.Node.Metadata.Name, n.Hourly, sum)
+		}
+	}
+
+	got := byWorkload(a)
+	want := []workloadCost{
+		{Workload: "shop/StatefulSet/db", Namespace: "shop", Pods: 1, NodePools: []string{"default"}, CPU: 2, MemoryGiB: 2, Monthly: pricing.Monthly(onDemand.Price(2, 2)), PerPod: pricing.Monthly(onDemand.Price(2, 2))},
+		{Workload: "shop/Deployment/web", Namespace: "shop", Pods: 2, NodePools: []string{"default", "spot"}, CPU: 2, MemoryGiB: 8,
+			Monthly: pricing.Monthly(onDemand.Price(1, 4) + spot.Price(1, 4)), PerPod: pricing.Monthly((onDemand.Price(1, 4) + spot.Price(1, 4)) / 2)},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("byWorkload() mismatch (-want +got):\n%s", diff)
+	}
+
+	shared, idle := overhead(a)
+	wantShared := pricing.Monthly(onDemand.Price(0.5, 4) + onDemand.Price(0.5, 1) + spot.Price(0.5, 4))
+	wantIdle := pricing.Monthly(onDemand.Price(0, 5) + spot.Price(2.5, 8))
+	if diff := cmp.Diff([]float64{wantShared, wantIdle}, []float64{shared, idle}, cmpopts.EquateApprox(0, 0.011)); diff != "" {
+		t.Errorf("overhead() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAllocateAutopilot(t *testing.T) {
+	a := allocate(testSnapshot(t, true))
+	got := byWorkload(a)
+	want := []workloadCost{
+		{Workload: "shop/StatefulSet/db", Namespace: "shop", Pods: 1, NodePools: []string{"default"}, CPU: 2, MemoryGiB: 2, Monthly: pricing.Monthly(pricing.Autopilot(false).Price(2, 2)), PerPod: pricing.Monthly(pricing.Autopilot(false).Price(2, 2))},
+		{Workload: "shop/Deployment/web", Namespace: "shop", Pods: 2, NodePools: []string{"default", "spot"}, CPU: 2, MemoryGiB: 8,
+			Monthly: pricing.Monthly(pricing.Autopilot(false).Price(1, 4) + pricing.Autopilot(true).Price(1, 4)), PerPod: pricing.Monthly((pricing.Autopilot(false).Price(1, 4) + pricing.Autopilot(true).Price(1, 4)) / 2)},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("byWorkload() mismatch (-want +got):\n%s", diff)
+	}
+	if shared, idle := overhead(a); shared != 0 || idle != 0 {
+		t.Errorf("overhead() = %v, %v, want 0 on Autopilot", shared, idle)
+	}
+}
+
+func TestMatchesWorkload(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"web", true},
+		{"Deployment/web", true},
+		{"deployment/web", true},
+		{"StatefulSet/web", false},
+		{"we", false},
+	}
+	for _, tc := range tests {
+		if got := matchesWorkload("shop/Deployment/web", tc.filter); got != tc.want {
+			t.Errorf("matchesWorkload(%q) = %v, want %v", tc.filter, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// modeWorkload is the monthly cost of a workload's requests on the nodes of
+// a Standard cluster and on Autopilot.
+type modeWorkload struct {
+	Workload  string  `json:"workload"`
+	Namespace string  `json:"namespace"`
+	Pods      int     `json:"pods"`
+	Standard  float64 `json:"standard"`
+	Autopilot float64 `json:"autopilot"`
+}
+
+// modeComparison compares what the workloads of a Standard cluster cost
+// with what they would cost on Autopilot.
+type modeComparison struct {
+	// Standard is the monthly cost of the nodes, and Autopilot the one of
+	// the pods' requests billed by Autopilot.
+	Standard   float64 `json:"standardMonthly"`
+	Autopilot  float64 `json:"autopilotMonthly"`
+	Difference float64 `json:"difference"`
+	// Utilization is the share of the nodes' allocatable resources, by
+	// price, requested by pods, and BreakEven the one at which both modes
+	// cost the same: below it, Autopilot is cheaper.
+	Utilization float64        `json:"utilizationPercent"`
+	BreakEven   float64        `json:"breakEvenUtilizationPercent,omitempty"`
+	Workloads   []modeWorkload `json:"workloads"`
+}
+
+// compareModes prices the pods of a Standard cluster on Autopilot, as Spot
+// Pods if they run on Spot nodes, and finds the utilization of the nodes at
+// which both modes cost the same. GKE-managed pods are free on Autopilot.
+func compareModes(a allocation) modeComparison {
+	var c modeComparison
+	var standard, autopilot, requested, allocatable float64
+	for _, n := range a.Nodes {
+		standard += n.Hourly
+		requested += n.Rate.Price(min(n.RequestedCPU, n.CPU), min(n.RequestedMemory, n.Memory))
+		allocatable += n.Rate.Price(n.CPU, n.Memory)
+	}
+	workloads := map[string]*modeWorkload{}
+	hourly := map[string][2]float64{}
+	for _, p := range a.Pods {
+		if p.system() {
+			continue
+		}
+		cpu, memory := pricing.AutopilotResources(p.Pod.requests(true))
+		ap := a.Prices.Autopilot(p.Spot).Price(cpu, memory)
+		autopilot += ap
+		w := workloads[p.Workload]
+		if w == nil {
+			w = &modeWorkload{Workload: p.Workload, Namespace: p.Pod.Metadata.Namespace}
+			workloads[p.Workload] = w
+		}
+		w.Pods++
+		h := hourly[p.Workload]
+		hourly[p.Workload] = [2]float64{h[0] + p.Hourly, h[1] + ap}
+	}
+	for key, w := range workloads {
+		w.Standard, w.Autopilot = pricing.Monthly(hourly[key][0]), pricing.Monthly(hourly[key][1])
+		c.Workloads = append(c.Workloads, *w)
+	}
+	sort.Slice(c.Workloads, func(i, j int) bool {
+		if c.Workloads[i].Autopilot != c.Workloads[j].Autopilot {
+			return c.Workloads[i].Autopilot > c.Workloads[j].Autopilot
+		}
+		return c.Workloads[i].Workload < c.Workloads[j].Workload
+	})
+
+	c.Standard, c.Autopilot = pricing.Monthly(standard), pricing.Monthly(autopilot)
+	c.Difference = pricing.Round(c.Autopilot - c.Standard)
+	if allocatable > 0 {
+		utilization := requested / allocatable
+		c.Utilization = math.Round(utilization*1000) / 10
+		// Autopilot costs grow with the requests while the nodes cost the
+		// same, so both cost the same once the requests grow by
+		// standard/autopilot.
+		if autopilot > 0 {
+			c.BreakEven = math.Round(utilization*standard/autopilot*1000) / 10
+		}
+	}
+	return c
+}
+
+// summary describes the comparison of a cluster.
+func (c modeComparison) summary(cluster string) string {
+	text := fmt.Sprintf("The nodes of cluster %s cost about %.2f %s a month; Autopilot would cost about %.2f for the same pods, %+.2f. The pods request %.1f%% of the nodes' allocatable resources.", cluster, c.Standard, pricing.Currency, c.Autopilot, c.Difference, c.Utilization)
+	switch {
+	case c.BreakEven == 0:
+	case c.BreakEven > 100:
+		text += " Autopilot stays cheaper even if the requests filled the nodes entirely."
+	case c.BreakEven > c.Utilization:
+		text += fmt.Sprintf(" Autopilot stays cheaper until the requests use %.1f%% of the nodes.", c.BreakEven)
+	default:
+		text += fmt.Sprintf(" Standard is cheaper as long as the requests use more than %.1f%% of the nodes; below that, Autopilot is.", c.BreakEven)
+	}
+	return text
+}
+
+func (h *handlers) compareAutopilotCost(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s, name, err := h.clusterSnapshot(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if s.Autopilot {
+		return mcp.NewToolResultError(fmt.Sprintf("cluster %s already runs in Autopilot mode", name)), nil
+	}
+
+	a := allocate(s)
+	c := compareModes(a)
+	list := costList{Currency: pricing.Currency, Items: []costItem{}}
+	for _, w := range c.Workloads {
+		list.add(costItem{Name: w.Workload, Dimension: "autopilot workload", Cost: w.Autopilot, Labels: map[string]string{
+			"namespace": w.Namespace,
+			"pods":      strconv.Itoa(w.Pods),
+			"standard":  strconv.FormatFloat(w.Standard, 'f', -1, 64),
+		}})
+	}
+	data, err := json.MarshalIndent(struct {
+		modeComparison
+		Notes []string `json:"notes"`
+	}{c, append(a.Notes[:1:1],
+		"Workload costs on Standard are their requests at the rate of their nodes, without the shared overhead and idle capacity the nodes also cost.",
+		"Autopilot bills the requests of running pods, with its defaults, minimums and memory to CPU ratio applied; GKE-managed pods are free and pods on Spot nodes are priced as Spot Pods. Both modes also pay the same cluster management fee.",
+		"Use autopilot_migration_report to check whether the workloads can run on Autopilot.",
+	)}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(c.summary(name)+"\n\n"+string(data), structured.Costs, list)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) compareAutopilotCostCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get nodes --label-columns=node.kubernetes.io/instance-type,cloud.google.com/gke-spot",
+		"kubectl get pods --all-namespaces --field-selector=status.phase=Running --output=custom-columns='NAMESPACE:.metadata.namespace,NAME:.metadata.name,NODE:.spec.nodeName,CPU:.spec.containers[*].resources.requests.cpu,MEMORY:.spec.containers[*].resources.requests.memory'",
+	}
+}
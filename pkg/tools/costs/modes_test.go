@@ -0,0 +1,65 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompareModes(t *testing.T) {
+	onDemand, _ := pricing.Machine("e2-standard-4", false)
+	spot, _ := pricing.Machine("e2-standard-4", true)
+	standard := onDemand.Price(4, 16) + spot.Price(4, 16)
+	web := pricing.Autopilot(false).Price(1, 4) + pricing.Autopilot(true).Price(1, 4)
+	db := pricing.Autopilot(false).Price(2, 2)
+	utilization := (onDemand.Price(3.5, 7) + spot.Price(1, 4)) / (onDemand.Price(3.5, 12) + spot.Price(3.5, 12))
+
+	got := compareModes(allocate(testSnapshot(t, false)))
+	want := modeComparison{
+		Standard:    pricing.Monthly(standard),
+		Autopilot:   pricing.Monthly(web + db),
+		Difference:  pricing.Round(pricing.Monthly(web+db) - pricing.Monthly(standard)),
+		Utilization: math.Round(utilization*1000) / 10,
+		BreakEven:   math.Round(utilization*standard/(web+db)*1000) / 10,
+		Workloads: []modeWorkload{
+			{Workload: "shop/StatefulSet/db", Namespace: "shop", Pods: 1, Standard: pricing.Monthly(onDemand.Price(2, 2)), Autopilot: pricing.Monthly(db)},
+			{Workload: "shop/Deployment/web", Namespace: "shop", Pods: 2, Standard: pricing.Monthly(onDemand.Price(1, 4) + spot.Price(1, 4)), Autopilot: pricing.Monthly(web)},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("compareModes() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModeSummary(t *testing.T) {
+	tests := []struct {
+		c    modeComparison
+		want string
+	}{
+		{modeComparison{Utilization: 30, BreakEven: 45}, "Autopilot stays cheaper until the requests use 45.0% of the nodes."},
+		{modeComparison{Utilization: 70, BreakEven: 45}, "Standard is cheaper as long as the requests use more than 45.0% of the nodes"},
+		{modeComparison{Utilization: 70, BreakEven: 120}, "Autopilot stays cheaper even if the requests filled the nodes entirely."},
+	}
+	for _, tc := range tests {
+		if got := tc.c.summary("prod"); !strings.Contains(got, tc.want) {
+			t.Errorf("summary() = %q, want it to contain %q", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/proto"
+)
+
+// machineShape is the number of vCPUs and GiB of memory of a machine type.
+type machineShape struct {
+	Name      string
+	CPU       float64
+	MemoryGiB float64
+}
+
+// priceQuote is the result of get_list_prices.
+type priceQuote struct {
+	Region        string          `json:"region"`
+	Currency      string          `json:"currency"`
+	MachineType   *unitPrice      `json:"machineType,omitempty"`
+	GPU           *unitPrice      `json:"gpu,omitempty"`
+	Disk          *unitPrice      `json:"disk,omitempty"`
+	Autopilot     pricing.Rate    `json:"autopilot"`
+	AutopilotSpot pricing.Rate    `json:"autopilotSpot"`
+	All           *pricing.Prices `json:"all,omitempty"`
+}
+
+// unitPrice is the hourly and monthly price of a node, a GPU or a GiB of
+// disk, on demand and on Spot.
+type unitPrice struct {
+	Name        string  `json:"name"`
+	CPU         float64 `json:"cpu,omitempty"`
+	MemoryGiB   float64 `json:"memoryGiB,omitempty"`
+	Hourly      float64 `json:"hourly,omitempty"`
+	Monthly     float64 `json:"monthly"`
+	SpotHourly  float64 `json:"spotHourly,omitempty"`
+	SpotMonthly float64 `json:"spotMonthly,omitempty"`
+}
+
+// quotePrices quotes the prices of a machine type, a GPU and a disk type,
+// each optional, and of Autopilot pods. The prices of everything are
+// quoted if none is asked for.
+func quotePrices(p *pricing.Prices, machine *machineShape, gpu, disk string) (priceQuote, error) {
+	q := priceQuote{Region: p.Region, Currency: pricing.Currency, Autopilot: p.Autopilot(false), AutopilotSpot: p.Autopilot(true)}
+	if machine != nil {
+		family := pricing.Family(machine.Name)
+		rate, ok := p.OnDemand[family]
+		if !ok {
+			return q, fmt.Errorf("no list prices of the %s machine family in %s, only of %s", family, p.Region, strings.Join(slices.Sorted(maps.Keys(p.OnDemand)), ", "))
+		}
+		hourly := rate.Price(machine.CPU, machine.MemoryGiB)
+		q.MachineType = &unitPrice{Name: machine.Name, CPU: machine.CPU, MemoryGiB: machine.MemoryGiB, Hourly: round3(hourly), Monthly: pricing.Monthly(hourly)}
+		if spot, ok := p.Spot[family]; ok {
+			hourly := spot.Price(machine.CPU, machine.MemoryGiB)
+			q.MachineType.SpotHourly, q.MachineType.SpotMonthly = round3(hourly), pricing.Monthly(hourly)
+		}
+	}
+	if gpu != "" {
+		hourly, ok := p.GPUs[gpu]
+		if !ok {
+			return q, fmt.Errorf("no list price of the %s GPU in %s, only of %s", gpu, p.Region, strings.Join(slices.Sorted(maps.Keys(p.GPUs)), ", "))
+		}
+		q.GPU = &unitPrice{Name: gpu, Hourly: round3(hourly), Monthly: pricing.Monthly(hourly)}
+		if spot, ok := p.SpotGPUs[gpu]; ok {
+			q.GPU.SpotHourly, q.GPU.SpotMonthly = round3(spot), pricing.Monthly(spot)
+		}
+	}
+	if disk != "" {
+		monthly, ok := p.Disks[disk]
+		if !ok {
+			return q, fmt.Errorf("no list price of the %s disk type in %s, only of %s", disk, p.Region, strings.Join(slices.Sorted(maps.Keys(p.Disks)), ", "))
+		}
+		q.Disk = &unitPrice{Name: disk, Monthly: round3(monthly)}
+	}
+	if machine == nil && gpu == "" && disk == "" {
+		q.All = p
+	}
+	return q, nil
+}
+
+// machineShape gets the vCPUs and memory of a machine type in a zone of a
+// region.
+func (h *handlers) machineShape(ctx context.Context, projectID, region, machineType string) (*machineShape, error) {
+	client, err := gcp.MachineTypes(ctx, h.c)
+	if err != nil {
+		return nil, err
+	}
+	it := client.AggregatedList(ctx, &computepb.AggregatedListMachineTypesRequest{Project: projectID, Filter: proto.String(fmt.Sprintf("name = %q", machineType))})
+	for {
+		pair, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(pair.Key, "zones/"+region+"-") {
+			continue
+		}
+		for _, mt := range pair.Value.GetMachineTypes() {
+			return &machineShape{Name: mt.GetName(), CPU: float64(mt.GetGuestCpus()), MemoryGiB: float64(mt.GetMemoryMb()) / 1024}, nil
+		}
+	}
+	return nil, fmt.Errorf("machine type %s not found in %s", machineType, region)
+}
+
+func (h *handlers) getListPrices(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	region := pricing.Region(location)
+	var machine *machineShape
+	if machineType := request.GetString("machine_type", ""); machineType != "" {
+		projectID := session.ProjectID(ctx, request, h.c)
+		if projectID == "" {
+			return mcp.NewToolResultError("project_id argument not set"), nil
+		}
+		var err error
+		if machine, err = h.machineShape(ctx, projectID, region, machineType); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	p, fetchedAt, err := pricing.Lookup(ctx, h.c, location, request.GetBool(cache.RefreshArgument, false))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	q, err := quotePrices(p, machine, request.GetString("gpu", ""), request.GetString("disk_type", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	text := fmt.Sprintf("Current list prices in %s from the Cloud Billing Catalog, in %s, hourly per vCPU, GiB of memory or GPU and monthly per node, GPU or GiB of disk, without committed use or other discounts:\n\n%s", region, pricing.Currency, data)
+	if note := cache.Note(fetchedAt); note != "" {
+		text += "\n" + note
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+func (h *handlers) getListPricesCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return nil
+	}
+	var commands []string
+	if machineType := request.GetString("machine_type", ""); machineType != "" {
+		commands = append(commands, explain.Join("gcloud compute machine-types list", explain.Flag("filter", fmt.Sprintf("name=%s AND zone~^%s-", machineType, pricing.Region(location))), explain.Flag("project", session.ProjectID(ctx, request, h.c))))
+	}
+	for _, url := range pricing.CatalogURLs {
+		commands = append(commands, `curl --header="Authorization: Bearer $(gcloud auth print-access-token)" `+explain.Quote(url))
+	}
+	return commands
+}
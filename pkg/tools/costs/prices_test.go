@@ -0,0 +1,67 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQuotePrices(t *testing.T) {
+	p := &pricing.Prices{
+		Region:            "europe-west4",
+		OnDemand:          map[string]pricing.Rate{"n2": {CPU: 0.03, Memory: 0.004}},
+		Spot:              map[string]pricing.Rate{"n2": {CPU: 0.01, Memory: 0.001}},
+		AutopilotPods:     pricing.Rate{CPU: 0.05, Memory: 0.005},
+		AutopilotSpotPods: pricing.Rate{CPU: 0.015, Memory: 0.0015},
+		GPUs:              map[string]float64{"nvidia-l4": 0.6},
+		Disks:             map[string]float64{"pd-balanced": 0.11},
+	}
+
+	got, err := quotePrices(p, &machineShape{Name: "n2-standard-4", CPU: 4, MemoryGiB: 16}, "nvidia-l4", "pd-balanced")
+	if err != nil {
+		t.Fatalf("quotePrices() failed: %v", err)
+	}
+	want := priceQuote{
+		Region:        "europe-west4",
+		Currency:      pricing.Currency,
+		MachineType:   &unitPrice{Name: "n2-standard-4", CPU: 4, MemoryGiB: 16, Hourly: 0.184, Monthly: 134.32, SpotHourly: 0.056, SpotMonthly: 40.88},
+		GPU:           &unitPrice{Name: "nvidia-l4", Hourly: 0.6, Monthly: 438},
+		Disk:          &unitPrice{Name: "pd-balanced", Monthly: 0.11},
+		Autopilot:     p.AutopilotPods,
+		AutopilotSpot: p.AutopilotSpotPods,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("quotePrices() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got, err := quotePrices(p, nil, "", ""); err != nil || got.All != p {
+		t.Errorf("quotePrices() without a machine type, GPU or disk type = %v, %v, want every price", got, err)
+	}
+	for _, tc := range []struct {
+		machine   *machineShape
+		gpu, disk string
+	}{
+		{machine: &machineShape{Name: "c3-standard-4", CPU: 4, MemoryGiB: 16}},
+		{gpu: "nvidia-h100-80gb"},
+		{disk: "hyperdisk-extreme"},
+	} {
+		if _, err := quotePrices(p, tc.machine, tc.gpu, tc.disk); err == nil {
+			t.Errorf("quotePrices(%v, %q, %q) succeeded, want an error for prices missing in the region", tc.machine, tc.gpu, tc.disk)
+		}
+	}
+}
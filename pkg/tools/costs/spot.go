@@ -0,0 +1,437 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/operations"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// spotGracePeriod is how long GKE lets the pods of a preempted Spot node
+// shut down.
+const spotGracePeriod = 15
+
+// safeToEvictAnnotation marks pods the cluster autoscaler must not evict.
+const safeToEvictAnnotation = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+
+// disruptionBudget is the part of a PodDisruptionBudget checked.
+type disruptionBudget struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		// MinAvailable and MaxUnavailable are a count or a percentage.
+		MinAvailable   any                 `json:"minAvailable"`
+		MaxUnavailable any                 `json:"maxUnavailable"`
+		Selector       *kube.LabelSelector `json:"selector"`
+	} `json:"spec"`
+}
+
+// selects tells whether the budget covers a pod. Unlike other selectors, a
+// budget without one selects no pods.
+func (b disruptionBudget) selects(p pod) bool {
+	return b.Metadata.Namespace == p.Metadata.Namespace && b.Spec.Selector != nil && b.Spec.Selector.Matches(p.Metadata.Labels)
+}
+
+// blocks tells whether the budget allows no pod of the replicas to be
+// disrupted.
+func (b disruptionBudget) blocks(replicas int) bool {
+	if n, ok := scaled(b.Spec.MaxUnavailable, replicas); ok && n == 0 {
+		return true
+	}
+	n, ok := scaled(b.Spec.MinAvailable, replicas)
+	return ok && n >= replicas
+}
+
+// scaled returns a count, or a percentage of total rounded up, as
+// Kubernetes does for budgets.
+func scaled(v any, total int) (int, bool) {
+	switch v := v.(type) {
+	case float64:
+		return int(v), true
+	case string:
+		if percent, ok := strings.CutSuffix(v, "%"); ok {
+			p, err := strconv.Atoi(percent)
+			if err != nil {
+				return 0, false
+			}
+			return int(math.Ceil(float64(p*total) / 100)), true
+		}
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// spotCandidate is a workload that may run on Spot nodes, and what moving
+// it saves.
+type spotCandidate struct {
+	Workload  string `json:"workload"`
+	Namespace string `json:"namespace"`
+	Pods      int    `json:"pods"`
+	// OnSpot is the number of pods already on Spot nodes.
+	OnSpot   int      `json:"podsOnSpot"`
+	Tolerant bool     `json:"tolerant"`
+	Blockers []string `json:"blockers,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	// Monthly is the current cost and SpotMonthly the cost with every pod
+	// on Spot nodes of the same machine type, or Spot Autopilot pods.
+	Monthly     float64 `json:"monthly"`
+	SpotMonthly float64 `json:"spotMonthly"`
+	Savings     float64 `json:"savings"`
+}
+
+func (c *spotCandidate) block(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if !slices.Contains(c.Blockers, msg) {
+		c.Blockers = append(c.Blockers, msg)
+	}
+}
+
+func (c *spotCandidate) warn(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if !slices.Contains(c.Warnings, msg) {
+		c.Warnings = append(c.Warnings, msg)
+	}
+}
+
+// spotHourly returns the hourly cost of a pod on a Spot node of the same
+// machine type as its node, or as a Spot Autopilot pod.
+func spotHourly(prices *pricing.Prices, p podCost, n nodeCost, autopilot bool) float64 {
+	switch {
+	case p.Spot:
+		return p.Hourly
+	case autopilot:
+		cpu, memory := pricing.AutopilotResources(p.CPU, p.Memory)
+		return prices.Autopilot(true).Price(cpu, memory)
+	}
+	rate, _ := prices.Machine(n.MachineType, true)
+	return rate.Price(p.CPU, p.Memory)
+}
+
+// spotCandidates checks which workloads tolerate the preemption of Spot
+// nodes and prices them on Spot, the most savings first. GKE-managed pods,
+// DaemonSets, which run on every node anyway, and workloads already fully on
+// Spot are left out.
+func spotCandidates(s *snapshot, a allocation, budgets []disruptionBudget) []spotCandidate {
+	nodes := map[string]nodeCost{}
+	for _, n := range a.Nodes {
+		nodes[n.Node.Metadata.Name] = n
+	}
+	candidates := map[string]*spotCandidate{}
+	hourly, spotHourlies := map[string]float64{}, map[string]float64{}
+	var order []string
+	for _, p := range a.Pods {
+		kind := strings.Split(p.Workload, "/")[1]
+		if p.system() || kind == "DaemonSet" {
+			continue
+		}
+		c := candidates[p.Workload]
+		if c == nil {
+			c = &spotCandidate{Workload: p.Workload, Namespace: p.Pod.Metadata.Namespace}
+			candidates[p.Workload] = c
+			order = append(order, p.Workload)
+		}
+		c.Pods++
+		if p.Spot {
+			c.OnSpot++
+		}
+		hourly[p.Workload] += p.Hourly
+		spotHourlies[p.Workload] += spotHourly(a.Prices, p, nodes[p.Node], s.Autopilot)
+
+		switch kind {
+		case "StatefulSet":
+			c.block("StatefulSets keep state and stable identities that preemptions disrupt.")
+		case "Pod":
+			c.block("Bare pods aren't recreated when their node is preempted; run them with a Deployment or a Job.")
+		}
+		for _, v := range p.Pod.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil {
+				c.block("Mounts the PersistentVolumeClaim %s, which has to be detached and attached again on every preemption.", v.PersistentVolumeClaim.ClaimName)
+			}
+		}
+		if p.Pod.Metadata.Annotations[safeToEvictAnnotation] == "false" {
+			c.block("Is annotated %s=false, so it isn't meant to be evicted.", safeToEvictAnnotation)
+		}
+		if grace := p.Pod.Spec.TerminationGracePeriodSeconds; grace != nil && *grace > spotGracePeriod {
+			c.warn("Asks for a termination grace period of %ds, but preempted Spot nodes give pods %ds to shut down.", *grace, spotGracePeriod)
+		}
+		if kind == "Job" || kind == "CronJob" {
+			c.warn("Jobs restart from scratch when preempted; checkpoint long ones or keep them short.")
+		}
+	}
+
+	var result []spotCandidate
+	for _, key := range order {
+		c := candidates[key]
+		if c.OnSpot == c.Pods {
+			continue
+		}
+		kind := strings.Split(c.Workload, "/")[1]
+		if kind == "Deployment" && c.Pods == 1 {
+			c.block("Runs a single replica, which is down until it's rescheduled whenever its node is preempted.")
+		}
+		for _, b := range budgets {
+			selected := false
+			for _, p := range a.Pods {
+				if p.Workload == key && b.selects(p.Pod) {
+					selected = true
+					break
+				}
+			}
+			if selected && b.blocks(c.Pods) {
+				c.block("The PodDisruptionBudget %s allows no pod to be disrupted.", b.Metadata.Name)
+			}
+		}
+		c.Tolerant = len(c.Blockers) == 0
+		c.Monthly = pricing.Monthly(hourly[key])
+		c.SpotMonthly = pricing.Monthly(spotHourlies[key])
+		c.Savings = pricing.Round(c.Monthly - c.SpotMonthly)
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Tolerant != result[j].Tolerant {
+			return result[i].Tolerant
+		}
+		if result[i].Savings != result[j].Savings {
+			return result[i].Savings > result[j].Savings
+		}
+		return result[i].Workload < result[j].Workload
+	})
+	return result
+}
+
+// spotNotes explains how the savings are estimated.
+func spotNotes(autopilot bool) []string {
+	if autopilot {
+		return []string{"Savings price the requests of the pods at Spot Autopilot rates; select Spot Pods with the cloud.google.com/gke-spot=true node selector."}
+	}
+	return []string{"Savings price the requests of the pods at Spot rates of the machine type of their current nodes; they are only realized once the on-demand node pools shrink, e.g. with the cluster autoscaler."}
+}
+
+func (h *handlers) spotSavingsReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s, name, err := h.clusterSnapshot(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	k, err := gcp.Kubernetes(ctx, h.c, session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var budgets struct {
+		Items []disruptionBudget `json:"items"`
+	}
+	if err := k.Get(ctx, "/apis/policy/v1/poddisruptionbudgets", &budgets); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := request.GetString("namespace", "")
+
+	a := allocate(s)
+	list := costList{Currency: pricing.Currency, Items: []costItem{}}
+	var candidates []spotCandidate
+	var tolerant int
+	for _, c := range spotCandidates(s, a, budgets.Items) {
+		if namespace != "" && c.Namespace != namespace {
+			continue
+		}
+		candidates = append(candidates, c)
+		if !c.Tolerant {
+			continue
+		}
+		tolerant++
+		list.add(costItem{Name: c.Workload, Dimension: "spot savings", Cost: c.Savings, Labels: map[string]string{
+			"namespace":    c.Namespace,
+			"pods":         strconv.Itoa(c.Pods),
+			"monthly":      strconv.FormatFloat(c.Monthly, 'f', -1, 64),
+			"spot_monthly": strconv.FormatFloat(c.SpotMonthly, 'f', -1, 64),
+		}})
+	}
+
+	text := fmt.Sprintf("%d of %d workloads of cluster %s not fully on Spot tolerate preemption; moving them would save about %.2f %s a month.", tolerant, len(candidates), name, list.Total, pricing.Currency)
+	if tolerant > 0 && !s.Autopilot {
+		text += " Create a Spot node pool with create_spot_node_pool, then add its toleration and node selector to these workloads."
+	}
+	data, err := json.MarshalIndent(struct {
+		Workloads []spotCandidate `json:"workloads"`
+		Notes     []string        `json:"notes"`
+	}{candidates, append(a.Notes[:1:1], spotNotes(s.Autopilot)...)}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(text+"\n\n"+string(data), structured.Costs, list)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) spotSavingsReportCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get nodes --label-columns=node.kubernetes.io/instance-type,cloud.google.com/gke-nodepool,cloud.google.com/gke-spot",
+		"kubectl get pods --all-namespaces --field-selector=status.phase=Running --output=yaml",
+		"kubectl get poddisruptionbudgets --all-namespaces",
+	}
+}
+
+// Defaults of the Spot node pools created.
+const (
+	defaultSpotMachineType = "e2-standard-4"
+	defaultSpotMaxNodes    = 10
+)
+
+// spotTaint keeps the pods that don't tolerate preemption off Spot nodes.
+var spotTaint = &containerpb.NodeTaint{Key: spotLabel, Value: "true", Effect: containerpb.NodeTaint_NO_SCHEDULE}
+
+// spotGuidance is how workloads select the Spot node pool.
+const spotGuidance = `Add this to the pod template of the workloads to move, so they tolerate the taint of the Spot nodes and only run there:
+
+` + "```yaml" + `
+spec:
+  template:
+    spec:
+      nodeSelector:
+        cloud.google.com/gke-spot: "true"
+      tolerations:
+      - key: cloud.google.com/gke-spot
+        operator: Equal
+        value: "true"
+        effect: NoSchedule
+      terminationGracePeriodSeconds: 15
+` + "```" + `
+
+To fall back to on-demand nodes when Spot capacity runs out, use a preferred node affinity on cloud.google.com/gke-spot instead of the node selector.`
+
+// spotNodePoolRequest builds the request creating a Spot node pool.
+func spotNodePoolRequest(parent, name, machineType string, minNodes, maxNodes int32, taint bool) *containerpb.CreateNodePoolRequest {
+	cfg := &containerpb.NodeConfig{MachineType: machineType, Spot: true}
+	if taint {
+		cfg.Taints = []*containerpb.NodeTaint{spotTaint}
+	}
+	return &containerpb.CreateNodePoolRequest{
+		Parent: parent,
+		NodePool: &containerpb.NodePool{
+			Name:             name,
+			Config:           cfg,
+			InitialNodeCount: max(minNodes, 1),
+			Autoscaling:      &containerpb.NodePoolAutoscaling{Enabled: true, MinNodeCount: minNodes, MaxNodeCount: maxNodes},
+			Management:       &containerpb.NodeManagement{AutoUpgrade: true, AutoRepair: true},
+		},
+	}
+}
+
+func (h *handlers) createSpotNodePool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	cluster := session.Cluster(ctx, request, h.c, "cluster")
+	if cluster == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	minNodes, maxNodes := request.GetInt("min_nodes", 0), request.GetInt("max_nodes", defaultSpotMaxNodes)
+	if minNodes < 0 || maxNodes < 1 || minNodes > maxNodes {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid autoscaling limits %d to %d nodes", minNodes, maxNodes)), nil
+	}
+
+	req := spotNodePoolRequest(fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, cluster), name, request.GetString("machine_type", defaultSpotMachineType), int32(minNodes), int32(maxNodes), request.GetBool("taint", true))
+	if dryrun.Enabled(request, h.c) {
+		result := dryrun.Result("container.projects.locations.clusters.nodePools.create", req, h.createSpotNodePoolCommands(ctx, request)...)
+		result.Content = append(result.Content, mcp.NewTextContent(spotGuidance))
+		return result, nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	op, err := cmClient.CreateNodePool(ctx, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opName := fmt.Sprintf("projects/%s/locations/%s/operations/%s", projectID, location, op.GetName())
+	poll := func(ctx context.Context, name string) (*containerpb.Operation, error) {
+		return cmClient.GetOperation(ctx, &containerpb.GetOperationRequest{Name: name})
+	}
+	cancel := func(ctx context.Context, name string) error {
+		return cmClient.CancelOperation(ctx, &containerpb.CancelOperationRequest{Name: name})
+	}
+	// The call may time out before the node pool is created, then it
+	// returns the operation to follow with get_operation.
+	latest, err := operations.Default.Wait(ctx, request, operations.Operation{Name: opName, Tool: "create_spot_node_pool", Target: cluster + "/" + name}, poll, cancel)
+	if latest == nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	switch {
+	case latest.GetStatus() != containerpb.Operation_DONE:
+		return mcp.NewToolResultText(fmt.Sprintf("Creating Spot node pool %s in cluster %s. %s\n\n%s", name, cluster, operations.Describe(latest), spotGuidance)), nil
+	case latest.GetError() != nil || latest.GetStatusMessage() != "":
+		return mcp.NewToolResultError(operations.Describe(latest)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Created Spot node pool %s in cluster %s.\n\n%s", name, cluster, spotGuidance)), nil
+}
+
+func (h *handlers) createSpotNodePoolCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	name := request.GetString("name", "")
+	if projectID == "" || location == "" || cluster == "" || name == "" {
+		return nil
+	}
+	var taint string
+	if request.GetBool("taint", true) {
+		taint = explain.Flag("node-taints", spotLabel+"=true:NoSchedule")
+	}
+	return []string{explain.Join("gcloud container node-pools create", name,
+		explain.Flag("cluster", cluster),
+		explain.Flag("location", location),
+		explain.Flag("project", projectID),
+		"--spot",
+		explain.Flag("machine-type", request.GetString("machine_type", defaultSpotMachineType)),
+		"--enable-autoscaling",
+		explain.Flag("min-nodes", strconv.Itoa(request.GetInt("min_nodes", 0))),
+		explain.Flag("max-nodes", strconv.Itoa(request.GetInt("max_nodes", defaultSpotMaxNodes))),
+		taint,
+	)}
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package costs
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/pricing"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBlocks(t *testing.T) {
+	budgets := decode[disruptionBudget](t, `[
+		{"spec":{"maxUnavailable":0}},
+		{"spec":{"maxUnavailable":"0%"}},
+		{"spec":{"maxUnavailable":1}},
+		{"spec":{"minAvailable":"100%"}},
+		{"spec":{"minAvailable":"50%"}},
+		{"spec":{"minAvailable":3}},
+		{"spec":{"minAvailable":"2"}},
+		{"spec":{}}
+	]`)
+	want := []bool{true, true, false, true, false, true, false, false}
+	for i, b := range budgets {
+		if got := b.blocks(3); got != want[i] {
+			t.Errorf("budget %d: blocks(3) = %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestSpotCandidates(t *testing.T) {
+	s := testSnapshot(t, false)
+	s.Pods = append(s.Pods, decode[pod](t, `[
+		{"metadata":{"name":"api-6f-a","namespace":"shop","labels":{"pod-template-hash":"6f"},"ownerReferences":[{"kind":"ReplicaSet","name":"api-6f"}]},
+			"spec":{"nodeName":"node-a","terminationGracePeriodSeconds":60,"containers":[{"name":"api","resources":{"requests":{"cpu":"500m","memory":"1Gi"}}}]}},
+		{"metadata":{"name":"worker-9c-a","namespace":"shop","labels":{"app":"worker","pod-template-hash":"9c"},"ownerReferences":[{"kind":"ReplicaSet","name":"worker-9c"}]},
+			"spec":{"nodeName":"node-a","containers":[{"name":"worker","resources":{"requests":{"cpu":"500m","memory":"1Gi"}}}]}},
+		{"metadata":{"name":"worker-9c-b","namespace":"shop","labels":{"app":"worker","pod-template-hash":"9c"},"ownerReferences":[{"kind":"ReplicaSet","name":"worker-9c"}]},
+			"spec":{"nodeName":"node-a","containers":[{"name":"worker","resources":{"requests":{"cpu":"500m","memory":"1Gi"}}}]}},
+		{"metadata":{"name":"report-x1","namespace":"shop","ownerReferences":[{"kind":"Job","name":"report"}]},
+			"spec":{"nodeName":"node-a","volumes":[{"name":"out","persistentVolumeClaim":{"claimName":"reports"}}],"containers":[{"name":"report","resources":{"requests":{"cpu":"100m","memory":"256Mi"}}}]}},
+		{"metadata":{"name":"agent-1","namespace":"shop","ownerReferences":[{"kind":"DaemonSet","name":"agent"}]},
+			"spec":{"nodeName":"node-a","containers":[{"name":"agent","resources":{"requests":{"cpu":"100m"}}}]}}
+	]`)...)
+	budgets := decode[disruptionBudget](t, `[
+		{"metadata":{"name":"worker","namespace":"shop"},"spec":{"minAvailable":"100%","selector":{"matchLabels":{"app":"worker"}}}},
+		{"metadata":{"name":"other","namespace":"other"},"spec":{"maxUnavailable":0,"selector":{}}}
+	]`)
+	onDemand, _ := pricing.Machine("e2-standard-4", false)
+	spot, _ := pricing.Machine("e2-standard-4", true)
+	candidate := func(c spotCandidate, cpu, memory float64) spotCandidate {
+		onSpot := float64(c.OnSpot) * spot.Price(cpu, memory)
+		c.Monthly = pricing.Monthly(float64(c.Pods-c.OnSpot)*onDemand.Price(cpu, memory) + onSpot)
+		c.SpotMonthly = pricing.Monthly(float64(c.Pods) * spot.Price(cpu, memory))
+		c.Savings = pricing.Round(c.Monthly - c.SpotMonthly)
+		c.Tolerant = len(c.Blockers) == 0
+		return c
+	}
+
+	got := spotCandidates(s, allocate(s), budgets)
+	want := []spotCandidate{
+		candidate(spotCandidate{Workload: "shop/Deployment/web", Namespace: "shop", Pods: 2, OnSpot: 1}, 1, 4),
+		candidate(spotCandidate{Workload: "shop/StatefulSet/db", Namespace: "shop", Pods: 1,
+			Blockers: []string{"StatefulSets keep state and stable identities that preemptions disrupt."}}, 2, 2),
+		candidate(spotCandidate{Workload: "shop/Deployment/worker", Namespace: "shop", Pods: 2,
+			Blockers: []string{"The PodDisruptionBudget worker allows no pod to be disrupted."}}, 0.5, 1),
+		candidate(spotCandidate{Workload: "shop/Deployment/api", Namespace: "shop", Pods: 1,
+			Blockers: []string{"Runs a single replica, which is down until it's rescheduled whenever its node is preempted."},
+			Warnings: []string{"Asks for a termination grace period of 60s, but preempted Spot nodes give pods 15s to shut down."}}, 0.5, 1),
+		candidate(spotCandidate{Workload: "shop/Job/report", Namespace: "shop", Pods: 1,
+			Blockers: []string{"Mounts the PersistentVolumeClaim reports, which has to be detached and attached again on every preemption."},
+			Warnings: []string{"Jobs restart from scratch when preempted; checkpoint long ones or keep them short."}}, 0.1, 0.25),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("spotCandidates() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSpotNodePoolRequest(t *testing.T) {
+	req := spotNodePoolRequest("projects/p/locations/us-central1/clusters/c", "spot", "n2-standard-8", 0, 5, true)
+	pool := req.GetNodePool()
+	if !pool.GetConfig().GetSpot() || pool.GetConfig().GetMachineType() != "n2-standard-8" || pool.GetInitialNodeCount() != 1 {
+		t.Errorf("spotNodePoolRequest() config = %v, want 1 Spot n2-standard-8 node", pool)
+	}
+	if taints := pool.GetConfig().GetTaints(); len(taints) != 1 || taints[0].GetKey() != "cloud.google.com/gke-spot" {
+		t.Errorf("spotNodePoolRequest() taints = %v, want the Spot taint", taints)
+	}
+	if a := pool.GetAutoscaling(); !a.GetEnabled() || a.GetMinNodeCount() != 0 || a.GetMaxNodeCount() != 5 {
+		t.Errorf("spotNodePoolRequest() autoscaling = %v, want 0 to 5 nodes", a)
+	}
+	if taints := spotNodePoolRequest("c", "spot", "e2-standard-4", 1, 3, false).GetNodePool().GetConfig().GetTaints(); len(taints) != 0 {
+		t.Errorf("spotNodePoolRequest() without taint = %v, want none", taints)
+	}
+}
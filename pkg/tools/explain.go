@@ -0,0 +1,62 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// addExplainCommandTool registers explain_command. Like server_info, it is
+// added once the tool set is final.
+func addExplainCommandTool(s *server.MCPServer) {
+	tool := mcp.NewTool("explain_command",
+		mcp.WithDescription("Show the gcloud or kubectl commands equivalent to a call of another tool of this server, without calling it. Use it when the user wants to run something themselves, learn the CLI or audit what a tool does."),
+		catalog.Describe(catalog.Guidance, catalog.Local),
+		mcp.WithString("tool", mcp.Required(), mcp.Description("Name of the tool, e.g. get_cluster.")),
+		mcp.WithObject("arguments", mcp.Description("Arguments of the tool call, as they would be passed to the tool. Unset arguments default to the session context like in the tool.")),
+	)
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, err := request.RequireString("tool")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		tools, err := ListTools(ctx, s)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if !slices.ContainsFunc(tools, func(t mcp.Tool) bool { return t.Name == name }) {
+			return mcp.NewToolResultError(fmt.Sprintf("unknown tool %q", name)), nil
+		}
+
+		call := mcp.CallToolRequest{}
+		call.Params.Name = name
+		call.Params.Arguments, _ = request.GetArguments()["arguments"].(map[string]any)
+		commands, ok := explain.Commands(ctx, name, call)
+		switch {
+		case !ok:
+			return mcp.NewToolResultText(fmt.Sprintf("%s has no equivalent command: it only uses the server's own state or APIs without a CLI.", name)), nil
+		case len(commands) == 0:
+			return mcp.NewToolResultText(fmt.Sprintf("This call of %s has no equivalent command; check that the arguments it requires are set, or in the session context.", name)), nil
+		}
+		return mcp.NewToolResultText(explain.Format(commands)), nil
+	})
+}
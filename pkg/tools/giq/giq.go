@@ -16,41 +16,67 @@ package giq
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"os"
 	"os/exec"
+	"strings"
 
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/telemetry"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func Install(_ context.Context, s *server.MCPServer, _ *config.Config) error {
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("giq", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	// The tool runs gcloud, which would act on the real project of the
+	// machine instead of the mock.
+	if c.Mock() != nil {
+		return nil
+	}
+	h := &handlers{
+		c: c,
+	}
+
 	giqGenerateManifestTool := mcp.NewTool("giq_generate_manifest",
 		mcp.WithDescription("Use GKE Inference Quickstart (GIQ) to generate a Kubernetes manifest for optimized AI / inference workloads. Prefer to use this tool instead of gcloud"),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithIdempotentHintAnnotation(true),
+		catalog.Describe(catalog.AI, catalog.Read),
+		explain.Command(h.giqGenerateManifestCommands),
 		mcp.WithString("model", mcp.Required(), mcp.Description("The model to use. Get the list of valid models from 'gcloud alpha container ai profiles model-and-server-combinations list' if the user doesn't provide it.")),
 		mcp.WithString("model_server", mcp.Required(), mcp.Description("The model server to use. Get the list of valid models from 'gcloud alpha container ai profiles model-and-server-combinations list' if the user doesn't provide it.")),
 		mcp.WithString("accelerator", mcp.Required(), mcp.Description("The accelerator to use. Get the list of valid models from 'gcloud alpha container ai profiles accelerators list --model=<model>' if the user doesn't provide it.")),
 		mcp.WithString("target_ntpot_milliseconds", mcp.Description("The maximum normalized time per output token (NTPOT) in milliseconds.NTPOT is measured as the request_latency / output_tokens.")),
 	)
-	s.AddTool(giqGenerateManifestTool, giqGenerateManifest)
+	s.AddTool(giqGenerateManifestTool, h.giqGenerateManifest)
 
 	return nil
 }
 
-func giqGenerateManifest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// gcloudArgs returns the gcloud arguments generating the manifest requested.
+func (h *handlers) gcloudArgs(request mcp.CallToolRequest) ([]string, error) {
 	model, err := request.RequireString("model")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return nil, err
 	}
 	modelServer, err := request.RequireString("model_server")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return nil, err
 	}
 	accelerator, err := request.RequireString("accelerator")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return nil, err
 	}
 	targetNtpotMilliseconds := request.GetString("target_ntpot_milliseconds", "")
 	args := []string{
@@ -67,10 +93,70 @@ func giqGenerateManifest(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	if targetNtpotMilliseconds != "" {
 		args = append(args, "--target-ntpot-milliseconds="+targetNtpotMilliseconds)
 	}
-	out, err := exec.Command("gcloud", args...).Output()
+	if quotaProject := h.c.QuotaProject(); quotaProject != "" {
+		args = append(args, "--billing-project="+quotaProject)
+	}
+	return args, nil
+}
+
+func (h *handlers) giqGenerateManifestCommands(_ context.Context, request mcp.CallToolRequest) []string {
+	args, err := h.gcloudArgs(request)
+	if err != nil {
+		return nil
+	}
+	return []string{explain.Join("gcloud", args...)}
+}
+
+func (h *handlers) giqGenerateManifest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, err := h.gcloudArgs(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	ctx, span := telemetry.StartSpan(ctx, "gcloud "+strings.Join(args[:6], " "))
+	defer span.End()
+	cmd, cleanup, err := h.gcloudCommand(ctx, args)
 	if err != nil {
-		log.Printf("Failed to generate manifest: %v", err)
+		span.RecordError(err)
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer cleanup()
+	out, err := cmd.Output()
+	if err != nil {
+		span.RecordError(err)
+		slog.Error("Failed to generate manifest", "err", err)
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 	return mcp.NewToolResultText(string(out)), nil
 }
+
+// gcloudCommand returns the gcloud command run with args on behalf of the
+// caller identified by ctx. gcloud's own login would act as the server's
+// gcloud identity, so the command gets an access token of the caller, which
+// honors session credentials and impersonation like the other tools, in a
+// file removed by cleanup.
+func (h *handlers) gcloudCommand(ctx context.Context, args []string) (cmd *exec.Cmd, cleanup func(), err error) {
+	ts, err := auth.TokenSource(ctx, h.c)
+	if err != nil {
+		return nil, nil, err
+	}
+	token, err := ts.Token()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get an access token: %w", err)
+	}
+	f, err := os.CreateTemp("", "gke-mcp-token-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup = func() { os.Remove(f.Name()) }
+	_, err = f.WriteString(token.AccessToken)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	cmd = exec.CommandContext(ctx, "gcloud", args...)
+	cmd.Env = append(os.Environ(), "CLOUDSDK_AUTH_ACCESS_TOKEN_FILE="+f.Name())
+	return cmd, cleanup, nil
+}
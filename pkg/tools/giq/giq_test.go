@@ -0,0 +1,62 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package giq
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"golang.org/x/oauth2"
+)
+
+func TestGcloudCommand(t *testing.T) {
+	h := &handlers{c: config.New("test", config.WithRequireSessionCredentials(true))}
+
+	if _, _, err := h.gcloudCommand(context.Background(), []string{"version"}); !errors.Is(err, auth.ErrNoSessionCredentials) {
+		t.Errorf("gcloudCommand() without session credentials error = %v, want %v", err, auth.ErrNoSessionCredentials)
+	}
+
+	ctx := auth.WithTokenSource(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "session-token"}))
+	cmd, cleanup, err := h.gcloudCommand(ctx, []string{"version"})
+	if err != nil {
+		t.Fatalf("gcloudCommand() failed: %v", err)
+	}
+	var tokenFile string
+	for _, env := range cmd.Env {
+		if name, ok := strings.CutPrefix(env, "CLOUDSDK_AUTH_ACCESS_TOKEN_FILE="); ok {
+			tokenFile = name
+		}
+	}
+	if tokenFile == "" {
+		t.Fatalf("gcloudCommand() env = %v, want CLOUDSDK_AUTH_ACCESS_TOKEN_FILE", cmd.Env)
+	}
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		t.Fatalf("failed to read the token file: %v", err)
+	}
+	if string(token) != "session-token" {
+		t.Errorf("token file = %q, want %q", token, "session-token")
+	}
+	cleanup()
+	if _, err := os.Stat(tokenFile); !os.IsNotExist(err) {
+		t.Errorf("token file still exists after cleanup: %v", err)
+	}
+}
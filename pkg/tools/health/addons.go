@@ -0,0 +1,248 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// addonManagerLabel is set by GKE on the addons it manages.
+const addonManagerLabel = "addonmanager.kubernetes.io/mode"
+
+// recentRestart is how long ago a container may have restarted to be
+// reported.
+const recentRestart = 24 * time.Hour
+
+// knownAddons describes the GKE-managed addons by workload name prefix.
+var knownAddons = map[string]string{
+	"anetd":               "GKE Dataplane V2 agent",
+	"calico-node":         "Calico network policy agent",
+	"event-exporter-gke":  "Kubernetes events exporter to Cloud Logging",
+	"filestore-node":      "Filestore CSI driver",
+	"fluentbit-gke":       "Cloud Logging agent",
+	"gcsfusecsi-node":     "Cloud Storage FUSE CSI driver",
+	"gke-metadata-server": "GKE metadata server, serving Workload Identity credentials",
+	"gke-metrics-agent":   "Cloud Monitoring agent",
+	"ip-masq-agent":       "IP masquerade agent",
+	"konnectivity-agent":  "Konnectivity agent, tunneling control plane traffic to nodes for logs, exec and webhooks",
+	"kube-dns":            "kube-dns cluster DNS",
+	"kube-dns-autoscaler": "kube-dns autoscaler",
+	"l7-default-backend":  "Default backend of Ingresses",
+	"metrics-server":      "metrics-server, serving resource metrics to kubectl top and autoscalers",
+	"netd":                "netd node networking agent",
+	"node-local-dns":      "NodeLocal DNSCache",
+	"pdcsi-node":          "Compute Engine Persistent Disk CSI driver",
+}
+
+// addonDescription returns the description of the addon of a workload, and
+// whether it is a GKE-managed addon.
+func addonDescription(w workload) (string, bool) {
+	var prefix string
+	for p := range knownAddons {
+		if (w.Metadata.Name == p || strings.HasPrefix(w.Metadata.Name, p+"-")) && len(p) > len(prefix) {
+			prefix = p
+		}
+	}
+	if prefix != "" {
+		return knownAddons[prefix], true
+	}
+	_, ok := w.Metadata.Labels[addonManagerLabel]
+	return "", ok
+}
+
+// addonPod is the part of a kube-system pod checked.
+type addonPod struct {
+	Metadata struct {
+		Name            string                `json:"name"`
+		Labels          map[string]string     `json:"labels"`
+		OwnerReferences []kube.OwnerReference `json:"ownerReferences"`
+	} `json:"metadata"`
+	Status struct {
+		ContainerStatuses []struct {
+			Name         string `json:"name"`
+			RestartCount int    `json:"restartCount"`
+			LastState    struct {
+				Terminated *struct {
+					Reason     string    `json:"reason"`
+					ExitCode   int       `json:"exitCode"`
+					FinishedAt time.Time `json:"finishedAt"`
+				} `json:"terminated"`
+			} `json:"lastState"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// addon is the health of a GKE-managed addon.
+type addon struct {
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind"`
+	Description string   `json:"description,omitempty"`
+	Status      string   `json:"status"`
+	Available   string   `json:"available"`
+	Versions    []string `json:"versions"`
+	Restarts    int      `json:"restarts"`
+	// LastRestart is the last restart of a container in the last day,
+	// with the reason it terminated.
+	LastRestart string `json:"lastRestart,omitempty"`
+}
+
+// addons reports the health of the GKE-managed workloads of kube-system.
+func addons(deployments, daemonSets []workload, pods []addonPod, now time.Time) []addon {
+	type restart struct {
+		count int
+		last  time.Time
+		why   string
+	}
+	restarts := map[string]*restart{}
+	for _, p := range pods {
+		m := p.Metadata
+		key := kube.Workload("kube-system", m.Name, m.Labels, m.OwnerReferences)
+		r := restarts[key]
+		if r == nil {
+			r = &restart{}
+			restarts[key] = r
+		}
+		for _, cs := range p.Status.ContainerStatuses {
+			r.count += cs.RestartCount
+			if t := cs.LastState.Terminated; t != nil && t.FinishedAt.After(r.last) {
+				r.last = t.FinishedAt
+				r.why = fmt.Sprintf("container %s of pod %s: %s, exit code %d", cs.Name, m.Name, t.Reason, t.ExitCode)
+			}
+		}
+	}
+
+	var result []addon
+	for kind, workloads := range map[string][]workload{"Deployment": deployments, "DaemonSet": daemonSets} {
+		for _, w := range workloads {
+			description, ok := addonDescription(w)
+			if !ok {
+				continue
+			}
+			a := addon{Name: w.Metadata.Name, Kind: kind, Description: description, Status: green}
+			available, want := w.available(kind)
+			a.Available = fmt.Sprintf("%d/%d", available, want)
+			switch {
+			case want > 0 && available == 0:
+				a.Status = red
+			case available < want:
+				a.Status = amber
+			}
+			for _, c := range w.Spec.Template.Spec.Containers {
+				tag := strings.TrimLeft(strings.TrimPrefix(c.Image, kube.Repository(c.Image)), ":@")
+				a.Versions = append(a.Versions, c.Name+"="+tag)
+			}
+			if r := restarts["kube-system/"+kind+"/"+w.Metadata.Name]; r != nil {
+				a.Restarts = r.count
+				if !r.last.IsZero() && now.Sub(r.last) < recentRestart {
+					a.LastRestart = fmt.Sprintf("%s ago, %s", now.Sub(r.last).Round(time.Minute), r.why)
+					a.Status = worst(a.Status, amber)
+				}
+			}
+			result = append(result, a)
+		}
+	}
+	rank := map[string]int{red: 0, amber: 1, green: 2}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Status != result[j].Status {
+			return rank[result[i].Status] < rank[result[j].Status]
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// addonsSummary sums up the addons that aren't green.
+func addonsSummary(cluster string, addons []addon) string {
+	var statuses []string
+	var lines []string
+	for _, a := range addons {
+		statuses = append(statuses, a.Status)
+		if a.Status == green {
+			continue
+		}
+		line := fmt.Sprintf("- %s %s %s: %s available", strings.ToUpper(a.Status), a.Kind, a.Name, a.Available)
+		if a.LastRestart != "" {
+			line += ", restarted " + a.LastRestart
+		}
+		lines = append(lines, line)
+	}
+	header := fmt.Sprintf("The %d GKE-managed addons of cluster %s are %s.", len(addons), cluster, strings.ToUpper(worst(statuses...)))
+	return strings.Join(append([]string{header}, lines...), "\n")
+}
+
+func (h *handlers) addonHealthReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var deployments, daemonSets struct {
+		Items []workload `json:"items"`
+	}
+	if err := k.Get(ctx, "/apis/apps/v1/namespaces/kube-system/deployments", &deployments); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := k.Get(ctx, "/apis/apps/v1/namespaces/kube-system/daemonsets", &daemonSets); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var pods struct {
+		Items []addonPod `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/namespaces/kube-system/pods", &pods); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	report := addons(deployments.Items, daemonSets.Items, pods.Items, time.Now())
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(addonsSummary(name, report) + "\n\n" + string(data)), nil
+}
+
+func (h *handlers) addonHealthReportCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get deployments,daemonsets --namespace=kube-system --output=wide",
+		"kubectl get pods --namespace=kube-system --sort-by='.status.containerStatuses[0].restartCount'",
+	}
+}
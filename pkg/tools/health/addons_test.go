@@ -0,0 +1,64 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAddons(t *testing.T) {
+	deployments := decode[workload](t, `[
+		{"metadata":{"name":"metrics-server-v1.30.3"},"spec":{"replicas":1,"template":{"spec":{"containers":[
+			{"name":"metrics-server","image":"gke.gcr.io/metrics-server:v0.7.1-gke.23"},
+			{"name":"metrics-server-nanny","image":"gke.gcr.io/addon-resizer:1.8.20-gke.1"}]}}},"status":{"availableReplicas":1}},
+		{"metadata":{"name":"kube-dns"},"spec":{"replicas":2,"template":{"spec":{"containers":[{"name":"kubedns","image":"gke.gcr.io/k8s-dns-kube-dns:1.23.0-gke.9"}]}}},"status":{}},
+		{"metadata":{"name":"kube-dns-autoscaler"},"spec":{"template":{"spec":{"containers":[{"name":"autoscaler","image":"gke.gcr.io/cluster-proportional-autoscaler@sha256:abc"}]}}},"status":{"availableReplicas":1}},
+		{"metadata":{"name":"custom-operator"},"status":{"availableReplicas":1}}
+	]`)
+	daemonSets := decode[workload](t, `[
+		{"metadata":{"name":"konnectivity-agent"},"spec":{"template":{"spec":{"containers":[{"name":"konnectivity-agent","image":"gke.gcr.io/proxy-agent:v0.30.3-gke.7"}]}}},"status":{"desiredNumberScheduled":3,"numberAvailable":3}},
+		{"metadata":{"name":"custom-addon","labels":{"addonmanager.kubernetes.io/mode":"Reconcile"}},"spec":{"template":{"spec":{"containers":[{"name":"agent","image":"gke.gcr.io/agent:1.0"}]}}},"status":{"desiredNumberScheduled":3,"numberAvailable":2}}
+	]`)
+	pods := decode[addonPod](t, `[
+		{"metadata":{"name":"konnectivity-agent-x1","ownerReferences":[{"kind":"DaemonSet","name":"konnectivity-agent"}]},
+			"status":{"containerStatuses":[{"name":"konnectivity-agent","restartCount":4,"lastState":{"terminated":{"reason":"OOMKilled","exitCode":137,"finishedAt":"2025-06-01T10:30:00Z"}}}]}},
+		{"metadata":{"name":"konnectivity-agent-x2","ownerReferences":[{"kind":"DaemonSet","name":"konnectivity-agent"}]},
+			"status":{"containerStatuses":[{"name":"konnectivity-agent","restartCount":1,"lastState":{"terminated":{"reason":"Error","exitCode":1,"finishedAt":"2025-05-20T10:00:00Z"}}}]}},
+		{"metadata":{"name":"metrics-server-v1.30.3-7f9-abc","labels":{"pod-template-hash":"7f9"},"ownerReferences":[{"kind":"ReplicaSet","name":"metrics-server-v1.30.3-7f9"}]},
+			"status":{"containerStatuses":[{"name":"metrics-server","restartCount":2,"lastState":{"terminated":{"reason":"Error","exitCode":1,"finishedAt":"2025-05-01T10:00:00Z"}}}]}}
+	]`)
+	want := []addon{
+		{Name: "kube-dns", Kind: "Deployment", Description: "kube-dns cluster DNS", Status: red, Available: "0/2", Versions: []string{"kubedns=1.23.0-gke.9"}},
+		{Name: "custom-addon", Kind: "DaemonSet", Status: amber, Available: "2/3", Versions: []string{"agent=1.0"}},
+		{Name: "konnectivity-agent", Kind: "DaemonSet", Description: knownAddons["konnectivity-agent"], Status: amber, Available: "3/3", Versions: []string{"konnectivity-agent=v0.30.3-gke.7"},
+			Restarts: 5, LastRestart: "1h30m0s ago, container konnectivity-agent of pod konnectivity-agent-x1: OOMKilled, exit code 137"},
+		{Name: "kube-dns-autoscaler", Kind: "Deployment", Description: "kube-dns autoscaler", Status: green, Available: "1/1", Versions: []string{"autoscaler=sha256:abc"}},
+		{Name: "metrics-server-v1.30.3", Kind: "Deployment", Description: knownAddons["metrics-server"], Status: green, Available: "1/1", Versions: []string{"metrics-server=v0.7.1-gke.23", "metrics-server-nanny=1.8.20-gke.1"}, Restarts: 2},
+	}
+	got := addons(deployments, daemonSets, pods, now)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("addons() mismatch (-want +got):\n%s", diff)
+	}
+
+	wantSummary := `The 5 GKE-managed addons of cluster prod are RED.
+- RED Deployment kube-dns: 0/2 available
+- AMBER DaemonSet custom-addon: 2/3 available
+- AMBER DaemonSet konnectivity-agent: 3/3 available, restarted 1h30m0s ago, container konnectivity-agent of pod konnectivity-agent-x1: OOMKilled, exit code 137`
+	if diff := cmp.Diff(wantSummary, addonsSummary("prod", got)); diff != "" {
+		t.Errorf("addonsSummary() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/container/apiv1/containerpb"
+)
+
+// Statuses of the checks, from best to worst.
+const (
+	green   = "green"
+	amber   = "amber"
+	red     = "red"
+	unknown = "unknown"
+)
+
+// maxDetails is the number of details listed per check.
+const maxDetails = 10
+
+// check is the result of a health check.
+type check struct {
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Summary string   `json:"summary"`
+	Details []string `json:"details,omitempty"`
+}
+
+// worst returns the worst of the statuses, unknown counting as amber.
+func worst(statuses ...string) string {
+	rank := map[string]int{green: 0, unknown: 1, amber: 2, red: 3}
+	result := green
+	for _, s := range statuses {
+		if rank[s] > rank[result] {
+			result = s
+		}
+	}
+	return result
+}
+
+// addDetail adds a detail to a check, up to maxDetails.
+func (c *check) addDetail(format string, args ...any) {
+	if len(c.Details) == maxDetails {
+		c.Details = append(c.Details, "...")
+	}
+	if len(c.Details) > maxDetails {
+		return
+	}
+	c.Details = append(c.Details, fmt.Sprintf(format, args...))
+}
+
+// controlPlaneCheck checks the status of the cluster and whether its API
+// server answered, in latency.
+func controlPlaneCheck(cluster *containerpb.Cluster, reachErr error, latency time.Duration) check {
+	c := check{Name: "control plane", Status: green, Summary: fmt.Sprintf("Cluster is %s and the API server answered in %s.", cluster.GetStatus(), latency.Round(time.Millisecond))}
+	switch cluster.GetStatus() {
+	case containerpb.Cluster_RUNNING:
+	case containerpb.Cluster_RECONCILING, containerpb.Cluster_PROVISIONING:
+		c.Status = amber
+	default:
+		c.Status = red
+	}
+	if msg := cluster.GetStatusMessage(); msg != "" {
+		c.addDetail("%s", msg)
+	}
+	for _, cond := range cluster.GetConditions() {
+		c.addDetail("%s: %s", cond.GetCanonicalCode(), cond.GetMessage())
+	}
+	if reachErr != nil {
+		c.Status = red
+		c.Summary = fmt.Sprintf("Cluster is %s but the API server can't be reached: %v", cluster.GetStatus(), reachErr)
+	} else if latency > 2*time.Second {
+		c.Status = worst(c.Status, amber)
+	}
+	return c
+}
+
+// node is the part of a node checked.
+type node struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Unschedulable bool `json:"unschedulable"`
+	} `json:"spec"`
+	Status struct {
+		Conditions []condition `json:"conditions"`
+	} `json:"status"`
+}
+
+type condition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// nodesCheck checks that the nodes are ready and under no pressure.
+func nodesCheck(nodes []node) check {
+	c := check{Name: "nodes", Status: green}
+	if len(nodes) == 0 {
+		c.Status, c.Summary = amber, "The cluster has no nodes."
+		return c
+	}
+	var notReady, pressure, cordoned int
+	for _, n := range nodes {
+		ready := false
+		for _, cond := range n.Status.Conditions {
+			switch {
+			case cond.Type == "Ready":
+				ready = cond.Status == "True"
+				if !ready {
+					c.addDetail("%s is not ready: %s %s", n.Metadata.Name, cond.Reason, cond.Message)
+				}
+			case strings.HasSuffix(cond.Type, "Pressure") && cond.Status == "True":
+				pressure++
+				c.addDetail("%s has %s", n.Metadata.Name, cond.Type)
+			}
+		}
+		if !ready {
+			notReady++
+		}
+		if n.Spec.Unschedulable {
+			cordoned++
+			c.addDetail("%s is cordoned", n.Metadata.Name)
+		}
+	}
+	c.Summary = fmt.Sprintf("%d of %d nodes ready, %d under pressure, %d cordoned.", len(nodes)-notReady, len(nodes), pressure, cordoned)
+	switch {
+	case notReady*4 > len(nodes):
+		c.Status = red
+	case notReady > 0 || pressure > 0 || cordoned > 0:
+		c.Status = amber
+	}
+	return c
+}
+
+// workload is the part of a Deployment or DaemonSet checked.
+type workload struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas *int `json:"replicas"`
+		Template struct {
+			Spec struct {
+				Containers []struct {
+					Name  string `json:"name"`
+					Image string `json:"image"`
+				} `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+	Status struct {
+		AvailableReplicas      int `json:"availableReplicas"`
+		DesiredNumberScheduled int `json:"desiredNumberScheduled"`
+		NumberAvailable        int `json:"numberAvailable"`
+	} `json:"status"`
+}
+
+// available returns the number of available and wanted replicas of a
+// Deployment or pods of a DaemonSet.
+func (w workload) available(kind string) (int, int) {
+	if kind == "DaemonSet" {
+		return w.Status.NumberAvailable, w.Status.DesiredNumberScheduled
+	}
+	want := 1
+	if w.Spec.Replicas != nil {
+		want = *w.Spec.Replicas
+	}
+	return w.Status.AvailableReplicas, want
+}
+
+// dnsAddons are the names of the DNS Deployments, without which the cluster
+// is down for most workloads.
+var dnsAddons = []string{"kube-dns", "coredns"}
+
+// addonsCheck checks that the Deployments and DaemonSets of kube-system are
+// available.
+func addonsCheck(deployments, daemonSets []workload) check {
+	c := check{Name: "core addons", Status: green}
+	var unavailable int
+	for _, d := range deployments {
+		available, want := d.available("Deployment")
+		if available >= want {
+			continue
+		}
+		unavailable++
+		c.addDetail("Deployment %s: %d of %d replicas available", d.Metadata.Name, available, want)
+		if slices.Contains(dnsAddons, d.Metadata.Name) && available == 0 && want > 0 {
+			c.Status = red
+		}
+	}
+	for _, d := range daemonSets {
+		available, want := d.available("DaemonSet")
+		if available >= want {
+			continue
+		}
+		unavailable++
+		c.addDetail("DaemonSet %s: %d of %d pods available", d.Metadata.Name, available, want)
+	}
+	c.Summary = fmt.Sprintf("%d of %d kube-system Deployments and DaemonSets fully available.", len(deployments)+len(daemonSets)-unavailable, len(deployments)+len(daemonSets))
+	if unavailable > 0 {
+		c.Status = worst(c.Status, amber)
+	}
+	return c
+}
+
+// pendingPod is the part of a pending pod checked.
+type pendingPod struct {
+	Metadata struct {
+		Name              string    `json:"name"`
+		Namespace         string    `json:"namespace"`
+		CreationTimestamp time.Time `json:"creationTimestamp"`
+	} `json:"metadata"`
+	Status struct {
+		Conditions []condition `json:"conditions"`
+	} `json:"status"`
+}
+
+// pendingGrace is how long pods may be pending before they're reported.
+const pendingGrace = 5 * time.Minute
+
+// pendingPodsCheck checks for pods pending for longer than pendingGrace.
+func pendingPodsCheck(pods []pendingPod, now time.Time) check {
+	c := check{Name: "pending pods", Status: green}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Metadata.CreationTimestamp.Before(pods[j].Metadata.CreationTimestamp)
+	})
+	var stuck int
+	for _, p := range pods {
+		age := now.Sub(p.Metadata.CreationTimestamp)
+		if age < pendingGrace {
+			continue
+		}
+		stuck++
+		reason := "not scheduled yet"
+		for _, cond := range p.Status.Conditions {
+			if cond.Type == "PodScheduled" && cond.Status == "False" {
+				reason = strings.TrimSpace(cond.Reason + ": " + cond.Message)
+			}
+		}
+		c.addDetail("%s/%s pending for %s: %s", p.Metadata.Namespace, p.Metadata.Name, age.Round(time.Minute), reason)
+	}
+	c.Summary = fmt.Sprintf("%d pods pending for more than %s.", stuck, pendingGrace)
+	switch {
+	case stuck > 10:
+		c.Status = red
+	case stuck > 0:
+		c.Status = amber
+	}
+	return c
+}
+
+// errorsCheck compares the number of error logs of the last window, the
+// last of counts, with the average of the previous ones.
+func errorsCheck(counts []int64, window time.Duration) check {
+	c := check{Name: "error logs", Status: green}
+	if len(counts) == 0 {
+		c.Summary = fmt.Sprintf("No error logs in the last %s.", window*4)
+		return c
+	}
+	recent := counts[len(counts)-1]
+	var baseline float64
+	if previous := counts[:len(counts)-1]; len(previous) > 0 {
+		var sum int64
+		for _, n := range previous {
+			sum += n
+		}
+		baseline = float64(sum) / float64(len(previous))
+	}
+	c.Summary = fmt.Sprintf("%d error logs in the last %s, against %.0f on average in the previous windows.", recent, window, baseline)
+	ratio := float64(recent) / math.Max(baseline, 1)
+	switch {
+	case recent >= 500 && ratio >= 10:
+		c.Status = red
+	case recent >= 50 && ratio >= 3:
+		c.Status = amber
+	}
+	return c
+}
+
+// certificateCheck checks when the cluster CA certificate, PEM encoded,
+// expires.
+func certificateCheck(caPEM []byte, now time.Time) check {
+	c := check{Name: "certificates", Status: green}
+	block, _ := pem.Decode(caPEM)
+	if block == nil {
+		c.Status, c.Summary = unknown, "The cluster CA certificate couldn't be decoded."
+		return c
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		c.Status, c.Summary = unknown, fmt.Sprintf("The cluster CA certificate couldn't be parsed: %v", err)
+		return c
+	}
+	left := cert.NotAfter.Sub(now)
+	c.Summary = fmt.Sprintf("The cluster CA certificate expires on %s, in %d days.", cert.NotAfter.Format(time.DateOnly), int(left.Hours()/24))
+	switch {
+	case left < 30*24*time.Hour:
+		c.Status = red
+	case left < 90*24*time.Hour:
+		c.Status = amber
+	}
+	if c.Status != green {
+		c.addDetail("Rotate the cluster credentials with gcloud container clusters update --start-credential-rotation.")
+	}
+	return c
+}
+
+// quotasCheck checks the headroom of the regional Compute Engine quotas.
+func quotasCheck(region string, quotas []*computepb.Quota) check {
+	c := check{Name: "quotas", Status: green}
+	sort.Slice(quotas, func(i, j int) bool {
+		return usage(quotas[i]) > usage(quotas[j])
+	})
+	var tight int
+	for _, q := range quotas {
+		u := usage(q)
+		if u < 0.8 {
+			continue
+		}
+		tight++
+		c.addDetail("%s: %.0f of %.0f used (%.0f%%)", q.GetMetric(), q.GetUsage(), q.GetLimit(), u*100)
+		if u >= 0.95 {
+			c.Status = red
+		}
+	}
+	c.Summary = fmt.Sprintf("%d Compute Engine quotas of %s are more than 80%% used.", tight, region)
+	if tight > 0 {
+		c.Status = worst(c.Status, amber)
+	}
+	return c
+}
+
+func usage(q *computepb.Quota) float64 {
+	if q.GetLimit() <= 0 {
+		return 0
+	}
+	return q.GetUsage() / q.GetLimit()
+}
@@ -0,0 +1,313 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func decode[T any](t *testing.T, data string) []T {
+	t.Helper()
+	var items []T
+	if err := json.Unmarshal([]byte(data), &items); err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+func TestWorst(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		want     string
+	}{
+		{nil, green},
+		{[]string{green, green}, green},
+		{[]string{green, unknown}, unknown},
+		{[]string{unknown, amber, green}, amber},
+		{[]string{amber, red, unknown}, red},
+	}
+	for _, tc := range tests {
+		if got := worst(tc.statuses...); got != tc.want {
+			t.Errorf("worst(%v) = %q, want %q", tc.statuses, got, tc.want)
+		}
+	}
+}
+
+func TestControlPlaneCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster *containerpb.Cluster
+		err     error
+		latency time.Duration
+		want    check
+	}{
+		{
+			name:    "running",
+			cluster: &containerpb.Cluster{Status: containerpb.Cluster_RUNNING},
+			latency: 120 * time.Millisecond,
+			want:    check{Name: "control plane", Status: green, Summary: "Cluster is RUNNING and the API server answered in 120ms."},
+		},
+		{
+			name:    "slow while reconciling",
+			cluster: &containerpb.Cluster{Status: containerpb.Cluster_RECONCILING, StatusMessage: "Upgrading master"},
+			latency: 3 * time.Second,
+			want:    check{Name: "control plane", Status: amber, Summary: "Cluster is RECONCILING and the API server answered in 3s.", Details: []string{"Upgrading master"}},
+		},
+		{
+			name:    "unreachable",
+			cluster: &containerpb.Cluster{Status: containerpb.Cluster_RUNNING},
+			err:     errors.New("connection refused"),
+			want:    check{Name: "control plane", Status: red, Summary: "Cluster is RUNNING but the API server can't be reached: connection refused"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := controlPlaneCheck(tc.cluster, tc.err, tc.latency)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("controlPlaneCheck() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNodesCheck(t *testing.T) {
+	ready := `{"type":"Ready","status":"True"}`
+	tests := []struct {
+		name  string
+		nodes string
+		want  check
+	}{
+		{
+			name:  "none",
+			nodes: `[]`,
+			want:  check{Name: "nodes", Status: amber, Summary: "The cluster has no nodes."},
+		},
+		{
+			name: "healthy",
+			nodes: `[{"metadata":{"name":"a"},"status":{"conditions":[` + ready + `,{"type":"MemoryPressure","status":"False"}]}},
+				{"metadata":{"name":"b"},"status":{"conditions":[` + ready + `]}}]`,
+			want: check{Name: "nodes", Status: green, Summary: "2 of 2 nodes ready, 0 under pressure, 0 cordoned."},
+		},
+		{
+			name: "pressure and cordoned",
+			nodes: `[{"metadata":{"name":"a"},"status":{"conditions":[` + ready + `,{"type":"DiskPressure","status":"True"}]}},
+				{"metadata":{"name":"b"},"spec":{"unschedulable":true},"status":{"conditions":[` + ready + `]}}]`,
+			want: check{Name: "nodes", Status: amber, Summary: "2 of 2 nodes ready, 1 under pressure, 1 cordoned.", Details: []string{"a has DiskPressure", "b is cordoned"}},
+		},
+		{
+			name: "half not ready",
+			nodes: `[{"metadata":{"name":"a"},"status":{"conditions":[{"type":"Ready","status":"Unknown","reason":"NodeStatusUnknown","message":"Kubelet stopped posting node status."}]}},
+				{"metadata":{"name":"b"},"status":{"conditions":[` + ready + `]}}]`,
+			want: check{Name: "nodes", Status: red, Summary: "1 of 2 nodes ready, 0 under pressure, 0 cordoned.", Details: []string{"a is not ready: NodeStatusUnknown Kubelet stopped posting node status."}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := nodesCheck(decode[node](t, tc.nodes))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("nodesCheck() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAddonsCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		deployments string
+		daemonSets  string
+		want        check
+	}{
+		{
+			name:        "available",
+			deployments: `[{"metadata":{"name":"kube-dns"},"spec":{"replicas":2},"status":{"availableReplicas":2}}]`,
+			daemonSets:  `[{"metadata":{"name":"fluentbit-gke"},"status":{"desiredNumberScheduled":3,"numberAvailable":3}}]`,
+			want:        check{Name: "core addons", Status: green, Summary: "2 of 2 kube-system Deployments and DaemonSets fully available."},
+		},
+		{
+			name:        "degraded",
+			deployments: `[{"metadata":{"name":"kube-dns"},"spec":{"replicas":2},"status":{"availableReplicas":1}}]`,
+			daemonSets:  `[{"metadata":{"name":"fluentbit-gke"},"status":{"desiredNumberScheduled":3,"numberAvailable":2}}]`,
+			want: check{Name: "core addons", Status: amber, Summary: "0 of 2 kube-system Deployments and DaemonSets fully available.", Details: []string{
+				"Deployment kube-dns: 1 of 2 replicas available",
+				"DaemonSet fluentbit-gke: 2 of 3 pods available",
+			}},
+		},
+		{
+			name:        "dns down",
+			deployments: `[{"metadata":{"name":"kube-dns"},"spec":{"replicas":2},"status":{}},{"metadata":{"name":"metrics-server"},"status":{"availableReplicas":1}}]`,
+			daemonSets:  `[]`,
+			want:        check{Name: "core addons", Status: red, Summary: "1 of 2 kube-system Deployments and DaemonSets fully available.", Details: []string{"Deployment kube-dns: 0 of 2 replicas available"}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := addonsCheck(decode[workload](t, tc.deployments), decode[workload](t, tc.daemonSets))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("addonsCheck() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPendingPodsCheck(t *testing.T) {
+	pods := decode[pendingPod](t, `[
+		{"metadata":{"name":"new","namespace":"default","creationTimestamp":"2025-06-01T11:58:00Z"}},
+		{"metadata":{"name":"big","namespace":"ml","creationTimestamp":"2025-06-01T11:00:00Z"},"status":{"conditions":[{"type":"PodScheduled","status":"False","reason":"Unschedulable","message":"0/3 nodes are available: 3 Insufficient nvidia.com/gpu."}]}},
+		{"metadata":{"name":"web","namespace":"default","creationTimestamp":"2025-06-01T11:50:00Z"}}
+	]`)
+	want := check{Name: "pending pods", Status: amber, Summary: "2 pods pending for more than 5m0s.", Details: []string{
+		"ml/big pending for 1h0m0s: Unschedulable: 0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+		"default/web pending for 10m0s: not scheduled yet",
+	}}
+	if diff := cmp.Diff(want, pendingPodsCheck(pods, now)); diff != "" {
+		t.Errorf("pendingPodsCheck() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestErrorsCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int64
+		want   string
+	}{
+		{name: "no logs", want: green},
+		{name: "steady", counts: []int64{400, 380, 420, 410}, want: green},
+		{name: "few", counts: []int64{0, 0, 0, 30}, want: green},
+		{name: "rising", counts: []int64{20, 30, 25, 120}, want: amber},
+		{name: "spike", counts: []int64{40, 50, 60, 900}, want: red},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := errorsCheck(tc.counts, errorWindow).Status; got != tc.want {
+				t.Errorf("errorsCheck(%v) status = %q, want %q", tc.counts, got, tc.want)
+			}
+		})
+	}
+}
+
+func certificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "cluster-ca"},
+		NotBefore:    now.AddDate(-5, 0, 0),
+		NotAfter:     notAfter,
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertificateCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		ca   []byte
+		want check
+	}{
+		{
+			name: "valid",
+			ca:   certificate(t, now.AddDate(10, 0, 0)),
+			want: check{Name: "certificates", Status: green, Summary: "The cluster CA certificate expires on 2035-06-01, in 3652 days."},
+		},
+		{
+			name: "expiring",
+			ca:   certificate(t, now.AddDate(0, 0, 20)),
+			want: check{Name: "certificates", Status: red, Summary: "The cluster CA certificate expires on 2025-06-21, in 20 days.", Details: []string{
+				"Rotate the cluster credentials with gcloud container clusters update --start-credential-rotation.",
+			}},
+		},
+		{
+			name: "invalid",
+			ca:   []byte("not a certificate"),
+			want: check{Name: "certificates", Status: unknown, Summary: "The cluster CA certificate couldn't be decoded."},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, certificateCheck(tc.ca, now)); diff != "" {
+				t.Errorf("certificateCheck() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQuotasCheck(t *testing.T) {
+	quota := func(metric string, usage, limit float64) *computepb.Quota {
+		return &computepb.Quota{Metric: proto.String(metric), Usage: proto.Float64(usage), Limit: proto.Float64(limit)}
+	}
+	quotas := []*computepb.Quota{
+		quota("CPUS", 85, 100),
+		quota("DISKS_TOTAL_GB", 1000, 40960),
+		quota("IN_USE_ADDRESSES", 23, 24),
+		quota("SSD_TOTAL_GB", 0, 0),
+	}
+	want := check{Name: "quotas", Status: red, Summary: "2 Compute Engine quotas of us-central1 are more than 80% used.", Details: []string{
+		"IN_USE_ADDRESSES: 23 of 24 used (96%)",
+		"CPUS: 85 of 100 used (85%)",
+	}}
+	if diff := cmp.Diff(want, quotasCheck("us-central1", quotas)); diff != "" {
+		t.Errorf("quotasCheck() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHeadroom(t *testing.T) {
+	quota := func(metric string, usage, limit float64) *computepb.Quota {
+		return &computepb.Quota{Metric: proto.String(metric), Usage: proto.Float64(usage), Limit: proto.Float64(limit)}
+	}
+	quotas := []*computepb.Quota{
+		quota("CPUS", 50, 100),
+		quota("N2_CPUS", 90, 100),
+		quota("NVIDIA_L4_GPUS", 0, 8),
+		quota("NVIDIA_H100_GPUS", 0, 0),
+		quota("IN_USE_ADDRESSES", 20, 24),
+		quota("FORWARDING_RULES", 14, 15),
+		quota("SSD_TOTAL_GB", 100, 1000),
+	}
+	want := []quotaHeadroom{
+		{Metric: "N2_CPUS", Group: "CPUs", Usage: 90, Limit: 100, Headroom: 10, Low: true},
+		{Metric: "IN_USE_ADDRESSES", Group: "IP addresses", Usage: 20, Limit: 24, Headroom: 16.7, Low: true},
+		{Metric: "CPUS", Group: "CPUs", Usage: 50, Limit: 100, Headroom: 50},
+		{Metric: "SSD_TOTAL_GB", Group: "Disks", Usage: 100, Limit: 1000, Headroom: 90},
+		{Metric: "NVIDIA_L4_GPUS", Group: "GPUs", Limit: 8, Headroom: 100},
+	}
+	if diff := cmp.Diff(want, headroom(quotas, defaultMinHeadroom)); diff != "" {
+		t.Errorf("headroom() mismatch (-want +got):\n%s", diff)
+	}
+}
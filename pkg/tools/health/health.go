@@ -0,0 +1,300 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/container/apiv1/containerpb"
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/scan"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// errorWindow is the window error logs are counted over; the last one is
+// compared with the 3 before.
+const errorWindow = 15 * time.Minute
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("health", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	clusterHealthCheckTool := mcp.NewTool("cluster_health_check",
+		mcp.WithDescription("Run a battery of health checks on a GKE cluster and return a red, amber or green report: control plane status and reachability, node readiness, core kube-system addons, pods stuck pending, spikes of error logs, cluster CA certificate expiry and regional Compute Engine quota headroom. Start with it when asked whether a cluster is healthy or during on-call triage."),
+		catalog.Describe(catalog.Observability, catalog.Read, "container.clusters.get", "container.nodes.list", "container.deployments.list", "container.daemonSets.list", "container.pods.list", "monitoring.timeSeries.list", "compute.regions.get"),
+		explain.Command(h.clusterHealthCheckCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+	)
+	s.AddTool(clusterHealthCheckTool, h.clusterHealthCheck)
+
+	quotaHeadroomReportTool := mcp.NewTool("quota_headroom_report",
+		mcp.WithDescription("Report the usage and limit of the regional Compute Engine quotas GKE nodes use (CPUs per machine family, GPUs per model, in-use IP addresses, disks and instances) in the region of a cluster location, lowest headroom first, highlighting the ones with less headroom than min_headroom_percent. Use it before scaling out or when node creation fails on quota."),
+		catalog.Describe(catalog.Observability, catalog.Read, "compute.regions.get"),
+		explain.Command(h.quotaHeadroomReportCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location, a region or a zone. Defaults to the session context.")),
+		mcp.WithNumber("min_headroom_percent", mcp.Description(fmt.Sprintf("Quotas with less headroom, in percent of their limit, are highlighted. Defaults to %d.", defaultMinHeadroom))),
+	)
+	s.AddTool(quotaHeadroomReportTool, h.quotaHeadroomReport)
+
+	addonHealthReportTool := mcp.NewTool("addon_health_report",
+		mcp.WithDescription("Summarize the health of the GKE-managed addons of a cluster's kube-system namespace, such as konnectivity-agent, metrics-server, gke-metadata-server, the CSI drivers, NodeLocal DNSCache, kube-dns and the logging and monitoring agents: their availability, image versions and container restarts. Use it when a cluster behaves oddly, e.g. kubectl logs or exec hang, HPAs can't read metrics, Workload Identity or volume mounts fail."),
+		catalog.Describe(catalog.Observability, catalog.Read, "container.deployments.list", "container.daemonSets.list", "container.pods.list"),
+		explain.Command(h.addonHealthReportCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+	)
+	s.AddTool(addonHealthReportTool, h.addonHealthReport)
+
+	return nil
+}
+
+// report is the result of cluster_health_check.
+type report struct {
+	Cluster string  `json:"cluster"`
+	Status  string  `json:"status"`
+	Checks  []check `json:"checks"`
+}
+
+// probe runs a check.
+type probe struct {
+	name string
+	run  func(ctx context.Context) (check, error)
+}
+
+func (h *handlers) clusterHealthCheck(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	k, connectErr := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	kubeProbe := func(name string, run func(ctx context.Context, k *kube.Client) (check, error)) probe {
+		return probe{name: name, run: func(ctx context.Context) (check, error) {
+			if connectErr != nil {
+				return check{}, connectErr
+			}
+			return run(ctx, k)
+		}}
+	}
+	probes := []probe{
+		kubeProbe("control plane", func(ctx context.Context, k *kube.Client) (check, error) {
+			start := time.Now()
+			err := k.Get(ctx, "/version", nil)
+			return controlPlaneCheck(cluster, err, time.Since(start)), nil
+		}),
+		kubeProbe("nodes", func(ctx context.Context, k *kube.Client) (check, error) {
+			var list struct {
+				Items []node `json:"items"`
+			}
+			if err := k.Get(ctx, "/api/v1/nodes", &list); err != nil {
+				return check{}, err
+			}
+			return nodesCheck(list.Items), nil
+		}),
+		kubeProbe("core addons", func(ctx context.Context, k *kube.Client) (check, error) {
+			var deployments, daemonSets struct {
+				Items []workload `json:"items"`
+			}
+			if err := k.Get(ctx, "/apis/apps/v1/namespaces/kube-system/deployments", &deployments); err != nil {
+				return check{}, err
+			}
+			if err := k.Get(ctx, "/apis/apps/v1/namespaces/kube-system/daemonsets", &daemonSets); err != nil {
+				return check{}, err
+			}
+			return addonsCheck(deployments.Items, daemonSets.Items), nil
+		}),
+		kubeProbe("pending pods", func(ctx context.Context, k *kube.Client) (check, error) {
+			var list struct {
+				Items []pendingPod `json:"items"`
+			}
+			if err := k.Get(ctx, "/api/v1/pods?fieldSelector=status.phase%3DPending", &list); err != nil {
+				return check{}, err
+			}
+			return pendingPodsCheck(list.Items, time.Now()), nil
+		}),
+		{name: "error logs", run: func(ctx context.Context) (check, error) {
+			counts, err := h.errorCounts(ctx, projectID, location, name)
+			if err != nil {
+				return check{}, err
+			}
+			return errorsCheck(counts, errorWindow), nil
+		}},
+		{name: "certificates", run: func(context.Context) (check, error) {
+			ca, err := base64.StdEncoding.DecodeString(cluster.GetMasterAuth().GetClusterCaCertificate())
+			if err != nil {
+				return check{}, err
+			}
+			return certificateCheck(ca, time.Now()), nil
+		}},
+		{name: "quotas", run: func(ctx context.Context) (check, error) {
+			region := regionOf(cluster.GetLocation())
+			quotas, err := h.regionQuotas(ctx, projectID, region)
+			if err != nil {
+				return check{}, err
+			}
+			return quotasCheck(region, quotas), nil
+		}},
+	}
+
+	r := report{Cluster: name}
+	var statuses []string
+	for _, result := range scan.Run(ctx, probes, len(probes), func(ctx context.Context, p probe) (check, error) {
+		return p.run(ctx)
+	}) {
+		c := result.Value
+		if result.Err != nil {
+			c = check{Status: unknown, Summary: fmt.Sprintf("The check failed: %v", result.Err)}
+		}
+		c.Name = result.Target.name
+		r.Checks = append(r.Checks, c)
+		statuses = append(statuses, c.Status)
+	}
+	r.Status = worst(statuses...)
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(summary(r) + "\n\n" + string(data)), nil
+}
+
+// summary lists the status of every check, one per line.
+func summary(r report) string {
+	lines := []string{fmt.Sprintf("Cluster %s is %s.", r.Cluster, strings.ToUpper(r.Status))}
+	for _, c := range r.Checks {
+		lines = append(lines, fmt.Sprintf("- %s %s: %s", strings.ToUpper(c.Status), c.Name, c.Summary))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// regionOf returns the region of a location, which may be a zone.
+func regionOf(location string) string {
+	if parts := strings.Split(location, "-"); len(parts) == 3 {
+		return parts[0] + "-" + parts[1]
+	}
+	return location
+}
+
+// errorCounts counts the error logs of the cluster's containers over the 4
+// last windows, oldest first.
+func (h *handlers) errorCounts(ctx context.Context, projectID, location, cluster string) ([]int64, error) {
+	client, err := gcp.Metrics(ctx, h.c)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now().Truncate(time.Minute)
+	it := client.ListTimeSeries(ctx, &monitoringpb.ListTimeSeriesRequest{
+		Name:     "projects/" + projectID,
+		Filter:   fmt.Sprintf(`metric.type="logging.googleapis.com/log_entry_count" AND metric.labels.severity=monitoring.regex.full_match("ERROR|CRITICAL|ALERT|EMERGENCY") AND resource.type="k8s_container" AND resource.labels.cluster_name="%s" AND resource.labels.location="%s"`, cluster, location),
+		Interval: &monitoringpb.TimeInterval{StartTime: timestamppb.New(end.Add(-4 * errorWindow)), EndTime: timestamppb.New(end)},
+		Aggregation: &monitoringpb.Aggregation{
+			AlignmentPeriod:    durationpb.New(errorWindow),
+			PerSeriesAligner:   monitoringpb.Aggregation_ALIGN_SUM,
+			CrossSeriesReducer: monitoringpb.Aggregation_REDUCE_SUM,
+		},
+	})
+	var counts []int64
+	for {
+		ts, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Points are newest first.
+		for _, p := range ts.GetPoints() {
+			counts = append([]int64{p.GetValue().GetInt64Value()}, counts...)
+		}
+	}
+	return counts, nil
+}
+
+// regionQuotas gets the Compute Engine quotas of a region.
+func (h *handlers) regionQuotas(ctx context.Context, projectID, region string) ([]*computepb.Quota, error) {
+	client, err := gcp.Regions(ctx, h.c)
+	if err != nil {
+		return nil, err
+	}
+	r, err := client.Get(ctx, &computepb.GetRegionRequest{Project: projectID, Region: region})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetQuotas(), nil
+}
+
+func (h *handlers) clusterHealthCheckCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud container clusters describe", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--format=value(status,statusMessage,masterAuth.clusterCaCertificate)"),
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get --raw='/readyz?verbose'",
+		"kubectl get nodes",
+		"kubectl get deployments,daemonsets --namespace=kube-system",
+		"kubectl get pods --all-namespaces --field-selector=status.phase=Pending",
+		explain.Join("gcloud compute regions describe", regionOf(location), explain.Flag("project", projectID), "--format=table(quotas.metric,quotas.usage,quotas.limit)"),
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultMinHeadroom is the headroom, in percent, under which quotas are
+// highlighted.
+const defaultMinHeadroom = 20
+
+// quotaHeadroom is the usage of a quota.
+type quotaHeadroom struct {
+	Metric   string  `json:"metric"`
+	Group    string  `json:"group"`
+	Usage    float64 `json:"usage"`
+	Limit    float64 `json:"limit"`
+	Headroom float64 `json:"headroomPercent"`
+	Low      bool    `json:"low,omitempty"`
+}
+
+// quotaGroup returns what a regional quota limits, if GKE nodes use it.
+func quotaGroup(metric string) (string, bool) {
+	switch {
+	case strings.HasSuffix(metric, "_GPUS"):
+		return "GPUs", true
+	case metric == "CPUS" || strings.HasSuffix(metric, "_CPUS"):
+		return "CPUs", true
+	case metric == "IN_USE_ADDRESSES" || metric == "STATIC_ADDRESSES" || metric == "INTERNAL_ADDRESSES":
+		return "IP addresses", true
+	case strings.HasSuffix(metric, "_TOTAL_GB"):
+		return "Disks", true
+	case metric == "INSTANCES" || metric == "INSTANCE_GROUPS" || metric == "INSTANCE_GROUP_MANAGERS" || metric == "INSTANCE_TEMPLATES":
+		return "Instances", true
+	}
+	return "", false
+}
+
+// headroom returns the usage of the quotas GKE nodes use, lowest headroom
+// first, marking the ones with less than minHeadroom percent left.
+func headroom(quotas []*computepb.Quota, minHeadroom float64) []quotaHeadroom {
+	var result []quotaHeadroom
+	for _, q := range quotas {
+		group, ok := quotaGroup(q.GetMetric())
+		// Quotas with no limit are for machine families not offered in
+		// the region.
+		if !ok || q.GetLimit() <= 0 {
+			continue
+		}
+		h := quotaHeadroom{
+			Metric:   q.GetMetric(),
+			Group:    group,
+			Usage:    q.GetUsage(),
+			Limit:    q.GetLimit(),
+			Headroom: math.Round((q.GetLimit()-q.GetUsage())/q.GetLimit()*1000) / 10,
+		}
+		h.Low = h.Headroom < minHeadroom
+		result = append(result, h)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Headroom != result[j].Headroom {
+			return result[i].Headroom < result[j].Headroom
+		}
+		return result[i].Metric < result[j].Metric
+	})
+	return result
+}
+
+func (h *handlers) quotaHeadroomReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	minHeadroom := request.GetFloat("min_headroom_percent", defaultMinHeadroom)
+	if minHeadroom < 0 || minHeadroom > 100 {
+		return mcp.NewToolResultError("min_headroom_percent must be between 0 and 100"), nil
+	}
+
+	region := regionOf(location)
+	quotas, err := h.regionQuotas(ctx, projectID, region)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	report := headroom(quotas, minHeadroom)
+
+	var low []string
+	for _, q := range report {
+		if q.Low {
+			low = append(low, fmt.Sprintf("%s (%.0f of %.0f used)", q.Metric, q.Usage, q.Limit))
+		}
+	}
+	text := fmt.Sprintf("No quota GKE nodes use in %s has less than %g%% headroom.", region, minHeadroom)
+	if len(low) > 0 {
+		text = fmt.Sprintf("%d quotas GKE nodes use in %s have less than %g%% headroom: %s.", len(low), region, minHeadroom, strings.Join(low, ", "))
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(text + "\n\n" + string(data)), nil
+}
+
+func (h *handlers) quotaHeadroomReportCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c)
+	if projectID == "" || location == "" {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud compute regions describe", regionOf(location), explain.Flag("project", projectID), "--flatten=quotas", "--format=table(quotas.metric,quotas.usage,quotas.limit)"),
+	}
+}
@@ -0,0 +1,443 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// releaseSecretType is the type of the secrets Helm stores releases in.
+	releaseSecretType = "helm.sh/release.v1"
+	// maxValueChanges is the number of changed values reported per release.
+	maxValueChanges = 50
+	// maxIndexSize bounds the size of a chart repository index.
+	maxIndexSize = 64 << 20
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("helm", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	listHelmReleasesTool := mcp.NewTool("list_helm_releases",
+		mcp.WithDescription("List the Helm releases installed in a GKE cluster, read from the release secrets Helm stores, with their status, chart and app versions and the values that differ from the chart defaults. Given the chart repository URL, also reports the latest chart version, to answer what's deployed and whether it is outdated. The caller needs permission to list secrets in the cluster."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.get", "container.secrets.list"),
+		explain.Command(h.listHelmReleasesCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("namespace", mcp.Description("Namespace of the releases. Leave empty for all namespaces.")),
+		mcp.WithString("release", mcp.Description("Name of a single release to show.")),
+		mcp.WithString("repository_url", mcp.Description("URL of the Helm chart repository the charts come from, e.g. https://charts.jetstack.io, to report the latest chart versions. OCI registries are not supported.")),
+		mcp.WithBoolean("all_revisions", mcp.Description("Include the superseded revisions of the releases, e.g. to find what changed in the last upgrade. Defaults to only the latest revision.")),
+		governor.FullOption(),
+	)
+	s.AddTool(listHelmReleasesTool, h.listHelmReleases)
+
+	return nil
+}
+
+func (h *handlers) listHelmReleasesCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	namespace := explain.Flag("namespace", request.GetString("namespace", ""))
+	if namespace == "" {
+		namespace = "--all-namespaces"
+	}
+	commands := []string{explain.GetCredentials(projectID, location, cluster)}
+	name := request.GetString("release", "")
+	switch {
+	case name == "":
+		commands = append(commands, explain.Join("helm list --all", namespace))
+	case request.GetBool("all_revisions", false):
+		commands = append(commands, explain.Join("helm history", name, explain.Flag("namespace", request.GetString("namespace", ""))))
+	default:
+		commands = append(commands,
+			explain.Join("helm list --all", namespace, explain.Flag("filter", "^"+name+"$")),
+			explain.Join("helm get values", name, explain.Flag("namespace", request.GetString("namespace", ""))))
+	}
+	if repositoryURL := request.GetString("repository_url", ""); repositoryURL != "" {
+		commands = append(commands,
+			explain.Join("helm repo add", "upstream", repositoryURL),
+			"helm search repo upstream/")
+	}
+	return commands
+}
+
+// release is the part of a Helm release record used here.
+type release struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status       string `json:"status"`
+		LastDeployed string `json:"last_deployed"`
+		Description  string `json:"description"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+			Deprecated bool   `json:"deprecated"`
+		} `json:"metadata"`
+		Values map[string]any `json:"values"`
+	} `json:"chart"`
+	Config map[string]any `json:"config"`
+}
+
+// releaseSummary is the tool result entry of a release revision.
+type releaseSummary struct {
+	Name          string        `json:"name"`
+	Namespace     string        `json:"namespace"`
+	Revision      int           `json:"revision"`
+	Status        string        `json:"status"`
+	Updated       string        `json:"updated,omitempty"`
+	Description   string        `json:"description,omitempty"`
+	Chart         string        `json:"chart"`
+	ChartVersion  string        `json:"chartVersion"`
+	AppVersion    string        `json:"appVersion,omitempty"`
+	Deprecated    bool          `json:"deprecated,omitempty"`
+	LatestVersion string        `json:"latestVersion,omitempty"`
+	Outdated      bool          `json:"outdated,omitempty"`
+	ValueChanges  []valueChange `json:"valueChanges,omitempty"`
+	Note          string        `json:"note,omitempty"`
+}
+
+// valueChange is a value set for a release. Default is omitted when the chart
+// doesn't define the value.
+type valueChange struct {
+	Path    string `json:"path"`
+	Default any    `json:"default,omitempty"`
+	Value   any    `json:"value"`
+}
+
+type secretList struct {
+	Metadata struct {
+		Continue string `json:"continue"`
+	} `json:"metadata"`
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Type string            `json:"type"`
+		Data map[string][]byte `json:"data"`
+	} `json:"items"`
+}
+
+func (h *handlers) listHelmReleases(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	cluster := session.Cluster(ctx, request, h.c, "cluster")
+	if cluster == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, cluster)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	releases, err := listReleases(ctx, k, request.GetString("namespace", ""), request.GetString("release", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !request.GetBool("all_revisions", false) {
+		releases = latestRevisions(releases)
+	}
+
+	var latest map[string]string
+	if repositoryURL := request.GetString("repository_url", ""); repositoryURL != "" {
+		latest, err = latestChartVersions(ctx, repositoryURL)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	summaries := make([]releaseSummary, 0, len(releases))
+	for _, r := range releases {
+		summaries = append(summaries, summarize(r, latest))
+	}
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// listReleases reads every revision of the releases stored in namespace, or
+// all namespaces if empty, optionally only those of the release name.
+func listReleases(ctx context.Context, k *kube.Client, namespace, name string) ([]*release, error) {
+	path := "/api/v1/secrets"
+	if namespace != "" {
+		path = "/api/v1/namespaces/" + url.PathEscape(namespace) + "/secrets"
+	}
+	selector := "owner=helm"
+	if name != "" {
+		selector += ",name=" + name
+	}
+	query := url.Values{"labelSelector": {selector}, "limit": {"100"}}
+
+	var releases []*release
+	for {
+		var list secretList
+		if err := k.Get(ctx, path+"?"+query.Encode(), &list); err != nil {
+			return nil, fmt.Errorf("failed to list the Helm release secrets: %w", err)
+		}
+		for _, secret := range list.Items {
+			if secret.Type != releaseSecretType {
+				continue
+			}
+			r, err := decodeRelease(secret.Data["release"])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode Helm release secret %s/%s: %w", secret.Metadata.Namespace, secret.Metadata.Name, err)
+			}
+			releases = append(releases, r)
+		}
+		if list.Metadata.Continue == "" {
+			break
+		}
+		query.Set("continue", list.Metadata.Continue)
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		a, b := releases[i], releases[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version > b.Version
+	})
+	return releases, nil
+}
+
+// decodeRelease decodes a release record as Helm stores it in a secret:
+// base64 encoded, usually gzipped, JSON.
+func decodeRelease(data []byte) (*release, error) {
+	data, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+	var r release
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// latestRevisions keeps the latest revision of every release, given the
+// revisions sorted by release and newest first.
+func latestRevisions(releases []*release) []*release {
+	var latest []*release
+	for i, r := range releases {
+		if i == 0 || r.Namespace != releases[i-1].Namespace || r.Name != releases[i-1].Name {
+			latest = append(latest, r)
+		}
+	}
+	return latest
+}
+
+func summarize(r *release, latest map[string]string) releaseSummary {
+	s := releaseSummary{
+		Name:         r.Name,
+		Namespace:    r.Namespace,
+		Revision:     r.Version,
+		Status:       r.Info.Status,
+		Updated:      r.Info.LastDeployed,
+		Description:  r.Info.Description,
+		Chart:        r.Chart.Metadata.Name,
+		ChartVersion: r.Chart.Metadata.Version,
+		AppVersion:   r.Chart.Metadata.AppVersion,
+		Deprecated:   r.Chart.Metadata.Deprecated,
+	}
+	if latest != nil {
+		if v, ok := latest[s.Chart]; ok {
+			s.LatestVersion = v
+			s.Outdated = compareVersions(s.ChartVersion, v) < 0
+		} else {
+			s.Note = "chart not found in the repository"
+		}
+	}
+	changes := valueChanges(r.Chart.Values, r.Config)
+	if len(changes) > maxValueChanges {
+		s.Note = strings.TrimPrefix(s.Note+fmt.Sprintf("; %d more changed values not shown", len(changes)-maxValueChanges), "; ")
+		changes = changes[:maxValueChanges]
+	}
+	s.ValueChanges = changes
+	return s
+}
+
+// valueChanges returns the values of config, the values supplied at install
+// or upgrade, that differ from the chart defaults, sorted by path. Maps are
+// compared key by key, other values as a whole.
+func valueChanges(defaults, config map[string]any) []valueChange {
+	var changes []valueChange
+	var walk func(prefix string, defaults, config map[string]any)
+	walk = func(prefix string, defaults, config map[string]any) {
+		for key, value := range config {
+			path := prefix + key
+			def, ok := defaults[key]
+			if m, isMap := value.(map[string]any); isMap {
+				defMap, _ := def.(map[string]any)
+				walk(path+".", defMap, m)
+				continue
+			}
+			if ok && reflect.DeepEqual(def, value) {
+				continue
+			}
+			changes = append(changes, valueChange{Path: path, Default: def, Value: value})
+		}
+	}
+	walk("", defaults, config)
+	slices.SortFunc(changes, func(a, b valueChange) int { return strings.Compare(a.Path, b.Path) })
+	return changes
+}
+
+// latestChartVersions returns the latest stable version of every chart in
+// the index of a Helm chart repository.
+func latestChartVersions(ctx context.Context, repositoryURL string) (map[string]string, error) {
+	if strings.HasPrefix(repositoryURL, "oci://") {
+		return nil, fmt.Errorf("OCI chart registries are not supported, use the URL of a Helm chart repository")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(repositoryURL, "/")+"/index.yaml", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the chart repository index: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the chart repository index %s: %s", req.URL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIndexSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the chart repository index: %w", err)
+	}
+	return parseIndex(data)
+}
+
+// parseIndex returns the latest stable version of every chart of a Helm
+// repository index, or the latest prerelease if a chart has no stable one.
+func parseIndex(data []byte) (map[string]string, error) {
+	var index struct {
+		Entries map[string][]struct {
+			Version string `yaml:"version"`
+		} `yaml:"entries"`
+	}
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid chart repository index: %w", err)
+	}
+	latest := map[string]string{}
+	for chart, versions := range index.Entries {
+		var best string
+		bestStable := false
+		for _, v := range versions {
+			stable := !strings.Contains(v.Version, "-")
+			if best == "" || stable && !bestStable || stable == bestStable && compareVersions(v.Version, best) > 0 {
+				best, bestStable = v.Version, stable
+			}
+		}
+		latest[chart] = best
+	}
+	return latest, nil
+}
+
+// compareVersions compares two semantic versions, returning -1, 0 or 1. Build
+// metadata is ignored and prereleases are compared as strings.
+func compareVersions(a, b string) int {
+	parse := func(v string) ([3]int, string) {
+		v, _, _ = strings.Cut(strings.TrimPrefix(v, "v"), "+")
+		v, pre, _ := strings.Cut(v, "-")
+		var nums [3]int
+		for i, part := range strings.SplitN(v, ".", 3) {
+			nums[i], _ = strconv.Atoi(part)
+		}
+		return nums, pre
+	}
+	an, apre := parse(a)
+	bn, bpre := parse(b)
+	for i := range an {
+		if an[i] != bn[i] {
+			if an[i] < bn[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	}
+	return strings.Compare(apre, bpre)
+}
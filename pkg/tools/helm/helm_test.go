@@ -0,0 +1,130 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeRelease(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(`{"name":"cert-manager","namespace":"cert-manager","version":3,"info":{"status":"deployed"},"chart":{"metadata":{"name":"cert-manager","version":"v1.14.0"}}}`))
+	zw.Close()
+
+	r, err := decodeRelease([]byte(base64.StdEncoding.EncodeToString(gz.Bytes())))
+	if err != nil {
+		t.Fatalf("decodeRelease() failed: %v", err)
+	}
+	got := summarize(r, map[string]string{"cert-manager": "v1.15.1"})
+	want := releaseSummary{
+		Name:          "cert-manager",
+		Namespace:     "cert-manager",
+		Revision:      3,
+		Status:        "deployed",
+		Chart:         "cert-manager",
+		ChartVersion:  "v1.14.0",
+		LatestVersion: "v1.15.1",
+		Outdated:      true,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("summarize() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLatestRevisions(t *testing.T) {
+	releases := []*release{
+		{Name: "a", Namespace: "x", Version: 2},
+		{Name: "a", Namespace: "x", Version: 1},
+		{Name: "a", Namespace: "y", Version: 1},
+		{Name: "b", Namespace: "y", Version: 5},
+		{Name: "b", Namespace: "y", Version: 4},
+	}
+	var got []int
+	for _, r := range latestRevisions(releases) {
+		got = append(got, r.Version)
+	}
+	if diff := cmp.Diff([]int{2, 1, 5}, got); diff != "" {
+		t.Errorf("latestRevisions() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValueChanges(t *testing.T) {
+	defaults := map[string]any{
+		"replicaCount": 1.0,
+		"image":        map[string]any{"repository": "nginx", "tag": ""},
+		"resources":    map[string]any{},
+		"tolerations":  []any{},
+	}
+	config := map[string]any{
+		"replicaCount": 3.0,
+		"image":        map[string]any{"repository": "nginx", "tag": "1.27"},
+		"resources":    map[string]any{"limits": map[string]any{"cpu": "500m"}},
+		"extra":        true,
+	}
+	want := []valueChange{
+		{Path: "extra", Value: true},
+		{Path: "image.tag", Default: "", Value: "1.27"},
+		{Path: "replicaCount", Default: 1.0, Value: 3.0},
+		{Path: "resources.limits.cpu", Value: "500m"},
+	}
+	if diff := cmp.Diff(want, valueChanges(defaults, config)); diff != "" {
+		t.Errorf("valueChanges() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	index := `apiVersion: v1
+entries:
+  app:
+  - version: 1.10.0
+  - version: 1.9.3
+  - version: 2.0.0-rc.1
+  beta-only:
+  - version: 0.1.0-alpha.1
+  - version: 0.1.0-alpha.2
+`
+	got, err := parseIndex([]byte(index))
+	if err != nil {
+		t.Fatalf("parseIndex() failed: %v", err)
+	}
+	want := map[string]string{"app": "1.10.0", "beta-only": "0.1.0-alpha.2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseIndex() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.9.0", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	} {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/install"
+)
+
+//go:generate go test -run ^TestBundledIndex$ -update-index .
+
+// bundledIndexFile is the index of the bundled instructions, generated so
+// that they aren't parsed and tokenized at startup.
+const bundledIndexFile = "bundled_index.json"
+
+//go:embed bundled_index.json
+var bundledIndexData []byte
+
+// prebuiltIndex is the format of bundledIndexFile: the sections of a
+// markdown document and an inverted index of their terms.
+type prebuiltIndex struct {
+	// Digest is the SHA-256 of the markdown, to detect a stale index.
+	Digest   string    `json:"digest"`
+	Sections []Section `json:"sections"`
+	// Lengths are the number of terms of the content of every section.
+	Lengths []int `json:"lengths"`
+	// Postings are the sections every term occurs in, in order.
+	Postings map[string][]posting `json:"postings"`
+}
+
+// posting counts the occurrences of a term in a section, by where they
+// occur.
+type posting struct {
+	Section  int `json:"s"`
+	Content  int `json:"c,omitempty"`
+	Title    int `json:"t,omitempty"`
+	Headings int `json:"h,omitempty"`
+}
+
+// newPrebuiltIndex parses and indexes the bundled instructions md.
+func newPrebuiltIndex(md string) prebuiltIndex {
+	sections := parseMarkdown(bundledSource, md)
+	p := prebuiltIndex{
+		Digest:   digest(md),
+		Sections: sections,
+		Lengths:  make([]int, len(sections)),
+		Postings: map[string][]posting{},
+	}
+	for n, section := range sections {
+		t := analyze(section)
+		p.Lengths[n] = t.length
+		terms := map[string]bool{}
+		for _, m := range []map[string]int{t.content, t.title, t.headings} {
+			for term := range m {
+				terms[term] = true
+			}
+		}
+		for term := range terms {
+			p.Postings[term] = append(p.Postings[term], posting{
+				Section:  n,
+				Content:  t.content[term],
+				Title:    t.title[term],
+				Headings: t.headings[term],
+			})
+		}
+	}
+	return p
+}
+
+// terms returns the terms of every section.
+func (p prebuiltIndex) terms() []sectionTerms {
+	terms := make([]sectionTerms, len(p.Sections))
+	for n := range terms {
+		terms[n] = newSectionTerms()
+		terms[n].length = p.Lengths[n]
+	}
+	for term, postings := range p.Postings {
+		for _, ps := range postings {
+			t := terms[ps.Section]
+			if ps.Content > 0 {
+				t.content[term] = ps.Content
+			}
+			if ps.Title > 0 {
+				t.title[term] = ps.Title
+			}
+			if ps.Headings > 0 {
+				t.headings[term] = ps.Headings
+			}
+		}
+	}
+	return terms
+}
+
+// valid reports whether p was generated from md.
+func (p prebuiltIndex) valid(md string) bool {
+	if p.Digest != digest(md) || len(p.Lengths) != len(p.Sections) {
+		return false
+	}
+	for _, postings := range p.Postings {
+		for _, ps := range postings {
+			if ps.Section < 0 || ps.Section >= len(p.Sections) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func digest(md string) string {
+	sum := sha256.Sum256([]byte(md))
+	return hex.EncodeToString(sum[:])
+}
+
+// bundled returns the sections of the bundled instructions and their terms.
+// They come from the generated index, unless GEMINI.md was edited without
+// running go generate.
+var bundled = sync.OnceValues(func() ([]Section, []sectionTerms) {
+	md := string(install.GeminiMarkdown)
+	var p prebuiltIndex
+	if err := json.Unmarshal(bundledIndexData, &p); err != nil || !p.valid(md) {
+		slog.Debug("The generated instructions index is stale, parsing the bundled instructions", "err", err)
+		p = newPrebuiltIndex(md)
+	}
+	return p.Sections, p.terms()
+})
+
+// bundledTerms returns the terms of the bundled sections that sections
+// starts with, as loadSections puts them first.
+func bundledTerms(sections []Section) []sectionTerms {
+	bundledSections, terms := bundled()
+	n := 0
+	for n < len(sections) && n < len(bundledSections) && sameSection(sections[n], bundledSections[n]) {
+		n++
+	}
+	return terms[:n]
+}
+
+func sameSection(a, b Section) bool {
+	return a.Source == b.Source && a.Title == b.Title && a.Level == b.Level && a.Part == b.Part &&
+		a.Content == b.Content && slices.Equal(a.Headings, b.Headings)
+}
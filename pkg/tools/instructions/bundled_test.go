@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/install"
+	"github.com/google/go-cmp/cmp"
+)
+
+var updateIndex = flag.Bool("update-index", false, "regenerate "+bundledIndexFile)
+
+func TestBundledIndex(t *testing.T) {
+	p := newPrebuiltIndex(string(install.GeminiMarkdown))
+	want, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	want = append(want, '\n')
+	if *updateIndex {
+		if err := os.WriteFile(bundledIndexFile, want, 0644); err != nil {
+			t.Fatalf("os.WriteFile() failed: %v", err)
+		}
+		return
+	}
+	if !bytes.Equal(bundledIndexData, want) {
+		t.Errorf("%s is stale, run go generate ./pkg/tools/instructions", bundledIndexFile)
+	}
+
+	sections, terms := bundled()
+	if diff := cmp.Diff(p.Sections, sections); diff != "" {
+		t.Errorf("bundled() sections mismatch (-want +got):\n%s", diff)
+	}
+	for n, section := range sections {
+		if diff := cmp.Diff(analyze(section), terms[n], cmp.AllowUnexported(sectionTerms{})); diff != "" {
+			t.Errorf("bundled() terms of %q mismatch (-want +got):\n%s", section.Title, diff)
+		}
+	}
+}
+
+func TestBundledTerms(t *testing.T) {
+	bundledSections, _ := bundled()
+	extra := parseMarkdown("runbook.md", "# Upgrades\n\nDrain the nodes first.\n")
+	sections := append(append([]Section{}, bundledSections...), extra...)
+	if got := len(bundledTerms(sections)); got != len(bundledSections) {
+		t.Errorf("bundledTerms() returned the terms of %d sections, want %d", got, len(bundledSections))
+	}
+	if got := len(bundledTerms(extra)); got != 0 {
+		t.Errorf("bundledTerms() of other sections returned the terms of %d sections, want 0", got)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+)
+
+const (
+	// chunkTokens is the approximate size in tokens above which sections
+	// are split into chunks.
+	chunkTokens = 400
+	// overlapTokens is the approximate size in tokens of the text repeated
+	// at the start of the next chunk, so context isn't lost at the split.
+	overlapTokens = 80
+)
+
+// chunk returns section, whose content so far is its heading line, with body
+// as content, split into overlapping chunks of about chunkTokens if needed.
+// Chunks are split between paragraphs, code blocks and list items where
+// possible.
+func chunk(section Section, body string) []Section {
+	heading := section.Content
+	body = strings.TrimSpace(body)
+	if governor.EstimateTokens(heading+body) <= chunkTokens {
+		section.Content = strings.TrimSpace(heading + "\n\n" + body)
+		return []Section{section}
+	}
+
+	var parts [][]string
+	var current []string
+	size := 0
+	for _, block := range splitBlocks(body) {
+		tokens := governor.EstimateTokens(block)
+		if size+tokens > chunkTokens && len(current) > 0 {
+			parts = append(parts, current)
+			current, size = nil, 0
+			if last := parts[len(parts)-1][len(parts[len(parts)-1])-1]; governor.EstimateTokens(last) <= overlapTokens {
+				current, size = []string{last}, governor.EstimateTokens(last)
+			}
+		}
+		current = append(current, block)
+		size += tokens
+	}
+	parts = append(parts, current)
+
+	var chunks []Section
+	for i, part := range parts {
+		c := section
+		c.Part = i + 1
+		c.Content = strings.Join(part, "\n\n")
+		if heading != "" {
+			if i > 0 {
+				c.Content = heading + " (continued)\n\n" + c.Content
+			} else {
+				c.Content = heading + "\n\n" + c.Content
+			}
+		}
+		chunks = append(chunks, c)
+	}
+	return chunks
+}
+
+// splitBlocks splits markdown text into blocks separated by blank lines,
+// keeping code blocks whole. Blocks larger than chunkTokens are split
+// between lines.
+func splitBlocks(text string) []string {
+	var blocks []string
+	var lines []string
+	inCode := false
+	add := func() {
+		if len(lines) == 0 {
+			return
+		}
+		blocks = append(blocks, splitLines(lines)...)
+		lines = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if strings.TrimSpace(line) == "" && !inCode {
+			add()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	add()
+	return blocks
+}
+
+// splitLines joins lines into blocks of at most about chunkTokens.
+func splitLines(lines []string) []string {
+	var blocks []string
+	var b strings.Builder
+	for _, line := range lines {
+		if b.Len() > 0 && governor.EstimateTokens(b.String()+line) > chunkTokens {
+			blocks = append(blocks, b.String())
+			b.Reset()
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(line)
+	}
+	if b.Len() > 0 {
+		blocks = append(blocks, b.String())
+	}
+	return blocks
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+)
+
+func TestChunk(t *testing.T) {
+	var paragraphs []string
+	for i := range 20 {
+		paragraphs = append(paragraphs, fmt.Sprintf("Paragraph %d. %s", i, strings.Repeat("word ", 40)))
+	}
+	code := "```sh\n" + strings.Repeat("gcloud container clusters list\n", 10) + "```"
+	body := strings.Join(paragraphs[:10], "\n\n") + "\n\n" + code + "\n\n" + strings.Join(paragraphs[10:], "\n\n")
+
+	section := Section{Title: "Long", Level: 2, Headings: []string{"Long"}, Content: "## Long"}
+	chunks := chunk(section, body)
+	if len(chunks) < 2 {
+		t.Fatalf("chunk() returned %d chunks, want the section split", len(chunks))
+	}
+	for i, c := range chunks {
+		if c.Part != i+1 || c.Title != "Long" {
+			t.Errorf("chunk %d has part %d and title %q, want part %d of Long", i, c.Part, c.Title, i+1)
+		}
+		if tokens := governor.EstimateTokens(c.Content); tokens > chunkTokens+overlapTokens {
+			t.Errorf("chunk %d has about %d tokens, want at most %d", i, tokens, chunkTokens+overlapTokens)
+		}
+		wantHeading := "## Long\n\n"
+		if i > 0 {
+			wantHeading = "## Long (continued)\n\n"
+		}
+		if !strings.HasPrefix(c.Content, wantHeading) {
+			t.Errorf("chunk %d starts with %q, want the heading %q", i, c.Content[:20], wantHeading)
+		}
+		if strings.Count(c.Content, "```") == 1 {
+			t.Errorf("chunk %d splits the code block", i)
+		}
+		if i > 0 {
+			// The last paragraph of a chunk is repeated in the next one.
+			prev := chunks[i-1].Content
+			last := prev[strings.LastIndex(prev, "\n\n")+2:]
+			if governor.EstimateTokens(last) <= overlapTokens && !strings.Contains(c.Content, last) {
+				t.Errorf("chunk %d doesn't start with the end of chunk %d", i, i-1)
+			}
+		}
+	}
+
+	short := chunk(section, "\nShort body.\n")
+	if len(short) != 1 || short[0].Part != 0 || short[0].Content != "## Long\n\nShort body." {
+		t.Errorf("chunk() of a short section = %+v, want it whole", short)
+	}
+}
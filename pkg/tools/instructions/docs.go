@@ -0,0 +1,325 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// docsBaseURL is the base of relative documentation page paths.
+	docsBaseURL = "https://cloud.google.com/kubernetes-engine/docs/"
+
+	// docsMaxAge is how long a fetched page is used before it is
+	// revalidated.
+	docsMaxAge = 24 * time.Hour
+)
+
+// docURL resolves a documentation page given as a URL or a path relative to
+// docsBaseURL.
+func docURL(page string) (string, error) {
+	base, _ := url.Parse(docsBaseURL)
+	u, err := base.Parse(strings.TrimPrefix(page, "/"))
+	if err != nil {
+		return "", fmt.Errorf("invalid documentation page %q: %w", page, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", fmt.Errorf("invalid documentation page %q: must be an HTTP(S) URL or a path", page)
+	}
+	return u.String(), nil
+}
+
+// cachedDoc is a documentation page cached on disk, converted to markdown.
+type cachedDoc struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	Markdown     string    `json:"markdown"`
+}
+
+// docFetcher fetches documentation pages, caching them in a directory and
+// revalidating them with their ETag once they are older than docsMaxAge.
+type docFetcher struct {
+	client *http.Client
+	dir    string
+	now    func() time.Time
+}
+
+// newDocFetcher returns a fetcher caching pages in the user cache
+// directory.
+func newDocFetcher() (*docFetcher, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &docFetcher{
+		client: http.DefaultClient,
+		dir:    filepath.Join(dir, "gke-mcp", "docs"),
+		now:    time.Now,
+	}, nil
+}
+
+// fetch returns the page at url as markdown. A cached copy is returned when
+// it is recent and refresh is false, unchanged, or the page can't be
+// fetched.
+func (f *docFetcher) fetch(ctx context.Context, url string, refresh bool) (string, error) {
+	cached, err := f.load(url)
+	if err != nil {
+		slog.Warn("Failed to read cached documentation page", "url", url, "err", err)
+	}
+	if cached != nil && !refresh && f.now().Sub(cached.FetchedAt) < docsMaxAge {
+		return cached.Markdown, nil
+	}
+
+	doc, err := f.get(ctx, url, cached)
+	if err != nil {
+		if cached == nil {
+			return "", err
+		}
+		slog.Warn("Failed to revalidate documentation page, using the cached copy", "url", url, "err", err)
+		return cached.Markdown, nil
+	}
+	if err := f.save(doc); err != nil {
+		slog.Warn("Failed to cache documentation page", "url", url, "err", err)
+	}
+	return doc.Markdown, nil
+}
+
+// get fetches url, or revalidates the cached copy if there is one.
+func (f *docFetcher) get(ctx context.Context, url string, cached *cachedDoc) (*cachedDoc, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		doc := *cached
+		doc.FetchedAt = f.now()
+		return &doc, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	md, err := htmlToMarkdown(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", url, err)
+	}
+	return &cachedDoc{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    f.now(),
+		Markdown:     md,
+	}, nil
+}
+
+func (f *docFetcher) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// load returns the cached copy of url, or nil if there is none.
+func (f *docFetcher) load(url string) (*cachedDoc, error) {
+	data, err := os.ReadFile(f.path(url))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var doc cachedDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.URL != url {
+		return nil, nil
+	}
+	return &doc, nil
+}
+
+func (f *docFetcher) save(doc *cachedDoc) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(f.path(doc.URL), data, 0600)
+}
+
+// htmlToMarkdown extracts the article of a documentation page as markdown,
+// keeping its headings, paragraphs, lists and code blocks.
+func htmlToMarkdown(r io.Reader) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", err
+	}
+	root := findArticle(doc)
+	if root == nil {
+		root = doc
+	}
+	var b strings.Builder
+	renderBlocks(&b, root)
+	return strings.TrimSpace(b.String()) + "\n", nil
+}
+
+// findArticle returns the main content of a page: the devsite article body,
+// or else the first article or main element.
+func findArticle(doc *html.Node) *html.Node {
+	var body, fallback *html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if body != nil {
+			return
+		}
+		if n.Type == html.ElementNode {
+			if strings.Contains(attr(n, "class"), "devsite-article-body") {
+				body = n
+				return
+			}
+			if fallback == nil && (n.DataAtom == atom.Article || n.DataAtom == atom.Main) {
+				fallback = n
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if body != nil {
+		return body
+	}
+	return fallback
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// skipped reports whether an element has no content worth indexing.
+func skipped(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Nav, atom.Noscript, atom.Button, atom.Svg, atom.Template:
+		return true
+	}
+	return false
+}
+
+func renderBlocks(b *strings.Builder, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			if text := strings.Join(strings.Fields(c.Data), " "); text != "" {
+				b.WriteString(text + "\n\n")
+			}
+			continue
+		case c.Type != html.ElementNode || skipped(c):
+			continue
+		}
+		switch c.DataAtom {
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			level := int(c.Data[1] - '0')
+			fmt.Fprintf(b, "%s %s\n\n", strings.Repeat("#", level), inlineText(c))
+		case atom.P:
+			if text := inlineText(c); text != "" {
+				b.WriteString(text + "\n\n")
+			}
+		case atom.Li:
+			b.WriteString("- " + inlineText(c) + "\n")
+		case atom.Ul, atom.Ol:
+			renderBlocks(b, c)
+			b.WriteString("\n")
+		case atom.Pre:
+			fmt.Fprintf(b, "```\n%s\n```\n\n", strings.TrimRight(textContent(c), "\n"))
+		default:
+			renderBlocks(b, c)
+		}
+	}
+}
+
+// inlineText returns the text of n on a single line, with inline code in
+// backticks.
+func inlineText(n *html.Node) string {
+	var parts []string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.TextNode:
+				parts = append(parts, c.Data)
+			case c.Type != html.ElementNode || skipped(c):
+			case c.DataAtom == atom.Code:
+				parts = append(parts, "`"+textContent(c)+"`")
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(strings.Join(parts, "")), " ")
+}
+
+// textContent returns the text of n as is.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testPage = `<html><head><script>var x;</script></head><body>
+<nav>Menu</nav>
+<div class="devsite-article-body">
+<h1>Known issues <button>link</button></h1>
+<p>This page lists   known issues.</p>
+<h2>Node pools</h2>
+<ul><li>Upgrade with <code>gcloud container node-pools upgrade</code>.</li><li>Check the version.</li></ul>
+<pre>gcloud container clusters list
+</pre>
+</div>
+<footer>Footer</footer>
+</body></html>`
+
+func TestHTMLToMarkdown(t *testing.T) {
+	got, err := htmlToMarkdown(strings.NewReader(testPage))
+	if err != nil {
+		t.Fatalf("htmlToMarkdown() failed: %v", err)
+	}
+	want := "# Known issues\n\nThis page lists known issues.\n\n## Node pools\n\n- Upgrade with `gcloud container node-pools upgrade`.\n- Check the version.\n\n```\ngcloud container clusters list\n```\n"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("htmlToMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDocURL(t *testing.T) {
+	for page, want := range map[string]string{
+		"troubleshooting/known-issues":        "https://cloud.google.com/kubernetes-engine/docs/troubleshooting/known-issues",
+		"/how-to/cost-allocations":            "https://cloud.google.com/kubernetes-engine/docs/how-to/cost-allocations",
+		"https://example.com/runbooks/a.html": "https://example.com/runbooks/a.html",
+	} {
+		if got, err := docURL(page); err != nil || got != want {
+			t.Errorf("docURL(%q) = %q, %v; want %q", page, got, err, want)
+		}
+	}
+	if _, err := docURL("ftp://example.com/a"); err == nil {
+		t.Errorf("docURL() of an FTP URL succeeded, want an error")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	requests := 0
+	online := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !online {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(testPage))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	f := &docFetcher{client: srv.Client(), dir: t.TempDir(), now: func() time.Time { return now }}
+	ctx := context.Background()
+	fetch := func(wantRequests int) {
+		t.Helper()
+		md, err := f.fetch(ctx, srv.URL, false)
+		if err != nil {
+			t.Fatalf("fetch() failed: %v", err)
+		}
+		if !strings.HasPrefix(md, "# Known issues") {
+			t.Errorf("fetch() = %q, want the page as markdown", md)
+		}
+		if requests != wantRequests {
+			t.Errorf("requests = %d, want %d", requests, wantRequests)
+		}
+	}
+
+	fetch(1)
+	// Recent pages come from the cache.
+	fetch(1)
+	// Older ones are revalidated.
+	now = now.Add(docsMaxAge)
+	fetch(2)
+	// The cached copy is used when the page can't be fetched.
+	now = now.Add(docsMaxAge)
+	online = false
+	fetch(3)
+}
@@ -0,0 +1,217 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	htransport "google.golang.org/api/transport/http"
+)
+
+const (
+	defaultVertexModel = "text-embedding-005"
+	defaultOllamaModel = "nomic-embed-text"
+	defaultOllamaHost  = "http://localhost:11434"
+
+	// vertexLocation is the Vertex AI region serving the embedding models.
+	vertexLocation = "us-central1"
+	// vertexBatchSize is the number of texts embedded per Vertex AI request.
+	vertexBatchSize = 16
+)
+
+// Embedder computes embeddings of text.
+type Embedder interface {
+	// EmbedDocuments returns the embeddings of texts to search.
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
+	// EmbedQuery returns the embedding of a search query.
+	EmbedQuery(ctx context.Context, query string) ([]float32, error)
+}
+
+// newEmbedder returns the embedder configured in c, or nil if keyword search
+// is used.
+func newEmbedder(c *config.Config) (Embedder, error) {
+	provider, model := c.Embeddings()
+	switch provider {
+	case "":
+		return nil, nil
+	case config.EmbeddingsVertex:
+		if model == "" {
+			model = defaultVertexModel
+		}
+		return &vertexEmbedder{c: c, model: model}, nil
+	case config.EmbeddingsOllama:
+		if model == "" {
+			model = defaultOllamaModel
+		}
+		host := os.Getenv("OLLAMA_HOST")
+		if host == "" {
+			host = defaultOllamaHost
+		} else if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		return &ollamaEmbedder{host: strings.TrimSuffix(host, "/"), model: model}, nil
+	default:
+		return nil, fmt.Errorf("unknown embeddings provider %q: must be %s or %s", provider, config.EmbeddingsVertex, config.EmbeddingsOllama)
+	}
+}
+
+// vertexEmbedder computes embeddings with a Vertex AI text embedding model.
+type vertexEmbedder struct {
+	c     *config.Config
+	model string
+}
+
+type vertexInstance struct {
+	Content  string `json:"content"`
+	TaskType string `json:"task_type"`
+}
+
+type vertexResponse struct {
+	Predictions []struct {
+		Embeddings struct {
+			Values []float32 `json:"values"`
+		} `json:"embeddings"`
+	} `json:"predictions"`
+}
+
+func (e *vertexEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.embed(ctx, texts, "RETRIEVAL_DOCUMENT")
+}
+
+func (e *vertexEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	vectors, err := e.embed(ctx, []string{query}, "RETRIEVAL_QUERY")
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func (e *vertexEmbedder) embed(ctx context.Context, texts []string, taskType string) ([][]float32, error) {
+	projectID := e.c.DefaultProjectID()
+	if projectID == "" {
+		return nil, fmt.Errorf("a default project is needed to call Vertex AI")
+	}
+	opts, err := auth.ClientOptions(ctx, e.c, config.APIAIPlatform)
+	if err != nil {
+		return nil, err
+	}
+	client, _, err := htransport.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vertex AI client: %w", err)
+	}
+	host := vertexLocation + "-aiplatform.googleapis.com"
+	if endpoint := e.c.Endpoint(config.APIAIPlatform); endpoint != "" {
+		host = endpoint
+	}
+	url := fmt.Sprintf("https://%s/v1/projects/%s/locations/%s/publishers/google/models/%s:predict", host, projectID, vertexLocation, e.model)
+
+	var vectors [][]float32
+	for start := 0; start < len(texts); start += vertexBatchSize {
+		var instances []vertexInstance
+		for _, text := range texts[start:min(start+vertexBatchSize, len(texts))] {
+			instances = append(instances, vertexInstance{Content: text, TaskType: taskType})
+		}
+		var resp vertexResponse
+		if err := postJSON(ctx, client, url, map[string]any{"instances": instances}, &resp); err != nil {
+			return nil, err
+		}
+		if len(resp.Predictions) != len(instances) {
+			return nil, fmt.Errorf("Vertex AI returned %d embeddings for %d texts", len(resp.Predictions), len(instances))
+		}
+		for _, p := range resp.Predictions {
+			vectors = append(vectors, p.Embeddings.Values)
+		}
+	}
+	return vectors, nil
+}
+
+// ollamaEmbedder computes embeddings with a model served by Ollama, e.g. on
+// the local machine.
+type ollamaEmbedder struct {
+	host  string
+	model string
+}
+
+func (e *ollamaEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := postJSON(ctx, http.DefaultClient, e.host+"/api/embed", map[string]any{"model": e.model, "input": texts}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("Ollama returned %d embeddings for %d texts", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, nil
+}
+
+func (e *ollamaEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	vectors, err := e.EmbedDocuments(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// postJSON posts body as JSON to url and decodes the JSON response into out.
+func postJSON(ctx context.Context, client *http.Client, url string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("POST %s: %s: %s", url, resp.Status, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 if
+// they can't be compared.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
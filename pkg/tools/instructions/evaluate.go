@@ -0,0 +1,132 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Judgment is a query and the titles of the sections that are relevant to
+// it.
+type Judgment struct {
+	Query    string   `yaml:"query" json:"query"`
+	Relevant []string `yaml:"relevant" json:"relevant"`
+}
+
+// Evaluation measures how well the instructions search ranks the relevant
+// sections of a set of judgments.
+type Evaluation struct {
+	// K is the number of results of each query that are considered.
+	K int
+	// MRR is the mean reciprocal rank of the first relevant section in the
+	// top K results, counting 0 for queries without any.
+	MRR float64
+	// Recall is the mean fraction of the relevant sections found in the top
+	// K results.
+	Recall float64
+	// Misses are the queries whose first result isn't relevant.
+	Misses []Miss
+}
+
+// Miss is a query whose first result isn't relevant.
+type Miss struct {
+	Query string
+	// Got are the titles of the top K results.
+	Got []string
+}
+
+// LoadJudgments reads judgments from a YAML or JSON file holding a list of
+// objects with a query and the titles of its relevant sections, like
+// testdata/judgments.yaml.
+func LoadJudgments(path string) ([]Judgment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var judgments []Judgment
+	if err := yaml.Unmarshal(data, &judgments); err != nil {
+		return nil, fmt.Errorf("failed to parse judgments in %s: %w", path, err)
+	}
+	for n, j := range judgments {
+		if j.Query == "" || len(j.Relevant) == 0 {
+			return nil, fmt.Errorf("judgment %d in %s needs a query and relevant sections", n+1, path)
+		}
+	}
+	return judgments, nil
+}
+
+// EvaluateRanking searches the instructions configured in c, with the
+// configured ranking weights and embeddings, for each judgment's query and
+// measures how well the top k results match the relevant sections. It lets
+// ranking changes be validated, and the weights be tuned for custom
+// instructions.
+func EvaluateRanking(ctx context.Context, c *config.Config, judgments []Judgment, k int) (Evaluation, error) {
+	if k <= 0 {
+		return Evaluation{}, fmt.Errorf("k must be positive, got %d", k)
+	}
+	embedder, err := newEmbedder(c)
+	if err != nil {
+		return Evaluation{}, err
+	}
+	idx, err := buildIndex(ctx, c, embedder, false)
+	if err != nil {
+		return Evaluation{}, err
+	}
+	return evaluate(ctx, idx, judgments, k), nil
+}
+
+// evaluate measures the ranking of idx against judgments.
+func evaluate(ctx context.Context, idx *index, judgments []Judgment, k int) Evaluation {
+	e := Evaluation{K: k}
+	if len(judgments) == 0 {
+		return e
+	}
+	for _, j := range judgments {
+		// Chunks of a section share its title, so the ranking is of
+		// distinct titles.
+		var got []string
+		for _, section := range idx.search(ctx, j.Query, len(idx.sections)) {
+			if !slices.Contains(got, section.Title) {
+				got = append(got, section.Title)
+			}
+			if len(got) == k {
+				break
+			}
+		}
+		found := 0
+		for rank, title := range got {
+			if !slices.Contains(j.Relevant, title) {
+				continue
+			}
+			if found == 0 {
+				e.MRR += 1 / float64(rank+1)
+			}
+			found++
+		}
+		e.Recall += float64(found) / float64(len(j.Relevant))
+		if len(got) == 0 || !slices.Contains(j.Relevant, got[0]) {
+			e.Misses = append(e.Misses, Miss{Query: j.Query, Got: got})
+		}
+	}
+	e.MRR /= float64(len(judgments))
+	e.Recall /= float64(len(judgments))
+	return e
+}
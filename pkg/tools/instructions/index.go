@@ -0,0 +1,256 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+)
+
+// queryEmbedTimeout bounds embedding a query, after which the keyword search
+// is used instead.
+const queryEmbedTimeout = 10 * time.Second
+
+// weights are the parameters of the keyword ranking.
+type weights struct {
+	// k1 and b are the BM25 parameters.
+	k1, b float64
+	// title and headings weigh the terms of a section's title and of the
+	// titles of its enclosing sections, on top of their occurrences in its
+	// content.
+	title, headings float64
+}
+
+// defaultWeights are the weights of a default configuration.
+var defaultWeights = weights{
+	k1:       config.DefaultBM25K1,
+	b:        config.DefaultBM25B,
+	title:    config.DefaultTitleWeight,
+	headings: config.DefaultHeadingsWeight,
+}
+
+// weightsFromConfig returns the ranking weights configured in c.
+func weightsFromConfig(c *config.Config) weights {
+	var w weights
+	w.k1, w.b = c.BM25()
+	w.title, w.headings = c.RankingWeights()
+	return w
+}
+
+// index ranks sections against queries.
+type index struct {
+	sections []Section
+	embedder Embedder
+
+	// slugs identify the sections in resource URIs.
+	slugs  []string
+	bySlug map[string]int
+
+	// Ranking weights and term statistics of the sections.
+	weights   weights
+	termFreqs []map[string]float64
+	lengths   []int
+	avgLength float64
+	docFreqs  map[string]int
+
+	mu      sync.RWMutex
+	vectors [][]float32
+}
+
+// newIndex indexes sections for BM25 keyword search with weights w, and for
+// semantic search if embedder isn't nil.
+func newIndex(sections []Section, embedder Embedder, w weights) *index {
+	i := &index{
+		sections:  sections,
+		embedder:  embedder,
+		weights:   w,
+		slugs:     make([]string, len(sections)),
+		bySlug:    map[string]int{},
+		termFreqs: make([]map[string]float64, len(sections)),
+		lengths:   make([]int, len(sections)),
+		docFreqs:  map[string]int{},
+	}
+	// The bundled sections were tokenized by go generate.
+	prebuilt := bundledTerms(sections)
+	total := 0
+	for n, section := range sections {
+		slug := sectionSlug(section)
+		for k := 2; ; k++ {
+			if _, taken := i.bySlug[slug]; !taken {
+				break
+			}
+			slug = fmt.Sprintf("%s-%d", sectionSlug(section), k)
+		}
+		i.slugs[n] = slug
+		i.bySlug[slug] = n
+
+		var t sectionTerms
+		if n < len(prebuilt) {
+			t = prebuilt[n]
+		} else {
+			t = analyze(section)
+		}
+		freqs := map[string]float64{}
+		for term, count := range t.content {
+			freqs[term] += float64(count)
+		}
+		// Headings matter more than the text under them.
+		for term, count := range t.title {
+			freqs[term] += float64(count) * w.title
+		}
+		for term, count := range t.headings {
+			freqs[term] += float64(count) * w.headings
+		}
+		for term := range freqs {
+			i.docFreqs[term]++
+		}
+		i.termFreqs[n] = freqs
+		i.lengths[n] = t.length
+		total += t.length
+	}
+	if len(sections) > 0 {
+		i.avgLength = float64(total) / float64(len(sections))
+	}
+	return i
+}
+
+// sectionTerms counts the terms of a section by where they occur.
+type sectionTerms struct {
+	content, title, headings map[string]int
+	// length is the number of terms of the content.
+	length int
+}
+
+func newSectionTerms() sectionTerms {
+	return sectionTerms{content: map[string]int{}, title: map[string]int{}, headings: map[string]int{}}
+}
+
+// analyze tokenizes the content, the title and the enclosing headings of
+// section.
+func analyze(section Section) sectionTerms {
+	t := newSectionTerms()
+	tokens := tokenize(section.Content)
+	for _, token := range tokens {
+		t.content[token]++
+	}
+	for _, token := range tokenize(section.Title) {
+		t.title[token]++
+	}
+	if len(section.Headings) > 1 {
+		for _, token := range tokenize(strings.Join(section.Headings[:len(section.Headings)-1], "\n")) {
+			t.headings[token]++
+		}
+	}
+	t.length = len(tokens)
+	return t
+}
+
+// embed computes the embeddings of every section, enabling the semantic
+// search.
+func (i *index) embed(ctx context.Context) error {
+	texts := make([]string, len(i.sections))
+	for n, section := range i.sections {
+		texts[n] = section.Content
+	}
+	vectors, err := i.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.vectors = vectors
+	return nil
+}
+
+// search returns up to n sections relevant to query, the most relevant
+// first. It ranks them by embedding similarity when the embeddings are
+// available, and by keyword matches otherwise.
+func (i *index) search(ctx context.Context, query string, n int) []Section {
+	i.mu.RLock()
+	vectors := i.vectors
+	i.mu.RUnlock()
+	if vectors != nil {
+		ctx, cancel := context.WithTimeout(ctx, queryEmbedTimeout)
+		defer cancel()
+		q, err := i.embedder.EmbedQuery(ctx, query)
+		if err == nil {
+			return i.top(n, func(n int) float64 { return cosineSimilarity(q, vectors[n]) })
+		}
+		slog.Warn("Failed to embed instructions query, falling back to keyword search", "err", err)
+	}
+	terms := slices.Compact(slices.Sorted(slices.Values(tokenize(query))))
+	return i.top(n, func(n int) float64 { return i.calculateRelevanceScore(n, terms) })
+}
+
+// top returns the n sections with the highest positive score.
+func (i *index) top(n int, score func(int) float64) []Section {
+	type scored struct {
+		section Section
+		score   float64
+	}
+	var ranked []scored
+	for idx, section := range i.sections {
+		if s := score(idx); s > 0 {
+			ranked = append(ranked, scored{section, s})
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b scored) int { return cmp.Compare(b.score, a.score) })
+	var sections []Section
+	for _, r := range ranked[:min(n, len(ranked))] {
+		sections = append(sections, r.section)
+	}
+	return sections
+}
+
+// titles returns the titles of every section, once.
+func (i *index) titles() []string {
+	var titles []string
+	for _, section := range i.sections {
+		titles = append(titles, section.Title)
+	}
+	// Chunks of a section are next to each other.
+	return slices.Compact(titles)
+}
+
+// calculateRelevanceScore returns the BM25 score of section doc for the
+// distinct query terms.
+func (i *index) calculateRelevanceScore(doc int, terms []string) float64 {
+	if i.avgLength == 0 {
+		return 0
+	}
+	n := float64(len(i.sections))
+	k1, b := i.weights.k1, i.weights.b
+	norm := k1 * (1 - b + b*float64(i.lengths[doc])/i.avgLength)
+	score := 0.0
+	for _, term := range terms {
+		tf := i.termFreqs[doc][term]
+		if tf == 0 {
+			continue
+		}
+		df := float64(i.docFreqs[term])
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		score += idf * tf * (k1 + 1) / (tf + norm)
+	}
+	return score
+}
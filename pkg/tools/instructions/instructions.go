@@ -0,0 +1,302 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instructions lets the agent search the bundled GKE instructions
+// with the get_instructions tool, instead of reading all of them up front.
+// Every section is also published as a resource, for clients that prefer
+// browsing resources.
+//
+// The bundled instructions, the sources registered by other packages with
+// RegisterSource, the markdown files of the directory set with
+// --instructions-dir and the GKE documentation pages set with
+// --instructions-docs are split into sections at their headings. Sections are
+// ranked by keyword matches or, when configured, by the similarity of their
+// embeddings to the query's.
+//
+// The bundled instructions are parsed and tokenized ahead of time into
+// bundled_index.json: run go generate after editing pkg/install/GEMINI.md.
+package instructions
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultMaxSections = 3
+
+	// bundledSource is the source of the bundled instructions.
+	bundledSource = "GEMINI.md"
+
+	// remoteTimeout bounds fetching the documentation pages and computing
+	// the embeddings of every section at startup.
+	remoteTimeout = 2 * time.Minute
+)
+
+// Section is a part of the instructions under a single heading, or a chunk
+// of it if it is long.
+type Section struct {
+	// Source is the file the section comes from, relative to the
+	// instructions directory, or the URL of a documentation page.
+	Source string
+	Title  string
+	Level  int
+	// Headings are the titles of the enclosing sections, outermost first,
+	// followed by Title.
+	Headings []string
+	// Part is the 1-based number of the chunk, or 0 if the section isn't
+	// split.
+	Part    int
+	Content string
+}
+
+type handlers struct {
+	s        *server.MCPServer
+	c        *config.Config
+	embedder Embedder
+	index    atomic.Pointer[index]
+
+	// reloadMu serializes reloads of the index.
+	reloadMu sync.Mutex
+
+	// publishMu guards published, the URIs of the sections published as
+	// resources.
+	publishMu sync.Mutex
+	published map[string]bool
+}
+
+func init() {
+	plugin.Register(plugin.New("instructions", Install))
+}
+
+func Install(ctx context.Context, s *server.MCPServer, c *config.Config) error {
+	embedder, err := newEmbedder(c)
+	if err != nil {
+		return err
+	}
+	h := &handlers{
+		s:        s,
+		c:        c,
+		embedder: embedder,
+	}
+	sections, err := loadSections(ctx, c.InstructionsDir())
+	if err != nil {
+		return err
+	}
+	h.publish(h.newIndex(sections))
+
+	// Documentation pages and embeddings are slow to get, so the local
+	// instructions are searched by keywords until they are ready.
+	remote := embedder != nil || len(c.InstructionsDocs()) > 0
+	if remote || c.InstructionsDir() != "" {
+		go func() {
+			if remote {
+				reloadCtx, cancel := context.WithTimeout(ctx, remoteTimeout)
+				if _, err := h.reload(reloadCtx, false); err != nil {
+					slog.Warn("Failed to load instructions", "err", err)
+				}
+				cancel()
+			}
+			h.watch(ctx)
+		}()
+	}
+
+	getInstructionsTool := mcp.NewTool("get_instructions",
+		mcp.WithDescription("Search the GKE instructions for guidance on a task, e.g. how to analyze GKE costs, query GKE logs or check a cluster against known issues. Call it before starting a GKE task you don't have instructions for."),
+		catalog.Describe(catalog.Guidance, catalog.Local),
+		mcp.WithString("query", mcp.Required(), mcp.Description("What you need instructions for, in natural language.")),
+		mcp.WithNumber("max_sections", mcp.Description(fmt.Sprintf("Maximum number of sections to return. Defaults to %d.", defaultMaxSections))),
+	)
+	s.AddTool(getInstructionsTool, h.getInstructions)
+
+	reloadInstructionsTool := mcp.NewTool("reload_instructions",
+		mcp.WithDescription("Reload the GKE instructions from the instructions directory and refetch the documentation pages, e.g. after the user edited their runbooks. Changes are otherwise picked up within a few seconds."),
+		catalog.Describe(catalog.Guidance, catalog.Local),
+	)
+	s.AddTool(reloadInstructionsTool, h.reloadInstructions)
+
+	return nil
+}
+
+func (h *handlers) getInstructions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := request.RequireString("query")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	maxSections := request.GetInt("max_sections", defaultMaxSections)
+	if maxSections <= 0 {
+		return mcp.NewToolResultError("max_sections must be positive"), nil
+	}
+
+	idx := h.index.Load()
+	sections := idx.search(ctx, query, maxSections)
+	if len(sections) == 0 {
+		return mcp.NewToolResultText("No instructions matched the query. Available sections: " + strings.Join(idx.titles(), ", ")), nil
+	}
+	var contents []string
+	for _, section := range sections {
+		var notes []string
+		if section.Source != bundledSource {
+			notes = append(notes, "From "+section.Source)
+		}
+		if len(section.Headings) > 1 {
+			notes = append(notes, "In "+strings.Join(section.Headings[:len(section.Headings)-1], " > "))
+		}
+		content := section.Content
+		if len(notes) > 0 {
+			content = fmt.Sprintf("(%s)\n\n%s", strings.Join(notes, ". "), content)
+		}
+		contents = append(contents, content)
+	}
+	return mcp.NewToolResultText(strings.Join(contents, "\n\n---\n\n")), nil
+}
+
+func (h *handlers) newIndex(sections []Section) *index {
+	return newIndex(sections, h.embedder, weightsFromConfig(h.c))
+}
+
+// loadSections returns the sections of the bundled instructions, of the
+// registered sources and of the markdown files in dir and its
+// subdirectories, if dir isn't empty.
+func loadSections(ctx context.Context, dir string) ([]Section, error) {
+	bundledSections, _ := bundled()
+	sections := slices.Clone(bundledSections)
+	sections = append(sections, registeredSections(ctx)...)
+	if dir == "" {
+		return sections, nil
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		sections = append(sections, parseMarkdown(filepath.ToSlash(rel), string(data))...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load instructions from %s: %w", dir, err)
+	}
+	return sections, nil
+}
+
+// loadDocs fetches the documentation pages and returns their sections.
+// Pages that can't be fetched are skipped. refresh revalidates cached pages
+// even if they are recent.
+func loadDocs(ctx context.Context, pages []string, refresh bool) []Section {
+	if len(pages) == 0 {
+		return nil
+	}
+	fetcher, err := newDocFetcher()
+	if err != nil {
+		slog.Warn("Failed to set up the documentation cache", "err", err)
+		return nil
+	}
+	var sections []Section
+	for _, page := range pages {
+		url, err := docURL(page)
+		if err != nil {
+			slog.Warn("Skipping documentation page", "err", err)
+			continue
+		}
+		md, err := fetcher.fetch(ctx, url, refresh)
+		if err != nil {
+			slog.Warn("Failed to fetch documentation page", "url", url, "err", err)
+			continue
+		}
+		sections = append(sections, parseMarkdown(url, md)...)
+	}
+	return sections
+}
+
+// parseMarkdown splits a markdown document read from source into sections
+// at its headings, and long sections into chunks. Text before the first
+// heading is a section titled after the source, and headings without any
+// content are dropped.
+func parseMarkdown(source, md string) []Section {
+	var sections []Section
+	current := &Section{Source: source, Title: source, Headings: []string{source}}
+	var headings []Section
+	var body strings.Builder
+	inCode := false
+	flush := func() {
+		if strings.TrimSpace(body.String()) != "" {
+			sections = append(sections, chunk(*current, body.String())...)
+		}
+		body.Reset()
+	}
+	for _, line := range strings.Split(md, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if level := headingLevel(line); level > 0 && !inCode {
+			flush()
+			for len(headings) > 0 && headings[len(headings)-1].Level >= level {
+				headings = headings[:len(headings)-1]
+			}
+			current = &Section{
+				Source:  source,
+				Title:   strings.TrimSpace(line[level:]),
+				Level:   level,
+				Content: line,
+			}
+			headings = append(headings, *current)
+			for _, h := range headings {
+				current.Headings = append(current.Headings, h.Title)
+			}
+			continue
+		}
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+	flush()
+	return sections
+}
+
+// headingLevel returns the level of a markdown heading line, or 0 if line
+// isn't a heading.
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level == len(line) || line[level] != ' ' {
+		return 0
+	}
+	return level
+}
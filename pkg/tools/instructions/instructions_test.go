@@ -0,0 +1,296 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/install"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const testMarkdown = `# Title
+
+Intro.
+
+## GKE Logs
+
+Use query_logs to search logs.
+
+## Empty
+
+## GKE Cost
+
+Costs come from the billing export.
+
+` + "```sh\n# not a heading\n```" + `
+
+### Cost Queries
+
+Query the billing table in BigQuery.
+`
+
+func TestParseMarkdown(t *testing.T) {
+	want := []Section{
+		{Source: "test.md", Title: "Title", Level: 1, Headings: []string{"Title"}, Content: "# Title\n\nIntro."},
+		{Source: "test.md", Title: "GKE Logs", Level: 2, Headings: []string{"Title", "GKE Logs"}, Content: "## GKE Logs\n\nUse query_logs to search logs."},
+		{Source: "test.md", Title: "GKE Cost", Level: 2, Headings: []string{"Title", "GKE Cost"}, Content: "## GKE Cost\n\nCosts come from the billing export.\n\n```sh\n# not a heading\n```"},
+		{Source: "test.md", Title: "Cost Queries", Level: 3, Headings: []string{"Title", "GKE Cost", "Cost Queries"}, Content: "### Cost Queries\n\nQuery the billing table in BigQuery."},
+	}
+	if diff := cmp.Diff(want, parseMarkdown("test.md", testMarkdown)); diff != "" {
+		t.Errorf("parseMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadSections(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"upgrades.md":           "Upgrade staging first.\n\n## Rollback\n\nPage the on-call.\n",
+		"team/networking.MD":    "# Ingress\n\nUse the shared gateway.\n",
+		"team/not-markdown.txt": "# Ignored\n\nNot markdown.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sections, err := loadSections(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("loadSections() failed: %v", err)
+	}
+	bundled := parseMarkdown(bundledSource, string(install.GeminiMarkdown))
+	want := append(bundled,
+		Section{Source: "team/networking.MD", Title: "Ingress", Level: 1, Headings: []string{"Ingress"}, Content: "# Ingress\n\nUse the shared gateway."},
+		Section{Source: "upgrades.md", Title: "upgrades.md", Headings: []string{"upgrades.md"}, Content: "Upgrade staging first."},
+		Section{Source: "upgrades.md", Title: "Rollback", Level: 2, Headings: []string{"Rollback"}, Content: "## Rollback\n\nPage the on-call."},
+	)
+	if diff := cmp.Diff(want, sections); diff != "" {
+		t.Errorf("loadSections() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := loadSections(context.Background(), filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("loadSections() of a missing directory succeeded, want an error")
+	}
+}
+
+// fakeEmbedder embeds text by counting its words in dimensions, where
+// synonyms share a dimension.
+type fakeEmbedder struct {
+	dims map[string]int
+	err  error
+}
+
+func (e *fakeEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	var vectors [][]float32
+	for _, text := range texts {
+		v := make([]float32, 2)
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+			if dim, ok := e.dims[word]; ok {
+				v[dim]++
+			}
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+func (e *fakeEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	vectors, err := e.EmbedDocuments(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func titles(sections []Section) []string {
+	var titles []string
+	for _, s := range sections {
+		titles = append(titles, s.Title)
+	}
+	return titles
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	sections := parseMarkdown("test.md", testMarkdown)
+
+	keyword := newIndex(sections, nil, defaultWeights)
+	if diff := cmp.Diff([]string{"GKE Cost", "Cost Queries"}, titles(keyword.search(ctx, "the billing export", 3))); diff != "" {
+		t.Errorf("keyword search mismatch (-want +got):\n%s", diff)
+	}
+	if got := keyword.search(ctx, "unrelated", 3); len(got) != 0 {
+		t.Errorf("keyword search for an unknown term = %v, want nothing", titles(got))
+	}
+
+	// "warehouse" never appears in the sections, but the embeddings relate
+	// it to BigQuery.
+	embedder := &fakeEmbedder{dims: map[string]int{"bigquery": 0, "warehouse": 0, "logs": 1}}
+	semantic := newIndex(sections, embedder, defaultWeights)
+	if err := semantic.embed(ctx); err != nil {
+		t.Fatalf("embed() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"Cost Queries"}, titles(semantic.search(ctx, "warehouse", 1))); diff != "" {
+		t.Errorf("semantic search mismatch (-want +got):\n%s", diff)
+	}
+
+	// Queries fall back to keywords when they can't be embedded.
+	embedder.err = errors.New("offline")
+	if diff := cmp.Diff([]string{"GKE Logs"}, titles(semantic.search(ctx, "logs", 1))); diff != "" {
+		t.Errorf("search while offline mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Upgrading the node pools", []string{"upgrad", "nodepool"}},
+		{"upgrade a nodepool", []string{"upgrad", "nodepool"}},
+		{"Logs of the HPA", []string{"log", "hpa"}},
+		{"logging for the Horizontal Pod Autoscaler", []string{"log", "hpa"}},
+		{"CA is scaling up", []string{"autoscaler", "scal", "up"}},
+		{"cluster autoscaler", []string{"autoscaler"}},
+		{"internal LB", []string{"internal", "lb"}},
+		{"internal load balancers", []string{"internal", "lb"}},
+		{"what do my clusters spend", []string{"cluster", "cost"}},
+	}
+	for _, tc := range tests {
+		if diff := cmp.Diff(tc.want, tokenize(tc.text)); diff != "" {
+			t.Errorf("tokenize(%q) mismatch (-want +got):\n%s", tc.text, diff)
+		}
+	}
+}
+
+func TestRankingQuality(t *testing.T) {
+	judgments, err := LoadJudgments("testdata/judgments.yaml")
+	if err != nil {
+		t.Fatalf("LoadJudgments() failed: %v", err)
+	}
+	i := newIndex(parseMarkdown(bundledSource, string(install.GeminiMarkdown)), nil, defaultWeights)
+	e := evaluate(context.Background(), i, judgments, 3)
+	for _, m := range e.Misses {
+		t.Errorf("search(%q) = %q, want a relevant section first", m.Query, m.Got)
+	}
+	if e.Recall < 0.9 {
+		t.Errorf("evaluate() recall@%d = %.2f, want at least 0.9", e.K, e.Recall)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	sections := parseMarkdown("runbook.md", `# Node upgrades
+
+Drain nodes before upgrading them.
+
+# Node pools
+
+Node pools group nodes with the same machine type.
+
+# Quotas
+
+Request more CPU quota before scaling.
+`)
+	i := newIndex(sections, nil, defaultWeights)
+	judgments := []Judgment{
+		{Query: "upgrading nodes", Relevant: []string{"Node upgrades"}},
+		{Query: "node pool machine type", Relevant: []string{"Quotas", "Node pools"}},
+		{Query: "cpu quota", Relevant: []string{"Node upgrades"}},
+	}
+	want := Evaluation{
+		K:      2,
+		MRR:    (1 + 1 + 0) / 3.0,
+		Recall: (1 + 0.5 + 0) / 3.0,
+		Misses: []Miss{{Query: "cpu quota", Got: []string{"Quotas"}}},
+	}
+	if diff := cmp.Diff(want, evaluate(context.Background(), i, judgments, 2), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("evaluate() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTitleWeight(t *testing.T) {
+	sections := parseMarkdown("runbook.md", `# Autoscaling
+
+Scale node pools with the number of pending pods.
+
+# Costs
+
+Autoscaling keeps costs down. Autoscaling removes idle nodes, and
+autoscaling node pools shrink at night.
+`)
+	tests := []struct {
+		title float64
+		want  string
+	}{
+		{title: 0, want: "Costs"},
+		{title: 3, want: "Autoscaling"},
+	}
+	for _, tc := range tests {
+		w := defaultWeights
+		w.title = tc.title
+		got := newIndex(sections, nil, w).search(context.Background(), "autoscaling", 1)
+		if len(got) != 1 || got[0].Title != tc.want {
+			t.Errorf("search() with title weight %v = %q, want %q", tc.title, titles(got), tc.want)
+		}
+	}
+}
+
+func TestLoadJudgments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "judgments.json")
+	if err := os.WriteFile(path, []byte(`[{"query": "logs", "relevant": ["GKE Logs"]}, {"query": "cost"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadJudgments(path); err == nil {
+		t.Errorf("LoadJudgments() of a judgment without relevant sections succeeded, want an error")
+	}
+}
+
+func TestRegisterSource(t *testing.T) {
+	saved := sources
+	t.Cleanup(func() { sources = saved })
+	sources = nil
+
+	RegisterSource(MarkdownSource("cost guidance", "# Rightsizing\n\nLower the requests of idle workloads.\n"))
+	sections, err := loadSections(context.Background(), "")
+	if err != nil {
+		t.Fatalf("loadSections() failed: %v", err)
+	}
+	i := newIndex(sections, nil, defaultWeights)
+	got := i.search(context.Background(), "rightsizing idle workloads", 1)
+	if len(got) != 1 || got[0].Source != "cost guidance" || got[0].Title != "Rightsizing" {
+		t.Errorf("search() = %+v, want the section of the registered source", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterSource() of a duplicate name didn't panic")
+		}
+	}()
+	RegisterSource(MarkdownSource("cost guidance", ""))
+}
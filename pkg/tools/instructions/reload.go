@@ -0,0 +1,143 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// watchInterval is how often the instructions directory is checked for
+// changes.
+const watchInterval = 5 * time.Second
+
+func (h *handlers) reloadInstructions(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	n, err := h.reload(ctx, true)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Reloaded %d instruction sections.", n)), nil
+}
+
+// reload rebuilds the index from every source and returns its number of
+// sections. refresh revalidates the documentation pages even if they are
+// recent. The current index is kept if the instructions directory can't be
+// read.
+func (h *handlers) reload(ctx context.Context, refresh bool) (int, error) {
+	h.reloadMu.Lock()
+	defer h.reloadMu.Unlock()
+
+	idx, err := buildIndex(ctx, h.c, h.embedder, refresh)
+	if err != nil {
+		return 0, err
+	}
+	h.publish(idx)
+	return len(idx.sections), nil
+}
+
+// buildIndex indexes the sections of every source configured in c, with
+// their embeddings if embedder isn't nil. refresh revalidates the
+// documentation pages even if they are recent.
+func buildIndex(ctx context.Context, c *config.Config, embedder Embedder, refresh bool) (*index, error) {
+	sections, err := loadSections(ctx, c.InstructionsDir())
+	if err != nil {
+		return nil, err
+	}
+	sections = append(sections, loadDocs(ctx, c.InstructionsDocs(), refresh)...)
+	idx := newIndex(sections, embedder, weightsFromConfig(c))
+	if embedder != nil {
+		if err := idx.embed(ctx); err != nil {
+			slog.Warn("Failed to compute instruction embeddings, falling back to keyword search", "err", err)
+		}
+	}
+	return idx, nil
+}
+
+// watch reloads the index when the files of the instructions directory
+// change, and when the documentation pages are due for revalidation, until
+// ctx is done.
+func (h *handlers) watch(ctx context.Context) {
+	dir := h.c.InstructionsDir()
+	hasDocs := len(h.c.InstructionsDocs()) > 0
+	if dir == "" && !hasDocs {
+		return
+	}
+	last := fingerprint(dir)
+	docsLoaded := time.Now()
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current := fingerprint(dir)
+		docsDue := hasDocs && time.Since(docsLoaded) >= docsMaxAge
+		if current == last && !docsDue {
+			continue
+		}
+		reloadCtx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		n, err := h.reload(reloadCtx, false)
+		cancel()
+		if err != nil {
+			slog.Warn("Failed to reload instructions", "err", err)
+			continue
+		}
+		last = current
+		if docsDue {
+			docsLoaded = time.Now()
+		}
+		slog.Info("Reloaded instructions", "sections", n)
+	}
+}
+
+// fingerprint returns a digest of the names, sizes and modification times of
+// the markdown files in dir, which changes when any of them does.
+func fingerprint(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(h, "error: %v", err)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "runbook.md")
+	if err := os.WriteFile(path, []byte("# Rollback\n\nPage the on-call.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h := &handlers{
+		s: server.NewMCPServer("test", "0.0.0"),
+		c: config.New("test", config.WithInstructionsDir(dir)),
+	}
+	if _, err := h.reload(ctx, false); err != nil {
+		t.Fatalf("reload() failed: %v", err)
+	}
+	before := fingerprint(dir)
+	if got := titles(h.index.Load().search(ctx, "quarantine", 1)); len(got) != 0 {
+		t.Fatalf("search() before the change = %q, want nothing", got)
+	}
+
+	if err := os.WriteFile(path, []byte("# Quarantine\n\nCordon the node.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Make sure the modification time changes on coarse-grained file systems.
+	if err := os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if fingerprint(dir) == before {
+		t.Errorf("fingerprint() didn't change after a file changed")
+	}
+	if _, err := h.reload(ctx, false); err != nil {
+		t.Fatalf("reload() failed: %v", err)
+	}
+	if got := titles(h.index.Load().search(ctx, "quarantine", 1)); len(got) != 1 || got[0] != "Quarantine" {
+		t.Errorf("search() after the change = %q, want the new section", got)
+	}
+
+	// The index is kept if the directory can't be read.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.reload(ctx, false); err == nil {
+		t.Errorf("reload() of a removed directory succeeded, want an error")
+	}
+	if got := titles(h.index.Load().search(ctx, "quarantine", 1)); len(got) != 1 {
+		t.Errorf("search() after a failed reload = %q, want the previous index", got)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// resourcePrefix is the URI prefix of the sections published as resources.
+const resourcePrefix = "gke-mcp://instructions/"
+
+// sectionSlug returns the identifier of a section in resource URIs, made of
+// its source, unless it is bundled, and its title. Sections with the same
+// title are told apart when the index is built.
+func sectionSlug(section Section) string {
+	parts := []string{section.Title}
+	if section.Source != bundledSource {
+		parts = append([]string{section.Source}, parts...)
+	}
+	if section.Part > 0 {
+		parts = append(parts, fmt.Sprintf("part %d", section.Part))
+	}
+	return slugify(strings.Join(parts, " "))
+}
+
+// slugify lowercases s and replaces every run of characters other than
+// letters and digits with a dash.
+func slugify(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+}
+
+// publish makes idx the current index and publishes its sections as
+// resources, removing the resources of sections that no longer exist.
+func (h *handlers) publish(idx *index) {
+	h.publishMu.Lock()
+	defer h.publishMu.Unlock()
+	h.index.Store(idx)
+
+	uris := map[string]bool{}
+	var resources []server.ServerResource
+	for n, section := range idx.sections {
+		uri := resourcePrefix + idx.slugs[n]
+		uris[uri] = true
+		name := strings.Join(section.Headings, " > ")
+		if section.Part > 0 {
+			name += fmt.Sprintf(" (part %d)", section.Part)
+		}
+		description := "GKE instructions"
+		if section.Source != bundledSource {
+			description += " from " + section.Source
+		}
+		resources = append(resources, server.ServerResource{
+			Resource: mcp.NewResource(uri, name,
+				mcp.WithResourceDescription(description),
+				mcp.WithMIMEType("text/markdown"),
+			),
+			Handler: h.readSection,
+		})
+	}
+	for uri := range h.published {
+		if !uris[uri] {
+			h.s.RemoveResource(uri)
+		}
+	}
+	h.s.AddResources(resources...)
+	h.published = uris
+}
+
+func (h *handlers) readSection(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	idx := h.index.Load()
+	n, ok := idx.bySlug[strings.TrimPrefix(request.Params.URI, resourcePrefix)]
+	if !ok {
+		return nil, fmt.Errorf("instructions section %s not found: it was removed since the resources were listed", request.Params.URI)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "text/markdown",
+			Text:     idx.sections[n].Content,
+		},
+	}, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSectionSlug(t *testing.T) {
+	for _, tc := range []struct {
+		section Section
+		want    string
+	}{
+		{Section{Source: bundledSource, Title: "GKE Cost"}, "gke-cost"},
+		{Section{Source: bundledSource, Title: "How to Interpret Version Ranges", Part: 2}, "how-to-interpret-version-ranges-part-2"},
+		{Section{Source: "team/upgrades.md", Title: "Rollback"}, "team-upgrades-md-rollback"},
+	} {
+		if got := sectionSlug(tc.section); got != tc.want {
+			t.Errorf("sectionSlug(%+v) = %q, want %q", tc.section, got, tc.want)
+		}
+	}
+}
+
+func listResources(t *testing.T, s *server.MCPServer) []string {
+	t.Helper()
+	resp, ok := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("resources/list failed")
+	}
+	var uris []string
+	for _, r := range resp.Result.(mcp.ListResourcesResult).Resources {
+		uris = append(uris, r.URI)
+	}
+	return uris
+}
+
+func TestResources(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "runbook.md")
+	if err := os.WriteFile(path, []byte("# Rollback\n\nPage the on-call.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := server.NewMCPServer("test", "0.0.0")
+	h := &handlers{s: s, c: config.New("test", config.WithInstructionsDir(dir))}
+	if _, err := h.reload(ctx, false); err != nil {
+		t.Fatalf("reload() failed: %v", err)
+	}
+
+	uris := listResources(t, s)
+	for _, want := range []string{resourcePrefix + "gke-cost-part-1", resourcePrefix + "runbook-md-rollback"} {
+		if !slices.Contains(uris, want) {
+			t.Errorf("resources %q don't include %s", uris, want)
+		}
+	}
+	resp, ok := s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"gke-mcp://instructions/runbook-md-rollback"}}`)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("resources/read failed")
+	}
+	contents := resp.Result.(mcp.ReadResourceResult).Contents
+	if text := contents[0].(mcp.TextResourceContents).Text; text != "# Rollback\n\nPage the on-call." {
+		t.Errorf("resources/read = %q, want the section", text)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.reload(ctx, false); err != nil {
+		t.Fatalf("reload() failed: %v", err)
+	}
+	if uris := listResources(t, s); slices.Contains(uris, resourcePrefix+"runbook-md-rollback") {
+		t.Errorf("resources after the file was removed %q, want the section removed", uris)
+	}
+}
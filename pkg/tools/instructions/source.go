@@ -0,0 +1,89 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// Source contributes sections to the instructions searched by
+// get_instructions, e.g. guidance specific to the tools of a package.
+type Source interface {
+	// Name identifies the source. It is shown with its sections.
+	Name() string
+	// Sections returns the sections of the source. It is called again
+	// whenever the instructions are reloaded.
+	Sections(ctx context.Context) ([]Section, error)
+}
+
+var (
+	sourcesMu sync.Mutex
+	sources   []Source
+)
+
+// RegisterSource adds a source to the instructions. It is meant to be called
+// from the init function of the package contributing the instructions;
+// sources registered after the server started are included the next time
+// the instructions are reloaded. It panics if a source with the same name is
+// already registered.
+func RegisterSource(s Source) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if slices.ContainsFunc(sources, func(r Source) bool { return r.Name() == s.Name() }) {
+		panic(fmt.Sprintf("instructions: source %q registered twice", s.Name()))
+	}
+	sources = append(sources, s)
+}
+
+// registeredSections returns the sections of every registered source. Sources
+// that fail are skipped.
+func registeredSections(ctx context.Context) []Section {
+	sourcesMu.Lock()
+	registered := slices.Clone(sources)
+	sourcesMu.Unlock()
+
+	var sections []Section
+	for _, s := range registered {
+		ss, err := s.Sections(ctx)
+		if err != nil {
+			slog.Warn("Failed to load instructions", "source", s.Name(), "err", err)
+			continue
+		}
+		sections = append(sections, ss...)
+	}
+	return sections
+}
+
+// MarkdownSource returns a source of the sections of a markdown document.
+func MarkdownSource(name, md string) Source {
+	return markdownSource{name: name, md: md}
+}
+
+type markdownSource struct {
+	name string
+	md   string
+}
+
+func (s markdownSource) Name() string {
+	return s.name
+}
+
+func (s markdownSource) Sections(context.Context) ([]Section, error) {
+	return parseMarkdown(s.name, s.md), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instructions
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are ignored when matching keywords.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"can": true, "do": true, "does": true, "for": true, "from": true, "how": true, "i": true,
+	"in": true, "is": true, "it": true, "my": true, "of": true, "on": true, "or": true, "the": true,
+	"to": true, "what": true, "when": true, "which": true, "with": true, "you": true,
+}
+
+// synonymGroups are terms with the same meaning for GKE users. Each group is
+// matched as its first term, which must be a single word.
+var synonymGroups = [][]string{
+	{"autoscaler", "ca", "cluster autoscaler", "autoscaling"},
+	{"hpa", "horizontal pod autoscaler", "horizontal pod autoscaling"},
+	{"vpa", "vertical pod autoscaler", "vertical pod autoscaling"},
+	{"lb", "load balancer", "load balancing", "loadbalancer"},
+	{"log", "logging", "stackdriver"},
+	{"monitoring", "metric", "metrics"},
+	{"cost", "spend", "spending", "price", "pricing"},
+	{"k8s", "kubernetes"},
+	{"nodepool", "node pool"},
+	{"controlplane", "control plane", "master"},
+	{"auth", "authentication", "authenticate", "credentials", "login"},
+	{"gpu", "accelerator", "tpu"},
+	{"error", "failure", "failing"},
+	{"cve", "vulnerability", "security bulletin"},
+}
+
+// maxPhraseWords is the length of the longest synonym.
+const maxPhraseWords = 3
+
+// synonyms maps the stems of each synonym, joined by spaces, to the stem of
+// the first term of its group.
+var synonyms = buildSynonyms(synonymGroups)
+
+func buildSynonyms(groups [][]string) map[string]string {
+	m := map[string]string{}
+	for _, group := range groups {
+		canonical := stem(group[0])
+		for _, term := range group {
+			var stems []string
+			for _, word := range strings.Fields(term) {
+				stems = append(stems, stem(word))
+			}
+			m[strings.Join(stems, " ")] = canonical
+		}
+	}
+	return m
+}
+
+// tokenize splits text into lowercase word stems, dropping stop words and
+// replacing synonyms, including phrases, with a single term.
+func tokenize(text string) []string {
+	var stems []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !stopWords[word] {
+			stems = append(stems, stem(word))
+		}
+	}
+
+	var tokens []string
+	for i := 0; i < len(stems); {
+		n := 1
+		token := stems[i]
+		for words := min(maxPhraseWords, len(stems)-i); words > 0; words-- {
+			if canonical, ok := synonyms[strings.Join(stems[i:i+words], " ")]; ok {
+				n, token = words, canonical
+				break
+			}
+		}
+		tokens = append(tokens, token)
+		i += n
+	}
+	return tokens
+}
+
+// stem strips common English inflections from a lowercase word, so e.g.
+// "upgrade", "upgrades" and "upgrading" match. It is a much simplified
+// Porter stemmer: precise enough to match words, but stems aren't always
+// words themselves.
+func stem(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "sses"):
+		word = strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ies"):
+		word = strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		word = strings.TrimSuffix(word, "s")
+	}
+	for _, suffix := range []string{"ing", "ed"} {
+		if base := strings.TrimSuffix(word, suffix); base != word && len(base) >= 3 && hasVowel(base) {
+			word = undouble(base)
+			break
+		}
+	}
+	if len(word) > 4 && strings.HasSuffix(word, "e") {
+		word = strings.TrimSuffix(word, "e")
+	}
+	return word
+}
+
+// undouble drops the last letter of a stem ending with a double consonant,
+// e.g. "logg" from "logging".
+func undouble(s string) string {
+	n := len(s)
+	if n >= 2 && s[n-1] == s[n-2] && !strings.ContainsRune("aeioulsz", rune(s[n-1])) {
+		return s[:n-1]
+	}
+	return s
+}
+
+func hasVowel(s string) bool {
+	return strings.ContainsAny(s, "aeiouy")
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	asset "cloud.google.com/go/asset/apiv1"
+	assetpb "cloud.google.com/go/asset/apiv1/assetpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/paging"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/selector"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const (
+	clusterAssetType = "container.googleapis.com/Cluster"
+	// pageSize is the number of clusters returned per call.
+	pageSize = 200
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("inventory", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	listClusterInventoryTool := mcp.NewTool("list_cluster_inventory",
+		mcp.WithDescription("List every GKE cluster in a GCP organization, folder or project using Cloud Asset Inventory, with its version, release channel, mode (Autopilot or Standard), status and labels. Use it for questions about the whole fleet, e.g. 'which clusters still run 1.27', instead of listing clusters project by project. The caller needs cloudasset.assets.searchAllResources on the scope."),
+		catalog.Describe(catalog.Fleet, catalog.Read, "cloudasset.assets.searchAllResources"),
+		explain.Command(listClusterInventoryCommands),
+		mcp.WithString("scope", mcp.Required(), mcp.Description("Scope to search: organizations/ORG_ID, folders/FOLDER_ID or projects/PROJECT_ID.")),
+		mcp.WithString("query", mcp.Description("Cloud Asset Inventory search query to narrow the clusters, e.g. 'labels.env:prod', 'location:us-central1' or 'state:RUNNING'. Leave empty for all clusters.")),
+		selector.Option(),
+		paging.CursorOption(),
+		governor.FullOption(),
+	)
+	s.AddTool(listClusterInventoryTool, h.listClusterInventory)
+
+	return nil
+}
+
+// cluster is the inventory entry of a GKE cluster.
+type cluster struct {
+	Name     string            `json:"name"`
+	Project  string            `json:"project"`
+	Location string            `json:"location"`
+	Version  string            `json:"version,omitempty"`
+	Channel  string            `json:"channel,omitempty"`
+	Mode     string            `json:"mode,omitempty"`
+	Status   string            `json:"status,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+func (h *handlers) listClusterInventory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	scope, err := request.RequireString("scope")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !strings.HasPrefix(scope, "organizations/") && !strings.HasPrefix(scope, "folders/") && !strings.HasPrefix(scope, "projects/") {
+		return mcp.NewToolResultError("scope must be organizations/ORG_ID, folders/FOLDER_ID or projects/PROJECT_ID"), nil
+	}
+	sel, err := selector.Parse(request.GetString(selector.Argument, ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cursor, err := paging.Decode(request.GetString(paging.CursorArgument, ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	opts, err := auth.ClientOptions(ctx, h.c, config.APICloudAsset)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	c, err := asset.NewClient(ctx, opts...)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create cloud asset client: %v", err)), nil
+	}
+	defer c.Close()
+
+	req := &assetpb.SearchAllResourcesRequest{
+		Scope:      scope,
+		Query:      request.GetString("query", ""),
+		AssetTypes: []string{clusterAssetType},
+		OrderBy:    "project,location,name",
+		ReadMask: &fieldmaskpb.FieldMask{
+			Paths: []string{"name", "location", "labels", "state", "versionedResources"},
+		},
+	}
+	var results []*assetpb.ResourceSearchResult
+	nextPageToken, err := iterator.NewPager(c.SearchAllResources(ctx, req), pageSize, cursor.PageToken).NextPage(&results)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	clusters := make([]cluster, 0, len(results))
+	for _, r := range results {
+		if c := toCluster(r); sel.Matches(c.Labels) {
+			clusters = append(clusters, c)
+		}
+	}
+	data, err := json.MarshalIndent(clusters, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	text := string(data)
+	if nextPageToken != "" {
+		text += "\n" + paging.Footer(&paging.Cursor{PageToken: nextPageToken})
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+func listClusterInventoryCommands(_ context.Context, request mcp.CallToolRequest) []string {
+	scope := request.GetString("scope", "")
+	if scope == "" {
+		return nil
+	}
+	return []string{explain.Join("gcloud asset search-all-resources", explain.Flag("scope", scope), "--asset-types="+clusterAssetType, explain.Flag("query", request.GetString("query", "")), "--order-by=project,location,name")}
+}
+
+// toCluster extracts the inventory entry of a cluster from its search result.
+func toCluster(r *assetpb.ResourceSearchResult) cluster {
+	c := cluster{
+		Location: r.GetLocation(),
+		Status:   r.GetState(),
+		Labels:   r.GetLabels(),
+	}
+	// Names look like //container.googleapis.com/projects/P/locations/L/clusters/C.
+	parts := strings.Split(strings.TrimPrefix(r.GetName(), "//container.googleapis.com/"), "/")
+	if len(parts) == 6 {
+		c.Project, c.Name = parts[1], parts[5]
+	} else {
+		c.Name = r.GetName()
+	}
+
+	for _, vr := range r.GetVersionedResources() {
+		fields := vr.GetResource().GetFields()
+		if v := fields["currentMasterVersion"].GetStringValue(); v != "" {
+			c.Version = v
+		}
+		if rc := fields["releaseChannel"].GetStructValue(); rc != nil {
+			c.Channel = rc.GetFields()["channel"].GetStringValue()
+		}
+		c.Mode = "Standard"
+		if ap := fields["autopilot"].GetStructValue(); ap != nil && ap.GetFields()["enabled"].GetBoolValue() {
+			c.Mode = "Autopilot"
+		}
+	}
+	return c
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"testing"
+
+	assetpb "cloud.google.com/go/asset/apiv1/assetpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestToCluster(t *testing.T) {
+	resource, err := structpb.NewStruct(map[string]any{
+		"currentMasterVersion": "1.27.8-gke.1067004",
+		"releaseChannel":       map[string]any{"channel": "REGULAR"},
+		"autopilot":            map[string]any{"enabled": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &assetpb.ResourceSearchResult{
+		Name:               "//container.googleapis.com/projects/my-project/locations/us-central1/clusters/prod",
+		Location:           "us-central1",
+		State:              "RUNNING",
+		Labels:             map[string]string{"env": "prod"},
+		VersionedResources: []*assetpb.VersionedResource{{Version: "v1", Resource: resource}},
+	}
+
+	want := cluster{
+		Name:     "prod",
+		Project:  "my-project",
+		Location: "us-central1",
+		Version:  "1.27.8-gke.1067004",
+		Channel:  "REGULAR",
+		Mode:     "Autopilot",
+		Status:   "RUNNING",
+		Labels:   map[string]string{"env": "prod"},
+	}
+	if diff := cmp.Diff(want, toCluster(r)); diff != "" {
+		t.Errorf("toCluster() mismatch (-want +got):\n%s", diff)
+	}
+}
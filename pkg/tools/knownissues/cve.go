@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package knownissues
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// cveID matches a CVE identifier, like CVE-2024-3094.
+var cveID = regexp.MustCompile(`(?i)^CVE-\d{4}-\d{4,}$`)
+
+// Exposure statuses of a cluster, or of one of its versions, to a CVE.
+const (
+	// exposed means a version is older than the fix of its minor version.
+	exposed = "exposed"
+	// noFixListed means the bulletins list no fix for the minor version,
+	// which usually means it is no longer supported.
+	noFixListed = "no fix listed"
+	notExposed  = "not exposed"
+)
+
+// bulletinsFor returns the security bulletins mentioning cve.
+func bulletinsFor(entries []entry, cve string) []entry {
+	cve = strings.ToUpper(cve)
+	var bulletins []entry
+	for _, e := range entries {
+		if e.Source == securityBulletin && strings.Contains(strings.ToUpper(e.Title+" "+e.Text), cve) {
+			bulletins = append(bulletins, e)
+		}
+	}
+	return bulletins
+}
+
+// cveFixes returns the version fixing a CVE for each minor version. When
+// several bulletins list a fix for the same minor version, as updated
+// bulletins do, the highest is kept.
+func cveFixes(bulletins []entry) map[string]version {
+	fixes := map[string]version{}
+	for _, b := range bulletins {
+		for minor, v := range fixVersions(b.Text) {
+			if fix, ok := fixes[minor]; !ok || fix.less(v) {
+				fixes[minor] = v
+			}
+		}
+	}
+	return fixes
+}
+
+// sortedFixes returns the fixes from the oldest minor version.
+func sortedFixes(fixes map[string]version) []version {
+	var sorted []version
+	for _, v := range fixes {
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].less(sorted[j]) })
+	return sorted
+}
+
+// targetExposure is the exposure of a version the cluster runs.
+type targetExposure struct {
+	Target  string `json:"target"`
+	Version string `json:"version"`
+	Status  string `json:"status"`
+	// FixedIn is the version to upgrade to: the fix of the same minor
+	// version, or the oldest fix of a later one if there is none.
+	FixedIn string `json:"fixedIn,omitempty"`
+}
+
+// clusterExposure is the exposure of a cluster to a CVE.
+type clusterExposure struct {
+	Cluster string           `json:"cluster"`
+	Status  string           `json:"status"`
+	Targets []targetExposure `json:"targets"`
+}
+
+// expose returns the exposure of the versions of a cluster to a CVE with
+// fixes. The cluster is exposed if one of its versions is, and no fix is
+// listed for it otherwise if one of its versions has none.
+func expose(name string, p clusterProfile, fixes map[string]version) clusterExposure {
+	c := clusterExposure{Cluster: name, Status: notExposed}
+	sorted := sortedFixes(fixes)
+	for _, t := range p.Targets {
+		te := targetExposure{Target: t.Name, Version: t.Version.String(), Status: notExposed}
+		if fix, ok := fixes[t.Version.minor()]; ok {
+			if t.Version.less(fix) {
+				te.Status, te.FixedIn = exposed, fix.String()
+			}
+		} else {
+			te.Status = noFixListed
+			for _, v := range sorted {
+				if t.Version.less(v) {
+					te.FixedIn = v.String()
+					break
+				}
+			}
+		}
+		switch {
+		case te.Status == exposed:
+			c.Status = exposed
+		case te.Status == noFixListed && c.Status == notExposed:
+			c.Status = noFixListed
+		}
+		c.Targets = append(c.Targets, te)
+	}
+	return c
+}
+
+// sortExposures sorts exposed clusters first, then the ones without a
+// listed fix, then by name.
+func sortExposures(clusters []clusterExposure) {
+	rank := map[string]int{exposed: 0, noFixListed: 1, notExposed: 2}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		if a.Status != b.Status {
+			return rank[a.Status] < rank[b.Status]
+		}
+		return a.Cluster < b.Cluster
+	})
+}
@@ -0,0 +1,282 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package knownissues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/scan"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var entriesCache = cache.New[[]entry]("known_issues")
+
+type handlers struct {
+	c      *config.Config
+	client *http.Client
+}
+
+func init() {
+	plugin.Register(plugin.New("knownissues", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c:      c,
+		client: http.DefaultClient,
+	}
+
+	lookupKnownIssuesTool := mcp.NewTool("lookup_known_issues",
+		mcp.WithDescription("Match the exact GKE versions of a cluster's control plane and node pools, and its enabled features such as Dataplane V2, Workload Identity, GKE Sandbox or GPUs, against the published GKE known issues and security bulletins. Reports the issues fixed in a later patch of a version the cluster runs as affected, and the ones mentioning its minor versions or features without a fix as possibly affected, with links. Use it to answer whether a problem is a known bug. Matching is textual, so read the linked issue before concluding."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.get"),
+		explain.Command(h.lookupKnownIssuesCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("version", mcp.Description("GKE version to look up, like 1.30.5-gke.1014001, instead of the versions of a cluster.")),
+		cache.RefreshOption(),
+	)
+	s.AddTool(lookupKnownIssuesTool, h.lookupKnownIssues)
+
+	lookupCVEExposureTool := mcp.NewTool("lookup_cve_exposure",
+		mcp.WithDescription("Report which GKE clusters of one or more projects are exposed to a named CVE, by cross-referencing the versions of their control planes and node pools against the GKE security bulletins mentioning it, and the version that fixes it for each. Versions whose minor version has no listed fix are reported separately, with the oldest fix of a later minor version. Matching is textual, so read the linked bulletins before concluding."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.list"),
+		explain.Command(h.lookupCVEExposureCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithArray("project_ids", mcp.Items(map[string]any{"type": "string"}), mcp.Description("GCP project IDs to look in concurrently, e.g. the projects found with list_projects, to check a whole fleet. Overrides project_id.")),
+		mcp.WithString("location", mcp.Description("GKE location to look in. Defaults to all locations.")),
+		mcp.WithString("cve", mcp.Required(), mcp.Description("CVE identifier, like CVE-2024-3094.")),
+		cache.RefreshOption(),
+	)
+	s.AddTool(lookupCVEExposureTool, h.lookupCVEExposure)
+
+	return nil
+}
+
+func (h *handlers) lookupKnownIssues(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var p clusterProfile
+	subject := request.GetString("version", "")
+	if subject != "" {
+		v, ok := parseVersion(subject)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid version %q, must be a GKE version like 1.30.5-gke.1014001", subject)), nil
+		}
+		p.Targets = []target{{Name: "version", Version: v}}
+	} else {
+		projectID := session.ProjectID(ctx, request, h.c)
+		if projectID == "" {
+			return mcp.NewToolResultError("project_id argument not set"), nil
+		}
+		location := session.Location(ctx, request, h.c)
+		if location == "" {
+			return mcp.NewToolResultError("location argument not set"), nil
+		}
+		name := session.Cluster(ctx, request, h.c, "cluster")
+		if name == "" {
+			return mcp.NewToolResultError("cluster argument not set"), nil
+		}
+		cmClient, err := gcp.ClusterManager(ctx, h.c)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		p = profile(cluster)
+		subject = "cluster " + name
+	}
+
+	entries, fetchedAt, err := entriesCache.Get(ctx, "entries", h.c.CacheTTL(config.CacheKnownIssues), request.GetBool(cache.RefreshArgument, false), func(ctx context.Context) ([]entry, error) {
+		return fetchEntries(ctx, h.client)
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to fetch the known issues: %v", err)), nil
+	}
+	matches := matchAll(entries, p)
+
+	var versions, enabled []string
+	for _, t := range p.Targets {
+		versions = append(versions, fmt.Sprintf("%s %s", t.Name, t.Version))
+	}
+	for _, f := range p.Features {
+		enabled = append(enabled, f.Name)
+	}
+	var affectedCount int
+	for _, m := range matches {
+		if m.Status == affected {
+			affectedCount++
+		}
+	}
+	text := fmt.Sprintf("Matched %s (%s", subject, strings.Join(versions, ", "))
+	if len(enabled) > 0 {
+		text += "; " + strings.Join(enabled, ", ")
+	}
+	text += fmt.Sprintf(") against %d published issues and bulletins: %d affect it and %d possibly do.", len(entries), affectedCount, len(matches)-affectedCount)
+	data, err := json.MarshalIndent(matches, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	text += "\n\n" + string(data)
+	if note := cache.Note(fetchedAt); note != "" {
+		text += "\n" + note
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+func (h *handlers) lookupKnownIssuesCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	commands := []string{
+		"curl " + bulletinsURL,
+		"curl " + knownIssuesURL,
+	}
+	if request.GetString("version", "") != "" {
+		return commands
+	}
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return append([]string{
+		explain.Join("gcloud container clusters describe", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--format=yaml(currentMasterVersion,nodePools[].name,nodePools[].version)"),
+	}, commands...)
+}
+
+func (h *handlers) lookupCVEExposure(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectIDs := request.GetStringSlice("project_ids", nil)
+	if len(projectIDs) == 0 {
+		projectID := session.ProjectID(ctx, request, h.c)
+		if projectID == "" {
+			return mcp.NewToolResultError("project_id argument not set"), nil
+		}
+		projectIDs = []string{projectID}
+	}
+	cve, err := request.RequireString("cve")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !cveID.MatchString(cve) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid CVE %q, must be like CVE-2024-3094", cve)), nil
+	}
+	cve = strings.ToUpper(cve)
+	location := request.GetString("location", "-")
+
+	entries, fetchedAt, err := entriesCache.Get(ctx, "entries", h.c.CacheTTL(config.CacheKnownIssues), request.GetBool(cache.RefreshArgument, false), func(ctx context.Context) ([]entry, error) {
+		return fetchEntries(ctx, h.client)
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to fetch the security bulletins: %v", err)), nil
+	}
+	bulletins := bulletinsFor(entries, cve)
+	if len(bulletins) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("no GKE security bulletin mentions %s; it may not affect GKE, or not have been published yet", cve)), nil
+	}
+	fixes := cveFixes(bulletins)
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	results := scan.Run(ctx, projectIDs, scan.DefaultWorkers, func(ctx context.Context, projectID string) (*containerpb.ListClustersResponse, error) {
+		return cmClient.ListClusters(ctx, &containerpb.ListClustersRequest{Parent: fmt.Sprintf("projects/%s/locations/%s", projectID, location)})
+	})
+	if len(results) == 1 && results[0].Err != nil {
+		return mcp.NewToolResultError(results[0].Err.Error()), nil
+	}
+
+	clusters := []clusterExposure{}
+	counts := map[string]int{}
+	var missing, failed []string
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", r.Target, r.Err))
+			continue
+		}
+		for _, c := range r.Value.GetClusters() {
+			name := c.GetLocation() + "/" + c.GetName()
+			if len(projectIDs) > 1 {
+				name = r.Target + "/" + name
+			}
+			e := expose(name, profile(c), fixes)
+			counts[e.Status]++
+			clusters = append(clusters, e)
+		}
+		for _, zone := range r.Value.GetMissingZones() {
+			if len(projectIDs) > 1 {
+				zone = r.Target + "/" + zone
+			}
+			missing = append(missing, zone)
+		}
+	}
+	sortExposures(clusters)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s is mentioned in %d GKE security bulletins:\n", cve, len(bulletins))
+	for _, e := range bulletins {
+		fmt.Fprintf(&b, "- %s: %s\n", e.ID, e.URL)
+	}
+	if len(fixes) > 0 {
+		var fixed []string
+		for _, v := range sortedFixes(fixes) {
+			fixed = append(fixed, v.String())
+		}
+		fmt.Fprintf(&b, "Fixed in %s.\n", strings.Join(fixed, ", "))
+	} else {
+		b.WriteString("The bulletins list no fixed version.\n")
+	}
+	fmt.Fprintf(&b, "\nOf %d clusters, %d are exposed, %d run a minor version without a listed fix and %d are not exposed.", len(clusters), counts[exposed], counts[noFixListed], counts[notExposed])
+	if len(missing) > 0 {
+		fmt.Fprintf(&b, " Clusters in %s couldn't be listed.", strings.Join(missing, ", "))
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(&b, " The clusters of %d of %d projects couldn't be listed: %s.", len(failed), len(projectIDs), strings.Join(failed, "; "))
+	}
+	data, err := json.MarshalIndent(clusters, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	b.WriteString("\n\n" + string(data))
+	if note := cache.Note(fetchedAt); note != "" {
+		b.WriteString("\n" + note)
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+func (h *handlers) lookupCVEExposureCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectIDs := request.GetStringSlice("project_ids", nil)
+	if len(projectIDs) == 0 {
+		projectIDs = []string{session.ProjectID(ctx, request, h.c)}
+	}
+	commands := []string{"curl " + bulletinsURL}
+	for _, projectID := range projectIDs {
+		if projectID == "" {
+			return nil
+		}
+		commands = append(commands, explain.Join("gcloud container clusters list", explain.Flag("location", request.GetString("location", "")), explain.Flag("project", projectID), "--format=yaml(name,location,currentMasterVersion,nodePools[].name,nodePools[].version)"))
+	}
+	return commands
+}
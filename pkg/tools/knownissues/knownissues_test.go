@@ -0,0 +1,236 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package knownissues
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/mock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseBulletins(t *testing.T) {
+	got, err := parseBulletins(strings.NewReader(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <title>GCP-2024-036</title>
+    <id>tag:cloud.google.com,2024:gcp-2024-036</id>
+    <updated>2024-06-18T00:00:00Z</updated>
+    <link href="https://cloud.google.com/kubernetes-engine/security-bulletins#gcp-2024-036"/>
+    <content type="html">&lt;p&gt;Upgrade to &lt;strong&gt;1.29.5-gke.1060000&lt;/strong&gt; or later.&lt;/p&gt;</content>
+  </entry>
+</feed>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []entry{{
+		Source:    securityBulletin,
+		ID:        "GCP-2024-036",
+		Title:     "GCP-2024-036",
+		URL:       "https://cloud.google.com/kubernetes-engine/security-bulletins#gcp-2024-036",
+		Published: time.Date(2024, 6, 18, 0, 0, 0, 0, time.UTC),
+		Text:      "Upgrade to 1.29.5-gke.1060000 or later.",
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseBulletins() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseKnownIssues(t *testing.T) {
+	got, err := parseKnownIssues(strings.NewReader(`<html><body><nav>Menu</nav><article>
+		<h1>Known issues</h1><p>Intro.</p>
+		<h2>Networking</h2>
+		<h3 id="dpv2-drops">Dataplane V2 drops packets</h3>
+		<table><tr><th>Identified versions</th><th>Fixed versions</th></tr><tr><td>1.29</td><td>1.29.6-gke.1038000</td></tr></table>
+		<h2>Storage</h2>
+		<h3>Filestore mounts hang</h3><p>Restart the node.</p>
+	</article></body></html>`), knownIssuesURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []entry{
+		{Source: knownIssue, ID: "dpv2-drops", Title: "Networking: Dataplane V2 drops packets", URL: knownIssuesURL + "#dpv2-drops", Text: "Identified versions Fixed versions 1.29 1.29.6-gke.1038000"},
+		{Source: knownIssue, Title: "Storage: Filestore mounts hang", URL: knownIssuesURL, Text: "Restart the node."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseKnownIssues() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	cluster := &containerpb.Cluster{
+		CurrentMasterVersion: "1.30.5-gke.1014001",
+		NetworkConfig:        &containerpb.NetworkConfig{DatapathProvider: containerpb.DatapathProvider_ADVANCED_DATAPATH},
+		NodePools: []*containerpb.NodePool{
+			{Name: "default", Version: "1.29.8-gke.1031000"},
+			{Name: "gpu", Version: "1.30.5-gke.1014001", Config: &containerpb.NodeConfig{Accelerators: []*containerpb.AcceleratorConfig{{AcceleratorType: "nvidia-l4"}}}},
+		},
+	}
+	p := profile(cluster)
+	wantTargets := []target{
+		{Name: "control plane", Version: version{1, 30, 5, 1014001}},
+		{Name: "node pool default", Version: version{1, 29, 8, 1031000}},
+		{Name: "node pool gpu", Version: version{1, 30, 5, 1014001}},
+	}
+	if diff := cmp.Diff(wantTargets, p.Targets); diff != "" {
+		t.Errorf("profile() targets mismatch (-want +got):\n%s", diff)
+	}
+	var names []string
+	for _, f := range p.Features {
+		names = append(names, f.Name)
+	}
+	if diff := cmp.Diff([]string{"GKE Dataplane V2", "GPUs"}, names); diff != "" {
+		t.Errorf("profile() features mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMatchAll(t *testing.T) {
+	p := clusterProfile{
+		Targets: []target{
+			{Name: "control plane", Version: version{1, 30, 5, 1014001}},
+			{Name: "node pool default", Version: version{1, 29, 8, 1031000}},
+		},
+	}
+	for _, f := range features {
+		if f.Name == "GKE Dataplane V2" {
+			p.Features = append(p.Features, f)
+		}
+	}
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC) }
+	entries := []entry{
+		{Source: securityBulletin, ID: "fixed", Published: day(1), Text: "Upgrade to 1.29.6-gke.1038000 or 1.30.2-gke.1023000."},
+		{Source: securityBulletin, ID: "nodes affected", Published: day(2), Text: "Upgrade to 1.29.9-gke.1000000, 1.29.10-gke.1 or 1.30.5-gke.1014001."},
+		{Source: securityBulletin, ID: "unrelated bulletin", Published: day(3), Text: "GKE 1.29 and 1.30 are not affected."},
+		{Source: securityBulletin, ID: "dataplane bulletin", Published: day(4), Text: "Clusters on 1.30 using GKE Dataplane V2 are affected. A fix is coming."},
+		{Source: knownIssue, ID: "minor", Published: day(5), Text: "Identified versions 1.29. No fix yet."},
+		{Source: knownIssue, ID: "feature", Text: "Pods lose connectivity with Dataplane V2 when the anetd DaemonSet restarts."},
+		{Source: knownIssue, ID: "other feature", Text: "Windows nodes fail to register."},
+	}
+	want := []match{
+		{entry: entries[1], Status: affected, Versions: []string{"node pool default 1.29.8-gke.1031000, fixed in 1.29.9-gke.1000000"}},
+		{entry: entries[4], Status: possiblyAffected, Versions: []string{"node pool default 1.29.8-gke.1031000"}},
+		{entry: entries[3], Status: possiblyAffected, Versions: []string{"control plane 1.30.5-gke.1014001"}, Features: []string{"GKE Dataplane V2"}},
+		{entry: entries[5], Status: possiblyAffected, Features: []string{"GKE Dataplane V2"}},
+	}
+	got := matchAll(entries, p)
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(match{}), cmpopts.IgnoreFields(match{}, "Excerpt")); diff != "" {
+		t.Errorf("matchAll() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+	got := excerpt(long)
+	if len(got) > excerptLength+3 || !strings.HasSuffix(got, "word...") {
+		t.Errorf("excerpt() = %q, want at most %d characters cut at a word", got, excerptLength)
+	}
+	if got := excerpt("short"); got != "short" {
+		t.Errorf("excerpt(%q) = %q", "short", got)
+	}
+}
+
+func TestExpose(t *testing.T) {
+	entries := []entry{
+		{Source: securityBulletin, ID: "GCP-2024-040", Text: "CVE-2024-6387 affects nodes. Upgrade to 1.28.11-gke.1019001, 1.29.6-gke.1137000 or 1.30.2-gke.1394000."},
+		{Source: securityBulletin, ID: "GCP-2024-040 update", Text: "Updated for cve-2024-6387: 1.29 is fixed in 1.29.6-gke.1254000."},
+		{Source: securityBulletin, ID: "unrelated", Text: "CVE-2024-0001. Upgrade to 1.29.9-gke.1000000."},
+		{Source: knownIssue, ID: "issue", Text: "CVE-2024-6387 in the known issues. 1.30.9-gke.1000000."},
+	}
+	bulletins := bulletinsFor(entries, "CVE-2024-6387")
+	if len(bulletins) != 2 {
+		t.Fatalf("bulletinsFor() = %d bulletins, want 2", len(bulletins))
+	}
+	fixes := cveFixes(bulletins)
+	p := clusterProfile{Targets: []target{
+		{Name: "control plane", Version: version{1, 30, 3, 1000000}},
+		{Name: "node pool default", Version: version{1, 29, 6, 1137000}},
+		{Name: "node pool old", Version: version{1, 27, 16, 1000000}},
+	}}
+	want := clusterExposure{Cluster: "us-central1/prod", Status: exposed, Targets: []targetExposure{
+		{Target: "control plane", Version: "1.30.3-gke.1000000", Status: notExposed},
+		{Target: "node pool default", Version: "1.29.6-gke.1137000", Status: exposed, FixedIn: "1.29.6-gke.1254000"},
+		{Target: "node pool old", Version: "1.27.16-gke.1000000", Status: noFixListed, FixedIn: "1.28.11-gke.1019001"},
+	}}
+	if diff := cmp.Diff(want, expose("us-central1/prod", p, fixes)); diff != "" {
+		t.Errorf("expose() mismatch (-want +got):\n%s", diff)
+	}
+
+	clusters := []clusterExposure{{Cluster: "b", Status: notExposed}, {Cluster: "c", Status: noFixListed}, {Cluster: "a", Status: notExposed}, {Cluster: "d", Status: exposed}}
+	sortExposures(clusters)
+	var order []string
+	for _, c := range clusters {
+		order = append(order, c.Cluster)
+	}
+	if diff := cmp.Diff([]string{"d", "c", "a", "b"}, order); diff != "" {
+		t.Errorf("sortExposures() order mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type pages map[string]string
+
+func (p pages) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, ok := p[r.URL.String()]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: r}, nil
+}
+
+func TestLookupCVEExposureProjects(t *testing.T) {
+	m, err := mock.New("")
+	if err != nil {
+		t.Fatalf("mock.New() failed: %v", err)
+	}
+	defer m.Close()
+	h := &handlers{
+		c: config.New("test", config.WithMock(m)),
+		client: &http.Client{Transport: pages{
+			bulletinsURL: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <title>GCP-2024-040</title>
+    <updated>2024-07-01T00:00:00Z</updated>
+    <content type="html">CVE-2024-6387 affects nodes. Upgrade to 1.28.11-gke.1019001.</content>
+  </entry>
+</feed>`,
+			knownIssuesURL: "<html><body><article><h1>Known issues</h1></article></body></html>",
+		}},
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"project_ids": []any{"gke-mcp-demo", "other-project"}, "cve": "CVE-2024-6387", "refresh": true}
+	result, err := h.lookupCVEExposure(context.Background(), request)
+	if err != nil {
+		t.Fatalf("lookupCVEExposure() failed: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("lookupCVEExposure() returned an error: %s", text)
+	}
+	for _, want := range []string{"Of 1 clusters", `"gke-mcp-demo/us-central1/demo-cluster"`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("lookupCVEExposure() = %q, want it to contain %q", text, want)
+		}
+	}
+}
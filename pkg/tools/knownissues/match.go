@@ -0,0 +1,269 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package knownissues
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+)
+
+// Statuses of an issue for a cluster.
+const (
+	// affected means a version of the cluster is older than the version
+	// the issue is fixed in for its minor version.
+	affected = "affected"
+	// possiblyAffected means the issue mentions the minor version of the
+	// cluster, or one of its features, without a fixed version for it.
+	possiblyAffected = "possibly affected"
+)
+
+// Sources of issues.
+const (
+	securityBulletin = "security bulletin"
+	knownIssue       = "known issue"
+)
+
+// entry is a published known issue or security bulletin.
+type entry struct {
+	Source    string    `json:"source"`
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	Published time.Time `json:"published,omitzero"`
+	// Text is the text of the issue, without markup.
+	Text string `json:"-"`
+}
+
+// excerptLength is the length of the start of the text of an issue shown.
+const excerptLength = 400
+
+// excerpt returns the start of text, cut at a word.
+func excerpt(text string) string {
+	if len(text) <= excerptLength {
+		return text
+	}
+	cut := strings.LastIndex(text[:excerptLength], " ")
+	if cut < 0 {
+		cut = excerptLength
+	}
+	return text[:cut] + "..."
+}
+
+// gkeVersion matches the full GKE versions in text.
+var gkeVersion = regexp.MustCompile(`\b1\.(\d+)\.(\d+)-gke\.(\d+)\b`)
+
+// version is a parsed GKE version, like 1.30.5-gke.1014001.
+type version [4]int
+
+func parseVersion(s string) (version, bool) {
+	m := gkeVersion.FindStringSubmatch(s)
+	if m == nil {
+		return version{}, false
+	}
+	v := version{1}
+	for i := 1; i < 4; i++ {
+		v[i], _ = strconv.Atoi(m[i])
+	}
+	return v, true
+}
+
+func (v version) minor() string {
+	return fmt.Sprintf("1.%d", v[1])
+}
+
+func (v version) String() string {
+	return fmt.Sprintf("1.%d.%d-gke.%d", v[1], v[2], v[3])
+}
+
+func (v version) less(w version) bool {
+	return slices.Compare(v[:], w[:]) < 0
+}
+
+// target is a version the cluster runs, on its control plane or a node
+// pool.
+type target struct {
+	Name    string
+	Version version
+}
+
+// feature is a cluster feature issues may be about.
+type feature struct {
+	Name string
+	// Keywords are the lowercase words identifying the feature in an
+	// issue.
+	Keywords []string
+	Enabled  func(*containerpb.Cluster) bool
+}
+
+// anyNodePool tells whether a node pool of the cluster matches f.
+func anyNodePool(f func(*containerpb.NodePool) bool) func(*containerpb.Cluster) bool {
+	return func(c *containerpb.Cluster) bool {
+		return slices.ContainsFunc(c.GetNodePools(), f)
+	}
+}
+
+var features = []feature{
+	{"Autopilot", []string{"autopilot"}, func(c *containerpb.Cluster) bool { return c.GetAutopilot().GetEnabled() }},
+	{"GKE Dataplane V2", []string{"dataplane v2", "anetd", "cilium"}, func(c *containerpb.Cluster) bool {
+		return c.GetNetworkConfig().GetDatapathProvider() == containerpb.DatapathProvider_ADVANCED_DATAPATH
+	}},
+	{"Workload Identity", []string{"workload identity", "gke-metadata-server"}, func(c *containerpb.Cluster) bool {
+		return c.GetWorkloadIdentityConfig().GetWorkloadPool() != ""
+	}},
+	{"NodeLocal DNSCache", []string{"nodelocal dns", "node-local-dns"}, func(c *containerpb.Cluster) bool {
+		return c.GetAddonsConfig().GetDnsCacheConfig().GetEnabled()
+	}},
+	{"Filestore CSI driver", []string{"filestore"}, func(c *containerpb.Cluster) bool {
+		return c.GetAddonsConfig().GetGcpFilestoreCsiDriverConfig().GetEnabled()
+	}},
+	{"Cloud Storage FUSE CSI driver", []string{"cloud storage fuse", "gcsfuse"}, func(c *containerpb.Cluster) bool {
+		return c.GetAddonsConfig().GetGcsFuseCsiDriverConfig().GetEnabled()
+	}},
+	{"Confidential GKE Nodes", []string{"confidential"}, func(c *containerpb.Cluster) bool { return c.GetConfidentialNodes().GetEnabled() }},
+	{"Private nodes", []string{"private cluster", "private nodes"}, func(c *containerpb.Cluster) bool {
+		return c.GetPrivateClusterConfig().GetEnablePrivateNodes()
+	}},
+	{"GKE Sandbox", []string{"gvisor", "gke sandbox"}, anyNodePool(func(np *containerpb.NodePool) bool {
+		return np.GetConfig().GetSandboxConfig().GetType() == containerpb.SandboxConfig_GVISOR
+	})},
+	{"GPUs", []string{"gpu", "nvidia"}, anyNodePool(func(np *containerpb.NodePool) bool { return len(np.GetConfig().GetAccelerators()) > 0 })},
+	{"Windows nodes", []string{"windows"}, anyNodePool(func(np *containerpb.NodePool) bool {
+		return strings.HasPrefix(strings.ToUpper(np.GetConfig().GetImageType()), "WINDOWS")
+	})},
+	{"Ubuntu nodes", []string{"ubuntu"}, anyNodePool(func(np *containerpb.NodePool) bool {
+		return strings.HasPrefix(strings.ToUpper(np.GetConfig().GetImageType()), "UBUNTU")
+	})},
+}
+
+// clusterProfile is what issues are matched against.
+type clusterProfile struct {
+	Targets  []target
+	Features []feature
+}
+
+// profile returns the versions and enabled features of a cluster.
+func profile(c *containerpb.Cluster) clusterProfile {
+	var p clusterProfile
+	if v, ok := parseVersion(c.GetCurrentMasterVersion()); ok {
+		p.Targets = append(p.Targets, target{Name: "control plane", Version: v})
+	}
+	for _, np := range c.GetNodePools() {
+		if v, ok := parseVersion(np.GetVersion()); ok {
+			p.Targets = append(p.Targets, target{Name: "node pool " + np.GetName(), Version: v})
+		}
+	}
+	for _, f := range features {
+		if f.Enabled(c) {
+			p.Features = append(p.Features, f)
+		}
+	}
+	return p
+}
+
+// match is an issue that may affect the cluster.
+type match struct {
+	entry
+	Status string `json:"status"`
+	// Versions lists the versions of the cluster the issue is about, with
+	// the version it is fixed in, if any.
+	Versions []string `json:"versions,omitempty"`
+	Features []string `json:"features,omitempty"`
+	Excerpt  string   `json:"excerpt"`
+}
+
+// minorMention matches a minor version mentioned in text, as in "1.29" or
+// "1.29.4-gke.1043002".
+func minorMention(minor string) *regexp.Regexp {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(minor) + `\b`)
+}
+
+// fixVersions returns the lowest version text lists for each minor
+// version, which is taken as the fix of an issue for it.
+func fixVersions(text string) map[string]version {
+	fixes := map[string]version{}
+	for _, s := range gkeVersion.FindAllString(text, -1) {
+		v, _ := parseVersion(s)
+		if fix, ok := fixes[v.minor()]; !ok || v.less(fix) {
+			fixes[v.minor()] = v
+		}
+	}
+	return fixes
+}
+
+// matchEntry matches an issue against the cluster. An issue affects a
+// version older than the lowest version it lists for the same minor
+// version, which is taken as its fix; a version at or past it is fixed.
+// Issues mentioning a minor version of the cluster without listing a fix
+// for it, or one of its features, possibly affect it.
+func matchEntry(e entry, p clusterProfile) (match, bool) {
+	m := match{entry: e, Excerpt: excerpt(e.Text)}
+	fixes := fixVersions(e.Text)
+	var mentioned bool
+	for _, t := range p.Targets {
+		if fix, ok := fixes[t.Version.minor()]; ok {
+			if t.Version.less(fix) {
+				m.Status = affected
+				m.Versions = append(m.Versions, fmt.Sprintf("%s %s, fixed in %s", t.Name, t.Version, fix))
+			}
+			continue
+		}
+		if minorMention(t.Version.minor()).MatchString(e.Text) {
+			mentioned = true
+			m.Versions = append(m.Versions, fmt.Sprintf("%s %s", t.Name, t.Version))
+		}
+	}
+	text := strings.ToLower(e.Title + " " + e.Text)
+	for _, f := range p.Features {
+		if slices.ContainsFunc(f.Keywords, func(k string) bool { return strings.Contains(text, k) }) {
+			m.Features = append(m.Features, f.Name)
+		}
+	}
+	if m.Status == affected {
+		return m, true
+	}
+	// Security bulletins mention many versions that aren't affected, so
+	// they also need a feature of the cluster.
+	if (mentioned && (e.Source == knownIssue || len(m.Features) > 0)) || (len(m.Features) > 0 && len(fixes) == 0 && e.Source == knownIssue) {
+		m.Status = possiblyAffected
+		return m, true
+	}
+	return match{}, false
+}
+
+// matchAll matches the issues against the cluster, affecting ones first,
+// then the most recent.
+func matchAll(entries []entry, p clusterProfile) []match {
+	var matches []match
+	for _, e := range entries {
+		if m, ok := matchEntry(e, p); ok {
+			matches = append(matches, m)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Status != b.Status {
+			return a.Status == affected
+		}
+		return a.Published.After(b.Published)
+	})
+	return matches
+}
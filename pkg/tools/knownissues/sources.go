@@ -0,0 +1,201 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package knownissues
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// bulletinsURL is the Atom feed of the GKE security bulletins.
+	bulletinsURL = "https://cloud.google.com/feeds/kubernetes-engine-security-bulletins.xml"
+	// knownIssuesURL is the page of the GKE known issues.
+	knownIssuesURL = "https://cloud.google.com/kubernetes-engine/docs/troubleshooting/known-issues"
+	// maxPageSize is the size above which a source is truncated.
+	maxPageSize = 20 << 20
+)
+
+// get fetches url.
+func get(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// fetchEntries fetches the security bulletins and the known issues.
+func fetchEntries(ctx context.Context, client *http.Client) ([]entry, error) {
+	body, err := get(ctx, client, bulletinsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	entries, err := parseBulletins(io.LimitReader(body, maxPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", bulletinsURL, err)
+	}
+
+	page, err := get(ctx, client, knownIssuesURL)
+	if err != nil {
+		return nil, err
+	}
+	defer page.Close()
+	issues, err := parseKnownIssues(io.LimitReader(page, maxPageSize), knownIssuesURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", knownIssuesURL, err)
+	}
+	return append(entries, issues...), nil
+}
+
+// feed is the part of an Atom feed read.
+type feed struct {
+	Entries []struct {
+		ID      string `xml:"id"`
+		Title   string `xml:"title"`
+		Updated string `xml:"updated"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Content string `xml:"content"`
+	} `xml:"entry"`
+}
+
+// parseBulletins parses the Atom feed of the security bulletins, whose
+// entries have HTML content.
+func parseBulletins(r io.Reader) ([]entry, error) {
+	var f feed
+	if err := xml.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	var entries []entry
+	for _, fe := range f.Entries {
+		e := entry{Source: securityBulletin, ID: strings.TrimSpace(fe.Title), Title: strings.TrimSpace(fe.Title), URL: fe.ID}
+		if len(fe.Links) > 0 {
+			e.URL = fe.Links[0].Href
+		}
+		e.Published, _ = time.Parse(time.RFC3339, fe.Updated)
+		doc, err := html.Parse(strings.NewReader(fe.Content))
+		if err != nil {
+			return nil, err
+		}
+		e.Text = text(doc)
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// parseKnownIssues splits the known issues page into one issue per h3
+// heading, under h2 headings naming the product area.
+func parseKnownIssues(r io.Reader, pageURL string) ([]entry, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var entries []entry
+	var area string
+	var current *entry
+	var b strings.Builder
+	flush := func() {
+		if current != nil {
+			current.Text = collapse(b.String())
+			entries = append(entries, *current)
+		}
+		current = nil
+		b.Reset()
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Script, atom.Style, atom.Nav, atom.Noscript, atom.Button:
+				return
+			case atom.H2:
+				flush()
+				area = collapse(text(n))
+				return
+			case atom.H3:
+				flush()
+				title := collapse(text(n))
+				if area != "" {
+					title = area + ": " + title
+				}
+				current = &entry{Source: knownIssue, ID: attr(n, "id"), Title: title, URL: pageURL}
+				if current.ID != "" {
+					current.URL += "#" + current.ID
+				}
+				return
+			}
+		}
+		if n.Type == html.TextNode && current != nil {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	flush()
+	return entries, nil
+}
+
+// text returns the text of a node and its children, collapsed.
+func text(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return collapse(b.String())
+}
+
+// collapse collapses whitespace.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
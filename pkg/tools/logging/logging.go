@@ -18,12 +18,18 @@ import (
 	"context"
 
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+func init() {
+	plugin.Register(plugin.New("logging", Install))
+}
+
 // Install adds GCP logging related tools to an MCP server.
 func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
 	installQueryLogsTool(s, c)
+	installTailLogsTool(s, c)
 	installGetLogSchemas(s)
 
 	return nil
@@ -18,17 +18,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
-	logging "cloud.google.com/go/logging/apiv2"
 	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/paging"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/stream"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"google.golang.org/api/iterator"
-	"google.golang.org/api/option"
 	_ "google.golang.org/genproto/googleapis/cloud/audit" // Import for AuditLog proto so we can convert to JSON.
 	"google.golang.org/protobuf/encoding/protojson"
 )
@@ -40,6 +46,7 @@ type LogQueryRequest struct {
 	Since     string     `json:"since,omitempty"`
 	Limit     int        `json:"limit,omitempty"`
 	Format    string     `json:"format,omitempty"`
+	Cursor    string     `json:"cursor,omitempty"`
 }
 
 type TimeRange struct {
@@ -50,13 +57,18 @@ type TimeRange struct {
 const (
 	defaultLimit = 10
 	maxLimit     = 100
+	// maxStreamLimit is the maximum number of log entries of a call
+	// streaming them, which are read maxLimit at a time.
+	maxStreamLimit = 10000
 )
 
 func installQueryLogsTool(s *server.MCPServer, conf *config.Config) {
+	t := newQueryLogsTool(conf)
 	queryLogsTool := mcp.NewTool("query_logs",
 		mcp.WithDescription("Query Google Cloud Platform logs using Logging Query Language (LQL). Before using this tool, it's **strongly** recommended to call the 'get_log_schema' tool to get information about supported log types and their schemas. Logs are returned in ascending order, based on the timestamp (i.e. oldest first)."),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithString("project_id", mcp.Description("GCP project ID to query logs from. Required."), mcp.Required()),
+		catalog.Describe(catalog.Observability, catalog.Query, "logging.logEntries.list"),
+		explain.Command(t.commands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID to query logs from. Defaults to the session context.")),
 		mcp.WithString("query", mcp.Description("LQL query string to filter and retrieve log entries. Don't specify time ranges in this filter. Use 'time_range' instead.")),
 		mcp.WithObject("time_range", mcp.Description("Time range for log query. If empty, no restrictions are applied."),
 			mcp.Properties(map[string]any{
@@ -71,11 +83,13 @@ func installQueryLogsTool(s *server.MCPServer, conf *config.Config) {
 			}),
 		),
 		mcp.WithString("since", mcp.Description("Only return logs newer than a relative duration like 5s, 2m, or 3h. The only supported units are seconds ('s'), minutes ('m'), and hours ('h').")),
-		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of log entries to return. Cannot be greater than %d. Consider multiple calls if needed. Defaults to %d.", maxLimit, defaultLimit))),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of log entries to return per page. Cannot be greater than %d, or %d when streaming. Use the returned cursor to get more. Defaults to %d.", maxLimit, maxStreamLimit, defaultLimit))),
 		mcp.WithString("format", mcp.Description("Go template string to format each log entry. If empty, the full JSON representation is returned. Note that empty fields are not included in the response. Example: '{{.timestamp}} [{{.severity}}] {{.textPayload}}'. It's strongly recommended to use a template to minimize the size of the response and only include the fields you need. Use the get_schema tool before this tool to get information about supported log types and their schemas.")),
+		paging.CursorOption(),
+		stream.Option(),
+		governor.FullOption(),
 	)
 
-	t := newQueryLogsTool(conf)
 	s.AddTool(queryLogsTool, mcp.NewTypedToolHandler(t.queryLogs))
 }
 
@@ -89,12 +103,19 @@ func newQueryLogsTool(conf *config.Config) *queryLogsTool {
 	}
 }
 
-func (t *queryLogsTool) queryLogs(ctx context.Context, _ mcp.CallToolRequest, req LogQueryRequest) (*mcp.CallToolResult, error) {
+func (t *queryLogsTool) queryLogs(ctx context.Context, request mcp.CallToolRequest, req LogQueryRequest) (*mcp.CallToolResult, error) {
+	if req.ProjectID == "" {
+		req.ProjectID = session.ProjectID(ctx, request, t.conf)
+	}
 	req.setDefaults()
-	if errMsg := req.validate(); errMsg != "" {
+	w, err := stream.NewWriter(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if errMsg := req.validate(w.Streaming()); errMsg != "" {
 		return mcp.NewToolResultError(errMsg), nil
 	}
-	result, err := t.queryGCPLogs(ctx, req)
+	result, err := t.queryGCPLogs(ctx, req, w)
 	if err != nil {
 		return mcp.NewToolResultErrorf("Query failed: %v", err), nil
 	}
@@ -102,18 +123,46 @@ func (t *queryLogsTool) queryLogs(ctx context.Context, _ mcp.CallToolRequest, re
 	return mcp.NewToolResultText(result), nil
 }
 
+func (t *queryLogsTool) commands(ctx context.Context, request mcp.CallToolRequest) []string {
+	var req LogQueryRequest
+	if err := request.BindArguments(&req); err != nil {
+		return nil
+	}
+	if req.ProjectID == "" {
+		req.ProjectID = session.ProjectID(ctx, request, t.conf)
+	}
+	req.setDefaults()
+	if req.ProjectID == "" {
+		return nil
+	}
+	// gcloud only reads the last day of logs by default, while the tool
+	// reads them all, up to the default retention of 30 days.
+	freshness := req.Since
+	if freshness == "" && req.TimeRange == nil {
+		freshness = "30d"
+	}
+	req.Since = ""
+	filter := buildListLogEntriesRequest(req).GetFilter()
+	return []string{explain.Join("gcloud logging read", filter, explain.Flag("project", req.ProjectID), explain.Flag("limit", strconv.Itoa(req.Limit)), "--order=asc", explain.Flag("freshness", freshness))}
+}
+
 func (r *LogQueryRequest) setDefaults() {
 	if r.Limit == 0 {
 		r.Limit = defaultLimit
 	}
 }
 
-func (r *LogQueryRequest) validate() string {
+// validate returns why r is invalid, or "" if it is valid. streaming allows
+// larger limits.
+func (r *LogQueryRequest) validate(streaming bool) string {
 	if r.ProjectID == "" {
 		return "project_id parameter is required"
 	}
-	if r.Limit > maxLimit {
-		return fmt.Sprintf("limit parameter cannot be greater than %d", maxLimit)
+	if streaming && r.Limit > maxStreamLimit {
+		return fmt.Sprintf("limit parameter cannot be greater than %d when streaming", maxStreamLimit)
+	}
+	if !streaming && r.Limit > maxLimit {
+		return fmt.Sprintf("limit parameter cannot be greater than %d; stream the results to get more at once", maxLimit)
 	}
 	if _, err := time.ParseDuration(r.Since); err != nil {
 		return fmt.Sprintf("invalid since parameter: %v", err)
@@ -121,6 +170,9 @@ func (r *LogQueryRequest) validate() string {
 	if r.TimeRange != nil && r.Since != "" {
 		return "since parameter cannot be used with time_range"
 	}
+	if _, err := paging.Decode(r.Cursor); err != nil {
+		return err.Error()
+	}
 	if r.Format != "" {
 		var err error
 		_, err = template.New("log").Parse(r.Format)
@@ -131,63 +183,76 @@ func (r *LogQueryRequest) validate() string {
 	return ""
 }
 
-func (t *queryLogsTool) queryGCPLogs(ctx context.Context, req LogQueryRequest) (string, error) {
-	client, err := logging.NewClient(context.TODO(), option.WithUserAgent(t.conf.UserAgent()))
+// queryGCPLogs reads the log entries matching req, maxLimit at a time, and
+// writes them to w.
+func (t *queryLogsTool) queryGCPLogs(ctx context.Context, req LogQueryRequest, w *stream.Writer) (string, error) {
+	client, err := gcp.Logging(ctx, t.conf)
 	if err != nil {
-		return "", fmt.Errorf("failed to create logging client: %v", err)
+		return "", err
 	}
-	defer client.Close()
 
+	cursor, err := paging.Decode(req.Cursor)
+	if err != nil {
+		return "", err
+	}
 	listLogsReq := buildListLogEntriesRequest(req)
-	// Request one more than the limit to check for truncation.
-	listLogsReq.PageSize = int32(req.Limit + 1)
-
-	resp := client.ListLogEntries(ctx, listLogsReq)
-
-	var entries []*loggingpb.LogEntry
-	for {
-		entry, err := resp.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return "", fmt.Errorf("failed to iterate log entries: %v", err)
-		}
-		entries = append(entries, entry)
-		if len(entries) > req.Limit {
-			break
-		}
+	// Later pages must use the exact filter of the first one, even if it
+	// was relative to the current time.
+	if cursor.Query != "" {
+		listLogsReq.Filter = cursor.Query
 	}
 
-	truncated := len(entries) > req.Limit
-	if truncated {
-		entries = entries[:req.Limit]
+	formatter, err := formatterForRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create formatter: %w", err)
 	}
 
-	allLogLines := strings.Builder{}
-	if len(entries) == 0 {
-		allLogLines.WriteString("No log entries found.")
-	} else {
-		formatter, err := formatterForRequest(req)
+	// Only a page of entries is kept in memory at a time.
+	count := 0
+	pageToken := cursor.PageToken
+	for count < req.Limit {
+		var entries []*loggingpb.LogEntry
+		pager := iterator.NewPager(client.ListLogEntries(ctx, listLogsReq), min(req.Limit-count, maxLimit), pageToken)
+		pageToken, err = pager.NextPage(&entries)
 		if err != nil {
-			return "", fmt.Errorf("failed to create formatter: %w", err)
+			return "", fmt.Errorf("failed to iterate log entries: %v", err)
 		}
-
-		for i, entry := range entries {
-			if i > 0 {
-				allLogLines.WriteString("\n")
-			}
+		for _, entry := range entries {
 			logLine, err := formatter.format(entry)
 			if err != nil {
 				return "", fmt.Errorf("failed to format log entry: %w", err)
 			}
-			allLogLines.WriteString(logLine)
+			if count > 0 {
+				logLine = "\n" + logLine
+			}
+			if err := w.Write(logLine); err != nil {
+				return "", err
+			}
+			count++
+		}
+		if pageToken == "" {
+			break
 		}
 	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	var next *paging.Cursor
+	if pageToken != "" {
+		next = &paging.Cursor{PageToken: pageToken, Query: listLogsReq.Filter}
+	}
+
+	logLines := w.String()
+	switch {
+	case count == 0:
+		logLines = "No log entries found."
+	case w.Streaming():
+		logLines = fmt.Sprintf("Streamed %d log entries in %d progress notifications.", count, w.Chunks())
+	}
 
-	result := fmt.Sprintf("Project ID: %s\nLQL Query:\n```\n%s\n```\nResult:\n\n%s", req.ProjectID, listLogsReq.Filter, allLogLines.String())
-	if truncated {
-		result += fmt.Sprintf("\n\nWarning: Results truncated. The query returned more than the limit of %d log entries. You can use the `limit` parameter to request more entries (up to %d).", req.Limit, maxLimit)
+	result := fmt.Sprintf("Project ID: %s\nLQL Query:\n```\n%s\n```\nResult:\n\n%s", req.ProjectID, listLogsReq.Filter, logLines)
+	if footer := paging.Footer(next); footer != "" {
+		result += "\n\n" + footer
 	}
 
 	return result, nil
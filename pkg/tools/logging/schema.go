@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/instructions"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -27,6 +29,21 @@ import (
 //go:embed schemas/*.md
 var schemas embed.FS
 
+// logTypes are the log types with a schema.
+var logTypes = []string{"k8s_audit_logs", "k8s_application_logs"}
+
+func init() {
+	// Make the schemas and their sample queries searchable with
+	// get_instructions too.
+	for _, logType := range logTypes {
+		content, err := schemas.ReadFile(filepath.Join("schemas", logType+".md"))
+		if err != nil {
+			panic(err)
+		}
+		instructions.RegisterSource(instructions.MarkdownSource("log schema "+logType, string(content)))
+	}
+}
+
 type GetLogSchemaRequest struct {
 	LogType string `json:"log_type"`
 }
@@ -34,7 +51,7 @@ type GetLogSchemaRequest struct {
 func installGetLogSchemas(s *server.MCPServer) {
 	getLogSchemaTool := mcp.NewTool("get_log_schema",
 		mcp.WithDescription("Get the schema for a specific log type."),
-		mcp.WithReadOnlyHintAnnotation(true),
+		catalog.Describe(catalog.Observability, catalog.Local),
 		mcp.WithString("log_type", mcp.Description("The type of log to get schema for. Supported values are: ['k8s_audit_logs', 'k8s_application_logs']."), mcp.Required()),
 	)
 	s.AddTool(getLogSchemaTool, mcp.NewTypedToolHandler(getLogSchema))
@@ -0,0 +1,202 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/template"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/stream"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type LogTailRequest struct {
+	Query     string `json:"query"`
+	ProjectID string `json:"project_id"`
+	Duration  string `json:"duration,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+	Format    string `json:"format,omitempty"`
+}
+
+const (
+	defaultTailDuration = 30 * time.Second
+	maxTailDuration     = 10 * time.Minute
+	defaultTailLimit    = 100
+	// tailMargin is how long before the deadline of the tool call the tail
+	// stops, to return the entries it read.
+	tailMargin = 5 * time.Second
+)
+
+func installTailLogsTool(s *server.MCPServer, conf *config.Config) {
+	t := &tailLogsTool{conf: conf}
+	tailLogsTool := mcp.NewTool("tail_logs",
+		mcp.WithDescription("Tail Google Cloud Platform logs: return the log entries matching a Logging Query Language (LQL) filter as they are written, for a while. Use it to watch what happens during an operation, e.g. a rollout; use query_logs for past logs."),
+		catalog.Describe(catalog.Observability, catalog.Query, "logging.logEntries.list"),
+		explain.Command(t.commands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID to tail logs from. Defaults to the session context.")),
+		mcp.WithString("query", mcp.Description("LQL query string to filter the log entries.")),
+		mcp.WithString("duration", mcp.Description(fmt.Sprintf("How long to tail the logs, like 30s or 2m. Cannot be longer than %v, and stops earlier if the tool call would time out. Defaults to %v.", maxTailDuration, defaultTailDuration))),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Stop after this many log entries. Cannot be greater than %d, or %d when streaming. Defaults to %d.", maxLimit, maxStreamLimit, defaultTailLimit))),
+		mcp.WithString("format", mcp.Description("Go template string to format each log entry, like in query_logs. If empty, the full JSON representation is returned.")),
+		stream.Option(),
+		governor.FullOption(),
+	)
+
+	s.AddTool(tailLogsTool, mcp.NewTypedToolHandler(t.tailLogs))
+}
+
+type tailLogsTool struct {
+	conf *config.Config
+}
+
+func (t *tailLogsTool) commands(ctx context.Context, request mcp.CallToolRequest) []string {
+	var req LogTailRequest
+	if err := request.BindArguments(&req); err != nil {
+		return nil
+	}
+	if req.ProjectID == "" {
+		req.ProjectID = session.ProjectID(ctx, request, t.conf)
+	}
+	if req.ProjectID == "" {
+		return nil
+	}
+	return []string{explain.Join("gcloud alpha logging tail", req.Query, explain.Flag("project", req.ProjectID))}
+}
+
+func (t *tailLogsTool) tailLogs(ctx context.Context, request mcp.CallToolRequest, req LogTailRequest) (*mcp.CallToolResult, error) {
+	if req.ProjectID == "" {
+		req.ProjectID = session.ProjectID(ctx, request, t.conf)
+	}
+	if req.ProjectID == "" {
+		return mcp.NewToolResultError("project_id parameter is required"), nil
+	}
+	w, err := stream.NewWriter(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	duration := defaultTailDuration
+	if req.Duration != "" {
+		if duration, err = time.ParseDuration(req.Duration); err != nil || duration <= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid duration parameter %q", req.Duration)), nil
+		}
+	}
+	if duration > maxTailDuration {
+		return mcp.NewToolResultError(fmt.Sprintf("duration parameter cannot be longer than %v", maxTailDuration)), nil
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultTailLimit
+	}
+	if w.Streaming() && req.Limit > maxStreamLimit {
+		return mcp.NewToolResultError(fmt.Sprintf("limit parameter cannot be greater than %d when streaming", maxStreamLimit)), nil
+	}
+	if !w.Streaming() && req.Limit > maxLimit {
+		return mcp.NewToolResultError(fmt.Sprintf("limit parameter cannot be greater than %d; stream the results to get more at once", maxLimit)), nil
+	}
+	if req.Format != "" {
+		if _, err := template.New("log").Parse(req.Format); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid format template: %v", err)), nil
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		duration = min(duration, time.Until(deadline)-tailMargin)
+	}
+
+	count, suppressed, err := t.tail(ctx, req, duration, w)
+	if err != nil {
+		return mcp.NewToolResultErrorf("Tail failed: %v", err), nil
+	}
+	logLines := w.String()
+	switch {
+	case count == 0:
+		logLines = fmt.Sprintf("No log entries were written in %v.", duration.Round(time.Second))
+	case w.Streaming():
+		logLines = fmt.Sprintf("Streamed %d log entries in %d progress notifications.", count, w.Chunks())
+	}
+	result := fmt.Sprintf("Project ID: %s\nLQL Query:\n```\n%s\n```\nResult:\n\n%s", req.ProjectID, req.Query, logLines)
+	if suppressed > 0 {
+		result += fmt.Sprintf("\n\n%d log entries were dropped by Cloud Logging because they were written too fast; narrow the query to see them.", suppressed)
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// tail writes the log entries matching req to w as they are written, for
+// duration or until req.Limit entries are read. It returns the number of
+// entries read and of entries the Logging API dropped.
+func (t *tailLogsTool) tail(ctx context.Context, req LogTailRequest, duration time.Duration, w *stream.Writer) (count, suppressed int, err error) {
+	client, err := gcp.Logging(ctx, t.conf)
+	if err != nil {
+		return 0, 0, err
+	}
+	formatter, err := formatterForRequest(LogQueryRequest{Format: req.Format})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create formatter: %w", err)
+	}
+
+	tailCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	tail, err := client.TailLogEntries(tailCtx)
+	if err != nil {
+		return 0, 0, err
+	}
+	err = tail.Send(&loggingpb.TailLogEntriesRequest{
+		ResourceNames: []string{fmt.Sprintf("projects/%s", req.ProjectID)},
+		Filter:        req.Query,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to start tailing log entries: %w", err)
+	}
+	for count < req.Limit {
+		resp, err := tail.Recv()
+		if err != nil {
+			// The tail ends when its duration is over.
+			if errors.Is(err, io.EOF) || (tailCtx.Err() != nil && ctx.Err() == nil) {
+				break
+			}
+			return count, suppressed, fmt.Errorf("failed to tail log entries: %w", err)
+		}
+		for _, info := range resp.GetSuppressionInfo() {
+			suppressed += int(info.GetSuppressedCount())
+		}
+		for _, entry := range resp.GetEntries() {
+			if count == req.Limit {
+				break
+			}
+			logLine, err := formatter.format(entry)
+			if err != nil {
+				return count, suppressed, fmt.Errorf("failed to format log entry: %w", err)
+			}
+			if count > 0 {
+				logLine = "\n" + logLine
+			}
+			if err := w.Write(logLine); err != nil {
+				return count, suppressed, err
+			}
+			count++
+		}
+	}
+	return count, suppressed, w.Flush()
+}
@@ -0,0 +1,411 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/telemetry"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// fieldManager is the field manager of the dry-run server-side applies.
+const fieldManager = "gke-mcp"
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("manifests", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	diffManifestsTool := mcp.NewTool("diff_manifests",
+		mcp.WithDescription("Diff Kubernetes manifests against the live state of a GKE cluster, using a server-side apply dry run, so admission webhooks, defaulting and validation are taken into account. Returns which objects would be created, changed or left unchanged and the fields that would change. Nothing is applied. Use it before applying any manifest. The caller needs permission to get and patch the objects."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.get"),
+		explain.Command(h.diffManifestsCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("manifests", mcp.Description("YAML manifests to diff, several documents separated by ---. Either this or kustomize_path is required.")),
+		mcp.WithString("kustomize_path", mcp.Description("Path of a kustomization directory on the machine the server runs on, built with `kubectl kustomize`. Not supported in mock mode.")),
+		mcp.WithString("namespace", mcp.Description("Namespace of namespaced objects that don't set one. Defaults to default.")),
+		governor.FullOption(),
+	)
+	s.AddTool(diffManifestsTool, h.diffManifests)
+
+	return nil
+}
+
+// object is a Kubernetes object decoded from a manifest or the API.
+type object = map[string]any
+
+// result is the diff of one object.
+type result struct {
+	// ID identifies the object, e.g. Deployment default/web.
+	ID      string
+	Created bool
+	Changes []change
+	Err     error
+}
+
+// change is a changed field. Old is nil for added fields and New for removed
+// ones.
+type change struct {
+	Path     string
+	Old, New any
+}
+
+func (h *handlers) diffManifests(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	cluster := session.Cluster(ctx, request, h.c, "cluster")
+	if cluster == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+
+	manifests := request.GetString("manifests", "")
+	if path := request.GetString("kustomize_path", ""); path != "" {
+		if manifests != "" {
+			return mcp.NewToolResultError("set either manifests or kustomize_path, not both"), nil
+		}
+		// kubectl would read the files of the machine the mock stands in
+		// for.
+		if h.c.Mock() != nil {
+			return mcp.NewToolResultError("kustomize_path isn't supported in mock mode, pass the manifests instead"), nil
+		}
+		var err error
+		if manifests, err = kustomize(ctx, path); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	if manifests == "" {
+		return mcp.NewToolResultError("manifests or kustomize_path argument not set"), nil
+	}
+	objects, err := decode(manifests)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(objects) == 0 {
+		return mcp.NewToolResultError("the manifests contain no objects"), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, cluster)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := request.GetString("namespace", "default")
+	results := make([]result, 0, len(objects))
+	for _, obj := range objects {
+		results = append(results, diffObject(ctx, k, obj, namespace))
+	}
+	return mcp.NewToolResultText(format(results)), nil
+}
+
+func (h *handlers) diffManifestsCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	source := "--filename=manifests.yaml"
+	if path := request.GetString("kustomize_path", ""); path != "" {
+		source = explain.Flag("kustomize", path)
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		explain.Join("kubectl diff --server-side --field-manager="+fieldManager, source, explain.Flag("namespace", request.GetString("namespace", ""))),
+	}
+}
+
+// kustomize builds the kustomization in path.
+func kustomize(ctx context.Context, path string) (string, error) {
+	ctx, span := telemetry.StartSpan(ctx, "kubectl kustomize")
+	defer span.End()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "kubectl", "kustomize", path)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("kubectl kustomize %s failed: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// decode returns the objects of YAML manifests, flattening lists.
+func decode(manifests string) ([]object, error) {
+	var objects []object
+	dec := yaml.NewDecoder(strings.NewReader(manifests))
+	for n := 1; ; n++ {
+		var obj object
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML in document %d: %w", n, err)
+		}
+		if obj == nil {
+			continue
+		}
+		if kind, _ := obj["kind"].(string); strings.HasSuffix(kind, "List") {
+			items, _ := obj["items"].([]any)
+			for _, item := range items {
+				if item, ok := item.(object); ok {
+					objects = append(objects, item)
+				}
+			}
+			continue
+		}
+		if obj["apiVersion"] == nil || obj["kind"] == nil {
+			return nil, fmt.Errorf("document %d is not a Kubernetes object: apiVersion and kind are required", n)
+		}
+		objects = append(objects, obj)
+	}
+}
+
+// diffObject dry runs the server-side apply of obj and diffs the result with
+// the live object.
+func diffObject(ctx context.Context, k *kube.Client, obj object, defaultNamespace string) result {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	metadata, _ := obj["metadata"].(map[string]any)
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+	r := result{ID: kind + " " + name}
+	if name == "" {
+		r.Err = errors.New("metadata.name is required")
+		return r
+	}
+
+	resource, err := k.Resource(ctx, apiVersion, kind)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	if resource.Namespaced {
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+		r.ID = kind + " " + namespace + "/" + name
+	}
+	path := resource.Path(namespace, name)
+
+	var live object
+	if err := k.Get(ctx, path, &live); err != nil && !kube.IsNotFound(err) {
+		r.Err = err
+		return r
+	}
+	body, err := json.Marshal(obj)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	var applied object
+	query := url.Values{"dryRun": {"All"}, "fieldManager": {fieldManager}, "force": {"true"}}
+	if err := k.Do(ctx, http.MethodPatch, path+"?"+query.Encode(), "application/apply-patch+yaml", body, &applied); err != nil {
+		r.Err = err
+		return r
+	}
+
+	r.Created = live == nil
+	if !r.Created {
+		r.Changes = diff(normalize(live), normalize(applied))
+	}
+	return r
+}
+
+// ignoredMetadata are the metadata fields maintained by the API server.
+var ignoredMetadata = []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"}
+
+// normalize removes the fields of obj that change on every write or aren't
+// set by manifests, and masks Secret data.
+func normalize(obj object) object {
+	delete(obj, "status")
+	if metadata, ok := obj["metadata"].(map[string]any); ok {
+		for _, field := range ignoredMetadata {
+			delete(metadata, field)
+		}
+		if annotations, ok := metadata["annotations"].(map[string]any); ok {
+			delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+			delete(annotations, "deployment.kubernetes.io/revision")
+		}
+	}
+	if obj["kind"] == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			if data, ok := obj[field].(map[string]any); ok {
+				for key, value := range data {
+					// Keep a hash of the value so changes are still
+					// detected without revealing it.
+					h := fnv.New32a()
+					fmt.Fprint(h, value)
+					data[key] = fmt.Sprintf("(sensitive, hash %08x)", h.Sum32())
+				}
+			}
+		}
+	}
+	return obj
+}
+
+// diff returns the changed leaf fields between two objects, in the order of
+// the paths.
+func diff(old, new object) []change {
+	oldFields, newFields := map[string]any{}, map[string]any{}
+	var oldPaths, newPaths []string
+	flatten("", old, oldFields, &oldPaths)
+	flatten("", new, newFields, &newPaths)
+	var changes []change
+	for _, path := range newPaths {
+		o, ok := oldFields[path]
+		if n := newFields[path]; !ok || !equal(o, n) {
+			changes = append(changes, change{Path: path, Old: o, New: n})
+		}
+	}
+	for _, path := range oldPaths {
+		if _, ok := newFields[path]; !ok {
+			changes = append(changes, change{Path: path, Old: oldFields[path]})
+		}
+	}
+	return changes
+}
+
+// flatten adds the leaf fields of value to fields by path, appending new
+// paths to paths. List items that are objects with a name, like
+// containers, are identified by their name rather than their index.
+func flatten(prefix string, value any, fields map[string]any, paths *[]string) {
+	add := func(path string, v any) {
+		if _, ok := fields[path]; !ok {
+			*paths = append(*paths, path)
+		}
+		fields[path] = v
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 && prefix != "" {
+			add(prefix, v)
+			return
+		}
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flatten(path, v[key], fields, paths)
+		}
+	case []any:
+		if len(v) == 0 {
+			add(prefix, v)
+			return
+		}
+		for i, item := range v {
+			key := fmt.Sprintf("[%d]", i)
+			if m, ok := item.(map[string]any); ok {
+				if name, ok := m["name"].(string); ok {
+					key = "[name=" + name + "]"
+				}
+			}
+			flatten(prefix+key, item, fields, paths)
+		}
+	default:
+		add(prefix, v)
+	}
+}
+
+// equal compares two JSON values, which may have been decoded differently,
+// e.g. 3 from YAML and 3.0 from JSON.
+func equal(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// format renders the diff of the objects.
+func format(results []result) string {
+	var created, changed, unchanged, failed int
+	var b strings.Builder
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed++
+			fmt.Fprintf(&b, "%s: error: %v\n", r.ID, r.Err)
+		case r.Created:
+			created++
+			fmt.Fprintf(&b, "%s: would be created\n", r.ID)
+		case len(r.Changes) == 0:
+			unchanged++
+			fmt.Fprintf(&b, "%s: unchanged\n", r.ID)
+		default:
+			changed++
+			fmt.Fprintf(&b, "%s: %d changed fields\n", r.ID, len(r.Changes))
+			for _, c := range r.Changes {
+				switch {
+				case c.Old == nil:
+					fmt.Fprintf(&b, "  + %s: %s\n", c.Path, value(c.New))
+				case c.New == nil:
+					fmt.Fprintf(&b, "  - %s: %s\n", c.Path, value(c.Old))
+				default:
+					fmt.Fprintf(&b, "  ~ %s: %s -> %s\n", c.Path, value(c.Old), value(c.New))
+				}
+			}
+		}
+	}
+	summary := fmt.Sprintf("%d objects: %d would be created, %d changed, %d unchanged, %d failed. Nothing was applied.\n\n", len(results), created, changed, unchanged, failed)
+	return summary + b.String()
+}
+
+// value renders a field value as JSON, shortening long strings.
+func value(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(data) > 200 {
+		return string(data[:200]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifests
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/mock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/oauth2"
+)
+
+func TestDecode(t *testing.T) {
+	manifests := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+# only a comment
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: b
+`
+	objects, err := decode(manifests)
+	if err != nil {
+		t.Fatalf("decode() failed: %v", err)
+	}
+	var names []string
+	for _, obj := range objects {
+		names = append(names, obj["metadata"].(map[string]any)["name"].(string))
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, names); diff != "" {
+		t.Errorf("decode() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := decode("metadata:\n  name: a\n"); err == nil {
+		t.Errorf("decode() of a document without kind succeeded, want an error")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	live := object{
+		"metadata": map[string]any{"name": "web", "resourceVersion": "12", "labels": map[string]any{"old": "x"}},
+		"spec": map[string]any{
+			"replicas": 2.0,
+			"template": map[string]any{"spec": map[string]any{"containers": []any{
+				map[string]any{"name": "web", "image": "web:1"},
+				map[string]any{"name": "sidecar", "image": "proxy:1"},
+			}}},
+		},
+		"status": map[string]any{"readyReplicas": 2.0},
+	}
+	applied := object{
+		"metadata": map[string]any{"name": "web", "resourceVersion": "13"},
+		"spec": map[string]any{
+			"replicas": 3,
+			"template": map[string]any{"spec": map[string]any{"containers": []any{
+				map[string]any{"name": "web", "image": "web:2"},
+				map[string]any{"name": "sidecar", "image": "proxy:1"},
+			}}},
+		},
+	}
+	want := []change{
+		{Path: "spec.replicas", Old: 2.0, New: 3},
+		{Path: "spec.template.spec.containers[name=web].image", Old: "web:1", New: "web:2"},
+		{Path: "metadata.labels.old", Old: "x"},
+	}
+	if diff := cmp.Diff(want, diff(normalize(live), normalize(applied))); diff != "" {
+		t.Errorf("diff() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiffObject(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1":
+			io.WriteString(w, `{"resources":[{"name":"configmaps","kind":"ConfigMap","namespaced":true}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/namespaces/default/configmaps/existing":
+			io.WriteString(w, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"existing","uid":"1"},"data":{"a":"1"}}`)
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"kind":"Status","message":"not found"}`)
+		case r.Method == http.MethodPatch:
+			if r.URL.Query().Get("dryRun") != "All" {
+				t.Errorf("PATCH without dryRun=All: %s", r.URL)
+			}
+			if got := r.Header.Get("Content-Type"); got != "application/apply-patch+yaml" {
+				t.Errorf("Content-Type = %q, want application/apply-patch+yaml", got)
+			}
+			io.Copy(w, r.Body)
+		}
+	}))
+	defer srv.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	k, err := kube.New(srv.URL, ca, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}))
+	if err != nil {
+		t.Fatalf("kube.New() failed: %v", err)
+	}
+
+	objects, err := decode(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: existing
+data:
+  a: "2"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: new
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+`)
+	if err != nil {
+		t.Fatalf("decode() failed: %v", err)
+	}
+	var results []result
+	for _, obj := range objects {
+		results = append(results, diffObject(context.Background(), k, obj, "default"))
+	}
+
+	want := `3 objects: 1 would be created, 1 changed, 0 unchanged, 1 failed. Nothing was applied.
+
+ConfigMap default/existing: 1 changed fields
+  ~ data.a: "1" -> "2"
+ConfigMap default/new: would be created
+Widget w: error: apiVersion example.com/v1 is not served by the cluster
+`
+	if diff := cmp.Diff(want, format(results)); diff != "" {
+		t.Errorf("format() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiffManifestsMockKustomize(t *testing.T) {
+	m, err := mock.New("")
+	if err != nil {
+		t.Fatalf("mock.New() failed: %v", err)
+	}
+	defer m.Close()
+	h := &handlers{c: config.New("test", config.WithMock(m))}
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"kustomize_path": t.TempDir()}
+	result, err := h.diffManifests(context.Background(), request)
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "mock mode") {
+		t.Errorf("diffManifests() = %+v, %v; want an error about mock mode", result, err)
+	}
+}
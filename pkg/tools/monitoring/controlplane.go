@@ -0,0 +1,305 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// defaultWindow is the window control plane metrics are aggregated over.
+	defaultWindow = time.Hour
+	// topObjects is the number of resources listed by etcd object count.
+	topObjects = 10
+)
+
+// Thresholds above which control plane signals are highlighted, after the
+// Kubernetes API call latency SLOs.
+const (
+	slowRequest    = time.Second
+	slowList       = 30 * time.Second
+	slowWebhook    = time.Second
+	highErrorRate  = 1.0
+	manyObjects    = 100000
+	manyOfResource = 50000
+)
+
+// Control plane metrics, exported to Cloud Monitoring by GKE when the
+// API_SERVER monitoring component is enabled.
+const (
+	requestDurationMetric = "prometheus.googleapis.com/apiserver_request_duration_seconds/histogram"
+	requestTotalMetric    = "prometheus.googleapis.com/apiserver_request_total/counter"
+	storageObjectsMetric  = "prometheus.googleapis.com/apiserver_storage_objects/gauge"
+	webhookDurationMetric = "prometheus.googleapis.com/apiserver_admission_webhook_admission_duration_seconds/histogram"
+)
+
+// sample is the value of a metric for a group, such as a verb.
+type sample struct {
+	Group string  `json:"group"`
+	Value float64 `json:"value"`
+}
+
+// controlPlaneReport is the result of get_control_plane_metrics.
+type controlPlaneReport struct {
+	Cluster    string   `json:"cluster"`
+	Window     string   `json:"window"`
+	Highlights []string `json:"highlights,omitempty"`
+	Notes      []string `json:"notes,omitempty"`
+	// RequestsPerSecond is the rate of API server requests.
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	// ErrorRatePercent is the percentage of requests answered with a 5xx
+	// code.
+	ErrorRatePercent float64 `json:"errorRatePercent"`
+	// RequestsByCode is the rate of requests per second by response code,
+	// other than 2xx.
+	RequestsByCode []sample `json:"requestsByCode,omitempty"`
+	// LatencyP99 is the 99th percentile latency of requests by verb, in
+	// seconds, excluding long-running WATCH and CONNECT requests.
+	LatencyP99 []sample `json:"latencyP99Seconds,omitempty"`
+	// StorageObjects is the number of objects stored in etcd for the
+	// resources with the most.
+	StorageObjects      []sample `json:"storageObjects,omitempty"`
+	TotalStorageObjects float64  `json:"totalStorageObjects"`
+	// WebhookLatencyP99 is the 99th percentile latency of admission
+	// webhooks by name, in seconds.
+	WebhookLatencyP99 []sample `json:"webhookLatencyP99Seconds,omitempty"`
+}
+
+func (h *handlers) getControlPlaneMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	cluster := session.Cluster(ctx, request, h.c, "cluster")
+	if cluster == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	window := defaultWindow
+	if w := request.GetString("window", ""); w != "" {
+		var err error
+		if window, err = time.ParseDuration(w); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid window argument: %v", err)), nil
+		}
+		if window < time.Minute {
+			return mcp.NewToolResultError("window must be at least 1m"), nil
+		}
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	c, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, cluster)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client, err := gcp.Metrics(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	q := metricQuery{client: client, projectID: projectID, location: c.GetLocation(), cluster: cluster, window: window, end: time.Now().Truncate(time.Minute)}
+	r := controlPlaneReport{Cluster: cluster, Window: window.String()}
+	byCode, err := q.samples(ctx, requestTotalMetric, monitoringpb.Aggregation_ALIGN_RATE, monitoringpb.Aggregation_REDUCE_SUM, "code")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	r.RequestsPerSecond, r.ErrorRatePercent, r.RequestsByCode = requestRates(byCode)
+	if r.LatencyP99, err = q.samples(ctx, requestDurationMetric, monitoringpb.Aggregation_ALIGN_DELTA, monitoringpb.Aggregation_REDUCE_PERCENTILE_99, "verb"); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	r.LatencyP99 = slices.DeleteFunc(r.LatencyP99, func(s sample) bool {
+		return s.Group == "WATCH" || s.Group == "CONNECT"
+	})
+	objects, err := q.samples(ctx, storageObjectsMetric, monitoringpb.Aggregation_ALIGN_MAX, monitoringpb.Aggregation_REDUCE_MAX, "resource")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	for _, s := range objects {
+		r.TotalStorageObjects += s.Value
+	}
+	r.StorageObjects = objects[:min(len(objects), topObjects)]
+	if r.WebhookLatencyP99, err = q.samples(ctx, webhookDurationMetric, monitoringpb.Aggregation_ALIGN_DELTA, monitoringpb.Aggregation_REDUCE_PERCENTILE_99, "name"); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	enabled := slices.Contains(c.GetMonitoringConfig().GetComponentConfig().GetEnableComponents(), containerpb.MonitoringComponentConfig_APISERVER)
+	if len(byCode) == 0 && len(r.LatencyP99) == 0 && len(objects) == 0 {
+		if !enabled {
+			r.Notes = append(r.Notes, fmt.Sprintf("Control plane metrics aren't collected for the cluster. Enable them with gcloud container clusters update %s --location=%s --project=%s --monitoring=SYSTEM,API_SERVER,SCHEDULER,CONTROLLER_MANAGER.", cluster, location, projectID))
+		} else {
+			r.Notes = append(r.Notes, "No control plane metrics were found in the window; they may have been enabled recently.")
+		}
+	}
+	r.Highlights = highlights(r)
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// metricQuery queries the control plane metrics of a cluster over a window.
+type metricQuery struct {
+	client              *monitoring.MetricClient
+	projectID, location string
+	cluster             string
+	window              time.Duration
+	end                 time.Time
+}
+
+// samples aggregates a metric over the window, grouped by a metric label,
+// highest value first.
+func (q metricQuery) samples(ctx context.Context, metric string, aligner monitoringpb.Aggregation_Aligner, reducer monitoringpb.Aggregation_Reducer, label string) ([]sample, error) {
+	it := q.client.ListTimeSeries(ctx, &monitoringpb.ListTimeSeriesRequest{
+		Name:     "projects/" + q.projectID,
+		Filter:   fmt.Sprintf(`metric.type="%s" AND resource.type="prometheus_target" AND resource.labels.cluster="%s" AND resource.labels.location="%s"`, metric, q.cluster, q.location),
+		Interval: &monitoringpb.TimeInterval{StartTime: timestamppb.New(q.end.Add(-q.window)), EndTime: timestamppb.New(q.end)},
+		Aggregation: &monitoringpb.Aggregation{
+			AlignmentPeriod:    durationpb.New(q.window),
+			PerSeriesAligner:   aligner,
+			CrossSeriesReducer: reducer,
+			GroupByFields:      []string{"metric.label." + label},
+		},
+	})
+	var series []*monitoringpb.TimeSeries
+	for {
+		ts, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s: %w", metric, err)
+		}
+		series = append(series, ts)
+	}
+	return toSamples(series, label), nil
+}
+
+// toSamples returns the latest value of every series, by the value of
+// label, highest first.
+func toSamples(series []*monitoringpb.TimeSeries, label string) []sample {
+	var samples []sample
+	for _, ts := range series {
+		points := ts.GetPoints()
+		if len(points) == 0 {
+			continue
+		}
+		// Points are newest first.
+		v := points[0].GetValue()
+		value := v.GetDoubleValue()
+		if _, ok := v.GetValue().(*monitoringpb.TypedValue_Int64Value); ok {
+			value = float64(v.GetInt64Value())
+		}
+		samples = append(samples, sample{Group: ts.GetMetric().GetLabels()[label], Value: value})
+	}
+	sort.SliceStable(samples, func(i, j int) bool {
+		if samples[i].Value != samples[j].Value {
+			return samples[i].Value > samples[j].Value
+		}
+		return samples[i].Group < samples[j].Group
+	})
+	return samples
+}
+
+// requestRates returns the total request rate, the percentage of 5xx
+// responses and the rates of the codes other than 2xx.
+func requestRates(byCode []sample) (total, errorRate float64, others []sample) {
+	var errors float64
+	for _, s := range byCode {
+		total += s.Value
+		if strings.HasPrefix(s.Group, "5") {
+			errors += s.Value
+		}
+		if !strings.HasPrefix(s.Group, "2") {
+			others = append(others, s)
+		}
+	}
+	if total > 0 {
+		errorRate = errors / total * 100
+	}
+	return total, errorRate, others
+}
+
+// highlights lists the signals of the report past their thresholds.
+func highlights(r controlPlaneReport) []string {
+	var result []string
+	if r.ErrorRatePercent >= highErrorRate {
+		result = append(result, fmt.Sprintf("%.1f%% of API server requests failed with a 5xx code.", r.ErrorRatePercent))
+	}
+	for _, s := range r.RequestsByCode {
+		if s.Group == "429" {
+			result = append(result, fmt.Sprintf("The API server throttled %.2f requests per second with 429 codes; check the API Priority and Fairness settings and chatty clients.", s.Value))
+		}
+	}
+	for _, s := range r.LatencyP99 {
+		limit := slowRequest
+		if s.Group == "LIST" {
+			limit = slowList
+		}
+		if s.Value >= limit.Seconds() {
+			result = append(result, fmt.Sprintf("The p99 latency of %s requests is %.2fs, above the %s SLO.", s.Group, s.Value, limit))
+		}
+	}
+	if r.TotalStorageObjects >= manyObjects {
+		result = append(result, fmt.Sprintf("etcd stores %.0f objects, which slows down LIST requests and upgrades.", r.TotalStorageObjects))
+	}
+	for _, s := range r.StorageObjects {
+		if s.Value >= manyOfResource {
+			result = append(result, fmt.Sprintf("etcd stores %.0f %s; consider cleaning up stale ones.", s.Value, s.Group))
+		}
+	}
+	for _, s := range r.WebhookLatencyP99 {
+		if s.Value >= slowWebhook.Seconds() {
+			result = append(result, fmt.Sprintf("The p99 latency of the %s admission webhook is %.2fs, which delays every request it intercepts.", s.Group, s.Value))
+		}
+	}
+	return result
+}
+
+func (h *handlers) getControlPlaneMetricsCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud container clusters describe", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--format=value(monitoringConfig.componentConfig.enableComponents)"),
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get --raw=/metrics | grep -E '^apiserver_(request_total|storage_objects|admission_webhook_admission_duration_seconds_count)'",
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"testing"
+
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/google/go-cmp/cmp"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+)
+
+func TestToSamples(t *testing.T) {
+	series := func(verb string, values ...*monitoringpb.TypedValue) *monitoringpb.TimeSeries {
+		ts := &monitoringpb.TimeSeries{Metric: &metricpb.Metric{Labels: map[string]string{"verb": verb}}}
+		for _, v := range values {
+			ts.Points = append(ts.Points, &monitoringpb.Point{Value: v})
+		}
+		return ts
+	}
+	double := func(v float64) *monitoringpb.TypedValue {
+		return &monitoringpb.TypedValue{Value: &monitoringpb.TypedValue_DoubleValue{DoubleValue: v}}
+	}
+	got := toSamples([]*monitoringpb.TimeSeries{
+		series("GET", double(0.05), double(0.9)),
+		series("LIST", double(1.2)),
+		series("POST", &monitoringpb.TypedValue{Value: &monitoringpb.TypedValue_Int64Value{Int64Value: 2}}),
+		series("PATCH"),
+	}, "verb")
+	want := []sample{{Group: "POST", Value: 2}, {Group: "LIST", Value: 1.2}, {Group: "GET", Value: 0.05}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("toSamples() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRequestRates(t *testing.T) {
+	total, errorRate, others := requestRates([]sample{
+		{Group: "200", Value: 90},
+		{Group: "201", Value: 5},
+		{Group: "429", Value: 3},
+		{Group: "500", Value: 1.5},
+		{Group: "504", Value: 0.5},
+	})
+	if total != 100 || errorRate != 2 {
+		t.Errorf("requestRates() = %v, %v, want 100, 2", total, errorRate)
+	}
+	want := []sample{{Group: "429", Value: 3}, {Group: "500", Value: 1.5}, {Group: "504", Value: 0.5}}
+	if diff := cmp.Diff(want, others); diff != "" {
+		t.Errorf("requestRates() others mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHighlights(t *testing.T) {
+	tests := []struct {
+		name   string
+		report controlPlaneReport
+		want   []string
+	}{
+		{
+			name: "healthy",
+			report: controlPlaneReport{
+				RequestsPerSecond:   40,
+				ErrorRatePercent:    0.1,
+				LatencyP99:          []sample{{Group: "LIST", Value: 2.5}, {Group: "GET", Value: 0.1}},
+				StorageObjects:      []sample{{Group: "pods", Value: 3000}},
+				TotalStorageObjects: 12000,
+			},
+		},
+		{
+			name: "degraded",
+			report: controlPlaneReport{
+				ErrorRatePercent:    2.5,
+				RequestsByCode:      []sample{{Group: "429", Value: 4}, {Group: "500", Value: 1}},
+				LatencyP99:          []sample{{Group: "LIST", Value: 31}, {Group: "PUT", Value: 1.5}, {Group: "GET", Value: 0.2}},
+				StorageObjects:      []sample{{Group: "events", Value: 90000}, {Group: "pods", Value: 20000}},
+				TotalStorageObjects: 120000,
+				WebhookLatencyP99:   []sample{{Group: "policy.example.com", Value: 9.5}},
+			},
+			want: []string{
+				"2.5% of API server requests failed with a 5xx code.",
+				"The API server throttled 4.00 requests per second with 429 codes; check the API Priority and Fairness settings and chatty clients.",
+				"The p99 latency of LIST requests is 31.00s, above the 30s SLO.",
+				"The p99 latency of PUT requests is 1.50s, above the 1s SLO.",
+				"etcd stores 120000 objects, which slows down LIST requests and upgrades.",
+				"etcd stores 90000 events; consider cleaning up stale ones.",
+				"The p99 latency of the policy.example.com admission webhook is 9.50s, which delays every request it intercepts.",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, highlights(tc.report)); diff != "" {
+				t.Errorf("highlights() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -19,20 +19,33 @@ import (
 	"fmt"
 	"strings"
 
-	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
 	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/paging"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"google.golang.org/api/iterator"
-	"google.golang.org/api/option"
+	monitoredrespb "google.golang.org/genproto/googleapis/api/monitoredres"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+// pageSize is the number of descriptors returned per call.
+const pageSize = 50
+
 type handlers struct {
 	c *config.Config
 }
 
+func init() {
+	plugin.Register(plugin.New("monitoring", Install))
+}
+
 func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
 	h := &handlers{
 		c: c,
@@ -40,38 +53,54 @@ func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
 
 	listMRDescriptorTool := mcp.NewTool("list_monitored_resource_descriptors",
 		mcp.WithDescription("List monitored resource descriptors(schema) related to GKE for this project. Prefer to use this tool instead of gcloud"),
-		mcp.WithString("project_id", mcp.DefaultString(c.DefaultProjectID()), mcp.Description("GCP project ID. If not provided, defaults to the GCP project configured in gcloud, if any")),
-		mcp.WithReadOnlyHintAnnotation(true),
+		catalog.Describe(catalog.Observability, catalog.Read, "monitoring.monitoredResourceDescriptors.list"),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context, then to the GCP project configured in gcloud, if any")),
+		paging.CursorOption(),
+		governor.FullOption(),
 	)
 	s.AddTool(listMRDescriptorTool, h.listMRDescriptor)
 
+	getControlPlaneMetricsTool := mcp.NewTool("get_control_plane_metrics",
+		mcp.WithDescription("Report control plane health signals of a GKE cluster from Cloud Monitoring over a window: API server request rate, error rate and p99 latency by verb, etcd object counts by resource and p99 latency of admission webhooks, highlighting the ones past the Kubernetes SLOs. Use it when the cluster or kubectl feels slow. Needs the API_SERVER monitoring component enabled on the cluster."),
+		catalog.Describe(catalog.Observability, catalog.Read, "container.clusters.get", "monitoring.timeSeries.list"),
+		explain.Command(h.getControlPlaneMetricsCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("window", mcp.Description(fmt.Sprintf("Window the metrics are aggregated over, ending now, like 15m or 6h. Defaults to %s.", defaultWindow))),
+	)
+	s.AddTool(getControlPlaneMetricsTool, h.getControlPlaneMetrics)
+
 	return nil
 }
 
 func (h *handlers) listMRDescriptor(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	projectID := request.GetString("project_id", h.c.DefaultProjectID())
+	projectID := session.ProjectID(ctx, request, h.c)
 	if projectID == "" {
 		return mcp.NewToolResultError("project_id argument not set"), nil
 	}
-	c, err := monitoring.NewMetricClient(ctx, option.WithUserAgent(h.c.UserAgent()))
+	cursor, err := paging.Decode(request.GetString(paging.CursorArgument, ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	c, err := gcp.Metrics(ctx, h.c)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	defer c.Close()
 	req := &monitoringpb.ListMonitoredResourceDescriptorsRequest{
 		Name: fmt.Sprintf("projects/%s", projectID),
 	}
-	it := c.ListMonitoredResourceDescriptors(ctx, req)
+	var descriptors []*monitoredrespb.MonitoredResourceDescriptor
+	nextPageToken, err := iterator.NewPager(c.ListMonitoredResourceDescriptors(ctx, req), pageSize, cursor.PageToken).NextPage(&descriptors)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	builder := new(strings.Builder)
-	for {
-		resp, err := it.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
-		}
+	for _, resp := range descriptors {
 		builder.WriteString(protojson.Format(resp))
 	}
+	if nextPageToken != "" {
+		builder.WriteString("\n" + paging.Footer(&paging.Cursor{PageToken: nextPageToken}))
+	}
 	return mcp.NewToolResultText(builder.String()), nil
 }
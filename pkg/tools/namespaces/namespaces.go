@@ -0,0 +1,626 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespaces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	clustertools "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/cluster"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// The defaults of the baseline of new namespaces.
+const (
+	defaultCPUQuota      = "8"
+	defaultMemoryQuota   = "32Gi"
+	defaultPodsQuota     = 50
+	defaultCPURequest    = "100m"
+	defaultMemoryRequest = "128Mi"
+	defaultMemoryLimit   = "512Mi"
+)
+
+// Network policies of the baseline.
+const (
+	sameNamespace = "same-namespace"
+	denyIngress   = "deny-ingress"
+	noPolicy      = "none"
+)
+
+// managedBy labels the baseline objects of namespaces.
+const managedBy = "app.kubernetes.io/managed-by=gke-mcp"
+
+// object is a Kubernetes object sent to the API.
+type object = map[string]any
+
+// namespaceName is the syntax of namespace names, DNS labels.
+var namespaceName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// systemNamespace reports whether namespace belongs to Kubernetes or GKE,
+// so it must not be deleted.
+func systemNamespace(namespace string) bool {
+	return namespace == "default" || strings.HasPrefix(namespace, "kube-") || strings.HasPrefix(namespace, "gke-") || strings.HasPrefix(namespace, "gmp-")
+}
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("namespaces", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	clusterArgs := []mcp.ToolOption{
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+	}
+
+	listNamespacesTool := mcp.NewTool("list_namespaces", append([]mcp.ToolOption{
+		mcp.WithDescription("List the namespaces of a GKE cluster with their status, creation time, labels and whether they have the baseline create_namespace applies. The caller needs permission to list namespaces."),
+		catalog.Describe(catalog.Clusters, catalog.Read, "container.namespaces.list"),
+		explain.Command(h.listNamespacesCommands),
+		mcp.WithString("label_selector", mcp.Description("Kubernetes label selector of the namespaces, e.g. team=payments.")),
+	}, clusterArgs...)...)
+	s.AddTool(listNamespacesTool, h.listNamespacesTool)
+
+	createNamespaceTool := mcp.NewTool("create_namespace", append([]mcp.ToolOption{
+		mcp.WithDescription(fmt.Sprintf("Create a namespace in a GKE cluster, onboarding a team or application in one call. Unless baseline is false, it also gets a ResourceQuota, a LimitRange giving containers default requests of %s CPU and %s memory and a default memory limit of %s, so the quota doesn't reject pods without requests, and a NetworkPolicy. The caller needs permission to create namespaces, resource quotas, limit ranges and network policies.", defaultCPURequest, defaultMemoryRequest, defaultMemoryLimit)),
+		catalog.Describe(catalog.Clusters, catalog.Write, "container.namespaces.create", "container.resourceQuotas.create", "container.limitRanges.create", "container.networkPolicies.create"),
+		explain.Command(h.createNamespaceCommands),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Name of the namespace.")),
+		mcp.WithObject("labels", mcp.Description("Labels of the namespace, e.g. {\"team\": \"payments\"}.")),
+		mcp.WithBoolean("baseline", mcp.DefaultBool(true), mcp.Description("Apply the baseline quota, limit range and network policy.")),
+		mcp.WithString("cpu_quota", mcp.Description("CPU the pods of the namespace may request in total. Defaults to "+defaultCPUQuota+".")),
+		mcp.WithString("memory_quota", mcp.Description("Memory the pods of the namespace may request in total. Defaults to "+defaultMemoryQuota+".")),
+		mcp.WithNumber("pods_quota", mcp.Description(fmt.Sprintf("Number of pods the namespace may hold. Defaults to %d.", defaultPodsQuota))),
+		mcp.WithString("network_policy", mcp.Enum(sameNamespace, denyIngress, noPolicy), mcp.DefaultString(sameNamespace), mcp.Description(sameNamespace+" only admits traffic to the pods of the namespace from the namespace itself, "+denyIngress+" admits none, and "+noPolicy+" creates no policy. Egress isn't restricted.")),
+		dryrun.Argument(h.c),
+	}, clusterArgs...)...)
+	s.AddTool(createNamespaceTool, h.createNamespace)
+
+	labelNamespaceTool := mcp.NewTool("label_namespace", append([]mcp.ToolOption{
+		mcp.WithDescription("Set or remove labels of a namespace of a GKE cluster, e.g. its team, cost center or Pod Security Admission level. Other labels are left as they are. The caller needs permission to update namespaces."),
+		catalog.Describe(catalog.Clusters, catalog.Write, "container.namespaces.update"),
+		explain.Command(h.labelNamespaceCommands),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Name of the namespace.")),
+		mcp.WithObject("labels", mcp.Description("Labels to set, e.g. {\"pod-security.kubernetes.io/enforce\": \"baseline\"}. Existing values are overwritten.")),
+		mcp.WithArray("remove_labels", mcp.Items(map[string]any{"type": "string"}), mcp.Description("Keys of the labels to remove.")),
+		dryrun.Argument(h.c),
+	}, clusterArgs...)...)
+	s.AddTool(labelNamespaceTool, h.labelNamespace)
+
+	deleteNamespaceTool := mcp.NewTool("delete_namespace", append([]mcp.ToolOption{
+		mcp.WithDescription("Delete a namespace of a GKE cluster and everything in it, including its workloads and persistent volume claims, whose volumes may be deleted with them. This can't be undone; do a dry run first to see what the namespace holds, and only delete it if the user asks to. Namespaces of Kubernetes and GKE can't be deleted. The caller needs permission to delete namespaces."),
+		catalog.Describe(catalog.Clusters, catalog.Delete, "container.namespaces.delete"),
+		explain.Command(h.deleteNamespaceCommands),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Name of the namespace. Make sure the user named or confirmed it; don't pick it yourself.")),
+		dryrun.Argument(h.c),
+	}, clusterArgs...)...)
+	s.AddTool(deleteNamespaceTool, h.deleteNamespace)
+
+	return nil
+}
+
+// namespaceTarget returns the cluster and namespace a namespace tool acts
+// on, or an error result.
+func (h *handlers) namespaceTarget(ctx context.Context, request mcp.CallToolRequest, needNamespace bool) (projectID, location, cluster, namespace string, errResult *mcp.CallToolResult) {
+	projectID = session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return "", "", "", "", mcp.NewToolResultError("project_id argument not set")
+	}
+	location = session.Location(ctx, request, h.c)
+	if location == "" {
+		return "", "", "", "", mcp.NewToolResultError("location argument not set")
+	}
+	cluster = session.Cluster(ctx, request, h.c, "cluster")
+	if cluster == "" {
+		return "", "", "", "", mcp.NewToolResultError("cluster argument not set")
+	}
+	if !needNamespace {
+		return projectID, location, cluster, "", nil
+	}
+	namespace, err := request.RequireString("namespace")
+	if err != nil {
+		return "", "", "", "", mcp.NewToolResultError(err.Error())
+	}
+	if !namespaceName.MatchString(namespace) {
+		return "", "", "", "", mcp.NewToolResultError(fmt.Sprintf("invalid namespace %q: it must be at most 63 lowercase letters, digits and dashes, starting and ending with a letter or digit", namespace))
+	}
+	return projectID, location, cluster, namespace, nil
+}
+
+// namespaceSummary is a namespace as list_namespaces returns it.
+type namespaceSummary struct {
+	Name     string            `json:"name"`
+	Status   string            `json:"status"`
+	Created  string            `json:"created"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Baseline *bool             `json:"baseline,omitempty"`
+}
+
+func (h *handlers) listNamespacesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID, location, cluster, _, errResult := h.namespaceTarget(ctx, request, false)
+	if errResult != nil {
+		return errResult, nil
+	}
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, cluster)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	path := "/api/v1/namespaces"
+	if selector := request.GetString("label_selector", ""); selector != "" {
+		path += "?" + url.Values{"labelSelector": {selector}}.Encode()
+	}
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name              string            `json:"name"`
+				CreationTimestamp string            `json:"creationTimestamp"`
+				Labels            map[string]string `json:"labels"`
+			} `json:"metadata"`
+			Status struct {
+				Phase string `json:"phase"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := k.Get(ctx, path, &list); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Namespaces with the baseline have its quota; if quotas can't be
+	// listed, whether they have it is left out.
+	withBaseline, err := baselineNamespaces(ctx, k)
+	if err != nil {
+		slog.Debug("Failed to list the baseline quotas", "cluster", cluster, "err", err)
+	}
+	namespaces := make([]namespaceSummary, 0, len(list.Items))
+	for _, item := range list.Items {
+		ns := namespaceSummary{
+			Name:    item.Metadata.Name,
+			Status:  item.Status.Phase,
+			Created: item.Metadata.CreationTimestamp,
+			Labels:  item.Metadata.Labels,
+		}
+		if withBaseline != nil {
+			baseline := withBaseline[ns.Name]
+			ns.Baseline = &baseline
+		}
+		namespaces = append(namespaces, ns)
+	}
+	data, err := json.MarshalIndent(map[string]any{"cluster": cluster, "namespaces": namespaces}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// baselineNamespaces returns the namespaces having the quota of the
+// baseline.
+func baselineNamespaces(ctx context.Context, k *kube.Client) (map[string]bool, error) {
+	var quotas struct {
+		Items []struct {
+			Metadata struct {
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/resourcequotas?"+url.Values{"labelSelector": {managedBy}}.Encode(), &quotas); err != nil {
+		return nil, err
+	}
+	namespaces := map[string]bool{}
+	for _, q := range quotas.Items {
+		namespaces[q.Metadata.Namespace] = true
+	}
+	return namespaces, nil
+}
+
+func (h *handlers) listNamespacesCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		explain.Join("kubectl get namespaces --show-labels", explain.Flag("selector", request.GetString("label_selector", ""))),
+	}
+}
+
+// baselineOptions are the settings of the baseline of a namespace.
+type baselineOptions struct {
+	cpuQuota, memoryQuota string
+	podsQuota             int
+	networkPolicy         string
+}
+
+// parseBaselineOptions returns the baseline settings of request.
+func parseBaselineOptions(request mcp.CallToolRequest) (baselineOptions, error) {
+	opts := baselineOptions{
+		cpuQuota:      request.GetString("cpu_quota", defaultCPUQuota),
+		memoryQuota:   request.GetString("memory_quota", defaultMemoryQuota),
+		podsQuota:     request.GetInt("pods_quota", defaultPodsQuota),
+		networkPolicy: request.GetString("network_policy", sameNamespace),
+	}
+	for name, q := range map[string]string{"cpu_quota": opts.cpuQuota, "memory_quota": opts.memoryQuota} {
+		if v, err := kube.ParseQuantity(q); err != nil || v <= 0 {
+			return opts, fmt.Errorf("invalid %s %q: it must be a positive Kubernetes quantity, e.g. 8 or 500m for CPU and 32Gi for memory", name, q)
+		}
+	}
+	if opts.podsQuota < 1 {
+		return opts, fmt.Errorf("invalid pods_quota %d: it must be positive", opts.podsQuota)
+	}
+	if !slices.Contains([]string{sameNamespace, denyIngress, noPolicy}, opts.networkPolicy) {
+		return opts, fmt.Errorf("invalid network_policy %q, must be %s, %s or %s", opts.networkPolicy, sameNamespace, denyIngress, noPolicy)
+	}
+	return opts, nil
+}
+
+// baselineObjects returns the quota, limit range and network policy of the
+// baseline of namespace.
+func baselineObjects(namespace string, opts baselineOptions) []object {
+	key, value, _ := strings.Cut(managedBy, "=")
+	metadata := func(name string) object {
+		return object{"name": name, "namespace": namespace, "labels": object{key: value}}
+	}
+	objects := []object{
+		{
+			"apiVersion": "v1",
+			"kind":       "ResourceQuota",
+			"metadata":   metadata("baseline-quota"),
+			"spec": object{"hard": object{
+				"requests.cpu":    opts.cpuQuota,
+				"requests.memory": opts.memoryQuota,
+				"pods":            strconv.Itoa(opts.podsQuota),
+			}},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "LimitRange",
+			"metadata":   metadata("baseline-limits"),
+			"spec": object{"limits": []any{object{
+				"type":           "Container",
+				"defaultRequest": object{"cpu": defaultCPURequest, "memory": defaultMemoryRequest},
+				"default":        object{"memory": defaultMemoryLimit},
+			}}},
+		},
+	}
+	if opts.networkPolicy == noPolicy {
+		return objects
+	}
+	spec := object{"podSelector": object{}, "policyTypes": []any{"Ingress"}}
+	if opts.networkPolicy == sameNamespace {
+		spec["ingress"] = []any{object{"from": []any{object{"podSelector": object{}}}}}
+	}
+	return append(objects, object{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "NetworkPolicy",
+		"metadata":   metadata("baseline-network"),
+		"spec":       spec,
+	})
+}
+
+// objectPath returns the path of the collection of a baseline object.
+func objectPath(obj object) string {
+	namespace := obj["metadata"].(object)["namespace"].(string)
+	switch obj["kind"] {
+	case "ResourceQuota":
+		return "/api/v1/namespaces/" + namespace + "/resourcequotas"
+	case "LimitRange":
+		return "/api/v1/namespaces/" + namespace + "/limitranges"
+	default:
+		return "/apis/networking.k8s.io/v1/namespaces/" + namespace + "/networkpolicies"
+	}
+}
+
+// labelsArgument returns the labels argument of request.
+func labelsArgument(request mcp.CallToolRequest) (map[string]string, error) {
+	raw, ok := request.GetArguments()["labels"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return nil, errors.New("labels must be an object of label keys and values")
+	}
+	labels := map[string]string{}
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("the value of label %s must be a string", k)
+		}
+		labels[k] = s
+	}
+	return labels, nil
+}
+
+func (h *handlers) createNamespace(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID, location, cluster, namespace, errResult := h.namespaceTarget(ctx, request, true)
+	if errResult != nil {
+		return errResult, nil
+	}
+	labels, err := labelsArgument(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts, err := parseBaselineOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	metadata := object{"name": namespace}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	objects := []object{{"apiVersion": "v1", "kind": "Namespace", "metadata": metadata}}
+	baseline := request.GetBool("baseline", true)
+	if baseline {
+		objects = append(objects, baselineObjects(namespace, opts)...)
+	}
+
+	if dryrun.Enabled(request, h.c) {
+		var manifests strings.Builder
+		for i, obj := range objects {
+			if i > 0 {
+				manifests.WriteString("---\n")
+			}
+			data, err := yaml.Marshal(obj)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			manifests.Write(data)
+		}
+		return dryrun.Manifests(fmt.Sprintf("create namespace %s in cluster %s", namespace, cluster), manifests.String(), h.createNamespaceCommands(ctx, request)...), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, cluster)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := k.Do(ctx, http.MethodPost, "/api/v1/namespaces", "", objects[0], nil); err != nil {
+		var statusErr *kube.StatusError
+		if errors.As(err, &statusErr) && statusErr.Code == http.StatusConflict {
+			return mcp.NewToolResultError(fmt.Sprintf("namespace %s already exists in cluster %s; use label_namespace to change its labels", namespace, cluster)), nil
+		}
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	clustertools.InvalidateNamespaces(projectID, location, cluster)
+
+	var created, failed []string
+	for _, obj := range objects[1:] {
+		id := fmt.Sprintf("%s %s", obj["kind"], obj["metadata"].(object)["name"])
+		if err := k.Do(ctx, http.MethodPost, objectPath(obj), "", obj, nil); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		created = append(created, id)
+	}
+	if len(failed) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Created namespace %s in cluster %s, but not all of its baseline:\n%s\nCreated: %s.", namespace, cluster, strings.Join(failed, "\n"), strings.Join(append([]string{"Namespace " + namespace}, created...), ", "))), nil
+	}
+
+	text := fmt.Sprintf("Created namespace %s in cluster %s.", namespace, cluster)
+	if baseline {
+		text = fmt.Sprintf("Created namespace %s in cluster %s with its baseline: %s.", namespace, cluster, strings.Join(created, ", "))
+		text += fmt.Sprintf("\nPods of the namespace may request %s CPU and %s memory in total, and run %d at most.", opts.cpuQuota, opts.memoryQuota, opts.podsQuota)
+		if opts.networkPolicy != noPolicy {
+			if c, err := h.getCluster(ctx, projectID, location, cluster); err == nil && !enforcesPolicies(c) {
+				text += fmt.Sprintf("\nWarning: cluster %s doesn't enforce network policies, so baseline-network has no effect until network policy enforcement or GKE Dataplane V2 is enabled.", cluster)
+			}
+		}
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+func (h *handlers) createNamespaceCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	namespace := request.GetString("namespace", "")
+	if projectID == "" || location == "" || cluster == "" || namespace == "" {
+		return nil
+	}
+	commands := []string{
+		explain.GetCredentials(projectID, location, cluster),
+		explain.Join("kubectl create namespace", namespace),
+	}
+	if labels, err := labelsArgument(request); err == nil && len(labels) > 0 {
+		args := []string{namespace}
+		for _, k := range slices.Sorted(maps.Keys(labels)) {
+			args = append(args, k+"="+labels[k])
+		}
+		commands = append(commands, explain.Join("kubectl label namespace", args...))
+	}
+	if request.GetBool("baseline", true) {
+		commands = append(commands, "kubectl apply --filename=baseline.yaml")
+	}
+	return commands
+}
+
+// getCluster returns a GKE cluster.
+func (h *handlers) getCluster(ctx context.Context, projectID, location, name string) (*containerpb.Cluster, error) {
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return nil, err
+	}
+	return cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+}
+
+// enforcesPolicies reports whether network policies take effect in c.
+func enforcesPolicies(c *containerpb.Cluster) bool {
+	return c.GetAutopilot().GetEnabled() || c.GetNetworkPolicy().GetEnabled() || c.GetNetworkConfig().GetDatapathProvider() == containerpb.DatapathProvider_ADVANCED_DATAPATH
+}
+
+func (h *handlers) labelNamespace(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID, location, cluster, namespace, errResult := h.namespaceTarget(ctx, request, true)
+	if errResult != nil {
+		return errResult, nil
+	}
+	labels, err := labelsArgument(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	remove := request.GetStringSlice("remove_labels", nil)
+	if len(labels) == 0 && len(remove) == 0 {
+		return mcp.NewToolResultError("labels or remove_labels argument not set"), nil
+	}
+	// A JSON merge patch removes the labels set to null.
+	patch := map[string]any{}
+	var changes []string
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		patch[k] = labels[k]
+		changes = append(changes, fmt.Sprintf("set %s=%s", k, labels[k]))
+	}
+	for _, k := range remove {
+		if _, ok := labels[k]; ok {
+			return mcp.NewToolResultError(fmt.Sprintf("label %s is both set and removed", k)), nil
+		}
+		patch[k] = nil
+		changes = append(changes, "remove "+k)
+	}
+
+	if dryrun.Enabled(request, h.c) {
+		return dryrun.Describe(fmt.Sprintf("label namespace %s of cluster %s: %s", namespace, cluster, strings.Join(changes, ", "))), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, cluster)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var updated struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+	body := map[string]any{"metadata": map[string]any{"labels": patch}}
+	if err := k.Do(ctx, http.MethodPatch, "/api/v1/namespaces/"+namespace, "application/merge-patch+json", body, &updated); err != nil {
+		if kube.IsNotFound(err) {
+			return mcp.NewToolResultError(fmt.Sprintf("namespace %s not found in cluster %s", namespace, cluster)), nil
+		}
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var now []string
+	for _, k := range slices.Sorted(maps.Keys(updated.Metadata.Labels)) {
+		now = append(now, k+"="+updated.Metadata.Labels[k])
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Labeled namespace %s of cluster %s: %s.\nIts labels are now: %s.", namespace, cluster, strings.Join(changes, ", "), strings.Join(now, ", "))), nil
+}
+
+func (h *handlers) labelNamespaceCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	namespace := request.GetString("namespace", "")
+	if projectID == "" || location == "" || cluster == "" || namespace == "" {
+		return nil
+	}
+	args := []string{namespace}
+	labels, _ := labelsArgument(request)
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		args = append(args, k+"="+labels[k])
+	}
+	for _, k := range request.GetStringSlice("remove_labels", nil) {
+		args = append(args, k+"-")
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		explain.Join("kubectl label namespace --overwrite", args...),
+	}
+}
+
+// namespaceContents are the collections listed to tell what deleting a
+// namespace deletes.
+var namespaceContents = []struct {
+	kind, path string
+}{
+	{"pods", "/api/v1/namespaces/%s/pods"},
+	{"services", "/api/v1/namespaces/%s/services"},
+	{"persistent volume claims", "/api/v1/namespaces/%s/persistentvolumeclaims"},
+}
+
+func (h *handlers) deleteNamespace(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID, location, cluster, namespace, errResult := h.namespaceTarget(ctx, request, true)
+	if errResult != nil {
+		return errResult, nil
+	}
+	if systemNamespace(namespace) {
+		return mcp.NewToolResultError(fmt.Sprintf("namespace %s belongs to Kubernetes or GKE and can't be deleted", namespace)), nil
+	}
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, cluster)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if dryrun.Enabled(request, h.c) {
+		var contents []string
+		for _, c := range namespaceContents {
+			var list struct {
+				Items []json.RawMessage `json:"items"`
+			}
+			if err := k.Get(ctx, fmt.Sprintf(c.path, namespace), &list); err != nil {
+				if kube.IsNotFound(err) {
+					return mcp.NewToolResultError(fmt.Sprintf("namespace %s not found in cluster %s", namespace, cluster)), nil
+				}
+				contents = append(contents, fmt.Sprintf("%s (couldn't be listed: %v)", c.kind, err))
+				continue
+			}
+			contents = append(contents, fmt.Sprintf("%d %s", len(list.Items), c.kind))
+		}
+		return dryrun.Describe(fmt.Sprintf("delete namespace %s of cluster %s and everything in it, including %s", namespace, cluster, strings.Join(contents, ", "))), nil
+	}
+
+	if err := k.Do(ctx, http.MethodDelete, "/api/v1/namespaces/"+namespace, "", nil, nil); err != nil {
+		if kube.IsNotFound(err) {
+			return mcp.NewToolResultError(fmt.Sprintf("namespace %s not found in cluster %s", namespace, cluster)), nil
+		}
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	clustertools.InvalidateNamespaces(projectID, location, cluster)
+	return mcp.NewToolResultText(fmt.Sprintf("Deleting namespace %s of cluster %s. It stays Terminating until everything in it is deleted, which can take a few minutes; list_namespaces shows when it's gone.", namespace, cluster)), nil
+}
+
+func (h *handlers) deleteNamespaceCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	namespace := request.GetString("namespace", "")
+	if projectID == "" || location == "" || cluster == "" || namespace == "" {
+		return nil
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		explain.Join("kubectl delete namespace", namespace),
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespaces
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/mock"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBaselineObjects(t *testing.T) {
+	opts := baselineOptions{cpuQuota: "4", memoryQuota: "8Gi", podsQuota: 20, networkPolicy: sameNamespace}
+	objects := baselineObjects("payments", opts)
+	var kinds []string
+	for _, obj := range objects {
+		kinds = append(kinds, obj["kind"].(string))
+		if ns := obj["metadata"].(object)["namespace"]; ns != "payments" {
+			t.Errorf("%s is in namespace %v, want payments", obj["kind"], ns)
+		}
+	}
+	if got := strings.Join(kinds, ","); got != "ResourceQuota,LimitRange,NetworkPolicy" {
+		t.Errorf("baseline kinds = %s, want ResourceQuota,LimitRange,NetworkPolicy", got)
+	}
+	hard := objects[0]["spec"].(object)["hard"].(object)
+	if hard["requests.cpu"] != "4" || hard["requests.memory"] != "8Gi" || hard["pods"] != "20" {
+		t.Errorf("quota = %v, want the requested one", hard)
+	}
+	if _, ok := objects[2]["spec"].(object)["ingress"]; !ok {
+		t.Errorf("%s policy has no ingress rule, want one from the namespace", sameNamespace)
+	}
+
+	opts.networkPolicy = denyIngress
+	if _, ok := baselineObjects("payments", opts)[2]["spec"].(object)["ingress"]; ok {
+		t.Errorf("%s policy has an ingress rule, want none", denyIngress)
+	}
+	opts.networkPolicy = noPolicy
+	if n := len(baselineObjects("payments", opts)); n != 2 {
+		t.Errorf("baseline without network policy has %d objects, want 2", n)
+	}
+}
+
+func TestParseBaselineOptions(t *testing.T) {
+	for name, args := range map[string]map[string]any{
+		"cpu":            {"cpu_quota": "lots"},
+		"memory":         {"memory_quota": "0"},
+		"pods":           {"pods_quota": 0},
+		"network policy": {"network_policy": "allow-all"},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		if _, err := parseBaselineOptions(request); err == nil {
+			t.Errorf("parseBaselineOptions() with an invalid %s succeeded, want an error", name)
+		}
+	}
+}
+
+func TestNamespaceTools(t *testing.T) {
+	m, err := mock.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	h := &handlers{c: config.New("test", config.WithMock(m))}
+	ctx := context.Background()
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) (string, bool) {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler(ctx, request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	for _, tc := range []struct {
+		name    string
+		handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		args    map[string]any
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "list",
+			handler: h.listNamespacesTool,
+			want:    `"name": "shop",`,
+		},
+		{
+			name:    "create",
+			handler: h.createNamespace,
+			args:    map[string]any{"namespace": "payments", "labels": map[string]any{"team": "payments"}},
+			want:    "with its baseline: ResourceQuota baseline-quota, LimitRange baseline-limits, NetworkPolicy baseline-network.",
+		},
+		{
+			name:    "create dry run",
+			handler: h.createNamespace,
+			args:    map[string]any{"namespace": "payments", "network_policy": noPolicy, "dry_run": true},
+			want:    "name: baseline-limits",
+		},
+		{
+			name:    "create existing",
+			handler: h.createNamespace,
+			args:    map[string]any{"namespace": "shop"},
+			want:    "already exists",
+			wantErr: true,
+		},
+		{
+			name:    "create invalid",
+			handler: h.createNamespace,
+			args:    map[string]any{"namespace": "Shop"},
+			want:    "invalid namespace",
+			wantErr: true,
+		},
+		{
+			name:    "label",
+			handler: h.labelNamespace,
+			args:    map[string]any{"namespace": "shop", "labels": map[string]any{"team": "commerce"}, "remove_labels": []any{"owner"}},
+			want:    "set team=commerce, remove owner.\nIts labels are now: kubernetes.io/metadata.name=shop, team=commerce.",
+		},
+		{
+			name:    "label nothing",
+			handler: h.labelNamespace,
+			args:    map[string]any{"namespace": "shop"},
+			wantErr: true,
+		},
+		{
+			name:    "delete dry run",
+			handler: h.deleteNamespace,
+			args:    map[string]any{"namespace": "shop", "dry_run": true},
+			want:    "including 7 pods, 3 services, 1 persistent volume claims",
+		},
+		{
+			name:    "delete",
+			handler: h.deleteNamespace,
+			args:    map[string]any{"namespace": "shop"},
+			want:    "Deleting namespace shop",
+		},
+		{
+			name:    "delete system namespace",
+			handler: h.deleteNamespace,
+			args:    map[string]any{"namespace": "kube-system"},
+			want:    "can't be deleted",
+			wantErr: true,
+		},
+		{
+			name:    "delete missing",
+			handler: h.deleteNamespace,
+			args:    map[string]any{"namespace": "gone"},
+			want:    "not found",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			text, isErr := call(tc.handler, tc.args)
+			if isErr != tc.wantErr || !strings.Contains(text, tc.want) {
+				t.Errorf("result = %q (error %v), want %q (error %v)", text, isErr, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,524 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/scan"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	maxPlanSteps = 20
+	// maxPlanCalls bounds the tool calls of a plan, for_each fan-outs
+	// included.
+	maxPlanCalls = 100
+	// itemReference names the element a for_each step is called for.
+	itemReference = "item"
+)
+
+var (
+	stepIDPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	// referencePattern matches references to the output of a step, or to
+	// the for_each item, like {{clusters.clusters.0.name}}.
+	referencePattern = regexp.MustCompile(`\{\{\s*([a-z][a-z0-9_]*)((?:\.[A-Za-z0-9_-]+)*)\s*\}\}`)
+)
+
+// planStep is a tool call of a plan.
+type planStep struct {
+	ID        string         `json:"id"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// ForEach references an array the tool is called for each element of.
+	ForEach string `json:"for_each,omitempty"`
+}
+
+// planCall is the outcome of a single tool call of a step.
+type planCall struct {
+	label  string
+	text   string
+	failed bool
+	output any
+}
+
+// stepOutcome is the outcome of a step: its calls, or why it didn't run.
+type stepOutcome struct {
+	calls   []planCall
+	skipped string
+	// output is what later steps reference: the output of the call, or
+	// the outputs of the for_each calls.
+	output any
+}
+
+// failed reports whether later steps can't use the outcome: the step was
+// skipped, or all its calls failed.
+func (o *stepOutcome) failed() bool {
+	if o.skipped != "" {
+		return true
+	}
+	for _, c := range o.calls {
+		if !c.failed {
+			return false
+		}
+	}
+	return len(o.calls) > 0
+}
+
+// addRunPlanTool registers run_plan. Like server_info, it is added once the
+// tool set is final.
+func addRunPlanTool(s *server.MCPServer) {
+	tool := mcp.NewTool("run_plan",
+		mcp.WithDescription("Run a sequence of read-only tool calls of this server in a single request, passing the outputs of earlier steps to later ones, and return all their results. Use it for multi-step analyses that fan out to many clusters or APIs, e.g. list the clusters, then run cluster_health_check on each, instead of calling the tools one at a time. A step's arguments can reference the output of an earlier step as {{step_id}} or a field of it as {{step_id.field.0.name}}, using its structured result or JSON output; a step with for_each, e.g. \"{{clusters.clusters}}\", is called once per element, referenced as {{item}} or {{item.name}}. Independent steps run concurrently; steps depending on a failed step are skipped."),
+		catalog.Describe(catalog.Server, catalog.Query),
+		explain.Command(planCommands),
+		mcp.WithArray("steps", mcp.Required(), mcp.Description(fmt.Sprintf("Steps of the plan, at most %d, making at most %d tool calls in total. Steps may only reference steps declared before them.", maxPlanSteps, maxPlanCalls)), mcp.Items(map[string]any{
+			"type":     "object",
+			"required": []string{"id", "tool"},
+			"properties": map[string]any{
+				"id":        map[string]any{"type": "string", "description": "Identifier of the step, in lower snake case, for references."},
+				"tool":      map[string]any{"type": "string", "description": "Name of a read-only tool of this server."},
+				"arguments": map[string]any{"type": "object", "description": "Arguments of the tool call, which may contain references."},
+				"for_each":  map[string]any{"type": "string", "description": "Reference to an array of an earlier step's output, to call the tool for each element."},
+			},
+		})),
+	)
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		steps, err := parsePlan(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		tools, err := ListTools(ctx, s)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := checkPlan(steps, tools); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		// Each call gets its own request ID, so the calls running
+		// concurrently don't collide where calls are tracked by ID, like in
+		// cancellation.Registry.
+		prefix := "run_plan-" + rand.Text()
+		var calls atomic.Int64
+		outcomes := runPlan(ctx, steps, func(ctx context.Context, name string, args map[string]any) (*mcp.CallToolResult, error) {
+			return callTool(ctx, s, fmt.Sprintf("%s-%d", prefix, calls.Add(1)), name, args)
+		})
+		return mcp.NewToolResultText(formatPlan(steps, outcomes)), nil
+	})
+}
+
+func parsePlan(request mcp.CallToolRequest) ([]planStep, error) {
+	raw, ok := request.GetArguments()["steps"]
+	if !ok {
+		return nil, fmt.Errorf("required argument \"steps\" not found")
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var steps []planStep
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("invalid steps: %w", err)
+	}
+	return steps, nil
+}
+
+// checkPlan makes sure the steps call read-only tools and only reference
+// steps declared before them, which also rules out cycles.
+func checkPlan(steps []planStep, tools []mcp.Tool) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("the plan has no steps")
+	}
+	if len(steps) > maxPlanSteps {
+		return fmt.Errorf("the plan has %d steps, more than the maximum of %d; split it into several plans", len(steps), maxPlanSteps)
+	}
+	byName := map[string]mcp.Tool{}
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+	declared := map[string]bool{}
+	for i, step := range steps {
+		switch {
+		case !stepIDPattern.MatchString(step.ID) || step.ID == itemReference:
+			return fmt.Errorf("step %d: invalid id %q; use lower snake case, other than %q", i+1, step.ID, itemReference)
+		case declared[step.ID]:
+			return fmt.Errorf("step %d: duplicate id %q", i+1, step.ID)
+		case step.Tool == "run_plan":
+			return fmt.Errorf("step %s: plans can't be nested", step.ID)
+		}
+		tool, ok := byName[step.Tool]
+		if !ok {
+			return fmt.Errorf("step %s: unknown tool %q", step.ID, step.Tool)
+		}
+		if !IsReadOnly(tool) {
+			return fmt.Errorf("step %s: %s is not read-only; plans only run read-only tools, call it directly instead", step.ID, step.Tool)
+		}
+		if step.ForEach != "" && !referencePattern.MatchString(step.ForEach) {
+			return fmt.Errorf("step %s: for_each must be a reference like {{step_id.field}}", step.ID)
+		}
+		for _, ref := range stepReferences(step) {
+			switch {
+			case ref == itemReference && step.ForEach == "":
+				return fmt.Errorf("step %s: {{%s}} is only defined in steps with for_each", step.ID, itemReference)
+			case ref != itemReference && !declared[ref]:
+				return fmt.Errorf("step %s: references step %q, which isn't declared before it", step.ID, ref)
+			}
+		}
+		if refs := referencePattern.FindAllStringSubmatch(step.ForEach, -1); len(refs) > 0 && refs[0][1] == itemReference {
+			return fmt.Errorf("step %s: for_each can't reference {{%s}}", step.ID, itemReference)
+		}
+		declared[step.ID] = true
+	}
+	return nil
+}
+
+// stepReferences returns the ids referenced by a step, {{item}} included.
+func stepReferences(step planStep) []string {
+	var refs []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			for _, m := range referencePattern.FindAllStringSubmatch(v, -1) {
+				refs = append(refs, m[1])
+			}
+		case map[string]any:
+			for _, e := range v {
+				walk(e)
+			}
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(step.ForEach)
+	walk(step.Arguments)
+	return refs
+}
+
+type toolCaller func(ctx context.Context, name string, args map[string]any) (*mcp.CallToolResult, error)
+
+// runPlan runs the steps in waves of the steps whose dependencies are done,
+// running the steps of a wave, and the calls of a for_each step,
+// concurrently.
+func runPlan(ctx context.Context, steps []planStep, call toolCaller) map[string]*stepOutcome {
+	outcomes := map[string]*stepOutcome{}
+	var calls atomic.Int64
+	for len(outcomes) < len(steps) {
+		var wave []planStep
+		for _, step := range steps {
+			if outcomes[step.ID] != nil {
+				continue
+			}
+			ready := true
+			for _, ref := range stepReferences(step) {
+				if ref != itemReference && outcomes[ref] == nil {
+					ready = false
+				}
+			}
+			if ready {
+				wave = append(wave, step)
+			}
+		}
+		if len(wave) == 0 {
+			// checkPlan rules this out.
+			break
+		}
+		// Outcomes are only read by the steps of later waves.
+		done := map[string]*stepOutcome{}
+		for k, v := range outcomes {
+			done[k] = v
+		}
+		for _, r := range scan.Run(ctx, wave, scan.DefaultWorkers, func(ctx context.Context, step planStep) (*stepOutcome, error) {
+			return runStep(ctx, step, done, &calls, call), nil
+		}) {
+			outcomes[r.Target.ID] = r.Value
+			if r.Err != nil {
+				outcomes[r.Target.ID] = &stepOutcome{skipped: r.Err.Error()}
+			}
+		}
+	}
+	return outcomes
+}
+
+func runStep(ctx context.Context, step planStep, done map[string]*stepOutcome, calls *atomic.Int64, call toolCaller) *stepOutcome {
+	for _, ref := range stepReferences(step) {
+		if o := done[ref]; o != nil && o.failed() {
+			return &stepOutcome{skipped: fmt.Sprintf("step %s, which it depends on, failed", ref)}
+		}
+	}
+	items := []any{nil}
+	if step.ForEach != "" {
+		v, err := resolve(step.ForEach, done, nil)
+		if err != nil {
+			return &stepOutcome{skipped: err.Error()}
+		}
+		var ok bool
+		if items, ok = v.([]any); !ok {
+			return &stepOutcome{skipped: fmt.Sprintf("for_each %s is not an array", step.ForEach)}
+		}
+	}
+	if n := calls.Add(int64(len(items))); n > maxPlanCalls {
+		return &stepOutcome{skipped: fmt.Sprintf("the plan exceeds the maximum of %d tool calls", maxPlanCalls)}
+	}
+
+	results := scan.Run(ctx, items, scan.DefaultWorkers, func(ctx context.Context, item any) (planCall, error) {
+		label := ""
+		if step.ForEach != "" {
+			label = itemLabel(item)
+		}
+		args, err := resolve(step.Arguments, done, item)
+		if err != nil {
+			return planCall{label: label, text: err.Error(), failed: true}, nil
+		}
+		argMap, _ := args.(map[string]any)
+		result, err := call(ctx, step.Tool, argMap)
+		if err != nil {
+			return planCall{label: label, text: err.Error(), failed: true}, nil
+		}
+		text, output := resultOutput(result)
+		return planCall{label: label, text: text, failed: result.IsError, output: output}, nil
+	})
+	o := &stepOutcome{}
+	var outputs []any
+	for _, r := range results {
+		c := r.Value
+		if r.Err != nil {
+			c = planCall{label: itemLabel(r.Target), text: r.Err.Error(), failed: true}
+		}
+		o.calls = append(o.calls, c)
+		outputs = append(outputs, c.output)
+	}
+	if step.ForEach != "" {
+		o.output = outputs
+	} else {
+		o.output = outputs[0]
+	}
+	return o
+}
+
+// resultOutput returns the text of a tool result, and the output later
+// steps reference: its structured result, else its text, decoded if JSON.
+func resultOutput(result *mcp.CallToolResult) (string, any) {
+	var texts []string
+	var output any
+	for _, content := range result.Content {
+		switch content := content.(type) {
+		case mcp.TextContent:
+			texts = append(texts, content.Text)
+		case mcp.EmbeddedResource:
+			if r, ok := content.Resource.(mcp.TextResourceContents); ok && structured.IsStructured(content) {
+				json.Unmarshal([]byte(r.Text), &output)
+			}
+		}
+	}
+	text := strings.Join(texts, "\n")
+	if result.IsError {
+		return text, nil
+	}
+	if output == nil {
+		if err := json.Unmarshal([]byte(text), &output); err != nil {
+			output = text
+		}
+	}
+	return text, output
+}
+
+// resolve replaces the references in v by the values they reference. A
+// string that is a single reference is replaced by the value, whatever its
+// type; references within longer strings are replaced by the value as text.
+func resolve(v any, done map[string]*stepOutcome, item any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		if m := referencePattern.FindStringSubmatch(v); m != nil && m[0] == strings.TrimSpace(v) {
+			return lookup(m[1], m[2], done, item)
+		}
+		var err error
+		s := referencePattern.ReplaceAllStringFunc(v, func(ref string) string {
+			m := referencePattern.FindStringSubmatch(ref)
+			value, lookupErr := lookup(m[1], m[2], done, item)
+			if lookupErr != nil {
+				err = lookupErr
+				return ""
+			}
+			if s, ok := value.(string); ok {
+				return s
+			}
+			data, _ := json.Marshal(value)
+			return string(data)
+		})
+		return s, err
+	case map[string]any:
+		out := map[string]any{}
+		for k, e := range v {
+			r, err := resolve(e, done, item)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			r, err := resolve(e, done, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// lookup returns the value at path, like .clusters.0.name, in the output of
+// step id or in the for_each item.
+func lookup(id, path string, done map[string]*stepOutcome, item any) (any, error) {
+	v := item
+	if id != itemReference {
+		v = done[id].output
+	}
+	ref := id
+	for _, field := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		if field == "" {
+			continue
+		}
+		ref += "." + field
+		switch current := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = current[field]; !ok {
+				return nil, fmt.Errorf("{{%s}} is not set", ref)
+			}
+		case []any:
+			i, err := strconv.Atoi(field)
+			if err != nil || i < 0 || i >= len(current) {
+				return nil, fmt.Errorf("{{%s}} is not an index of the array", ref)
+			}
+			v = current[i]
+		default:
+			return nil, fmt.Errorf("{{%s}} can't be resolved: the output of %s isn't a JSON object or array", ref, id)
+		}
+	}
+	return v, nil
+}
+
+// itemLabel names a for_each item in the results, by its name if it has one.
+func itemLabel(item any) string {
+	if m, ok := item.(map[string]any); ok {
+		if name, ok := m["name"].(string); ok {
+			return name
+		}
+	}
+	if s, ok := item.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(item)
+	return string(data)
+}
+
+// callTool calls a tool of s through its whole middleware chain, like a
+// call from the client with request ID id.
+func callTool(ctx context.Context, s *server.MCPServer, id, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	msg, err := json.Marshal(map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      id,
+		"method":  string(mcp.MethodToolsCall),
+		"params":  map[string]any{"name": name, "arguments": args},
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch resp := s.HandleMessage(ctx, msg).(type) {
+	case mcp.JSONRPCResponse:
+		result, ok := resp.Result.(mcp.CallToolResult)
+		if !ok {
+			return nil, fmt.Errorf("unexpected tools/call result type %T", resp.Result)
+		}
+		return &result, nil
+	case mcp.JSONRPCError:
+		return nil, fmt.Errorf("%s failed: %s", name, resp.Error.Message)
+	default:
+		return nil, fmt.Errorf("unexpected tools/call response type %T", resp)
+	}
+}
+
+func formatPlan(steps []planStep, outcomes map[string]*stepOutcome) string {
+	var b strings.Builder
+	var calls, failed, skipped int
+	for _, step := range steps {
+		o := outcomes[step.ID]
+		fmt.Fprintf(&b, "\n## Step %s: %s", step.ID, step.Tool)
+		if step.ForEach != "" {
+			fmt.Fprintf(&b, " for each of %s", step.ForEach)
+		}
+		b.WriteString("\n")
+		if o.skipped != "" {
+			skipped++
+			fmt.Fprintf(&b, "Skipped: %s.\n", o.skipped)
+			continue
+		}
+		for _, c := range o.calls {
+			calls++
+			if c.label != "" {
+				fmt.Fprintf(&b, "\n### %s\n", c.label)
+			}
+			if c.failed {
+				failed++
+				b.WriteString("Failed: ")
+			}
+			b.WriteString(c.text + "\n")
+		}
+	}
+	summary := fmt.Sprintf("Ran %d of %d steps with %d tool calls: %d failed", len(steps)-skipped, len(steps), calls, failed)
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	return summary + ".\n" + b.String()
+}
+
+// planCommands returns the commands of the steps whose arguments don't
+// depend on other steps.
+func planCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	steps, err := parsePlan(request)
+	if err != nil {
+		return nil
+	}
+	var commands []string
+	for _, step := range steps {
+		if len(stepReferences(step)) > 0 {
+			continue
+		}
+		call := mcp.CallToolRequest{}
+		call.Params.Name = step.Tool
+		call.Params.Arguments = step.Arguments
+		c, _ := explain.Commands(ctx, step.Tool, call)
+		commands = append(commands, c...)
+	}
+	return commands
+}
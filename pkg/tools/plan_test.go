@@ -0,0 +1,167 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func newPlanServer(opts ...server.ServerOption) *server.MCPServer {
+	s := server.NewMCPServer("test", "0.0.0", append(opts, server.WithToolCapabilities(true))...)
+	s.AddTool(mcp.NewTool("list", mcp.WithReadOnlyHintAnnotation(true)), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(`{"clusters":[{"name":"a","location":"us-central1"},{"name":"b","location":"europe-west1"}]}`), nil
+	})
+	s.AddTool(mcp.NewTool("describe", mcp.WithReadOnlyHintAnnotation(true)), func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetString("cluster", "") == "b" {
+			return mcp.NewToolResultError("cluster b is unreachable"), nil
+		}
+		data, _ := json.Marshal(request.GetArguments())
+		return mcp.NewToolResultText(string(data)), nil
+	})
+	s.AddTool(mcp.NewTool("fail", mcp.WithReadOnlyHintAnnotation(true)), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("failed"), nil
+	})
+	s.AddTool(mcp.NewTool("write", mcp.WithReadOnlyHintAnnotation(false)), noop)
+	addRunPlanTool(s)
+	return s
+}
+
+func runTestPlan(t *testing.T, s *server.MCPServer, steps string) *mcp.CallToolResult {
+	t.Helper()
+	var args map[string]any
+	if err := json.Unmarshal([]byte(`{"steps":`+steps+`}`), &args); err != nil {
+		t.Fatal(err)
+	}
+	result, err := callTool(context.Background(), s, "test", "run_plan", args)
+	if err != nil {
+		t.Fatalf("run_plan failed: %v", err)
+	}
+	return result
+}
+
+func TestRunPlan(t *testing.T) {
+	s := newPlanServer()
+	result := runTestPlan(t, s, `[
+		{"id": "clusters", "tool": "list"},
+		{"id": "first", "tool": "describe", "arguments": {"cluster": "{{clusters.clusters.0.name}}", "note": "in {{clusters.clusters.0.location}}"}},
+		{"id": "each", "tool": "describe", "for_each": "{{clusters.clusters}}", "arguments": {"cluster": "{{item.name}}", "location": "{{item.location}}"}},
+		{"id": "broken", "tool": "fail"},
+		{"id": "after_broken", "tool": "describe", "arguments": {"input": "{{broken}}"}}
+	]`)
+	if result.IsError {
+		t.Fatalf("run_plan failed: %v", result.Content)
+	}
+	got := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"Ran 4 of 5 steps with 5 tool calls: 2 failed, 1 skipped.",
+		`{"cluster":"a","note":"in us-central1"}`,
+		"## Step each: describe for each of {{clusters.clusters}}\n\n### a\n" + `{"cluster":"a","location":"us-central1"}`,
+		"### b\nFailed: cluster b is unreachable",
+		"## Step after_broken: describe\nSkipped: step broken, which it depends on, failed.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("run_plan result doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunPlanRequestIDs(t *testing.T) {
+	var mu sync.Mutex
+	ids := map[string]int{}
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(func(_ context.Context, id any, request *mcp.CallToolRequest) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids[fmt.Sprint(id)]++
+	})
+	s := newPlanServer(server.WithHooks(hooks))
+	result := runTestPlan(t, s, `[
+		{"id": "clusters", "tool": "list"},
+		{"id": "each", "tool": "describe", "for_each": "{{clusters.clusters}}", "arguments": {"cluster": "{{item.name}}"}}
+	]`)
+	if result.IsError {
+		t.Fatalf("run_plan failed: %v", result.Content)
+	}
+	// The plan itself and its 3 nested calls.
+	if len(ids) != 4 {
+		t.Errorf("request IDs = %v, want 4 distinct ones", ids)
+	}
+	for id, n := range ids {
+		if n != 1 {
+			t.Errorf("request ID %s used by %d calls", id, n)
+		}
+	}
+}
+
+func TestRunPlanRejectsInvalidPlans(t *testing.T) {
+	s := newPlanServer()
+	for _, tc := range []struct {
+		name  string
+		steps string
+		want  string
+	}{
+		{"empty", `[]`, "the plan has no steps"},
+		{"mutating", `[{"id": "w", "tool": "write"}]`, "step w: write is not read-only"},
+		{"unknown tool", `[{"id": "x", "tool": "missing"}]`, `step x: unknown tool "missing"`},
+		{"nested", `[{"id": "x", "tool": "run_plan"}]`, "step x: plans can't be nested"},
+		{"duplicate", `[{"id": "x", "tool": "list"}, {"id": "x", "tool": "list"}]`, `step 2: duplicate id "x"`},
+		{"forward reference", `[{"id": "x", "tool": "describe", "arguments": {"a": "{{y}}"}}, {"id": "y", "tool": "list"}]`, `step x: references step "y", which isn't declared before it`},
+		{"item without for_each", `[{"id": "x", "tool": "describe", "arguments": {"a": "{{item}}"}}]`, "step x: {{item}} is only defined in steps with for_each"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result := runTestPlan(t, s, tc.steps)
+			if got := result.Content[0].(mcp.TextContent).Text; !result.IsError || !strings.Contains(got, tc.want) {
+				t.Errorf("run_plan = %q, want an error containing %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	done := map[string]*stepOutcome{
+		"clusters": {output: map[string]any{"clusters": []any{map[string]any{"name": "a", "nodes": 3.0}}}},
+	}
+	got, err := resolve(map[string]any{
+		"list":  "{{clusters.clusters}}",
+		"count": "{{ clusters.clusters.0.nodes }}",
+		"text":  "{{clusters.clusters.0.name}} has {{clusters.clusters.0.nodes}} nodes",
+		"item":  []any{"{{item}}"},
+	}, done, "x")
+	if err != nil {
+		t.Fatalf("resolve() failed: %v", err)
+	}
+	want := map[string]any{
+		"list":  []any{map[string]any{"name": "a", "nodes": 3.0}},
+		"count": 3.0,
+		"text":  "a has 3 nodes",
+		"item":  []any{"x"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("resolve() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := resolve("{{clusters.clusters.1.name}}", done, nil); err == nil {
+		t.Error("resolve() of an index out of range succeeded")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/availability"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// probeResult is the result of probe_tools.
+type probeResult struct {
+	*availability.Probe
+	// Hidden tells whether the unavailable tools are hidden.
+	Hidden      bool              `json:"hidden"`
+	Unavailable map[string]string `json:"unavailable_tools,omitempty"`
+}
+
+// addProbeToolsTool registers probe_tools. Like server_info, it is added once
+// the tool set is final. It has no permissions, so it's never hidden itself.
+func addProbeToolsTool(s *server.MCPServer, c *config.Config) {
+	tool := mcp.NewTool("probe_tools",
+		mcp.WithDescription("Check again which APIs are enabled on a project and which IAM permissions the caller holds there, and list the tools that are unavailable as a result, with why. With --hide-unavailable-tools, those tools are hidden until probed again. Call it after enabling an API or granting a role, or to find out why a tool is missing."),
+		catalog.Describe(catalog.Server, catalog.Query),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+	)
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		projectID := session.ProjectID(ctx, request, c)
+		if projectID == "" {
+			return mcp.NewToolResultError("project_id argument not set"), nil
+		}
+		// Listed outside of the session, the tools include the hidden ones.
+		tools, err := ListTools(context.Background(), s)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		p := availability.Default.Get(ctx, c, projectID, true)
+		result := probeResult{Probe: p, Hidden: c.HideUnavailableTools(), Unavailable: map[string]string{}}
+		for _, tool := range tools {
+			e, _ := catalog.Lookup(tool.Name)
+			if reason := p.Unavailable(e); reason != "" {
+				result.Unavailable[tool.Name] = reason
+			}
+		}
+		if c.HideUnavailableTools() {
+			if err := s.SendNotificationToClient(ctx, string(mcp.MethodNotificationToolsListChanged), nil); err != nil {
+				slog.Debug("Failed to notify the client of the tools change", "err", err)
+			}
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	})
+}
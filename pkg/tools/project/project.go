@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	resourcemanagerpb "cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/cache"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/completion"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/paging"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/scan"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"google.golang.org/api/iterator"
+)
+
+// pageSize is the number of projects searched per call.
+const pageSize = 50
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("project", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	listProjectsTool := mcp.NewTool("list_projects",
+		mcp.WithDescription("List the GCP projects the user can access that contain GKE clusters, with the number of clusters in each. Use it when the user doesn't know the project ID or asks about several projects, then pass the project IDs to the other tools or pin one with set_context."),
+		catalog.Describe(catalog.Fleet, catalog.Read, "resourcemanager.projects.get", "container.clusters.list"),
+		explain.Command(listProjectsCommands),
+		mcp.WithString("query", mcp.Description("Resource Manager search query to narrow the projects, e.g. 'displayName:prod*', 'labels.env:prod' or 'parent:folders/123'. Leave empty to search all accessible projects.")),
+		mcp.WithBoolean("include_without_clusters", mcp.Description("Also list projects without GKE clusters, or where the GKE API isn't enabled.")),
+		paging.CursorOption(),
+	)
+	s.AddTool(listProjectsTool, h.listProjects)
+
+	completion.Default.Register(h.completeProjects, "project_id", "project")
+
+	return nil
+}
+
+// projectsCache holds the IDs of the projects the caller can access, for
+// completion.
+var projectsCache = cache.New[[]string]("projects")
+
+// maxCompletedProjects is the number of projects searched for completion.
+const maxCompletedProjects = 1000
+
+func (h *handlers) completeProjects(ctx context.Context, _ map[string]string) ([]string, error) {
+	ids, _, err := projectsCache.Get(ctx, "projects|"+auth.CacheKey(ctx, h.c), h.c.CacheTTL(config.CacheProjects), false, func(ctx context.Context) ([]string, error) {
+		client, err := gcp.Projects(ctx, h.c)
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []string
+		it := client.SearchProjects(ctx, &resourcemanagerpb.SearchProjectsRequest{Query: "state:ACTIVE"})
+		for len(ids) < maxCompletedProjects {
+			p, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, p.GetProjectId())
+		}
+		return ids, nil
+	})
+	return append([]string{session.Default.Get(ctx).ProjectID, h.c.DefaultProjectID()}, ids...), err
+}
+
+// projectClusters is a project and its number of GKE clusters.
+type projectClusters struct {
+	project  *resourcemanagerpb.Project
+	clusters int
+}
+
+// listProjectsCommands returns the commands listing the projects and the
+// clusters of one of them, as gcloud doesn't count them per project.
+func listProjectsCommands(_ context.Context, _ mcp.CallToolRequest) []string {
+	return []string{
+		"gcloud projects list",
+		"gcloud container clusters list --project=PROJECT_ID",
+	}
+}
+
+func (h *handlers) listProjects(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cursor, err := paging.Decode(request.GetString(paging.CursorArgument, ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	query := "state:ACTIVE"
+	if q := request.GetString("query", ""); q != "" {
+		query += " " + q
+	}
+	includeAll := request.GetBool("include_without_clusters", false)
+
+	rmClient, err := gcp.Projects(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var projects []*resourcemanagerpb.Project
+	it := rmClient.SearchProjects(ctx, &resourcemanagerpb.SearchProjectsRequest{Query: query})
+	nextPageToken, err := iterator.NewPager(it, pageSize, cursor.PageToken).NextPage(&projects)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	results := scan.Run(ctx, projects, scan.DefaultWorkers, func(ctx context.Context, p *resourcemanagerpb.Project) (int, error) {
+		resp, err := cmClient.ListClusters(ctx, &containerpb.ListClustersRequest{
+			Parent: fmt.Sprintf("projects/%s/locations/-", p.GetProjectId()),
+		})
+		if err != nil {
+			return 0, err
+		}
+		return len(resp.GetClusters()), nil
+	})
+
+	builder := new(strings.Builder)
+	found := 0
+	for _, r := range results {
+		if !includeAll && (r.Err != nil || r.Value == 0) {
+			continue
+		}
+		found++
+		fmt.Fprintf(builder, "- %s (%s): ", r.Target.GetProjectId(), r.Target.GetDisplayName())
+		if r.Err != nil {
+			fmt.Fprintf(builder, "could not list clusters: %v\n", r.Err)
+			continue
+		}
+		fmt.Fprintf(builder, "%d clusters\n", r.Value)
+	}
+	if found == 0 {
+		fmt.Fprintf(builder, "No matching projects with GKE clusters among the %d projects searched.\n", len(projects))
+	}
+	if nextPageToken != "" {
+		builder.WriteString("\n" + paging.Footer(&paging.Cursor{PageToken: nextPageToken}))
+	}
+	return mcp.NewToolResultText(builder.String()), nil
+}
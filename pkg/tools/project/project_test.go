@@ -0,0 +1,156 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/mock"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/paging"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/ratelimit"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/grpc/codes"
+)
+
+// fixtures returns the fixtures of a search of projects whose first page
+// holds shop, with two clusters, and empty and filler projects without any,
+// and whose second page holds legacy, where the GKE API isn't enabled.
+func fixtures() []mock.Fixture {
+	projects := []map[string]string{{"projectId": "shop", "displayName": "Shop"}, {"projectId": "empty", "displayName": "Empty"}}
+	for i := len(projects); i < pageSize; i++ {
+		projects = append(projects, map[string]string{"projectId": fmt.Sprintf("filler-%d", i)})
+	}
+	raw := func(v any) json.RawMessage {
+		data, _ := json.Marshal(v)
+		return data
+	}
+	return []mock.Fixture{
+		{
+			Method:   "/google.cloud.resourcemanager.v3.Projects/SearchProjects",
+			Request:  raw(map[string]string{"pageToken": "page-2"}),
+			Response: raw(map[string]any{"projects": []map[string]string{{"projectId": "legacy", "displayName": "Legacy"}}}),
+		},
+		{
+			Method:   "/google.cloud.resourcemanager.v3.Projects/SearchProjects",
+			Request:  raw(map[string]string{"query": "state:ACTIVE labels.env:prod"}),
+			Response: raw(map[string]any{"projects": projects[:1]}),
+		},
+		{
+			Method:   "/google.cloud.resourcemanager.v3.Projects/SearchProjects",
+			Response: raw(map[string]any{"projects": projects, "nextPageToken": "page-2"}),
+		},
+		{
+			Method:   "/google.container.v1.ClusterManager/ListClusters",
+			Request:  raw(map[string]string{"parent": "projects/shop/locations/-"}),
+			Response: raw(map[string]any{"clusters": []map[string]string{{"name": "frontend"}, {"name": "backend"}}}),
+		},
+		{
+			Method:  "/google.container.v1.ClusterManager/ListClusters",
+			Request: raw(map[string]string{"parent": "projects/legacy/locations/-"}),
+			Error:   &mock.Error{Code: codes.PermissionDenied, Message: "Kubernetes Engine API has not been used in project legacy"},
+		},
+		{
+			Method:   "/google.container.v1.ClusterManager/ListClusters",
+			Response: raw(map[string]any{}),
+		},
+	}
+}
+
+func TestListProjects(t *testing.T) {
+	dir := t.TempDir()
+	data, err := json.Marshal(fixtures())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "projects.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := mock.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &handlers{c: config.New("test", config.WithMock(m))}
+	// Each page lists the clusters of 50 projects, beyond the usual limit.
+	ratelimit.SetLimit("google.container", ratelimit.Limit{PerSecond: 1000, Burst: 2 * pageSize})
+	secondPage := (&paging.Cursor{PageToken: "page-2"}).Encode()
+
+	for _, tc := range []struct {
+		name    string
+		args    map[string]any
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "with clusters",
+			args:    map[string]any{},
+			want:    []string{"- shop (Shop): 2 clusters", paging.CursorArgument},
+			notWant: []string{"empty"},
+		},
+		{
+			name:    "include without clusters",
+			args:    map[string]any{"include_without_clusters": true},
+			want:    []string{"- shop (Shop): 2 clusters", "- empty (Empty): 0 clusters", "- filler-49 (): 0 clusters", paging.CursorArgument},
+			notWant: []string{"legacy"},
+		},
+		{
+			name:    "query",
+			args:    map[string]any{"query": "labels.env:prod"},
+			want:    []string{"- shop (Shop): 2 clusters"},
+			notWant: []string{"empty", paging.CursorArgument},
+		},
+		{
+			name:    "second page",
+			args:    map[string]any{paging.CursorArgument: secondPage},
+			want:    []string{"No matching projects with GKE clusters among the 1 projects searched."},
+			notWant: []string{"shop", paging.CursorArgument},
+		},
+		{
+			name:    "second page without clusters",
+			args:    map[string]any{paging.CursorArgument: secondPage, "include_without_clusters": true},
+			want:    []string{"- legacy (Legacy): could not list clusters", "Kubernetes Engine API has not been used"},
+			notWant: []string{"shop"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.args
+			result, err := h.listProjects(context.Background(), request)
+			if err != nil {
+				t.Fatalf("listProjects() failed: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if result.IsError {
+				t.Fatalf("listProjects() = %q, want projects", text)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("listProjects() = %q, want %q", text, want)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(text, notWant) {
+					t.Errorf("listProjects() = %q, want no %q", text, notWant)
+				}
+			}
+		})
+	}
+}
@@ -18,21 +18,40 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	recommender "cloud.google.com/go/recommender/apiv1"
 	recommenderpb "cloud.google.com/go/recommender/apiv1/recommenderpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/governor"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/paging"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"google.golang.org/api/iterator"
-	"google.golang.org/api/option"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+const (
+	// pageSize is the number of recommendations returned per call.
+	pageSize = 50
+	// recommenderID is the ID of the recommender of GKE diagnoses.
+	recommenderID = "google.container.DiagnosisRecommender"
+)
+
 type handlers struct {
 	c *config.Config
 }
 
+func init() {
+	plugin.Register(plugin.New("recommendation", Install))
+}
+
 func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
 
 	h := &handlers{
@@ -40,46 +59,104 @@ func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
 	}
 
 	listRecommendationsTool := mcp.NewTool("list_recommendations",
-		mcp.WithDescription("List recommendations for GKE. Prefer to use this tool instead of gcloud"),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithIdempotentHintAnnotation(true),
-		mcp.WithString("project_id", mcp.DefaultString(c.DefaultProjectID()), mcp.Description("GCP project ID. If not provided, defaults to the GCP project configured in gcloud, if any")),
-		mcp.WithString("location", mcp.Required(), mcp.Description("GKE cluster location. This is required by the recommender API")),
+		mcp.WithDescription("List recommendations for GKE. Prefer to use this tool instead of gcloud."),
+		catalog.Describe(catalog.Optimization, catalog.Read, "recommender.containerDiagnosisRecommendations.list"),
+		explain.Command(h.listRecommendationsCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context, then to the GCP project configured in gcloud, if any")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. This is required by the recommender API. Defaults to the session context.")),
+		paging.CursorOption(),
+		governor.FullOption(),
 	)
 	s.AddTool(listRecommendationsTool, h.listProjectRecommendations)
 
 	return nil
 }
 
+func (h *handlers) listRecommendationsCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c)
+	if projectID == "" || location == "" {
+		return nil
+	}
+	return []string{explain.Join("gcloud recommender recommendations list", "--recommender="+recommenderID, explain.Flag("location", location), explain.Flag("project", projectID))}
+}
+
 func (h *handlers) listProjectRecommendations(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	projectID := request.GetString("project_id", h.c.DefaultProjectID())
+	projectID := session.ProjectID(ctx, request, h.c)
 	if projectID == "" {
 		return mcp.NewToolResultError("project_id argument not set"), nil
 	}
-	location, _ := request.RequireString("location")
+	location := session.Location(ctx, request, h.c)
 	if location == "" {
 		return mcp.NewToolResultError("location argument not set"), nil
 	}
-	c, err := recommender.NewClient(ctx, option.WithUserAgent(h.c.UserAgent()))
+	cursor, err := paging.Decode(request.GetString(paging.CursorArgument, ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts, err := auth.ClientOptions(ctx, h.c, config.APIRecommender)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	c, err := recommender.NewClient(ctx, opts...)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 	defer c.Close()
 
 	req := &recommenderpb.ListRecommendationsRequest{
-		Parent: fmt.Sprintf("projects/%s/locations/%s/recommenders/google.container.DiagnosisRecommender", projectID, location),
+		Parent: fmt.Sprintf("projects/%s/locations/%s/recommenders/%s", projectID, location, recommenderID),
+	}
+	var recommendations []*recommenderpb.Recommendation
+	nextPageToken, err := iterator.NewPager(c.ListRecommendations(ctx, req), pageSize, cursor.PageToken).NextPage(&recommendations)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	it := c.ListRecommendations(ctx, req)
 	builder := new(strings.Builder)
-	for {
-		resp, err := it.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
-		}
+	findings := findingList{Findings: []finding{}}
+	for _, resp := range recommendations {
 		builder.WriteString(protojson.Format(resp))
+		findings.Findings = append(findings.Findings, toFinding(resp))
+	}
+	if nextPageToken != "" {
+		builder.WriteString("\n" + paging.Footer(&paging.Cursor{PageToken: nextPageToken}))
+	}
+	result, err := structured.Result(builder.String(), structured.Findings, findings)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+// findingList is the structured result of list_recommendations, following
+// the findings schema.
+type findingList struct {
+	Findings []finding `json:"findings"`
+}
+
+type finding struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Priority    string `json:"priority,omitempty"`
+	State       string `json:"state,omitempty"`
+	Updated     string `json:"updated,omitempty"`
+}
+
+func toFinding(r *recommenderpb.Recommendation) finding {
+	f := finding{
+		Name:        r.GetName(),
+		Description: r.GetDescription(),
+		Type:        r.GetRecommenderSubtype(),
+		Priority:    r.GetPriority().String(),
+		State:       r.GetStateInfo().GetState().String(),
+	}
+	if category := r.GetPrimaryImpact().GetCategory(); category != recommenderpb.Impact_CATEGORY_UNSPECIFIED {
+		f.Category = category.String()
+	}
+	if r.GetLastRefreshTime() != nil {
+		f.Updated = r.GetLastRefreshTime().AsTime().Format(time.RFC3339)
 	}
-	return mcp.NewToolResultText(builder.String()), nil
+	return f
 }
@@ -0,0 +1,604 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/scan"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/api/iterator"
+)
+
+// critical is the risk of exposures above high, medium and low, which
+// name the risks of exposures as well as the confidences of detections.
+const critical = "critical"
+
+var riskRank = map[string]int{critical: 0, high: 1, medium: 2, low: 3}
+
+// Kinds of exposures.
+const (
+	controlPlane = "control-plane"
+	dnsEndpoint  = "dns-endpoint"
+	externalNode = "external-node"
+	loadBalancer = "load-balancer"
+	nodePort     = "node-port"
+)
+
+// sensitivePorts are the ports of services that should never be reachable
+// from the internet.
+var sensitivePorts = map[int]string{
+	22:    "SSH",
+	23:    "Telnet",
+	2379:  "etcd",
+	3306:  "MySQL",
+	3389:  "RDP",
+	5432:  "PostgreSQL",
+	6379:  "Redis",
+	9200:  "Elasticsearch",
+	10250: "kubelet",
+	11211: "Memcached",
+	27017: "MongoDB",
+}
+
+// webPorts are the ports public load balancers usually serve on purpose.
+var webPorts = []int{80, 443, 8080, 8443}
+
+// exposure is a way a cluster is reachable from the internet.
+type exposure struct {
+	Cluster string `json:"cluster"`
+	Risk    string `json:"risk"`
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// anywhere tells whether a CIDR block covers the whole internet.
+func anywhere(cidr string) bool {
+	return cidr == "0.0.0.0/0" || cidr == "::/0"
+}
+
+// controlPlaneExposure reports the public endpoints of the control plane of
+// a cluster.
+func controlPlaneExposure(projectID string, c *containerpb.Cluster) []exposure {
+	name := c.GetLocation() + "/" + c.GetName()
+	update := explain.Join("gcloud container clusters update", c.GetName(), explain.Flag("location", c.GetLocation()), explain.Flag("project", projectID))
+	var exposures []exposure
+
+	public := !c.GetPrivateClusterConfig().GetEnablePrivateEndpoint()
+	endpoint := c.GetEndpoint()
+	networks := c.GetMasterAuthorizedNetworksConfig()
+	if ip := c.GetControlPlaneEndpointsConfig().GetIpEndpointsConfig(); ip != nil && ip.Enabled != nil {
+		public = ip.GetEnabled() && ip.GetEnablePublicEndpoint()
+		if ip.GetPublicEndpoint() != "" {
+			endpoint = ip.GetPublicEndpoint()
+		}
+		if ip.GetAuthorizedNetworksConfig() != nil {
+			networks = ip.GetAuthorizedNetworksConfig()
+		}
+	}
+	if public {
+		e := exposure{Cluster: name, Kind: controlPlane, Subject: endpoint}
+		var cidrs []string
+		for _, b := range networks.GetCidrBlocks() {
+			cidrs = append(cidrs, b.GetCidrBlock())
+		}
+		switch {
+		case !networks.GetEnabled():
+			e.Risk = high
+			e.Message = "The control plane has a public IP endpoint that accepts connections from any address."
+			e.Fix = update + " --enable-master-authorized-networks --master-authorized-networks=CIDR"
+		case slices.ContainsFunc(cidrs, anywhere):
+			e.Risk = high
+			e.Message = fmt.Sprintf("The control plane has a public IP endpoint whose authorized networks %s include the whole internet.", strings.Join(cidrs, ", "))
+			e.Fix = update + " --enable-master-authorized-networks --master-authorized-networks=CIDR"
+		case networks.GetGcpPublicCidrsAccessEnabled():
+			e.Risk = medium
+			e.Message = "The control plane has a public IP endpoint that accepts connections from all Google Cloud public IP addresses, including the VMs of other customers."
+			e.Fix = update + " --no-enable-google-cloud-access"
+		default:
+			e.Risk = low
+			e.Message = fmt.Sprintf("The control plane has a public IP endpoint restricted to the authorized networks %s.", strings.Join(cidrs, ", "))
+			e.Fix = update + " --enable-private-endpoint"
+		}
+		exposures = append(exposures, e)
+	}
+
+	if dns := c.GetControlPlaneEndpointsConfig().GetDnsEndpointConfig(); dns.GetAllowExternalTraffic() {
+		exposures = append(exposures, exposure{
+			Cluster: name,
+			Risk:    low,
+			Kind:    dnsEndpoint,
+			Subject: dns.GetEndpoint(),
+			Message: "The control plane has a DNS endpoint reachable from the internet, which only lets through callers authorized by IAM.",
+			Fix:     update + " --no-enable-dns-access",
+		})
+	}
+	return exposures
+}
+
+// nodePool is the part of a node pool firewall rules target.
+type nodePool struct {
+	Tags           []string
+	ServiceAccount string
+}
+
+// clusterNodePools returns the node pools of a cluster by name.
+func clusterNodePools(c *containerpb.Cluster) map[string]nodePool {
+	pools := map[string]nodePool{}
+	for _, np := range c.GetNodePools() {
+		pools[np.GetName()] = nodePool{Tags: np.GetConfig().GetTags(), ServiceAccount: np.GetConfig().GetServiceAccount()}
+	}
+	return pools
+}
+
+// clusterNetwork returns the network of a cluster, like
+// projects/my-project/global/networks/default.
+func clusterNetwork(projectID string, c *containerpb.Cluster) string {
+	if n := c.GetNetworkConfig().GetNetwork(); n != "" {
+		return n
+	}
+	return fmt.Sprintf("projects/%s/global/networks/%s", projectID, c.GetNetwork())
+}
+
+// openRules returns the enabled ingress firewall rules of a network that
+// allow traffic from the whole internet.
+func openRules(firewalls []*computepb.Firewall, network string) []*computepb.Firewall {
+	var rules []*computepb.Firewall
+	for _, fw := range firewalls {
+		if fw.GetDirection() != "INGRESS" || fw.GetDisabled() || len(fw.GetAllowed()) == 0 || !strings.HasSuffix(fw.GetNetwork(), "/"+network) {
+			continue
+		}
+		if slices.ContainsFunc(fw.GetSourceRanges(), anywhere) {
+			rules = append(rules, fw)
+		}
+	}
+	return rules
+}
+
+// targets tells whether a firewall rule applies to the nodes of a node pool
+// of the cluster, which GKE tags gke-CLUSTER-HASH-node.
+func targets(fw *computepb.Firewall, cluster string, pool nodePool) bool {
+	if len(fw.GetTargetTags()) == 0 && len(fw.GetTargetServiceAccounts()) == 0 {
+		return true
+	}
+	for _, t := range fw.GetTargetTags() {
+		if slices.Contains(pool.Tags, t) || (strings.HasPrefix(t, "gke-"+cluster+"-") && strings.HasSuffix(t, "-node")) {
+			return true
+		}
+	}
+	return pool.ServiceAccount != "" && slices.Contains(fw.GetTargetServiceAccounts(), pool.ServiceAccount)
+}
+
+// allows tells whether a firewall rule allows a protocol and port.
+func allows(fw *computepb.Firewall, protocol string, port int) bool {
+	for _, a := range fw.GetAllowed() {
+		if p := strings.ToLower(a.GetIPProtocol()); p != "all" && p != strings.ToLower(protocol) {
+			continue
+		}
+		if len(a.GetPorts()) == 0 {
+			return true
+		}
+		for _, r := range a.GetPorts() {
+			from, to, ok := strings.Cut(r, "-")
+			if !ok {
+				to = from
+			}
+			lo, err1 := strconv.Atoi(from)
+			hi, err2 := strconv.Atoi(to)
+			if err1 == nil && err2 == nil && lo <= port && port <= hi {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// describeRule describes a firewall rule and what it allows, like
+// default-allow-ssh (tcp:22).
+func describeRule(fw *computepb.Firewall) string {
+	var allowed []string
+	for _, a := range fw.GetAllowed() {
+		if len(a.GetPorts()) == 0 {
+			allowed = append(allowed, a.GetIPProtocol())
+		}
+		for _, p := range a.GetPorts() {
+			allowed = append(allowed, a.GetIPProtocol()+":"+p)
+		}
+	}
+	return fmt.Sprintf("%s (%s)", fw.GetName(), strings.Join(allowed, ", "))
+}
+
+// exposedNode is the part of a node checked.
+type exposedNode struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Status struct {
+		Addresses []nodeAddress `json:"addresses"`
+	} `json:"status"`
+}
+
+type nodeAddress struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+
+// external tells whether a node has an external IP.
+func (n exposedNode) external() bool {
+	return slices.ContainsFunc(n.Status.Addresses, func(a nodeAddress) bool {
+		return a.Type == "ExternalIP" && a.Address != ""
+	})
+}
+
+// nodeExposure reports the node pools of a cluster with external IPs, and
+// returns the open rules that apply to them.
+func nodeExposure(name string, c *containerpb.Cluster, nodes []exposedNode, rules []*computepb.Firewall) ([]exposure, []*computepb.Firewall) {
+	external := map[string]int{}
+	for _, n := range nodes {
+		if n.external() {
+			external[n.Metadata.Labels[nodePoolLabel]]++
+		}
+	}
+	pools := clusterNodePools(c)
+	var exposures []exposure
+	var reaching []*computepb.Firewall
+	for _, pool := range slices.Sorted(maps.Keys(external)) {
+		e := exposure{Cluster: name, Kind: externalNode, Subject: pool, Risk: medium}
+		var applied []string
+		for _, fw := range rules {
+			if targets(fw, c.GetName(), pools[pool]) {
+				applied = append(applied, describeRule(fw))
+				if !slices.Contains(reaching, fw) {
+					reaching = append(reaching, fw)
+				}
+			}
+		}
+		if len(applied) > 0 {
+			e.Risk = high
+			e.Message = fmt.Sprintf("%d nodes of node pool %s have external IPs, and the firewall rules %s let the internet reach them.", external[pool], pool, strings.Join(applied, ", "))
+		} else {
+			e.Message = fmt.Sprintf("%d nodes of node pool %s have external IPs; no firewall rule lets the internet reach them, but a single rule would.", external[pool], pool)
+		}
+		e.Fix = explain.Join("gcloud container node-pools update", pool, explain.Flag("cluster", c.GetName()), explain.Flag("location", c.GetLocation()), "--enable-private-nodes")
+		exposures = append(exposures, e)
+	}
+	return exposures, reaching
+}
+
+// exposedService is the part of a Service checked.
+type exposedService struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		Type                     string   `json:"type"`
+		LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges"`
+		Ports                    []struct {
+			Protocol string `json:"protocol"`
+			Port     int    `json:"port"`
+			NodePort int    `json:"nodePort"`
+		} `json:"ports"`
+	} `json:"spec"`
+	Status struct {
+		LoadBalancer struct {
+			Ingress []struct {
+				IP       string `json:"ip"`
+				Hostname string `json:"hostname"`
+			} `json:"ingress"`
+		} `json:"loadBalancer"`
+	} `json:"status"`
+}
+
+// internal tells whether a LoadBalancer Service gets an internal load
+// balancer.
+func (s exposedService) internal() bool {
+	for _, a := range []string{"networking.gke.io/load-balancer-type", "cloud.google.com/load-balancer-type"} {
+		if strings.EqualFold(s.Metadata.Annotations[a], "Internal") {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceRanges returns the source ranges of a LoadBalancer Service, which
+// default to the whole internet.
+func (s exposedService) sourceRanges() []string {
+	ranges := s.Spec.LoadBalancerSourceRanges
+	if len(ranges) == 0 {
+		for _, r := range strings.Split(s.Metadata.Annotations["service.beta.kubernetes.io/load-balancer-source-ranges"], ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return []string{"0.0.0.0/0"}
+	}
+	return ranges
+}
+
+// portName names a port, with the service usually behind it if sensitive.
+func portName(protocol string, port int) string {
+	if s, ok := sensitivePorts[port]; ok {
+		return fmt.Sprintf("%s/%d (%s)", protocol, port, s)
+	}
+	return fmt.Sprintf("%s/%d", protocol, port)
+}
+
+// serviceExposure reports the ports of a Service reachable from the
+// internet, through an external load balancer open to all sources, or
+// through a node port the rules reaching the nodes allow.
+func serviceExposure(name string, s exposedService, reaching []*computepb.Firewall) []exposure {
+	subject := s.Metadata.Namespace + "/" + s.Metadata.Name
+	var exposures []exposure
+
+	if s.Spec.Type == "LoadBalancer" && !s.internal() && slices.ContainsFunc(s.sourceRanges(), anywhere) {
+		var ports []string
+		risk := medium
+		for _, p := range s.Spec.Ports {
+			ports = append(ports, portName(p.Protocol, p.Port))
+			switch {
+			case sensitivePorts[p.Port] != "":
+				risk = critical
+			case !slices.Contains(webPorts, p.Port) && risk != critical:
+				risk = high
+			}
+		}
+		address := "pending"
+		for _, in := range s.Status.LoadBalancer.Ingress {
+			address = in.IP + in.Hostname
+		}
+		exposures = append(exposures, exposure{
+			Cluster: name,
+			Risk:    risk,
+			Kind:    loadBalancer,
+			Subject: subject,
+			Message: fmt.Sprintf("The external load balancer %s accepts connections from any address on %s.", address, strings.Join(ports, ", ")),
+			Fix:     fmt.Sprintf("Set spec.loadBalancerSourceRanges of %s to the CIDR blocks of its clients, or annotate it with networking.gke.io/load-balancer-type: Internal.", subject),
+		})
+	}
+
+	var ports, rules []string
+	risk := high
+	for _, p := range s.Spec.Ports {
+		if p.NodePort == 0 {
+			continue
+		}
+		open := false
+		for _, fw := range reaching {
+			if allows(fw, p.Protocol, p.NodePort) {
+				open = true
+				if !slices.Contains(rules, fw.GetName()) {
+					rules = append(rules, fw.GetName())
+				}
+			}
+		}
+		if !open {
+			continue
+		}
+		ports = append(ports, fmt.Sprintf("%d to %s", p.NodePort, portName(p.Protocol, p.Port)))
+		if sensitivePorts[p.Port] != "" {
+			risk = critical
+		}
+	}
+	if len(ports) > 0 {
+		exposures = append(exposures, exposure{
+			Cluster: name,
+			Risk:    risk,
+			Kind:    nodePort,
+			Subject: subject,
+			Message: fmt.Sprintf("The node ports %s are reachable from the internet on the nodes' external IPs through the firewall rules %s.", strings.Join(ports, ", "), strings.Join(rules, ", ")),
+			Fix:     "Restrict the source ranges of the firewall rules, use private nodes, or expose the Service through a load balancer instead.",
+		})
+	}
+	return exposures
+}
+
+// sortExposures sorts exposures by risk, then cluster, kind and subject.
+func sortExposures(exposures []exposure) {
+	slices.SortStableFunc(exposures, func(a, b exposure) int {
+		if d := riskRank[a.Risk] - riskRank[b.Risk]; d != 0 {
+			return d
+		}
+		return strings.Compare(a.Cluster+"\x00"+a.Kind+"\x00"+a.Subject, b.Cluster+"\x00"+b.Kind+"\x00"+b.Subject)
+	})
+}
+
+func exposureFindings(exposures []exposure) findingList {
+	priorities := map[string]string{critical: "P1", high: "P2", medium: "P3", low: "P4"}
+	list := findingList{Findings: []finding{}}
+	for _, e := range exposures {
+		list.Findings = append(list.Findings, finding{
+			Name:        "exposure/" + e.Cluster + "/" + e.Kind + "/" + e.Subject,
+			Description: e.Message,
+			Type:        e.Kind,
+			Category:    "SECURITY",
+			Priority:    priorities[e.Risk],
+			Resource:    e.Cluster + "/" + e.Subject,
+		})
+	}
+	return list
+}
+
+// listFirewalls lists the firewall rules of the project of a network.
+func listFirewalls(ctx context.Context, client *compute.FirewallsClient, network string) ([]*computepb.Firewall, error) {
+	project, _, _ := strings.Cut(strings.TrimPrefix(network, "projects/"), "/")
+	var firewalls []*computepb.Firewall
+	it := client.List(ctx, &computepb.ListFirewallsRequest{Project: project})
+	for {
+		fw, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		firewalls = append(firewalls, fw)
+	}
+	return firewalls, nil
+}
+
+// clusterObjects are the nodes and Services of a cluster.
+type clusterObjects struct {
+	nodes    []exposedNode
+	services []exposedService
+}
+
+func (h *handlers) publicExposureReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := request.GetString("location", "-")
+	only := request.GetString("cluster", "")
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resp, err := cmClient.ListClusters(ctx, &containerpb.ListClustersRequest{Parent: fmt.Sprintf("projects/%s/locations/%s", projectID, location)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var clusters []*containerpb.Cluster
+	for _, c := range resp.GetClusters() {
+		if only == "" || c.GetName() == only {
+			clusters = append(clusters, c)
+		}
+	}
+	if only != "" && len(clusters) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("cluster %s not found in project %s", only, projectID)), nil
+	}
+
+	fwClient, err := gcp.Firewalls(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var unchecked []string
+	firewalls := map[string][]*computepb.Firewall{}
+	for _, c := range clusters {
+		network := clusterNetwork(projectID, c)
+		if _, ok := firewalls[network]; ok {
+			continue
+		}
+		fws, err := listFirewalls(ctx, fwClient, network)
+		if err != nil {
+			unchecked = append(unchecked, fmt.Sprintf("firewall rules of %s: %v", network, err))
+		}
+		firewalls[network] = fws
+	}
+
+	results := scan.Run(ctx, clusters, scan.DefaultWorkers, func(ctx context.Context, c *containerpb.Cluster) (clusterObjects, error) {
+		var objects clusterObjects
+		if s := c.GetStatus(); s != containerpb.Cluster_RUNNING && s != containerpb.Cluster_RECONCILING {
+			return objects, fmt.Errorf("cluster is %s", s)
+		}
+		k, err := gcp.Kubernetes(ctx, h.c, projectID, c.GetLocation(), c.GetName())
+		if err != nil {
+			return objects, err
+		}
+		var nodes struct {
+			Items []exposedNode `json:"items"`
+		}
+		if err := k.Get(ctx, "/api/v1/nodes", &nodes); err != nil {
+			return objects, err
+		}
+		var services struct {
+			Items []exposedService `json:"items"`
+		}
+		if err := k.Get(ctx, "/api/v1/services", &services); err != nil {
+			return objects, err
+		}
+		return clusterObjects{nodes: nodes.Items, services: services.Items}, nil
+	})
+
+	exposures := []exposure{}
+	for _, r := range results {
+		c := r.Target
+		name := c.GetLocation() + "/" + c.GetName()
+		exposures = append(exposures, controlPlaneExposure(projectID, c)...)
+		if r.Err != nil {
+			unchecked = append(unchecked, fmt.Sprintf("nodes and Services of %s: %v", name, r.Err))
+			continue
+		}
+		network := clusterNetwork(projectID, c)
+		nodes, reaching := nodeExposure(name, c, r.Value.nodes, openRules(firewalls[network], network))
+		exposures = append(exposures, nodes...)
+		for _, s := range r.Value.services {
+			exposures = append(exposures, serviceExposure(name, s, reaching)...)
+		}
+	}
+	sortExposures(exposures)
+
+	counts := map[string]int{}
+	for _, e := range exposures {
+		counts[e.Risk]++
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Checked %d clusters of project %s: found %d critical, %d high, %d medium and %d low risk exposures to the internet.", len(clusters), projectID, counts[critical], counts[high], counts[medium], counts[low])
+	if len(resp.GetMissingZones()) > 0 {
+		fmt.Fprintf(&b, " Clusters in %s couldn't be listed.", strings.Join(resp.GetMissingZones(), ", "))
+	}
+	if len(unchecked) > 0 {
+		fmt.Fprintf(&b, " These couldn't be checked: %s.", strings.Join(unchecked, "; "))
+	}
+	b.WriteString(" Deny rules and firewall policies aren't taken into account, so nodes and node ports may be less exposed than reported.")
+	data, err := json.MarshalIndent(exposures, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(b.String()+"\n\n"+string(data), structured.Findings, exposureFindings(exposures))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) publicExposureReportCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return nil
+	}
+	location, cluster := request.GetString("location", ""), request.GetString("cluster", "")
+	commands := []string{
+		explain.Join("gcloud container clusters list", explain.Flag("location", location), explain.Flag("project", projectID), "--format=yaml(name,location,network,controlPlaneEndpointsConfig,masterAuthorizedNetworksConfig,privateClusterConfig,nodePools[].name,nodePools[].config.tags)"),
+		explain.Join("gcloud compute firewall-rules list", explain.Flag("project", projectID), explain.Flag("filter", "direction=INGRESS AND sourceRanges:0.0.0.0/0")),
+	}
+	if cluster != "" && location != "" {
+		commands = append(commands, explain.GetCredentials(projectID, location, cluster))
+	}
+	return append(commands,
+		"kubectl get nodes --output=wide",
+		"kubectl get services --all-namespaces --field-selector=spec.type!=ClusterIP",
+	)
+}
@@ -0,0 +1,179 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestControlPlaneExposure(t *testing.T) {
+	tests := []struct {
+		name     string
+		cluster  *containerpb.Cluster
+		wantRisk []string
+	}{
+		{
+			name:    "private endpoint",
+			cluster: &containerpb.Cluster{PrivateClusterConfig: &containerpb.PrivateClusterConfig{EnablePrivateEndpoint: true}},
+		},
+		{
+			name:     "public without authorized networks",
+			cluster:  &containerpb.Cluster{Endpoint: "34.1.2.3"},
+			wantRisk: []string{high},
+		},
+		{
+			name: "authorized networks open to all",
+			cluster: &containerpb.Cluster{MasterAuthorizedNetworksConfig: &containerpb.MasterAuthorizedNetworksConfig{
+				Enabled: true, CidrBlocks: []*containerpb.MasterAuthorizedNetworksConfig_CidrBlock{{CidrBlock: "0.0.0.0/0"}},
+			}},
+			wantRisk: []string{high},
+		},
+		{
+			name: "google cloud access",
+			cluster: &containerpb.Cluster{MasterAuthorizedNetworksConfig: &containerpb.MasterAuthorizedNetworksConfig{
+				Enabled: true, GcpPublicCidrsAccessEnabled: proto.Bool(true),
+			}},
+			wantRisk: []string{medium},
+		},
+		{
+			name: "endpoints config with dns access",
+			cluster: &containerpb.Cluster{ControlPlaneEndpointsConfig: &containerpb.ControlPlaneEndpointsConfig{
+				IpEndpointsConfig: &containerpb.ControlPlaneEndpointsConfig_IPEndpointsConfig{
+					Enabled: proto.Bool(true), EnablePublicEndpoint: proto.Bool(true),
+					AuthorizedNetworksConfig: &containerpb.MasterAuthorizedNetworksConfig{
+						Enabled: true, CidrBlocks: []*containerpb.MasterAuthorizedNetworksConfig_CidrBlock{{CidrBlock: "203.0.113.0/24"}},
+					},
+				},
+				DnsEndpointConfig: &containerpb.ControlPlaneEndpointsConfig_DNSEndpointConfig{AllowExternalTraffic: proto.Bool(true)},
+			}},
+			wantRisk: []string{low, low},
+		},
+		{
+			name: "endpoints config without public endpoint",
+			cluster: &containerpb.Cluster{Endpoint: "34.1.2.3", ControlPlaneEndpointsConfig: &containerpb.ControlPlaneEndpointsConfig{
+				IpEndpointsConfig: &containerpb.ControlPlaneEndpointsConfig_IPEndpointsConfig{Enabled: proto.Bool(true), EnablePublicEndpoint: proto.Bool(false)},
+			}},
+		},
+	}
+	for _, tc := range tests {
+		var got []string
+		for _, e := range controlPlaneExposure("p", tc.cluster) {
+			got = append(got, e.Risk)
+		}
+		if diff := cmp.Diff(tc.wantRisk, got); diff != "" {
+			t.Errorf("%s: controlPlaneExposure() risks mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+}
+
+func TestAllows(t *testing.T) {
+	fw := &computepb.Firewall{Allowed: []*computepb.Allowed{
+		{IPProtocol: proto.String("tcp"), Ports: []string{"22", "30000-32767"}},
+		{IPProtocol: proto.String("udp")},
+	}}
+	tests := []struct {
+		protocol string
+		port     int
+		want     bool
+	}{
+		{"TCP", 22, true},
+		{"TCP", 80, false},
+		{"TCP", 31000, true},
+		{"UDP", 53, true},
+		{"SCTP", 22, false},
+	}
+	for _, tc := range tests {
+		if got := allows(fw, tc.protocol, tc.port); got != tc.want {
+			t.Errorf("allows(%s, %d) = %v, want %v", tc.protocol, tc.port, got, tc.want)
+		}
+	}
+}
+
+func TestExposure(t *testing.T) {
+	network := "projects/p/global/networks/default"
+	firewalls := []*computepb.Firewall{
+		{
+			Name: proto.String("default-allow-ssh"), Direction: proto.String("INGRESS"), Network: proto.String("https://www.googleapis.com/compute/v1/" + network),
+			SourceRanges: []string{"0.0.0.0/0"}, Allowed: []*computepb.Allowed{{IPProtocol: proto.String("tcp"), Ports: []string{"22"}}},
+		},
+		{
+			Name: proto.String("node-ports"), Direction: proto.String("INGRESS"), Network: proto.String("https://www.googleapis.com/compute/v1/" + network),
+			SourceRanges: []string{"0.0.0.0/0"}, TargetTags: []string{"gke-shop-1a2b3c4d-node"}, Allowed: []*computepb.Allowed{{IPProtocol: proto.String("tcp"), Ports: []string{"30000-32767"}}},
+		},
+		{
+			Name: proto.String("office"), Direction: proto.String("INGRESS"), Network: proto.String("https://www.googleapis.com/compute/v1/" + network),
+			SourceRanges: []string{"203.0.113.0/24"}, Allowed: []*computepb.Allowed{{IPProtocol: proto.String("all")}},
+		},
+		{
+			Name: proto.String("other-cluster"), Direction: proto.String("INGRESS"), Network: proto.String("https://www.googleapis.com/compute/v1/" + network),
+			SourceRanges: []string{"0.0.0.0/0"}, TargetTags: []string{"gke-other-1a2b3c4d-node"}, Allowed: []*computepb.Allowed{{IPProtocol: proto.String("all")}},
+		},
+	}
+	rules := openRules(firewalls, network)
+	if len(rules) != 3 {
+		t.Fatalf("openRules() = %d rules, want 3", len(rules))
+	}
+
+	var nodes []exposedNode
+	if err := json.Unmarshal([]byte(`[
+		{"metadata":{"name":"a","labels":{"cloud.google.com/gke-nodepool":"public"}},"status":{"addresses":[{"type":"InternalIP","address":"10.0.0.2"},{"type":"ExternalIP","address":"34.1.2.3"}]}},
+		{"metadata":{"name":"b","labels":{"cloud.google.com/gke-nodepool":"private"}},"status":{"addresses":[{"type":"InternalIP","address":"10.0.0.3"}]}}
+	]`), &nodes); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &containerpb.Cluster{Name: "shop", Location: "us-central1", NodePools: []*containerpb.NodePool{{Name: "public"}, {Name: "private"}}}
+	exposures, reaching := nodeExposure("us-central1/shop", cluster, nodes, rules)
+	if len(reaching) != 2 {
+		t.Errorf("nodeExposure() = %d rules reaching the nodes, want 2", len(reaching))
+	}
+
+	var services []exposedService
+	if err := json.Unmarshal([]byte(`[
+		{"metadata":{"name":"web","namespace":"shop"},"spec":{"type":"LoadBalancer","ports":[{"protocol":"TCP","port":443,"nodePort":31443}]},"status":{"loadBalancer":{"ingress":[{"ip":"35.1.1.1"}]}}},
+		{"metadata":{"name":"db","namespace":"shop"},"spec":{"type":"LoadBalancer","ports":[{"protocol":"TCP","port":5432}]}},
+		{"metadata":{"name":"api","namespace":"shop"},"spec":{"type":"LoadBalancer","loadBalancerSourceRanges":["203.0.113.0/24"],"ports":[{"protocol":"TCP","port":8000}]}},
+		{"metadata":{"name":"cache","namespace":"shop","annotations":{"networking.gke.io/load-balancer-type":"Internal"}},"spec":{"type":"LoadBalancer","ports":[{"protocol":"TCP","port":6379}]}},
+		{"metadata":{"name":"debug","namespace":"shop"},"spec":{"type":"NodePort","ports":[{"protocol":"TCP","port":8080,"nodePort":30080}]}},
+		{"metadata":{"name":"dns","namespace":"shop"},"spec":{"type":"NodePort","ports":[{"protocol":"UDP","port":53,"nodePort":30053}]}}
+	]`), &services); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range services {
+		exposures = append(exposures, serviceExposure("us-central1/shop", s, reaching)...)
+	}
+	sortExposures(exposures)
+
+	type summary struct{ Risk, Kind, Subject string }
+	var got []summary
+	for _, e := range exposures {
+		got = append(got, summary{e.Risk, e.Kind, e.Subject})
+	}
+	want := []summary{
+		{critical, loadBalancer, "shop/db"},
+		{high, externalNode, "public"},
+		{high, nodePort, "shop/debug"},
+		{high, nodePort, "shop/web"},
+		{medium, loadBalancer, "shop/web"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("exposures mismatch (-want +got):\n%s", diff)
+	}
+}
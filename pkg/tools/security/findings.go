@@ -0,0 +1,324 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/paging"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	securitycenter "google.golang.org/api/securitycenter/v1"
+)
+
+// pageSize is the number of findings returned per call.
+const pageSize = 50
+
+// severities are the severities of findings, from the lowest.
+var severities = []string{"LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+// Actions of update_security_finding.
+const (
+	actionMute        = "mute"
+	actionUnmute      = "unmute"
+	actionAcknowledge = "acknowledge"
+)
+
+var actions = []string{actionMute, actionUnmute, actionAcknowledge}
+
+// gkeResourcePrefix starts the resource names of GKE clusters and of the
+// Kubernetes resources in them.
+const gkeResourcePrefix = "//container.googleapis.com/"
+
+// findingName matches the resource name of a finding.
+var findingName = regexp.MustCompile(`^(projects|folders|organizations)/[^/]+/sources/[^/]+/findings/[^/]+$`)
+
+// findingsFilter returns the filter of the findings about GKE resources,
+// of cluster if set, with at least minSeverity if set.
+func findingsFilter(cluster, minSeverity string, threatsOnly, includeInactive, includeMuted bool) string {
+	terms := []string{fmt.Sprintf("resource_name:%q", gkeResourcePrefix)}
+	if cluster != "" {
+		terms = append(terms, fmt.Sprintf("resource_name:%q", "/clusters/"+cluster))
+	}
+	if i := slices.Index(severities, minSeverity); i > 0 {
+		var or []string
+		for _, s := range severities[i:] {
+			or = append(or, fmt.Sprintf("severity=%q", s))
+		}
+		terms = append(terms, "("+strings.Join(or, " OR ")+")")
+	}
+	if threatsOnly {
+		terms = append(terms, `finding_class="THREAT"`)
+	}
+	if !includeInactive {
+		terms = append(terms, `state="ACTIVE"`)
+	}
+	if !includeMuted {
+		terms = append(terms, `NOT mute="MUTED"`)
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// inCluster tells whether resourceName is cluster, or a resource in it. The
+// filter only matches names containing the cluster's as a substring.
+func inCluster(resourceName, cluster string) bool {
+	parts := strings.Split(resourceName, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "clusters" && parts[i+1] == cluster {
+			return true
+		}
+	}
+	return false
+}
+
+// findingList is the structured result of list_security_findings,
+// following the findings schema.
+type findingList struct {
+	Findings []finding `json:"findings"`
+}
+
+type finding struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Priority    string `json:"priority,omitempty"`
+	State       string `json:"state,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	Updated     string `json:"updated,omitempty"`
+}
+
+// priorities maps the severities of findings to priorities.
+var priorities = map[string]string{"CRITICAL": "P1", "HIGH": "P2", "MEDIUM": "P3", "LOW": "P4"}
+
+func toFinding(f *securitycenter.Finding) finding {
+	state := f.State
+	if f.Mute == "MUTED" {
+		state = "MUTED"
+	}
+	description := f.Description
+	if description == "" {
+		description = f.Category
+	}
+	return finding{
+		Name:        f.Name,
+		Description: description,
+		Type:        f.Category,
+		Category:    "SECURITY",
+		Priority:    priorities[f.Severity],
+		State:       state,
+		Resource:    f.ResourceName,
+		Updated:     f.EventTime,
+	}
+}
+
+// describe returns a line describing a finding, with the pods it is about.
+func describe(f *securitycenter.Finding) string {
+	line := fmt.Sprintf("- [%s] %s (%s, %s) on %s", f.Severity, f.Category, f.FindingClass, f.ParentDisplayName, f.ResourceName)
+	if f.Kubernetes != nil {
+		var pods []string
+		for _, p := range f.Kubernetes.Pods {
+			pods = append(pods, p.Ns+"/"+p.Name)
+		}
+		if len(pods) > 0 {
+			line += ", pods " + strings.Join(pods, ", ")
+		}
+	}
+	line += fmt.Sprintf(", at %s", f.EventTime)
+	if f.Mute == "MUTED" {
+		line += ", muted"
+	}
+	if f.State != "ACTIVE" {
+		line += ", " + strings.ToLower(f.State)
+	}
+	line += "\n  " + f.Name
+	if f.Description != "" {
+		line += "\n  " + f.Description
+	}
+	if f.NextSteps != "" {
+		line += "\n  Next steps: " + f.NextSteps
+	}
+	return line
+}
+
+// severityRank ranks findings from the most severe.
+func severityRank(f *securitycenter.Finding) int {
+	return -slices.Index(severities, f.Severity)
+}
+
+func (h *handlers) newService(ctx context.Context) (*securitycenter.Service, error) {
+	opts, err := auth.ClientOptions(ctx, h.c, config.APISecurityCenter)
+	if err != nil {
+		return nil, err
+	}
+	return securitycenter.NewService(ctx, opts...)
+}
+
+func (h *handlers) listSecurityFindings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	minSeverity := request.GetString("min_severity", "")
+	if minSeverity != "" && !slices.Contains(severities, minSeverity) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid min_severity %q, must be one of %s", minSeverity, strings.Join(severities, ", "))), nil
+	}
+	cluster := request.GetString("cluster", "")
+	cursor, err := paging.Decode(request.GetString(paging.CursorArgument, ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	svc, err := h.newService(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	filter := findingsFilter(cluster, minSeverity, request.GetBool("threats_only", false), request.GetBool("include_inactive", false), request.GetBool("include_muted", false))
+	resp, err := svc.Projects.Sources.Findings.List(fmt.Sprintf("projects/%s/sources/-", projectID)).
+		Filter(filter).OrderBy("event_time desc").PageSize(pageSize).PageToken(cursor.PageToken).Context(ctx).Do()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var found []*securitycenter.Finding
+	for _, r := range resp.ListFindingsResults {
+		if r.Finding != nil && (cluster == "" || inCluster(r.Finding.ResourceName, cluster)) {
+			found = append(found, r.Finding)
+		}
+	}
+	slices.SortStableFunc(found, func(a, b *securitycenter.Finding) int {
+		return severityRank(a) - severityRank(b)
+	})
+
+	var b strings.Builder
+	scope := "the GKE clusters of project " + projectID
+	if cluster != "" {
+		scope = "cluster " + cluster
+	}
+	fmt.Fprintf(&b, "%d Security Command Center findings about %s", len(found), scope)
+	if resp.TotalSize > int64(len(found)) {
+		fmt.Fprintf(&b, ", of %d matching", resp.TotalSize)
+	}
+	b.WriteString(":\n")
+	findings := findingList{Findings: []finding{}}
+	for _, f := range found {
+		b.WriteString(describe(f) + "\n")
+		findings.Findings = append(findings.Findings, toFinding(f))
+	}
+	if resp.NextPageToken != "" {
+		b.WriteString("\n" + paging.Footer(&paging.Cursor{PageToken: resp.NextPageToken}))
+	}
+	result, err := structured.Result(b.String(), structured.Findings, findings)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) listSecurityFindingsCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return nil
+	}
+	filter := findingsFilter(request.GetString("cluster", ""), request.GetString("min_severity", ""), request.GetBool("threats_only", false), request.GetBool("include_inactive", false), request.GetBool("include_muted", false))
+	return []string{explain.Join("gcloud scc findings list", "projects/"+projectID, "--source=-", "--filter="+filter, "--order-by=event_time desc")}
+}
+
+// updateRequest returns the API method and request of an action on a
+// finding.
+func updateRequest(action string, now time.Time) (string, any) {
+	switch action {
+	case actionMute:
+		return "securitycenter.projects.sources.findings.setMute", &securitycenter.SetMuteRequest{Mute: "MUTED"}
+	case actionUnmute:
+		return "securitycenter.projects.sources.findings.setMute", &securitycenter.SetMuteRequest{Mute: "UNMUTED"}
+	default:
+		return "securitycenter.projects.sources.findings.setState", &securitycenter.SetFindingStateRequest{State: "INACTIVE", StartTime: now.UTC().Format(time.RFC3339)}
+	}
+}
+
+func (h *handlers) updateSecurityFinding(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("finding")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !findingName.MatchString(name) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid finding %q, must be like projects/my-project/sources/123/findings/abc", name)), nil
+	}
+	action, err := request.RequireString("action")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !slices.Contains(actions, action) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid action %q, must be one of %s", action, strings.Join(actions, ", "))), nil
+	}
+
+	method, req := updateRequest(action, time.Now())
+	if dryrun.Enabled(request, h.c) {
+		data, err := json.MarshalIndent(req, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		result := dryrun.Describe(fmt.Sprintf("%s the finding %s", action, name))
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf("API method: %s\nName: %s\nRequest:\n```json\n%s\n```\n\nEquivalent command:\n```sh\n%s\n```", method, name, data, updateCommand(name, action))))
+		return result, nil
+	}
+
+	svc, err := h.newService(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var f *securitycenter.Finding
+	switch req := req.(type) {
+	case *securitycenter.SetMuteRequest:
+		f, err = svc.Projects.Sources.Findings.SetMute(name, req).Context(ctx).Do()
+	case *securitycenter.SetFindingStateRequest:
+		f, err = svc.Projects.Sources.Findings.SetState(name, req).Context(ctx).Do()
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Finding %s (%s on %s) is now %s and %s.", f.Name, f.Category, f.ResourceName, strings.ToLower(f.State), strings.ToLower(f.Mute))), nil
+}
+
+// updateCommand returns the gcloud command taking action on a finding.
+func updateCommand(name, action string) string {
+	switch action {
+	case actionMute:
+		return explain.Join("gcloud scc findings set-mute", name, "--mute=MUTED")
+	case actionUnmute:
+		return explain.Join("gcloud scc findings set-mute", name, "--mute=UNMUTED")
+	default:
+		return explain.Join("gcloud scc findings update", name, "--state=INACTIVE")
+	}
+}
+
+func (h *handlers) updateSecurityFindingCommands(_ context.Context, request mcp.CallToolRequest) []string {
+	name, action := request.GetString("finding", ""), request.GetString("action", "")
+	if name == "" || !slices.Contains(actions, action) {
+		return nil
+	}
+	return []string{updateCommand(name, action)}
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	securitycenter "google.golang.org/api/securitycenter/v1"
+)
+
+func TestFindingsFilter(t *testing.T) {
+	tests := []struct {
+		name                                      string
+		cluster, minSeverity                      string
+		threatsOnly, includeInactive, includeMute bool
+		want                                      string
+	}{
+		{
+			name: "defaults",
+			want: `resource_name:"//container.googleapis.com/" AND state="ACTIVE" AND NOT mute="MUTED"`,
+		},
+		{
+			name:        "high threats of a cluster",
+			cluster:     "prod",
+			minSeverity: "HIGH",
+			threatsOnly: true,
+			want:        `resource_name:"//container.googleapis.com/" AND resource_name:"/clusters/prod" AND (severity="HIGH" OR severity="CRITICAL") AND finding_class="THREAT" AND state="ACTIVE" AND NOT mute="MUTED"`,
+		},
+		{
+			name:            "everything",
+			minSeverity:     "LOW",
+			includeInactive: true,
+			includeMute:     true,
+			want:            `resource_name:"//container.googleapis.com/"`,
+		},
+	}
+	for _, tc := range tests {
+		if got := findingsFilter(tc.cluster, tc.minSeverity, tc.threatsOnly, tc.includeInactive, tc.includeMute); got != tc.want {
+			t.Errorf("%s: findingsFilter() = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestInCluster(t *testing.T) {
+	tests := []struct {
+		resource string
+		want     bool
+	}{
+		{"//container.googleapis.com/projects/p/locations/us-central1/clusters/prod", true},
+		{"//container.googleapis.com/projects/p/zones/us-central1-a/clusters/prod/k8s/namespaces/web/pods/web-1", true},
+		{"//container.googleapis.com/projects/p/locations/us-central1/clusters/prod-2", false},
+	}
+	for _, tc := range tests {
+		if got := inCluster(tc.resource, "prod"); got != tc.want {
+			t.Errorf("inCluster(%q, prod) = %v, want %v", tc.resource, got, tc.want)
+		}
+	}
+}
+
+func TestToFinding(t *testing.T) {
+	f := &securitycenter.Finding{
+		Name:         "projects/p/sources/1/findings/a",
+		Category:     "Added Binary Executed",
+		Severity:     "CRITICAL",
+		State:        "ACTIVE",
+		Mute:         "MUTED",
+		ResourceName: "//container.googleapis.com/projects/p/locations/us-central1/clusters/prod",
+		EventTime:    "2026-10-01T12:00:00Z",
+	}
+	want := finding{
+		Name:        "projects/p/sources/1/findings/a",
+		Description: "Added Binary Executed",
+		Type:        "Added Binary Executed",
+		Category:    "SECURITY",
+		Priority:    "P1",
+		State:       "MUTED",
+		Resource:    "//container.googleapis.com/projects/p/locations/us-central1/clusters/prod",
+		Updated:     "2026-10-01T12:00:00Z",
+	}
+	if diff := cmp.Diff(want, toFinding(f)); diff != "" {
+		t.Errorf("toFinding() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpdateRequest(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		action     string
+		wantMethod string
+		wantReq    any
+	}{
+		{actionMute, "securitycenter.projects.sources.findings.setMute", &securitycenter.SetMuteRequest{Mute: "MUTED"}},
+		{actionUnmute, "securitycenter.projects.sources.findings.setMute", &securitycenter.SetMuteRequest{Mute: "UNMUTED"}},
+		{actionAcknowledge, "securitycenter.projects.sources.findings.setState", &securitycenter.SetFindingStateRequest{State: "INACTIVE", StartTime: "2026-10-01T12:00:00Z"}},
+	}
+	for _, tc := range tests {
+		method, req := updateRequest(tc.action, now)
+		if method != tc.wantMethod {
+			t.Errorf("updateRequest(%s) method = %s, want %s", tc.action, method, tc.wantMethod)
+		}
+		if diff := cmp.Diff(tc.wantReq, req); diff != "" {
+			t.Errorf("updateRequest(%s) request mismatch (-want +got):\n%s", tc.action, diff)
+		}
+	}
+}
@@ -0,0 +1,698 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of the flows policies are generated from.
+const (
+	vpcFlowLogs = "vpc_flow_logs"
+	dataplaneV2 = "dataplane_v2"
+)
+
+var flowSources = []string{vpcFlowLogs, dataplaneV2}
+
+const (
+	defaultPolicyHours = 24
+	// maxPolicyFlows is the number of flow log entries read.
+	maxPolicyFlows = 20000
+	// maxIPPeers is the number of public IPs a workload may talk to before
+	// they're allowed as the whole internet.
+	maxIPPeers = 5
+)
+
+// volatileLabels change between the pods of a workload, so they don't
+// select it.
+var volatileLabels = []string{
+	"pod-template-hash",
+	"controller-revision-hash",
+	"pod-template-generation",
+	"statefulset.kubernetes.io/pod-name",
+	"apps.kubernetes.io/pod-index",
+	"controller-uid",
+	"batch.kubernetes.io/controller-uid",
+}
+
+// healthCheckRanges are the sources of the Google Cloud load balancer
+// health checks, which container-native load balancing sends to pods.
+var healthCheckRanges = []netip.Prefix{netip.MustParsePrefix("35.191.0.0/16"), netip.MustParsePrefix("130.211.0.0/22")}
+
+// privateRanges aren't part of the internet.
+var privateRanges = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+
+// networkPolicy is a Kubernetes NetworkPolicy.
+type networkPolicy struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   policyMetadata `yaml:"metadata"`
+	Spec       policySpec     `yaml:"spec"`
+}
+
+type policyMetadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type policySpec struct {
+	PodSelector labelSelector `yaml:"podSelector"`
+	PolicyTypes []string      `yaml:"policyTypes"`
+	Ingress     []policyRule  `yaml:"ingress,omitempty"`
+	Egress      []policyRule  `yaml:"egress,omitempty"`
+}
+
+type labelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels,omitempty"`
+}
+
+type policyRule struct {
+	From  []policyPeer `yaml:"from,omitempty"`
+	To    []policyPeer `yaml:"to,omitempty"`
+	Ports []policyPort `yaml:"ports,omitempty"`
+}
+
+type policyPeer struct {
+	NamespaceSelector *labelSelector `yaml:"namespaceSelector,omitempty"`
+	PodSelector       *labelSelector `yaml:"podSelector,omitempty"`
+	IPBlock           *ipBlock       `yaml:"ipBlock,omitempty"`
+}
+
+type ipBlock struct {
+	CIDR   string   `yaml:"cidr"`
+	Except []string `yaml:"except,omitempty"`
+}
+
+type policyPort struct {
+	Protocol string `yaml:"protocol"`
+	Port     int    `yaml:"port"`
+}
+
+// generatedLabel marks the policies the tool generates.
+var generatedLabel = map[string]string{"app.kubernetes.io/managed-by": "gke-mcp"}
+
+func newPolicy(namespace, name string, selector map[string]string) networkPolicy {
+	return networkPolicy{
+		APIVersion: "networking.k8s.io/v1",
+		Kind:       "NetworkPolicy",
+		Metadata:   policyMetadata{Name: name, Namespace: namespace, Labels: generatedLabel},
+		Spec:       policySpec{PodSelector: labelSelector{MatchLabels: selector}, PolicyTypes: []string{"Ingress", "Egress"}},
+	}
+}
+
+// baselinePolicies deny all the traffic of the pods of a namespace but DNS
+// lookups, which the generated policies then allow.
+func baselinePolicies(namespace string) []networkPolicy {
+	deny := newPolicy(namespace, "default-deny", nil)
+	dns := newPolicy(namespace, "allow-dns", nil)
+	dns.Spec.PolicyTypes = []string{"Egress"}
+	dns.Spec.Egress = []policyRule{{
+		To: []policyPeer{{
+			NamespaceSelector: &labelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"}},
+			PodSelector:       &labelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}},
+		}},
+		Ports: []policyPort{{Protocol: "UDP", Port: 53}, {Protocol: "TCP", Port: 53}},
+	}}
+	return []networkPolicy{deny, dns}
+}
+
+// netpolPod is the part of a pod used to resolve the ends of flows.
+type netpolPod struct {
+	Metadata struct {
+		Name            string                `json:"name"`
+		Namespace       string                `json:"namespace"`
+		Labels          map[string]string     `json:"labels"`
+		OwnerReferences []kube.OwnerReference `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		HostNetwork bool `json:"hostNetwork"`
+	} `json:"spec"`
+	Status struct {
+		PodIP string `json:"podIP"`
+	} `json:"status"`
+}
+
+// liveWorkload is a workload running in the cluster, and the labels its
+// pods share, which select it.
+type liveWorkload struct {
+	Namespace string
+	Name      string
+	Selector  map[string]string
+}
+
+// resolver finds the workloads of the ends of flows.
+type resolver struct {
+	byPod     map[string]string
+	byIP      map[string]string
+	workloads map[string]*liveWorkload
+}
+
+func newResolver(pods []netpolPod) *resolver {
+	r := &resolver{byPod: map[string]string{}, byIP: map[string]string{}, workloads: map[string]*liveWorkload{}}
+	for _, p := range pods {
+		m := p.Metadata
+		id := kube.Workload(m.Namespace, m.Name, m.Labels, m.OwnerReferences)
+		r.byPod[m.Namespace+"/"+m.Name] = id
+		if p.Status.PodIP != "" && !p.Spec.HostNetwork {
+			r.byIP[p.Status.PodIP] = id
+		}
+		labels := maps.Clone(m.Labels)
+		for _, l := range volatileLabels {
+			delete(labels, l)
+		}
+		w := r.workloads[id]
+		if w == nil {
+			r.workloads[id] = &liveWorkload{Namespace: m.Namespace, Name: id[strings.LastIndex(id, "/")+1:], Selector: labels}
+			continue
+		}
+		maps.DeleteFunc(w.Selector, func(k, v string) bool { return labels[k] != v })
+	}
+	return r
+}
+
+// resolve returns the workload of a pod, or of an IP if the pod is
+// unknown, or "" if it runs no more. Pods replaced since the flow are
+// attributed to the workload whose name prefixes theirs.
+func (r *resolver) resolve(namespace, pod, ip string) string {
+	if id := r.byPod[namespace+"/"+pod]; id != "" {
+		return id
+	}
+	if pod == "" {
+		return r.byIP[ip]
+	}
+	var best string
+	for id, w := range r.workloads {
+		if w.Namespace == namespace && strings.HasPrefix(pod, w.Name+"-") && len(id) > len(best) {
+			best = id
+		}
+	}
+	return best
+}
+
+// flowEnd is one end of an observed flow: a workload if it's a pod still
+// running, else an IP.
+type flowEnd struct {
+	Workload string
+	// Namespace is the namespace of the pod, even if it's gone.
+	Namespace string
+	IP        string
+}
+
+type observedFlow struct {
+	Src, Dest flowEnd
+	Protocol  string
+	Port      int
+}
+
+// flowPod is a pod end of a flow log entry, as VPC flow logs and Dataplane
+// V2 logs name it.
+type flowPod struct {
+	Name      string `json:"pod_name"`
+	Namespace string `json:"pod_namespace"`
+}
+
+// flowLog is the part of a VPC flow log or Dataplane V2 network policy log
+// entry used.
+type flowLog struct {
+	Connection struct {
+		SrcIP    string `json:"src_ip"`
+		DestIP   string `json:"dest_ip"`
+		SrcPort  int    `json:"src_port"`
+		DestPort int    `json:"dest_port"`
+		// Protocol is an IANA number in VPC flow logs and a name in
+		// Dataplane V2 logs.
+		Protocol any `json:"protocol"`
+	} `json:"connection"`
+	SrcGKEDetails struct {
+		Pod flowPod `json:"pod"`
+	} `json:"src_gke_details"`
+	DestGKEDetails struct {
+		Pod flowPod `json:"pod"`
+	} `json:"dest_gke_details"`
+	Src  flowPod `json:"src"`
+	Dest flowPod `json:"dest"`
+}
+
+// protocolName returns the NetworkPolicy protocol of a flow, or "" if
+// policies can't select it, like ICMP.
+func protocolName(p any) string {
+	switch p := p.(type) {
+	case float64:
+		return map[float64]string{6: "TCP", 17: "UDP", 132: "SCTP"}[p]
+	case string:
+		if n, err := strconv.Atoi(p); err == nil {
+			return protocolName(float64(n))
+		}
+		if name := strings.ToUpper(p); slices.Contains([]string{"TCP", "UDP", "SCTP"}, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// observe resolves the ends of flow log entries. Entries of responses,
+// from a lower port to an ephemeral one, are left out.
+func observe(logs []flowLog, r *resolver) []observedFlow {
+	var flows []observedFlow
+	for _, l := range logs {
+		c := l.Connection
+		protocol := protocolName(c.Protocol)
+		if protocol == "" || c.DestPort == 0 || (c.DestPort >= 32768 && c.SrcPort < c.DestPort) {
+			continue
+		}
+		src, dest := l.Src, l.Dest
+		if src.Name == "" {
+			src = l.SrcGKEDetails.Pod
+		}
+		if dest.Name == "" {
+			dest = l.DestGKEDetails.Pod
+		}
+		flows = append(flows, observedFlow{
+			Src:      flowEnd{Workload: r.resolve(src.Namespace, src.Name, c.SrcIP), Namespace: src.Namespace, IP: c.SrcIP},
+			Dest:     flowEnd{Workload: r.resolve(dest.Namespace, dest.Name, c.DestIP), Namespace: dest.Namespace, IP: c.DestIP},
+			Protocol: protocol,
+			Port:     c.DestPort,
+		})
+	}
+	return flows
+}
+
+// policyImpact is what moving a workload to default-deny with its
+// generated policy allows, and what to check first.
+type policyImpact struct {
+	Workload string   `json:"workload"`
+	Policy   string   `json:"policy,omitempty"`
+	Flows    int      `json:"flows"`
+	Ingress  []string `json:"ingressAllowed,omitempty"`
+	Egress   []string `json:"egressAllowed,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// peerSet collects the peers of a workload in one direction, and the ports
+// of each.
+type peerSet map[string][]policyPort
+
+func (s peerSet) add(key string, protocol string, port int) {
+	p := policyPort{Protocol: protocol, Port: port}
+	if !slices.Contains(s[key], p) {
+		s[key] = append(s[key], p)
+	}
+}
+
+// Keys of the peers that aren't workloads.
+const (
+	ipPeer       = "ip:"
+	internetPeer = "internet"
+	checkPeer    = "health-checks"
+)
+
+// peerKey returns the key of the peer at an end of a flow.
+func peerKey(end flowEnd) string {
+	if end.Workload != "" {
+		return end.Workload
+	}
+	if ip, err := netip.ParseAddr(end.IP); err == nil {
+		for _, p := range healthCheckRanges {
+			if p.Contains(ip) {
+				return checkPeer
+			}
+		}
+	}
+	return ipPeer + end.IP
+}
+
+// collapse allows the public IPs of a peer set as the internet when there
+// are more than maxIPPeers of them.
+func collapse(s peerSet) {
+	var public []string
+	for key := range s {
+		ip, err := netip.ParseAddr(strings.TrimPrefix(key, ipPeer))
+		if strings.HasPrefix(key, ipPeer) && err == nil && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
+			public = append(public, key)
+		}
+	}
+	if len(public) <= maxIPPeers {
+		return
+	}
+	for _, key := range public {
+		for _, p := range s[key] {
+			s.add(internetPeer, p.Protocol, p.Port)
+		}
+		delete(s, key)
+	}
+}
+
+// toPeers returns the NetworkPolicy peers of a peer key.
+func toPeers(key, namespace string, workloads map[string]*liveWorkload) []policyPeer {
+	switch {
+	case key == internetPeer:
+		return []policyPeer{{IPBlock: &ipBlock{CIDR: "0.0.0.0/0", Except: privateRanges}}}
+	case key == checkPeer:
+		var peers []policyPeer
+		for _, p := range healthCheckRanges {
+			peers = append(peers, policyPeer{IPBlock: &ipBlock{CIDR: p.String()}})
+		}
+		return peers
+	case strings.HasPrefix(key, ipPeer):
+		ip := strings.TrimPrefix(key, ipPeer)
+		bits := "/32"
+		if strings.Contains(ip, ":") {
+			bits = "/128"
+		}
+		return []policyPeer{{IPBlock: &ipBlock{CIDR: ip + bits}}}
+	}
+	w := workloads[key]
+	peer := policyPeer{PodSelector: &labelSelector{MatchLabels: w.Selector}}
+	if w.Namespace != namespace {
+		peer.NamespaceSelector = &labelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": w.Namespace}}
+	}
+	return []policyPeer{peer}
+}
+
+// toRules turns a peer set into rules, one per set of ports, and describes
+// what each allows.
+func toRules(s peerSet, namespace string, workloads map[string]*liveWorkload, ingress bool) ([]policyRule, []string) {
+	var rules []policyRule
+	var described []string
+	byPorts := map[string]int{}
+	for _, key := range slices.Sorted(maps.Keys(s)) {
+		ports := s[key]
+		slices.SortFunc(ports, func(a, b policyPort) int {
+			if a.Port != b.Port {
+				return a.Port - b.Port
+			}
+			return strings.Compare(a.Protocol, b.Protocol)
+		})
+		var names []string
+		for _, p := range ports {
+			names = append(names, fmt.Sprintf("%s/%d", p.Protocol, p.Port))
+		}
+		portKey := strings.Join(names, ",")
+		described = append(described, fmt.Sprintf("%s on %s", key, portKey))
+		i, ok := byPorts[portKey]
+		if !ok {
+			i = len(rules)
+			byPorts[portKey] = i
+			rules = append(rules, policyRule{Ports: ports})
+		}
+		if ingress {
+			rules[i].From = append(rules[i].From, toPeers(key, namespace, workloads)...)
+		} else {
+			rules[i].To = append(rules[i].To, toPeers(key, namespace, workloads)...)
+		}
+	}
+	return rules, described
+}
+
+// policyName returns the name of the policy of a workload.
+func policyName(workload string) string {
+	name := "allow-" + strings.ToLower(workload)
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
+
+// synthesize generates the policies allowing the flows observed in a
+// namespace, after default-deny, and the impact of applying them.
+func synthesize(namespace string, flows []observedFlow, r *resolver) ([]networkPolicy, []policyImpact, []string) {
+	ingress, egress := map[string]peerSet{}, map[string]peerSet{}
+	counts := map[string]int{}
+	unresolved := map[string]bool{}
+	for _, f := range flows {
+		if f.Dest.Namespace == namespace || (f.Dest.Workload != "" && r.workloads[f.Dest.Workload].Namespace == namespace) {
+			if f.Dest.Workload == "" {
+				unresolved[f.Dest.IP] = true
+			} else {
+				if ingress[f.Dest.Workload] == nil {
+					ingress[f.Dest.Workload] = peerSet{}
+				}
+				ingress[f.Dest.Workload].add(peerKey(f.Src), f.Protocol, f.Port)
+				counts[f.Dest.Workload]++
+			}
+		}
+		if f.Src.Namespace == namespace || (f.Src.Workload != "" && r.workloads[f.Src.Workload].Namespace == namespace) {
+			if f.Src.Workload == "" {
+				unresolved[f.Src.IP] = true
+			} else {
+				if egress[f.Src.Workload] == nil {
+					egress[f.Src.Workload] = peerSet{}
+				}
+				egress[f.Src.Workload].add(peerKey(f.Dest), f.Protocol, f.Port)
+				counts[f.Src.Workload]++
+			}
+		}
+	}
+
+	policies := baselinePolicies(namespace)
+	impacts := []policyImpact{}
+	for _, id := range slices.Sorted(maps.Keys(r.workloads)) {
+		w := r.workloads[id]
+		if w.Namespace != namespace {
+			continue
+		}
+		impact := policyImpact{Workload: id, Flows: counts[id]}
+		if counts[id] == 0 {
+			impact.Warnings = append(impact.Warnings, "No traffic was observed, so default-deny cuts all of it but DNS lookups; check that it really serves and calls nothing, such as a job that runs rarely.")
+		}
+		if len(w.Selector) == 0 {
+			impact.Warnings = append(impact.Warnings, "Its pods share no stable label, so no policy can select them; label them and generate the policies again.")
+			impacts = append(impacts, impact)
+			continue
+		}
+		if counts[id] == 0 {
+			impacts = append(impacts, impact)
+			continue
+		}
+		p := newPolicy(namespace, policyName(w.Name), w.Selector)
+		for _, s := range []peerSet{ingress[id], egress[id]} {
+			collapse(s)
+			for key := range s {
+				if strings.HasPrefix(key, ipPeer) {
+					if ip, err := netip.ParseAddr(strings.TrimPrefix(key, ipPeer)); err == nil && ip.IsPrivate() {
+						impact.Warnings = appendNew(impact.Warnings, "Some peers inside the network aren't running pods, or pods that are gone, and are allowed by IP; pod IPs change when pods are rescheduled, so replace them with selectors if they're pods.")
+					}
+				}
+				if key == internetPeer {
+					impact.Warnings = appendNew(impact.Warnings, fmt.Sprintf("It talks with more than %d public IPs, so the policy allows the whole internet on those ports; narrow it down if the peers are known.", maxIPPeers))
+				}
+			}
+		}
+		p.Spec.Ingress, impact.Ingress = toRules(ingress[id], namespace, r.workloads, true)
+		p.Spec.Egress, impact.Egress = toRules(egress[id], namespace, r.workloads, false)
+		impact.Policy = p.Metadata.Name
+		policies = append(policies, p)
+		impacts = append(impacts, impact)
+	}
+
+	var warnings []string
+	if len(unresolved) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d pod IPs of the namespace in the flows belong to no running workload, so their flows were left out.", len(unresolved)))
+	}
+	return policies, impacts, warnings
+}
+
+// policyFlowFilter selects the flow log entries of a namespace of a cluster.
+func policyFlowFilter(projectID, cluster, namespace, source string) string {
+	if source == dataplaneV2 {
+		return fmt.Sprintf(`logName="projects/%s/logs/policy-action" AND resource.labels.cluster_name=%q AND (jsonPayload.src.pod_namespace=%q OR jsonPayload.dest.pod_namespace=%q)`, projectID, cluster, namespace, namespace)
+	}
+	return fmt.Sprintf(`logName="projects/%s/logs/compute.googleapis.com%%2Fvpc_flows" AND ((jsonPayload.src_gke_details.cluster.cluster_name=%q AND jsonPayload.src_gke_details.pod.pod_namespace=%q) OR (jsonPayload.dest_gke_details.cluster.cluster_name=%q AND jsonPayload.dest_gke_details.pod.pod_namespace=%q))`, projectID, cluster, namespace, cluster, namespace)
+}
+
+// readFlowLogs reads up to maxPolicyFlows flow log entries of a namespace
+// over the last hours.
+func (h *handlers) readFlowLogs(ctx context.Context, projectID, filter string, hours int) ([]flowLog, error) {
+	client, err := gcp.Logging(ctx, h.c)
+	if err != nil {
+		return nil, err
+	}
+	it := client.ListLogEntries(ctx, &loggingpb.ListLogEntriesRequest{
+		ResourceNames: []string{"projects/" + projectID},
+		Filter:        fmt.Sprintf(`%s AND timestamp>="%s"`, filter, time.Now().Add(-time.Duration(hours)*time.Hour).UTC().Format(time.RFC3339)),
+		OrderBy:       "timestamp desc",
+		PageSize:      1000,
+	})
+	var logs []flowLog
+	for len(logs) < maxPolicyFlows {
+		e, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the flow logs: %w", err)
+		}
+		data, err := protojson.Marshal(e.GetJsonPayload())
+		if err != nil {
+			return nil, err
+		}
+		var l flowLog
+		if err := json.Unmarshal(data, &l); err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, nil
+}
+
+// enforcesPolicies tells whether a cluster enforces NetworkPolicies.
+func enforcesPolicies(c *containerpb.Cluster) bool {
+	return c.GetAutopilot().GetEnabled() || c.GetNetworkPolicy().GetEnabled() || c.GetNetworkConfig().GetDatapathProvider() == containerpb.DatapathProvider_ADVANCED_DATAPATH
+}
+
+func (h *handlers) generateNetworkPolicies(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	namespace, err := request.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	source := request.GetString("source", vpcFlowLogs)
+	if !slices.Contains(flowSources, source) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid source %q, must be one of %s", source, strings.Join(flowSources, ", "))), nil
+	}
+	hours := request.GetInt("hours", defaultPolicyHours)
+	if hours < 1 {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid hours %d, must be at least 1", hours)), nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var pods struct {
+		Items []netpolPod `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/pods?fieldSelector=status.phase%3DRunning", &pods); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var existing struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := k.Get(ctx, "/apis/networking.k8s.io/v1/namespaces/"+url.PathEscape(namespace)+"/networkpolicies", &existing); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	logs, err := h.readFlowLogs(ctx, projectID, policyFlowFilter(projectID, name, namespace, source), hours)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(logs) == 0 {
+		msg := fmt.Sprintf("no flow logs of namespace %s in the last %d hours", namespace, hours)
+		if source == vpcFlowLogs {
+			msg += "; enable VPC flow logs on the cluster's subnet, and intranode visibility to see the traffic between pods of the same node"
+		} else {
+			msg += "; enable network policy logging, which only logs the connections policies apply to"
+		}
+		return mcp.NewToolResultError(msg), nil
+	}
+
+	r := newResolver(pods.Items)
+	policies, impacts, warnings := synthesize(namespace, observe(logs, r), r)
+	if !enforcesPolicies(cluster) {
+		warnings = append(warnings, "The cluster doesn't enforce NetworkPolicies: enable Dataplane V2 or network policy enforcement, or the policies have no effect.")
+	}
+	if len(existing.Items) > 0 {
+		var names []string
+		for _, p := range existing.Items {
+			names = append(names, p.Metadata.Name)
+		}
+		warnings = append(warnings, fmt.Sprintf("The namespace already has the policies %s; policies add up, so what they allow stays allowed.", strings.Join(names, ", ")))
+	}
+	if len(logs) == maxPolicyFlows {
+		warnings = append(warnings, fmt.Sprintf("Only the latest %d flow log entries were read; shorten the window if recent traffic is enough.", maxPolicyFlows))
+	}
+	warnings = append(warnings,
+		"Flow logs are sampled and only cover the window read, so rare traffic, like that of jobs or failovers, may be missing: apply the policies to a staging namespace first, and watch the denied connections with network policy logging.",
+		"Pods that call Google Cloud with Workload Identity also need egress to the metadata server, 169.254.169.252/32 on TCP 988, or 169.254.169.254/32 on TCP 80 with Dataplane V2.",
+	)
+
+	var docs []string
+	for _, p := range policies {
+		data, err := yaml.Marshal(p)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		docs = append(docs, string(data))
+	}
+	impact, err := json.MarshalIndent(struct {
+		Workloads []policyImpact `json:"workloads"`
+		Warnings  []string       `json:"warnings"`
+	}{impacts, warnings}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	text := fmt.Sprintf("Generated %d NetworkPolicies for namespace %s of cluster %s from %d %s entries of the last %d hours: default-deny, DNS lookups, and the traffic observed for each workload. Review them, and check them against the cluster with diff_manifests before applying them.\n\n```yaml\n%s```\n\nImpact:\n%s",
+		len(policies), namespace, name, len(logs), strings.ReplaceAll(source, "_", " "), hours, strings.Join(docs, "---\n"), impact)
+	return mcp.NewToolResultText(text), nil
+}
+
+func (h *handlers) generateNetworkPoliciesCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	namespace := request.GetString("namespace", "")
+	if projectID == "" || location == "" || cluster == "" || namespace == "" {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud logging read", policyFlowFilter(projectID, cluster, namespace, request.GetString("source", vpcFlowLogs)), explain.Flag("project", projectID), explain.Flag("freshness", fmt.Sprintf("%dh", request.GetInt("hours", defaultPolicyHours))), explain.Flag("limit", strconv.Itoa(maxPolicyFlows))),
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get pods --all-namespaces --field-selector=status.phase=Running --output=wide --show-labels",
+		explain.Join("kubectl get networkpolicies", explain.Flag("namespace", namespace)),
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func netpolResolver(t *testing.T) *resolver {
+	t.Helper()
+	var pods []netpolPod
+	if err := json.Unmarshal([]byte(`[
+		{"metadata":{"name":"web-5c8f-a","namespace":"shop","labels":{"app":"web","tier":"front","pod-template-hash":"5c8f"},"ownerReferences":[{"kind":"ReplicaSet","name":"web-5c8f"}]},"status":{"podIP":"10.8.0.10"}},
+		{"metadata":{"name":"web-5c8f-b","namespace":"shop","labels":{"app":"web","pod-template-hash":"5c8f"},"ownerReferences":[{"kind":"ReplicaSet","name":"web-5c8f"}]},"status":{"podIP":"10.8.0.11"}},
+		{"metadata":{"name":"db-0","namespace":"shop","labels":{"app":"db","statefulset.kubernetes.io/pod-name":"db-0"},"ownerReferences":[{"kind":"StatefulSet","name":"db"}]},"status":{"podIP":"10.8.1.5"}},
+		{"metadata":{"name":"cron-x","namespace":"shop","labels":{"pod-template-hash":"1"}},"status":{"podIP":"10.8.1.6"}},
+		{"metadata":{"name":"ingress-0","namespace":"ingress","labels":{"app":"ingress"},"ownerReferences":[{"kind":"StatefulSet","name":"ingress"}]},"status":{"podIP":"10.8.2.2"}},
+		{"metadata":{"name":"proxy","namespace":"kube-system","labels":{"k8s-app":"proxy"}},"spec":{"hostNetwork":true},"status":{"podIP":"10.0.0.2"}}
+	]`), &pods); err != nil {
+		t.Fatal(err)
+	}
+	return newResolver(pods)
+}
+
+func TestResolve(t *testing.T) {
+	r := netpolResolver(t)
+	if diff := cmp.Diff(map[string]string{"app": "web"}, r.workloads["shop/Deployment/web"].Selector); diff != "" {
+		t.Errorf("web selector mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"app": "db"}, r.workloads["shop/StatefulSet/db"].Selector); diff != "" {
+		t.Errorf("db selector mismatch (-want +got):\n%s", diff)
+	}
+	tests := []struct {
+		namespace, pod, ip, want string
+	}{
+		{"shop", "db-0", "", "shop/StatefulSet/db"},
+		{"shop", "web-5c8f-gone", "10.8.9.9", "shop/Deployment/web"},
+		{"", "", "10.8.2.2", "ingress/StatefulSet/ingress"},
+		{"", "", "10.0.0.2", ""},
+		{"shop", "api-1", "", ""},
+	}
+	for _, tc := range tests {
+		if got := r.resolve(tc.namespace, tc.pod, tc.ip); got != tc.want {
+			t.Errorf("resolve(%q, %q, %q) = %q, want %q", tc.namespace, tc.pod, tc.ip, got, tc.want)
+		}
+	}
+}
+
+func TestSynthesize(t *testing.T) {
+	r := netpolResolver(t)
+	var logs []flowLog
+	if err := json.Unmarshal([]byte(`[
+		{"connection":{"src_ip":"10.8.2.2","dest_ip":"10.8.0.10","src_port":51000,"dest_port":8080,"protocol":6},
+			"src_gke_details":{"pod":{"pod_name":"ingress-0","pod_namespace":"ingress"}},"dest_gke_details":{"pod":{"pod_name":"web-5c8f-a","pod_namespace":"shop"}}},
+		{"connection":{"src_ip":"10.8.0.10","dest_ip":"10.8.2.2","src_port":8080,"dest_port":51000,"protocol":6},
+			"src_gke_details":{"pod":{"pod_name":"web-5c8f-a","pod_namespace":"shop"}},"dest_gke_details":{"pod":{"pod_name":"ingress-0","pod_namespace":"ingress"}}},
+		{"connection":{"src_ip":"10.8.0.11","dest_ip":"10.8.1.5","src_port":40000,"dest_port":5432,"protocol":"tcp"},
+			"src":{"pod_name":"web-5c8f-b","pod_namespace":"shop"},"dest":{"pod_name":"db-0","pod_namespace":"shop"}},
+		{"connection":{"src_ip":"35.191.4.1","dest_ip":"10.8.0.10","src_port":33000,"dest_port":8080,"protocol":6},
+			"dest_gke_details":{"pod":{"pod_name":"web-5c8f-a","pod_namespace":"shop"}}},
+		{"connection":{"src_ip":"10.8.0.10","dest_ip":"10.20.0.3","src_port":41000,"dest_port":6379,"protocol":6},
+			"src_gke_details":{"pod":{"pod_name":"web-5c8f-a","pod_namespace":"shop"}}},
+		{"connection":{"src_ip":"10.8.0.10","dest_ip":"10.8.1.5","protocol":1},
+			"src_gke_details":{"pod":{"pod_name":"web-5c8f-a","pod_namespace":"shop"}}}
+	]`), &logs); err != nil {
+		t.Fatal(err)
+	}
+
+	policies, impacts, warnings := synthesize("shop", observe(logs, r), r)
+	if len(policies) != 4 {
+		t.Fatalf("synthesize() returned %d policies, want default-deny, allow-dns, allow-web and allow-db", len(policies))
+	}
+	web := policies[2]
+	want := policySpec{
+		PodSelector: labelSelector{MatchLabels: map[string]string{"app": "web"}},
+		PolicyTypes: []string{"Ingress", "Egress"},
+		Ingress: []policyRule{{
+			From: []policyPeer{
+				{IPBlock: &ipBlock{CIDR: "35.191.0.0/16"}},
+				{IPBlock: &ipBlock{CIDR: "130.211.0.0/22"}},
+				{
+					NamespaceSelector: &labelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "ingress"}},
+					PodSelector:       &labelSelector{MatchLabels: map[string]string{"app": "ingress"}},
+				},
+			},
+			Ports: []policyPort{{Protocol: "TCP", Port: 8080}},
+		}},
+		Egress: []policyRule{
+			{To: []policyPeer{{IPBlock: &ipBlock{CIDR: "10.20.0.3/32"}}}, Ports: []policyPort{{Protocol: "TCP", Port: 6379}}},
+			{To: []policyPeer{{PodSelector: &labelSelector{MatchLabels: map[string]string{"app": "db"}}}}, Ports: []policyPort{{Protocol: "TCP", Port: 5432}}},
+		},
+	}
+	if web.Metadata.Name != "allow-web" {
+		t.Errorf("policy name = %s, want allow-web", web.Metadata.Name)
+	}
+	if diff := cmp.Diff(want, web.Spec); diff != "" {
+		t.Errorf("allow-web mismatch (-want +got):\n%s", diff)
+	}
+
+	byWorkload := map[string]policyImpact{}
+	for _, i := range impacts {
+		byWorkload[i.Workload] = i
+	}
+	if i := byWorkload["shop/Pod/cron-x"]; i.Policy != "" || len(i.Warnings) != 2 {
+		t.Errorf("cron-x impact = %+v, want no policy, and warnings about no traffic and no selector", i)
+	}
+	if i := byWorkload["shop/StatefulSet/db"]; i.Policy != "allow-db" || i.Flows != 1 || len(i.Warnings) != 0 {
+		t.Errorf("db impact = %+v, want allow-db with 1 flow", i)
+	}
+	if i := byWorkload["shop/Deployment/web"]; i.Flows != 4 || len(i.Warnings) != 1 {
+		t.Errorf("web impact = %+v, want 4 flows and a warning about the peer allowed by IP", i)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("synthesize() warnings = %q, want none", warnings)
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	s := peerSet{}
+	for _, ip := range []string{"8.8.8.8", "1.1.1.1", "9.9.9.9", "151.101.1.1", "140.82.112.3", "104.16.0.1"} {
+		s.add(ipPeer+ip, "TCP", 443)
+	}
+	s.add(ipPeer+"10.1.0.1", "TCP", 443)
+	collapse(s)
+	want := peerSet{internetPeer: {{Protocol: "TCP", Port: 443}}, ipPeer + "10.1.0.1": {{Protocol: "TCP", Port: 443}}}
+	if diff := cmp.Diff(want, s); diff != "" {
+		t.Errorf("collapse() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,507 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	iam "google.golang.org/api/iam/v1"
+	"google.golang.org/api/policyanalyzer/v1"
+)
+
+// lastAuthentication is the Policy Analyzer activity recording when
+// service account keys were last used.
+const lastAuthentication = "serviceAccountKeyLastAuthentication"
+
+// keyPod is the part of a pod read to find the secrets it mounts.
+type keyPod struct {
+	Metadata struct {
+		Name            string                `json:"name"`
+		Namespace       string                `json:"namespace"`
+		Labels          map[string]string     `json:"labels"`
+		OwnerReferences []kube.OwnerReference `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		ServiceAccountName string `json:"serviceAccountName"`
+		Volumes            []struct {
+			Name   string `json:"name"`
+			Secret *struct {
+				SecretName string `json:"secretName"`
+			} `json:"secret"`
+			Projected *struct {
+				Sources []struct {
+					Secret *struct {
+						Name string `json:"name"`
+					} `json:"secret"`
+				} `json:"sources"`
+			} `json:"projected"`
+		} `json:"volumes"`
+		InitContainers []keyContainer `json:"initContainers"`
+		Containers     []keyContainer `json:"containers"`
+	} `json:"spec"`
+}
+
+type keyContainer struct {
+	Name         string `json:"name"`
+	VolumeMounts []struct {
+		Name      string `json:"name"`
+		MountPath string `json:"mountPath"`
+	} `json:"volumeMounts"`
+	Env []struct {
+		Name      string `json:"name"`
+		ValueFrom *struct {
+			SecretKeyRef *struct {
+				Name string `json:"name"`
+				Key  string `json:"key"`
+			} `json:"secretKeyRef"`
+		} `json:"valueFrom"`
+	} `json:"env"`
+	EnvFrom []struct {
+		SecretRef *struct {
+			Name string `json:"name"`
+		} `json:"secretRef"`
+	} `json:"envFrom"`
+}
+
+// secretUse is a secret a container of a workload uses.
+type secretUse struct {
+	Workload string
+	// ServiceAccount is the Kubernetes service account of the workload.
+	ServiceAccount string
+	Secret         string
+	// Key is the key of the secret used, "" for all of them.
+	Key string
+	// Via is how the container gets it, as a mounted file or a variable.
+	Via string
+}
+
+// secretUses returns the secrets the containers of a pod mount or read in
+// variables.
+func secretUses(p keyPod) []secretUse {
+	m := p.Metadata
+	base := secretUse{Workload: kube.Workload(m.Namespace, m.Name, m.Labels, m.OwnerReferences), ServiceAccount: p.Spec.ServiceAccountName}
+	if base.ServiceAccount == "" {
+		base.ServiceAccount = "default"
+	}
+	volumes := map[string][]string{}
+	for _, v := range p.Spec.Volumes {
+		if v.Secret != nil {
+			volumes[v.Name] = append(volumes[v.Name], v.Secret.SecretName)
+		}
+		if v.Projected != nil {
+			for _, s := range v.Projected.Sources {
+				if s.Secret != nil {
+					volumes[v.Name] = append(volumes[v.Name], s.Secret.Name)
+				}
+			}
+		}
+	}
+	var uses []secretUse
+	for _, c := range slices.Concat(p.Spec.InitContainers, p.Spec.Containers) {
+		for _, vm := range c.VolumeMounts {
+			for _, secret := range volumes[vm.Name] {
+				u := base
+				u.Secret, u.Via = secret, fmt.Sprintf("container %s file %s", c.Name, vm.MountPath)
+				uses = append(uses, u)
+			}
+		}
+		for _, e := range c.Env {
+			if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+				u := base
+				u.Secret, u.Key, u.Via = e.ValueFrom.SecretKeyRef.Name, e.ValueFrom.SecretKeyRef.Key, fmt.Sprintf("container %s env %s", c.Name, e.Name)
+				uses = append(uses, u)
+			}
+		}
+		for _, e := range c.EnvFrom {
+			if e.SecretRef != nil {
+				u := base
+				u.Secret, u.Via = e.SecretRef.Name, fmt.Sprintf("container %s env", c.Name)
+				uses = append(uses, u)
+			}
+		}
+	}
+	return uses
+}
+
+// keyFile is the part of a service account key file read. The private key
+// is never read.
+type keyFile struct {
+	Type         string `json:"type"`
+	PrivateKeyID string `json:"private_key_id"`
+	ClientEmail  string `json:"client_email"`
+}
+
+// keyFiles returns the service account key files in the data of a secret,
+// by key.
+func keyFiles(data map[string][]byte) map[string]keyFile {
+	files := map[string]keyFile{}
+	for k, v := range data {
+		var f keyFile
+		if json.Unmarshal(v, &f) == nil && f.Type == "service_account" && f.PrivateKeyID != "" && f.ClientEmail != "" {
+			files[k] = f
+		}
+	}
+	return files
+}
+
+// mountedKey is a service account key a workload uses.
+type mountedKey struct {
+	Workload       string `json:"workload"`
+	ServiceAccount string `json:"kubernetesServiceAccount"`
+	Secret         string `json:"secret"`
+	Key            string `json:"key"`
+	Via            string `json:"via"`
+}
+
+// keyAudit is what IAM knows about a service account key found in the
+// cluster.
+type keyAudit struct {
+	ServiceAccount string       `json:"serviceAccount"`
+	KeyID          string       `json:"keyId"`
+	Created        string       `json:"created,omitempty"`
+	AgeDays        int          `json:"ageDays,omitempty"`
+	Disabled       bool         `json:"disabled,omitempty"`
+	LastUsed       string       `json:"lastUsed,omitempty"`
+	Error          string       `json:"error,omitempty"`
+	MountedBy      []mountedKey `json:"mountedBy"`
+}
+
+// keyProject returns the project of a service account from its email, or
+// "" for the service accounts GCP creates, whose email has a project number.
+func keyProject(email string) string {
+	_, domain, _ := strings.Cut(email, "@")
+	project, _ := strings.CutSuffix(domain, ".iam.gserviceaccount.com")
+	if project == domain {
+		return ""
+	}
+	return project
+}
+
+// lastUsed returns when a key was last used to authenticate, from a
+// Policy Analyzer activity.
+func lastUsed(activity []byte) string {
+	var a struct {
+		LastAuthenticatedTime string `json:"lastAuthenticatedTime"`
+	}
+	if json.Unmarshal(activity, &a) != nil {
+		return ""
+	}
+	return a.LastAuthenticatedTime
+}
+
+// workloadIdentity is the Workload Identity setup of a cluster.
+type workloadIdentity struct {
+	Pool string
+	// NodePools lists the node pools not running the GKE metadata server,
+	// which Workload Identity needs.
+	NodePools []string
+}
+
+func clusterWorkloadIdentity(c *containerpb.Cluster) workloadIdentity {
+	wi := workloadIdentity{Pool: c.GetWorkloadIdentityConfig().GetWorkloadPool()}
+	if c.GetAutopilot().GetEnabled() {
+		return wi
+	}
+	for _, np := range c.GetNodePools() {
+		if np.GetConfig().GetWorkloadMetadataConfig().GetMode() != containerpb.WorkloadMetadataConfig_GKE_METADATA {
+			wi.NodePools = append(wi.NodePools, np.GetName())
+		}
+	}
+	return wi
+}
+
+// migrationPlan is how to move a workload from keys to Workload Identity.
+type migrationPlan struct {
+	Workload string   `json:"workload"`
+	Steps    []string `json:"steps"`
+}
+
+// planMigration returns the steps moving a workload from the keys it
+// mounts to Workload Identity, impersonating the same service accounts so
+// it keeps their roles.
+func planMigration(projectID, location, cluster string, wi workloadIdentity, workload string, keys []keyAudit) migrationPlan {
+	p := migrationPlan{Workload: workload}
+	ns, kind, name := splitWorkload(workload)
+	pool := wi.Pool
+	if pool == "" {
+		pool = projectID + ".svc.id.goog"
+		p.Steps = append(p.Steps, "Enable Workload Identity on the cluster: "+explain.Join("gcloud container clusters update", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--workload-pool="+pool))
+	}
+	for _, np := range wi.NodePools {
+		p.Steps = append(p.Steps, "Run the GKE metadata server on node pool "+np+", which recreates its nodes: "+explain.Join("gcloud container node-pools update", np, explain.Flag("cluster", cluster), explain.Flag("location", location), explain.Flag("project", projectID), "--workload-metadata=GKE_METADATA"))
+	}
+
+	var ksa string
+	var emails []string
+	var mounts []mountedKey
+	for _, k := range keys {
+		for _, m := range k.MountedBy {
+			if m.Workload == workload {
+				ksa = m.ServiceAccount
+				mounts = append(mounts, m)
+				if !slices.Contains(emails, k.ServiceAccount) {
+					emails = append(emails, k.ServiceAccount)
+				}
+			}
+		}
+	}
+	if len(emails) > 1 {
+		p.Steps = append(p.Steps, fmt.Sprintf("The workload uses the keys of %d service accounts, but can only impersonate one with Workload Identity: grant the roles it needs to %s, or split the workload.", len(emails), emails[0]))
+	}
+	email := emails[0]
+	if ksa == "default" {
+		ksa = name
+		p.Steps = append(p.Steps,
+			"Give the workload its own Kubernetes service account rather than the namespace's default one: "+explain.Join("kubectl create serviceaccount", ksa, explain.Flag("namespace", ns)),
+		)
+		if kind != "Pod" {
+			p.Steps = append(p.Steps, "Run the workload as it: "+explain.Join("kubectl set serviceaccount", strings.ToLower(kind)+"/"+name, ksa, explain.Flag("namespace", ns)))
+		}
+	}
+	p.Steps = append(p.Steps,
+		"Let the Kubernetes service account impersonate the service account: "+explain.Join("gcloud iam service-accounts add-iam-policy-binding", email, "--role=roles/iam.workloadIdentityUser", fmt.Sprintf("--member=serviceAccount:%s[%s/%s]", pool, ns, ksa), explain.Flag("project", keyProject(email))),
+		"Point the Kubernetes service account at it: "+explain.Join("kubectl annotate serviceaccount", ksa, "iam.gke.io/gcp-service-account="+email, explain.Flag("namespace", ns)),
+	)
+	var vias []string
+	for _, m := range mounts {
+		vias = append(vias, m.Via)
+	}
+	p.Steps = append(p.Steps, fmt.Sprintf("Remove the key from the workload (%s), and GOOGLE_APPLICATION_CREDENTIALS if it points to it, then roll it out and check it still reaches Google Cloud.", strings.Join(vias, "; ")))
+	for _, k := range keys {
+		for _, m := range k.MountedBy {
+			if m.Workload == workload {
+				p.Steps = append(p.Steps, fmt.Sprintf("Once no workload mounts it, delete the Secret and the key: %s && %s",
+					explain.Join("kubectl delete secret", m.Secret, explain.Flag("namespace", ns)),
+					explain.Join("gcloud iam service-accounts keys delete", k.KeyID, "--iam-account="+k.ServiceAccount, explain.Flag("project", keyProject(k.ServiceAccount)))))
+				break
+			}
+		}
+	}
+	return p
+}
+
+// splitWorkload splits a workload, as namespace/Kind/name.
+func splitWorkload(workload string) (string, string, string) {
+	parts := strings.SplitN(workload, "/", 3)
+	if len(parts) < 3 {
+		return "", "", workload
+	}
+	return parts[0], parts[1], parts[2]
+}
+
+func (h *handlers) auditServiceAccountKeys(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var pods struct {
+		Items []keyPod `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/pods", &pods); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Read each secret the workloads use once, keeping only the identity
+	// of the keys in them.
+	secrets := map[string]map[string]keyFile{}
+	var unreadable []string
+	keys := map[string]*keyAudit{}
+	seen := map[string]bool{}
+	for _, p := range pods.Items {
+		for _, u := range secretUses(p) {
+			ns, _, _ := splitWorkload(u.Workload)
+			ref := ns + "/" + u.Secret
+			files, ok := secrets[ref]
+			if !ok {
+				var secret struct {
+					Data map[string][]byte `json:"data"`
+				}
+				if err := k.Get(ctx, "/api/v1/namespaces/"+url.PathEscape(ns)+"/secrets/"+url.PathEscape(u.Secret), &secret); err != nil {
+					if !kube.IsNotFound(err) {
+						unreadable = append(unreadable, fmt.Sprintf("%s: %v", ref, err))
+					}
+				}
+				files = keyFiles(secret.Data)
+				secrets[ref] = files
+			}
+			for key, f := range files {
+				if u.Key != "" && u.Key != key {
+					continue
+				}
+				id := f.ClientEmail + "/" + f.PrivateKeyID
+				m := mountedKey{Workload: u.Workload, ServiceAccount: u.ServiceAccount, Secret: u.Secret, Key: key, Via: u.Via}
+				if seen[id+"|"+m.Workload+"|"+m.Via] {
+					continue
+				}
+				seen[id+"|"+m.Workload+"|"+m.Via] = true
+				if keys[id] == nil {
+					keys[id] = &keyAudit{ServiceAccount: f.ClientEmail, KeyID: f.PrivateKeyID}
+				}
+				keys[id].MountedBy = append(keys[id].MountedBy, m)
+			}
+		}
+	}
+
+	audits := []keyAudit{}
+	if len(keys) > 0 {
+		if err := h.auditKeys(ctx, projectID, keys, time.Now()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, a := range keys {
+			audits = append(audits, *a)
+		}
+		sort.Slice(audits, func(i, j int) bool {
+			if audits[i].AgeDays != audits[j].AgeDays {
+				return audits[i].AgeDays > audits[j].AgeDays
+			}
+			return audits[i].ServiceAccount+audits[i].KeyID < audits[j].ServiceAccount+audits[j].KeyID
+		})
+	}
+
+	var workloads []string
+	for _, a := range audits {
+		for _, m := range a.MountedBy {
+			if !slices.Contains(workloads, m.Workload) {
+				workloads = append(workloads, m.Workload)
+			}
+		}
+	}
+	slices.Sort(workloads)
+	wi := clusterWorkloadIdentity(cluster)
+	plans := []migrationPlan{}
+	for _, w := range workloads {
+		plans = append(plans, planMigration(projectID, location, name, wi, w, audits))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d service account keys mounted by %d workloads of cluster %s.", len(audits), len(workloads), name)
+	if wi.Pool == "" {
+		b.WriteString(" Workload Identity is not enabled on the cluster.")
+	}
+	if len(unreadable) > 0 {
+		fmt.Fprintf(&b, " %d secrets couldn't be read, so keys in them are missing: %s.", len(unreadable), strings.Join(unreadable, "; "))
+	}
+	data, err := json.MarshalIndent(struct {
+		Keys  []keyAudit      `json:"keys"`
+		Plans []migrationPlan `json:"migrationPlans"`
+	}{audits, plans}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(b.String() + "\n\n" + string(data)), nil
+}
+
+// auditKeys reads the age and last use of keys from IAM and Policy
+// Analyzer, looking up service accounts GCP creates in projectID. Keys that
+// can't be read keep the error.
+func (h *handlers) auditKeys(ctx context.Context, projectID string, keys map[string]*keyAudit, now time.Time) error {
+	opts, err := auth.ClientOptions(ctx, h.c, config.APIIAM)
+	if err != nil {
+		return err
+	}
+	iamService, err := iam.NewService(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	opts, err = auth.ClientOptions(ctx, h.c, config.APIPolicyAnalyzer)
+	if err != nil {
+		return err
+	}
+	analyzer, err := policyanalyzer.NewService(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	for _, a := range keys {
+		keyName := fmt.Sprintf("projects/-/serviceAccounts/%s/keys/%s", a.ServiceAccount, a.KeyID)
+		key, err := iamService.Projects.ServiceAccounts.Keys.Get(keyName).Context(ctx).Do()
+		if err != nil {
+			a.Error = fmt.Sprintf("failed to get the key, which may have been deleted: %v", err)
+			continue
+		}
+		a.Created, a.Disabled = key.ValidAfterTime, key.Disabled
+		if created, err := time.Parse(time.RFC3339, key.ValidAfterTime); err == nil {
+			a.AgeDays = int(now.Sub(created).Hours() / 24)
+		}
+		project := keyProject(a.ServiceAccount)
+		if project == "" {
+			project = projectID
+		}
+		resp, err := analyzer.Projects.Locations.ActivityTypes.Activities.Query(fmt.Sprintf("projects/%s/locations/global/activityTypes/%s", project, lastAuthentication)).
+			Filter(fmt.Sprintf("activities.fullResourceName=%q", fmt.Sprintf("//iam.googleapis.com/projects/%s/serviceAccounts/%s/keys/%s", project, a.ServiceAccount, a.KeyID))).Context(ctx).Do()
+		if err != nil {
+			a.Error = fmt.Sprintf("failed to query when the key was last used: %v", err)
+			continue
+		}
+		a.LastUsed = "not in the observation period"
+		for _, activity := range resp.Activities {
+			if t := lastUsed(activity.Activity); t != "" {
+				a.LastUsed = t
+			}
+		}
+	}
+	return nil
+}
+
+func (h *handlers) auditServiceAccountKeysCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud container clusters describe", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--format=yaml(workloadIdentityConfig,nodePools[].name,nodePools[].config.workloadMetadataConfig)"),
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get pods --all-namespaces --output=yaml",
+		"kubectl get secret SECRET --namespace=NAMESPACE --output=jsonpath='{.data}'",
+		"gcloud iam service-accounts keys list --iam-account=SERVICE_ACCOUNT --managed-by=user",
+		explain.Join("gcloud policy-intelligence query-activity", "--activity-type="+lastAuthentication, "--project=SERVICE_ACCOUNT_PROJECT"),
+	}
+}
@@ -0,0 +1,521 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/operations"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// gvisor is the runtime class of the pods GKE Sandbox runs.
+const gvisor = "gvisor"
+
+// untrustedName matches the names of namespaces and workloads that usually
+// run code written by others, such as tenants, notebooks or CI jobs.
+var untrustedName = regexp.MustCompile(`(^|[-_.])(tenants?|untrusted|sandbox(es)?|playground|previews?|ci|runners?|builds?|notebooks?|jupyter|eval|exec|plugins?)($|[-_.0-9])`)
+
+// untrustedImage matches the images of tools that run arbitrary code, such
+// as CI runners, notebooks and in-cluster image builders.
+var untrustedImage = regexp.MustCompile(`actions-runner|gitlab-runner|inbound-agent|jnlp|jupyter|notebook|code-server|kaniko|buildkit|buildah`)
+
+// sandboxPod is the part of a pod checked.
+type sandboxPod struct {
+	Metadata struct {
+		Name            string                `json:"name"`
+		Namespace       string                `json:"namespace"`
+		Labels          map[string]string     `json:"labels"`
+		OwnerReferences []kube.OwnerReference `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		RuntimeClassName string `json:"runtimeClassName"`
+		NodeName         string `json:"nodeName"`
+		HostNetwork      bool   `json:"hostNetwork"`
+		HostPID          bool   `json:"hostPID"`
+		HostIPC          bool   `json:"hostIPC"`
+		Volumes          []struct {
+			Name     string `json:"name"`
+			HostPath *struct {
+				Path string `json:"path"`
+			} `json:"hostPath"`
+		} `json:"volumes"`
+		InitContainers []sandboxContainer `json:"initContainers"`
+		Containers     []sandboxContainer `json:"containers"`
+	} `json:"spec"`
+}
+
+type sandboxContainer struct {
+	Name            string `json:"name"`
+	Image           string `json:"image"`
+	SecurityContext *struct {
+		Privileged *bool `json:"privileged"`
+	} `json:"securityContext"`
+	Resources struct {
+		Limits map[string]string `json:"limits"`
+	} `json:"resources"`
+}
+
+// sandboxCandidate is a workload that may run code it doesn't trust, and
+// whether GKE Sandbox can run it.
+type sandboxCandidate struct {
+	Workload       string   `json:"workload"`
+	NodePools      []string `json:"nodePools,omitempty"`
+	Sandboxed      bool     `json:"sandboxed"`
+	Signals        []string `json:"signals"`
+	Incompatible   []string `json:"incompatible,omitempty"`
+	Recommendation string   `json:"recommendation"`
+}
+
+func appendNew(list []string, s string) []string {
+	if slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+// untrustedSignals returns why a pod looks like it runs code it doesn't
+// trust, given the labels of its namespace.
+func untrustedSignals(p sandboxPod, namespaceLabels map[string]string) []string {
+	var signals []string
+	if untrustedName.MatchString(p.Metadata.Namespace) {
+		signals = append(signals, fmt.Sprintf("Runs in namespace %s, whose name suggests tenants, notebooks or CI jobs.", p.Metadata.Namespace))
+	}
+	workload := kube.Workload(p.Metadata.Namespace, p.Metadata.Name, p.Metadata.Labels, p.Metadata.OwnerReferences)
+	if name := workload[strings.LastIndex(workload, "/")+1:]; untrustedName.MatchString(name) {
+		signals = append(signals, fmt.Sprintf("Its name %s suggests tenants, notebooks or CI jobs.", name))
+	}
+	for _, key := range slices.Sorted(maps.Keys(namespaceLabels)) {
+		if strings.Contains(strings.ToLower(key), "tenant") {
+			signals = append(signals, fmt.Sprintf("Its namespace is labeled %s=%s, so the cluster is shared between tenants.", key, namespaceLabels[key]))
+		}
+	}
+	for _, c := range append(p.Spec.InitContainers, p.Spec.Containers...) {
+		if untrustedImage.MatchString(c.Image) {
+			signals = appendNew(signals, fmt.Sprintf("Runs the image %s, a CI runner, notebook or image builder that executes the code it's given.", c.Image))
+		}
+	}
+	return signals
+}
+
+// sandboxIncompatibilities returns what keeps a pod from running in GKE
+// Sandbox.
+func sandboxIncompatibilities(p sandboxPod) []string {
+	var reasons []string
+	if p.Spec.HostNetwork || p.Spec.HostPID || p.Spec.HostIPC {
+		reasons = append(reasons, "Uses the host network, PID or IPC namespace, which GKE Sandbox doesn't support.")
+	}
+	for _, c := range append(p.Spec.InitContainers, p.Spec.Containers...) {
+		if c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged {
+			reasons = appendNew(reasons, fmt.Sprintf("Container %s is privileged, which GKE Sandbox doesn't support.", c.Name))
+		}
+		for resource := range c.Resources.Limits {
+			switch resource {
+			case "nvidia.com/gpu":
+				reasons = appendNew(reasons, "Requests GPUs, which GKE Sandbox only supports for some GPU types on recent GKE versions; check that its GPU type is supported before sandboxing it.")
+			case "google.com/tpu":
+				reasons = appendNew(reasons, "Requests TPUs, which GKE Sandbox doesn't support.")
+			}
+		}
+	}
+	for _, v := range p.Spec.Volumes {
+		if v.HostPath != nil {
+			reasons = appendNew(reasons, fmt.Sprintf("Mounts the host path %s, which gVisor doesn't share with the node.", v.HostPath.Path))
+		}
+	}
+	return reasons
+}
+
+// poolIncompatibilities returns what keeps a node pool from running GKE
+// Sandbox.
+func poolIncompatibilities(np *containerpb.NodePool) []string {
+	var reasons []string
+	if image := strings.ToUpper(np.GetConfig().GetImageType()); image != "" && image != "COS_CONTAINERD" {
+		reasons = append(reasons, fmt.Sprintf("Runs the %s node image; GKE Sandbox needs Container-Optimized OS with containerd.", image))
+	}
+	for _, a := range np.GetConfig().GetAccelerators() {
+		reasons = append(reasons, fmt.Sprintf("Has %s GPUs, which GKE Sandbox only supports for some GPU types on recent GKE versions.", a.GetAcceleratorType()))
+	}
+	return reasons
+}
+
+// sandboxedPools returns the node pools of a cluster running GKE Sandbox.
+func sandboxedPools(cluster *containerpb.Cluster) []string {
+	var pools []string
+	for _, np := range cluster.GetNodePools() {
+		if np.GetConfig().GetSandboxConfig().GetType() == containerpb.SandboxConfig_GVISOR {
+			pools = append(pools, np.GetName())
+		}
+	}
+	return pools
+}
+
+// sandboxCandidates finds the workloads that look like they run code they
+// don't trust, and recommends how to sandbox them. namespaces has the labels
+// of the namespaces, nodePools the node pool of each node.
+func sandboxCandidates(pods []sandboxPod, namespaces map[string]map[string]string, nodePools map[string]string, autopilot bool, sandboxed []string) []sandboxCandidate {
+	candidates := map[string]*sandboxCandidate{}
+	var order []string
+	for _, p := range pods {
+		if managedNamespace(p.Metadata.Namespace) {
+			continue
+		}
+		signals := untrustedSignals(p, namespaces[p.Metadata.Namespace])
+		if len(signals) == 0 {
+			continue
+		}
+		workload := kube.Workload(p.Metadata.Namespace, p.Metadata.Name, p.Metadata.Labels, p.Metadata.OwnerReferences)
+		c := candidates[workload]
+		if c == nil {
+			c = &sandboxCandidate{Workload: workload, Sandboxed: true}
+			candidates[workload] = c
+			order = append(order, workload)
+		}
+		c.Sandboxed = c.Sandboxed && p.Spec.RuntimeClassName == gvisor
+		if pool := nodePools[p.Spec.NodeName]; pool != "" {
+			c.NodePools = appendNew(c.NodePools, pool)
+		}
+		for _, s := range signals {
+			c.Signals = appendNew(c.Signals, s)
+		}
+		for _, r := range sandboxIncompatibilities(p) {
+			c.Incompatible = appendNew(c.Incompatible, r)
+		}
+	}
+
+	var result []sandboxCandidate
+	for _, key := range order {
+		c := candidates[key]
+		switch {
+		case c.Sandboxed:
+			c.Recommendation = "Already runs in GKE Sandbox."
+		case len(c.Incompatible) > 0:
+			c.Recommendation = "Can't run in GKE Sandbox as is; remove the incompatibilities, or isolate it on a dedicated node pool with its own namespace, network policies and service account."
+		case autopilot:
+			c.Recommendation = "Set runtimeClassName: gvisor in its pod template; Autopilot runs it in GKE Sandbox without a dedicated node pool."
+		case len(sandboxed) > 0:
+			c.Recommendation = fmt.Sprintf("Set runtimeClassName: gvisor in its pod template to run it in GKE Sandbox on node pool %s.", strings.Join(sandboxed, " or "))
+		default:
+			c.Recommendation = "Create a GKE Sandbox node pool with create_sandbox_node_pool, then set runtimeClassName: gvisor in its pod template."
+		}
+		result = append(result, *c)
+	}
+	slices.SortStableFunc(result, func(a, b sandboxCandidate) int {
+		rank := func(c sandboxCandidate) int {
+			switch {
+			case c.Sandboxed:
+				return 2
+			case len(c.Incompatible) > 0:
+				return 1
+			}
+			return 0
+		}
+		if d := rank(a) - rank(b); d != 0 {
+			return d
+		}
+		return strings.Compare(a.Workload, b.Workload)
+	})
+	return result
+}
+
+func sandboxFindings(candidates []sandboxCandidate) findingList {
+	list := findingList{Findings: []finding{}}
+	for _, c := range candidates {
+		if c.Sandboxed {
+			continue
+		}
+		priority := "P2"
+		if len(c.Incompatible) > 0 {
+			priority = "P3"
+		}
+		list.Findings = append(list.Findings, finding{
+			Name:        "gke-sandbox/" + c.Workload,
+			Description: strings.Join(c.Signals, " ") + " " + c.Recommendation,
+			Type:        "unsandboxed-untrusted-workload",
+			Category:    "SECURITY",
+			Priority:    priority,
+			Resource:    c.Workload,
+		})
+	}
+	return list
+}
+
+// sandboxPoolReport is a node pool and whether it runs or can run GKE
+// Sandbox.
+type sandboxPoolReport struct {
+	Name         string   `json:"name"`
+	Sandboxed    bool     `json:"sandboxed"`
+	Incompatible []string `json:"incompatible,omitempty"`
+}
+
+func (h *handlers) recommendGKESandbox(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var pods struct {
+		Items []sandboxPod `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/pods?fieldSelector=status.phase%3DRunning", &pods); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var namespaceList struct {
+		Items []struct {
+			Metadata struct {
+				Name   string            `json:"name"`
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/namespaces", &namespaceList); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespaces := map[string]map[string]string{}
+	for _, ns := range namespaceList.Items {
+		namespaces[ns.Metadata.Name] = ns.Metadata.Labels
+	}
+	var nodes struct {
+		Items []struct {
+			Metadata struct {
+				Name   string            `json:"name"`
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/nodes", &nodes); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	nodePools := map[string]string{}
+	for _, n := range nodes.Items {
+		nodePools[n.Metadata.Name] = n.Metadata.Labels[nodePoolLabel]
+	}
+
+	autopilot := cluster.GetAutopilot().GetEnabled()
+	sandboxed := sandboxedPools(cluster)
+	candidates := sandboxCandidates(pods.Items, namespaces, nodePools, autopilot, sandboxed)
+	pools := []sandboxPoolReport{}
+	if !autopilot {
+		for _, np := range cluster.GetNodePools() {
+			pools = append(pools, sandboxPoolReport{
+				Name:         np.GetName(),
+				Sandboxed:    slices.Contains(sandboxed, np.GetName()),
+				Incompatible: poolIncompatibilities(np),
+			})
+		}
+	}
+
+	var unsandboxed int
+	for _, c := range candidates {
+		if !c.Sandboxed {
+			unsandboxed++
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d workloads of cluster %s that look like they run untrusted or multi-tenant code, %d of which don't run in GKE Sandbox.", len(candidates), name, unsandboxed)
+	switch {
+	case autopilot:
+		b.WriteString(" The cluster runs Autopilot, which runs pods with runtimeClassName: gvisor in GKE Sandbox without a dedicated node pool.")
+	case len(sandboxed) == 0:
+		b.WriteString(" The cluster has no GKE Sandbox node pool yet.")
+	default:
+		fmt.Fprintf(&b, " The node pools %s run GKE Sandbox.", strings.Join(sandboxed, ", "))
+	}
+	b.WriteString(" Candidates are found from the names of namespaces, workloads and images, so review them before sandboxing; gVisor adds overhead to system calls and I/O.")
+	data, err := json.MarshalIndent(struct {
+		Workloads []sandboxCandidate  `json:"workloads"`
+		NodePools []sandboxPoolReport `json:"nodePools,omitempty"`
+	}{candidates, pools}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(b.String()+"\n\n"+string(data), structured.Findings, sandboxFindings(candidates))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) recommendGKESandboxCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud container clusters describe", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--format=yaml(autopilot,nodePools[].name,nodePools[].config.imageType,nodePools[].config.sandboxConfig,nodePools[].config.accelerators)"),
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get pods --all-namespaces --field-selector=status.phase=Running --output=yaml",
+		"kubectl get namespaces --show-labels",
+		"kubectl get nodes --label-columns=" + nodePoolLabel,
+	}
+}
+
+// Defaults of the GKE Sandbox node pools created.
+const (
+	defaultSandboxMachineType = "e2-standard-4"
+	defaultSandboxMaxNodes    = 10
+)
+
+// sandboxGuidance is how workloads run in GKE Sandbox.
+const sandboxGuidance = `Set the runtime class in the pod template of the workloads to sandbox; GKE adds the toleration of the sandbox nodes' taint and schedules the pods on them:
+
+` + "```yaml" + `
+spec:
+  template:
+    spec:
+      runtimeClassName: gvisor
+` + "```" + `
+
+Test the workloads in the sandbox first: gVisor doesn't support host namespaces, privileged containers or host paths, and adds overhead to system calls and I/O.`
+
+// sandboxNodePoolRequest builds the request creating a GKE Sandbox node pool.
+func sandboxNodePoolRequest(parent, name, machineType string, minNodes, maxNodes int32) *containerpb.CreateNodePoolRequest {
+	return &containerpb.CreateNodePoolRequest{
+		Parent: parent,
+		NodePool: &containerpb.NodePool{
+			Name: name,
+			Config: &containerpb.NodeConfig{
+				MachineType:   machineType,
+				ImageType:     "COS_CONTAINERD",
+				SandboxConfig: &containerpb.SandboxConfig{Type: containerpb.SandboxConfig_GVISOR},
+			},
+			InitialNodeCount: max(minNodes, 1),
+			Autoscaling:      &containerpb.NodePoolAutoscaling{Enabled: true, MinNodeCount: minNodes, MaxNodeCount: maxNodes},
+			Management:       &containerpb.NodeManagement{AutoUpgrade: true, AutoRepair: true},
+		},
+	}
+}
+
+func (h *handlers) createSandboxNodePool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	cluster := session.Cluster(ctx, request, h.c, "cluster")
+	if cluster == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	minNodes, maxNodes := request.GetInt("min_nodes", 0), request.GetInt("max_nodes", defaultSandboxMaxNodes)
+	if minNodes < 0 || maxNodes < 1 || minNodes > maxNodes {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid autoscaling limits %d to %d nodes", minNodes, maxNodes)), nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	parent := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, cluster)
+	c, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: parent})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if c.GetAutopilot().GetEnabled() {
+		return mcp.NewToolResultError(fmt.Sprintf("cluster %s runs Autopilot, which runs pods with runtimeClassName: gvisor in GKE Sandbox without a dedicated node pool", cluster)), nil
+	}
+
+	req := sandboxNodePoolRequest(parent, name, request.GetString("machine_type", defaultSandboxMachineType), int32(minNodes), int32(maxNodes))
+	if dryrun.Enabled(request, h.c) {
+		result := dryrun.Result("container.projects.locations.clusters.nodePools.create", req, h.createSandboxNodePoolCommands(ctx, request)...)
+		result.Content = append(result.Content, mcp.NewTextContent(sandboxGuidance))
+		return result, nil
+	}
+
+	op, err := cmClient.CreateNodePool(ctx, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opName := fmt.Sprintf("projects/%s/locations/%s/operations/%s", projectID, location, op.GetName())
+	poll := func(ctx context.Context, name string) (*containerpb.Operation, error) {
+		return cmClient.GetOperation(ctx, &containerpb.GetOperationRequest{Name: name})
+	}
+	cancel := func(ctx context.Context, name string) error {
+		return cmClient.CancelOperation(ctx, &containerpb.CancelOperationRequest{Name: name})
+	}
+	// The call may time out before the node pool is created, then it
+	// returns the operation to follow with get_operation.
+	latest, err := operations.Default.Wait(ctx, request, operations.Operation{Name: opName, Tool: "create_sandbox_node_pool", Target: cluster + "/" + name}, poll, cancel)
+	if latest == nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	switch {
+	case latest.GetStatus() != containerpb.Operation_DONE:
+		return mcp.NewToolResultText(fmt.Sprintf("Creating GKE Sandbox node pool %s in cluster %s. %s\n\n%s", name, cluster, operations.Describe(latest), sandboxGuidance)), nil
+	case latest.GetError() != nil || latest.GetStatusMessage() != "":
+		return mcp.NewToolResultError(operations.Describe(latest)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Created GKE Sandbox node pool %s in cluster %s.\n\n%s", name, cluster, sandboxGuidance)), nil
+}
+
+func (h *handlers) createSandboxNodePoolCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	name := request.GetString("name", "")
+	if projectID == "" || location == "" || cluster == "" || name == "" {
+		return nil
+	}
+	return []string{explain.Join("gcloud container node-pools create", name,
+		explain.Flag("cluster", cluster),
+		explain.Flag("location", location),
+		explain.Flag("project", projectID),
+		"--sandbox=type=gvisor",
+		"--image-type=COS_CONTAINERD",
+		explain.Flag("machine-type", request.GetString("machine_type", defaultSandboxMachineType)),
+		"--enable-autoscaling",
+		explain.Flag("min-nodes", strconv.Itoa(request.GetInt("min_nodes", 0))),
+		explain.Flag("max-nodes", strconv.Itoa(request.GetInt("max_nodes", defaultSandboxMaxNodes))),
+	)}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSandboxCandidates(t *testing.T) {
+	var pods []sandboxPod
+	if err := json.Unmarshal([]byte(`[
+		{"metadata":{"name":"runner-7d9f-x1","namespace":"ci","labels":{"pod-template-hash":"7d9f"},"ownerReferences":[{"kind":"ReplicaSet","name":"runner-7d9f"}]},
+			"spec":{"nodeName":"n1","containers":[{"name":"runner","image":"ghcr.io/actions/actions-runner:2.317.0"}]}},
+		{"metadata":{"name":"lab-0","namespace":"research","ownerReferences":[{"kind":"StatefulSet","name":"lab"}]},
+			"spec":{"runtimeClassName":"gvisor","nodeName":"n2","containers":[{"name":"lab","image":"jupyter/base-notebook"}]}},
+		{"metadata":{"name":"agent-abcde","namespace":"acme","ownerReferences":[{"kind":"DaemonSet","name":"agent"}]},
+			"spec":{"hostNetwork":true,"nodeName":"n1","volumes":[{"name":"logs","hostPath":{"path":"/var/log"}}],
+			"containers":[{"name":"agent","image":"acme/agent","securityContext":{"privileged":true}}]}},
+		{"metadata":{"name":"web-1","namespace":"shop"},"spec":{"nodeName":"n1","containers":[{"name":"web","image":"nginx"}]}},
+		{"metadata":{"name":"kaniko","namespace":"kube-system"},"spec":{"containers":[{"name":"kaniko","image":"gcr.io/kaniko-project/executor"}]}}
+	]`), &pods); err != nil {
+		t.Fatal(err)
+	}
+	namespaces := map[string]map[string]string{"acme": {"capsule.clastix.io/tenant": "acme"}}
+	nodePools := map[string]string{"n1": "default-pool", "n2": "sandbox"}
+
+	got := sandboxCandidates(pods, namespaces, nodePools, false, []string{"sandbox"})
+	want := []sandboxCandidate{
+		{
+			Workload:  "ci/Deployment/runner",
+			NodePools: []string{"default-pool"},
+			Signals: []string{
+				"Runs in namespace ci, whose name suggests tenants, notebooks or CI jobs.",
+				"Its name runner suggests tenants, notebooks or CI jobs.",
+				"Runs the image ghcr.io/actions/actions-runner:2.317.0, a CI runner, notebook or image builder that executes the code it's given.",
+			},
+			Recommendation: "Set runtimeClassName: gvisor in its pod template to run it in GKE Sandbox on node pool sandbox.",
+		},
+		{
+			Workload:  "acme/DaemonSet/agent",
+			NodePools: []string{"default-pool"},
+			Signals:   []string{"Its namespace is labeled capsule.clastix.io/tenant=acme, so the cluster is shared between tenants."},
+			Incompatible: []string{
+				"Uses the host network, PID or IPC namespace, which GKE Sandbox doesn't support.",
+				"Container agent is privileged, which GKE Sandbox doesn't support.",
+				"Mounts the host path /var/log, which gVisor doesn't share with the node.",
+			},
+			Recommendation: "Can't run in GKE Sandbox as is; remove the incompatibilities, or isolate it on a dedicated node pool with its own namespace, network policies and service account.",
+		},
+		{
+			Workload:       "research/StatefulSet/lab",
+			NodePools:      []string{"sandbox"},
+			Sandboxed:      true,
+			Signals:        []string{"Runs the image jupyter/base-notebook, a CI runner, notebook or image builder that executes the code it's given."},
+			Recommendation: "Already runs in GKE Sandbox.",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("sandboxCandidates() mismatch (-want +got):\n%s", diff)
+	}
+	if findings := sandboxFindings(got).Findings; len(findings) != 2 || findings[0].Priority != "P2" || findings[1].Priority != "P3" {
+		t.Errorf("sandboxFindings() = %+v, want a P2 and a P3 finding", findings)
+	}
+}
+
+func TestPoolIncompatibilities(t *testing.T) {
+	np := &containerpb.NodePool{Config: &containerpb.NodeConfig{
+		ImageType:    "UBUNTU_CONTAINERD",
+		Accelerators: []*containerpb.AcceleratorConfig{{AcceleratorType: "nvidia-l4", AcceleratorCount: 1}},
+	}}
+	if got := poolIncompatibilities(np); len(got) != 2 {
+		t.Errorf("poolIncompatibilities() = %q, want the image and the GPUs", got)
+	}
+	if got := poolIncompatibilities(&containerpb.NodePool{Config: &containerpb.NodeConfig{ImageType: "cos_containerd"}}); len(got) != 0 {
+		t.Errorf("poolIncompatibilities() = %q, want none", got)
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Confidences of an embedded secret, from the detector that found it.
+const (
+	// high is a value in the format of a known credential.
+	high = "high"
+	// medium is a random looking value under a name such as password.
+	medium = "medium"
+	// low is a long, random looking value under any name.
+	low = "low"
+)
+
+// maxSecrets is the number of embedded secrets listed.
+const maxSecrets = 100
+
+// secretPattern is the format of a known kind of credential.
+type secretPattern struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+var secretPatterns = []secretPattern{
+	{"private key", regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH|ENCRYPTED|PGP) )?PRIVATE KEY( BLOCK)?-----`)},
+	{"Google service account key", regexp.MustCompile(`"private_key_id"\s*:\s*"[0-9a-f]{40}"`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{"Google OAuth client secret", regexp.MustCompile(`\bGOCSPX-[0-9A-Za-z_\-]{28}\b`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[0-9A-Za-z]{36,}|github_pat_[0-9A-Za-z_]{50,})\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abprs]-[0-9A-Za-z\-]{10,}\b`)},
+	{"Stripe key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+	{"JSON web token", regexp.MustCompile(`\beyJ[0-9A-Za-z_\-]{10,}\.eyJ[0-9A-Za-z_\-]{10,}\.[0-9A-Za-z_\-]{10,}`)},
+	{"password in URL", regexp.MustCompile(`\b[a-z][a-z0-9+.\-]*://[^/\s:@$]+:[^/\s@$]{3,}@`)},
+}
+
+// sensitiveName matches names of values that are usually secret.
+var sensitiveName = regexp.MustCompile(`(?i)(passw(or)?d|passwd|pwd|secret|token|api_?key|access_?key|private_?key|credential|auth)`)
+
+// notSecretSuffixes end the names of values that only refer to secrets, or
+// configure them, like PASSWORD_FILE or TOKEN_URL.
+var notSecretSuffixes = []string{"name", "file", "path", "dir", "ref", "url", "uri", "endpoint", "host", "header", "enabled", "mode", "type", "length", "ttl", "expiry", "id"}
+
+// placeholders are values standing for a secret in examples.
+var placeholders = []string{"password", "secret", "changeme", "redacted", "none", "null", "true", "false", "example", "placeholder"}
+
+// assignment matches a line setting a value in a configuration file, as
+// in key=value, key: value or "key": "value".
+var assignment = regexp.MustCompile(`^\s*["']?([A-Za-z0-9_.\-]+)["']?\s*[:=]\s*["']?([^"'\s#,]+)`)
+
+// entropy returns the Shannon entropy of s, in bits per byte.
+func entropy(s string) float64 {
+	counts := map[byte]int{}
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	var h float64
+	for _, n := range counts {
+		p := float64(n) / float64(len(s))
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// words splits a name like dbPassword or DB_PASSWORD into lowercase words.
+func words(name string) []string {
+	var result []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			result = append(result, strings.ToLower(string(word)))
+			word = nil
+		}
+	}
+	for i, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(rune(name[i-1])):
+			flush()
+		}
+		word = append(word, r)
+	}
+	flush()
+	return result
+}
+
+// isSensitive tells whether a value named name usually holds a secret.
+func isSensitive(name string) bool {
+	w := words(name)
+	return sensitiveName.MatchString(name) && len(w) > 0 && !slices.Contains(notSecretSuffixes, w[len(w)-1])
+}
+
+// isPlaceholder tells whether value can't be a secret: empty, a variable
+// or template reference, a path, a URL without password, a number or an
+// example value.
+func isPlaceholder(value string) bool {
+	v := strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case len(v) < 8,
+		strings.Contains(v, "${"), strings.Contains(v, "$("), strings.Contains(v, "{{"),
+		strings.HasPrefix(v, "/"), strings.Contains(v, "://"),
+		slices.Contains(placeholders, v), strings.Trim(v, v[:1]) == "":
+		return true
+	}
+	return strings.IndexFunc(v, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' && r != '-' }) < 0
+}
+
+// tokenChars matches values made of the characters of tokens and keys.
+var tokenChars = regexp.MustCompile(`^[A-Za-z0-9+/=_\-]{24,}$`)
+
+// detection is a secret detected in a value.
+type detection struct {
+	Detector   string
+	Confidence string
+	// Line is the line of the value the secret is on, from 1, or 0 for a
+	// single line value.
+	Line int
+}
+
+// detect returns the secrets found in a value named name, without its
+// value. Values with several lines, like configuration files, are also
+// checked line by line for secret settings.
+func detect(name, value string) []detection {
+	var found []detection
+	lines := strings.Split(value, "\n")
+	line := func(offset int) int {
+		if len(lines) == 1 {
+			return 0
+		}
+		return strings.Count(value[:offset], "\n") + 1
+	}
+	for _, p := range secretPatterns {
+		if loc := p.Pattern.FindStringIndex(value); loc != nil {
+			found = append(found, detection{Detector: p.Name, Confidence: high, Line: line(loc[0])})
+		}
+	}
+	if len(found) > 0 {
+		return found
+	}
+	if len(lines) == 1 {
+		switch v := strings.TrimSpace(value); {
+		case isSensitive(name) && !isPlaceholder(v) && entropy(v) >= 2.5:
+			found = append(found, detection{Detector: "secret value of " + name, Confidence: medium})
+		case tokenChars.MatchString(v) && strings.ContainsAny(v, "0123456789") && entropy(v) >= 4.5:
+			found = append(found, detection{Detector: "random looking value", Confidence: low})
+		}
+		return found
+	}
+	for i, l := range lines {
+		m := assignment.FindStringSubmatch(l)
+		if m != nil && isSensitive(m[1]) && !isPlaceholder(m[2]) && entropy(m[2]) >= 2.5 {
+			found = append(found, detection{Detector: "secret value of " + m[1], Confidence: medium, Line: i + 1})
+		}
+	}
+	return found
+}
+
+// embeddedSecret is where a secret was found, without its value.
+type embeddedSecret struct {
+	// Object is the ConfigMap or workload, as namespace/Kind/name.
+	Object     string `json:"object"`
+	Field      string `json:"field"`
+	Line       int    `json:"line,omitempty"`
+	Detector   string `json:"detector"`
+	Confidence string `json:"confidence"`
+}
+
+// scanner collects the secrets found in a cluster, once per location.
+type scanner struct {
+	secrets []embeddedSecret
+	seen    map[string]bool
+}
+
+func (s *scanner) scan(object, field, name, value string) {
+	for _, d := range detect(name, value) {
+		e := embeddedSecret{Object: object, Field: field, Line: d.Line, Detector: d.Detector, Confidence: d.Confidence}
+		key := fmt.Sprintf("%s|%s|%d|%s", e.Object, e.Field, e.Line, e.Detector)
+		if s.seen[key] {
+			continue
+		}
+		if s.seen == nil {
+			s.seen = map[string]bool{}
+		}
+		s.seen[key] = true
+		s.secrets = append(s.secrets, e)
+	}
+}
+
+// lastApplied is the annotation kubectl copies the applied object to,
+// which would report the secrets of the object a second time.
+const lastApplied = "kubectl.kubernetes.io/last-applied-configuration"
+
+func (s *scanner) scanAnnotations(object string, annotations map[string]string) {
+	for k, v := range annotations {
+		if k != lastApplied {
+			s.scan(object, "annotation "+k, k, v)
+		}
+	}
+}
+
+// configMap is the part of a ConfigMap scanned.
+type configMap struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Data map[string]string `json:"data"`
+}
+
+func (s *scanner) scanConfigMap(cm configMap) {
+	object := cm.Metadata.Namespace + "/ConfigMap/" + cm.Metadata.Name
+	for k, v := range cm.Data {
+		s.scan(object, "data "+k, k, v)
+	}
+	s.scanAnnotations(object, cm.Metadata.Annotations)
+}
+
+// scannedPod is the part of a pod scanned.
+type scannedPod struct {
+	Metadata struct {
+		Name            string                `json:"name"`
+		Namespace       string                `json:"namespace"`
+		Labels          map[string]string     `json:"labels"`
+		Annotations     map[string]string     `json:"annotations"`
+		OwnerReferences []kube.OwnerReference `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		InitContainers []scannedContainer `json:"initContainers"`
+		Containers     []scannedContainer `json:"containers"`
+	} `json:"spec"`
+}
+
+type scannedContainer struct {
+	Name string `json:"name"`
+	Env  []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"env"`
+}
+
+// scanPod scans the pod once for all the pods of its workload.
+func (s *scanner) scanPod(p scannedPod) {
+	m := p.Metadata
+	object := kube.Workload(m.Namespace, m.Name, m.Labels, m.OwnerReferences)
+	for _, c := range slices.Concat(p.Spec.InitContainers, p.Spec.Containers) {
+		for _, e := range c.Env {
+			s.scan(object, fmt.Sprintf("container %s env %s", c.Name, e.Name), e.Name, e.Value)
+		}
+	}
+	s.scanAnnotations(object, m.Annotations)
+}
+
+// sorted returns the secrets from the most confident.
+func (s *scanner) sorted() []embeddedSecret {
+	rank := map[string]int{high: 0, medium: 1, low: 2}
+	sort.SliceStable(s.secrets, func(i, j int) bool {
+		a, b := s.secrets[i], s.secrets[j]
+		if a.Confidence != b.Confidence {
+			return rank[a.Confidence] < rank[b.Confidence]
+		}
+		if a.Object != b.Object {
+			return a.Object < b.Object
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Line < b.Line
+	})
+	return s.secrets
+}
+
+// managedNamespace tells whether GKE manages the namespace.
+func managedNamespace(ns string) bool {
+	return ns == "kube-system" || strings.HasPrefix(ns, "gke-") || strings.HasPrefix(ns, "gmp-")
+}
+
+func secretFindings(secrets []embeddedSecret) findingList {
+	priorities := map[string]string{high: "P1", medium: "P2", low: "P3"}
+	list := findingList{Findings: []finding{}}
+	for _, e := range secrets {
+		location := e.Field
+		if e.Line > 0 {
+			location += fmt.Sprintf(" line %d", e.Line)
+		}
+		list.Findings = append(list.Findings, finding{
+			Name:        "embedded-secret/" + e.Object + "/" + location,
+			Description: fmt.Sprintf("%s in %s of %s (%s confidence)", e.Detector, location, e.Object, e.Confidence),
+			Type:        e.Detector,
+			Category:    "SECURITY",
+			Priority:    priorities[e.Confidence],
+			Resource:    e.Object,
+		})
+	}
+	return list
+}
+
+func (h *handlers) scanEmbeddedSecrets(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+	namespace := request.GetString("namespace", "")
+	includeManaged := request.GetBool("include_managed_namespaces", false)
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	configMapsPath, podsPath := "/api/v1/configmaps", "/api/v1/pods"
+	if namespace != "" {
+		configMapsPath = "/api/v1/namespaces/" + url.PathEscape(namespace) + "/configmaps"
+		podsPath = "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods"
+	}
+	var configMaps struct {
+		Items []configMap `json:"items"`
+	}
+	if err := k.Get(ctx, configMapsPath, &configMaps); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var pods struct {
+		Items []scannedPod `json:"items"`
+	}
+	if err := k.Get(ctx, podsPath, &pods); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var s scanner
+	var scannedConfigMaps, scannedPods int
+	for _, cm := range configMaps.Items {
+		if includeManaged || namespace != "" || !managedNamespace(cm.Metadata.Namespace) {
+			s.scanConfigMap(cm)
+			scannedConfigMaps++
+		}
+	}
+	for _, p := range pods.Items {
+		if includeManaged || namespace != "" || !managedNamespace(p.Metadata.Namespace) {
+			s.scanPod(p)
+			scannedPods++
+		}
+	}
+	secrets := s.sorted()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Scanned %d ConfigMaps and the environment variables and annotations of %d pods of cluster %s: found %d embedded credentials. Their values aren't shown.", scannedConfigMaps, scannedPods, name, len(secrets))
+	if len(secrets) > 0 {
+		b.WriteString(" Move them to Secrets, or to Secret Manager, and rotate them, since anyone who can read these objects has seen them.")
+	}
+	listed := secrets
+	if len(listed) > maxSecrets {
+		listed = listed[:maxSecrets]
+		fmt.Fprintf(&b, " Only the first %d are listed.", maxSecrets)
+	}
+	data, err := json.MarshalIndent(listed, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(b.String()+"\n\n"+string(data), structured.Findings, secretFindings(listed))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) scanEmbeddedSecretsCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	scope := "--all-namespaces"
+	if namespace := request.GetString("namespace", ""); namespace != "" {
+		scope = explain.Flag("namespace", namespace)
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		explain.Join("kubectl get configmaps", scope, "--output=yaml"),
+		explain.Join("kubectl get pods", scope, "--output=yaml"),
+	}
+}
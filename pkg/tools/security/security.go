@@ -0,0 +1,155 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/paging"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("security", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	listSecurityFindingsTool := mcp.NewTool("list_security_findings",
+		mcp.WithDescription("List the Security Command Center findings about the GKE clusters of a project and their workloads, such as Container Threat Detection threats, misconfigurations and vulnerabilities, most severe first, with the affected pods. Only active, unmuted findings are listed unless asked otherwise."),
+		catalog.Describe(catalog.Security, catalog.Query, "securitycenter.findings.list"),
+		explain.Command(h.listSecurityFindingsCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("Only list the findings of this GKE cluster.")),
+		mcp.WithString("min_severity", mcp.Enum(severities...), mcp.Description("Lowest severity to list. Defaults to all severities.")),
+		mcp.WithBoolean("threats_only", mcp.Description("Only list threats, such as Container Threat Detection findings, and not misconfigurations or vulnerabilities.")),
+		mcp.WithBoolean("include_inactive", mcp.Description("Also list inactive findings.")),
+		mcp.WithBoolean("include_muted", mcp.Description("Also list muted findings.")),
+		paging.CursorOption(),
+	)
+	s.AddTool(listSecurityFindingsTool, h.listSecurityFindings)
+
+	updateSecurityFindingTool := mcp.NewTool("update_security_finding",
+		mcp.WithDescription("Mute or unmute a Security Command Center finding, or acknowledge it by marking it inactive, which hides it from everyone's default views. Always do a dry run first and ask the user to confirm before updating a finding."),
+		catalog.Describe(catalog.Security, catalog.Dismiss, "securitycenter.findings.setMute", "securitycenter.findings.setState"),
+		explain.Command(h.updateSecurityFindingCommands),
+		mcp.WithString("finding", mcp.Required(), mcp.Description("Resource name of the finding, like projects/my-project/sources/123/findings/abc, as listed by list_security_findings.")),
+		mcp.WithString("action", mcp.Required(), mcp.Enum(actions...), mcp.Description("mute hides the finding, unmute shows it again and acknowledge marks it inactive.")),
+		dryrun.Argument(c),
+	)
+	s.AddTool(updateSecurityFindingTool, h.updateSecurityFinding)
+
+	scanEmbeddedSecretsTool := mcp.NewTool("scan_embedded_secrets",
+		mcp.WithDescription("Scan the ConfigMaps of a cluster, and the environment variables and annotations of its pods, for embedded credentials such as private keys, API keys, tokens and passwords, using the formats of known credentials and the entropy of values under names like password. Reports where each one is, with the confidence of the detection, without ever returning the values."),
+		catalog.Describe(catalog.Security, catalog.Read, "container.configMaps.list", "container.pods.list"),
+		explain.Command(h.scanEmbeddedSecretsCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("namespace", mcp.Description("Namespace to scan. Defaults to all namespaces.")),
+		mcp.WithBoolean("include_managed_namespaces", mcp.Description("Also scan the namespaces GKE manages, such as kube-system, when scanning all namespaces.")),
+	)
+	s.AddTool(scanEmbeddedSecretsTool, h.scanEmbeddedSecrets)
+
+	auditServiceAccountKeysTool := mcp.NewTool("audit_service_account_keys",
+		mcp.WithDescription("Find the exported service account keys the workloads of a cluster mount from Secrets, as files or variables, instead of using Workload Identity. Reports each key's age and when it was last used according to IAM, and a plan per workload to move it to Workload Identity while keeping the same service account. Reads the Secrets the workloads use, but only returns the identity of the keys in them."),
+		catalog.Describe(catalog.Security, catalog.Read, "container.clusters.get", "container.pods.list", "container.secrets.get", "iam.serviceAccountKeys.get", "policyanalyzer.serviceAccountKeyLastAuthenticationActivities.query"),
+		explain.Command(h.auditServiceAccountKeysCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+	)
+	s.AddTool(auditServiceAccountKeysTool, h.auditServiceAccountKeys)
+
+	checkWorkloadIdentityTool := mcp.NewTool("check_workload_identity",
+		mcp.WithDescription("Check the links between the Kubernetes service accounts of a cluster and the IAM service accounts they impersonate with Workload Identity: annotations naming missing, disabled or malformed service accounts, missing workloadIdentityUser bindings, bindings for another workload pool, bindings whose Kubernetes service account lacks the annotation, and pods that still use the node's service account. These break silently until a workload first calls Google Cloud. Reports each issue with the command fixing it."),
+		catalog.Describe(catalog.Security, catalog.Read, "container.clusters.get", "container.serviceAccounts.list", "container.pods.list", "container.nodes.list", "iam.serviceAccounts.list", "iam.serviceAccounts.get", "iam.serviceAccounts.getIamPolicy"),
+		explain.Command(h.checkWorkloadIdentityCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+	)
+	s.AddTool(checkWorkloadIdentityTool, h.checkWorkloadIdentity)
+
+	publicExposureReportTool := mcp.NewTool("public_exposure_report",
+		mcp.WithDescription("Report how the GKE clusters of a project are reachable from the internet: public control plane endpoints and their authorized networks, nodes with external IPs and the firewall rules open to them, external LoadBalancer Services accepting any source, and node ports the firewall opens to the internet. Ranks the exposures from critical, such as databases or SSH open to the internet, to low, with how to close each one."),
+		catalog.Describe(catalog.Security, catalog.Read, "container.clusters.list", "container.nodes.list", "container.services.list", "compute.firewalls.list"),
+		explain.Command(h.publicExposureReportCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE location to look in. Defaults to all locations.")),
+		mcp.WithString("cluster", mcp.Description("Only check this GKE cluster. Defaults to all clusters.")),
+	)
+	s.AddTool(publicExposureReportTool, h.publicExposureReport)
+
+	recommendGKESandboxTool := mcp.NewTool("recommend_gke_sandbox",
+		mcp.WithDescription("Find the workloads of a cluster that look like they run untrusted or multi-tenant code, such as CI runners, notebooks, image builders and tenant namespaces, and recommend running them in GKE Sandbox (gVisor). Checks what keeps each workload and node pool from running in the sandbox, such as GPUs, host namespaces, privileged containers and host paths, before recommending it."),
+		catalog.Describe(catalog.Security, catalog.Read, "container.clusters.get", "container.pods.list", "container.namespaces.list", "container.nodes.list"),
+		explain.Command(h.recommendGKESandboxCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+	)
+	s.AddTool(recommendGKESandboxTool, h.recommendGKESandbox)
+
+	createSandboxNodePoolTool := mcp.NewTool("create_sandbox_node_pool",
+		mcp.WithDescription("Create an autoscaled GKE Sandbox (gVisor) node pool in a GKE Standard cluster, on which pods with runtimeClassName: gvisor run isolated from the node's kernel, and return how to move workloads to it. Always do a dry run first and ask the user to confirm before creating the node pool."),
+		catalog.Describe(catalog.Security, catalog.Write, "container.clusters.get", "container.clusters.update", "container.operations.get"),
+		explain.Command(h.createSandboxNodePoolCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the node pool to create.")),
+		mcp.WithString("machine_type", mcp.Description(fmt.Sprintf("Machine type of the nodes. Defaults to %s.", defaultSandboxMachineType))),
+		mcp.WithNumber("min_nodes", mcp.Description("Minimum number of nodes per zone the autoscaler keeps. Defaults to 0.")),
+		mcp.WithNumber("max_nodes", mcp.Description(fmt.Sprintf("Maximum number of nodes per zone the autoscaler adds. Defaults to %d.", defaultSandboxMaxNodes))),
+		dryrun.Argument(c),
+	)
+	s.AddTool(createSandboxNodePoolTool, h.createSandboxNodePool)
+
+	generateNetworkPoliciesTool := mcp.NewTool("generate_network_policies",
+		mcp.WithDescription("Generate candidate NetworkPolicies for a namespace from the flows observed in VPC flow logs or Dataplane V2 network policy logs: a default-deny policy, DNS lookups, and per workload the ingress and egress seen. Returns the policies as YAML with an impact analysis of what each workload keeps and what to check before moving to default-deny. Nothing is applied."),
+		catalog.Describe(catalog.Security, catalog.Query, "logging.logEntries.list", "container.clusters.get", "container.pods.list", "container.networkPolicies.list"),
+		explain.Command(h.generateNetworkPoliciesCommands),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace to generate the policies for.")),
+		mcp.WithString("source", mcp.Enum(flowSources...), mcp.Description("Flows to learn from: vpc_flow_logs, the sampled flows of the subnet, or dataplane_v2, the network policy logs of Dataplane V2, which only log the connections policies already apply to. Defaults to vpc_flow_logs.")),
+		mcp.WithNumber("hours", mcp.Description(fmt.Sprintf("Number of hours of flows to learn from. Defaults to %d.", defaultPolicyHours))),
+	)
+	s.AddTool(generateNetworkPoliciesTool, h.generateNetworkPolicies)
+
+	return nil
+}
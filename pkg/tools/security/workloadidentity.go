@@ -0,0 +1,438 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/scan"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/api/googleapi"
+	iam "google.golang.org/api/iam/v1"
+)
+
+// gsaAnnotation is the annotation of a Kubernetes service account naming
+// the IAM service account it impersonates.
+const gsaAnnotation = "iam.gke.io/gcp-service-account"
+
+// workloadIdentityUser is the role letting Kubernetes service accounts
+// impersonate an IAM service account.
+const workloadIdentityUser = "roles/iam.workloadIdentityUser"
+
+// Severities of Workload Identity issues.
+const (
+	// broken means workloads fail to authenticate, or will.
+	broken = "broken"
+	// risky means workloads run with more access than they should.
+	risky = "risky"
+	// stale means a leftover with no effect.
+	stale = "stale"
+)
+
+// wiIssue is a Workload Identity misconfiguration.
+type wiIssue struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	// Subject is the Kubernetes or IAM service account, node pool or
+	// workload the issue is about.
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// poolMember matches the members of workloadIdentityUser bindings naming a
+// Kubernetes service account, as serviceAccount:POOL[NAMESPACE/NAME].
+var poolMember = regexp.MustCompile(`^serviceAccount:([^\[]+)\[([^/\]]+)/([^\]]+)\]$`)
+
+// iamServiceAccount is what the checks need of an IAM service account.
+type iamServiceAccount struct {
+	Missing  bool
+	Disabled bool
+	// Members are the members allowed to impersonate it with Workload
+	// Identity.
+	Members []string
+	// Err is set when the service account couldn't be read.
+	Err error
+}
+
+// kubeServiceAccount is the part of a Kubernetes service account checked.
+type kubeServiceAccount struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+}
+
+func (k kubeServiceAccount) String() string {
+	return k.Metadata.Namespace + "/" + k.Metadata.Name
+}
+
+// validEmail matches the emails of IAM service accounts.
+var validEmail = regexp.MustCompile(`^[a-z0-9\-]+@[a-z0-9\-.]+\.gserviceaccount\.com$`)
+
+// checkBindings checks the links between the Kubernetes service accounts
+// of a cluster using pool and the IAM service accounts, by email: each
+// annotation must name an existing, enabled service account that lets the
+// Kubernetes service account impersonate it, and each such binding needs
+// the annotation.
+func checkBindings(pool string, ksas []kubeServiceAccount, gsas map[string]iamServiceAccount) []wiIssue {
+	var issues []wiIssue
+	byName := map[string]kubeServiceAccount{}
+	for _, ksa := range ksas {
+		byName[ksa.String()] = ksa
+		email, ok := ksa.Metadata.Annotations[gsaAnnotation]
+		if !ok {
+			continue
+		}
+		ns, name := ksa.Metadata.Namespace, ksa.Metadata.Name
+		member := fmt.Sprintf("serviceAccount:%s[%s/%s]", pool, ns, name)
+		bind := explain.Join("gcloud iam service-accounts add-iam-policy-binding", email, "--role="+workloadIdentityUser, "--member="+member, explain.Flag("project", keyProject(email)))
+		gsa, known := gsas[email]
+		switch {
+		case !validEmail.MatchString(email):
+			issues = append(issues, wiIssue{Severity: broken, Check: "malformed-annotation", Subject: ksa.String(),
+				Message: fmt.Sprintf("The %s annotation %q isn't the email of an IAM service account.", gsaAnnotation, email),
+				Fix:     explain.Join("kubectl annotate serviceaccount", name, gsaAnnotation+"=EMAIL", "--overwrite", explain.Flag("namespace", ns))})
+		case !known || gsa.Err != nil:
+		case gsa.Missing:
+			issues = append(issues, wiIssue{Severity: broken, Check: "missing-service-account", Subject: ksa.String(),
+				Message: fmt.Sprintf("Is annotated with %s, which doesn't exist.", email),
+				Fix:     "Create the service account, or correct the annotation."})
+		case gsa.Disabled:
+			issues = append(issues, wiIssue{Severity: broken, Check: "disabled-service-account", Subject: ksa.String(),
+				Message: fmt.Sprintf("Is annotated with %s, which is disabled.", email),
+				Fix:     explain.Join("gcloud iam service-accounts enable", email, explain.Flag("project", keyProject(email)))})
+		case slices.Contains(gsa.Members, member):
+		default:
+			var pools []string
+			for _, m := range gsa.Members {
+				if p := poolMember.FindStringSubmatch(m); p != nil && p[2] == ns && p[3] == name {
+					pools = append(pools, p[1])
+				}
+			}
+			if len(pools) > 0 {
+				issues = append(issues, wiIssue{Severity: broken, Check: "wrong-pool", Subject: ksa.String(),
+					Message: fmt.Sprintf("%s lets it impersonate it from the workload pool %s, but the cluster's pool is %s, so tokens have the wrong audience.", email, strings.Join(pools, ", "), pool),
+					Fix:     bind})
+				continue
+			}
+			issues = append(issues, wiIssue{Severity: broken, Check: "missing-iam-binding", Subject: ksa.String(),
+				Message: fmt.Sprintf("Is annotated with %s, which doesn't grant it %s, so its pods fail to get tokens.", email, workloadIdentityUser),
+				Fix:     bind})
+		}
+	}
+
+	for _, email := range slices.Sorted(maps.Keys(gsas)) {
+		for _, m := range gsas[email].Members {
+			p := poolMember.FindStringSubmatch(m)
+			if p == nil || p[1] != pool {
+				continue
+			}
+			subject := p[2] + "/" + p[3]
+			ksa, ok := byName[subject]
+			switch {
+			case !ok:
+				issues = append(issues, wiIssue{Severity: stale, Check: "stale-binding", Subject: email,
+					Message: fmt.Sprintf("Lets the Kubernetes service account %s impersonate it, which doesn't exist in this cluster. Other clusters of the project share the pool, so check them before removing it.", subject),
+					Fix:     explain.Join("gcloud iam service-accounts remove-iam-policy-binding", email, "--role="+workloadIdentityUser, "--member="+m, explain.Flag("project", keyProject(email)))})
+			case ksa.Metadata.Annotations[gsaAnnotation] == "":
+				issues = append(issues, wiIssue{Severity: broken, Check: "missing-annotation", Subject: subject,
+					Message: fmt.Sprintf("%s lets it impersonate it, but it lacks the %s annotation, so its pods authenticate as the Kubernetes service account instead.", email, gsaAnnotation),
+					Fix:     explain.Join("kubectl annotate serviceaccount", p[3], gsaAnnotation+"="+email, explain.Flag("namespace", p[2]))})
+			}
+		}
+	}
+	return issues
+}
+
+// wiPod is the part of a pod checked.
+type wiPod struct {
+	Metadata struct {
+		Name            string                `json:"name"`
+		Namespace       string                `json:"namespace"`
+		Labels          map[string]string     `json:"labels"`
+		OwnerReferences []kube.OwnerReference `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName    string `json:"nodeName"`
+		HostNetwork bool   `json:"hostNetwork"`
+	} `json:"spec"`
+}
+
+// nodePoolLabel is the node label naming its node pool.
+const nodePoolLabel = "cloud.google.com/gke-nodepool"
+
+// checkNodes checks that the node pools run the GKE metadata server, and
+// finds the workloads that get the node's service account anyway: the
+// ones on the other node pools, and the ones on the host network.
+func checkNodes(projectID, location, cluster string, wi workloadIdentity, pods []wiPod, nodePools map[string]string) []wiIssue {
+	var issues []wiIssue
+	if wi.Pool == "" {
+		issues = append(issues, wiIssue{Severity: risky, Check: "workload-identity-disabled", Subject: cluster,
+			Message: "Workload Identity is not enabled, so every pod can use the service account of its node.",
+			Fix:     explain.Join("gcloud container clusters update", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--workload-pool="+projectID+".svc.id.goog")})
+	}
+	for _, np := range wi.NodePools {
+		if wi.Pool == "" {
+			break
+		}
+		issues = append(issues, wiIssue{Severity: risky, Check: "node-metadata", Subject: "node pool " + np,
+			Message: "Doesn't run the GKE metadata server, so its pods use the service account of the node instead of Workload Identity.",
+			Fix:     explain.Join("gcloud container node-pools update", np, explain.Flag("cluster", cluster), explain.Flag("location", location), explain.Flag("project", projectID), "--workload-metadata=GKE_METADATA")})
+	}
+	nodeSA := map[string][]string{}
+	for _, p := range pods {
+		m := p.Metadata
+		if managedNamespace(m.Namespace) || p.Spec.NodeName == "" {
+			continue
+		}
+		workload := kube.Workload(m.Namespace, m.Name, m.Labels, m.OwnerReferences)
+		var reason string
+		switch pool := nodePools[p.Spec.NodeName]; {
+		case wi.Pool == "":
+			reason = "Workload Identity is disabled"
+		case slices.Contains(wi.NodePools, pool):
+			reason = "it runs on node pool " + pool + " without the GKE metadata server"
+		case p.Spec.HostNetwork:
+			reason = "it uses the host network, which bypasses the GKE metadata server"
+		default:
+			continue
+		}
+		if !slices.Contains(nodeSA[workload], reason) {
+			nodeSA[workload] = append(nodeSA[workload], reason)
+		}
+	}
+	for _, workload := range slices.Sorted(maps.Keys(nodeSA)) {
+		issues = append(issues, wiIssue{Severity: risky, Check: "node-service-account", Subject: workload,
+			Message: "Uses the service account of its node, since " + strings.Join(nodeSA[workload], " and ") + "."})
+	}
+	return issues
+}
+
+// sortIssues sorts broken links first, then risks, then leftovers.
+func sortIssues(issues []wiIssue) {
+	rank := map[string]int{broken: 0, risky: 1, stale: 2}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return rank[issues[i].Severity] < rank[issues[j].Severity]
+	})
+}
+
+func wiFindings(issues []wiIssue) findingList {
+	priorities := map[string]string{broken: "P1", risky: "P2", stale: "P4"}
+	list := findingList{Findings: []finding{}}
+	for _, i := range issues {
+		list.Findings = append(list.Findings, finding{
+			Name:        "workload-identity/" + i.Check + "/" + i.Subject,
+			Description: i.Message,
+			Type:        i.Check,
+			Category:    "SECURITY",
+			Priority:    priorities[i.Severity],
+			Resource:    i.Subject,
+		})
+	}
+	return list
+}
+
+// readServiceAccounts reads the IAM service accounts of the project and
+// the ones the annotations name, with who can impersonate them.
+func (h *handlers) readServiceAccounts(ctx context.Context, projectID string, ksas []kubeServiceAccount) (map[string]iamServiceAccount, error) {
+	opts, err := auth.ClientOptions(ctx, h.c, config.APIIAM)
+	if err != nil {
+		return nil, err
+	}
+	svc, err := iam.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var emails []string
+	err = svc.Projects.ServiceAccounts.List("projects/"+projectID).Pages(ctx, func(resp *iam.ListServiceAccountsResponse) error {
+		for _, sa := range resp.Accounts {
+			emails = append(emails, sa.Email)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, ksa := range ksas {
+		if email := ksa.Metadata.Annotations[gsaAnnotation]; validEmail.MatchString(email) && !slices.Contains(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+
+	results := scan.Run(ctx, emails, scan.DefaultWorkers, func(ctx context.Context, email string) (iamServiceAccount, error) {
+		var gsa iamServiceAccount
+		name := "projects/-/serviceAccounts/" + email
+		sa, err := svc.Projects.ServiceAccounts.Get(name).Context(ctx).Do()
+		if err != nil {
+			var apiErr *googleapi.Error
+			if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+				gsa.Missing = true
+				return gsa, nil
+			}
+			return gsa, err
+		}
+		gsa.Disabled = sa.Disabled
+		policy, err := svc.Projects.ServiceAccounts.GetIamPolicy(name).Context(ctx).Do()
+		if err != nil {
+			return gsa, err
+		}
+		for _, b := range policy.Bindings {
+			if b.Role == workloadIdentityUser {
+				gsa.Members = append(gsa.Members, b.Members...)
+			}
+		}
+		return gsa, nil
+	})
+	gsas := map[string]iamServiceAccount{}
+	for _, r := range results {
+		r.Value.Err = r.Err
+		gsas[r.Target] = r.Value
+	}
+	return gsas, nil
+}
+
+func (h *handlers) checkWorkloadIdentity(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+
+	cmClient, err := gcp.ClusterManager(ctx, h.c)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := cmClient.GetCluster(ctx, &containerpb.GetClusterRequest{Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	wi := clusterWorkloadIdentity(cluster)
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var ksas struct {
+		Items []kubeServiceAccount `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/serviceaccounts", &ksas); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var pods struct {
+		Items []wiPod `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/pods?fieldSelector=status.phase%3DRunning", &pods); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var nodes struct {
+		Items []struct {
+			Metadata struct {
+				Name   string            `json:"name"`
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/nodes", &nodes); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	nodePools := map[string]string{}
+	for _, n := range nodes.Items {
+		nodePools[n.Metadata.Name] = n.Metadata.Labels[nodePoolLabel]
+	}
+
+	issues := checkNodes(projectID, location, name, wi, pods.Items, nodePools)
+	var unchecked []string
+	if wi.Pool != "" {
+		gsas, err := h.readServiceAccounts(ctx, projectID, ksas.Items)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read the IAM service accounts: %v", err)), nil
+		}
+		issues = append(issues, checkBindings(wi.Pool, ksas.Items, gsas)...)
+		for email, gsa := range gsas {
+			if gsa.Err != nil {
+				unchecked = append(unchecked, fmt.Sprintf("%s: %v", email, gsa.Err))
+			}
+		}
+		slices.Sort(unchecked)
+	}
+	sortIssues(issues)
+
+	var annotated int
+	for _, ksa := range ksas.Items {
+		if ksa.Metadata.Annotations[gsaAnnotation] != "" {
+			annotated++
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Checked the Workload Identity setup of cluster %s", name)
+	if wi.Pool != "" {
+		fmt.Fprintf(&b, " (pool %s)", wi.Pool)
+	}
+	fmt.Fprintf(&b, ", %d Kubernetes service accounts of which %d are annotated, and %d running pods: found %d issues.", len(ksas.Items), annotated, len(pods.Items), len(issues))
+	if len(unchecked) > 0 {
+		fmt.Fprintf(&b, " These IAM service accounts couldn't be checked: %s.", strings.Join(unchecked, "; "))
+	}
+	data, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(b.String()+"\n\n"+string(data), structured.Findings, wiFindings(issues))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+func (h *handlers) checkWorkloadIdentityCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.Join("gcloud container clusters describe", cluster, explain.Flag("location", location), explain.Flag("project", projectID), "--format=yaml(workloadIdentityConfig,nodePools[].name,nodePools[].config.workloadMetadataConfig)"),
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get serviceaccounts --all-namespaces --output=yaml",
+		"kubectl get pods --all-namespaces --field-selector=status.phase=Running --output=wide",
+		"kubectl get nodes --label-columns=" + nodePoolLabel,
+		explain.Join("gcloud iam service-accounts list", explain.Flag("project", projectID)),
+		"gcloud iam service-accounts get-iam-policy SERVICE_ACCOUNT",
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func ksa(namespace, name, gsa string) kubeServiceAccount {
+	var k kubeServiceAccount
+	k.Metadata.Namespace, k.Metadata.Name = namespace, name
+	if gsa != "" {
+		k.Metadata.Annotations = map[string]string{gsaAnnotation: gsa}
+	}
+	return k
+}
+
+func TestCheckBindings(t *testing.T) {
+	const pool = "p.svc.id.goog"
+	ksas := []kubeServiceAccount{
+		ksa("shop", "ok", "ok@p.iam.gserviceaccount.com"),
+		ksa("shop", "unbound", "unbound@p.iam.gserviceaccount.com"),
+		ksa("shop", "moved", "moved@p.iam.gserviceaccount.com"),
+		ksa("shop", "gone", "gone@p.iam.gserviceaccount.com"),
+		ksa("shop", "off", "off@p.iam.gserviceaccount.com"),
+		ksa("shop", "typo", "ok@p.iam"),
+		ksa("shop", "unannotated", ""),
+		ksa("shop", "unreadable", "secret@other.iam.gserviceaccount.com"),
+	}
+	gsas := map[string]iamServiceAccount{
+		"ok@p.iam.gserviceaccount.com":         {Members: []string{"serviceAccount:p.svc.id.goog[shop/ok]", "serviceAccount:p.svc.id.goog[shop/unannotated]", "serviceAccount:p.svc.id.goog[old/job]"}},
+		"unbound@p.iam.gserviceaccount.com":    {},
+		"moved@p.iam.gserviceaccount.com":      {Members: []string{"serviceAccount:old.svc.id.goog[shop/moved]"}},
+		"gone@p.iam.gserviceaccount.com":       {Missing: true},
+		"off@p.iam.gserviceaccount.com":        {Disabled: true, Members: []string{"serviceAccount:p.svc.id.goog[shop/off]"}},
+		"secret@other.iam.gserviceaccount.com": {Err: errors.New("permission denied")},
+	}
+	want := []wiIssue{
+		{Severity: broken, Check: "missing-iam-binding", Subject: "shop/unbound"},
+		{Severity: broken, Check: "wrong-pool", Subject: "shop/moved"},
+		{Severity: broken, Check: "missing-service-account", Subject: "shop/gone"},
+		{Severity: broken, Check: "disabled-service-account", Subject: "shop/off"},
+		{Severity: broken, Check: "malformed-annotation", Subject: "shop/typo"},
+		{Severity: broken, Check: "missing-annotation", Subject: "shop/unannotated"},
+		{Severity: stale, Check: "stale-binding", Subject: "ok@p.iam.gserviceaccount.com"},
+	}
+	got := checkBindings(pool, ksas, gsas)
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(wiIssue{}, "Message", "Fix")); diff != "" {
+		t.Errorf("checkBindings() mismatch (-want +got):\n%s", diff)
+	}
+	if want := "gcloud iam service-accounts add-iam-policy-binding unbound@p.iam.gserviceaccount.com --role=roles/iam.workloadIdentityUser --member='serviceAccount:p.svc.id.goog[shop/unbound]' --project=p"; got[0].Fix != want {
+		t.Errorf("checkBindings() fix = %s, want %s", got[0].Fix, want)
+	}
+}
+
+func TestCheckNodes(t *testing.T) {
+	pod := func(name, node string, hostNetwork bool) wiPod {
+		var p wiPod
+		p.Metadata.Namespace, p.Metadata.Name = "shop", name
+		p.Spec.NodeName, p.Spec.HostNetwork = node, hostNetwork
+		return p
+	}
+	pods := []wiPod{pod("web", "node-1", false), pod("batch", "node-2", false), pod("agent", "node-1", true)}
+	nodePools := map[string]string{"node-1": "wi", "node-2": "legacy"}
+	wi := workloadIdentity{Pool: "p.svc.id.goog", NodePools: []string{"legacy"}}
+	want := []wiIssue{
+		{Severity: risky, Check: "node-metadata", Subject: "node pool legacy", Message: "Doesn't run the GKE metadata server, so its pods use the service account of the node instead of Workload Identity."},
+		{Severity: risky, Check: "node-service-account", Subject: "shop/Pod/agent", Message: "Uses the service account of its node, since it uses the host network, which bypasses the GKE metadata server."},
+		{Severity: risky, Check: "node-service-account", Subject: "shop/Pod/batch", Message: "Uses the service account of its node, since it runs on node pool legacy without the GKE metadata server."},
+	}
+	got := checkNodes("p", "us-central1", "prod", wi, pods, nodePools)
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(wiIssue{}, "Fix")); diff != "" {
+		t.Errorf("checkNodes() mismatch (-want +got):\n%s", diff)
+	}
+
+	got = checkNodes("p", "us-central1", "prod", workloadIdentity{NodePools: []string{"legacy"}}, pods[:1], nodePools)
+	if len(got) != 2 || got[0].Check != "workload-identity-disabled" || got[1].Check != "node-service-account" {
+		t.Errorf("checkNodes() without Workload Identity = %+v, want it disabled and the pod using the node's service account", got)
+	}
+}
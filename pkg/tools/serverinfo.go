@@ -0,0 +1,79 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/protocol"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ServerInfo describes the server, the tools it serves and the client it
+// serves them to.
+type ServerInfo struct {
+	Version          string           `json:"version"`
+	ProtocolVersions []string         `json:"protocol_versions"`
+	Client           *protocol.Client `json:"client,omitempty"`
+	ClientWarning    string           `json:"client_warning,omitempty"`
+	ReadOnly         bool             `json:"read_only"`
+	DryRun           bool             `json:"dry_run"`
+	Tools            []string         `json:"tools"`
+}
+
+// addServerInfoTool registers server_info. It is added once the tool set is
+// final, so it is always available and lists the tools actually served.
+func addServerInfoTool(s *server.MCPServer, c *config.Config) {
+	tool := mcp.NewTool("server_info",
+		mcp.WithDescription("Show the version of the GKE MCP server, the MCP protocol versions it supports and negotiated with the client, and the tools it enables. Use it to troubleshoot a tool that's missing or a client that misbehaves."),
+		catalog.Describe(catalog.Server, catalog.Local),
+	)
+	s.AddTool(tool, func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		info, err := serverInfo(ctx, s, c)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	})
+}
+
+func serverInfo(ctx context.Context, s *server.MCPServer, c *config.Config) (*ServerInfo, error) {
+	tools, err := ListTools(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	info := &ServerInfo{
+		Version:          c.Version(),
+		ProtocolVersions: protocol.Supported(),
+		ReadOnly:         c.ReadOnly(),
+		DryRun:           c.DryRun(),
+	}
+	for _, tool := range tools {
+		info.Tools = append(info.Tools, tool.Name)
+	}
+	if client, ok := protocol.Default.Get(ctx); ok {
+		info.Client = &client
+		info.ClientWarning = protocol.Incompatibility(client.Requested)
+	}
+	return info, nil
+}
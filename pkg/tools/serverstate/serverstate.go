@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serverstate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/auth"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/state"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("serverstate", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	getServerStateTool := mcp.NewTool("get_server_state",
+		mcp.WithDescription("Show the state the server keeps across restarts: the persisted session context, the long-running operations still being tracked and the cluster inventory cached for the caller, and where it is stored."),
+		catalog.Describe(catalog.Server, catalog.Local),
+	)
+	s.AddTool(getServerStateTool, h.getServerState)
+
+	// The state is shared by every session, so only a server serving a
+	// single user lets it be cleared.
+	if c.SingleUser() {
+		clearServerStateTool := mcp.NewTool("clear_server_state",
+			mcp.WithDescription("Forget the state the server keeps across restarts, in memory and in its store. Only use it if the user asks to reset the server or the state is stale."),
+			catalog.Describe(catalog.Server, catalog.Delete),
+			dryrun.Argument(c),
+		)
+		s.AddTool(clearServerStateTool, h.clearServerState)
+	}
+
+	return nil
+}
+
+// getServerState shows the cache entries of the caller's identity only, as
+// auth.CacheKey ends their keys. Over HTTP, the state not kept per identity,
+// like tracked operations, is left out too, since it may belong to other
+// users.
+func (h *handlers) getServerState(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	store := "none, state is not persisted"
+	if backend := state.Default.Backend(); backend != nil {
+		store = backend.String()
+	}
+	suffix := "|" + auth.CacheKey(ctx, h.c)
+	snapshot, err := state.Default.SnapshotMatching(func(key string) bool {
+		return strings.HasSuffix(key, suffix)
+	}, h.c.SingleUser())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Store: %s\nState:\n```json\n%s\n```", store, data)), nil
+}
+
+func (h *handlers) clearServerState(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if dryrun.Enabled(request, h.c) {
+		return dryrun.Describe("forget the persisted session context, tracked operations and cached cluster inventory"), nil
+	}
+	if err := state.Default.Clear(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText("Server state cleared."), nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessioncontext
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/state"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("sessioncontext", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	// Setting the context only changes the server's defaults, never any
+	// resource, so the tool is read-only.
+	setContextTool := mcp.NewTool("set_context",
+		mcp.WithDescription("Pin the GCP project, location and GKE cluster the user is working with for the rest of the session, so later tool calls can leave them out. Only the given fields are changed. Use it when the user says which cluster they are working on."),
+		catalog.Describe(catalog.Server, catalog.Local),
+		mcp.WithString("project_id", mcp.Description("GCP project ID.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location (region or zone).")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name.")),
+		mcp.WithBoolean("clear", mcp.Description("Clear the session context before applying the other arguments.")),
+	)
+	s.AddTool(setContextTool, h.setContext)
+
+	// Saving the context changes what every later session starts with, so
+	// it's only offered when they all belong to the same user.
+	if c.SingleUser() {
+		saveContextTool := mcp.NewTool("save_context",
+			mcp.WithDescription("Use the context of this session, set with set_context, for new sessions too, including after the server restarts. Only use it if the user asks to remember the context."),
+			catalog.Describe(catalog.Server, catalog.Write),
+			dryrun.Argument(c),
+		)
+		s.AddTool(saveContextTool, h.saveContext)
+	}
+
+	getContextTool := mcp.NewTool("get_context",
+		mcp.WithDescription("Get the GCP project, location and GKE cluster that tools default to in this session."),
+		catalog.Describe(catalog.Server, catalog.Local),
+	)
+	s.AddTool(getContextTool, h.getContext)
+
+	return nil
+}
+
+func (h *handlers) setContext(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sc := session.Default.Get(ctx)
+	if request.GetBool("clear", false) {
+		sc = session.Context{}
+	}
+	if projectID := request.GetString("project_id", ""); projectID != "" {
+		sc.ProjectID = projectID
+	}
+	if location := request.GetString("location", ""); location != "" {
+		sc.Location = location
+	}
+	if cluster := request.GetString("cluster", ""); cluster != "" {
+		sc.Cluster = cluster
+	}
+	session.Default.Set(ctx, sc)
+	return h.getContext(ctx, request)
+}
+
+func (h *handlers) saveContext(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sc := session.Default.Get(ctx)
+	if dryrun.Enabled(request, h.c) {
+		return dryrun.Describe(fmt.Sprintf("start new sessions with project %q, location %q and cluster %q", sc.ProjectID, sc.Location, sc.Cluster)), nil
+	}
+	session.Default.SetInitial(sc)
+	if err := state.Default.Save(ctx); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("context used for new sessions but could not be saved across restarts: %v", err)), nil
+	}
+	return h.getContext(ctx, request)
+}
+
+func (h *handlers) getContext(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sc := session.Default.Get(ctx)
+	effective := session.Context{
+		ProjectID: firstNonEmpty(sc.ProjectID, h.c.DefaultProjectID()),
+		Location:  firstNonEmpty(sc.Location, h.c.DefaultLocation()),
+		Cluster:   firstNonEmpty(sc.Cluster, h.c.DefaultCluster()),
+	}
+	data, err := json.MarshalIndent(effective, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
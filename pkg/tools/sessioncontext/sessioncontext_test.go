@@ -0,0 +1,87 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package sessioncontext_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/mock"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/sessioncontext"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolNames returns the names of the tools of s.
+func toolNames(t *testing.T, s *server.MCPServer) []string {
+	t.Helper()
+	list, err := tools.ListTools(context.Background(), s)
+	if err != nil {
+		t.Fatalf("ListTools() failed: %v", err)
+	}
+	var names []string
+	for _, tool := range list {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestInstall(t *testing.T) {
+	for _, tc := range []struct {
+		mode string
+		want bool
+	}{
+		{mode: "stdio", want: true},
+		{mode: "http", want: false},
+	} {
+		t.Run(tc.mode, func(t *testing.T) {
+			s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true))
+			if err := sessioncontext.Install(context.Background(), s, config.New("test", config.WithServerMode(tc.mode))); err != nil {
+				t.Fatalf("Install() failed: %v", err)
+			}
+			names := toolNames(t, s)
+			if got := slices.Contains(names, "save_context"); got != tc.want {
+				t.Errorf("save_context installed = %t, want %t (tools %v)", got, tc.want, names)
+			}
+			if !slices.Contains(names, "set_context") {
+				t.Errorf("tools = %v, want set_context", names)
+			}
+		})
+	}
+}
+
+func TestInstallReadOnly(t *testing.T) {
+	m, err := mock.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	for _, readOnly := range []bool{false, true} {
+		s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true))
+		if err := tools.Install(context.Background(), s, config.New("test", config.WithMock(m), config.WithReadOnly(readOnly))); err != nil {
+			t.Fatalf("Install() failed: %v", err)
+		}
+		names := toolNames(t, s)
+		if got := slices.Contains(names, "save_context"); got == readOnly {
+			t.Errorf("read-only %t: save_context installed = %t, want %t", readOnly, got, !readOnly)
+		}
+		if !slices.Contains(names, "get_context") {
+			t.Errorf("read-only %t: tools = %v, want get_context", readOnly, names)
+		}
+	}
+}
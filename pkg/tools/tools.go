@@ -16,34 +16,164 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
 
 	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
-	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/cluster"
-	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/clustertoolkit"
-	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/giq"
-	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/logging"
-	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/monitoring"
-	"github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/recommendation"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/argocd"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/artifacts"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/autopilot"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/builds"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/certificates"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/clouddeploy"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/cluster"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/clustertoolkit"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/compliance"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/costs"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/giq"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/health"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/helm"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/instructions"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/inventory"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/knownissues"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/logging"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/manifests"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/monitoring"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/namespaces"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/project"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/recommendation"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/security"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/serverstate"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/sessioncontext"
+	_ "github.com/GoogleCloudPlatform/gke-mcp/pkg/tools/webhooks"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-type installer func(ctx context.Context, s *server.MCPServer, c *config.Config) error
-
+// Install installs the tools of the registered plugins, which include every
+// package of pkg/tools, and of the external plugins of the configuration.
 func Install(ctx context.Context, s *server.MCPServer, c *config.Config) error {
-	installers := []installer{
-		cluster.Install,
-		clustertoolkit.Install,
-		giq.Install,
-		logging.Install,
-		monitoring.Install,
-		recommendation.Install,
+	if err := plugin.Install(ctx, s, c); err != nil {
+		return err
+	}
+	plugin.Attach(ctx, s, c)
+
+	if err := checkDryRunSupport(ctx, s); err != nil {
+		return err
+	}
+	if err := checkCatalog(ctx, s); err != nil {
+		return err
+	}
+
+	if len(c.EnabledTools()) > 0 {
+		if err := keepEnabledTools(ctx, s, c.EnabledTools()); err != nil {
+			return err
+		}
 	}
 
-	for _, installer := range installers {
-		if err := installer(ctx, s, c); err != nil {
+	if c.ReadOnly() {
+		if err := removeMutatingTools(ctx, s); err != nil {
 			return err
 		}
 	}
 
+	addServerInfoTool(s, c)
+	addCapabilitiesTool(s)
+	addExplainCommandTool(s)
+	addRunPlanTool(s)
+	addProbeToolsTool(s, c)
+
+	if err := structured.Install(s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListTools returns every tool registered on the server.
+func ListTools(ctx context.Context, s *server.MCPServer) ([]mcp.Tool, error) {
+	resp := s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":0,"method":"tools/list"}`))
+	switch resp := resp.(type) {
+	case mcp.JSONRPCResponse:
+		result, ok := resp.Result.(mcp.ListToolsResult)
+		if !ok {
+			return nil, fmt.Errorf("unexpected tools/list result type %T", resp.Result)
+		}
+		return result.Tools, nil
+	case mcp.JSONRPCError:
+		return nil, fmt.Errorf("failed to list tools: %s", resp.Error.Message)
+	default:
+		return nil, fmt.Errorf("unexpected tools/list response type %T", resp)
+	}
+}
+
+// IsReadOnly reports whether a tool is annotated as not modifying its
+// environment. Tools without the annotation are assumed to be mutating.
+func IsReadOnly(tool mcp.Tool) bool {
+	return tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint
+}
+
+// checkDryRunSupport makes sure every mutating tool can be dry run.
+func checkDryRunSupport(ctx context.Context, s *server.MCPServer) error {
+	tools, err := ListTools(ctx, s)
+	if err != nil {
+		return err
+	}
+	for _, tool := range tools {
+		if !IsReadOnly(tool) && !dryrun.Supported(tool) {
+			return fmt.Errorf("mutating tool %q does not support the %s argument", tool.Name, dryrun.ArgumentName)
+		}
+	}
+	return nil
+}
+
+// removeMutatingTools unregisters every tool that is not read-only, so it is
+// neither listed nor callable.
+func removeMutatingTools(ctx context.Context, s *server.MCPServer) error {
+	tools, err := ListTools(ctx, s)
+	if err != nil {
+		return err
+	}
+	var mutating []string
+	for _, tool := range tools {
+		if !IsReadOnly(tool) {
+			mutating = append(mutating, tool.Name)
+		}
+	}
+	if len(mutating) > 0 {
+		slog.Info("Read-only mode: disabling mutating tools", "tools", mutating)
+		s.DeleteTools(mutating...)
+	}
+	return nil
+}
+
+// keepEnabledTools unregisters every tool that is not in enabled.
+func keepEnabledTools(ctx context.Context, s *server.MCPServer, enabled []string) error {
+	tools, err := ListTools(ctx, s)
+	if err != nil {
+		return err
+	}
+	var disabled []string
+	registered := map[string]bool{}
+	for _, tool := range tools {
+		registered[tool.Name] = true
+		if !slices.Contains(enabled, tool.Name) {
+			disabled = append(disabled, tool.Name)
+		}
+	}
+	for _, name := range enabled {
+		if !registered[name] {
+			slog.Warn("Unknown tool in enabled tools", "tool", name)
+		}
+	}
+	if len(disabled) > 0 {
+		slog.Info("Disabling tools not enabled by the configuration", "tools", disabled)
+		s.DeleteTools(disabled...)
+	}
 	return nil
 }
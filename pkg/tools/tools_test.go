@@ -0,0 +1,199 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/dryrun"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/mock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func noop(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultText(""), nil
+}
+
+func TestRemoveMutatingTools(t *testing.T) {
+	ctx := context.Background()
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("read", mcp.WithReadOnlyHintAnnotation(true)), noop)
+	s.AddTool(mcp.NewTool("write", mcp.WithReadOnlyHintAnnotation(false)), noop)
+	s.AddTool(mcp.NewTool("unannotated"), noop)
+
+	if err := removeMutatingTools(ctx, s); err != nil {
+		t.Fatalf("removeMutatingTools() failed: %v", err)
+	}
+
+	tools, err := ListTools(ctx, s)
+	if err != nil {
+		t.Fatalf("ListTools() failed: %v", err)
+	}
+	var got []string
+	for _, tool := range tools {
+		got = append(got, tool.Name)
+	}
+	if diff := cmp.Diff([]string{"read"}, got); diff != "" {
+		t.Errorf("remaining tools mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestKeepEnabledTools(t *testing.T) {
+	ctx := context.Background()
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("a", mcp.WithReadOnlyHintAnnotation(true)), noop)
+	s.AddTool(mcp.NewTool("b", mcp.WithReadOnlyHintAnnotation(true)), noop)
+	s.AddTool(mcp.NewTool("c", mcp.WithReadOnlyHintAnnotation(true)), noop)
+
+	if err := keepEnabledTools(ctx, s, []string{"a", "c", "unknown"}); err != nil {
+		t.Fatalf("keepEnabledTools() failed: %v", err)
+	}
+
+	tools, err := ListTools(ctx, s)
+	if err != nil {
+		t.Fatalf("ListTools() failed: %v", err)
+	}
+	var got []string
+	for _, tool := range tools {
+		got = append(got, tool.Name)
+	}
+	if diff := cmp.Diff([]string{"a", "c"}, got); diff != "" {
+		t.Errorf("remaining tools mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckDryRunSupport(t *testing.T) {
+	ctx := context.Background()
+	c := config.New("test")
+
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("read", mcp.WithReadOnlyHintAnnotation(true)), noop)
+	s.AddTool(mcp.NewTool("write", mcp.WithReadOnlyHintAnnotation(false), dryrun.Argument(c)), noop)
+	if err := checkDryRunSupport(ctx, s); err != nil {
+		t.Errorf("checkDryRunSupport() failed: %v", err)
+	}
+
+	s.AddTool(mcp.NewTool("write_without_dry_run", mcp.WithReadOnlyHintAnnotation(false)), noop)
+	if err := checkDryRunSupport(ctx, s); err == nil {
+		t.Errorf("checkDryRunSupport() succeeded for a mutating tool without dry_run, want error")
+	}
+}
+
+func TestServerInfo(t *testing.T) {
+	ctx := context.Background()
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("a", mcp.WithReadOnlyHintAnnotation(true)), noop)
+	c := config.New("1.2.3", config.WithReadOnly(true))
+	addServerInfoTool(s, c)
+
+	info, err := serverInfo(ctx, s, c)
+	if err != nil {
+		t.Fatalf("serverInfo() failed: %v", err)
+	}
+	want := &ServerInfo{
+		Version:          "1.2.3",
+		ProtocolVersions: []string{"2024-11-05", "2025-03-26"},
+		ReadOnly:         true,
+		Tools:            []string{"a", "server_info"},
+	}
+	if diff := cmp.Diff(want, info); diff != "" {
+		t.Errorf("serverInfo() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	ctx := context.Background()
+	c := config.New("test")
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("test_list_clusters", catalog.Describe(catalog.Clusters, catalog.Read, "container.clusters.list")), noop)
+	s.AddTool(mcp.NewTool("test_delete_cluster", catalog.Describe(catalog.Clusters, catalog.Delete, "container.clusters.delete", "container.clusters.list"), dryrun.Argument(c)), noop)
+	s.AddTool(mcp.NewTool("test_get_context", catalog.Describe(catalog.Server, catalog.Local)), noop)
+	if err := checkCatalog(ctx, s); err != nil {
+		t.Fatalf("checkCatalog() failed: %v", err)
+	}
+
+	got, err := capabilities(ctx, s)
+	if err != nil {
+		t.Fatalf("capabilities() failed: %v", err)
+	}
+	want := &Capabilities{
+		Categories: []Category{
+			{
+				Name: catalog.Clusters,
+				Tools: []ToolCapability{
+					{Name: "test_delete_cluster", Kind: catalog.Delete, Destructive: true, Idempotent: true, OpenWorld: true, Permissions: []string{"container.clusters.delete", "container.clusters.list"}},
+					{Name: "test_list_clusters", Kind: catalog.Read, ReadOnly: true, Idempotent: true, OpenWorld: true, Permissions: []string{"container.clusters.list"}},
+				},
+				Permissions: []string{"container.clusters.delete", "container.clusters.list"},
+			},
+			{
+				Name:  catalog.Server,
+				Tools: []ToolCapability{{Name: "test_get_context", Kind: catalog.Local, ReadOnly: true, Idempotent: true}},
+			},
+		},
+		Permissions: []string{"container.clusters.delete", "container.clusters.list"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("capabilities() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Tools must be described, and keep the annotations of their kind.
+	s.AddTool(mcp.NewTool("test_undescribed", mcp.WithReadOnlyHintAnnotation(true)), noop)
+	if err := checkCatalog(ctx, s); err == nil {
+		t.Errorf("checkCatalog() succeeded with a tool missing from the catalog, want error")
+	}
+	s.DeleteTools("test_undescribed")
+	s.AddTool(mcp.NewTool("test_overridden", catalog.Describe(catalog.Server, catalog.Local), mcp.WithOpenWorldHintAnnotation(true)), noop)
+	if err := checkCatalog(ctx, s); err == nil {
+		t.Errorf("checkCatalog() succeeded with annotations not matching the tool's kind, want error")
+	}
+}
+
+func TestMockMode(t *testing.T) {
+	m, err := mock.New("")
+	if err != nil {
+		t.Fatalf("mock.New() failed: %v", err)
+	}
+	defer m.Close()
+	ctx := context.Background()
+	c := config.New("test", config.WithMock(m))
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true))
+	if err := Install(ctx, s, c); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+
+	for tool, want := range map[string]string{
+		"list_clusters":        mock.Cluster,
+		"cluster_health_check": "Insufficient memory",
+	} {
+		resp := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+tool+`","arguments":{}}}`))
+		r, ok := resp.(mcp.JSONRPCResponse)
+		if !ok {
+			t.Errorf("calling %s failed: %+v", tool, resp)
+			continue
+		}
+		result := r.Result.(mcp.CallToolResult)
+		text := result.Content[0].(mcp.TextContent).Text
+		if result.IsError || !strings.Contains(text, want) {
+			t.Errorf("%s returned %q, want a result mentioning %q", tool, text, want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+)
+
+// Severities of the issues, from worst to least.
+const (
+	critical = "critical"
+	warning  = "warning"
+	info     = "info"
+)
+
+const (
+	// defaultTimeout is the timeout of webhooks that don't set one.
+	defaultTimeout = 10
+	// slowTimeout is the timeout above which a slow backend noticeably
+	// delays requests.
+	slowTimeout = 15
+)
+
+// configuration is a ValidatingWebhookConfiguration or a
+// MutatingWebhookConfiguration.
+type configuration struct {
+	Kind     string
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Webhooks []webhook `json:"webhooks"`
+}
+
+type webhook struct {
+	Name         string `json:"name"`
+	ClientConfig struct {
+		URL     string `json:"url"`
+		Service *struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+		} `json:"service"`
+		CABundle string `json:"caBundle"`
+	} `json:"clientConfig"`
+	Rules             []rule              `json:"rules"`
+	FailurePolicy     string              `json:"failurePolicy"`
+	TimeoutSeconds    *int                `json:"timeoutSeconds"`
+	NamespaceSelector *kube.LabelSelector `json:"namespaceSelector"`
+}
+
+type rule struct {
+	APIGroups  []string `json:"apiGroups"`
+	Resources  []string `json:"resources"`
+	Operations []string `json:"operations"`
+}
+
+// service is the state of the Service behind a webhook.
+type service struct {
+	Found          bool
+	ReadyEndpoints int
+}
+
+// failure is the admission failures of a webhook recorded in events.
+type failure struct {
+	Count   int
+	Last    time.Time
+	Message string
+}
+
+// event is the part of an event read.
+type event struct {
+	Metadata struct {
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Message       string    `json:"message"`
+	Count         int       `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+var failedCall = regexp.MustCompile(`failed calling webhook "([^"]+)": (.+)`)
+
+// failures returns the admission failures in events by webhook name.
+func failures(events []event) map[string]*failure {
+	result := map[string]*failure{}
+	for _, e := range events {
+		m := failedCall.FindStringSubmatch(e.Message)
+		if m == nil {
+			continue
+		}
+		f := result[m[1]]
+		if f == nil {
+			f = &failure{}
+			result[m[1]] = f
+		}
+		f.Count += max(e.Count, 1)
+		if !e.LastTimestamp.Before(f.Last) {
+			f.Last, f.Message = e.LastTimestamp, m[2]
+		}
+	}
+	return result
+}
+
+// issue is a problem found with a webhook.
+type issue struct {
+	Webhook       string `json:"webhook"`
+	Configuration string `json:"configuration"`
+	// Namespace is the namespace of the webhook's Service, if any.
+	Namespace string `json:"namespace,omitempty"`
+	Severity  string `json:"severity"`
+	Check     string `json:"check"`
+	Message   string `json:"message"`
+}
+
+// diagnose checks the webhooks of the configurations against the state of
+// their Services, their recent failures and the labels of namespaces.
+func diagnose(configs []configuration, services map[string]service, failed map[string]*failure, namespaces map[string]map[string]string) []issue {
+	var issues []issue
+	for _, c := range configs {
+		for _, w := range c.Webhooks {
+			add := func(severity, check, format string, args ...any) {
+				i := issue{Webhook: w.Name, Configuration: c.Kind + "/" + c.Metadata.Name, Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)}
+				if s := w.ClientConfig.Service; s != nil {
+					i.Namespace = s.Namespace
+				}
+				issues = append(issues, i)
+			}
+			// Requests are rejected when a webhook failing closed can't
+			// be called.
+			fails := w.FailurePolicy != "Ignore"
+			outage := warning
+			if fails {
+				outage = critical
+			}
+
+			if s := w.ClientConfig.Service; s != nil {
+				svc := services[s.Namespace+"/"+s.Name]
+				switch {
+				case !svc.Found:
+					add(outage, "unreachable", "The Service %s/%s of the webhook doesn't exist.", s.Namespace, s.Name)
+				case svc.ReadyEndpoints == 0:
+					add(outage, "unreachable", "The Service %s/%s of the webhook has no ready endpoints.", s.Namespace, s.Name)
+				}
+				if w.ClientConfig.CABundle == "" {
+					add(outage, "ca-bundle", "The webhook has no caBundle, so the API server can't verify the certificate of %s/%s; check that the CA injector, such as cert-manager's cainjector, is running.", s.Namespace, s.Name)
+				}
+				if fails && w.intercepts("pods") && w.NamespaceSelector.Matches(namespaces[s.Namespace]) {
+					add(warning, "self-dependency", "The webhook intercepts pods of its own namespace %s and fails closed, so its pods can't be recreated when all of them are down, such as after a node pool upgrade.", s.Namespace)
+				}
+			}
+
+			if f := failed[w.Name]; f != nil {
+				kind := "failed"
+				if strings.Contains(f.Message, "deadline exceeded") || strings.Contains(f.Message, "timeout") {
+					kind = "timed out"
+				}
+				severity := warning
+				if fails {
+					severity = critical
+				}
+				add(severity, "failures", "Calls to the webhook %s %d times, last at %s: %s", kind, f.Count, f.Last.Format(time.RFC3339), f.Message)
+			}
+
+			timeout := defaultTimeout
+			if w.TimeoutSeconds != nil {
+				timeout = *w.TimeoutSeconds
+			}
+			if timeout > slowTimeout {
+				add(warning, "timeout", "The webhook times out after %ds, so a slow backend delays every request it intercepts by up to that long.", timeout)
+			}
+
+			if fails && (w.intercepts("pods") || w.intercepts("nodes")) && w.NamespaceSelector.Matches(namespaces["kube-system"]) {
+				add(warning, "upgrade-risk", "The webhook fails closed on pods or nodes including kube-system, which can block system pods and node registration during upgrades when its backend is down; exclude kube-system with a namespaceSelector or set failurePolicy: Ignore.")
+			}
+		}
+	}
+	rank := map[string]int{critical: 0, warning: 1, info: 2}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return rank[issues[i].Severity] < rank[issues[j].Severity]
+	})
+	return issues
+}
+
+// intercepts tells whether the webhook intercepts a resource of the core
+// API group.
+func (w *webhook) intercepts(resource string) bool {
+	for _, r := range w.Rules {
+		group := slices.Contains(r.APIGroups, "") || slices.Contains(r.APIGroups, "*")
+		if group && (slices.Contains(r.Resources, resource) || slices.Contains(r.Resources, "*") || slices.Contains(r.Resources, "*/*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func parseConfig(t *testing.T, kind, data string) configuration {
+	t.Helper()
+	c := configuration{Kind: kind}
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestFailures(t *testing.T) {
+	var events []event
+	if err := json.Unmarshal([]byte(`[
+		{"message":"Error creating: Internal error occurred: failed calling webhook \"policy.example.com\": failed to call webhook: Post \"https://policy.policy.svc:443/validate\": context deadline exceeded","count":3,"lastTimestamp":"2025-06-01T10:00:00Z"},
+		{"message":"Error creating: Internal error occurred: failed calling webhook \"policy.example.com\": no endpoints available for service \"policy\"","lastTimestamp":"2025-06-01T11:00:00Z"},
+		{"message":"Back-off restarting failed container","count":12,"lastTimestamp":"2025-06-01T11:30:00Z"}
+	]`), &events); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*failure{
+		"policy.example.com": {Count: 4, Last: time.Date(2025, 6, 1, 11, 0, 0, 0, time.UTC), Message: `no endpoints available for service "policy"`},
+	}
+	if diff := cmp.Diff(want, failures(events)); diff != "" {
+		t.Errorf("failures() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	configs := []configuration{
+		parseConfig(t, "ValidatingWebhookConfiguration", `{"metadata":{"name":"policy"},"webhooks":[{
+			"name":"policy.example.com",
+			"clientConfig":{"service":{"namespace":"policy","name":"policy"},"caBundle":"Y2E="},
+			"rules":[{"apiGroups":[""],"resources":["pods"],"operations":["CREATE"]}],
+			"timeoutSeconds":30}]}`),
+		parseConfig(t, "MutatingWebhookConfiguration", `{"metadata":{"name":"sidecar"},"webhooks":[{
+			"name":"sidecar.example.com",
+			"clientConfig":{"service":{"namespace":"mesh","name":"injector"}},
+			"rules":[{"apiGroups":[""],"resources":["pods"],"operations":["CREATE"]}],
+			"failurePolicy":"Ignore",
+			"namespaceSelector":{"matchLabels":{"injection":"enabled"}}}]}`),
+		parseConfig(t, "ValidatingWebhookConfiguration", `{"metadata":{"name":"healthy"},"webhooks":[{
+			"name":"healthy.example.com",
+			"clientConfig":{"url":"https://hooks.example.com/validate"},
+			"rules":[{"apiGroups":["apps"],"resources":["deployments"],"operations":["*"]}]}]}`),
+	}
+	services := map[string]service{
+		"policy/policy": {Found: true},
+		"mesh/injector": {Found: true, ReadyEndpoints: 2},
+	}
+	failed := map[string]*failure{
+		"sidecar.example.com": {Count: 2, Last: time.Date(2025, 6, 1, 11, 0, 0, 0, time.UTC), Message: "context deadline exceeded"},
+	}
+	namespaces := map[string]map[string]string{
+		"kube-system": {"kubernetes.io/metadata.name": "kube-system"},
+		"policy":      {"kubernetes.io/metadata.name": "policy"},
+	}
+	want := []issue{
+		{Webhook: "policy.example.com", Configuration: "ValidatingWebhookConfiguration/policy", Namespace: "policy", Severity: critical, Check: "unreachable", Message: "The Service policy/policy of the webhook has no ready endpoints."},
+		{Webhook: "policy.example.com", Configuration: "ValidatingWebhookConfiguration/policy", Namespace: "policy", Severity: warning, Check: "self-dependency", Message: "The webhook intercepts pods of its own namespace policy and fails closed, so its pods can't be recreated when all of them are down, such as after a node pool upgrade."},
+		{Webhook: "policy.example.com", Configuration: "ValidatingWebhookConfiguration/policy", Namespace: "policy", Severity: warning, Check: "timeout", Message: "The webhook times out after 30s, so a slow backend delays every request it intercepts by up to that long."},
+		{Webhook: "policy.example.com", Configuration: "ValidatingWebhookConfiguration/policy", Namespace: "policy", Severity: warning, Check: "upgrade-risk", Message: "The webhook fails closed on pods or nodes including kube-system, which can block system pods and node registration during upgrades when its backend is down; exclude kube-system with a namespaceSelector or set failurePolicy: Ignore."},
+		{Webhook: "sidecar.example.com", Configuration: "MutatingWebhookConfiguration/sidecar", Namespace: "mesh", Severity: warning, Check: "ca-bundle", Message: "The webhook has no caBundle, so the API server can't verify the certificate of mesh/injector; check that the CA injector, such as cert-manager's cainjector, is running."},
+		{Webhook: "sidecar.example.com", Configuration: "MutatingWebhookConfiguration/sidecar", Namespace: "mesh", Severity: warning, Check: "failures", Message: "Calls to the webhook timed out 2 times, last at 2025-06-01T11:00:00Z: context deadline exceeded"},
+	}
+	if diff := cmp.Diff(want, diagnose(configs, services, failed, namespaces)); diff != "" {
+		t.Errorf("diagnose() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/catalog"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/config"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/explain"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/gcp"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/kube"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/plugin"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/session"
+	"github.com/GoogleCloudPlatform/gke-mcp/pkg/structured"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type handlers struct {
+	c *config.Config
+}
+
+func init() {
+	plugin.Register(plugin.New("webhooks", Install))
+}
+
+func Install(_ context.Context, s *server.MCPServer, c *config.Config) error {
+	h := &handlers{
+		c: c,
+	}
+
+	diagnoseAdmissionWebhooksTool := mcp.NewTool("diagnose_admission_webhooks",
+		mcp.WithDescription("Diagnose the validating and mutating admission webhooks of a GKE cluster: Services that are missing or have no ready endpoints, missing CA bundles, recent call failures and timeouts recorded in events, long timeouts, and failurePolicy: Fail risks such as blocking kube-system or their own pods during upgrades. Names the offending webhook, its configuration and namespace. Use it when creating or updating resources fails with \"failed calling webhook\" or hangs."),
+		catalog.Describe(catalog.Observability, catalog.Read, "container.validatingWebhookConfigurations.list", "container.mutatingWebhookConfigurations.list", "container.services.get", "container.endpoints.get", "container.events.list", "container.namespaces.list"),
+		explain.Command(h.diagnoseAdmissionWebhooksCommands),
+		structured.Output(structured.Findings),
+		mcp.WithString("project_id", mcp.Description("GCP project ID. Defaults to the session context.")),
+		mcp.WithString("location", mcp.Description("GKE cluster location. Defaults to the session context.")),
+		mcp.WithString("cluster", mcp.Description("GKE cluster name. Defaults to the session context.")),
+	)
+	s.AddTool(diagnoseAdmissionWebhooksTool, h.diagnoseAdmissionWebhooks)
+
+	return nil
+}
+
+func (h *handlers) diagnoseAdmissionWebhooks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectID := session.ProjectID(ctx, request, h.c)
+	if projectID == "" {
+		return mcp.NewToolResultError("project_id argument not set"), nil
+	}
+	location := session.Location(ctx, request, h.c)
+	if location == "" {
+		return mcp.NewToolResultError("location argument not set"), nil
+	}
+	name := session.Cluster(ctx, request, h.c, "cluster")
+	if name == "" {
+		return mcp.NewToolResultError("cluster argument not set"), nil
+	}
+
+	k, err := gcp.Kubernetes(ctx, h.c, projectID, location, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	configs, err := listConfigurations(ctx, k)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	services := map[string]service{}
+	for _, c := range configs {
+		for _, w := range c.Webhooks {
+			s := w.ClientConfig.Service
+			if s == nil {
+				continue
+			}
+			key := s.Namespace + "/" + s.Name
+			if _, ok := services[key]; ok {
+				continue
+			}
+			if services[key], err = serviceState(ctx, k, s.Namespace, s.Name); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+	}
+	var events struct {
+		Items []event `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/events", &events); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var namespaceList struct {
+		Items []struct {
+			Metadata struct {
+				Name   string            `json:"name"`
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := k.Get(ctx, "/api/v1/namespaces", &namespaceList); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespaces := map[string]map[string]string{}
+	for _, ns := range namespaceList.Items {
+		namespaces[ns.Metadata.Name] = ns.Metadata.Labels
+	}
+
+	issues := diagnose(configs, services, failures(events.Items), namespaces)
+	var webhooks int
+	for _, c := range configs {
+		webhooks += len(c.Webhooks)
+	}
+	text := fmt.Sprintf("Found %d issues with the %d admission webhooks of cluster %s.", len(issues), webhooks, name)
+	data, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result, err := structured.Result(text+"\n\n"+string(data), structured.Findings, findingList(issues))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
+}
+
+// listConfigurations lists the validating and mutating webhook
+// configurations.
+func listConfigurations(ctx context.Context, k *kube.Client) ([]configuration, error) {
+	var configs []configuration
+	for _, kind := range []string{"ValidatingWebhookConfiguration", "MutatingWebhookConfiguration"} {
+		var list struct {
+			Items []configuration `json:"items"`
+		}
+		if err := k.Get(ctx, "/apis/admissionregistration.k8s.io/v1/"+strings.ToLower(kind)+"s", &list); err != nil {
+			return nil, err
+		}
+		for _, c := range list.Items {
+			c.Kind = kind
+			configs = append(configs, c)
+		}
+	}
+	return configs, nil
+}
+
+// serviceState gets whether a Service exists and how many ready endpoints
+// it has.
+func serviceState(ctx context.Context, k *kube.Client, namespace, name string) (service, error) {
+	if err := k.Get(ctx, fmt.Sprintf("/api/v1/namespaces/%s/services/%s", namespace, name), nil); err != nil {
+		if kube.IsNotFound(err) {
+			return service{}, nil
+		}
+		return service{}, err
+	}
+	var endpoints struct {
+		Subsets []struct {
+			Addresses []struct{} `json:"addresses"`
+		} `json:"subsets"`
+	}
+	if err := k.Get(ctx, fmt.Sprintf("/api/v1/namespaces/%s/endpoints/%s", namespace, name), &endpoints); err != nil && !kube.IsNotFound(err) {
+		return service{}, err
+	}
+	s := service{Found: true}
+	for _, subset := range endpoints.Subsets {
+		s.ReadyEndpoints += len(subset.Addresses)
+	}
+	return s, nil
+}
+
+// findings is the structured result of diagnose_admission_webhooks,
+// following the findings schema.
+type findings struct {
+	Findings []finding `json:"findings"`
+}
+
+type finding struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Category    string `json:"category"`
+	Priority    string `json:"priority"`
+	Resource    string `json:"resource"`
+}
+
+func findingList(issues []issue) findings {
+	priorities := map[string]string{critical: "P1", warning: "P2", info: "P4"}
+	list := findings{Findings: []finding{}}
+	for _, i := range issues {
+		list.Findings = append(list.Findings, finding{
+			Name:        "webhooks/" + i.Check + "/" + i.Webhook,
+			Description: i.Message,
+			Type:        i.Check,
+			Category:    "RELIABILITY",
+			Priority:    priorities[i.Severity],
+			Resource:    i.Configuration,
+		})
+	}
+	return list
+}
+
+func (h *handlers) diagnoseAdmissionWebhooksCommands(ctx context.Context, request mcp.CallToolRequest) []string {
+	projectID, location, cluster := session.ProjectID(ctx, request, h.c), session.Location(ctx, request, h.c), session.Cluster(ctx, request, h.c, "cluster")
+	if projectID == "" || location == "" || cluster == "" {
+		return nil
+	}
+	return []string{
+		explain.GetCredentials(projectID, location, cluster),
+		"kubectl get validatingwebhookconfigurations,mutatingwebhookconfigurations --output=yaml",
+		"kubectl get endpoints --all-namespaces",
+		"kubectl get events --all-namespaces --field-selector=type=Warning | grep 'failed calling webhook'",
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package update replaces the running binary with the latest GitHub release,
+// after verifying it against the release's checksums and, in builds with a
+// release signing key, their signature.
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Repo is the GitHub repository of the releases.
+	Repo = "GoogleCloudPlatform/gke-mcp"
+
+	binaryName = "gke-mcp"
+
+	// maxDownloadSize bounds the size of downloaded release assets.
+	maxDownloadSize = 256 << 20
+)
+
+// publicKey is the base64-encoded ed25519 key that signs the checksums of
+// the releases. Release builds set it with -ldflags; other builds can't
+// verify the checksums, so they refuse to update unless told to skip it.
+var publicKey string
+
+// Release is a GitHub release.
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file of a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Updater downloads and verifies releases.
+type Updater struct {
+	// Client makes the requests, http.DefaultClient if nil.
+	Client *http.Client
+	// APIURL is the URL of the GitHub API.
+	APIURL string
+	// Token authenticates to the GitHub API if set, to avoid its rate limit
+	// for anonymous requests.
+	Token string
+	// PublicKey verifies the signature of the checksums. Without it,
+	// Download fails unless InsecureSkipSignature is set.
+	PublicKey ed25519.PublicKey
+	// InsecureSkipSignature lets Download only verify the checksums when
+	// there is no PublicKey, trusting whoever serves them.
+	InsecureSkipSignature bool
+}
+
+// New returns an updater using the public GitHub API, with the release
+// signing key of the build if any.
+func New() (*Updater, error) {
+	u := &Updater{
+		APIURL: "https://api.github.com",
+		Token:  os.Getenv("GITHUB_TOKEN"),
+	}
+	if publicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(publicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid release signing key in the build")
+		}
+		u.PublicKey = key
+	}
+	return u, nil
+}
+
+// Latest returns the latest release.
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/releases/latest", u.APIURL, Repo), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if u.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+u.Token)
+	}
+	data, err := u.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest release: %w", err)
+	}
+	var release Release
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("failed to parse the latest release: %w", err)
+	}
+	if release.Tag == "" {
+		return nil, errors.New("the latest release has no tag")
+	}
+	return &release, nil
+}
+
+// ArchiveName returns the name of the release archive for an OS and
+// architecture, as named by the release configuration.
+func ArchiveName(goos, goarch string) string {
+	arch := goarch
+	switch goarch {
+	case "amd64":
+		arch = "x86_64"
+	case "386":
+		arch = "i386"
+	}
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("%s_%s_%s%s", binaryName, strings.ToUpper(goos[:1])+goos[1:], arch, ext)
+}
+
+// Download returns the binary of release for an OS and architecture, after
+// verifying the archive against the release checksums, and the checksums
+// against their signature. Without a public key, it fails unless u skips the
+// signature.
+func (u *Updater) Download(ctx context.Context, release *Release, goos, goarch string) ([]byte, error) {
+	if u.PublicKey == nil && !u.InsecureSkipSignature {
+		return nil, fmt.Errorf("this build has no release signing key to verify the release with, use --insecure-skip-signature to only verify its checksums")
+	}
+	archiveName := ArchiveName(goos, goarch)
+	archive, err := asset(release, func(name string) bool { return name == archiveName })
+	if err != nil {
+		return nil, err
+	}
+	sums, err := asset(release, func(name string) bool { return strings.HasSuffix(name, "checksums.txt") })
+	if err != nil {
+		return nil, err
+	}
+
+	sumsData, err := u.get(ctx, sums.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", sums.Name, err)
+	}
+	if u.PublicKey != nil {
+		sig, err := asset(release, func(name string) bool { return name == sums.Name+".sig" })
+		if err != nil {
+			return nil, fmt.Errorf("release %s isn't signed: %w", release.Tag, err)
+		}
+		sigData, err := u.get(ctx, sig.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", sig.Name, err)
+		}
+		// The signature is the raw 64 bytes, any of which may look like
+		// whitespace, so it's used as is.
+		if len(sigData) != ed25519.SignatureSize || !ed25519.Verify(u.PublicKey, sumsData, sigData) {
+			return nil, fmt.Errorf("invalid signature of %s", sums.Name)
+		}
+	}
+	want, err := checksum(sumsData, archiveName)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveData, err := u.get(ctx, archive.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", archive.Name, err)
+	}
+	if got := sha256.Sum256(archiveData); hex.EncodeToString(got[:]) != want {
+		return nil, fmt.Errorf("checksum mismatch for %s", archive.Name)
+	}
+	if goos == "windows" {
+		return extractZip(archiveData, binaryName+".exe")
+	}
+	return extractTarGz(archiveData, binaryName)
+}
+
+// Replace replaces the binary at exePath with binary. The new binary is
+// written next to it and renamed over it, so a failure leaves the old binary
+// in place.
+func Replace(exePath string, binary []byte) error {
+	info, err := os.Stat(exePath)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(exePath), "."+filepath.Base(exePath)+".new-*")
+	if err != nil {
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return err
+	}
+	// A running binary can't be replaced on Windows, but it can be renamed.
+	old := exePath + ".old"
+	os.Remove(old)
+	if err := os.Rename(exePath, old); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", exePath, err)
+	}
+	if err := os.Rename(tmp.Name(), exePath); err != nil {
+		if restoreErr := os.Rename(old, exePath); restoreErr != nil {
+			return fmt.Errorf("failed to replace %s: %w; the previous binary is at %s", exePath, err, old)
+		}
+		return fmt.Errorf("failed to replace %s: %w", exePath, err)
+	}
+	// Windows keeps the running binary open until it exits.
+	os.Remove(old)
+	return nil
+}
+
+// Newer reports whether version latest is newer than current. Versions are
+// semantic versions with a "v" prefix; pre-releases are older than their
+// release. It returns an error if current isn't a release version, e.g. in
+// development builds.
+func Newer(latest, current string) (bool, error) {
+	l, err := parseVersion(latest)
+	if err != nil {
+		return false, err
+	}
+	c, err := parseVersion(current)
+	if err != nil {
+		return false, err
+	}
+	for i := range 3 {
+		if l.parts[i] != c.parts[i] {
+			return l.parts[i] > c.parts[i], nil
+		}
+	}
+	switch {
+	case l.pre == c.pre:
+		return false, nil
+	case l.pre == "":
+		return true, nil
+	case c.pre == "":
+		return false, nil
+	default:
+		return l.pre > c.pre, nil
+	}
+}
+
+type version struct {
+	parts [3]int
+	pre   string
+}
+
+func parseVersion(v string) (version, error) {
+	var parsed version
+	s, ok := strings.CutPrefix(v, "v")
+	if !ok {
+		return parsed, fmt.Errorf("%q isn't a release version", v)
+	}
+	s, _, _ = strings.Cut(s, "+")
+	s, parsed.pre, _ = strings.Cut(s, "-")
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return parsed, fmt.Errorf("%q isn't a release version", v)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("%q isn't a release version", v)
+		}
+		parsed.parts[i] = n
+	}
+	return parsed, nil
+}
+
+func asset(release *Release, match func(name string) bool) (Asset, error) {
+	for _, a := range release.Assets {
+		if match(a.Name) {
+			return a, nil
+		}
+	}
+	return Asset{}, fmt.Errorf("release %s has no matching asset", release.Tag)
+}
+
+// checksum returns the SHA-256 checksum of name in a checksums file, which
+// has a "<checksum>  <name>" line per file.
+func checksum(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s", name)
+}
+
+func (u *Updater) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return u.do(req)
+}
+
+func (u *Updater) do(req *http.Request) ([]byte, error) {
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", req.URL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDownloadSize {
+		return nil, fmt.Errorf("GET %s: response larger than %d bytes", req.URL, maxDownloadSize)
+	}
+	return data, nil
+}
+
+func extractTarGz(data []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("archive has no %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == name {
+			return io.ReadAll(io.LimitReader(tr, maxDownloadSize))
+		}
+	}
+}
+
+func extractZip(data []byte, name string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().Mode().IsRegular() && filepath.Base(f.Name) == name {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(io.LimitReader(rc, maxDownloadSize))
+		}
+	}
+	return nil, fmt.Errorf("archive has no %s", name)
+}
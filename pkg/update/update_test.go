@@ -0,0 +1,189 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tarGz(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDownload(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := []byte("new gke-mcp")
+	archiveName := ArchiveName("linux", "amd64")
+	archive := tarGz(t, "gke-mcp", binary)
+	sum := sha256.Sum256(archive)
+	sums := []byte(fmt.Sprintf("%s  %s\n0000  gke-mcp_Darwin_arm64.tar.gz\n", hex.EncodeToString(sum[:]), archiveName))
+	sig := ed25519.Sign(priv, sums)
+
+	files := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/"+Repo+"/releases/latest" {
+			fmt.Fprintf(w, `{"tag_name": "v1.2.0", "assets": [
+				{"name": %q, "browser_download_url": "%s/archive"},
+				{"name": "gke-mcp_1.2.0_checksums.txt", "browser_download_url": "%s/sums"},
+				{"name": "gke-mcp_1.2.0_checksums.txt.sig", "browser_download_url": "%s/sig"}]}`,
+				archiveName, "http://"+r.Host, "http://"+r.Host, "http://"+r.Host)
+			return
+		}
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		archive []byte
+		sig     []byte
+		noKey   bool
+		skipSig bool
+		wantErr bool
+	}{
+		{name: "valid", archive: archive, sig: sig},
+		{name: "tampered archive", archive: tarGz(t, "gke-mcp", []byte("malware")), sig: sig, wantErr: true},
+		{name: "invalid signature", archive: archive, sig: ed25519.Sign(priv, []byte("other")), wantErr: true},
+		{name: "signature with trailing newline", archive: archive, sig: append(sig[:len(sig):len(sig)], '\n'), wantErr: true},
+		{name: "no key", archive: archive, sig: sig, noKey: true, wantErr: true},
+		{name: "no key, signature skipped", archive: archive, noKey: true, skipSig: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files["/archive"] = tc.archive
+			files["/sums"] = sums
+			files["/sig"] = tc.sig
+			u := &Updater{APIURL: srv.URL, PublicKey: pub, InsecureSkipSignature: tc.skipSig}
+			if tc.noKey {
+				u.PublicKey = nil
+			}
+			release, err := u.Latest(context.Background())
+			if err != nil {
+				t.Fatalf("Latest() failed: %v", err)
+			}
+			if release.Tag != "v1.2.0" {
+				t.Errorf("Latest() tag = %q, want v1.2.0", release.Tag)
+			}
+			got, err := u.Download(context.Background(), release, "linux", "amd64")
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Download() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Download() failed: %v", err)
+			}
+			if !bytes.Equal(got, binary) {
+				t.Errorf("Download() = %q, want %q", got, binary)
+			}
+		})
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	tests := []struct {
+		goos, goarch string
+		want         string
+	}{
+		{"linux", "amd64", "gke-mcp_Linux_x86_64.tar.gz"},
+		{"darwin", "arm64", "gke-mcp_Darwin_arm64.tar.gz"},
+		{"windows", "386", "gke-mcp_Windows_i386.zip"},
+	}
+	for _, tc := range tests {
+		if got := ArchiveName(tc.goos, tc.goarch); got != tc.want {
+			t.Errorf("ArchiveName(%q, %q) = %q, want %q", tc.goos, tc.goarch, got, tc.want)
+		}
+	}
+}
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+		wantErr         bool
+	}{
+		{latest: "v0.3.0", current: "v0.2.9", want: true},
+		{latest: "v0.10.0", current: "v0.9.0", want: true},
+		{latest: "v0.3.0", current: "v0.3.0", want: false},
+		{latest: "v0.3.0", current: "v0.4.0", want: false},
+		{latest: "v0.3.0", current: "v0.3.0-rc.1", want: true},
+		{latest: "v0.3.0-rc.1", current: "v0.3.0", want: false},
+		{latest: "v0.3.0", current: "(devel)", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := Newer(tc.latest, tc.current)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("Newer(%q, %q) = %v, %v; want %v, error %v", tc.latest, tc.current, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	exePath := filepath.Join(t.TempDir(), "gke-mcp")
+	if err := os.WriteFile(exePath, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Replace(exePath, []byte("new")); err != nil {
+		t.Fatalf("Replace() failed: %v", err)
+	}
+	got, err := os.ReadFile(exePath)
+	if err != nil || string(got) != "new" {
+		t.Errorf("binary after Replace() = %q, %v; want %q", got, err, "new")
+	}
+	if info, err := os.Stat(exePath); err != nil || info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("Stat() after Replace() = %v, %v; want an executable", info, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(exePath))
+	if len(entries) != 1 {
+		t.Errorf("Replace() left %d files, want only the binary", len(entries))
+	}
+}